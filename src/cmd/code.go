@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/randlee/claude-history/pkg/export"
+	"github.com/randlee/claude-history/pkg/paths"
+	"github.com/randlee/claude-history/pkg/resolver"
+	"github.com/randlee/claude-history/pkg/session"
+)
+
+var (
+	codeSessionID   string
+	codeLang        string
+	codeOutputDir   string
+	codeConcatFile  string
+	codeFailOnEmpty bool
+)
+
+var codeCmd = &cobra.Command{
+	Use:   "code <project-path>",
+	Short: "Extract code blocks from a session",
+	Long: `Extract the fenced code blocks from a session's assistant messages.
+
+Each block is preceded by a comment naming the message (UUID and timestamp) it
+came from, written in the block's language comment syntax.
+
+By default the blocks are printed to stdout, one after another. Use --output
+to write each block to its own numbered file (001.go, 002.go, ...) in a
+directory, or --concat to write them all to a single file.
+
+Examples:
+  # Print every code block from the most recent session
+  claude-history code /path/to/project
+
+  # Only Go blocks from a specific session (--lang is case-insensitive)
+  claude-history code /path/to/project --session abc123 --lang go
+
+  # Write each Python block to its own file
+  claude-history code /path/to/project --session abc123 --lang python --output ./snippets/
+
+  # Collect all SQL into one file
+  claude-history code /path/to/project --session abc123 --lang sql --concat queries.sql`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCode,
+}
+
+func init() {
+	rootCmd.AddCommand(codeCmd)
+
+	codeCmd.Flags().StringVar(&codeSessionID, "session", "", "Session ID (default: most recent session)")
+	codeCmd.Flags().StringVar(&codeLang, "lang", "", "Only extract blocks in this language (case-insensitive)")
+	codeCmd.Flags().StringVarP(&codeOutputDir, "output", "o", "", "Write each block to a numbered file in this directory")
+	codeCmd.Flags().StringVar(&codeConcatFile, "concat", "", "Write all blocks to this single file")
+	codeCmd.Flags().BoolVar(&codeFailOnEmpty, "fail-on-empty", false, "Exit with status 2 if no code blocks are found")
+}
+
+func runCode(cmd *cobra.Command, args []string) error {
+	if codeOutputDir != "" && codeConcatFile != "" {
+		return fmt.Errorf("--output and --concat cannot be used together")
+	}
+
+	projectPath := args[0]
+	projectDir, err := paths.ProjectDir(claudeDir, projectPath)
+	if err != nil {
+		return err
+	}
+	if !paths.Exists(projectDir) {
+		return fmt.Errorf("project not found: %s", projectPath)
+	}
+
+	sessionID := codeSessionID
+	if sessionID == "" {
+		sessions, err := session.ListSessions(projectDir)
+		if err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			return fmt.Errorf("no sessions found in project")
+		}
+		sessionID = sessions[0].ID
+	} else {
+		resolvedSessionID, err := resolver.ResolveSessionID(projectDir, sessionID)
+		if err != nil {
+			return fmt.Errorf("failed to resolve session ID: %w", err)
+		}
+		sessionID = resolvedSessionID
+	}
+
+	entries, err := session.ReadSession(paths.JSONLFile(filepath.Join(projectDir, sessionID)))
+	if err != nil {
+		return fmt.Errorf("failed to read session: %w", err)
+	}
+
+	blocks := export.CollectCodeBlocks(entries, codeLang)
+	if len(blocks) == 0 {
+		msg := "No code blocks found"
+		if codeLang != "" {
+			msg = fmt.Sprintf("No %s code blocks found", codeLang)
+		}
+		return noMatches(msg, codeFailOnEmpty)
+	}
+
+	switch {
+	case codeOutputDir != "":
+		files, err := writeCodeBlockFiles(codeOutputDir, blocks)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d code blocks to %s\n", len(files), codeOutputDir)
+	case codeConcatFile != "":
+		f, err := os.Create(codeConcatFile) //nolint:gosec // G304: output path from CLI input is expected
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", codeConcatFile, err)
+		}
+		if err := writeCodeBlocks(f, blocks); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d code blocks to %s\n", len(blocks), codeConcatFile)
+	default:
+		return writeCodeBlocks(os.Stdout, blocks)
+	}
+	return nil
+}
+
+// writeCodeBlocks writes blocks to w one after another, separated by blank lines.
+func writeCodeBlocks(w io.Writer, blocks []export.CodeBlock) error {
+	for i, block := range blocks {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, export.FormatCodeBlock(block, i+1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCodeBlockFiles writes each block to its own file in dir, named by its 1-based
+// position and language (001.go, 002.py, ...), and returns the file paths.
+func writeCodeBlockFiles(dir string, blocks []export.CodeBlock) ([]string, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	width := len(fmt.Sprint(len(blocks)))
+	if width < 3 {
+		width = 3
+	}
+
+	files := make([]string, 0, len(blocks))
+	for i, block := range blocks {
+		name := fmt.Sprintf("%0*d.%s", width, i+1, export.CodeBlockExtension(block.Language))
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(export.FormatCodeBlock(block, i+1)), 0600); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
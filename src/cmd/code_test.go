@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// createCodeTestProject writes a project with one session whose assistant messages
+// contain Go and Python code blocks, and returns the claude dir.
+func createCodeTestProject(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "projects", "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `{"uuid":"u1","sessionId":"c0de0000-0000-0000-0000-000000000001","type":"user","timestamp":"2026-02-01T10:00:00.000Z","message":"Show me code"}
+{"uuid":"a1","sessionId":"c0de0000-0000-0000-0000-000000000001","type":"assistant","timestamp":"2026-02-01T10:00:05.000Z","message":{"role":"assistant","content":[{"type":"text","text":"Go:\n` + "```go\\nfunc main() {}\\n```" + `\nPython:\n` + "```Python\\nprint(1)\\n```" + `"}]}}
+{"uuid":"a2","sessionId":"c0de0000-0000-0000-0000-000000000001","type":"assistant","timestamp":"2026-02-01T10:01:00.000Z","message":{"role":"assistant","content":[{"type":"text","text":"` + "```GO\\nvar x = 1\\n```" + `"}]}}
+`
+	if err := os.WriteFile(filepath.Join(projectDir, "c0de0000-0000-0000-0000-000000000001.jsonl"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return tmpDir
+}
+
+// saveCodeFlags restores the code command flags when the test ends.
+func saveCodeFlags(t *testing.T) {
+	t.Helper()
+	oldClaudeDir, oldSession, oldLang, oldOut, oldConcat, oldFail := claudeDir, codeSessionID, codeLang, codeOutputDir, codeConcatFile, codeFailOnEmpty
+	t.Cleanup(func() {
+		claudeDir, codeSessionID, codeLang, codeOutputDir, codeConcatFile, codeFailOnEmpty = oldClaudeDir, oldSession, oldLang, oldOut, oldConcat, oldFail
+	})
+}
+
+func TestRunCode_OutputDir(t *testing.T) {
+	saveCodeFlags(t)
+	claudeDir = createCodeTestProject(t)
+	codeSessionID = "c0de"
+	codeLang = "go"
+	codeOutputDir = filepath.Join(t.TempDir(), "snippets")
+
+	if err := runCode(codeCmd, []string{"/test/project"}); err != nil {
+		t.Fatalf("runCode() error = %v", err)
+	}
+
+	first, err := os.ReadFile(filepath.Join(codeOutputDir, "001.go"))
+	if err != nil {
+		t.Fatalf("missing 001.go: %v", err)
+	}
+	want := "// claude-history: code block 1 from message a1 at 2026-02-01T10:00:05.000Z\nfunc main() {}\n"
+	if string(first) != want {
+		t.Errorf("001.go = %q, want %q", first, want)
+	}
+
+	second, err := os.ReadFile(filepath.Join(codeOutputDir, "002.go"))
+	if err != nil {
+		t.Fatalf("missing 002.go (GO block should match --lang go): %v", err)
+	}
+	if !strings.Contains(string(second), "message a2") || !strings.Contains(string(second), "var x = 1") {
+		t.Errorf("002.go = %q", second)
+	}
+
+	files, _ := os.ReadDir(codeOutputDir)
+	if len(files) != 2 {
+		t.Errorf("wrote %d files, want 2 (python block filtered out)", len(files))
+	}
+}
+
+func TestRunCode_Concat(t *testing.T) {
+	saveCodeFlags(t)
+	claudeDir = createCodeTestProject(t)
+	codeSessionID = ""
+	codeLang = ""
+	codeConcatFile = filepath.Join(t.TempDir(), "all.txt")
+
+	if err := runCode(codeCmd, []string{"/test/project"}); err != nil {
+		t.Fatalf("runCode() error = %v", err)
+	}
+
+	content, err := os.ReadFile(codeConcatFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "// claude-history: code block 1 from message a1 at 2026-02-01T10:00:05.000Z\nfunc main() {}\n" +
+		"\n# claude-history: code block 2 from message a1 at 2026-02-01T10:00:05.000Z\nprint(1)\n" +
+		"\n// claude-history: code block 3 from message a2 at 2026-02-01T10:01:00.000Z\nvar x = 1\n"
+	if string(content) != want {
+		t.Errorf("concatenated file =\n%s\nwant:\n%s", content, want)
+	}
+}
+
+func TestRunCode_NoMatches(t *testing.T) {
+	saveCodeFlags(t)
+	claudeDir = createCodeTestProject(t)
+	codeLang = "rust"
+
+	codeFailOnEmpty = false
+	if err := runCode(codeCmd, []string{"/test/project"}); err != nil {
+		t.Errorf("no blocks without --fail-on-empty should succeed, got %v", err)
+	}
+
+	codeFailOnEmpty = true
+	if err := runCode(codeCmd, []string{"/test/project"}); exitCode(err) != exitNoMatches {
+		t.Errorf("no blocks with --fail-on-empty = %v, want exit status %d", err, exitNoMatches)
+	}
+}
+
+func TestRunCode_OutputAndConcatConflict(t *testing.T) {
+	saveCodeFlags(t)
+	codeOutputDir = "out"
+	codeConcatFile = "all.txt"
+
+	err := runCode(codeCmd, []string{"/test/project"})
+	if err == nil || !strings.Contains(err.Error(), "cannot be used together") {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/randlee/claude-history/internal/output"
+)
+
+// Color modes accepted by --color.
+const (
+	colorAuto   = "auto"   // Color when writing to a terminal
+	colorAlways = "always" // Color even when piped, e.g. into less -R
+	colorNever  = "never"  // Never color
+)
+
+var (
+	colorMode string // --color flag
+	noColor   bool   // --no-color flag, same as --color never
+)
+
+// validateColorMode checks the --color flag value.
+func validateColorMode() error {
+	switch colorMode {
+	case colorAuto, colorAlways, colorNever:
+		return nil
+	default:
+		return fmt.Errorf("invalid --color %q: must be auto, always, or never", colorMode)
+	}
+}
+
+// colorEnabled reports whether terminal output written to w should be colored.
+// --no-color, --color never, and a non-empty NO_COLOR environment variable
+// (https://no-color.org) turn color off; --color always turns it on otherwise.
+// By default (--color auto) only a terminal gets color, so output piped to a
+// file or another program stays plain.
+func colorEnabled(w io.Writer) bool {
+	if noColor || colorMode == colorNever || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if colorMode == colorAlways {
+		return true
+	}
+	return isTerminal(w)
+}
+
+// colorizer returns the terminal color scheme for output written to w, disabled
+// when colorEnabled reports false.
+func colorizer(w io.Writer) output.Colorizer {
+	return output.Colorizer{Enabled: colorEnabled(w)}
+}
+
+// isTerminal reports whether w is a terminal (a character device such as a TTY).
+// Files, pipes, and writers that are not files are not terminals.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
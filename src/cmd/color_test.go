@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// withColorFlags sets the color flags and NO_COLOR for one test.
+func withColorFlags(t *testing.T, mode string, disabled bool, noColorEnv string) {
+	t.Helper()
+	oldMode, oldNoColor := colorMode, noColor
+	t.Cleanup(func() { colorMode, noColor = oldMode, oldNoColor })
+	colorMode, noColor = mode, disabled
+	t.Setenv("NO_COLOR", noColorEnv)
+}
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		noColor  bool
+		envValue string
+		want     bool
+	}{
+		{"auto on a non-terminal", colorAuto, false, "", false},
+		{"always", colorAlways, false, "", true},
+		{"never", colorNever, false, "", false},
+		{"no-color beats always", colorAlways, true, "", false},
+		{"NO_COLOR beats always", colorAlways, false, "1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withColorFlags(t, tt.mode, tt.noColor, tt.envValue)
+			if got := colorEnabled(&bytes.Buffer{}); got != tt.want {
+				t.Errorf("colorEnabled() = %v, want %v", got, tt.want)
+			}
+			if got := colorizer(&bytes.Buffer{}).Enabled; got != tt.want {
+				t.Errorf("colorizer().Enabled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	if isTerminal(&bytes.Buffer{}) {
+		t.Error("a buffer is not a terminal")
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Error("a regular file is not a terminal")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if isTerminal(w) {
+		t.Error("a pipe is not a terminal")
+	}
+}
+
+func TestValidateColorMode(t *testing.T) {
+	old := colorMode
+	defer func() { colorMode = old }()
+
+	for _, mode := range []string{colorAuto, colorAlways, colorNever} {
+		colorMode = mode
+		if err := validateColorMode(); err != nil {
+			t.Errorf("validateColorMode(%q) error = %v", mode, err)
+		}
+	}
+	colorMode = "sometimes"
+	if err := validateColorMode(); err == nil {
+		t.Error("validateColorMode should reject an unknown mode")
+	}
+}
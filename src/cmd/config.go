@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/randlee/claude-history/pkg/config"
+)
+
+// printConfig is the --print-config flag: print the effective options and exit.
+var printConfig bool
+
+// errConfigPrinted stops a command after --print-config; Execute treats it as success.
+var errConfigPrinted = errors.New("config printed")
+
+// configSkipFlags are flags the config file cannot set and --print-config does not show.
+var configSkipFlags = map[string]bool{"help": true, "version": true, "print-config": true}
+
+// loadConfigDefaults is the root PersistentPreRunE. It applies the config file to the
+// flags of the command being run, then handles --print-config.
+func loadConfigDefaults(cmd *cobra.Command, _ []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return badInput(err)
+	}
+	if err := applyConfig(cfg, cmd); err != nil {
+		return badInput(err)
+	}
+	if err := validateColorMode(); err != nil {
+		return badInput(err)
+	}
+	if !printConfig {
+		return nil
+	}
+	if err := writeEffectiveConfig(cmd.OutOrStdout(), cfg, cmd); err != nil {
+		return err
+	}
+	// Skip the command (and its required-flag checks) without reporting an error
+	cmd.SilenceErrors = true
+	return errConfigPrinted
+}
+
+// applyConfig validates cfg against the command tree of cmd, then sets every flag of cmd
+// that the config gives a value for and the command line did not set. Top-level keys
+// set the global flags; a section named after cmd sets its own flags. Last, the filter
+// profile named by --filter is applied (see applyFilterProfile).
+func applyConfig(cfg *config.Config, cmd *cobra.Command) error {
+	if err := validateConfig(cfg, cmd.Root()); err != nil {
+		return err
+	}
+
+	set := func(flags *pflag.FlagSet, name, value, key string) error {
+		f := flags.Lookup(name)
+		if f == nil || f.Changed {
+			return nil // Not a flag of this command, or given on the command line
+		}
+		// Value.Set leaves Changed false, so the flag still counts as a default
+		if err := f.Value.Set(value); err != nil {
+			return fmt.Errorf("config %s: invalid value %q for %s: %w", cfg.Path, value, key, err)
+		}
+		return nil
+	}
+
+	for name, value := range cfg.Global {
+		if err := set(cmd.InheritedFlags(), name, value, name); err != nil {
+			return err
+		}
+	}
+	for name, value := range cfg.Commands[cmd.Name()] {
+		if err := set(cmd.LocalFlags(), name, value, cmd.Name()+"."+name); err != nil {
+			return err
+		}
+	}
+	return applyFilterProfile(cfg, cmd)
+}
+
+// validateConfig reports keys in cfg that do not name a global flag, a command, or a
+// flag of that command, so typos fail loudly instead of being ignored.
+func validateConfig(cfg *config.Config, root *cobra.Command) error {
+	for name := range cfg.Global {
+		if configSkipFlags[name] || root.PersistentFlags().Lookup(name) == nil {
+			return fmt.Errorf("config %s: unknown global option %q", cfg.Path, name)
+		}
+	}
+	for cmdName, section := range cfg.Commands {
+		sub := findCommand(root, cmdName)
+		if sub == nil {
+			return fmt.Errorf("config %s: unknown command %q", cfg.Path, cmdName)
+		}
+		for name := range section {
+			if configSkipFlags[name] || (sub.Flags().Lookup(name) == nil && sub.PersistentFlags().Lookup(name) == nil) {
+				return fmt.Errorf("config %s: unknown option %q for command %q", cfg.Path, name, cmdName)
+			}
+		}
+	}
+	return validateFilterProfiles(cfg)
+}
+
+// findCommand returns the command named name anywhere under root, or nil.
+func findCommand(root *cobra.Command, name string) *cobra.Command {
+	for _, c := range root.Commands() {
+		if c.Name() == name {
+			return c
+		}
+		if found := findCommand(c, name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// writeEffectiveConfig writes the option values cmd will run with, in the config file
+// format, so the output can be pasted into a config file.
+func writeEffectiveConfig(w io.Writer, cfg *config.Config, cmd *cobra.Command) error {
+	status := ""
+	if _, err := os.Stat(cfg.Path); err != nil {
+		status = " (not found)"
+	}
+	if _, err := fmt.Fprintf(w, "# config file: %s%s\n", cfg.Path, status); err != nil {
+		return err
+	}
+
+	doc := map[string]interface{}{}
+	cmd.InheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if !configSkipFlags[f.Name] {
+			doc[f.Name] = flagConfigValue(f)
+		}
+	})
+	if cmd != cmd.Root() {
+		local := map[string]interface{}{}
+		cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+			if !configSkipFlags[f.Name] {
+				local[f.Name] = flagConfigValue(f)
+			}
+		})
+		if len(local) > 0 {
+			doc[cmd.Name()] = local
+		}
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// flagConfigValue returns a flag's current value typed for YAML output: booleans and
+// numbers unquoted, list flags as lists, everything else as a string.
+func flagConfigValue(f *pflag.Flag) interface{} {
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		return sv.GetSlice()
+	}
+	s := f.Value.String()
+	switch {
+	case f.Value.Type() == "bool":
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case strings.HasPrefix(f.Value.Type(), "int"):
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case strings.HasPrefix(f.Value.Type(), "float"):
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+	}
+	return s
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/randlee/claude-history/pkg/config"
+)
+
+// newConfigTestTree builds a root command with a global flag and an "export" subcommand
+// with a few local flags, and parses args against the subcommand.
+func newConfigTestTree(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	root := &cobra.Command{Use: "root"}
+	root.PersistentFlags().String("claude-dir", "", "")
+	root.PersistentFlags().String("format", "", "")
+
+	sub := &cobra.Command{Use: "export", RunE: func(*cobra.Command, []string) error { return nil }}
+	sub.Flags().String("format", "html", "")
+	sub.Flags().Bool("paginate", false, "")
+	sub.Flags().Int("max-output-bytes", 0, "")
+	sub.Flags().StringSlice("fields", nil, "")
+	root.AddCommand(sub)
+
+	if err := sub.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags(%v) error = %v", args, err)
+	}
+	return sub
+}
+
+func testConfig(global map[string]string, commands map[string]map[string]string) *config.Config {
+	if global == nil {
+		global = map[string]string{}
+	}
+	if commands == nil {
+		commands = map[string]map[string]string{}
+	}
+	return &config.Config{Path: "/test/config.yaml", Global: global, Commands: commands}
+}
+
+func TestApplyConfig_SetsDefaults(t *testing.T) {
+	sub := newConfigTestTree(t)
+	cfg := testConfig(
+		map[string]string{"claude-dir": "/data/.claude"},
+		map[string]map[string]string{"export": {"format": "markdown", "paginate": "true", "fields": "uuid,type"}},
+	)
+
+	if err := applyConfig(cfg, sub); err != nil {
+		t.Fatalf("applyConfig() error = %v", err)
+	}
+
+	flags := sub.Flags()
+	if got, _ := flags.GetString("claude-dir"); got != "/data/.claude" {
+		t.Errorf("claude-dir = %q, want /data/.claude", got)
+	}
+	if got, _ := flags.GetString("format"); got != "markdown" {
+		t.Errorf("format = %q, want markdown", got)
+	}
+	if got, _ := flags.GetBool("paginate"); !got {
+		t.Error("paginate = false, want true")
+	}
+	if got, _ := flags.GetStringSlice("fields"); strings.Join(got, ",") != "uuid,type" {
+		t.Errorf("fields = %v, want [uuid type]", got)
+	}
+	if flags.Changed("format") {
+		t.Error("config values should not mark flags as changed")
+	}
+}
+
+func TestApplyConfig_FlagsWin(t *testing.T) {
+	sub := newConfigTestTree(t, "--format", "text", "--claude-dir", "/cli")
+	cfg := testConfig(
+		map[string]string{"claude-dir": "/config"},
+		map[string]map[string]string{"export": {"format": "markdown", "max-output-bytes": "100"}},
+	)
+
+	if err := applyConfig(cfg, sub); err != nil {
+		t.Fatalf("applyConfig() error = %v", err)
+	}
+
+	if got, _ := sub.Flags().GetString("format"); got != "text" {
+		t.Errorf("format = %q, want the command-line value text", got)
+	}
+	if got, _ := sub.Flags().GetString("claude-dir"); got != "/cli" {
+		t.Errorf("claude-dir = %q, want the command-line value /cli", got)
+	}
+	if got, _ := sub.Flags().GetInt("max-output-bytes"); got != 100 {
+		t.Errorf("max-output-bytes = %d, want 100 from the config", got)
+	}
+}
+
+func TestApplyConfig_GlobalKeyDoesNotSetShadowingLocalFlag(t *testing.T) {
+	sub := newConfigTestTree(t)
+	cfg := testConfig(map[string]string{"format": "json"}, nil)
+
+	if err := applyConfig(cfg, sub); err != nil {
+		t.Fatalf("applyConfig() error = %v", err)
+	}
+	if got, _ := sub.Flags().GetString("format"); got != "html" {
+		t.Errorf("export format = %q, want html (global format applies to the global flag only)", got)
+	}
+}
+
+func TestApplyConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		global   map[string]string
+		commands map[string]map[string]string
+		wantErr  string
+	}{
+		{"unknown global", map[string]string{"claud-dir": "x"}, nil, `unknown global option "claud-dir"`},
+		{"unknown command", nil, map[string]map[string]string{"exprt": {"format": "html"}}, `unknown command "exprt"`},
+		{"unknown option", nil, map[string]map[string]string{"export": {"paginat": "true"}}, `unknown option "paginat" for command "export"`},
+		{"help not settable", nil, map[string]map[string]string{"export": {"help": "true"}}, `unknown option "help"`},
+		{"invalid value", nil, map[string]map[string]string{"export": {"max-output-bytes": "lots"}}, `invalid value "lots" for export.max-output-bytes`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := newConfigTestTree(t)
+			err := applyConfig(testConfig(tt.global, tt.commands), sub)
+			if err == nil {
+				t.Fatal("applyConfig() error = nil, want error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), "/test/config.yaml") {
+				t.Errorf("error = %q, want it to name the config file", err)
+			}
+		})
+	}
+}
+
+func TestWriteEffectiveConfig(t *testing.T) {
+	sub := newConfigTestTree(t, "--paginate", "--fields", "uuid,type")
+	cfg := testConfig(
+		map[string]string{"claude-dir": "/data/.claude"},
+		map[string]map[string]string{"export": {"format": "markdown"}},
+	)
+	cfg.Path = filepath.Join(t.TempDir(), "config.yaml")
+	if err := applyConfig(cfg, sub); err != nil {
+		t.Fatalf("applyConfig() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeEffectiveConfig(&buf, cfg, sub); err != nil {
+		t.Fatalf("writeEffectiveConfig() error = %v", err)
+	}
+	out := buf.String()
+
+	want := "# config file: " + cfg.Path + " (not found)\n" +
+		"claude-dir: /data/.claude\n" +
+		"export:\n" +
+		"  fields:\n" +
+		"    - uuid\n" +
+		"    - type\n" +
+		"  format: markdown\n" +
+		"  max-output-bytes: 0\n" +
+		"  paginate: true\n"
+	if out != want {
+		t.Errorf("writeEffectiveConfig() =\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestLoadConfigDefaults_PrintConfig(t *testing.T) {
+	t.Setenv(config.EnvPath, filepath.Join(t.TempDir(), "missing.yaml"))
+	sub := newConfigTestTree(t)
+	var buf bytes.Buffer
+	sub.SetOut(&buf)
+
+	printConfig = true
+	defer func() { printConfig = false }()
+
+	err := loadConfigDefaults(sub, nil)
+	if err != errConfigPrinted {
+		t.Fatalf("loadConfigDefaults() error = %v, want errConfigPrinted", err)
+	}
+	if !sub.SilenceErrors {
+		t.Error("--print-config should silence the sentinel error")
+	}
+	if !strings.Contains(buf.String(), "format: html") {
+		t.Errorf("output missing effective export format:\n%s", buf.String())
+	}
+}
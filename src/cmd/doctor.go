@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/randlee/claude-history/internal/output"
+	"github.com/randlee/claude-history/pkg/paths"
+	"github.com/randlee/claude-history/pkg/session"
+)
+
+// Statuses of a doctor check.
+const (
+	doctorOK   = "OK"   // The check passed
+	doctorWarn = "WARN" // Something looks off, but the tool can still work
+	doctorFail = "FAIL" // The tool cannot work until this is fixed
+)
+
+// doctorCheck is the result of one doctor check.
+type doctorCheck struct {
+	Name    string // What was checked
+	Status  string // doctorOK, doctorWarn, or doctorFail
+	Message string // What was found, and what to do about it if it is not OK
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that claude-history can find and read Claude Code history",
+	Long: `Check the setup claude-history depends on and report each check as OK, WARN,
+or FAIL with what to do about it.
+
+The checks look for the Claude directory (~/.claude, or --claude-dir) and its
+projects directory, count the projects and sessions found there, parse the most
+recent session, and report the versions in use.
+
+Doctor exits with status 1 only if a check fails, meaning the tool cannot work
+as configured (for example the projects directory is missing). Warnings, such as
+finding no sessions yet, leave the exit status 0.
+
+Examples:
+  # Check the default ~/.claude directory
+  claude-history doctor
+
+  # Check another Claude directory
+  claude-history doctor --claude-dir /data/claude`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	checks := runDoctorChecks(claudeDir)
+	writeDoctorChecks(out, checks, colorizer(out))
+
+	failed := 0
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// runDoctorChecks runs the doctor checks against claudeDir ("" for the default
+// ~/.claude) and returns their results in order. Checks that depend on a failed one
+// are reported as skipped warnings rather than left out, so the output always lists
+// the same checks.
+func runDoctorChecks(claudeDir string) []doctorCheck {
+	checks := []doctorCheck{checkVersions()}
+
+	dir := claudeDir
+	if dir == "" {
+		defaultDir, err := paths.DefaultClaudeDir()
+		if err != nil {
+			return append(checks, doctorCheck{"Claude directory", doctorFail,
+				fmt.Sprintf("cannot find the home directory (%v); pass --claude-dir", err)})
+		}
+		dir = defaultDir
+	}
+
+	projectsDir, err := paths.ProjectsDir(dir)
+	if err != nil {
+		return append(checks, doctorCheck{"Projects directory", doctorFail, err.Error()})
+	}
+	projectsCheck := checkProjectsDir(projectsDir)
+	checks = append(checks, projectsCheck)
+	if projectsCheck.Status == doctorFail {
+		return append(checks,
+			doctorCheck{"Projects", doctorWarn, "skipped: the projects directory check failed"},
+			doctorCheck{"Sessions", doctorWarn, "skipped: the projects directory check failed"},
+			doctorCheck{"Sample session", doctorWarn, "skipped: the projects directory check failed"})
+	}
+
+	projects, err := paths.ListProjects(dir)
+	if err != nil {
+		return append(checks, doctorCheck{"Projects", doctorFail, fmt.Sprintf("cannot list projects: %v", err)})
+	}
+	checks = append(checks, checkProjectCount(projectsDir, len(projects)))
+
+	sessionCheck, newest := checkSessions(projects)
+	checks = append(checks, sessionCheck)
+	return append(checks, checkSampleSession(newest))
+}
+
+// checkVersions reports the claude-history version and the Go runtime it was built with.
+func checkVersions() doctorCheck {
+	info := versionInfo
+	if info == "" {
+		info = buildVersion
+	}
+	return doctorCheck{"Versions", doctorOK,
+		fmt.Sprintf("claude-history %s, %s %s/%s", info, runtime.Version(), runtime.GOOS, runtime.GOARCH)}
+}
+
+// checkProjectsDir checks that projectsDir is a readable directory.
+func checkProjectsDir(projectsDir string) doctorCheck {
+	const name = "Projects directory"
+	info, err := os.Stat(projectsDir)
+	if os.IsNotExist(err) {
+		return doctorCheck{name, doctorFail, fmt.Sprintf(
+			"%s does not exist; run Claude Code once to create it, or point --claude-dir at the directory that contains projects/", projectsDir)}
+	}
+	if err != nil {
+		return doctorCheck{name, doctorFail, fmt.Sprintf("cannot access %s: %v", projectsDir, err)}
+	}
+	if !info.IsDir() {
+		return doctorCheck{name, doctorFail, fmt.Sprintf("%s is not a directory; check --claude-dir", projectsDir)}
+	}
+	if _, err := os.ReadDir(projectsDir); err != nil {
+		return doctorCheck{name, doctorFail, fmt.Sprintf("cannot read %s: %v; check its permissions", projectsDir, err)}
+	}
+	return doctorCheck{name, doctorOK, projectsDir}
+}
+
+// checkProjectCount reports how many projects were found in projectsDir.
+func checkProjectCount(projectsDir string, count int) doctorCheck {
+	if count == 0 {
+		return doctorCheck{"Projects", doctorWarn, fmt.Sprintf(
+			"no projects found in %s; use Claude Code in a project first, or check --claude-dir", projectsDir)}
+	}
+	return doctorCheck{"Projects", doctorOK, fmt.Sprintf("%d found", count)}
+}
+
+// checkSessions counts the sessions in projects and returns the path of the most
+// recently modified session file ("" if there are none). Projects whose directory
+// cannot be read are counted in the message as a warning.
+func checkSessions(projects map[string]string) (doctorCheck, string) {
+	const name = "Sessions"
+	var (
+		count      int
+		unreadable int
+		newest     string
+		newestMod  int64
+	)
+	for _, projectDir := range projects {
+		files, err := paths.ListSessionFiles(projectDir)
+		if err != nil {
+			unreadable++
+			continue
+		}
+		for _, path := range files {
+			count++
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if mod := info.ModTime().UnixNano(); newest == "" || mod > newestMod {
+				newest, newestMod = path, mod
+			}
+		}
+	}
+
+	switch {
+	case unreadable > 0:
+		return doctorCheck{name, doctorWarn, fmt.Sprintf(
+			"%d found; %d project directories could not be read, check their permissions", count, unreadable)}, newest
+	case count == 0:
+		return doctorCheck{name, doctorWarn, "no sessions found; start a Claude Code session, then run doctor again"}, newest
+	default:
+		return doctorCheck{name, doctorOK, fmt.Sprintf("%d found in %d projects", count, len(projects))}, newest
+	}
+}
+
+// checkSampleSession parses the session file at path ("" if there is none).
+func checkSampleSession(path string) doctorCheck {
+	const name = "Sample session"
+	if path == "" {
+		return doctorCheck{name, doctorWarn, "skipped: no sessions to parse"}
+	}
+	entries, err := session.ReadSession(path)
+	if err != nil {
+		return doctorCheck{name, doctorFail, fmt.Sprintf("cannot read %s: %v; check its permissions", path, err)}
+	}
+	if len(entries) == 0 {
+		return doctorCheck{name, doctorWarn, fmt.Sprintf(
+			"no entries could be parsed from %s; it may be empty, or written by a newer Claude Code (see claude-history version --check)", path)}
+	}
+	return doctorCheck{name, doctorOK, fmt.Sprintf("parsed %d entries from %s", len(entries), path)}
+}
+
+// writeDoctorChecks writes one line per check, its status colored by c.
+func writeDoctorChecks(w io.Writer, checks []doctorCheck, c output.Colorizer) {
+	for _, check := range checks {
+		status := "[" + check.Status + "]"
+		padding := strings.Repeat(" ", len("[WARN]")-len(status)+1)
+		switch check.Status {
+		case doctorFail:
+			status = c.Error(status)
+		case doctorWarn:
+			status = c.Warning(status)
+		}
+		fmt.Fprintf(w, "%s%s%s: %s\n", status, padding, check.Name, check.Message)
+	}
+}
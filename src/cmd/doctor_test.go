@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/randlee/claude-history/internal/output"
+)
+
+// doctorStatuses returns the status of each check by name.
+func doctorStatuses(checks []doctorCheck) map[string]string {
+	statuses := make(map[string]string)
+	for _, c := range checks {
+		statuses[c.Name] = c.Status
+	}
+	return statuses
+}
+
+func TestRunDoctorChecks_Healthy(t *testing.T) {
+	dir := createScriptTestProject(t)
+
+	checks := runDoctorChecks(dir)
+	for _, c := range checks {
+		if c.Status != doctorOK {
+			t.Errorf("check %q = %s (%s), want OK", c.Name, c.Status, c.Message)
+		}
+	}
+
+	statuses := doctorStatuses(checks)
+	for _, name := range []string{"Versions", "Projects directory", "Projects", "Sessions", "Sample session"} {
+		if _, ok := statuses[name]; !ok {
+			t.Errorf("missing check %q", name)
+		}
+	}
+	for _, c := range checks {
+		if c.Name == "Sample session" && !strings.Contains(c.Message, "parsed 5 entries") {
+			t.Errorf("sample session message = %q, want the entry count", c.Message)
+		}
+		if c.Name == "Sessions" && c.Message != "1 found in 1 projects" {
+			t.Errorf("sessions message = %q", c.Message)
+		}
+	}
+}
+
+func TestRunDoctorChecks_MissingProjectsDir(t *testing.T) {
+	checks := runDoctorChecks(t.TempDir())
+
+	statuses := doctorStatuses(checks)
+	if statuses["Projects directory"] != doctorFail {
+		t.Errorf("projects directory = %s, want FAIL", statuses["Projects directory"])
+	}
+	// The dependent checks are still listed, as skipped warnings
+	for _, name := range []string{"Projects", "Sessions", "Sample session"} {
+		if statuses[name] != doctorWarn {
+			t.Errorf("check %q = %s, want WARN", name, statuses[name])
+		}
+	}
+	for _, c := range checks {
+		if c.Name == "Projects directory" && !strings.Contains(c.Message, "--claude-dir") {
+			t.Errorf("message %q should say how to fix it", c.Message)
+		}
+	}
+}
+
+func TestRunDoctorChecks_ProjectsDirIsFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "projects"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := doctorStatuses(runDoctorChecks(dir))
+	if statuses["Projects directory"] != doctorFail {
+		t.Errorf("projects directory = %s, want FAIL", statuses["Projects directory"])
+	}
+}
+
+func TestRunDoctorChecks_NoSessions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "projects", "-test-project"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := doctorStatuses(runDoctorChecks(dir))
+	want := map[string]string{
+		"Projects directory": doctorOK,
+		"Projects":           doctorOK,
+		"Sessions":           doctorWarn,
+		"Sample session":     doctorWarn,
+	}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("check %q = %s, want %s", name, statuses[name], status)
+		}
+	}
+}
+
+func TestRunDoctorChecks_NoProjects(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "projects"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := doctorStatuses(runDoctorChecks(dir))
+	if statuses["Projects"] != doctorWarn {
+		t.Errorf("projects = %s, want WARN", statuses["Projects"])
+	}
+}
+
+func TestCheckSampleSession_NoEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "5c0e0000-0000-0000-0000-000000000001.jsonl")
+	if err := os.WriteFile(path, []byte("not json\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := checkSampleSession(path); got.Status != doctorWarn {
+		t.Errorf("status = %s (%s), want WARN", got.Status, got.Message)
+	}
+}
+
+func TestCheckSessions_PicksNewest(t *testing.T) {
+	dir := createScriptTestProject(t)
+	projectDir := filepath.Join(dir, "projects", "-test-project")
+	newer := filepath.Join(projectDir, "5c0e0000-0000-0000-0000-000000000002.jsonl")
+	if err := os.WriteFile(newer, []byte(`{"uuid":"u1","type":"user","message":"hi"}`+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	oldTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	older := filepath.Join(projectDir, "5c0e0000-0000-0000-0000-000000000001.jsonl")
+	if err := os.Chtimes(older, oldTime, oldTime); err != nil {
+		t.Fatal(err)
+	}
+
+	check, newest := checkSessions(map[string]string{"-test-project": projectDir})
+	if check.Status != doctorOK || check.Message != "2 found in 1 projects" {
+		t.Errorf("check = %+v", check)
+	}
+	if newest != newer {
+		t.Errorf("newest = %q, want %q", newest, newer)
+	}
+}
+
+func TestRunDoctor_ExitStatus(t *testing.T) {
+	oldClaudeDir := claudeDir
+	t.Cleanup(func() {
+		claudeDir = oldClaudeDir
+		doctorCmd.SetOut(nil)
+	})
+
+	// Warnings only: no error
+	claudeDir = t.TempDir()
+	if err := os.MkdirAll(filepath.Join(claudeDir, "projects"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	doctorCmd.SetOut(&buf)
+	if err := runDoctor(doctorCmd, nil); err != nil {
+		t.Errorf("runDoctor() with warnings error = %v, want nil", err)
+	}
+	if !strings.Contains(buf.String(), "[WARN] Sessions: ") {
+		t.Errorf("output missing sessions warning:\n%s", buf.String())
+	}
+
+	// A missing projects directory fails
+	claudeDir = t.TempDir()
+	buf.Reset()
+	err := runDoctor(doctorCmd, nil)
+	if err == nil {
+		t.Fatal("runDoctor() with a missing projects directory should fail")
+	}
+	if exitCode(err) != exitError {
+		t.Errorf("exitCode = %d, want %d", exitCode(err), exitError)
+	}
+	if !strings.Contains(buf.String(), "[FAIL] Projects directory: ") {
+		t.Errorf("output missing failure:\n%s", buf.String())
+	}
+}
+
+func TestWriteDoctorChecks(t *testing.T) {
+	checks := []doctorCheck{
+		{"Versions", doctorOK, "claude-history 1.0"},
+		{"Sessions", doctorWarn, "no sessions found"},
+		{"Projects directory", doctorFail, "missing"},
+	}
+
+	var buf bytes.Buffer
+	writeDoctorChecks(&buf, checks, output.Colorizer{})
+	want := "[OK]   Versions: claude-history 1.0\n" +
+		"[WARN] Sessions: no sessions found\n" +
+		"[FAIL] Projects directory: missing\n"
+	if buf.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	writeDoctorChecks(&buf, checks, output.Colorizer{Enabled: true})
+	if !strings.Contains(buf.String(), "\x1b[") {
+		t.Error("expected colored statuses when enabled")
+	}
+}
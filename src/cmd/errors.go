@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/randlee/claude-history/internal/output"
+	"github.com/randlee/claude-history/pkg/paths"
+	"github.com/randlee/claude-history/pkg/resolver"
+	"github.com/randlee/claude-history/pkg/session"
+)
+
+// Number of characters of a tool call's input and of its error shown per line in the
+// terminal view. JSON output always carries the full text.
+const (
+	toolErrorInputDisplayLen = 100
+	toolErrorTextDisplayLen  = 200
+)
+
+var (
+	errorsSessionID   string
+	errorsJSON        bool
+	errorsFailOnEmpty bool
+)
+
+var errorsCmd = &cobra.Command{
+	Use:   "errors <project-path>",
+	Short: "List the tool calls of a session that returned errors",
+	Long: `List every tool call in a session whose result was an error, in order, with
+the tool's name, a summary of its input (such as the command or file path), and
+the error text.
+
+The terminal view prints each error on two lines, shortened to fit. Use --json
+for the full input and error text, along with the UUID of the message that made
+each call, for drilling into the session with query or export. Errors whose call
+is no longer in the session (e.g. lost to compaction) are listed without it.
+
+Examples:
+  # Tool errors of the most recent session
+  claude-history errors /path/to/project
+
+  # A specific session, as JSON for a reliability report
+  claude-history errors /path/to/project --session abc123 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runErrors,
+}
+
+func init() {
+	rootCmd.AddCommand(errorsCmd)
+
+	errorsCmd.Flags().StringVar(&errorsSessionID, "session", "", "Session ID (default: most recent session)")
+	errorsCmd.Flags().BoolVar(&errorsJSON, "json", false, "Output the errors as JSON, with full input and error text")
+	errorsCmd.Flags().BoolVar(&errorsFailOnEmpty, "fail-on-empty", false, "Exit with status 2 if no tool call returned an error")
+}
+
+// toolErrorRecord is one tool error as written by errors --json.
+type toolErrorRecord struct {
+	EntryUUID    string         `json:"entryUuid"`
+	ResultUUID   string         `json:"resultUuid"`
+	Timestamp    string         `json:"timestamp"`
+	ToolUseID    string         `json:"toolUseId"`
+	ToolName     string         `json:"toolName"`
+	Input        map[string]any `json:"input"`
+	InputSummary string         `json:"inputSummary"`
+	Error        string         `json:"error"`
+}
+
+func runErrors(cmd *cobra.Command, args []string) error {
+	projectPath := args[0]
+	projectDir, err := paths.ProjectDir(claudeDir, projectPath)
+	if err != nil {
+		return err
+	}
+	if !paths.Exists(projectDir) {
+		return fmt.Errorf("project not found: %s", projectPath)
+	}
+
+	sessionID := errorsSessionID
+	if sessionID == "" {
+		sessions, err := session.ListSessions(projectDir)
+		if err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			return fmt.Errorf("no sessions found in project")
+		}
+		sessionID = sessions[0].ID
+	} else {
+		resolvedSessionID, err := resolver.ResolveSessionID(projectDir, sessionID)
+		if err != nil {
+			return fmt.Errorf("failed to resolve session ID: %w", err)
+		}
+		sessionID = resolvedSessionID
+	}
+
+	entries, err := session.ReadSession(paths.JSONLFile(filepath.Join(projectDir, sessionID)))
+	if err != nil {
+		return fmt.Errorf("failed to read session: %w", err)
+	}
+
+	toolErrors := session.CollectToolErrors(entries)
+	if len(toolErrors) == 0 {
+		return noMatches("No tool errors found", errorsFailOnEmpty)
+	}
+
+	if errorsJSON || output.ParseFormat(format) == output.FormatJSON {
+		records := make([]toolErrorRecord, len(toolErrors))
+		for i, e := range toolErrors {
+			records[i] = toolErrorRecord(e)
+		}
+		return output.WriteJSON(cmd.OutOrStdout(), records)
+	}
+	return writeToolErrors(cmd.OutOrStdout(), toolErrors)
+}
+
+// writeToolErrors writes two lines per error: the tool and its input summary, with the
+// message UUID and timestamp of the call, then the error text indented below. Both are
+// shortened to a single line (see displayPrompt).
+func writeToolErrors(w io.Writer, toolErrors []session.ToolError) error {
+	for _, e := range toolErrors {
+		name, uuid := e.ToolName, e.EntryUUID
+		if name == "" {
+			name = "(unknown tool)"
+		}
+		if uuid == "" {
+			uuid = e.ResultUUID
+		}
+
+		line := name
+		if e.InputSummary != "" {
+			line += "  " + displayPrompt(e.InputSummary, toolErrorInputDisplayLen)
+		}
+		if _, err := fmt.Fprintf(w, "%s  (message %s at %s)\n    %s\n", line, uuid, e.Timestamp, displayPrompt(e.Error, toolErrorTextDisplayLen)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// saveErrorsFlags restores the errors command flags when the test ends.
+func saveErrorsFlags(t *testing.T) {
+	t.Helper()
+	oldClaudeDir, oldFormat, oldSession, oldJSON, oldFail := claudeDir, format, errorsSessionID, errorsJSON, errorsFailOnEmpty
+	t.Cleanup(func() {
+		claudeDir, format, errorsSessionID, errorsJSON, errorsFailOnEmpty = oldClaudeDir, oldFormat, oldSession, oldJSON, oldFail
+		errorsCmd.SetOut(nil)
+	})
+}
+
+// runErrorsOutput runs the errors command on the test project and returns its output.
+func runErrorsOutput(t *testing.T) string {
+	t.Helper()
+	var buf bytes.Buffer
+	errorsCmd.SetOut(&buf)
+	if err := runErrors(errorsCmd, []string{"/test/project"}); err != nil {
+		t.Fatalf("runErrors() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestRunErrors(t *testing.T) {
+	saveErrorsFlags(t)
+	claudeDir = createScriptTestProject(t)
+	format, errorsSessionID, errorsJSON = "", "5c0e", false
+
+	got := runErrorsOutput(t)
+	want := "Bash  make build  (message a1 at 2026-02-01T10:00:05.000Z)\n    make: *** No rule\n"
+	if got != want {
+		t.Errorf("errors output = %q, want %q", got, want)
+	}
+}
+
+func TestRunErrors_JSON(t *testing.T) {
+	saveErrorsFlags(t)
+	claudeDir = createScriptTestProject(t)
+	format, errorsSessionID, errorsJSON = "", "", true
+
+	var records []toolErrorRecord
+	if err := json.Unmarshal([]byte(runErrorsOutput(t)), &records); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d errors, want 1: %+v", len(records), records)
+	}
+	r := records[0]
+	if r.EntryUUID != "a1" || r.ResultUUID != "r1" || r.ToolUseID != "t1" || r.ToolName != "Bash" {
+		t.Errorf("record = %+v, want the call of message a1 answered by r1", r)
+	}
+	if r.Input["command"] != "make build" || r.Input["description"] != "Build the\nproject" {
+		t.Errorf("Input = %v, want the full call input", r.Input)
+	}
+	if r.Error != "make: *** No rule" {
+		t.Errorf("Error = %q", r.Error)
+	}
+}
+
+func TestRunErrors_JSONFormatFlag(t *testing.T) {
+	saveErrorsFlags(t)
+	claudeDir = createScriptTestProject(t)
+	format, errorsSessionID, errorsJSON = "json", "", false
+
+	if got := runErrorsOutput(t); !strings.Contains(got, `"entryUuid": "a1"`) {
+		t.Errorf("--format json should write JSON, got %q", got)
+	}
+}
+
+func TestRunErrors_NoErrors(t *testing.T) {
+	saveErrorsFlags(t)
+	claudeDir = createPromptsTestProject(t)
+	format, errorsSessionID, errorsJSON, errorsFailOnEmpty = "", "aaaa", false, true
+
+	if err := runErrors(errorsCmd, []string{"/test/project"}); exitCode(err) != exitNoMatches {
+		t.Errorf("session without tool errors = %v, want no-matches exit", err)
+	}
+}
+
+func TestRunErrors_ProjectNotFound(t *testing.T) {
+	saveErrorsFlags(t)
+	claudeDir = t.TempDir()
+
+	err := runErrors(errorsCmd, []string{"/test/project"})
+	if err == nil || !strings.Contains(err.Error(), "project not found") {
+		t.Errorf("runErrors() = %v, want project not found", err)
+	}
+}
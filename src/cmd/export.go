@@ -10,7 +10,6 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/randlee/claude-history/internal/jsonl"
 	"github.com/randlee/claude-history/pkg/agent"
 	"github.com/randlee/claude-history/pkg/export"
 	"github.com/randlee/claude-history/pkg/models"
@@ -20,9 +19,72 @@ import (
 )
 
 var (
-	exportSessionID string
-	exportOutputDir string
-	exportFormat    string
+	exportSessionIDs []string
+	exportLatest     bool
+	exportSelect     bool
+	exportNoInteract bool
+	exportOutputDir  string
+	exportFormat     string
+	exportFields     []string
+	exportProjDir    string
+
+	exportRelativeTimes bool
+	exportPaginate      bool
+	exportTimeline      bool
+	exportResume        bool
+	exportMaxOutput     int
+	exportSortAgents    string
+	exportTemplate      string
+	exportNoStats       bool
+	exportFrontMatter   bool
+	exportWrap          int
+	exportAgentID       string
+	exportSummaryLen    int
+	exportCollapseCode  int
+	exportCombineTools  bool
+	exportGroupParallel bool
+	exportLocale        string
+	exportHighlight     string
+	exportHighlightCase bool
+	exportIncludeRaw    bool
+	exportPreamble      bool
+	exportFirstPrompt   bool
+	exportFilesTouched  bool
+	exportHideResults   bool
+	exportGroupByTool   bool
+	exportCollapseReads bool
+	exportLineNumbers   bool
+	exportAnnotations   string
+	exportStripIDs      bool
+	exportDaySeparators bool
+	exportShowGaps      bool
+	exportGapThreshold  time.Duration
+	exportIdleThreshold time.Duration
+	exportShowAll       bool
+	exportShowLegend    bool
+	exportSidebar       bool
+	exportSearchIndex   bool
+	exportAvatars       []string // --avatar flags, each type=initials
+	exportAvatarImages  []string // --avatar-image flags, each type=url
+	exportTypeColors    []string // --type-color flags, each type=color
+	exportEncoding      string
+	exportEmoji         bool
+	exportSafeHTML      bool
+	exportReplay        bool
+	exportReplayDelay   time.Duration
+	exportInspector     bool
+	exportPageSize      int
+	exportNoIcons       bool
+	exportMarkdownTools []string
+	exportExpandTools   []string
+	exportLimitAgents   int
+	exportZip           bool
+	exportTimezone      string
+	exportNoJS          bool
+	exportCompact       bool
+	exportThreadOrder   bool
+	exportFilterProfile string                 // --filter profile selecting the exported entries
+	exportFilter        *session.FilterOptions // Built from the profile in runExport; nil without --filter
 )
 
 var exportCmd = &cobra.Command{
@@ -39,15 +101,207 @@ HTML format creates a standalone folder with:
 
 JSONL format copies only the source files.
 
+With --zip the export is packaged as a single .zip archive instead of a folder,
+with the same layout inside (so the HTML works once extracted). --output then
+names the archive; "--output -" streams it to stdout.
+
+Other formats (markdown, json, text, csv, ipynb, vtt) write a single conversation.<ext>
+document alongside the source files. ipynb writes a Jupyter notebook: prose in
+markdown cells, fenced code in code cells, and tool output in raw cells. vtt writes
+a WebVTT timed transcript for overlaying onto a screen recording: one cue per
+message, timed from the start of the session.
+
 Examples:
   # Export to HTML (default format)
   claude-history export /path/to/project --session abc123
 
+  # Export the session modified most recently (e.g. the one still running)
+  claude-history export /path/to/project --latest
+
+  # Pick the session to export from a list, most recent first
+  claude-history export /path/to/project --select
+
   # Export to specific folder
   claude-history export /path/to/project --session abc123 --output ./my-export/
 
+  # Export two sessions into subdirectories of ./exports, with an index.html linking them
+  claude-history export /path/to/project --session abc123 --session def456 --output ./exports/
+
   # Export just JSONL (smaller, for backup/restore)
-  claude-history export /path/to/project --session abc123 --format jsonl`,
+  claude-history export /path/to/project --session abc123 --format jsonl
+
+  # Export as a markdown document
+  claude-history export /path/to/project --session abc123 --format markdown
+
+  # Replay a session as a Jupyter notebook
+  claude-history export /path/to/project --session abc123 --format ipynb
+
+  # Caption a screen recording of a session with its messages
+  claude-history export /path/to/project --session abc123 --format vtt
+
+  # Show "5 minutes ago" style timestamps in the HTML
+  claude-history export /path/to/project --session abc123 --relative-times
+
+  # Add print page breaks for saving as PDF from the browser
+  claude-history export /path/to/project --session abc123 --paginate
+
+  # Include a timeline of when each subagent ran
+  claude-history export /path/to/project --session abc123 --timeline
+
+  # List the busiest subagents first
+  claude-history export /path/to/project --session abc123 --sort-agents entries
+
+  # Use a custom page layout (an html/template file; see templates/layout.html)
+  claude-history export /path/to/project --session abc123 --template ./my-layout.html
+
+  # Show full commands and paths in tool headers on wide screens
+  claude-history export /path/to/project --session abc123 --summary-length 0
+
+  # Show every code block expanded, however long
+  claude-history export /path/to/project --session abc123 --collapse-code-lines 0
+
+  # Share an export focused on a topic, with every mention pre-highlighted
+  claude-history export /path/to/project --session abc123 --highlight goroutine --highlight-ignore-case
+
+  # Format statistics numbers and durations for a German report
+  claude-history export /path/to/project --session abc123 --format markdown --locale de
+
+  # Show each assistant turn's text and tool calls in a single bubble
+  claude-history export /path/to/project --session abc123 --combine-tool-messages
+
+  # Group the tool calls a message made in parallel under one header
+  claude-history export /path/to/project --session abc123 --group-parallel-tools
+
+  # Explain the message colors and tool styling in the page footer
+  claude-history export /path/to/project --session abc123 --show-legend
+
+  # List the main session and every subagent in a sidebar, to jump between them
+  claude-history export /path/to/project --session abc123 --sidebar
+
+  # Embed a search index so search stays fast on a long session
+  claude-history export /path/to/project --session abc123 --search-index
+
+  # Show initials and an image in the avatars instead of the built-in letters
+  claude-history export /path/to/project --session abc123 --avatar user=RL \
+    --avatar-image assistant=https://example.com/claude.png
+
+  # Tone down system messages and color user messages indigo
+  claude-history export /path/to/project --session abc123 --type-color system=gray --type-color user=#6366f1
+
+  # Show tool output that a command wrote in Latin-1 instead of as replacement characters
+  claude-history export /path/to/project --session abc123 --encoding windows-1252
+
+  # Show :rocket: style shortcodes in assistant messages as emoji
+  claude-history export /path/to/project --session abc123 --emoji-shortcodes
+
+  # Render <br>, <sub>, <sup> and <kbd> in assistant messages instead of escaping them
+  claude-history export /path/to/project --session abc123 --allow-safe-html
+
+  # Put a date header between the days of a session resumed over several days,
+  # splitting days at midnight New York time
+  claude-history export /path/to/project --session abc123 --day-separators --timezone America/New_York
+
+  # Show the system prompt and other context the session starts with
+  claude-history export /path/to/project --session abc123 --include-preamble
+
+  # Show replies after the messages they answer where the file order has them first
+  claude-history export /path/to/project --session abc123 --thread-order
+
+  # Recall what a session was about from a card with its first prompt at the top
+  claude-history export /path/to/project --session abc123 --show-first-prompt
+
+  # Review a session's changes from a list of the files it modified
+  claude-history export /path/to/project --session abc123 --files-touched
+
+  # Read just the conversation: tool calls without their (noisy) output
+  claude-history export /path/to/project --session abc123 --hide-tool-results
+
+  # Also write tools.html, listing the tool calls grouped by tool
+  claude-history export /path/to/project --session abc123 --group-by-tool
+
+  # Collapse files the agent read again under "re-read (Nx)" groups
+  claude-history export /path/to/project --session abc123 --collapse-repeats
+
+  # Number the lines of Read and Bash output, each number linking to its line
+  claude-history export /path/to/project --session abc123 --line-numbers
+
+  # Show reviewers' notes from an annotations file on the messages they annotate
+  claude-history export /path/to/project --session abc123 --annotations review.json
+
+  # Replace session, agent, message and tool IDs with labels like agent-1 before sharing
+  claude-history export /path/to/project --session abc123 --strip-uuids
+
+  # Write smaller files by leaving out the indentation kept for readability
+  claude-history export /path/to/project --session abc123 --compact
+
+  # Export only the entries matching the filter profile bash-errors from the config file
+  claude-history export /path/to/project --session abc123 --filter bash-errors
+
+  # Mark pauses of more than 10 minutes between messages
+  claude-history export /path/to/project --session abc123 --show-gaps --gap-threshold 10m
+
+  # Count pauses of up to 20 minutes as active time in the header's duration
+  claude-history export /path/to/project --session abc123 --idle-threshold 20m
+
+  # Add a Play button that reveals the messages one at a time, for a demo
+  claude-history export /path/to/project --session abc123 --replay --replay-delay 2s
+
+  # Archive a page that works without JavaScript, with subagent conversations inlined
+  claude-history export /path/to/project --session abc123 --no-js
+
+  # Debug a session: also show the empty and system entries normally hidden
+  claude-history export /path/to/project --session abc123 --show-all
+
+  # Debug the exporter: add a "🔧 raw" toggle showing each entry's original JSON
+  claude-history export /path/to/project --session abc123 --debug-inspector
+
+  # Split a long session into pages of 200 messages (search covers one page)
+  claude-history export /path/to/project --session abc123 --page-size 200
+
+  # Plain tool call headers, without the tool icons
+  claude-history export /path/to/project --session abc123 --no-icons
+
+  # Only render the 20 largest subagents of a session that spawned hundreds
+  claude-history export /path/to/project --session abc123 --limit-agents 20
+
+  # Render WebFetch and WebSearch results as markdown instead of plain text
+  claude-history export /path/to/project --session abc123 --markdown-results WebFetch,WebSearch
+
+  # Open Edit and Bash calls on load, leaving other tool calls collapsed
+  claude-history export /path/to/project --session abc123 --expand-tools Edit,Bash
+
+  # Link every message to its line in the exported source JSONL for auditing
+  claude-history export /path/to/project --session abc123 --include-raw
+
+  # Keep the HTML small by truncating large tool outputs to 64KB
+  claude-history export /path/to/project --session abc123 --max-output-bytes 65536
+
+  # Finish an interrupted export, reusing the source files already copied
+  claude-history export /path/to/project --session abc123 --output ./my-export/ --resume
+
+  # Package the export as a single portable archive
+  claude-history export /path/to/project --session abc123 --zip --output session.zip
+
+  # Stream the archive to another program
+  claude-history export /path/to/project --session abc123 --output - | ssh host 'cat > session.zip'
+
+  # Export only one subagent (and the agents it spawned) as a standalone page
+  claude-history export /path/to/project --session abc123 --agent def456
+
+  # Export from a project directory whose name the project path does not encode to
+  claude-history export --project-dir -Users-me-my-app --session abc123
+
+  # Export a plain-text transcript without the trailing statistics block
+  claude-history export /path/to/project --session abc123 --format text --no-stats
+
+  # Export markdown with YAML front matter for a static-site generator such as Hugo
+  claude-history export /path/to/project --session abc123 --format markdown --front-matter
+
+  # Export a plain-text transcript wrapped at 80 columns
+  claude-history export /path/to/project --session abc123 --format text --wrap 80
+
+  # Export selected columns of each tool call as CSV
+  claude-history export /path/to/project --session abc123 --format csv --fields uuid,timestamp,tool`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runExport,
 }
@@ -55,10 +309,70 @@ Examples:
 func init() {
 	rootCmd.AddCommand(exportCmd)
 
-	exportCmd.Flags().StringVarP(&exportSessionID, "session", "s", "", "Session ID (required)")
-	exportCmd.Flags().StringVarP(&exportOutputDir, "output", "o", "", "Output directory (auto-generated if not specified)")
-	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "html", "Export format: html or jsonl")
-	_ = exportCmd.MarkFlagRequired("session")
+	exportCmd.Flags().StringArrayVarP(&exportSessionIDs, "session", "s", nil, "Session ID; repeat to export several sessions into subdirectories (required unless --latest or --select)")
+	exportCmd.Flags().BoolVar(&exportLatest, "latest", false, "Export the most recently modified session")
+	exportCmd.Flags().BoolVar(&exportSelect, "select", false, "Choose the session to export from a numbered list, most recent first")
+	exportCmd.Flags().BoolVar(&exportNoInteract, "no-interactive", false, "With --select, read a plain number instead of offering filtering (the default when stdin is not a terminal)")
+	exportCmd.Flags().StringVarP(&exportOutputDir, "output", "o", "", "Output directory, or archive file with --zip; - streams a zip to stdout (auto-generated if not specified)")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "html", "Export format: jsonl, "+strings.Join(export.ExporterNames(), ", "))
+	exportCmd.Flags().StringSliceVar(&exportFields, "fields", nil, "Comma-separated fields to include (json and csv formats only)")
+	exportCmd.Flags().BoolVar(&exportRelativeTimes, "relative-times", false, "Show relative message times, with the absolute time on hover (html format only)")
+	exportCmd.Flags().BoolVar(&exportPaginate, "paginate", false, "Insert page breaks for printing to PDF, keeping one file; see --page-size to split into files (html format only)")
+	exportCmd.Flags().BoolVar(&exportTimeline, "timeline", false, "Add a timeline panel of subagent activity (html format only)")
+	exportCmd.Flags().IntVar(&exportMaxOutput, "max-output-bytes", 0, "Truncate tool output beyond this many bytes (html format only, 0 = no limit)")
+	exportCmd.Flags().StringVar(&exportProjDir, "project-dir", "", "Exact encoded project directory name in ~/.claude/projects, overriding the one derived from the project path")
+	exportCmd.Flags().StringVar(&exportAgentID, "agent", "", "Export only this subagent and its nested subagents (ID or prefix)")
+	exportCmd.Flags().StringVar(&exportSortAgents, "sort-agents", "spawn", "Order subagents by: spawn (spawn time) or entries (entry count)")
+	exportCmd.Flags().StringVar(&exportTemplate, "template", "", "Custom html/template file for the page layout (html format only)")
+	exportCmd.Flags().BoolVar(&exportNoStats, "no-stats", false, "Omit the session statistics block (markdown and text formats only)")
+	exportCmd.Flags().BoolVar(&exportFrontMatter, "front-matter", false, "Start with YAML front matter: session ID, project, date, duration and tool tags (markdown format only)")
+	exportCmd.Flags().IntVar(&exportWrap, "wrap", 0, "Wrap message text at this column, keeping code and URLs whole (markdown and text formats only, 0 = no wrapping)")
+	exportCmd.Flags().IntVar(&exportSummaryLen, "summary-length", export.DefaultSummaryMaxLen, "Truncate inline tool summaries to this many characters (html format only, 0 = no limit)")
+	exportCmd.Flags().IntVar(&exportCollapseCode, "collapse-code-lines", export.DefaultCollapseCodeLines, "Collapse code blocks longer than this many lines behind a line-count summary (html format only, 0 = never)")
+	exportCmd.Flags().StringVar(&exportHighlight, "highlight", "", "Pre-mark every occurrence of this term in message text (html format only)")
+	exportCmd.Flags().BoolVar(&exportHighlightCase, "highlight-ignore-case", false, "Match --highlight case-insensitively")
+	exportCmd.Flags().StringVar(&exportLocale, "locale", "", "Locale for numbers and durations in the session statistics (e.g. de, fr, ja)")
+	exportCmd.Flags().BoolVar(&exportCombineTools, "combine-tool-messages", false, "Show an assistant turn's text and tool calls in one bubble (html format only)")
+	exportCmd.Flags().BoolVar(&exportGroupParallel, "group-parallel-tools", false, "Show the tool calls one message made at once under a \"Parallel tools (N)\" header (html format only)")
+	exportCmd.Flags().BoolVar(&exportIncludeRaw, "include-raw", false, "Link each message to its line in the exported source JSONL (html format only)")
+	exportCmd.Flags().BoolVar(&exportShowAll, "show-all", false, "Also show entries normally hidden as empty, as faint debug rows (html format only)")
+	exportCmd.Flags().BoolVar(&exportInspector, "debug-inspector", false, "Add a collapsed block with the original JSON of each entry to every message (html format only)")
+	exportCmd.Flags().IntVar(&exportPageSize, "page-size", 0, "Split the conversation into linked page-N.html files of N messages each, listed in index.html; see --paginate for print page breaks (html format only, 0 = one file)")
+	exportCmd.Flags().BoolVar(&exportNoIcons, "no-icons", false, "Omit the tool icons from tool call headers (html format only)")
+	exportCmd.Flags().IntVar(&exportLimitAgents, "limit-agents", 0, "Only render the N subagents with the most entries; list the rest by ID (html format only, 0 = all)")
+	exportCmd.Flags().StringSliceVar(&exportMarkdownTools, "markdown-results", nil, "Render the results of these tools as markdown, e.g. WebFetch,Task; Bash stays literal (html format only)")
+	exportCmd.Flags().StringSliceVar(&exportExpandTools, "expand-tools", nil, "Start calls of these tools expanded, e.g. Edit,Bash; names are case-insensitive (html format only)")
+	exportCmd.Flags().BoolVar(&exportPreamble, "include-preamble", false, "Show the system prompt and other context the session starts with in a collapsed header panel, unredacted (html format only)")
+	exportCmd.Flags().BoolVar(&exportHideResults, "hide-tool-results", false, "Leave tool output out, keeping each call's header and input; the header marks calls that had a result or an error (html format only)")
+	exportCmd.Flags().BoolVar(&exportGroupByTool, "group-by-tool", false, "Also write tools.html, listing the tool calls grouped by tool with links back to the conversation (html format only)")
+	exportCmd.Flags().BoolVar(&exportCollapseReads, "collapse-repeats", false, "Collapse re-reads of a file already read in the same conversation under a \"re-read (Nx)\" group (html format only)")
+	exportCmd.Flags().BoolVar(&exportLineNumbers, "line-numbers", false, "Number the lines of Read and Bash output, each number linking to its line (html format only)")
+	exportCmd.Flags().StringVar(&exportAnnotations, "annotations", "", "Notes and tags to show on messages, as a JSON object keyed by entry UUID (default: annotations.json in the session folder, if present; html format only)")
+	exportCmd.Flags().BoolVar(&exportStripIDs, "strip-uuids", false, "Replace session, agent, message and tool IDs in the rendered HTML with sequential labels such as agent-1 and tool-2; the copied source JSONL, manifest.json and default output directory name keep the real IDs (html format only)")
+	exportCmd.Flags().BoolVar(&exportFirstPrompt, "show-first-prompt", false, "Repeat the session's first prompt in full in a card at the top of the page (html format only)")
+	exportCmd.Flags().BoolVar(&exportFilesTouched, "files-touched", false, "List the files the session modified, with edit and read counts, in a collapsed panel at the top of the page (html format only)")
+	exportCmd.Flags().BoolVar(&exportDaySeparators, "day-separators", false, "Insert a date header when the day changes in multi-day sessions (html format only)")
+	exportCmd.Flags().BoolVar(&exportSidebar, "sidebar", false, "Add a sidebar listing the main session and every subagent, nested by depth, as links to their sections (html format only)")
+	exportCmd.Flags().BoolVar(&exportShowLegend, "show-legend", false, "Add a legend of message colors and tool styling to the page footer (html format only)")
+	exportCmd.Flags().BoolVar(&exportSearchIndex, "search-index", false, "Embed a word index so in-page search only scans messages that can match (html format only)")
+	exportCmd.Flags().StringArrayVar(&exportAvatars, "avatar", nil, "Show initials in the avatars of a message type, as type=initials, e.g. user=RL (repeatable, html format only)")
+	exportCmd.Flags().StringArrayVar(&exportAvatarImages, "avatar-image", nil, "Show an image in the avatars of a message type, as type=url (repeatable, html format only)")
+	exportCmd.Flags().StringArrayVar(&exportTypeColors, "type-color", nil, "Color the messages of an entry type, as type=color, e.g. system=gray or user=#3b82f6 (repeatable, html format only)")
+	exportCmd.Flags().StringVar(&exportEncoding, "encoding", "", "Assume tool output that is not valid UTF-8 is in this single-byte encoding, e.g. latin1 or windows-1252 (html format only)")
+	exportCmd.Flags().BoolVar(&exportEmoji, "emoji-shortcodes", false, "Show common :name: shortcodes in assistant messages as emoji (html format only)")
+	exportCmd.Flags().BoolVar(&exportSafeHTML, "allow-safe-html", false, "Render <br>, <sub>, <sup> and <kbd> tags in assistant messages instead of escaping them; other HTML stays escaped (html format only)")
+	exportCmd.Flags().BoolVar(&exportShowGaps, "show-gaps", false, "Mark long pauses between consecutive messages (html format only)")
+	exportCmd.Flags().DurationVar(&exportGapThreshold, "gap-threshold", export.DefaultGapThreshold, "Shortest pause marked by --show-gaps")
+	exportCmd.Flags().DurationVar(&exportIdleThreshold, "idle-threshold", export.DefaultIdleThreshold, "Longest pause between messages counted as active time in the header's duration")
+	exportCmd.Flags().BoolVar(&exportReplay, "replay", false, "Add Play and Show All buttons that reveal the messages one at a time (html format only)")
+	exportCmd.Flags().DurationVar(&exportReplayDelay, "replay-delay", export.DefaultReplayDelay, "Pause between messages revealed by --replay")
+	exportCmd.Flags().BoolVar(&exportNoJS, "no-js", false, "Render a page that works without JavaScript: native collapsing, subagents inlined, no search or copy buttons (html format only)")
+	exportCmd.Flags().BoolVar(&exportThreadOrder, "thread-order", false, "Move replies the file lists before the entry they answer (by parentUuid) to just after it, where timestamps tie or are missing")
+	exportCmd.Flags().BoolVar(&exportCompact, "compact", false, "Strip the indentation and blank lines from the generated HTML, CSS, and JavaScript; text is unchanged (html format only)")
+	exportCmd.Flags().StringVar(&exportFilterProfile, filterProfileFlag, "", "Export only the main-session entries matching a named filter profile from the config file's filters section")
+	exportCmd.Flags().StringVar(&exportTimezone, "timezone", "", "Time zone deciding day boundaries for --day-separators: an IANA name or Local (default UTC)")
+	exportCmd.Flags().BoolVar(&exportZip, "zip", false, "Write the export as a single .zip archive")
+	exportCmd.Flags().BoolVar(&exportResume, "resume", false, "Reuse verified source files from a previous export in --output")
 }
 
 func runExport(cmd *cobra.Command, args []string) error {
@@ -77,73 +391,418 @@ func runExport(cmd *cobra.Command, args []string) error {
 		projectPath = absPath
 	}
 
-	// Validate format
-	if exportFormat != "html" && exportFormat != "jsonl" {
-		return fmt.Errorf("invalid format: %s (must be 'html' or 'jsonl')", exportFormat)
+	// Validate format: jsonl only copies source files, everything else is a registered exporter.
+	// Format names are case-insensitive; the rest of the export compares the lowercase name.
+	exportFormat = strings.ToLower(strings.TrimSpace(exportFormat))
+	var exporter export.Exporter
+	if exportFormat != "jsonl" {
+		e, err := export.GetExporter(exportFormat)
+		if err != nil {
+			return fmt.Errorf("invalid format: %w", err)
+		}
+		exporter = e
 	}
 
-	// Get the project directory in Claude's storage
-	projectDir, err := paths.ProjectDir(claudeDir, projectPath)
+	// Apply rendering options and field selection for formats that support them
+	if exportMaxOutput < 0 {
+		return fmt.Errorf("--max-output-bytes must not be negative")
+	}
+	if exportSummaryLen < 0 {
+		return fmt.Errorf("--summary-length must not be negative")
+	}
+	if exportCollapseCode < 0 {
+		return fmt.Errorf("--collapse-code-lines must not be negative")
+	}
+	if exportLimitAgents < 0 {
+		return fmt.Errorf("--limit-agents must not be negative")
+	}
+	if _, err := agent.ParseSortMode(exportSortAgents); err != nil {
+		return fmt.Errorf("invalid --sort-agents: %w", err)
+	}
+	if exportLocale != "" {
+		if _, ok := export.MatchLocale(exportLocale); !ok {
+			fmt.Fprintf(os.Stderr, "Warning: unsupported locale %q, using English\n", exportLocale)
+		}
+	}
+	var location *time.Location
+	if exportTimezone != "" {
+		loc, err := time.LoadLocation(exportTimezone)
+		if err != nil {
+			return fmt.Errorf("invalid --timezone: %w", err)
+		}
+		location = loc
+	}
+	avatars, err := parseAvatars(exportAvatars, exportAvatarImages)
+	if err != nil {
+		return err
+	}
+	typeColors, err := parseTypeColors(exportTypeColors)
 	if err != nil {
-		return fmt.Errorf("failed to resolve project directory: %w", err)
+		return err
+	}
+	var idMap *export.IDMapper
+	if exportStripIDs {
+		idMap = export.NewIDMapper() // Shared by the pages and agent fragments
+	}
+	exporter = withRenderOptions(exporter, export.ExportOptions{
+		RelativeTimes:         exportRelativeTimes,
+		Paginate:              exportPaginate,
+		MaxToolOutputBytes:    exportMaxOutput,
+		SummaryMaxLen:         exportSummaryLen,
+		CollapseCodeLines:     exportCollapseCode,
+		CombineToolMessages:   exportCombineTools,
+		GroupParallelTools:    exportGroupParallel,
+		Locale:                exportLocale,
+		WrapWidth:             exportWrap,
+		ShowAll:               exportShowAll,
+		ShowLegend:            exportShowLegend,
+		Sidebar:               exportSidebar,
+		SearchIndex:           exportSearchIndex,
+		Avatars:               avatars,
+		TypeColors:            typeColors,
+		ToolOutputEncoding:    exportEncoding,
+		EmojiShortcodes:       exportEmoji,
+		AllowSafeHTML:         exportSafeHTML,
+		DebugInspector:        exportInspector,
+		PageSize:              exportPageSize,
+		NoToolIcons:           exportNoIcons,
+		MaxAgents:             exportLimitAgents,
+		RenderResultMarkdown:  len(exportMarkdownTools) > 0,
+		MarkdownResultTools:   exportMarkdownTools,
+		AutoExpandTools:       exportExpandTools,
+		DaySeparators:         exportDaySeparators,
+		ShowGaps:              exportShowGaps,
+		GapThreshold:          exportGapThreshold,
+		IdleThreshold:         exportIdleThreshold,
+		ReplayMode:            exportReplay,
+		ReplayDelay:           exportReplayDelay,
+		Location:              location,
+		Highlight:             exportHighlight,
+		HighlightIgnoreCase:   exportHighlightCase,
+		TemplateFile:          exportTemplate,
+		NoJS:                  exportNoJS,
+		IncludePreamble:       exportPreamble,
+		ShowFirstPrompt:       exportFirstPrompt,
+		ShowFilesTouched:      exportFilesTouched,
+		HideToolResults:       exportHideResults,
+		GroupByTool:           exportGroupByTool,
+		CollapseRepeats:       exportCollapseReads,
+		ToolOutputLineNumbers: exportLineNumbers,
+		Minify:                exportCompact,
+		StripIDs:              exportStripIDs,
+		IDMap:                 idMap,
+	})
+	if len(exportFields) > 0 {
+		fieldExporter, err := applyExportFields(exporter, exportFields)
+		if err != nil {
+			return err
+		}
+		exporter = fieldExporter
 	}
 
-	if !paths.Exists(projectDir) {
-		return fmt.Errorf("project not found: %s", projectPath)
+	if exportWrap != 0 {
+		if exportWrap < 0 {
+			return fmt.Errorf("--wrap must not be negative")
+		}
+		switch exporter.(type) {
+		case export.MarkdownExporter, export.TextExporter:
+		default:
+			return fmt.Errorf("--wrap is only supported for markdown and text formats")
+		}
+	}
+
+	if exportNoStats {
+		statsExporter, err := withoutStats(exporter)
+		if err != nil {
+			return err
+		}
+		exporter = statsExporter
 	}
 
-	// Resolve session ID prefix
-	resolvedSessionID, err := resolver.ResolveSessionID(projectDir, exportSessionID)
+	if exportFrontMatter {
+		frontMatterExporter, err := withFrontMatter(exporter)
+		if err != nil {
+			return err
+		}
+		exporter = frontMatterExporter
+	}
+
+	if _, ok := exporter.(export.HTMLExporter); !ok {
+		for _, flag := range htmlOnlyFlags() {
+			if flag.set {
+				return fmt.Errorf("%s is only supported for html format", flag.name)
+			}
+		}
+	}
+
+	// Check a custom layout before copying anything, so template errors surface early
+	if exportTemplate != "" {
+		if err := export.CheckTemplateFile(exportTemplate); err != nil {
+			return err
+		}
+	}
+
+	exportFilter = nil
+	if exportFilterProfile != "" {
+		if exporter == nil {
+			return fmt.Errorf("--filter is not supported for jsonl format")
+		}
+		if exportAgentID != "" {
+			return fmt.Errorf("--filter cannot be combined with --agent")
+		}
+		opts, err := buildFilterOptions("")
+		if err != nil {
+			return fmt.Errorf("invalid filter profile %q: %w", exportFilterProfile, err)
+		}
+		exportFilter = &opts
+	}
+
+	if exportStripIDs && exportIncludeRaw {
+		return fmt.Errorf("--strip-uuids cannot be combined with --include-raw")
+	}
+
+	if exportShowGaps && exportGapThreshold <= 0 {
+		return fmt.Errorf("--gap-threshold must be positive")
+	}
+
+	if exportIdleThreshold <= 0 {
+		return fmt.Errorf("--idle-threshold must be positive")
+	}
+
+	if exportReplay && exportReplayDelay <= 0 {
+		return fmt.Errorf("--replay-delay must be positive")
+	}
+
+	if exportNoJS && exportReplay {
+		return fmt.Errorf("--no-js cannot be combined with --replay")
+	}
+
+	if exportEncoding != "" {
+		if _, err := models.LookupEncoding(exportEncoding); err != nil {
+			return fmt.Errorf("invalid --encoding: %w", err)
+		}
+	}
+
+	if exportPageSize < 0 {
+		return fmt.Errorf("--page-size must not be negative")
+	}
+
+	// Agent exports render a standalone page without the session-level extras
+	if exportAgentID != "" && (exportResume || exportTimeline || exportTemplate != "" || exportIncludeRaw || exportNoJS) {
+		return fmt.Errorf("--agent cannot be combined with --resume, --timeline, --template, --include-raw, or --no-js")
+	}
+
+	// Streaming to stdout only makes sense for a single file
+	zipOutput := exportZip || exportOutputDir == "-"
+	if zipOutput && exportResume {
+		return fmt.Errorf("--resume cannot be combined with --zip")
+	}
+
+	// Resume needs a stable output directory; generated paths are unique per run
+	if exportResume && exportOutputDir == "" {
+		return fmt.Errorf("--resume requires --output")
+	}
+
+	if exportLatest && len(exportSessionIDs) > 0 {
+		return fmt.Errorf("--latest cannot be combined with --session")
+	}
+	if exportSelect && (exportLatest || len(exportSessionIDs) > 0) {
+		return fmt.Errorf("--select cannot be combined with --session or --latest")
+	}
+	if !exportLatest && !exportSelect && len(exportSessionIDs) == 0 {
+		return fmt.Errorf("--session, --latest, or --select is required")
+	}
+	if len(exportSessionIDs) > 1 && exportAgentID != "" {
+		return fmt.Errorf("--agent cannot be combined with more than one --session")
+	}
+
+	// Get the project directory in Claude's storage
+	projectDir, err := export.ProjectDir(projectPath, export.ExportOptions{ClaudeDir: claudeDir, EncodedProjectDir: exportProjDir})
 	if err != nil {
-		return fmt.Errorf("failed to resolve session ID: %w", err)
+		return err
+	}
+
+	if len(exportSessionIDs) > 1 {
+		return runMultiExport(exporter, projectPath, projectDir, zipOutput)
+	}
+
+	// Resolve session ID prefix, or pick the most recently modified or a chosen session
+	var resolvedSessionID string
+	if exportSelect {
+		interactive := !exportNoInteract && isTerminal(os.Stdin)
+		resolvedSessionID, err = selectSession(projectDir, os.Stdin, os.Stderr, interactive)
+		if err != nil {
+			return err
+		}
+	} else if exportLatest {
+		resolvedSessionID, err = session.LatestSession(projectDir)
+		if err != nil {
+			return fmt.Errorf("failed to find latest session: %w", err)
+		}
+	} else {
+		resolvedSessionID, err = resolver.ResolveSessionID(projectDir, exportSessionIDs[0])
+		if err != nil {
+			return fmt.Errorf("failed to resolve session ID: %w", err)
+		}
 	}
 
 	// Validate session exists
-	sessionFile := filepath.Join(projectDir, resolvedSessionID+".jsonl")
+	sessionFile := paths.JSONLFile(filepath.Join(projectDir, resolvedSessionID))
 	if !paths.Exists(sessionFile) {
-		return fmt.Errorf("session not found: %s", resolvedSessionID)
+		return fmt.Errorf("%w: %s", resolver.ErrSessionNotFound, resolvedSessionID)
 	}
 
-	// Get session info for display
-	sessionInfo, err := session.GetSessionInfo(sessionFile)
+	outputDir, zipPath, cleanup, err := prepareOutputDir(resolvedSessionID, zipOutput)
 	if err != nil {
-		return fmt.Errorf("failed to read session: %w", err)
+		return err
+	}
+	defer cleanup()
+
+	if exportAgentID != "" {
+		return runAgentExport(exporter, projectPath, projectDir, resolvedSessionID, outputDir, zipPath)
+	}
+
+	// Rendering failures were reported as warnings; the source files are exported
+	if _, err := exportSessionTo(exporter, projectPath, projectDir, resolvedSessionID, outputDir); err != nil {
+		return err
 	}
 
+	return finishExport(outputDir, zipPath, resolvedSessionID)
+}
+
+// prepareOutputDir creates the directory an export is written to: --output, or a new
+// directory named after name under the system temp directory. For archives the export is
+// assembled in a scratch directory, removed by cleanup, and zipPath is where the archive
+// goes ("-" for stdout); otherwise zipPath is empty.
+func prepareOutputDir(name string, zipOutput bool) (outputDir, zipPath string, cleanup func(), err error) {
+	cleanup = func() {}
+
 	// Generate output directory if not specified
-	outputDir := exportOutputDir
+	outputDir = exportOutputDir
 	if outputDir == "" {
-		outputDir = generateTempExportPath(resolvedSessionID)
+		outputDir = generateTempExportPath(name)
+	}
+
+	// Archives are assembled in a scratch directory, then zipped to the requested path
+	if zipOutput {
+		zipPath = outputDir
+		if exportOutputDir == "" {
+			zipPath += ".zip"
+		}
+		stagingDir, err := os.MkdirTemp("", "claude-history-export-")
+		if err != nil {
+			return "", "", cleanup, fmt.Errorf("failed to create staging directory: %w", err)
+		}
+		cleanup = func() { os.RemoveAll(stagingDir) }
+		outputDir = stagingDir
 	}
 
 	// Resolve output directory to absolute path
 	if !filepath.IsAbs(outputDir) {
 		absPath, err := filepath.Abs(outputDir)
 		if err != nil {
-			return fmt.Errorf("failed to resolve output path: %w", err)
+			cleanup()
+			return "", "", func() {}, fmt.Errorf("failed to resolve output path: %w", err)
 		}
 		outputDir = absPath
 	}
 
 	// Create output directory
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		cleanup()
+		return "", "", func() {}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return outputDir, zipPath, cleanup, nil
+}
+
+// sessionExport describes one exported session.
+type sessionExport struct {
+	FirstPrompt string // First user prompt, for display
+	DocPath     string // Rendered page or document; empty for jsonl or when rendering failed
+	RenderErr   error  // Why rendering failed, after the source files were exported
+}
+
+// htmlOnlyFlag is an export flag that only the html format supports.
+type htmlOnlyFlag struct {
+	name string // As given on the command line, e.g. "--sidebar"
+	set  bool   // Whether the flag was given
+}
+
+// htmlOnlyFlags returns the export flags that only the html format supports, each marked
+// with whether it was given, in the order runExport reports them.
+func htmlOnlyFlags() []htmlOnlyFlag {
+	return []htmlOnlyFlag{
+		{"--template", exportTemplate != ""},
+		{"--include-raw", exportIncludeRaw},
+		{"--compact", exportCompact},
+		{"--include-preamble", exportPreamble},
+		{"--show-first-prompt", exportFirstPrompt},
+		{"--files-touched", exportFilesTouched},
+		{"--hide-tool-results", exportHideResults},
+		{"--group-by-tool", exportGroupByTool},
+		{"--collapse-repeats", exportCollapseReads},
+		{"--line-numbers", exportLineNumbers},
+		{"--annotations", exportAnnotations != ""},
+		{"--strip-uuids", exportStripIDs},
+		{"--day-separators", exportDaySeparators},
+		{"--show-gaps", exportShowGaps},
+		{"--replay", exportReplay},
+		{"--no-js", exportNoJS},
+		{"--show-legend", exportShowLegend},
+		{"--sidebar", exportSidebar},
+		{"--search-index", exportSearchIndex},
+		{"--type-color", len(exportTypeColors) > 0},
+		{"--avatar", len(exportAvatars) > 0},
+		{"--avatar-image", len(exportAvatarImages) > 0},
+		{"--encoding", exportEncoding != ""},
+		{"--emoji-shortcodes", exportEmoji},
+		{"--allow-safe-html", exportSafeHTML},
+		{"--show-all", exportShowAll},
+		{"--debug-inspector", exportInspector},
+		{"--page-size", exportPageSize > 0},
+		{"--group-parallel-tools", exportGroupParallel},
+		{"--no-icons", exportNoIcons},
+		{"--limit-agents", exportLimitAgents > 0},
+		{"--markdown-results", len(exportMarkdownTools) > 0},
+		{"--expand-tools", len(exportExpandTools) > 0},
+		{"--relative-times", exportRelativeTimes},
+		{"--paginate", exportPaginate},
+		{"--timeline", exportTimeline},
+		{"--combine-tool-messages", exportCombineTools},
+		{"--max-output-bytes", exportMaxOutput > 0},
+		{"--summary-length", exportSummaryLen != export.DefaultSummaryMaxLen},
+		{"--collapse-code-lines", exportCollapseCode != export.DefaultCollapseCodeLines},
+		{"--highlight", exportHighlight != ""},
+	}
+}
+
+// exportSessionTo exports the source files of a resolved session into outputDir and
+// renders them in the requested format, reporting progress on stderr. Rendering failures
+// are reported as warnings and returned in RenderErr rather than as an error.
+func exportSessionTo(exporter export.Exporter, projectPath, projectDir, sessionID, outputDir string) (*sessionExport, error) {
+	// Get session info for display
+	sessionInfo, err := session.GetSessionInfo(paths.JSONLFile(filepath.Join(projectDir, sessionID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
 	}
 
 	// Prepare export options
 	opts := export.ExportOptions{
-		OutputDir: outputDir,
-		ClaudeDir: claudeDir,
+		OutputDir:         outputDir,
+		ClaudeDir:         claudeDir,
+		EncodedProjectDir: exportProjDir,
+		Format:            exportFormat,
+		Resume:            exportResume,
 	}
 
 	// Call export
-	result, err := export.ExportSession(projectPath, resolvedSessionID, opts)
+	result, err := export.ExportSession(projectPath, sessionID, opts)
 	if err != nil {
-		return fmt.Errorf("export failed: %w", err)
+		return nil, fmt.Errorf("export failed: %w", err)
 	}
 
 	// Report export parameters
-	fmt.Fprintf(os.Stderr, "Exporting session %s\n", exportSessionID[:8])
+	fmt.Fprintf(os.Stderr, "Exporting session %s\n", sessionID[:8])
 	fmt.Fprintf(os.Stderr, "  Project: %s\n", projectPath)
 	fmt.Fprintf(os.Stderr, "  Format: %s\n", exportFormat)
 	fmt.Fprintf(os.Stderr, "  Output: %s\n", result.OutputDir)
@@ -164,41 +823,478 @@ func runExport(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Export JSONL files
-	opts = export.ExportOptions{
-		OutputDir: outputDir,
-		ClaudeDir: claudeDir,
+	if result.Resumed {
+		fmt.Fprintf(os.Stderr, "✓ Resumed with verified JSONL files (%d agents)\n", result.TotalAgents)
+	} else {
+		fmt.Fprintf(os.Stderr, "✓ JSONL files exported (%d agents)\n", result.TotalAgents)
 	}
-	result2, err := export.ExportSession(projectPath, resolvedSessionID, opts)
-	if err != nil {
-		return fmt.Errorf("failed to export session: %w", err)
+
+	// Render the requested format (JSONL files are already exported, so failures are non-fatal)
+	exported := &sessionExport{FirstPrompt: sessionInfo.FirstPrompt}
+	switch {
+	case exporter == nil:
+		// jsonl: source files only
+	case exportFormat == "html":
+		spansExporter, err := withAgentSpans(exporter, result, projectDir, sessionID, exportTimeline)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: agent durations failed: %v\n", err)
+		} else {
+			exporter = spansExporter
+		}
+		annotatedExporter, err := withAnnotations(exporter, annotationsPath(projectDir, sessionID))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring annotations: %v\n", err)
+		} else {
+			exporter = annotatedExporter
+		}
+		if err := renderHTML(exporter, result, projectPath, projectDir, sessionID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: HTML rendering failed: %v\n", err)
+			exported.RenderErr = fmt.Errorf("HTML rendering failed: %w", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "✓ HTML export completed\n")
+			exported.DocPath = result.HTMLFile
+		}
+	default:
+		docPath, err := renderDocument(exporter, result, projectPath, projectDir, sessionID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s rendering failed: %v\n", exportFormat, err)
+			exported.RenderErr = fmt.Errorf("%s rendering failed: %w", exportFormat, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "✓ %s export completed: %s\n", exportFormat, docPath)
+			exported.DocPath = docPath
+		}
+	}
+	return exported, nil
+}
+
+// finishExport reports the finished export in outputDir, first packaging it into
+// zipPath when an archive was requested ("-" streams it to stdout).
+func finishExport(outputDir, zipPath, sessionID string) error {
+	switch zipPath {
+	case "":
+		// Print the output location (stdout for scripting)
+		fmt.Println(outputDir)
+		return nil
+	case "-":
+		root := "claude-history-" + sessionID
+		if len(sessionID) > 8 {
+			root = "claude-history-" + sessionID[:8]
+		}
+		return export.WriteArchive(os.Stdout, outputDir, root)
 	}
 
-	// Report any non-fatal errors
-	if len(result2.Errors) > 0 {
-		for _, errMsg := range result2.Errors {
-			fmt.Fprintf(os.Stderr, "Warning: %s\n", errMsg)
+	if !filepath.IsAbs(zipPath) {
+		absPath, err := filepath.Abs(zipPath)
+		if err != nil {
+			return fmt.Errorf("failed to resolve output path: %w", err)
 		}
+		zipPath = absPath
+	}
+	if err := os.MkdirAll(filepath.Dir(zipPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := export.ArchiveExport(outputDir, zipPath); err != nil {
+		return err
 	}
+	fmt.Fprintf(os.Stderr, "✓ Archive created at: %s\n", zipPath)
+	fmt.Println(zipPath)
+	return nil
+}
 
-	fmt.Fprintf(os.Stderr, "✓ JSONL files exported (%d agents)\n", result.TotalAgents)
+// runAgentExport exports a single subagent subtree and renders it in the requested format.
+func runAgentExport(exporter export.Exporter, projectPath, projectDir, sessionID, outputDir, zipPath string) error {
+	result, err := export.ExportAgent(projectPath, sessionID, exportAgentID, export.ExportOptions{
+		OutputDir:         outputDir,
+		ClaudeDir:         claudeDir,
+		EncodedProjectDir: exportProjDir,
+	})
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
 
-	// If HTML format requested, generate HTML pages
-	if exportFormat == "html" {
-		if err := renderHTML(result, projectPath, projectDir, resolvedSessionID); err != nil {
-			// Non-fatal: JSONL files are already exported
-			fmt.Fprintf(os.Stderr, "Warning: HTML rendering failed: %v\n", err)
+	fmt.Fprintf(os.Stderr, "Exporting agent %s from session %s\n", truncateAgentID(result.AgentID), truncateAgentID(sessionID))
+	fmt.Fprintf(os.Stderr, "  Project: %s\n", projectPath)
+	fmt.Fprintf(os.Stderr, "  Format: %s\n", exportFormat)
+	fmt.Fprintf(os.Stderr, "  Output: %s\n", result.OutputDir)
+	fmt.Fprintf(os.Stderr, "  Nested agents: %d\n", result.TotalAgents)
+	fmt.Fprintln(os.Stderr)
+	for _, errMsg := range result.Errors {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", errMsg)
+	}
+	fmt.Fprintf(os.Stderr, "✓ JSONL files exported (%d nested agents)\n", result.TotalAgents)
+
+	if exporter != nil {
+		docPath, err := renderAgentDocument(exporter, result, projectPath, projectDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s rendering failed: %v\n", exportFormat, err)
 		} else {
-			fmt.Fprintf(os.Stderr, "✓ HTML export completed\n")
+			fmt.Fprintf(os.Stderr, "✓ %s export completed: %s\n", exportFormat, docPath)
 		}
 	}
 
-	// Print the output location (stdout for scripting)
-	fmt.Println(outputDir)
+	return finishExport(result.OutputDir, zipPath, sessionID)
+}
+
+// runMultiExport exports each session named by --session into its own subdirectory of the
+// output directory, named after the full session ID, and writes an index.html linking to
+// them. A session that fails is recorded and the rest are still exported; the run fails
+// if any session did, after the others are written.
+func runMultiExport(exporter export.Exporter, projectPath, projectDir string, zipOutput bool) error {
+	outputDir, zipPath, cleanup, err := prepareOutputDir("sessions", zipOutput)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	var links []export.SessionLink
+	var failures []string
+	seen := make(map[string]bool)
+	for _, sessionID := range exportSessionIDs {
+		link := exportSessionSubdir(exporter, projectPath, projectDir, sessionID, outputDir)
+		if seen[link.SessionID] {
+			continue
+		}
+		seen[link.SessionID] = true
+		links = append(links, link)
+		if link.Error != "" {
+			failures = append(failures, fmt.Sprintf("%s: %s", link.SessionID, link.Error))
+		}
+		fmt.Fprintln(os.Stderr)
+	}
 
+	exported := len(links) - len(failures)
+	fmt.Fprintf(os.Stderr, "✓ Exported %d of %d sessions\n", exported, len(links))
+	if len(failures) > 0 {
+		fmt.Fprintf(os.Stderr, "✗ %d failed:\n", len(failures))
+		for _, f := range failures {
+			fmt.Fprintf(os.Stderr, "  - %s\n", f)
+		}
+	}
+	if exported == 0 {
+		return fmt.Errorf("all %d sessions failed to export", len(links))
+	}
+
+	indexPath := filepath.Join(outputDir, export.SessionsIndexFileName)
+	if err := os.WriteFile(indexPath, []byte(export.RenderSessionsIndex(projectPath, links)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", export.SessionsIndexFileName, err)
+	}
+
+	if err := finishExport(outputDir, zipPath, "sessions"); err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d sessions failed to export", len(failures), len(links))
+	}
 	return nil
 }
 
+// exportSessionSubdir exports one session of a multi-session export into
+// outputDir/<session ID> and returns its entry in the sessions index.
+func exportSessionSubdir(exporter export.Exporter, projectPath, projectDir, sessionID, outputDir string) export.SessionLink {
+	resolvedSessionID, err := resolver.ResolveSessionID(projectDir, sessionID)
+	if err != nil {
+		return export.SessionLink{SessionID: sessionID, Error: fmt.Sprintf("failed to resolve session ID: %v", err)}
+	}
+	link := export.SessionLink{SessionID: resolvedSessionID}
+	if !paths.Exists(paths.JSONLFile(filepath.Join(projectDir, resolvedSessionID))) {
+		link.Error = "session not found"
+		return link
+	}
+
+	sessionDir := filepath.Join(outputDir, resolvedSessionID)
+	if err := os.MkdirAll(sessionDir, 0755); err != nil {
+		link.Error = fmt.Sprintf("failed to create output directory: %v", err)
+		return link
+	}
+
+	exported, err := exportSessionTo(exporter, projectPath, projectDir, resolvedSessionID, sessionDir)
+	if err != nil {
+		link.Error = err.Error()
+		return link
+	}
+	link.FirstPrompt = exported.FirstPrompt
+	link.Href = resolvedSessionID + "/"
+	if exported.DocPath != "" {
+		if rel, err := filepath.Rel(outputDir, exported.DocPath); err == nil {
+			link.Href = filepath.ToSlash(rel)
+		}
+	}
+	if exported.RenderErr != nil {
+		link.Error = exported.RenderErr.Error()
+	}
+	return link
+}
+
+// renderAgentDocument renders an agent subtree export and returns the written file's path.
+// HTML is a single self-contained page (inline CSS and JavaScript) with Orchestrator/Agent
+// labels; other formats are written as conversation.<ext>.
+func renderAgentDocument(exporter export.Exporter, result *export.ExportResult, projectPath, projectDir string) (string, error) {
+	entries, err := export.ReadAgentExportEntries(result)
+	if err != nil {
+		return "", err
+	}
+
+	var content []byte
+	var docPath string
+	if _, ok := exporter.(export.HTMLExporter); ok {
+		sessionFolder := filepath.Join(projectDir, result.SessionID)
+		html, err := export.RenderQueryResults(entries, projectPath, result.SessionID, sessionFolder, result.AgentID, "Orchestrator", "Agent")
+		if err != nil {
+			return "", fmt.Errorf("failed to render agent: %w", err)
+		}
+		content = []byte(html)
+		docPath = filepath.Join(result.OutputDir, "index.html")
+	} else {
+		stats := export.ComputeSessionStats(entries, nil)
+		stats.SessionID = result.SessionID
+		stats.ProjectPath = projectPath
+		content, err = exporter.Render(entries, nil, stats)
+		if err != nil {
+			return "", fmt.Errorf("failed to render agent: %w", err)
+		}
+		docPath = filepath.Join(result.OutputDir, "conversation"+exporter.Extension())
+	}
+
+	if err := os.WriteFile(docPath, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", filepath.Base(docPath), err)
+	}
+	return docPath, nil
+}
+
+// parseAvatars parses the --avatar (type=initials) and --avatar-image (type=url) flags
+// into the avatars to show for each message type, nil if neither flag was given.
+func parseAvatars(initials, images []string) (map[models.EntryType]export.Avatar, error) {
+	if len(initials) == 0 && len(images) == 0 {
+		return nil, nil
+	}
+	avatars := make(map[models.EntryType]export.Avatar)
+	set := func(flag, value string, apply func(a *export.Avatar, v string)) error {
+		name, v, ok := strings.Cut(value, "=")
+		entryType := models.EntryType(strings.TrimSpace(name))
+		if !ok || strings.TrimSpace(v) == "" {
+			return fmt.Errorf("invalid %s %q: want type=value", flag, value)
+		}
+		switch entryType {
+		case models.EntryTypeUser, models.EntryTypeAssistant, models.EntryTypeSystem,
+			models.EntryTypeQueueOperation, models.EntryTypeSummary:
+		default:
+			return fmt.Errorf("invalid %s %q: type must be user, assistant, system, queue-operation, or summary", flag, value)
+		}
+		a := avatars[entryType]
+		apply(&a, strings.TrimSpace(v))
+		if err := a.Validate(); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", flag, value, err)
+		}
+		avatars[entryType] = a
+		return nil
+	}
+	for _, v := range initials {
+		if err := set("--avatar", v, func(a *export.Avatar, s string) { a.Initials = s }); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range images {
+		if err := set("--avatar-image", v, func(a *export.Avatar, s string) { a.ImageURL = s }); err != nil {
+			return nil, err
+		}
+	}
+	return avatars, nil
+}
+
+// parseTypeColors parses --type-color flags, each type=color, into the color overrides
+// of export.ExportOptions.TypeColors. A later flag for the same type wins.
+func parseTypeColors(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	colors := make(map[string]string, len(values))
+	for _, value := range values {
+		name, color, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(color) == "" {
+			return nil, fmt.Errorf("invalid --type-color %q: want type=color", value)
+		}
+		name, color = strings.TrimSpace(name), strings.TrimSpace(color)
+		if err := export.ValidateTypeColors(map[string]string{name: color}); err != nil {
+			return nil, fmt.Errorf("invalid --type-color %q: %w", value, err)
+		}
+		colors[name] = color
+	}
+	return colors, nil
+}
+
+// withRenderOptions returns a copy of the exporter configured with the given rendering options.
+// Exporters without rendering options are returned unchanged.
+func withRenderOptions(exporter export.Exporter, opts export.ExportOptions) export.Exporter {
+	switch e := exporter.(type) {
+	case export.HTMLExporter:
+		return export.HTMLExporter{Options: opts}
+	case export.MarkdownExporter:
+		e.Locale = opts.Locale
+		e.WrapWidth = opts.WrapWidth
+		return e
+	case export.TextExporter:
+		e.Locale = opts.Locale
+		e.WrapWidth = opts.WrapWidth
+		return e
+	default:
+		return exporter
+	}
+}
+
+// withoutStats returns a copy of the exporter that omits the trailing session statistics.
+// Only the markdown and text exporters write a statistics block.
+func withoutStats(exporter export.Exporter) (export.Exporter, error) {
+	switch e := exporter.(type) {
+	case export.MarkdownExporter:
+		e.NoStats = true
+		return e, nil
+	case export.TextExporter:
+		e.NoStats = true
+		return e, nil
+	default:
+		return nil, fmt.Errorf("--no-stats is only supported for markdown and text formats")
+	}
+}
+
+// withFrontMatter returns a copy of the markdown exporter that starts the document with
+// YAML front matter. Only the markdown exporter writes front matter.
+func withFrontMatter(exporter export.Exporter) (export.Exporter, error) {
+	e, ok := exporter.(export.MarkdownExporter)
+	if !ok {
+		return nil, fmt.Errorf("--front-matter is only supported for markdown format")
+	}
+	e.FrontMatter = true
+	return e, nil
+}
+
+// withAgentSpans returns a copy of the HTML exporter with subagent activity spans
+// computed from the exported agent files, shown as durations in the subagent headers
+// and, with timeline set, as the timeline panel. Other exporters are returned unchanged.
+func withAgentSpans(exporter export.Exporter, result *export.ExportResult, projectDir, sessionID string, timeline bool) (export.Exporter, error) {
+	htmlExporter, ok := exporter.(export.HTMLExporter)
+	if !ok {
+		return exporter, nil
+	}
+
+	agentTree, err := agent.BuildNestedTree(projectDir, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build agent tree: %w", err)
+	}
+
+	entriesByAgent := make(map[string][]models.ConversationEntry, len(result.AgentFiles))
+	for agentID, agentFile := range result.AgentFiles {
+		entries, _, err := agent.ReadAgentFile(agentFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read agent %s: %w", truncateAgentID(agentID), err)
+		}
+		entriesByAgent[agentID] = entries
+	}
+
+	opts := htmlExporter.Options
+	opts.AgentSpans = agent.AgentTimeSpans(agentTree, entriesByAgent)
+	if timeline {
+		opts.Timeline = opts.AgentSpans
+	}
+	return export.HTMLExporter{Options: opts}, nil
+}
+
+// annotationsPath returns the annotations file of a session: the --annotations file,
+// or the sidecar in the session's folder. A --annotations file that does not exist is
+// reported, while a missing sidecar is expected.
+func annotationsPath(projectDir, sessionID string) string {
+	if exportAnnotations == "" {
+		return filepath.Join(projectDir, sessionID, export.AnnotationsFileName)
+	}
+	if !paths.Exists(exportAnnotations) {
+		fmt.Fprintf(os.Stderr, "Warning: annotations file not found: %s\n", exportAnnotations)
+	}
+	return exportAnnotations
+}
+
+// withAnnotations returns a copy of the HTML exporter showing the annotations read from
+// path (see export.LoadAnnotations). Other exporters, and paths without annotations,
+// leave the exporter unchanged.
+func withAnnotations(exporter export.Exporter, path string) (export.Exporter, error) {
+	htmlExporter, ok := exporter.(export.HTMLExporter)
+	if !ok {
+		return exporter, nil
+	}
+	annotations, err := export.LoadAnnotations(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(annotations) == 0 {
+		return exporter, nil
+	}
+	opts := htmlExporter.Options
+	opts.Annotations = annotations
+	return export.HTMLExporter{Options: opts}, nil
+}
+
+// withInlineAgents returns a copy of an HTML exporter rendering without JavaScript
+// (see export.ExportOptions.NoJS) with the entries of the exported agent files, so
+// subagent sections can show them inline. Other exporters are returned unchanged.
+func withInlineAgents(exporter export.Exporter, result *export.ExportResult) (export.Exporter, error) {
+	htmlExporter, ok := exporter.(export.HTMLExporter)
+	if !ok || !htmlExporter.Options.NoJS {
+		return exporter, nil
+	}
+
+	entriesByAgent := make(map[string][]models.ConversationEntry, len(result.AgentFiles))
+	for agentID, agentFile := range result.AgentFiles {
+		entries, _, err := agent.ReadAgentFile(agentFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read agent %s: %w", truncateAgentID(agentID), err)
+		}
+		entriesByAgent[agentID] = entries
+	}
+
+	opts := htmlExporter.Options
+	opts.AgentEntries = entriesByAgent
+	return export.HTMLExporter{Options: opts}, nil
+}
+
+// withRawSource returns a copy of the HTML exporter whose messages link to their lines in
+// sourceFile, an exported JSONL file under outputDir. Other exporters are returned unchanged.
+func withRawSource(exporter export.Exporter, outputDir, sourceFile string) export.Exporter {
+	htmlExporter, ok := exporter.(export.HTMLExporter)
+	if !ok {
+		return exporter
+	}
+	opts := htmlExporter.Options
+	opts.RawSource = rawSourcePath(outputDir, sourceFile)
+	return export.HTMLExporter{Options: opts}
+}
+
+// rawSourcePath returns sourceFile relative to outputDir as a URL path, for links from
+// pages at the export root (agent fragments are loaded into index.html).
+func rawSourcePath(outputDir, sourceFile string) string {
+	rel, err := filepath.Rel(outputDir, sourceFile)
+	if err != nil {
+		return filepath.ToSlash(sourceFile)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// applyExportFields returns a copy of the exporter restricted to the given fields.
+// Only the JSON and CSV exporters support field selection.
+func applyExportFields(exporter export.Exporter, fields []string) (export.Exporter, error) {
+	switch exporter.(type) {
+	case export.JSONExporter:
+		if err := export.ValidateJSONFields(fields); err != nil {
+			return nil, err
+		}
+		return export.JSONExporter{Fields: fields}, nil
+	case export.CSVExporter:
+		if err := export.ValidateCSVFields(fields); err != nil {
+			return nil, err
+		}
+		return export.CSVExporter{Fields: fields}, nil
+	default:
+		return nil, fmt.Errorf("--fields is only supported for json and csv formats")
+	}
+}
+
 // generateTempExportPath creates a temporary export path based on session ID and timestamp.
 // Format: {tempdir}/claude-history/{sessionId[:8]}-{timestamp}/
 func generateTempExportPath(sessionID string) string {
@@ -221,52 +1317,124 @@ func truncateString(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-// renderHTML generates HTML pages for the exported session.
-func renderHTML(result *export.ExportResult, projectPath, projectDir, sessionID string) error {
-	// 1. Read main session entries
-	entries, err := jsonl.ReadAll[models.ConversationEntry](result.MainSessionFile)
+// exportData holds the parsed session data shared by all export formats.
+type exportData struct {
+	entries    []models.ConversationEntry
+	agentTree  *agent.TreeNode
+	agentNodes []*agent.TreeNode
+	stats      *export.SessionStats
+}
+
+// loadExportData reads the exported session, builds its agent tree, and computes stats.
+func loadExportData(result *export.ExportResult, projectPath, projectDir, sessionID string) (*exportData, error) {
+	// Read main session entries
+	entries, err := session.ReadSession(result.MainSessionFile)
 	if err != nil {
-		return fmt.Errorf("failed to read session: %w", err)
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	// Put replies after their parents when the file order and timestamps do not
+	if exportThreadOrder {
+		entries = session.OrderByThread(entries)
 	}
 
-	// 2. Build agent tree
+	// Keep the entries the --filter profile selects; stats then describe what was exported
+	if exportFilter != nil {
+		entries = session.FilterEntries(entries, *exportFilter)
+	}
+
+	// Build agent tree
 	agentTree, err := agent.BuildNestedTree(projectDir, sessionID)
 	if err != nil {
-		return fmt.Errorf("failed to build agent tree: %w", err)
+		return nil, fmt.Errorf("failed to build agent tree: %w", err)
 	}
 
-	// Convert tree to slice for RenderConversation
+	// Present agents in a consistent order (validated in runExport; spawn time otherwise)
+	sortMode, _ := agent.ParseSortMode(exportSortAgents)
+	agent.SortChildren(agentTree, sortMode)
+
+	// Convert tree to slice for rendering
 	var agentNodes []*agent.TreeNode
 	if agentTree != nil && len(agentTree.Children) > 0 {
 		agentNodes = agentTree.Children
 	}
 
-	// 3. Compute session stats with project path
+	// Compute session stats with project path
 	stats := export.ComputeSessionStats(entries, agentNodes)
 	stats.ProjectPath = projectPath
 	// Build session folder path: projectDir/sessionID
 	stats.SessionFolderPath = filepath.Join(projectDir, sessionID)
 
+	return &exportData{
+		entries:    entries,
+		agentTree:  agentTree,
+		agentNodes: agentNodes,
+		stats:      stats,
+	}, nil
+}
+
+// renderDocument renders a single-file export (markdown, json, ...) and returns its path.
+func renderDocument(exporter export.Exporter, result *export.ExportResult, projectPath, projectDir, sessionID string) (string, error) {
+	data, err := loadExportData(result, projectPath, projectDir, sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := exporter.Render(data.entries, data.agentNodes, data.stats)
+	if err != nil {
+		return "", fmt.Errorf("failed to render conversation: %w", err)
+	}
+
+	docPath := filepath.Join(result.OutputDir, "conversation"+exporter.Extension())
+	if err := os.WriteFile(docPath, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", filepath.Base(docPath), err)
+	}
+	return docPath, nil
+}
+
+// renderHTML generates HTML pages for the exported session.
+func renderHTML(exporter export.Exporter, result *export.ExportResult, projectPath, projectDir, sessionID string) error {
+	// 1-3. Read entries, build agent tree, compute stats
+	data, err := loadExportData(result, projectPath, projectDir, sessionID)
+	if err != nil {
+		return err
+	}
+	agentTree := data.agentTree
+
 	// 4. Render main conversation HTML with stats
-	htmlContent, err := export.RenderConversationWithStats(entries, agentNodes, stats)
+	if exportIncludeRaw {
+		exporter = withRawSource(exporter, result.OutputDir, result.MainSessionFile)
+	}
+	exporter, err = withInlineAgents(exporter, result)
+	if err != nil {
+		return err
+	}
+	pages, err := renderPages(exporter, data)
 	if err != nil {
 		return fmt.Errorf("failed to render conversation: %w", err)
 	}
 
-	// 5. Write index.html
-	indexPath := filepath.Join(result.OutputDir, "index.html")
-	if err := os.WriteFile(indexPath, []byte(htmlContent), 0644); err != nil {
-		return fmt.Errorf("failed to write index.html: %w", err)
+	// 5. Write index.html (and page-N.html when split into pages)
+	for _, page := range pages {
+		if err := os.WriteFile(filepath.Join(result.OutputDir, page.FileName), []byte(page.HTML), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", page.FileName, err)
+		}
 	}
 
-	// 6. Render agent fragments
-	if err := renderAgentFragments(result, agentTree); err != nil {
-		// Non-fatal: log warning and continue
-		fmt.Fprintf(os.Stderr, "Warning: some agent fragments failed: %v\n", err)
+	// 6. Render agent fragments (pages without JavaScript have them inlined instead)
+	var fragmentOpts export.ExportOptions
+	if htmlExporter, ok := exporter.(export.HTMLExporter); ok {
+		fragmentOpts = htmlExporter.Options
+	}
+	if !fragmentOpts.NoJS {
+		if err := renderAgentFragments(result, agentTree, fragmentOpts); err != nil {
+			// Non-fatal: log warning and continue
+			fmt.Fprintf(os.Stderr, "Warning: some agent fragments failed: %v\n", err)
+		}
 	}
 
 	// 7. Write static assets (CSS, JS)
-	if err := export.WriteStaticAssets(result.OutputDir); err != nil {
+	if err := export.WriteStaticAssetsWith(result.OutputDir, fragmentOpts); err != nil {
 		return fmt.Errorf("failed to write static assets: %w", err)
 	}
 
@@ -276,6 +1444,10 @@ func renderHTML(result *export.ExportResult, projectPath, projectDir, sessionID
 		// Non-fatal: log warning
 		fmt.Fprintf(os.Stderr, "Warning: failed to generate manifest: %v\n", err)
 	} else {
+		// Keep the copy records so a later --resume can verify the source files
+		if err := manifest.RecordExportedFiles(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record exported files: %v\n", err)
+		}
 		if err := export.WriteManifest(manifest, result.OutputDir); err != nil {
 			// Non-fatal: log warning
 			fmt.Fprintf(os.Stderr, "Warning: failed to write manifest: %v\n", err)
@@ -285,33 +1457,67 @@ func renderHTML(result *export.ExportResult, projectPath, projectDir, sessionID
 	return nil
 }
 
+// renderPages renders the conversation pages of an HTML export: index.html, plus a file
+// per page when the exporter's PageSize is set.
+func renderPages(exporter export.Exporter, data *exportData) ([]export.RenderedPage, error) {
+	if htmlExporter, ok := exporter.(export.HTMLExporter); ok {
+		return htmlExporter.RenderPages(data.entries, data.agentNodes, data.stats)
+	}
+	htmlContent, err := exporter.Render(data.entries, data.agentNodes, data.stats)
+	if err != nil {
+		return nil, err
+	}
+	return []export.RenderedPage{{FileName: export.PageIndexFileName, HTML: string(htmlContent)}}, nil
+}
+
 // renderAgentFragments renders HTML fragments for each agent.
-func renderAgentFragments(result *export.ExportResult, agentTree *agent.TreeNode) error {
+func renderAgentFragments(result *export.ExportResult, agentTree *agent.TreeNode, opts export.ExportOptions) error {
 	// Create agents/ directory
 	agentsDir := filepath.Join(result.OutputDir, "agents")
 	if err := os.MkdirAll(agentsDir, 0755); err != nil {
 		return err
 	}
 
+	// Agents left out of the page have no section to load a fragment into
+	var agentNodes []*agent.TreeNode
+	if agentTree != nil {
+		agentNodes = agentTree.Children
+	}
+	skip := make(map[string]bool)
+	for _, id := range export.OverflowAgents(agentNodes, opts.MaxAgents) {
+		skip[id] = true
+	}
+
 	// Render each agent
 	var errors []string
 	for agentID, agentFile := range result.AgentFiles {
-		// Read agent entries
-		entries, err := jsonl.ReadAll[models.ConversationEntry](agentFile)
+		if skip[agentID] {
+			continue
+		}
+		// Read agent entries, skipping lines that cannot be parsed (reported by ExportSession)
+		entries, _, err := agent.ReadAgentFile(agentFile)
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("agent %s: %v", truncateAgentID(agentID), err))
 			continue
 		}
 
-		// Render agent fragment
-		htmlContent, err := export.RenderAgentFragment(agentID, entries)
+		// Render agent fragment, linking to the agent's own source file
+		agentOpts := opts
+		if agentOpts.RawSource != "" {
+			agentOpts.RawSource = rawSourcePath(result.OutputDir, agentFile)
+		}
+		htmlContent, err := export.RenderAgentFragmentWithOptions(agentID, entries, agentOpts)
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("agent %s: %v", truncateAgentID(agentID), err))
 			continue
 		}
 
-		// Write agent HTML
-		agentPath := filepath.Join(agentsDir, truncateAgentID(agentID)+".html")
+		// Write agent HTML, named by the ID its section on the page loads it by
+		fileName := truncateAgentID(agentID)
+		if opts.StripIDs && opts.IDMap != nil {
+			fileName = opts.IDMap.Agent(agentID)
+		}
+		agentPath := filepath.Join(agentsDir, fileName+".html")
 		if err := os.WriteFile(agentPath, []byte(htmlContent), 0644); err != nil {
 			errors = append(errors, fmt.Sprintf("agent %s: %v", truncateAgentID(agentID), err))
 			continue
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// zipNames returns the file names in a zip archive.
+func zipNames(t *testing.T, data []byte) []string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestExport_Zip(t *testing.T) {
+	tmpDir, projectDir, projectPath := setupTestProject(t, "zip-export-test")
+	sessionID := createTestSessionWithAgents(t, projectDir, 2)
+
+	oldSessionID, oldFormat, oldOutputDir, oldClaudeDir, oldZip := exportSessionIDs, exportFormat, exportOutputDir, claudeDir, exportZip
+	defer func() {
+		exportSessionIDs, exportFormat, exportOutputDir, claudeDir, exportZip = oldSessionID, oldFormat, oldOutputDir, oldClaudeDir, oldZip
+	}()
+
+	zipPath := filepath.Join(tmpDir, "out", "session.zip")
+	exportSessionIDs = []string{sessionID}
+	exportFormat = "html"
+	exportOutputDir = zipPath
+	exportZip = true
+	claudeDir = tmpDir
+
+	if err := runExport(exportCmd, []string{projectPath}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	data, err := os.ReadFile(zipPath)
+	if err != nil {
+		t.Fatalf("archive not written: %v", err)
+	}
+	names := zipNames(t, data)
+	for _, want := range []string{"session/index.html", "session/static/style.css", "session/source/session.jsonl", "session/manifest.json"} {
+		if !containsName(names, want) {
+			t.Errorf("archive missing %s, got %v", want, names)
+		}
+	}
+}
+
+func TestExport_ZipToStdout(t *testing.T) {
+	tmpDir, projectDir, projectPath := setupTestProject(t, "zip-stdout-test")
+	sessionID := createTestSessionWithAgents(t, projectDir, 1)
+
+	oldSessionID, oldFormat, oldOutputDir, oldClaudeDir := exportSessionIDs, exportFormat, exportOutputDir, claudeDir
+	defer func() {
+		exportSessionIDs, exportFormat, exportOutputDir, claudeDir = oldSessionID, oldFormat, oldOutputDir, oldClaudeDir
+	}()
+
+	exportSessionIDs = []string{sessionID}
+	exportFormat = "html"
+	exportOutputDir = "-"
+	claudeDir = tmpDir
+
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	out := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- data
+	}()
+
+	runErr := runExport(exportCmd, []string{projectPath})
+	_ = w.Close()
+	os.Stdout = oldStdout
+	data := <-out
+
+	if runErr != nil {
+		t.Fatalf("Export failed: %v", runErr)
+	}
+	names := zipNames(t, data)
+	root := "claude-history-" + sessionID[:8] + "/"
+	if !containsName(names, root+"index.html") || !containsName(names, root+"static/style.css") {
+		t.Errorf("streamed archive should hold the export under %s, got %v", root, names)
+	}
+	if _, err := os.Stat("-"); !os.IsNotExist(err) {
+		t.Error("--output - should not create a directory named -")
+	}
+}
+
+func TestRunExport_ZipWithResume(t *testing.T) {
+	oldZip, oldResume, oldOutputDir := exportZip, exportResume, exportOutputDir
+	defer func() { exportZip, exportResume, exportOutputDir = oldZip, oldResume, oldOutputDir }()
+
+	exportZip = true
+	exportResume = true
+	exportOutputDir = filepath.Join(t.TempDir(), "out.zip")
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--resume cannot be combined with --zip") {
+		t.Errorf("expected --resume/--zip error, got %v", err)
+	}
+}
@@ -20,12 +20,12 @@ func TestExport_WindowsPaths(t *testing.T) {
 	tmpDir, projectDir, _ := setupTestProject(t, "windows-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -34,7 +34,7 @@ func TestExport_WindowsPaths(t *testing.T) {
 	// Use Windows-style path for output
 	outputDir := filepath.Join(tmpDir, "export-windows")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -82,12 +82,12 @@ func TestExport_UnixPaths(t *testing.T) {
 	tmpDir, projectDir, _ := setupTestProject(t, "unix-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -95,7 +95,7 @@ func TestExport_UnixPaths(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-unix")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -149,12 +149,12 @@ func TestExport_PathEncoding(t *testing.T) {
 
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -162,7 +162,7 @@ func TestExport_PathEncoding(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-encoding")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -195,12 +195,12 @@ func TestExport_PathWithSpecialChars(t *testing.T) {
 
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -208,7 +208,7 @@ func TestExport_PathWithSpecialChars(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-special")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -243,12 +243,12 @@ func TestExport_LineEndings_CRLF(t *testing.T) {
 		t.Fatalf("failed to create session: %v", err)
 	}
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -256,7 +256,7 @@ func TestExport_LineEndings_CRLF(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-crlf")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -295,12 +295,12 @@ func TestExport_LineEndings_LF(t *testing.T) {
 		t.Fatalf("failed to create session: %v", err)
 	}
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -308,7 +308,7 @@ func TestExport_LineEndings_LF(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-lf")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -330,12 +330,12 @@ func TestExport_FileSeparators(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "separators-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -343,7 +343,7 @@ func TestExport_FileSeparators(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-separators")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -380,19 +380,19 @@ func TestExport_TempDirectory(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "temp-dir-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
 	}()
 
 	// Don't specify output directory - use auto-generated temp path
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = ""
 	claudeDir = tmpDir
@@ -424,12 +424,12 @@ func TestExport_AbsoluteVsRelative(t *testing.T) {
 	tmpDir, projectDir, _ := setupTestProject(t, "abs-rel-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -438,7 +438,7 @@ func TestExport_AbsoluteVsRelative(t *testing.T) {
 	// Test with absolute output path
 	absOutputDir := filepath.Join(tmpDir, "export-absolute")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = absOutputDir
 	claudeDir = tmpDir
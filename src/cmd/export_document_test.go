@@ -0,0 +1,1258 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/encoding"
+	"github.com/randlee/claude-history/pkg/export"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// setupDocumentExport creates a minimal session and exports its source files.
+func setupDocumentExport(t *testing.T) (*export.ExportResult, string, string, string) {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	projectPath := filepath.Join(tempDir, "test-project")
+	claudeDir := filepath.Join(tempDir, ".claude")
+	projectDir := filepath.Join(claudeDir, "projects", encoding.EncodePath(projectPath))
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create Claude project directory: %v", err)
+	}
+
+	sessionID := "22222222-2222-2222-2222-222222222222"
+	sessionContent := `{"uuid":"entry-1","type":"user","timestamp":"2026-02-01T10:00:00Z","sessionId":"22222222-2222-2222-2222-222222222222","message":[{"type":"text","text":"List files"}]}
+{"uuid":"entry-2","type":"assistant","timestamp":"2026-02-01T10:00:01Z","sessionId":"22222222-2222-2222-2222-222222222222","message":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"ls"}}]}
+{"uuid":"entry-3","type":"user","timestamp":"2026-02-01T10:00:02Z","sessionId":"22222222-2222-2222-2222-222222222222","message":[{"type":"tool_result","tool_use_id":"toolu_1","content":"main.go"}]}
+`
+	if err := os.WriteFile(filepath.Join(projectDir, sessionID+".jsonl"), []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to write session file: %v", err)
+	}
+
+	result, err := export.ExportSession(projectPath, sessionID, export.ExportOptions{
+		OutputDir: filepath.Join(tempDir, "export-output"),
+		ClaudeDir: claudeDir,
+	})
+	if err != nil {
+		t.Fatalf("ExportSession failed: %v", err)
+	}
+	return result, projectPath, projectDir, sessionID
+}
+
+func TestRenderDocument_Formats(t *testing.T) {
+	tests := []struct {
+		format   string
+		filename string
+		contains string
+	}{
+		{"markdown", "conversation.md", "## User"},
+		{"json", "conversation.json", `"tool_calls"`},
+		{"text", "conversation.txt", "USER"},
+		{"csv", "conversation.csv", "toolu_1"},
+		{"ipynb", "conversation.ipynb", `"nbformat": 4`},
+		{"vtt", "conversation.vtt", "WEBVTT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			result, projectPath, projectDir, sessionID := setupDocumentExport(t)
+
+			exporter, err := export.GetExporter(tt.format)
+			if err != nil {
+				t.Fatalf("GetExporter(%q) error = %v", tt.format, err)
+			}
+
+			docPath, err := renderDocument(exporter, result, projectPath, projectDir, sessionID)
+			if err != nil {
+				t.Fatalf("renderDocument() error = %v", err)
+			}
+			if filepath.Base(docPath) != tt.filename {
+				t.Errorf("document name = %q, want %q", filepath.Base(docPath), tt.filename)
+			}
+
+			content, err := os.ReadFile(docPath)
+			if err != nil {
+				t.Fatalf("failed to read document: %v", err)
+			}
+			if !strings.Contains(string(content), tt.contains) {
+				t.Errorf("document should contain %q, got:\n%s", tt.contains, content)
+			}
+		})
+	}
+}
+
+func TestRenderDocument_JSONIncludesProjectPath(t *testing.T) {
+	result, projectPath, projectDir, sessionID := setupDocumentExport(t)
+
+	docPath, err := renderDocument(export.JSONExporter{}, result, projectPath, projectDir, sessionID)
+	if err != nil {
+		t.Fatalf("renderDocument() error = %v", err)
+	}
+
+	content, err := os.ReadFile(docPath)
+	if err != nil {
+		t.Fatalf("failed to read document: %v", err)
+	}
+	var doc export.JSONExport
+	if err := json.Unmarshal(content, &doc); err != nil {
+		t.Fatalf("invalid JSON export: %v", err)
+	}
+	if doc.ProjectPath != projectPath {
+		t.Errorf("ProjectPath = %q, want %q", doc.ProjectPath, projectPath)
+	}
+}
+
+func TestApplyExportFields(t *testing.T) {
+	e, err := applyExportFields(export.CSVExporter{}, []string{"tool", "uuid"})
+	if err != nil {
+		t.Fatalf("applyExportFields() error = %v", err)
+	}
+	if csvExporter, ok := e.(export.CSVExporter); !ok || len(csvExporter.Fields) != 2 {
+		t.Errorf("expected CSVExporter with fields, got %#v", e)
+	}
+
+	if _, err := applyExportFields(export.JSONExporter{}, []string{"bogus"}); err == nil || !strings.Contains(err.Error(), "valid fields") {
+		t.Errorf("expected unknown field error, got %v", err)
+	}
+
+	if _, err := applyExportFields(export.HTMLExporter{}, []string{"uuid"}); err == nil {
+		t.Error("expected error for format without field selection")
+	}
+	if _, err := applyExportFields(nil, []string{"uuid"}); err == nil {
+		t.Error("expected error for jsonl format")
+	}
+}
+
+func TestWithRenderOptions(t *testing.T) {
+	opts := export.ExportOptions{RelativeTimes: true}
+
+	e := withRenderOptions(export.HTMLExporter{}, opts)
+	htmlExporter, ok := e.(export.HTMLExporter)
+	if !ok || !htmlExporter.Options.RelativeTimes {
+		t.Errorf("expected HTMLExporter with options, got %#v", e)
+	}
+
+	if _, ok := withRenderOptions(export.CSVExporter{}, opts).(export.CSVExporter); !ok {
+		t.Error("exporters without options should be returned unchanged")
+	}
+	if e := withRenderOptions(nil, opts); e != nil {
+		t.Errorf("nil exporter (jsonl) should stay nil, got %#v", e)
+	}
+}
+
+func TestWithAgentSpans(t *testing.T) {
+	result, _, projectDir, sessionID := setupDocumentExport(t)
+
+	agentFile := filepath.Join(t.TempDir(), "agent-a1b2c3d.jsonl")
+	agentContent := `{"uuid":"a-1","type":"assistant","timestamp":"2026-02-01T10:00:05Z","agentId":"a1b2c3d"}
+{"uuid":"a-2","type":"assistant","timestamp":"2026-02-01T10:00:09Z","agentId":"a1b2c3d"}
+`
+	if err := os.WriteFile(agentFile, []byte(agentContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	subagentsDir := filepath.Join(projectDir, sessionID, "subagents")
+	if err := os.MkdirAll(subagentsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(subagentsDir, "agent-a1b2c3d.jsonl"), []byte(agentContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result.AgentFiles = map[string]string{"a1b2c3d": agentFile}
+
+	exporter, err := withAgentSpans(export.HTMLExporter{Options: export.ExportOptions{RelativeTimes: true}}, result, projectDir, sessionID, true)
+	if err != nil {
+		t.Fatalf("withAgentSpans() error = %v", err)
+	}
+	htmlExporter, ok := exporter.(export.HTMLExporter)
+	if !ok {
+		t.Fatalf("expected HTMLExporter, got %T", exporter)
+	}
+	if !htmlExporter.Options.RelativeTimes {
+		t.Error("existing render options should be preserved")
+	}
+	if len(htmlExporter.Options.Timeline) != 1 || htmlExporter.Options.Timeline[0].AgentID != "a1b2c3d" {
+		t.Fatalf("Timeline = %+v, want one span for a1b2c3d", htmlExporter.Options.Timeline)
+	}
+	if htmlExporter.Options.Timeline[0].EntryCount != 2 {
+		t.Errorf("EntryCount = %d, want 2", htmlExporter.Options.Timeline[0].EntryCount)
+	}
+
+	// Without the timeline, the spans are still kept for the subagent durations
+	exporter, err = withAgentSpans(export.HTMLExporter{}, result, projectDir, sessionID, false)
+	if err != nil {
+		t.Fatalf("withAgentSpans(no timeline) error = %v", err)
+	}
+	if opts := exporter.(export.HTMLExporter).Options; len(opts.Timeline) != 0 || len(opts.AgentSpans) != 1 {
+		t.Errorf("withAgentSpans(no timeline) Timeline = %+v, AgentSpans = %+v; want only AgentSpans", opts.Timeline, opts.AgentSpans)
+	}
+
+	// Non-HTML exporters are unchanged
+	md, err := withAgentSpans(export.MarkdownExporter{}, result, projectDir, sessionID, true)
+	if err != nil || md != (export.MarkdownExporter{}) {
+		t.Errorf("withAgentSpans(markdown) = %v, %v; want unchanged exporter", md, err)
+	}
+}
+
+func TestRunExport_ResumeRequiresOutput(t *testing.T) {
+	oldResume, oldOutputDir, oldFormat := exportResume, exportOutputDir, exportFormat
+	defer func() {
+		exportResume, exportOutputDir, exportFormat = oldResume, oldOutputDir, oldFormat
+	}()
+
+	exportResume = true
+	exportOutputDir = ""
+	exportFormat = "html"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--resume requires --output") {
+		t.Errorf("expected --resume requires --output error, got %v", err)
+	}
+}
+
+func TestRenderHTML_ManifestKeepsResumeRecords(t *testing.T) {
+	result, projectPath, projectDir, sessionID := setupDocumentExport(t)
+
+	if err := renderHTML(export.HTMLExporter{}, result, projectPath, projectDir, sessionID); err != nil {
+		t.Fatalf("renderHTML() error = %v", err)
+	}
+
+	manifest, err := export.ReadManifest(result.OutputDir)
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	if err := export.VerifyExportedFiles(result.OutputDir, manifest); err != nil {
+		t.Errorf("final manifest should verify for --resume: %v", err)
+	}
+}
+
+func TestRunExport_NegativeMaxOutputBytes(t *testing.T) {
+	oldMax, oldFormat := exportMaxOutput, exportFormat
+	defer func() { exportMaxOutput, exportFormat = oldMax, oldFormat }()
+
+	exportMaxOutput = -1
+	exportFormat = "html"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--max-output-bytes") {
+		t.Errorf("expected --max-output-bytes error, got %v", err)
+	}
+}
+
+func TestWithRenderOptions_MaxToolOutputBytes(t *testing.T) {
+	exporter := withRenderOptions(export.HTMLExporter{}, export.ExportOptions{MaxToolOutputBytes: 1024})
+	htmlExporter, ok := exporter.(export.HTMLExporter)
+	if !ok || htmlExporter.Options.MaxToolOutputBytes != 1024 {
+		t.Errorf("withRenderOptions() = %+v, want MaxToolOutputBytes 1024", exporter)
+	}
+}
+
+func TestRunExport_InvalidSortAgents(t *testing.T) {
+	oldSort, oldFormat := exportSortAgents, exportFormat
+	defer func() { exportSortAgents, exportFormat = oldSort, oldFormat }()
+
+	exportSortAgents = "size"
+	exportFormat = "html"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--sort-agents") {
+		t.Errorf("expected --sort-agents error, got %v", err)
+	}
+}
+
+func TestLoadExportData_SortsAgents(t *testing.T) {
+	oldSort := exportSortAgents
+	defer func() { exportSortAgents = oldSort }()
+
+	result, projectPath, projectDir, sessionID := setupDocumentExport(t)
+	subagentsDir := filepath.Join(projectDir, sessionID, "subagents")
+	if err := os.MkdirAll(subagentsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	agentFiles := map[string]int{"a1111111": 1, "a2222222": 3}
+	for id, count := range agentFiles {
+		var content strings.Builder
+		for i := 0; i < count; i++ {
+			content.WriteString(`{"uuid":"` + id + `-` + string(rune('0'+i)) + `","type":"assistant","timestamp":"2026-02-01T10:00:05Z","agentId":"` + id + `"}` + "\n")
+		}
+		if err := os.WriteFile(filepath.Join(subagentsDir, "agent-"+id+".jsonl"), []byte(content.String()), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exportSortAgents = "entries"
+	data, err := loadExportData(result, projectPath, projectDir, sessionID)
+	if err != nil {
+		t.Fatalf("loadExportData() error = %v", err)
+	}
+	if len(data.agentNodes) != 2 || data.agentNodes[0].AgentID != "a2222222" {
+		t.Fatalf("agents should be sorted by entry count, got %+v", data.agentNodes)
+	}
+}
+
+func TestRunExport_TemplateRequiresHTML(t *testing.T) {
+	oldTemplate, oldFormat := exportTemplate, exportFormat
+	defer func() { exportTemplate, exportFormat = oldTemplate, oldFormat }()
+
+	exportTemplate = "layout.html"
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--template is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_InvalidTemplate(t *testing.T) {
+	oldTemplate, oldFormat := exportTemplate, exportFormat
+	defer func() { exportTemplate, exportFormat = oldTemplate, oldFormat }()
+
+	layout := filepath.Join(t.TempDir(), "layout.html")
+	if err := os.WriteFile(layout, []byte("<html>\n{{range}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	exportTemplate = layout
+	exportFormat = "html"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "2 | {{range}}") {
+		t.Errorf("expected template error with line context, got %v", err)
+	}
+}
+
+func TestRenderHTML_CustomTemplate(t *testing.T) {
+	result, projectPath, projectDir, sessionID := setupDocumentExport(t)
+
+	layout := filepath.Join(t.TempDir(), "layout.html")
+	if err := os.WriteFile(layout, []byte(`<body>{{range .Entries}}<section>{{.HTML}}</section>{{end}}</body>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	exporter := export.HTMLExporter{Options: export.ExportOptions{TemplateFile: layout}}
+	if err := renderHTML(exporter, result, projectPath, projectDir, sessionID); err != nil {
+		t.Fatalf("renderHTML() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(result.OutputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "<body><section>") || !strings.Contains(string(content), "List files") {
+		t.Errorf("index.html should use the custom layout, got:\n%s", content)
+	}
+}
+
+func TestRunExport_IncludeRawRequiresHTML(t *testing.T) {
+	oldRaw, oldFormat := exportIncludeRaw, exportFormat
+	defer func() { exportIncludeRaw, exportFormat = oldRaw, oldFormat }()
+
+	exportIncludeRaw = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--include-raw is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRenderHTML_IncludeRaw(t *testing.T) {
+	result, projectPath, projectDir, sessionID := setupDocumentExport(t)
+
+	oldRaw := exportIncludeRaw
+	defer func() { exportIncludeRaw = oldRaw }()
+	exportIncludeRaw = true
+
+	if err := renderHTML(export.HTMLExporter{}, result, projectPath, projectDir, sessionID); err != nil {
+		t.Fatalf("renderHTML() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(result.OutputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`href="source/session.jsonl#L1"`, `href="source/session.jsonl#L2"`} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("index.html should contain %q", want)
+		}
+	}
+}
+
+func TestRawSourcePath(t *testing.T) {
+	out := filepath.Join("tmp", "export")
+	if got := rawSourcePath(out, filepath.Join(out, "source", "agents", "agent-a1.jsonl")); got != "source/agents/agent-a1.jsonl" {
+		t.Errorf("rawSourcePath() = %q", got)
+	}
+}
+
+func TestWithoutStats(t *testing.T) {
+	md, err := withoutStats(export.MarkdownExporter{})
+	if err != nil || md != (export.MarkdownExporter{NoStats: true}) {
+		t.Errorf("withoutStats(markdown) = %v, %v", md, err)
+	}
+	text, err := withoutStats(export.TextExporter{})
+	if err != nil || text != (export.TextExporter{NoStats: true}) {
+		t.Errorf("withoutStats(text) = %v, %v", text, err)
+	}
+	if _, err := withoutStats(export.HTMLExporter{}); err == nil || !strings.Contains(err.Error(), "--no-stats") {
+		t.Errorf("expected unsupported format error, got %v", err)
+	}
+	if _, err := withoutStats(nil); err == nil {
+		t.Error("expected error for jsonl format")
+	}
+}
+
+func TestWithFrontMatter(t *testing.T) {
+	md, err := withFrontMatter(export.MarkdownExporter{Locale: "de"})
+	if err != nil || md != (export.MarkdownExporter{Locale: "de", FrontMatter: true}) {
+		t.Errorf("withFrontMatter(markdown) = %v, %v", md, err)
+	}
+	for _, exporter := range []export.Exporter{export.TextExporter{}, export.HTMLExporter{}, nil} {
+		if _, err := withFrontMatter(exporter); err == nil || !strings.Contains(err.Error(), "--front-matter") {
+			t.Errorf("withFrontMatter(%T) error = %v, want unsupported format", exporter, err)
+		}
+	}
+}
+
+func TestRunExport_AgentRejectsSessionOnlyFlags(t *testing.T) {
+	oldAgent, oldTimeline, oldFormat := exportAgentID, exportTimeline, exportFormat
+	defer func() { exportAgentID, exportTimeline, exportFormat = oldAgent, oldTimeline, oldFormat }()
+
+	exportAgentID = "a1b2c3d"
+	exportTimeline = true
+	exportFormat = "html"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--agent cannot be combined") {
+		t.Errorf("expected --agent conflict error, got %v", err)
+	}
+}
+
+func TestRenderAgentDocument(t *testing.T) {
+	_, projectPath, projectDir, sessionID := setupDocumentExport(t)
+
+	subagentsDir := filepath.Join(projectDir, sessionID, "subagents")
+	if err := os.MkdirAll(subagentsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	agentContent := `{"uuid":"a-1","type":"user","timestamp":"2026-02-01T10:00:05Z","agentId":"a1b2c3d","message":"Find the bug"}
+{"uuid":"a-2","type":"assistant","timestamp":"2026-02-01T10:00:09Z","agentId":"a1b2c3d","message":{"role":"assistant","content":"Found it"}}
+`
+	if err := os.WriteFile(filepath.Join(subagentsDir, "agent-a1b2c3d.jsonl"), []byte(agentContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	claudeDir := filepath.Dir(filepath.Dir(projectDir))
+	result, err := export.ExportAgent(projectPath, sessionID, "a1b2", export.ExportOptions{OutputDir: t.TempDir(), ClaudeDir: claudeDir})
+	if err != nil {
+		t.Fatalf("ExportAgent() error = %v", err)
+	}
+
+	tests := []struct {
+		exporter export.Exporter
+		filename string
+		contains string
+	}{
+		{export.HTMLExporter{}, "index.html", "Orchestrator"},
+		{export.MarkdownExporter{}, "conversation.md", "Found it"},
+	}
+	for _, tt := range tests {
+		docPath, err := renderAgentDocument(tt.exporter, result, projectPath, projectDir)
+		if err != nil {
+			t.Fatalf("renderAgentDocument() error = %v", err)
+		}
+		if filepath.Base(docPath) != tt.filename {
+			t.Errorf("document name = %q, want %q", filepath.Base(docPath), tt.filename)
+		}
+		content, err := os.ReadFile(docPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), tt.contains) || strings.Contains(string(content), "List files") {
+			t.Errorf("%s should contain only the agent conversation, got:\n%s", tt.filename, content)
+		}
+	}
+}
+
+func TestRunExport_NegativeSummaryLength(t *testing.T) {
+	oldLen, oldFormat := exportSummaryLen, exportFormat
+	defer func() { exportSummaryLen, exportFormat = oldLen, oldFormat }()
+
+	exportSummaryLen = -5
+	exportFormat = "html"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--summary-length") {
+		t.Errorf("expected --summary-length error, got %v", err)
+	}
+}
+
+func TestExportCmd_SummaryLengthDefault(t *testing.T) {
+	flag := exportCmd.Flags().Lookup("summary-length")
+	if flag == nil || flag.DefValue != "60" {
+		t.Errorf("--summary-length default = %v, want 60", flag)
+	}
+}
+
+func TestWithRenderOptions_Locale(t *testing.T) {
+	opts := export.ExportOptions{Locale: "de"}
+
+	if e := withRenderOptions(export.MarkdownExporter{NoStats: true}, opts); e != (export.MarkdownExporter{NoStats: true, Locale: "de"}) {
+		t.Errorf("withRenderOptions(markdown) = %#v", e)
+	}
+	if e := withRenderOptions(export.TextExporter{}, opts); e != (export.TextExporter{Locale: "de"}) {
+		t.Errorf("withRenderOptions(text) = %#v", e)
+	}
+	if e, ok := withRenderOptions(export.HTMLExporter{}, opts).(export.HTMLExporter); !ok || e.Options.Locale != "de" {
+		t.Errorf("withRenderOptions(html) = %#v", e)
+	}
+
+	// --no-stats applied afterwards keeps the locale
+	md, err := withoutStats(withRenderOptions(export.MarkdownExporter{}, opts))
+	if err != nil || md != (export.MarkdownExporter{NoStats: true, Locale: "de"}) {
+		t.Errorf("withoutStats() = %#v, %v", md, err)
+	}
+}
+
+func TestWithRenderOptions_WrapWidth(t *testing.T) {
+	opts := export.ExportOptions{WrapWidth: 80}
+
+	if e := withRenderOptions(export.MarkdownExporter{}, opts); e != (export.MarkdownExporter{WrapWidth: 80}) {
+		t.Errorf("withRenderOptions(markdown) = %#v", e)
+	}
+	if e := withRenderOptions(export.TextExporter{NoStats: true}, opts); e != (export.TextExporter{NoStats: true, WrapWidth: 80}) {
+		t.Errorf("withRenderOptions(text) = %#v", e)
+	}
+}
+
+func TestRunExport_WrapRequiresMarkdownOrText(t *testing.T) {
+	oldWrap, oldFormat := exportWrap, exportFormat
+	defer func() { exportWrap, exportFormat = oldWrap, oldFormat }()
+
+	exportWrap = 80
+	exportFormat = "html"
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--wrap is only supported for markdown and text") {
+		t.Errorf("expected markdown/text-only error, got %v", err)
+	}
+
+	exportWrap = -1
+	exportFormat = "text"
+	err = runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--wrap must not be negative") {
+		t.Errorf("expected negative width error, got %v", err)
+	}
+}
+
+func TestRunExport_DaySeparatorsRequiresHTML(t *testing.T) {
+	oldDays, oldFormat := exportDaySeparators, exportFormat
+	defer func() { exportDaySeparators, exportFormat = oldDays, oldFormat }()
+
+	exportDaySeparators = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--day-separators is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_InvalidTimezone(t *testing.T) {
+	oldTZ := exportTimezone
+	defer func() { exportTimezone = oldTZ }()
+
+	exportTimezone = "Mars/Olympus_Mons"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "invalid --timezone") {
+		t.Errorf("expected invalid timezone error, got %v", err)
+	}
+}
+
+func TestRunExport_ShowAllRequiresHTML(t *testing.T) {
+	oldShowAll, oldFormat := exportShowAll, exportFormat
+	defer func() { exportShowAll, exportFormat = oldShowAll, oldFormat }()
+
+	exportShowAll = true
+	exportFormat = "text"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--show-all is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_DebugInspectorRequiresHTML(t *testing.T) {
+	oldInspector, oldFormat := exportInspector, exportFormat
+	defer func() { exportInspector, exportFormat = oldInspector, oldFormat }()
+
+	exportInspector = true
+	exportFormat = "json"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--debug-inspector is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_NoIconsRequiresHTML(t *testing.T) {
+	oldNoIcons, oldFormat := exportNoIcons, exportFormat
+	defer func() { exportNoIcons, exportFormat = oldNoIcons, oldFormat }()
+
+	exportNoIcons = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--no-icons is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_MarkdownResultsRequiresHTML(t *testing.T) {
+	oldTools, oldFormat := exportMarkdownTools, exportFormat
+	defer func() { exportMarkdownTools, exportFormat = oldTools, oldFormat }()
+
+	exportMarkdownTools = []string{"WebFetch"}
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--markdown-results is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_ExpandToolsRequiresHTML(t *testing.T) {
+	oldTools, oldFormat := exportExpandTools, exportFormat
+	defer func() { exportExpandTools, exportFormat = oldTools, oldFormat }()
+
+	exportExpandTools = []string{"Edit"}
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--expand-tools is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_HideToolResultsRequiresHTML(t *testing.T) {
+	oldHide, oldFormat := exportHideResults, exportFormat
+	defer func() { exportHideResults, exportFormat = oldHide, oldFormat }()
+
+	exportHideResults = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--hide-tool-results is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_GroupByToolRequiresHTML(t *testing.T) {
+	oldGroup, oldFormat := exportGroupByTool, exportFormat
+	defer func() { exportGroupByTool, exportFormat = oldGroup, oldFormat }()
+
+	exportGroupByTool = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--group-by-tool is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_CollapseRepeatsRequiresHTML(t *testing.T) {
+	oldCollapse, oldFormat := exportCollapseReads, exportFormat
+	defer func() { exportCollapseReads, exportFormat = oldCollapse, oldFormat }()
+
+	exportCollapseReads = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--collapse-repeats is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_LineNumbersRequiresHTML(t *testing.T) {
+	oldLineNumbers, oldFormat := exportLineNumbers, exportFormat
+	defer func() { exportLineNumbers, exportFormat = oldLineNumbers, oldFormat }()
+
+	exportLineNumbers = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--line-numbers is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_FilesTouchedRequiresHTML(t *testing.T) {
+	oldFiles, oldFormat := exportFilesTouched, exportFormat
+	defer func() { exportFilesTouched, exportFormat = oldFiles, oldFormat }()
+
+	exportFilesTouched = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--files-touched is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_AnnotationsRequiresHTML(t *testing.T) {
+	oldAnnotations, oldFormat := exportAnnotations, exportFormat
+	defer func() { exportAnnotations, exportFormat = oldAnnotations, oldFormat }()
+
+	exportAnnotations = "review.json"
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--annotations is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_StripIDsRequiresHTML(t *testing.T) {
+	oldStrip, oldFormat := exportStripIDs, exportFormat
+	defer func() { exportStripIDs, exportFormat = oldStrip, oldFormat }()
+
+	exportStripIDs = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--strip-uuids is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_StripIDsWithIncludeRaw(t *testing.T) {
+	oldStrip, oldRaw, oldFormat := exportStripIDs, exportIncludeRaw, exportFormat
+	defer func() { exportStripIDs, exportIncludeRaw, exportFormat = oldStrip, oldRaw, oldFormat }()
+
+	exportStripIDs = true
+	exportIncludeRaw = true
+	exportFormat = "html"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--strip-uuids cannot be combined with --include-raw") {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+}
+
+func TestWithAnnotations(t *testing.T) {
+	oldAnnotations := exportAnnotations
+	defer func() { exportAnnotations = oldAnnotations }()
+
+	projectDir := t.TempDir()
+	sessionDir := filepath.Join(projectDir, "s1")
+	if err := os.MkdirAll(sessionDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sidecar := filepath.Join(sessionDir, export.AnnotationsFileName)
+
+	// Without a sidecar the exporter is unchanged
+	exportAnnotations = ""
+	exporter, err := withAnnotations(export.HTMLExporter{}, annotationsPath(projectDir, "s1"))
+	if err != nil || exporter.(export.HTMLExporter).Options.Annotations != nil {
+		t.Errorf("withAnnotations(no sidecar) = %v, %v", exporter, err)
+	}
+
+	if err := os.WriteFile(sidecar, []byte(`{"u1": {"note": "Look"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	exporter, err = withAnnotations(export.HTMLExporter{}, annotationsPath(projectDir, "s1"))
+	if err != nil || exporter.(export.HTMLExporter).Options.Annotations["u1"].Note != "Look" {
+		t.Errorf("withAnnotations(sidecar) = %v, %v", exporter, err)
+	}
+
+	// --annotations replaces the sidecar
+	flagFile := filepath.Join(t.TempDir(), "review.json")
+	if err := os.WriteFile(flagFile, []byte(`{"u2": {"tags": ["bug"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	exportAnnotations = flagFile
+	exporter, err = withAnnotations(export.HTMLExporter{}, annotationsPath(projectDir, "s1"))
+	if annotations := exporter.(export.HTMLExporter).Options.Annotations; err != nil || len(annotations) != 1 || annotations["u2"].Tags[0] != "bug" {
+		t.Errorf("withAnnotations(--annotations) = %v, %v", exporter, err)
+	}
+
+	// An invalid file is an error for the caller to report
+	if err := os.WriteFile(flagFile, []byte(`{`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := withAnnotations(export.HTMLExporter{}, flagFile); err == nil {
+		t.Error("withAnnotations(invalid) should fail")
+	}
+	if md, err := withAnnotations(export.MarkdownExporter{}, flagFile); err != nil || md != (export.MarkdownExporter{}) {
+		t.Errorf("withAnnotations(markdown) = %v, %v; want unchanged exporter", md, err)
+	}
+}
+
+func TestRunExport_LimitAgentsRequiresHTML(t *testing.T) {
+	oldLimit, oldFormat := exportLimitAgents, exportFormat
+	defer func() { exportLimitAgents, exportFormat = oldLimit, oldFormat }()
+
+	exportLimitAgents = 5
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--limit-agents is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_LimitAgentsNegative(t *testing.T) {
+	oldLimit := exportLimitAgents
+	defer func() { exportLimitAgents = oldLimit }()
+
+	exportLimitAgents = -1
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--limit-agents must not be negative") {
+		t.Errorf("expected negative value error, got %v", err)
+	}
+}
+
+func TestRunExport_ShowGapsRequiresHTML(t *testing.T) {
+	oldGaps, oldFormat := exportShowGaps, exportFormat
+	defer func() { exportShowGaps, exportFormat = oldGaps, oldFormat }()
+
+	exportShowGaps = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--show-gaps is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_GapThresholdMustBePositive(t *testing.T) {
+	oldGaps, oldThreshold, oldFormat := exportShowGaps, exportGapThreshold, exportFormat
+	defer func() { exportShowGaps, exportGapThreshold, exportFormat = oldGaps, oldThreshold, oldFormat }()
+
+	exportShowGaps = true
+	exportGapThreshold = 0
+	exportFormat = "html"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--gap-threshold must be positive") {
+		t.Errorf("expected threshold error, got %v", err)
+	}
+}
+
+func TestRunExport_PageSizeRequiresHTML(t *testing.T) {
+	oldPageSize, oldFormat := exportPageSize, exportFormat
+	defer func() { exportPageSize, exportFormat = oldPageSize, oldFormat }()
+
+	exportPageSize = 100
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--page-size is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_NegativePageSize(t *testing.T) {
+	oldPageSize := exportPageSize
+	defer func() { exportPageSize = oldPageSize }()
+
+	exportPageSize = -1
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--page-size must not be negative") {
+		t.Errorf("expected negative page size error, got %v", err)
+	}
+}
+
+func TestRunExport_SessionOrLatestRequired(t *testing.T) {
+	oldSession, oldLatest := exportSessionIDs, exportLatest
+	defer func() { exportSessionIDs, exportLatest = oldSession, oldLatest }()
+
+	exportSessionIDs, exportLatest = nil, false
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--session, --latest, or --select is required") {
+		t.Errorf("expected missing session error, got %v", err)
+	}
+
+	exportSessionIDs, exportLatest = []string{"abc123"}, true
+	err = runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--latest cannot be combined with --session") {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+
+	oldSelect := exportSelect
+	defer func() { exportSelect = oldSelect }()
+	exportSessionIDs, exportLatest, exportSelect = []string{"abc123"}, false, true
+	err = runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--select cannot be combined with --session or --latest") {
+		t.Errorf("expected select conflict error, got %v", err)
+	}
+}
+
+func TestRunExport_LatestEmptyProject(t *testing.T) {
+	tmpDir, _, projectPath := setupTestProject(t, "empty-project")
+
+	oldClaudeDir, oldSession, oldLatest := claudeDir, exportSessionIDs, exportLatest
+	defer func() { claudeDir, exportSessionIDs, exportLatest = oldClaudeDir, oldSession, oldLatest }()
+	claudeDir = tmpDir
+	exportSessionIDs, exportLatest = nil, true
+
+	err := runExport(exportCmd, []string{projectPath})
+	if err == nil || !strings.Contains(err.Error(), "no sessions found") {
+		t.Errorf("expected no sessions error, got %v", err)
+	}
+}
+
+func TestRunExport_GroupParallelToolsRequiresHTML(t *testing.T) {
+	oldGroup, oldFormat := exportGroupParallel, exportFormat
+	defer func() { exportGroupParallel, exportFormat = oldGroup, oldFormat }()
+
+	exportGroupParallel = true
+	exportFormat = "text"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--group-parallel-tools is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_ShowLegendRequiresHTML(t *testing.T) {
+	oldLegend, oldFormat := exportShowLegend, exportFormat
+	defer func() { exportShowLegend, exportFormat = oldLegend, oldFormat }()
+
+	exportShowLegend = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--show-legend is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_SidebarRequiresHTML(t *testing.T) {
+	oldSidebar, oldFormat := exportSidebar, exportFormat
+	defer func() { exportSidebar, exportFormat = oldSidebar, oldFormat }()
+
+	exportSidebar = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--sidebar is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_SearchIndexRequiresHTML(t *testing.T) {
+	oldIndex, oldFormat := exportSearchIndex, exportFormat
+	defer func() { exportSearchIndex, exportFormat = oldIndex, oldFormat }()
+
+	exportSearchIndex = true
+	exportFormat = "text"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--search-index is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_EmojiShortcodesRequiresHTML(t *testing.T) {
+	oldEmoji, oldFormat := exportEmoji, exportFormat
+	defer func() { exportEmoji, exportFormat = oldEmoji, oldFormat }()
+
+	exportEmoji = true
+	exportFormat = "text"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--emoji-shortcodes is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_AllowSafeHTMLRequiresHTML(t *testing.T) {
+	oldSafeHTML, oldFormat := exportSafeHTML, exportFormat
+	defer func() { exportSafeHTML, exportFormat = oldSafeHTML, oldFormat }()
+
+	exportSafeHTML = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--allow-safe-html is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_ReplayRequiresHTML(t *testing.T) {
+	oldReplay, oldFormat := exportReplay, exportFormat
+	defer func() { exportReplay, exportFormat = oldReplay, oldFormat }()
+
+	exportReplay = true
+	exportFormat = "text"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--replay is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_ReplayDelayMustBePositive(t *testing.T) {
+	oldReplay, oldDelay, oldFormat := exportReplay, exportReplayDelay, exportFormat
+	defer func() { exportReplay, exportReplayDelay, exportFormat = oldReplay, oldDelay, oldFormat }()
+
+	exportReplay = true
+	exportReplayDelay = 0
+	exportFormat = "html"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--replay-delay must be positive") {
+		t.Errorf("expected replay delay error, got %v", err)
+	}
+}
+
+func TestRunExport_NoJSRequiresHTML(t *testing.T) {
+	oldNoJS, oldFormat := exportNoJS, exportFormat
+	defer func() { exportNoJS, exportFormat = oldNoJS, oldFormat }()
+
+	exportNoJS = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--no-js is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_NoJSRejectsReplay(t *testing.T) {
+	oldNoJS, oldReplay, oldFormat := exportNoJS, exportReplay, exportFormat
+	defer func() { exportNoJS, exportReplay, exportFormat = oldNoJS, oldReplay, oldFormat }()
+
+	exportNoJS = true
+	exportReplay = true
+	exportFormat = "html"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--no-js cannot be combined with --replay") {
+		t.Errorf("expected replay conflict error, got %v", err)
+	}
+}
+
+func TestParseAvatars(t *testing.T) {
+	avatars, err := parseAvatars([]string{"user=RL", "assistant=C"}, []string{"assistant=https://example.com/c.png?a=1,b=2"})
+	if err != nil {
+		t.Fatalf("parseAvatars() error = %v", err)
+	}
+	want := map[models.EntryType]export.Avatar{
+		models.EntryTypeUser:      {Initials: "RL"},
+		models.EntryTypeAssistant: {Initials: "C", ImageURL: "https://example.com/c.png?a=1,b=2"},
+	}
+	if !reflect.DeepEqual(avatars, want) {
+		t.Errorf("parseAvatars() = %v, want %v", avatars, want)
+	}
+
+	if avatars, err := parseAvatars(nil, nil); err != nil || avatars != nil {
+		t.Errorf("parseAvatars(nil, nil) = %v, %v, want nil", avatars, err)
+	}
+}
+
+func TestParseAvatars_Invalid(t *testing.T) {
+	tests := []struct {
+		initials, images []string
+		wantErr          string
+	}{
+		{[]string{"user"}, nil, "want type=value"},
+		{[]string{"user="}, nil, "want type=value"},
+		{[]string{"robot=R"}, nil, "type must be user, assistant"},
+		{[]string{"user=ABCD"}, nil, "longer than 3 characters"},
+		{nil, []string{"user=javascript:alert(1)"}, "--avatar-image"},
+		{nil, []string{"user=file:///me.png"}, "must be an http(s) URL"},
+	}
+	for _, tt := range tests {
+		_, err := parseAvatars(tt.initials, tt.images)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("parseAvatars(%q, %q) error = %v, want %q", tt.initials, tt.images, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseTypeColors(t *testing.T) {
+	colors, err := parseTypeColors([]string{"system=gray", " user = #6366f1 ", "system=rgb(100, 100, 100)"})
+	if err != nil {
+		t.Fatalf("parseTypeColors() error = %v", err)
+	}
+	want := map[string]string{"system": "rgb(100, 100, 100)", "user": "#6366f1"}
+	if !reflect.DeepEqual(colors, want) {
+		t.Errorf("parseTypeColors() = %v, want %v", colors, want)
+	}
+
+	for value, wantErr := range map[string]string{
+		"system":             "want type=color",
+		"robot=red":          "type must be one of assistant, queue-operation, summary, system, user",
+		"system=notacolor":   `invalid color "notacolor" for system`,
+		"user=red;}body{x:y": "invalid color",
+	} {
+		if _, err := parseTypeColors([]string{value}); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("parseTypeColors(%q) error = %v, want %q", value, err, wantErr)
+		}
+	}
+}
+
+func TestRunExport_AvatarRequiresHTML(t *testing.T) {
+	oldAvatars, oldImages, oldFormat := exportAvatars, exportAvatarImages, exportFormat
+	defer func() { exportAvatars, exportAvatarImages, exportFormat = oldAvatars, oldImages, oldFormat }()
+
+	exportAvatars = []string{"user=RL"}
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--avatar is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+
+	exportAvatars = nil
+	exportAvatarImages = []string{"user=https://example.com/me.png"}
+	err = runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--avatar-image is only supported for html") {
+		t.Errorf("expected html-only error for --avatar-image, got %v", err)
+	}
+}
+
+func TestHTMLOnlyFlags(t *testing.T) {
+	oldSidebar, oldPageSize := exportSidebar, exportPageSize
+	defer func() { exportSidebar, exportPageSize = oldSidebar, oldPageSize }()
+	exportSidebar, exportPageSize = true, 0
+
+	seen := make(map[string]bool)
+	for _, flag := range htmlOnlyFlags() {
+		if seen[flag.name] {
+			t.Errorf("%s is listed twice", flag.name)
+		}
+		seen[flag.name] = true
+		if exportCmd.Flags().Lookup(strings.TrimPrefix(flag.name, "--")) == nil {
+			t.Errorf("%s is not an export flag", flag.name)
+		}
+		if want := flag.name == "--sidebar"; flag.set != want && (flag.name == "--sidebar" || flag.name == "--page-size") {
+			t.Errorf("%s set = %v, want %v", flag.name, flag.set, want)
+		}
+	}
+}
+
+func TestRunExport_SessionHTMLFlagsRequireHTML(t *testing.T) {
+	oldFormat := exportFormat
+	oldRelative, oldPaginate, oldTimeline, oldCombine := exportRelativeTimes, exportPaginate, exportTimeline, exportCombineTools
+	defer func() {
+		exportFormat = oldFormat
+		exportRelativeTimes, exportPaginate, exportTimeline, exportCombineTools = oldRelative, oldPaginate, oldTimeline, oldCombine
+	}()
+
+	exportFormat = "markdown"
+	for _, tt := range []struct {
+		name string
+		flag *bool
+	}{
+		{"--relative-times", &exportRelativeTimes},
+		{"--paginate", &exportPaginate},
+		{"--timeline", &exportTimeline},
+		{"--combine-tool-messages", &exportCombineTools},
+	} {
+		*tt.flag = true
+		err := runExport(exportCmd, []string{t.TempDir()})
+		if err == nil || !strings.Contains(err.Error(), tt.name+" is only supported for html") {
+			t.Errorf("%s: expected html-only error, got %v", tt.name, err)
+		}
+		*tt.flag = false
+	}
+}
+
+func TestRunExport_RenderingFlagsRequireHTML(t *testing.T) {
+	oldFormat := exportFormat
+	oldMaxOutput, oldSummaryLen, oldCollapseCode, oldHighlight := exportMaxOutput, exportSummaryLen, exportCollapseCode, exportHighlight
+	defer func() {
+		exportFormat = oldFormat
+		exportMaxOutput, exportSummaryLen, exportCollapseCode, exportHighlight = oldMaxOutput, oldSummaryLen, oldCollapseCode, oldHighlight
+	}()
+	reset := func() {
+		exportMaxOutput, exportSummaryLen, exportCollapseCode, exportHighlight = 0, export.DefaultSummaryMaxLen, export.DefaultCollapseCodeLines, ""
+	}
+
+	for _, format := range []string{"markdown", "text", "json"} {
+		exportFormat = format
+		for _, tt := range []struct {
+			name string
+			set  func()
+		}{
+			{"--max-output-bytes", func() { exportMaxOutput = 1024 }},
+			{"--summary-length", func() { exportSummaryLen = 0 }},
+			{"--collapse-code-lines", func() { exportCollapseCode = 5 }},
+			{"--highlight", func() { exportHighlight = "TODO" }},
+		} {
+			reset()
+			tt.set()
+			err := runExport(exportCmd, []string{t.TempDir()})
+			if err == nil || !strings.Contains(err.Error(), tt.name+" is only supported for html") {
+				t.Errorf("%s with %s: expected html-only error, got %v", tt.name, format, err)
+			}
+		}
+	}
+	reset()
+}
+
+func TestRunExport_EncodingValidation(t *testing.T) {
+	oldEncoding, oldFormat := exportEncoding, exportFormat
+	defer func() { exportEncoding, exportFormat = oldEncoding, oldFormat }()
+
+	exportEncoding = "latin1"
+	exportFormat = "markdown"
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--encoding is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+
+	for _, name := range []string{"no-such-encoding", "shift_jis"} {
+		exportEncoding = name
+		exportFormat = "html"
+		err = runExport(exportCmd, []string{t.TempDir()})
+		if err == nil || !strings.Contains(err.Error(), "invalid --encoding") {
+			t.Errorf("--encoding %s: expected invalid encoding error, got %v", name, err)
+		}
+	}
+}
+
+func TestRunExport_NegativeCollapseCodeLines(t *testing.T) {
+	oldLines, oldFormat := exportCollapseCode, exportFormat
+	defer func() { exportCollapseCode, exportFormat = oldLines, oldFormat }()
+
+	exportCollapseCode = -1
+	exportFormat = "html"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--collapse-code-lines") {
+		t.Errorf("expected --collapse-code-lines error, got %v", err)
+	}
+}
+
+func TestRunExport_CompactRequiresHTML(t *testing.T) {
+	oldCompact, oldFormat := exportCompact, exportFormat
+	defer func() { exportCompact, exportFormat = oldCompact, oldFormat }()
+
+	exportCompact = true
+	exportFormat = "markdown"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--compact is only supported for html") {
+		t.Errorf("expected html-only error, got %v", err)
+	}
+}
+
+func TestRunExport_IdleThresholdMustBePositive(t *testing.T) {
+	oldThreshold, oldFormat := exportIdleThreshold, exportFormat
+	defer func() { exportIdleThreshold, exportFormat = oldThreshold, oldFormat }()
+
+	exportIdleThreshold = 0
+	exportFormat = "html"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--idle-threshold must be positive") {
+		t.Errorf("expected threshold error, got %v", err)
+	}
+}
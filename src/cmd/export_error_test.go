@@ -16,12 +16,12 @@ func TestExport_InvalidSessionID(t *testing.T) {
 	// Create a valid session first
 	_ = createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -30,7 +30,7 @@ func TestExport_InvalidSessionID(t *testing.T) {
 	outputDir := filepath.Join(tmpDir, "export-invalid")
 
 	// Use non-existent session ID
-	exportSessionID = "nonexistent-session-id-12345"
+	exportSessionIDs = []string{"nonexistent-session-id-12345"}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -45,18 +45,21 @@ func TestExport_InvalidSessionID(t *testing.T) {
 	if !strings.Contains(err.Error(), "no sessions found") && !strings.Contains(err.Error(), "not found") {
 		t.Errorf("Error should mention session not found, got: %v", err)
 	}
+	if got := exitCode(err); got != exitSessionNotFound {
+		t.Errorf("exitCode() = %d, want %d (session not found)", got, exitSessionNotFound)
+	}
 }
 
 func TestExport_InvalidProjectPath(t *testing.T) {
 	// Test with non-existent project path
 	tmpDir := t.TempDir()
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -64,7 +67,7 @@ func TestExport_InvalidProjectPath(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-invalid-project")
 
-	exportSessionID = "some-session"
+	exportSessionIDs = []string{"some-session"}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -94,12 +97,12 @@ func TestExport_PermissionDenied(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "permission-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -114,7 +117,7 @@ func TestExport_PermissionDenied(t *testing.T) {
 
 	outputDir := filepath.Join(readOnlyDir, "export-no-perms")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -136,12 +139,12 @@ func TestExport_InvalidFormat(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "invalid-format-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -149,7 +152,7 @@ func TestExport_InvalidFormat(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-bad-format")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "invalid-format"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -171,12 +174,12 @@ func TestExport_MissingSessionFlag(t *testing.T) {
 
 	tmpDir, _, projectPath := setupTestProject(t, "missing-session-test")
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -184,7 +187,7 @@ func TestExport_MissingSessionFlag(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-no-session")
 
-	exportSessionID = "" // Empty session ID
+	exportSessionIDs = nil // Empty session ID
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -214,12 +217,12 @@ func TestExport_CorruptedJSONL(t *testing.T) {
 		t.Fatalf("failed to create session: %v", err)
 	}
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -227,7 +230,7 @@ func TestExport_CorruptedJSONL(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-corrupted")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -263,12 +266,12 @@ func TestExport_EmptySessionFile(t *testing.T) {
 		t.Fatalf("failed to create empty session: %v", err)
 	}
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -276,7 +279,7 @@ func TestExport_EmptySessionFile(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-empty-file")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -305,12 +308,12 @@ func TestExport_OutputDirCreationFailure(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "mkdir-fail-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -325,7 +328,7 @@ func TestExport_OutputDirCreationFailure(t *testing.T) {
 
 	outputDir := filepath.Join(readOnlyParent, "cannot-create-this")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -341,12 +344,12 @@ func TestExport_InvalidClaudeDir(t *testing.T) {
 	// Test with invalid Claude directory
 	tmpDir, _, projectPath := setupTestProject(t, "invalid-claude-test")
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -354,7 +357,7 @@ func TestExport_InvalidClaudeDir(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-invalid-claude")
 
-	exportSessionID = "some-session"
+	exportSessionIDs = []string{"some-session"}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = filepath.Join(tmpDir, "nonexistent-claude-dir")
@@ -371,12 +374,12 @@ func TestExport_NonAbsolutePathResolution(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "relative-resolve-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -396,7 +399,7 @@ func TestExport_NonAbsolutePathResolution(t *testing.T) {
 	// Use relative path for output
 	relativeOutput := "export-rel-resolve"
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = relativeOutput
 	claudeDir = tmpDir
@@ -426,12 +429,12 @@ func TestExport_SessionFileIsDirectory(t *testing.T) {
 		t.Fatalf("failed to create session directory: %v", err)
 	}
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -439,7 +442,7 @@ func TestExport_SessionFileIsDirectory(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-dir-as-file")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -491,19 +494,19 @@ func TestExport_ConcurrentExports(t *testing.T) {
 	// Run exports concurrently (in separate test invocations)
 	// For now, just verify both can be exported sequentially
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
 	}()
 
 	// Export first session
-	exportSessionID = session1
+	exportSessionIDs = []string{session1}
 	exportFormat = "html"
 	exportOutputDir = filepath.Join(tmpDir, "export-1")
 	claudeDir = tmpDir
@@ -513,7 +516,7 @@ func TestExport_ConcurrentExports(t *testing.T) {
 	}
 
 	// Export second session
-	exportSessionID = session2
+	exportSessionIDs = []string{session2}
 	exportOutputDir = filepath.Join(tmpDir, "export-2")
 
 	if err := runExport(exportCmd, []string{projectPath}); err != nil {
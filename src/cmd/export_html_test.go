@@ -56,7 +56,7 @@ func TestExportCommand_HTMLFormat(t *testing.T) {
 	}
 
 	// Now test HTML rendering
-	if err := renderHTML(result, projectPath, projectDir, sessionID); err != nil {
+	if err := renderHTML(export.HTMLExporter{}, result, projectPath, projectDir, sessionID); err != nil {
 		t.Fatalf("renderHTML failed: %v", err)
 	}
 
@@ -161,7 +161,7 @@ func TestExportCommand_HTMLWithAgents(t *testing.T) {
 	}
 
 	// Test HTML rendering
-	if err := renderHTML(result, projectPath, projectDir, sessionID); err != nil {
+	if err := renderHTML(export.HTMLExporter{}, result, projectPath, projectDir, sessionID); err != nil {
 		t.Fatalf("renderHTML failed: %v", err)
 	}
 
@@ -224,7 +224,7 @@ func TestRenderHTML(t *testing.T) {
 	}
 
 	// Test renderHTML directly
-	if err := renderHTML(result, projectPath, projectDir, sessionID); err != nil {
+	if err := renderHTML(export.HTMLExporter{}, result, projectPath, projectDir, sessionID); err != nil {
 		t.Errorf("renderHTML failed: %v", err)
 	}
 
@@ -274,7 +274,7 @@ func TestRenderAgentFragments(t *testing.T) {
 	}
 
 	// Test renderAgentFragments
-	if err := renderAgentFragments(result, nil); err != nil {
+	if err := renderAgentFragments(result, nil, export.ExportOptions{}); err != nil {
 		t.Errorf("renderAgentFragments failed: %v", err)
 	}
 
@@ -300,7 +300,7 @@ func TestRenderAgentFragments_MissingFile(t *testing.T) {
 	}
 
 	// Should return error for missing file
-	err := renderAgentFragments(result, nil)
+	err := renderAgentFragments(result, nil, export.ExportOptions{})
 	if err == nil {
 		t.Errorf("Expected error for missing agent file, got nil")
 	}
@@ -346,7 +346,7 @@ func TestHTMLOutput_ValidStructure(t *testing.T) {
 		t.Fatalf("ExportSession failed: %v", err)
 	}
 
-	if err := renderHTML(result, projectPath, projectDir, sessionID); err != nil {
+	if err := renderHTML(export.HTMLExporter{}, result, projectPath, projectDir, sessionID); err != nil {
 		t.Fatalf("renderHTML failed: %v", err)
 	}
 
@@ -366,7 +366,7 @@ func TestHTMLOutput_ValidStructure(t *testing.T) {
 		"<head>",
 		"<title>Claude Code Session [v", // Title includes version number
 		"<link rel=\"stylesheet\" href=\"static/style.css\">",
-		"<body>",
+		"<body data-session-id=\"", // Scopes saved collapse state to the session
 		"<div class=\"conversation\">",
 		"<div class=\"message-row user\"",
 		"<div class=\"message-row assistant\"",
@@ -431,3 +431,188 @@ func TestTruncateAgentID(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderHTML_LimitAgents(t *testing.T) {
+	tempDir := t.TempDir()
+	projectPath := filepath.Join(tempDir, "test-project")
+	claudeDir := filepath.Join(tempDir, ".claude")
+
+	encodedPath := encoding.EncodePath(projectPath)
+	projectDir := filepath.Join(claudeDir, "projects", encodedPath)
+	subagentsDir := filepath.Join(projectDir, "22222222-2222-2222-2222-222222222222", "subagents")
+	if err := os.MkdirAll(subagentsDir, 0755); err != nil {
+		t.Fatalf("Failed to create subagents directory: %v", err)
+	}
+
+	sessionID := "22222222-2222-2222-2222-222222222222"
+	sessionContent := `{"uuid":"entry-1","type":"user","timestamp":"2026-02-01T10:00:00Z","sessionId":"22222222-2222-2222-2222-222222222222","message":[{"type":"text","text":"Hello"}]}
+{"uuid":"entry-2","type":"queue-operation","timestamp":"2026-02-01T10:00:01Z","sessionId":"22222222-2222-2222-2222-222222222222","agentId":"big1234"}
+{"uuid":"entry-3","type":"queue-operation","timestamp":"2026-02-01T10:00:02Z","sessionId":"22222222-2222-2222-2222-222222222222","agentId":"small12"}
+`
+	if err := os.WriteFile(filepath.Join(projectDir, sessionID+".jsonl"), []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to write session file: %v", err)
+	}
+
+	bigAgent := `{"uuid":"b1","type":"user","timestamp":"2026-02-01T10:00:02Z","message":[{"type":"text","text":"Big task"}]}
+{"uuid":"b2","type":"assistant","timestamp":"2026-02-01T10:00:03Z","message":[{"type":"text","text":"Working"}]}
+{"uuid":"b3","type":"assistant","timestamp":"2026-02-01T10:00:04Z","message":[{"type":"text","text":"Done"}]}
+`
+	smallAgent := `{"uuid":"s1","type":"user","timestamp":"2026-02-01T10:00:02Z","message":[{"type":"text","text":"Small task"}]}
+`
+	if err := os.WriteFile(filepath.Join(subagentsDir, "agent-big1234.jsonl"), []byte(bigAgent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(subagentsDir, "agent-small12.jsonl"), []byte(smallAgent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := filepath.Join(tempDir, "export-output")
+	result, err := export.ExportSession(projectPath, sessionID, export.ExportOptions{OutputDir: outputDir, ClaudeDir: claudeDir})
+	if err != nil {
+		t.Fatalf("ExportSession failed: %v", err)
+	}
+
+	exporter := export.HTMLExporter{Options: export.ExportOptions{MaxAgents: 1}}
+	if err := renderHTML(exporter, result, projectPath, projectDir, sessionID); err != nil {
+		t.Fatalf("renderHTML failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "agents", "big1234.html")); err != nil {
+		t.Errorf("fragment for the rendered agent missing: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "agents", "small12.html")); !os.IsNotExist(err) {
+		t.Errorf("agent beyond --limit-agents should get no fragment, stat error = %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(index)
+	if !strings.Contains(html, `data-agent-id="small12"`) || !strings.Contains(html, "… and 1 more agent<") {
+		t.Error("index.html should list the hidden agent in the overflow section")
+	}
+	if !strings.Contains(html, "Subagents[2]") {
+		t.Error("index.html header should count both agents")
+	}
+}
+
+func TestRenderHTML_PageSize(t *testing.T) {
+	tempDir := t.TempDir()
+	projectPath := filepath.Join(tempDir, "test-project")
+	claudeDir := filepath.Join(tempDir, ".claude")
+
+	encodedPath := encoding.EncodePath(projectPath)
+	projectDir := filepath.Join(claudeDir, "projects", encodedPath)
+	sessionID := "33333333-3333-3333-3333-333333333333"
+	subagentsDir := filepath.Join(projectDir, sessionID, "subagents")
+	if err := os.MkdirAll(subagentsDir, 0755); err != nil {
+		t.Fatalf("Failed to create subagents directory: %v", err)
+	}
+
+	sessionContent := `{"uuid":"e1","type":"user","timestamp":"2026-02-01T10:00:00Z","message":[{"type":"text","text":"First"}]}
+{"uuid":"e2","type":"assistant","timestamp":"2026-02-01T10:00:01Z","message":[{"type":"text","text":"Second"}]}
+{"uuid":"e3","type":"user","timestamp":"2026-02-01T10:00:02Z","message":[{"type":"text","text":"Third"}]}
+{"uuid":"e4","type":"queue-operation","timestamp":"2026-02-01T10:00:03Z","agentId":"agent12"}
+`
+	if err := os.WriteFile(filepath.Join(projectDir, sessionID+".jsonl"), []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to write session file: %v", err)
+	}
+	agentContent := `{"uuid":"a1","type":"user","timestamp":"2026-02-01T10:00:03Z","message":[{"type":"text","text":"Sub task"}]}
+`
+	if err := os.WriteFile(filepath.Join(subagentsDir, "agent-agent12.jsonl"), []byte(agentContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := filepath.Join(tempDir, "export-output")
+	result, err := export.ExportSession(projectPath, sessionID, export.ExportOptions{OutputDir: outputDir, ClaudeDir: claudeDir})
+	if err != nil {
+		t.Fatalf("ExportSession failed: %v", err)
+	}
+
+	exporter := export.HTMLExporter{Options: export.ExportOptions{PageSize: 2}}
+	if err := renderHTML(exporter, result, projectPath, projectDir, sessionID); err != nil {
+		t.Fatalf("renderHTML failed: %v", err)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatalf("%s not written: %v", name, err)
+		}
+		return string(data)
+	}
+
+	index := read("index.html")
+	if !strings.Contains(index, `href="page-1.html"`) || !strings.Contains(index, `href="page-2.html"`) {
+		t.Error("index.html should link both pages")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "page-3.html")); !os.IsNotExist(err) {
+		t.Errorf("page-3.html should not exist, stat error = %v", err)
+	}
+	if page1 := read("page-1.html"); !strings.Contains(page1, "Second") || strings.Contains(page1, "Third") {
+		t.Error("page-1.html should hold the first two messages only")
+	}
+
+	// The subagent spawned on page 2 still lazy-loads its fragment from agents/
+	page2 := read("page-2.html")
+	if !strings.Contains(page2, `data-agent-id="agent12"`) {
+		t.Error("page-2.html should have the subagent section")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "agents", "agent12.html")); err != nil {
+		t.Errorf("agent fragment missing: %v", err)
+	}
+}
+
+func TestRenderHTML_NoJS(t *testing.T) {
+	tempDir := t.TempDir()
+	projectPath := filepath.Join(tempDir, "test-project")
+	claudeDir := filepath.Join(tempDir, ".claude")
+
+	encodedPath := encoding.EncodePath(projectPath)
+	projectDir := filepath.Join(claudeDir, "projects", encodedPath)
+	sessionID := "44444444-4444-4444-4444-444444444444"
+	subagentsDir := filepath.Join(projectDir, sessionID, "subagents")
+	if err := os.MkdirAll(subagentsDir, 0755); err != nil {
+		t.Fatalf("Failed to create subagents directory: %v", err)
+	}
+
+	sessionContent := `{"uuid":"e1","type":"user","timestamp":"2026-02-01T10:00:00Z","message":[{"type":"text","text":"Start"}]}
+{"uuid":"e2","type":"queue-operation","timestamp":"2026-02-01T10:00:01Z","agentId":"agent34"}
+`
+	if err := os.WriteFile(filepath.Join(projectDir, sessionID+".jsonl"), []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to write session file: %v", err)
+	}
+	agentContent := `{"uuid":"a1","type":"user","timestamp":"2026-02-01T10:00:01Z","message":[{"type":"text","text":"Inlined sub task"}]}
+`
+	if err := os.WriteFile(filepath.Join(subagentsDir, "agent-agent34.jsonl"), []byte(agentContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := filepath.Join(tempDir, "export-output")
+	result, err := export.ExportSession(projectPath, sessionID, export.ExportOptions{OutputDir: outputDir, ClaudeDir: claudeDir})
+	if err != nil {
+		t.Fatalf("ExportSession failed: %v", err)
+	}
+
+	exporter := export.HTMLExporter{Options: export.ExportOptions{NoJS: true}}
+	if err := renderHTML(exporter, result, projectPath, projectDir, sessionID); err != nil {
+		t.Fatalf("renderHTML failed: %v", err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(index)
+	if strings.Contains(html, "<script") {
+		t.Error("index.html should have no scripts")
+	}
+	if !strings.Contains(html, `<details class="subagent"`) || !strings.Contains(html, "Inlined sub task") {
+		t.Error("index.html should inline the subagent conversation in a <details> section")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "agents", "agent34.html")); !os.IsNotExist(err) {
+		t.Errorf("inlined agent should get no fragment, stat error = %v", err)
+	}
+}
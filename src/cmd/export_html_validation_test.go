@@ -15,12 +15,12 @@ func TestHTML_ValidStructure(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "html-structure-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -28,7 +28,7 @@ func TestHTML_ValidStructure(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-structure")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -119,12 +119,12 @@ func TestHTML_XSSPrevention(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "xss-test")
 	sessionID := createSessionWithXSS(t, projectDir)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -132,7 +132,7 @@ func TestHTML_XSSPrevention(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-xss")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -187,12 +187,12 @@ func TestHTML_SpecialCharacters(t *testing.T) {
 		t.Fatalf("failed to create session: %v", err)
 	}
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -200,7 +200,7 @@ func TestHTML_SpecialCharacters(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-chars")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -261,12 +261,12 @@ func TestHTML_LongToolInputs(t *testing.T) {
 		t.Fatalf("failed to create session: %v", err)
 	}
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -274,7 +274,7 @@ func TestHTML_LongToolInputs(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-long")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -334,12 +334,12 @@ func TestHTML_ExpandableSections(t *testing.T) {
 		t.Fatalf("failed to create agent file: %v", err)
 	}
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -347,7 +347,7 @@ func TestHTML_ExpandableSections(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-expandable")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -416,12 +416,12 @@ func TestHTML_MultipleEntryTypes(t *testing.T) {
 		t.Fatalf("failed to create session: %v", err)
 	}
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -429,7 +429,7 @@ func TestHTML_MultipleEntryTypes(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-types")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -489,12 +489,12 @@ func TestHTML_EmptyContentHandling(t *testing.T) {
 		t.Fatalf("failed to create session: %v", err)
 	}
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -502,7 +502,7 @@ func TestHTML_EmptyContentHandling(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-empty-content")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -525,12 +525,12 @@ func TestHTML_CSSAndJSPresent(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "assets-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -538,7 +538,7 @@ func TestHTML_CSSAndJSPresent(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-assets")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
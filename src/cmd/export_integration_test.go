@@ -17,12 +17,12 @@ func TestExport_FullWorkflow_HTML(t *testing.T) {
 	sessionID := createTestSessionWithAgents(t, projectDir, 3)
 
 	// Set up command flags
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -30,7 +30,7 @@ func TestExport_FullWorkflow_HTML(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-output")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -93,12 +93,12 @@ func TestExport_FullWorkflow_JSONL(t *testing.T) {
 	sessionID := createTestSessionWithAgents(t, projectDir, 2)
 
 	// Set up command flags
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -106,7 +106,7 @@ func TestExport_FullWorkflow_JSONL(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-jsonl")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "jsonl"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -150,18 +150,18 @@ func TestExport_AutoGeneratedOutputDir(t *testing.T) {
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
 	// Set up command flags - no output directory specified
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
 	}()
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = "" // Auto-generate
 	claudeDir = tmpDir
@@ -185,12 +185,12 @@ func TestExport_CustomOutputDir(t *testing.T) {
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
 	// Set up command flags with custom output
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -198,7 +198,7 @@ func TestExport_CustomOutputDir(t *testing.T) {
 
 	customOutput := filepath.Join(tmpDir, "my-custom-export")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = customOutput
 	claudeDir = tmpDir
@@ -231,12 +231,12 @@ func TestExport_NestedAgents(t *testing.T) {
 	createNestedAgentStructure(t, projectDir, sessionID)
 
 	// Set up command flags
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -244,7 +244,7 @@ func TestExport_NestedAgents(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-nested")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -282,12 +282,12 @@ func TestExport_EmptySession(t *testing.T) {
 	sessionID := createEmptySession(t, projectDir)
 
 	// Set up command flags
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -295,7 +295,7 @@ func TestExport_EmptySession(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-empty")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -327,12 +327,12 @@ func TestExport_NoAgents(t *testing.T) {
 	sessionID := createTestSessionWithAgents(t, projectDir, 0)
 
 	// Set up command flags
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -340,7 +340,7 @@ func TestExport_NoAgents(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-no-agents")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -387,12 +387,12 @@ func TestExport_MissingAgentFiles(t *testing.T) {
 	// Do NOT create the agent file - simulate missing agent
 
 	// Set up command flags
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -400,7 +400,7 @@ func TestExport_MissingAgentFiles(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-missing")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -427,12 +427,12 @@ func TestExport_RelativePath(t *testing.T) {
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
 	// Set up command flags
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -440,7 +440,7 @@ func TestExport_RelativePath(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-relative")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -483,12 +483,12 @@ func TestExport_CurrentDirectory(t *testing.T) {
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
 	// Set up command flags
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -496,7 +496,7 @@ func TestExport_CurrentDirectory(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-curdir")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -532,18 +532,18 @@ func TestExport_TimestampInPath(t *testing.T) {
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
 	// Set up command flags
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
 	}()
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = "" // Auto-generate with timestamp
 	claudeDir = tmpDir
@@ -14,12 +14,12 @@ func TestManifest_JSONValidity(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "manifest-valid-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 2)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -27,7 +27,7 @@ func TestManifest_JSONValidity(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-manifest")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -72,12 +72,12 @@ func TestManifest_AgentTree(t *testing.T) {
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 	createNestedAgentStructure(t, projectDir, sessionID)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -85,7 +85,7 @@ func TestManifest_AgentTree(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-tree")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -157,12 +157,12 @@ func TestManifest_SourcePaths(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "manifest-sources-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 2)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -170,7 +170,7 @@ func TestManifest_SourcePaths(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-sources")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -257,12 +257,12 @@ func TestManifest_Metadata(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "manifest-metadata-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -270,7 +270,7 @@ func TestManifest_Metadata(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-metadata")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -336,12 +336,12 @@ func TestManifest_EmptySession(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "manifest-empty-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 0)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -349,7 +349,7 @@ func TestManifest_EmptySession(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-empty-manifest")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -405,12 +405,12 @@ func TestManifest_VersionFormat(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "manifest-version-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -418,7 +418,7 @@ func TestManifest_VersionFormat(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-version")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -468,12 +468,12 @@ func TestManifest_JSONLFormat(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "manifest-jsonl-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 1)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -481,7 +481,7 @@ func TestManifest_JSONLFormat(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-jsonl-manifest")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "jsonl"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -504,12 +504,12 @@ func TestManifest_ReadRoundtrip(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "manifest-roundtrip-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 2)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -517,7 +517,7 @@ func TestManifest_ReadRoundtrip(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-roundtrip")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/paths"
+)
+
+func TestExport_MultipleSessions(t *testing.T) {
+	tmpDir, projectDir, projectPath := setupTestProject(t, "multi-session-test")
+	sessionID := createTestSessionWithAgents(t, projectDir, 1)
+	emptyID := createEmptySession(t, projectDir)
+
+	oldSessionIDs, oldFormat, oldOutputDir, oldClaudeDir := exportSessionIDs, exportFormat, exportOutputDir, claudeDir
+	defer func() {
+		exportSessionIDs, exportFormat, exportOutputDir, claudeDir = oldSessionIDs, oldFormat, oldOutputDir, oldClaudeDir
+	}()
+
+	outputDir := filepath.Join(tmpDir, "out")
+	exportSessionIDs = []string{sessionID[:8], "nonexistent-session", emptyID}
+	exportFormat = "html"
+	exportOutputDir = outputDir
+	claudeDir = tmpDir
+
+	// The missing session fails the run, but not the others
+	err := runExport(exportCmd, []string{projectPath})
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 sessions failed to export") {
+		t.Fatalf("runExport() error = %v, want 1 of 3 failed", err)
+	}
+
+	for _, id := range []string{sessionID, emptyID} {
+		for _, name := range []string{"index.html", "source/session.jsonl"} {
+			if !paths.Exists(filepath.Join(outputDir, id, name)) {
+				t.Errorf("session %s missing %s", id, name)
+			}
+		}
+	}
+
+	index, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatalf("combined index not written: %v", err)
+	}
+	for _, want := range []string{
+		`<p class="sessions-summary">2 exported, 1 failed</p>`,
+		`<a href="` + sessionID + `/index.html"><code>` + sessionID + `</code></a>`,
+		`<a href="` + emptyID + `/index.html">`,
+		`<code>nonexistent-session</code> <span class="session-error">failed: failed to resolve session ID`,
+	} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("index missing %q", want)
+		}
+	}
+}
+
+func TestExport_MultipleSessionsAllFail(t *testing.T) {
+	tmpDir, _, projectPath := setupTestProject(t, "multi-session-fail-test")
+
+	oldSessionIDs, oldOutputDir, oldClaudeDir := exportSessionIDs, exportOutputDir, claudeDir
+	defer func() {
+		exportSessionIDs, exportOutputDir, claudeDir = oldSessionIDs, oldOutputDir, oldClaudeDir
+	}()
+
+	outputDir := filepath.Join(tmpDir, "out")
+	exportSessionIDs = []string{"missing-a", "missing-b"}
+	exportOutputDir = outputDir
+	claudeDir = tmpDir
+
+	err := runExport(exportCmd, []string{projectPath})
+	if err == nil || !strings.Contains(err.Error(), "all 2 sessions failed to export") {
+		t.Fatalf("runExport() error = %v, want all failed", err)
+	}
+	if paths.Exists(filepath.Join(outputDir, "index.html")) {
+		t.Error("no index should be written when every session failed")
+	}
+}
+
+func TestExport_MultipleSessionsWithAgent(t *testing.T) {
+	oldSessionIDs, oldAgentID := exportSessionIDs, exportAgentID
+	defer func() { exportSessionIDs, exportAgentID = oldSessionIDs, oldAgentID }()
+
+	exportSessionIDs = []string{"a", "b"}
+	exportAgentID = "agent-1"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--agent cannot be combined with more than one --session") {
+		t.Errorf("runExport() error = %v, want --agent conflict", err)
+	}
+}
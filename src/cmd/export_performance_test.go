@@ -19,12 +19,12 @@ func TestExport_LargeSession(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "large-session-test")
 	sessionID := createLargeSession(t, projectDir, 1000)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -32,7 +32,7 @@ func TestExport_LargeSession(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-large")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -66,12 +66,12 @@ func TestExport_ManyAgents(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "many-agents-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 50)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -79,7 +79,7 @@ func TestExport_ManyAgents(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-many-agents")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -144,12 +144,12 @@ func TestExport_DeepNesting(t *testing.T) {
 		currentDir = filepath.Join(subagentsDir, fmt.Sprintf("agent-agent-%d", level))
 	}
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -157,7 +157,7 @@ func TestExport_DeepNesting(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-deep-nest")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -182,18 +182,18 @@ func BenchmarkExport_HTML(b *testing.B) {
 	tmpDir, projectDir, projectPath := setupBenchProject(b, "bench-html")
 	sessionID := createBenchSession(b, projectDir, 5)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
 	}()
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	claudeDir = tmpDir
 
@@ -214,18 +214,18 @@ func BenchmarkExport_JSONL(b *testing.B) {
 	tmpDir, projectDir, projectPath := setupBenchProject(b, "bench-jsonl")
 	sessionID := createBenchSession(b, projectDir, 5)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
 	}()
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "jsonl"
 	claudeDir = tmpDir
 
@@ -246,18 +246,18 @@ func BenchmarkExport_ManyAgents(b *testing.B) {
 	tmpDir, projectDir, projectPath := setupBenchProject(b, "bench-many-agents")
 	sessionID := createBenchSession(b, projectDir, 20)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
 	}()
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	claudeDir = tmpDir
 
@@ -282,12 +282,12 @@ func TestExport_MemoryUsage(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "memory-test")
 	sessionID := createLargeSession(t, projectDir, 5000)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -295,7 +295,7 @@ func TestExport_MemoryUsage(t *testing.T) {
 
 	outputDir := filepath.Join(tmpDir, "export-memory")
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -314,18 +314,18 @@ func TestExport_RepeatableOutput(t *testing.T) {
 	tmpDir, projectDir, projectPath := setupTestProject(t, "repeatable-test")
 	sessionID := createTestSessionWithAgents(t, projectDir, 2)
 
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
 	}()
 
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	claudeDir = tmpDir
 
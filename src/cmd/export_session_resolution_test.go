@@ -68,7 +68,7 @@ func TestExport_SessionResolution(t *testing.T) {
 		outputDir := filepath.Join(tempDir, "export-output-full")
 
 		// Mock the command arguments
-		exportSessionID = sessionID
+		exportSessionIDs = []string{sessionID}
 		exportOutputDir = outputDir
 		exportFormat = "jsonl"
 
@@ -98,7 +98,7 @@ func TestExport_SessionResolution(t *testing.T) {
 		sessionIDPrefix := sessionID[:8]
 
 		// Mock the command arguments
-		exportSessionID = sessionIDPrefix
+		exportSessionIDs = []string{sessionIDPrefix}
 		exportOutputDir = outputDir
 		exportFormat = "jsonl"
 
@@ -160,7 +160,7 @@ func TestExport_SessionResolution_WithEncodedPath(t *testing.T) {
 		// Use prefix of session ID
 		sessionIDPrefix := sessionID[:12]
 
-		exportSessionID = sessionIDPrefix
+		exportSessionIDs = []string{sessionIDPrefix}
 		exportOutputDir = outputDir
 		exportFormat = "jsonl"
 
@@ -230,17 +230,17 @@ func TestExportCmd_Usage(t *testing.T) {
 
 func TestExportCmd_InvalidFormat(t *testing.T) {
 	// Reset global variables
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 	}()
 
 	// Set up test values
-	exportSessionID = "test-session"
+	exportSessionIDs = []string{"test-session"}
 	exportFormat = "invalid"
 	exportOutputDir = ""
 
@@ -256,12 +256,12 @@ func TestExportCmd_InvalidFormat(t *testing.T) {
 
 func TestExportCmd_MissingProject(t *testing.T) {
 	// Reset global variables
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -275,7 +275,7 @@ func TestExportCmd_MissingProject(t *testing.T) {
 	}
 
 	// Set up test values
-	exportSessionID = "test-session"
+	exportSessionIDs = []string{"test-session"}
 	exportFormat = "html"
 	exportOutputDir = ""
 	claudeDir = tmpDir
@@ -292,12 +292,12 @@ func TestExportCmd_MissingProject(t *testing.T) {
 
 func TestExportCmd_MissingSession(t *testing.T) {
 	// Reset global variables
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -316,7 +316,7 @@ func TestExportCmd_MissingSession(t *testing.T) {
 	}
 
 	// Set up test values
-	exportSessionID = "nonexistent-session"
+	exportSessionIDs = []string{"nonexistent-session"}
 	exportFormat = "html"
 	exportOutputDir = ""
 	claudeDir = tmpDir
@@ -333,12 +333,12 @@ func TestExportCmd_MissingSession(t *testing.T) {
 
 func TestExportCmd_ValidSession(t *testing.T) {
 	// Reset global variables
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -370,7 +370,7 @@ func TestExportCmd_ValidSession(t *testing.T) {
 	outputDir := filepath.Join(tmpDir, "export-output")
 
 	// Set up test values
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -399,14 +399,116 @@ func TestExportCmd_ValidSession(t *testing.T) {
 	}
 }
 
+func TestExportCmd_ProjectDir(t *testing.T) {
+	oldSessionID, oldFormat, oldOutputDir, oldClaudeDir, oldProjDir := exportSessionIDs, exportFormat, exportOutputDir, claudeDir, exportProjDir
+	defer func() {
+		exportSessionIDs, exportFormat, exportOutputDir, claudeDir, exportProjDir = oldSessionID, oldFormat, oldOutputDir, oldClaudeDir, oldProjDir
+	}()
+
+	// The project directory is named unlike the encoding of the project path
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "projects", "-weird-dir")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sessionID := "679761ba-80c0-4cd3-a586-cc6a1fc56308"
+	sessionContent := `{"uuid":"1","sessionId":"679761ba-80c0-4cd3-a586-cc6a1fc56308","type":"user","timestamp":"2026-02-01T18:00:00.000Z","message":"Hello, world!"}
+`
+	if err := os.WriteFile(filepath.Join(projectDir, sessionID+".jsonl"), []byte(sessionContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := filepath.Join(tmpDir, "export-output")
+	exportSessionIDs = []string{"679761ba"}
+	exportFormat = "html"
+	exportOutputDir = outputDir
+	exportProjDir = "-weird-dir"
+	claudeDir = tmpDir
+
+	if err := runExport(exportCmd, []string{filepath.Join(tmpDir, "nonexistent", "path")}); err != nil {
+		t.Fatalf("runExport(--project-dir) error = %v", err)
+	}
+	for _, name := range []string{"index.html", filepath.Join("source", "session.jsonl")} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("export through --project-dir should write %s: %v", name, err)
+		}
+	}
+}
+
+func TestExportCmd_FormatCaseInsensitive(t *testing.T) {
+	oldSessionID, oldFormat, oldOutputDir, oldClaudeDir := exportSessionIDs, exportFormat, exportOutputDir, claudeDir
+	defer func() {
+		exportSessionIDs, exportFormat, exportOutputDir, claudeDir = oldSessionID, oldFormat, oldOutputDir, oldClaudeDir
+	}()
+
+	tmpDir, projectDir, projectPath := setupTestProject(t, "format-case")
+	sessionID := createTestSessionWithAgents(t, projectDir, 1)
+
+	outputDir := filepath.Join(tmpDir, "export-output")
+	exportSessionIDs = []string{sessionID}
+	exportFormat = "HTML"
+	exportOutputDir = outputDir
+	claudeDir = tmpDir
+
+	if err := runExport(exportCmd, []string{projectPath}); err != nil {
+		t.Fatalf("runExport(--format HTML) error = %v", err)
+	}
+	for _, name := range []string{"index.html", filepath.Join("static", "style.css"), filepath.Join("agents", "agent-1.html")} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("--format HTML should write the html export's %s: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "conversation.html")); err == nil {
+		t.Error("--format HTML should not be rendered as a single document")
+	}
+}
+
+func TestExportCmd_Zstd(t *testing.T) {
+	oldSessionID, oldFormat, oldOutputDir, oldClaudeDir := exportSessionIDs, exportFormat, exportOutputDir, claudeDir
+	defer func() {
+		exportSessionIDs, exportFormat, exportOutputDir, claudeDir = oldSessionID, oldFormat, oldOutputDir, oldClaudeDir
+	}()
+
+	tmpDir, projectDir, projectPath := setupTestProject(t, "zstd-export")
+	sessionID := createTestSessionWithAgents(t, projectDir, 1)
+	compressJSONLFiles(t, projectDir)
+
+	outputDir := filepath.Join(tmpDir, "export-output")
+	exportSessionIDs = []string{sessionID[:8]}
+	exportFormat = "html"
+	exportOutputDir = outputDir
+	claudeDir = tmpDir
+
+	if err := runExport(exportCmd, []string{projectPath}); err != nil {
+		t.Fatalf("runExport() of a compressed session error = %v", err)
+	}
+	for _, name := range []string{
+		"index.html",
+		filepath.Join("source", "session.jsonl.zst"),
+		filepath.Join("source", "agents", "agent-agent-1.jsonl.zst"),
+		filepath.Join("agents", "agent-1.html"),
+	} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("compressed session export should write %s: %v", name, err)
+		}
+	}
+	page, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), "Create a test application") {
+		t.Error("index.html should render the compressed session's messages")
+	}
+}
+
 func TestExportCmd_JSONLFormat(t *testing.T) {
 	// Reset global variables
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -437,7 +539,7 @@ func TestExportCmd_JSONLFormat(t *testing.T) {
 	outputDir := filepath.Join(tmpDir, "jsonl-output")
 
 	// Set up test values
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "jsonl"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -477,12 +579,12 @@ func TestExportCmd_JSONLFormat(t *testing.T) {
 
 func TestExportCmd_AutoGeneratedOutput(t *testing.T) {
 	// Reset global variables
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -510,7 +612,7 @@ func TestExportCmd_AutoGeneratedOutput(t *testing.T) {
 	}
 
 	// Set up test values - no output directory specified
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = "" // Auto-generate
 	claudeDir = tmpDir
@@ -527,12 +629,12 @@ func TestExportCmd_AutoGeneratedOutput(t *testing.T) {
 
 func TestExportCmd_RelativeOutputPath(t *testing.T) {
 	// Reset global variables
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -563,7 +665,7 @@ func TestExportCmd_RelativeOutputPath(t *testing.T) {
 	relativeOutput := filepath.Join(tmpDir, "relative-export")
 
 	// Set up test values
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "html"
 	exportOutputDir = relativeOutput
 	claudeDir = tmpDir
@@ -582,12 +684,12 @@ func TestExportCmd_RelativeOutputPath(t *testing.T) {
 
 func TestExportCmd_WithAgents(t *testing.T) {
 	// Reset global variables
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -633,7 +735,7 @@ func TestExportCmd_WithAgents(t *testing.T) {
 	outputDir := filepath.Join(tmpDir, "export-with-agents")
 
 	// Set up test values
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "jsonl"
 	exportOutputDir = outputDir
 	claudeDir = tmpDir
@@ -662,12 +764,12 @@ func TestExportCmd_WithAgents(t *testing.T) {
 
 func TestExportCmd_AutoOutputDir(t *testing.T) {
 	// Reset global variables
-	oldSessionID := exportSessionID
+	oldSessionID := exportSessionIDs
 	oldFormat := exportFormat
 	oldOutputDir := exportOutputDir
 	oldClaudeDir := claudeDir
 	defer func() {
-		exportSessionID = oldSessionID
+		exportSessionIDs = oldSessionID
 		exportFormat = oldFormat
 		exportOutputDir = oldOutputDir
 		claudeDir = oldClaudeDir
@@ -695,7 +797,7 @@ func TestExportCmd_AutoOutputDir(t *testing.T) {
 	}
 
 	// Set up test values - no output directory specified
-	exportSessionID = sessionID
+	exportSessionIDs = []string{sessionID}
 	exportFormat = "jsonl"
 	exportOutputDir = "" // Auto-generate
 	claudeDir = tmpDir
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/klauspost/compress/zstd"
 	"golang.org/x/net/html"
 
 	"github.com/randlee/claude-history/pkg/encoding"
@@ -85,6 +86,33 @@ func createTestSessionWithAgents(t *testing.T, projectDir string, agentCount int
 	return sessionID
 }
 
+// compressJSONLFiles replaces every .jsonl file under dir with its zstd-compressed
+// .jsonl.zst copy, as compressed session archives are stored.
+func compressJSONLFiles(t *testing.T, dir string) {
+	t.Helper()
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = encoder.Close() }()
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(path, ".jsonl") {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path+".zst", encoder.EncodeAll(content, nil), 0644); err != nil {
+			return err
+		}
+		return os.Remove(path)
+	})
+	if err != nil {
+		t.Fatalf("failed to compress session files: %v", err)
+	}
+}
+
 // createNestedAgentStructure creates a more complex nested agent structure.
 // Creates a hierarchy: main session -> agent-parent -> agent-child-1 and agent-child-2.
 //
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/randlee/claude-history/pkg/config"
+)
+
+// filterProfileFlag is the flag, on query and export, that names a filter profile.
+const filterProfileFlag = "filter"
+
+// filterProfileOptions are the query flags a filter profile can set: the ones that
+// select entries, as opposed to choosing a session or formatting output.
+var filterProfileOptions = map[string]bool{
+	"start": true, "end": true, "type": true, "tool": true, "tool-match": true,
+	"tool-field": true, "text": true, "errors": true, "spawns-only": true, "cwd": true,
+	"branch": true, "user-turn": true, "assistant-turn": true, "within": true,
+	"since-start": true,
+}
+
+// validateFilterProfiles reports profile options in cfg that are not filter flags.
+func validateFilterProfiles(cfg *config.Config) error {
+	names := make([]string, 0, len(cfg.Filters))
+	for name := range cfg.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for option := range cfg.Filters[name] {
+			if !filterProfileOptions[option] {
+				return fmt.Errorf("config %s: unknown option %q in filter profile %q", cfg.Path, option, name)
+			}
+		}
+	}
+	return nil
+}
+
+// applyFilterProfile sets the query filter flags from the profile named by the --filter
+// flag of cmd, if any. Like the config sections, it only sets flags the command line did
+// not, so a flag overrides one field of the profile; it runs after applyConfig, so the
+// profile wins over the query section. The flags are the query command's: export reads
+// its filter from them too (see buildFilterOptions).
+func applyFilterProfile(cfg *config.Config, cmd *cobra.Command) error {
+	f := cmd.Flags().Lookup(filterProfileFlag)
+	if f == nil || f.Value.String() == "" {
+		return nil
+	}
+	name := f.Value.String()
+	profile, err := cfg.FilterProfile(name)
+	if err != nil {
+		return err
+	}
+
+	for option, value := range profile {
+		flag := queryCmd.Flags().Lookup(option)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("config %s: invalid value %q for %s.%s.%s: %w", cfg.Path, value, config.FiltersKey, name, option, err)
+		}
+	}
+	return nil
+}
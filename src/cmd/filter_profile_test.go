@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/randlee/claude-history/pkg/config"
+)
+
+// saveFilterFlags restores the query filter flags, values and Changed state, when the
+// test ends.
+func saveFilterFlags(t *testing.T) {
+	t.Helper()
+	type saved struct {
+		value   string
+		changed bool
+	}
+	state := map[string]saved{}
+	for name := range filterProfileOptions {
+		f := queryCmd.Flags().Lookup(name)
+		state[name] = saved{f.Value.String(), f.Changed}
+	}
+	oldTools, oldFields := queryTools, queryToolFields
+	t.Cleanup(func() {
+		for name, s := range state {
+			f := queryCmd.Flags().Lookup(name)
+			_ = f.Value.Set(s.value)
+			f.Changed = s.changed
+		}
+		queryTools, queryToolFields = oldTools, oldFields
+	})
+}
+
+func filterProfileConfig() *config.Config {
+	cfg := testConfig(nil, nil)
+	cfg.Filters = map[string]map[string]string{
+		"bash-errors": {"tool": "Bash", "errors": "true"},
+		"go-edits":    {"tool": "Edit"},
+	}
+	return cfg
+}
+
+func TestFilterProfileOptions_AreQueryFlags(t *testing.T) {
+	for name := range filterProfileOptions {
+		if queryCmd.Flags().Lookup(name) == nil {
+			t.Errorf("filter profile option %q is not a query flag", name)
+		}
+	}
+}
+
+func TestApplyFilterProfile(t *testing.T) {
+	saveFilterFlags(t)
+	oldProfile := queryFilterProfile
+	defer func() { queryFilterProfile = oldProfile }()
+
+	// --tool on the command line overrides the profile's tool; errors still applies
+	if err := queryCmd.Flags().Set("tool", "Read"); err != nil {
+		t.Fatal(err)
+	}
+	queryFilterProfile = "bash-errors"
+	if err := applyFilterProfile(filterProfileConfig(), queryCmd); err != nil {
+		t.Fatalf("applyFilterProfile() error = %v", err)
+	}
+
+	opts, err := buildFilterOptions("")
+	if err != nil {
+		t.Fatalf("buildFilterOptions() error = %v", err)
+	}
+	if len(opts.ToolTypes) != 1 || opts.ToolTypes[0] != "Read" {
+		t.Errorf("ToolTypes = %v, want the command-line [Read]", opts.ToolTypes)
+	}
+	if !opts.ToolErrorsOnly {
+		t.Error("ToolErrorsOnly should come from the profile")
+	}
+}
+
+func TestApplyFilterProfile_UnknownProfile(t *testing.T) {
+	saveFilterFlags(t)
+	oldProfile := queryFilterProfile
+	defer func() { queryFilterProfile = oldProfile }()
+
+	queryFilterProfile = "bash"
+	err := applyFilterProfile(filterProfileConfig(), queryCmd)
+	if err == nil || !strings.Contains(err.Error(), `unknown filter profile "bash"`) || !strings.Contains(err.Error(), "bash-errors, go-edits") {
+		t.Errorf("applyFilterProfile() error = %v, want the defined profiles listed", err)
+	}
+}
+
+func TestApplyFilterProfile_NoFilterFlag(t *testing.T) {
+	cmd := &cobra.Command{Use: "list"}
+	if err := applyFilterProfile(filterProfileConfig(), cmd); err != nil {
+		t.Errorf("applyFilterProfile() error = %v, want nil for a command without --filter", err)
+	}
+}
+
+func TestValidateConfig_FilterProfileOptions(t *testing.T) {
+	sub := newConfigTestTree(t)
+	cfg := filterProfileConfig()
+	cfg.Filters["bad"] = map[string]string{"format": "json"}
+
+	err := applyConfig(cfg, sub)
+	if err == nil || !strings.Contains(err.Error(), `unknown option "format" in filter profile "bad"`) {
+		t.Errorf("applyConfig() error = %v, want unknown filter option", err)
+	}
+}
+
+func TestRunExport_FilterRejectsJSONL(t *testing.T) {
+	oldProfile, oldFormat := exportFilterProfile, exportFormat
+	defer func() { exportFilterProfile, exportFormat = oldProfile, oldFormat }()
+
+	exportFilterProfile = "bash-errors"
+	exportFormat = "jsonl"
+
+	err := runExport(exportCmd, []string{t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "--filter is not supported for jsonl") {
+		t.Errorf("expected jsonl error, got %v", err)
+	}
+}
+
+func TestLoadExportData_Filter(t *testing.T) {
+	saveFilterFlags(t)
+	oldFilter := exportFilter
+	defer func() { exportFilter = oldFilter }()
+
+	result, projectPath, projectDir, sessionID := setupDocumentExport(t)
+
+	queryTools = "Bash"
+	opts, err := buildFilterOptions("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exportFilter = &opts
+
+	data, err := loadExportData(result, projectPath, projectDir, sessionID)
+	if err != nil {
+		t.Fatalf("loadExportData() error = %v", err)
+	}
+	if len(data.entries) != 1 || data.entries[0].UUID != "entry-2" {
+		t.Errorf("filtered entries = %d, want only the Bash call", len(data.entries))
+	}
+	if data.stats.UserMessages != 0 || data.stats.AssistantMessages != 1 {
+		t.Errorf("stats should describe the exported entries, got %d user / %d assistant", data.stats.UserMessages, data.stats.AssistantMessages)
+	}
+}
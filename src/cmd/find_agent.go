@@ -22,6 +22,8 @@ var (
 	findStart     string
 	findEnd       string
 	findSessionID string
+
+	findFailOnEmpty bool
 )
 
 var findAgentCmd = &cobra.Command{
@@ -50,7 +52,10 @@ Examples:
   claude-history find-agent /path --session abc123 --explored "*.go"
 
   # JSON output for scripting
-  claude-history find-agent /path --explored "*.go" --format json`,
+  claude-history find-agent /path --explored "*.go" --format json
+
+  # Exit with status 2 (instead of 0) when no agent matches
+  claude-history find-agent /path --explored "*.go" --fail-on-empty`,
 	Args: cobra.ExactArgs(1),
 	RunE: runFindAgent,
 }
@@ -64,6 +69,7 @@ func init() {
 	findAgentCmd.Flags().StringVar(&findStart, "start", "", "Start time filter (RFC3339 or YYYY-MM-DD)")
 	findAgentCmd.Flags().StringVar(&findEnd, "end", "", "End time filter (RFC3339 or YYYY-MM-DD)")
 	findAgentCmd.Flags().StringVar(&findSessionID, "session", "", "Scope to single session ID")
+	findAgentCmd.Flags().BoolVar(&findFailOnEmpty, "fail-on-empty", false, "Exit with status 2 when no agents match")
 }
 
 func runFindAgent(cmd *cobra.Command, args []string) error {
@@ -101,8 +107,7 @@ func runFindAgent(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(matches) == 0 {
-		fmt.Fprintln(os.Stderr, "No agents found matching criteria")
-		return nil
+		return noMatches("No agents found matching criteria", findFailOnEmpty)
 	}
 
 	// Output results based on format
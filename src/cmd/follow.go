@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/randlee/claude-history/internal/output"
+	"github.com/randlee/claude-history/pkg/models"
+	"github.com/randlee/claude-history/pkg/paths"
+	"github.com/randlee/claude-history/pkg/resolver"
+	"github.com/randlee/claude-history/pkg/session"
+)
+
+var (
+	followSessionID    string
+	followPollInterval time.Duration
+)
+
+var followCmd = &cobra.Command{
+	Use:   "follow <project-path>",
+	Short: "Print new entries of a session as they are written",
+	Long: `Follow a session while it is running, printing each entry appended to its
+JSONL file on one line:
+
+  [15:04:05] assistant: Let me check the tests.
+  [15:04:06] assistant: [Bash] go test ./...
+  [15:04:09] user: (tool result)
+
+Following starts at the end of the file, so only new entries are shown. An
+entry is printed once its line has been completely written. If the file is
+truncated or replaced, following continues from the start of the new content.
+Press Ctrl-C to stop.
+
+Examples:
+  # Follow the most recent session of a project
+  claude-history follow /path/to/project
+
+  # Follow a specific session (prefixes are accepted)
+  claude-history follow /path/to/project --session abc123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFollow,
+}
+
+func init() {
+	rootCmd.AddCommand(followCmd)
+
+	followCmd.Flags().StringVar(&followSessionID, "session", "", "Session ID (default: most recent session)")
+	followCmd.Flags().DurationVar(&followPollInterval, "poll-interval", session.DefaultFollowPollInterval, "How often to check the session file for new entries")
+}
+
+func runFollow(cmd *cobra.Command, args []string) error {
+	if followPollInterval <= 0 {
+		return fmt.Errorf("--poll-interval must be positive")
+	}
+
+	projectPath := args[0]
+	projectDir, err := paths.ProjectDir(claudeDir, projectPath)
+	if err != nil {
+		return err
+	}
+	if !paths.Exists(projectDir) {
+		return fmt.Errorf("project not found: %s", projectPath)
+	}
+
+	sessionID := followSessionID
+	if sessionID == "" {
+		sessions, err := session.ListSessions(projectDir)
+		if err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			return fmt.Errorf("no sessions found in project")
+		}
+		sessionID = sessions[0].ID
+	} else {
+		resolvedSessionID, err := resolver.ResolveSessionID(projectDir, sessionID)
+		if err != nil {
+			return fmt.Errorf("failed to resolve session ID: %w", err)
+		}
+		sessionID = resolvedSessionID
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Following session %s (Ctrl-C to stop)\n", sessionID)
+
+	w := cmd.OutOrStdout()
+	colors := colorizer(w)
+	opts := session.FollowOptions{PollInterval: followPollInterval}
+	err = session.FollowSessionWith(ctx, paths.JSONLFile(filepath.Join(projectDir, sessionID)), opts, func(entry models.ConversationEntry) {
+		output.WriteEntryLineWith(w, entry, colors)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to follow session: %w", err)
+	}
+	return nil
+}
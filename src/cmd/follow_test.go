@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe to read while runFollow writes to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// saveFollowFlags restores the follow command flags and streams when the test ends.
+func saveFollowFlags(t *testing.T) {
+	t.Helper()
+	oldClaudeDir, oldSession, oldInterval := claudeDir, followSessionID, followPollInterval
+	t.Cleanup(func() {
+		claudeDir, followSessionID, followPollInterval = oldClaudeDir, oldSession, oldInterval
+		followCmd.SetOut(nil)
+		followCmd.SetErr(nil)
+		followCmd.SetContext(context.Background())
+	})
+}
+
+func TestRunFollow_PrintsAppendedEntries(t *testing.T) {
+	saveFollowFlags(t)
+	claudeDir = createCodeTestProject(t)
+	followSessionID = "c0de"
+	followPollInterval = 5 * time.Millisecond
+	sessionFile := filepath.Join(claudeDir, "projects", "-test-project", "c0de0000-0000-0000-0000-000000000001.jsonl")
+
+	out := &syncBuffer{}
+	followCmd.SetOut(out)
+	followCmd.SetErr(&bytes.Buffer{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	followCmd.SetContext(ctx)
+
+	done := make(chan error, 1)
+	go func() { done <- runFollow(followCmd, []string{"/test/project"}) }()
+	time.Sleep(50 * time.Millisecond)
+
+	f, err := os.OpenFile(sessionFile, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"uuid":"u2","type":"user","timestamp":"2026-02-01T10:05:00.000Z","message":"Now run\nthe tests"}` + "\n")
+	_, _ = f.WriteString(`{"uuid":"a3","type":"assistant","timestamp":"2026-02-01T10:05:02.000Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}}]}}` + "\n")
+	_ = f.Close()
+
+	want := "[10:05:00] user: Now run the tests\n[10:05:02] assistant: [Bash] go test ./...\n"
+	deadline := time.Now().Add(2 * time.Second)
+	for out.String() != want && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("runFollow() error = %v", err)
+	}
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q (existing entries must not be printed)", got, want)
+	}
+}
+
+func TestRunFollow_InvalidPollInterval(t *testing.T) {
+	saveFollowFlags(t)
+	followPollInterval = 0
+
+	err := runFollow(followCmd, []string{"/test/project"})
+	if err == nil || !strings.Contains(err.Error(), "--poll-interval must be positive") {
+		t.Errorf("runFollow() error = %v, want poll interval error", err)
+	}
+}
+
+func TestRunFollow_UnknownSession(t *testing.T) {
+	saveFollowFlags(t)
+	claudeDir = createCodeTestProject(t)
+	followSessionID = "ffff"
+
+	err := runFollow(followCmd, []string{"/test/project"})
+	if err == nil || !strings.Contains(err.Error(), "failed to resolve session ID") {
+		t.Errorf("runFollow() error = %v, want resolve error", err)
+	}
+}
@@ -123,10 +123,13 @@ func listSessions(projectDir string, format output.Format) error {
 		return fmt.Errorf("project directory not found: %s", projectDir)
 	}
 
-	sessions, err := session.ListSessions(projectDir)
+	sessions, readErrs, err := session.ListSessionsWithErrors(projectDir)
 	if err != nil {
 		return err
 	}
+	for _, readErr := range readErrs {
+		fmt.Fprintf(os.Stderr, "Warning: skipping unreadable %v\n", readErr)
+	}
 
 	if len(sessions) == 0 {
 		fmt.Fprintln(os.Stderr, "No sessions found")
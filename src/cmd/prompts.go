@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/randlee/claude-history/internal/output"
+	"github.com/randlee/claude-history/pkg/paths"
+	"github.com/randlee/claude-history/pkg/session"
+)
+
+// promptDisplayLen is the number of characters of a prompt shown per line in the
+// terminal view. JSON output always carries the full prompt.
+const promptDisplayLen = 100
+
+var (
+	promptsJSON        bool
+	promptsGrep        string
+	promptsFailOnEmpty bool
+)
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts <project-path>",
+	Short: "List the first prompt of every session",
+	Long: `List each session in a project with the first prompt the user typed, most
+recently modified first. Sessions without a user prompt are left out.
+
+The terminal view prints one line per session, with the prompt on a single line
+and shortened to 100 characters. Use --json for the full prompts along
+with each session's created and modified times.
+
+Examples:
+  # Catalog every session in a project
+  claude-history prompts /path/to/project
+
+  # Full prompts as JSON
+  claude-history prompts /path/to/project --json
+
+  # Sessions whose first prompt mentions a migration (case-insensitive)
+  claude-history prompts /path/to/project --grep '(?i)migrat'`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPrompts,
+}
+
+func init() {
+	rootCmd.AddCommand(promptsCmd)
+
+	promptsCmd.Flags().BoolVar(&promptsJSON, "json", false, "Output full prompts as JSON")
+	promptsCmd.Flags().StringVar(&promptsGrep, "grep", "", "Only list sessions whose first prompt matches this regex")
+	promptsCmd.Flags().BoolVar(&promptsFailOnEmpty, "fail-on-empty", false, "Exit with status 2 when no sessions match")
+}
+
+// sessionPrompt is one session's first prompt, as listed by the prompts command.
+type sessionPrompt struct {
+	SessionID string    `json:"sessionId"`
+	Created   time.Time `json:"created"`
+	Modified  time.Time `json:"modified"`
+	Prompt    string    `json:"firstPrompt"`
+}
+
+func runPrompts(cmd *cobra.Command, args []string) error {
+	var re *regexp.Regexp
+	if promptsGrep != "" {
+		var err error
+		re, err = regexp.Compile(promptsGrep)
+		if err != nil {
+			return fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+	}
+
+	projectPath := args[0]
+	projectDir, err := paths.ProjectDir(claudeDir, projectPath)
+	if err != nil {
+		return err
+	}
+	if !paths.Exists(projectDir) {
+		return fmt.Errorf("project not found: %s", projectPath)
+	}
+
+	prompts, err := collectPrompts(projectDir, re)
+	if err != nil {
+		return err
+	}
+	if len(prompts) == 0 {
+		msg := "No session prompts found"
+		if re != nil {
+			msg = fmt.Sprintf("No session prompts match %q", promptsGrep)
+		}
+		return noMatches(msg, promptsFailOnEmpty)
+	}
+
+	if promptsJSON || output.ParseFormat(format) == output.FormatJSON {
+		return output.WriteJSON(cmd.OutOrStdout(), prompts)
+	}
+	return writePrompts(cmd.OutOrStdout(), prompts)
+}
+
+// collectPrompts reads the full first prompt of every session in projectDir, keeping
+// those that match re (all of them when re is nil), most recently modified first.
+// Sessions that cannot be read or have no user prompt are skipped.
+func collectPrompts(projectDir string, re *regexp.Regexp) ([]sessionPrompt, error) {
+	sessionFiles, err := paths.ListSessionFiles(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var prompts []sessionPrompt
+	for sessionID, filePath := range sessionFiles {
+		info, err := session.GetSessionInfoWith(filePath, 0)
+		if err != nil || strings.TrimSpace(info.FirstPrompt) == "" {
+			continue
+		}
+		if re != nil && !re.MatchString(info.FirstPrompt) {
+			continue
+		}
+		prompts = append(prompts, sessionPrompt{
+			SessionID: sessionID,
+			Created:   info.Created,
+			Modified:  info.Modified,
+			Prompt:    info.FirstPrompt,
+		})
+	}
+
+	sort.Slice(prompts, func(i, j int) bool {
+		if !prompts[i].Modified.Equal(prompts[j].Modified) {
+			return prompts[i].Modified.After(prompts[j].Modified)
+		}
+		return prompts[i].SessionID < prompts[j].SessionID
+	})
+	return prompts, nil
+}
+
+// writePrompts writes one line per session: its ID and its first prompt, shortened
+// to a single line (see displayPrompt).
+func writePrompts(w io.Writer, prompts []sessionPrompt) error {
+	for _, p := range prompts {
+		if _, err := fmt.Fprintf(w, "%s  %s\n", p.SessionID, displayPrompt(p.Prompt, promptDisplayLen)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// displayPrompt collapses the whitespace of prompt (including newlines) to single
+// spaces and shortens it to at most maxChars characters, ending in "..." when cut.
+func displayPrompt(prompt string, maxChars int) string {
+	prompt = strings.Join(strings.Fields(prompt), " ")
+	if utf8.RuneCountInString(prompt) <= maxChars {
+		return prompt
+	}
+	runes := []rune(prompt)
+	if maxChars <= 3 {
+		return string(runes[:maxChars])
+	}
+	return string(runes[:maxChars-3]) + "..."
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// createPromptsTestProject writes a project with three sessions: one with a string
+// prompt, one with a long block-form prompt, and one with no user prompt. It returns
+// the claude dir.
+func createPromptsTestProject(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "projects", "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	long := strings.Repeat("refactor the parser ", 20)
+	sessions := map[string]string{
+		"aaaa0000-0000-0000-0000-000000000001": `{"uuid":"u1","type":"user","timestamp":"2026-02-01T10:00:00.000Z","message":{"role":"user","content":"Add a migration for users"}}
+{"uuid":"a1","type":"assistant","timestamp":"2026-02-01T10:00:05.000Z","message":{"role":"assistant","content":[{"type":"text","text":"Done"}]}}
+`,
+		"bbbb0000-0000-0000-0000-000000000002": `{"uuid":"u1","type":"user","timestamp":"2026-02-02T10:00:00.000Z","message":{"role":"user","content":[{"type":"text","text":"` + long + `\nsecond line"}]}}
+`,
+		"cccc0000-0000-0000-0000-000000000003": `{"uuid":"a1","type":"assistant","timestamp":"2026-02-03T10:00:00.000Z","message":{"role":"assistant","content":[{"type":"text","text":"Hi"}]}}
+`,
+	}
+	for id, content := range sessions {
+		if err := os.WriteFile(filepath.Join(projectDir, id+".jsonl"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tmpDir
+}
+
+// savePromptsFlags restores the prompts command flags when the test ends.
+func savePromptsFlags(t *testing.T) {
+	t.Helper()
+	oldClaudeDir, oldFormat, oldJSON, oldGrep, oldFail := claudeDir, format, promptsJSON, promptsGrep, promptsFailOnEmpty
+	t.Cleanup(func() {
+		claudeDir, format, promptsJSON, promptsGrep, promptsFailOnEmpty = oldClaudeDir, oldFormat, oldJSON, oldGrep, oldFail
+		promptsCmd.SetOut(nil)
+	})
+}
+
+// runPromptsOutput runs the prompts command on the test project and returns its output.
+func runPromptsOutput(t *testing.T) string {
+	t.Helper()
+	var buf bytes.Buffer
+	promptsCmd.SetOut(&buf)
+	if err := runPrompts(promptsCmd, []string{"/test/project"}); err != nil {
+		t.Fatalf("runPrompts() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestRunPrompts_Terminal(t *testing.T) {
+	savePromptsFlags(t)
+	claudeDir = createPromptsTestProject(t)
+	format, promptsJSON, promptsGrep = "", false, ""
+
+	lines := strings.Split(strings.TrimSuffix(runPromptsOutput(t), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2 (session without a prompt left out):\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+
+	// Most recently modified first
+	if !strings.HasPrefix(lines[0], "bbbb0000-0000-0000-0000-000000000002  refactor the parser") {
+		t.Errorf("line 1 = %q", lines[0])
+	}
+	if lines[1] != "aaaa0000-0000-0000-0000-000000000001  Add a migration for users" {
+		t.Errorf("line 2 = %q", lines[1])
+	}
+
+	prompt := strings.TrimPrefix(lines[0], "bbbb0000-0000-0000-0000-000000000002  ")
+	if len(prompt) != promptDisplayLen || !strings.HasSuffix(prompt, "...") {
+		t.Errorf("long prompt should be cut to %d characters, got %d: %q", promptDisplayLen, len(prompt), prompt)
+	}
+}
+
+func TestRunPrompts_JSONKeepsFullPrompt(t *testing.T) {
+	savePromptsFlags(t)
+	claudeDir = createPromptsTestProject(t)
+	format, promptsJSON, promptsGrep = "", true, ""
+
+	var got []sessionPrompt
+	if err := json.Unmarshal([]byte(runPromptsOutput(t)), &got); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(got))
+	}
+	want := strings.Repeat("refactor the parser ", 20) + "\nsecond line"
+	if got[0].Prompt != want {
+		t.Errorf("JSON prompt = %q, want the full prompt", got[0].Prompt)
+	}
+	if got[0].Created.IsZero() || got[0].Modified.IsZero() {
+		t.Errorf("JSON entry missing times: %+v", got[0])
+	}
+}
+
+func TestRunPrompts_FormatJSON(t *testing.T) {
+	savePromptsFlags(t)
+	claudeDir = createPromptsTestProject(t)
+	format, promptsJSON, promptsGrep = "json", false, ""
+
+	if out := runPromptsOutput(t); !strings.HasPrefix(out, "[") {
+		t.Errorf("--format json should write JSON, got %q", out)
+	}
+}
+
+func TestRunPrompts_Grep(t *testing.T) {
+	savePromptsFlags(t)
+	claudeDir = createPromptsTestProject(t)
+	format, promptsJSON, promptsGrep = "", false, "(?i)MIGRATION"
+
+	out := runPromptsOutput(t)
+	if strings.Count(out, "\n") != 1 || !strings.Contains(out, "aaaa0000") {
+		t.Errorf("--grep should keep only the matching session, got:\n%s", out)
+	}
+
+	// Matches the full prompt, not just the displayed part
+	promptsGrep = "second line"
+	if out := runPromptsOutput(t); !strings.Contains(out, "bbbb0000") {
+		t.Errorf("--grep should search the full prompt, got:\n%s", out)
+	}
+}
+
+func TestRunPrompts_GrepNoMatches(t *testing.T) {
+	savePromptsFlags(t)
+	claudeDir = createPromptsTestProject(t)
+	format, promptsJSON, promptsGrep, promptsFailOnEmpty = "", false, "nothing like this", true
+
+	err := runPrompts(promptsCmd, []string{"/test/project"})
+	if exitCode(err) != exitNoMatches {
+		t.Errorf("runPrompts() error = %v, want a no-matches error", err)
+	}
+}
+
+func TestRunPrompts_InvalidGrep(t *testing.T) {
+	savePromptsFlags(t)
+	claudeDir = createPromptsTestProject(t)
+	promptsGrep = "("
+
+	err := runPrompts(promptsCmd, []string{"/test/project"})
+	if err == nil || !strings.Contains(err.Error(), "invalid --grep pattern") {
+		t.Errorf("runPrompts() error = %v, want invalid pattern error", err)
+	}
+}
+
+func TestDisplayPrompt(t *testing.T) {
+	tests := []struct {
+		prompt string
+		max    int
+		want   string
+	}{
+		{"short", 10, "short"},
+		{"two\n  lines\there", 40, "two lines here"},
+		{"abcdefghij", 8, "abcde..."},
+		{"ééééé", 4, "é..."},
+		{"abcdef", 2, "ab"},
+	}
+	for _, tt := range tests {
+		if got := displayPrompt(tt.prompt, tt.max); got != tt.want {
+			t.Errorf("displayPrompt(%q, %d) = %q, want %q", tt.prompt, tt.max, got, tt.want)
+		}
+	}
+}
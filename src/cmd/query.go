@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -24,14 +27,35 @@ var (
 	queryEnd           string
 	queryTypes         string
 	querySessionID     string
+	queryLatest        bool // --latest flag for the most recently modified session
 	queryAgentID       string
-	queryTools         string // --tool flag
-	queryToolMatch     string // --tool-match flag
-	queryIncludeAgents bool   // --include-agents flag
-	queryLimit         int    // --limit flag for text truncation (0 = no truncation)
-	queryText          string // --text flag for searching message content
+	queryTools         string   // --tool flag
+	queryToolMatch     string   // --tool-match flag
+	queryToolFields    []string // --tool-field flags, each field=regex
+	queryIncludeAgents bool     // --include-agents flag
+	queryIncludeDesc   bool     // --include-descendants flag, with --agent
+	queryLimit         int      // --limit flag for text truncation (0 = no truncation)
+	queryText          string   // --text flag for searching message content
+	queryErrors        bool     // --errors flag for entries with failed tool calls
+	querySpawnsOnly    bool     // --spawns-only flag for entries that spawned agents
+	queryCwd           string   // --cwd flag for entries recorded in a directory (or below it)
+	queryBranch        string   // --branch flag for entries recorded on a git branch
+	queryUserTurn      int      // --user-turn flag for the Nth user message
+	queryAssistantTurn int      // --assistant-turn flag for the Nth assistant message
+	queryCountOnly     bool     // --count-only flag to print the number of matching entries
+	queryCountBy       string   // --count-by flag for a breakdown by type, tool, or agent
+	queryFailOnEmpty   bool     // --fail-on-empty flag to exit with status 2 when nothing matched
+	queryFilterProfile string   // --filter flag naming a filter profile from the config file
+	queryAfterUUID     string   // --after-uuid flag for entries after the last one a poller saw
+	queryContext       int      // --context flag for messages shown around each match
+
+	queryWithin     time.Duration // --within flag for entries in the first part of a session
+	querySinceStart time.Duration // --since-start flag for entries after the first part of a session
 )
 
+// countByModes lists the valid --count-by values.
+var countByModes = []string{"type", "tool", "agent"}
+
 // knownTools is used for validation warnings when unknown tool types are specified
 var knownTools = map[string]bool{
 	"bash": true, "read": true, "write": true, "edit": true,
@@ -57,9 +81,17 @@ Examples:
   # Query specific session
   claude-history query /path/to/project --session 679761ba-80c0-4cd3-a586-cc6a1fc56308
 
+  # Query the session modified most recently (e.g. the one still running)
+  claude-history query /path/to/project --latest
+
   # Query specific agent (reads agent's JSONL file directly)
   claude-history query /path/to/project --session <session-id> --agent <agent-id>
 
+  # Only an agent's own responses, as markdown; add --include-descendants for
+  # the agents it spawned too
+  claude-history query /path/to/project --session <session-id> --agent <agent-id> --type assistant --format markdown
+  claude-history query /path/to/project --session <session-id> --agent <agent-id> --include-descendants
+
   # Query session including all subagent entries
   claude-history query /path/to/project --session <session-id> --include-agents
 
@@ -70,14 +102,63 @@ Examples:
   # Filter by tool input pattern
   claude-history query /path/to/project --tool bash --tool-match "git"
 
+  # Filter by a tool input field (dot notation for nested fields); repeat to
+  # require several fields of the same call
+  claude-history query /path/to/project --tool bash --tool-field command='^git (push|commit)'
+  claude-history query /path/to/project --tool-field file_path='\.go$' --tool-field old_string=TODO
+
+  # Show only tool calls that failed (optionally limited to a tool type)
+  claude-history query /path/to/project --errors
+  claude-history query /path/to/project --errors --tool bash
+
+  # Show only the entries that spawned subagents, to review delegation
+  claude-history query /path/to/project --session <session-id> --spawns-only
+
+  # Only entries recorded on a git branch, or in a directory (or below it)
+  claude-history query /path/to/project --branch feature/login
+  claude-history query /path/to/project --cwd /path/to/project/frontend
+
+  # Extract a single turn: the 3rd user message, or the 2nd assistant reply as markdown
+  claude-history query /path/to/project --session <session-id> --user-turn 3
+  claude-history query /path/to/project --session <session-id> --assistant-turn 2 --format markdown --limit 0
+
+  # Only the first five minutes of a session, or what happened after its first hour
+  claude-history query /path/to/project --session <session-id> --within 5m
+  claude-history query /path/to/project --session <session-id> --since-start 1h
+
+  # Use the filter profile bash-errors from the config file; flags override
+  # single options of the profile
+  claude-history query /path/to/project --filter bash-errors
+  claude-history query /path/to/project --filter bash-errors --tool read
+
   # Search for text in message content
   claude-history query /path/to/project --text "resurrect"
   claude-history query /path/to/project --type user --text "search term"
 
+  # Show two messages before and after each match, like grep -C
+  claude-history query /path/to/project --text "resurrect" --context 2
+
+  # Count matching entries instead of printing them
+  claude-history query /path/to/project --session <session-id> --tool bash --count-only
+  claude-history query /path/to/project --session <session-id> --count-by type
+  claude-history query /path/to/project --session <session-id> --include-agents --count-by agent
+
+  # Poll a live session: pass the UUID of the last entry received to get only
+  # newer entries (an empty JSON array when there are none)
+  claude-history query /path/to/project --session <session-id> --format json --after-uuid <uuid>
+
+  # Exit with status 2 (instead of 0) when nothing matches, for scripts
+  claude-history query /path/to/project --tool bash --errors --fail-on-empty
+
   # Output formats
   claude-history query /path/to/project --format json
   claude-history query /path/to/project --format summary
   claude-history query /path/to/project --format html
+  claude-history query /path/to/project --format markdown
+
+  # Write the matching entries as JSONL (their original lines), e.g. to share a
+  # filtered slice of a session with another tool
+  claude-history query /path/to/project --session <session-id> --tool bash --format jsonl > bash.jsonl
 
   # Control text truncation
   claude-history query /path/to/project --limit 0        # No truncation (full content)
@@ -88,9 +169,35 @@ Agent Queries:
   When --agent is specified, the command reads the agent's JSONL file directly
   instead of filtering the main session file. This provides accurate results
   for agent-specific queries, as agent entries are stored in separate files.
+  Only the agent's own entries are returned; --include-descendants adds the
+  entries of the agents it spawned, recursively. Markdown and HTML output
+  labels the prompts the agent received "Orchestrator" and its responses
+  "Agent".
 
   When --include-agents is specified, entries from all subagents are included
-  in the query results, recursively gathering entries from nested agents.`,
+  in the query results, recursively gathering entries from nested agents.
+
+Context:
+  --context N prints up to N user or assistant messages before and after
+  each match, from the match's own session. Matches close enough for their
+  context to meet are printed as one passage; passages are separated by
+  "--" lines and matches are marked with ">". With --format json, each
+  match is an object holding the entry and its "before" and "after"
+  messages, with "joined" set when it continues the previous passage.
+  --context supports the text and json formats, and not --agent,
+  --include-agents or counting.
+
+Counting:
+  --count-only prints the number of entries left after all filters, and
+  --count-by prints one "<key>\t<count>" line per type, tool, or agent,
+  largest first. Both ignore --format. --count-by tool counts tool calls,
+  so an entry with several calls contributes to several tools; --count-by
+  agent labels main-session entries "main".
+
+Exit Status:
+  0 on success (including no matches), 1 on errors, and 2 when
+  --fail-on-empty is set and no entries matched. Counts are still
+  printed before exiting with 2.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runQuery,
 }
@@ -102,12 +209,29 @@ func init() {
 	queryCmd.Flags().StringVar(&queryEnd, "end", "", "End date (ISO 8601 format)")
 	queryCmd.Flags().StringVar(&queryTypes, "type", "", "Entry types to include (comma-separated: user,assistant,system)")
 	queryCmd.Flags().StringVar(&querySessionID, "session", "", "Filter to specific session ID")
+	queryCmd.Flags().BoolVar(&queryLatest, "latest", false, "Query the most recently modified session")
 	queryCmd.Flags().StringVar(&queryAgentID, "agent", "", "Query specific agent (reads agent's JSONL file directly)")
 	queryCmd.Flags().StringVar(&queryTools, "tool", "", "Filter by tool types (comma-separated: bash,read,write)")
 	queryCmd.Flags().StringVar(&queryToolMatch, "tool-match", "", "Filter by tool input regex pattern")
+	queryCmd.Flags().StringArrayVar(&queryToolFields, "tool-field", nil, "Filter by a tool input field regex, as field=regex (dot notation for nested fields; repeatable)")
 	queryCmd.Flags().BoolVar(&queryIncludeAgents, "include-agents", false, "Include entries from all subagents")
+	queryCmd.Flags().BoolVar(&queryIncludeDesc, "include-descendants", false, "With --agent, include entries from the agents it spawned (recursively)")
 	queryCmd.Flags().IntVar(&queryLimit, "limit", 100, "Maximum characters per entry in text format (0 = no limit)")
 	queryCmd.Flags().StringVar(&queryText, "text", "", "Search for text in message content (case-insensitive)")
+	queryCmd.Flags().BoolVar(&queryErrors, "errors", false, "Only include assistant entries with a tool call that returned an error")
+	queryCmd.Flags().BoolVar(&querySpawnsOnly, "spawns-only", false, "Only include entries that spawned subagents (queue operations or toolUseResult spawns)")
+	queryCmd.Flags().StringVar(&queryCwd, "cwd", "", "Only include entries recorded with this working directory or one below it")
+	queryCmd.Flags().StringVar(&queryBranch, "branch", "", "Only include entries recorded on this git branch")
+	queryCmd.Flags().IntVar(&queryUserTurn, "user-turn", 0, "Only include the Nth user message (1-based, counted before other filters)")
+	queryCmd.Flags().IntVar(&queryAssistantTurn, "assistant-turn", 0, "Only include the Nth assistant message (1-based, counted before other filters)")
+	queryCmd.Flags().DurationVar(&queryWithin, "within", 0, "Only include entries within this long of the session start (e.g. 5m; the start is the session's first timestamp)")
+	queryCmd.Flags().DurationVar(&querySinceStart, "since-start", 0, "Only include entries at least this long after the session start (e.g. 1h)")
+	queryCmd.Flags().BoolVar(&queryCountOnly, "count-only", false, "Print only the number of matching entries")
+	queryCmd.Flags().StringVar(&queryCountBy, "count-by", "", "Print matching counts grouped by: type, tool, agent")
+	queryCmd.Flags().StringVar(&queryFilterProfile, filterProfileFlag, "", "Apply a named filter profile from the config file's filters section (flags override its options)")
+	queryCmd.Flags().BoolVar(&queryFailOnEmpty, "fail-on-empty", false, "Exit with status 2 when no entries match")
+	queryCmd.Flags().IntVar(&queryContext, "context", 0, "Show up to N messages before and after each match, merging nearby matches (text and json formats)")
+	queryCmd.Flags().StringVar(&queryAfterUUID, "after-uuid", "", "Only include matching entries after the one with this UUID, for polling a live session (unknown or empty = all)")
 }
 
 func runQuery(cmd *cobra.Command, args []string) error {
@@ -126,7 +250,15 @@ func runQuery(cmd *cobra.Command, args []string) error {
 
 	// Resolve session ID prefix if provided
 	var resolvedSessionID string
-	if querySessionID != "" {
+	if queryLatest {
+		if querySessionID != "" {
+			return fmt.Errorf("--latest cannot be combined with --session")
+		}
+		resolvedSessionID, err = session.LatestSession(projectDir)
+		if err != nil {
+			return fmt.Errorf("failed to find latest session: %w", err)
+		}
+	} else if querySessionID != "" {
 		resolvedSessionID, err = resolver.ResolveSessionID(projectDir, querySessionID)
 		if err != nil {
 			return fmt.Errorf("failed to resolve session ID: %w", err)
@@ -149,6 +281,16 @@ func runQuery(cmd *cobra.Command, args []string) error {
 	if queryIncludeAgents && resolvedAgentID != "" {
 		return fmt.Errorf("--include-agents and --agent cannot be used together")
 	}
+	if queryIncludeDesc && resolvedAgentID == "" {
+		return fmt.Errorf("--include-descendants requires --agent")
+	}
+	if queryCountBy != "" && !isCountByMode(queryCountBy) {
+		return fmt.Errorf("invalid --count-by %q (valid: %s)", queryCountBy, strings.Join(countByModes, ", "))
+	}
+
+	if err := validateQueryContext(outputFormat, resolvedAgentID); err != nil {
+		return err
+	}
 
 	// Build filter options (don't pass agent ID since we read agent file directly)
 	filterOpts, err := buildFilterOptions("")
@@ -156,13 +298,37 @@ func runQuery(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Queries across all sessions report their progress on a terminal
+	var progress *queryProgress
+	if resolvedSessionID == "" {
+		progress = newQueryProgress(os.Stderr)
+	}
+
+	if queryContext > 0 {
+		hits, err := queryHitsWithContext(projectDir, resolvedSessionID, filterOpts, queryContext, progress)
+		if err != nil {
+			return err
+		}
+		defer progress.done(len(hits))
+		if len(hits) == 0 {
+			if queryAfterUUID != "" && outputFormat == output.FormatJSON && !queryFailOnEmpty {
+				return output.WriteJSON(os.Stdout, []session.SearchHit{})
+			}
+			return noMatches("No entries found matching criteria", queryFailOnEmpty)
+		}
+		if outputFormat == output.FormatJSON {
+			return output.WriteJSON(os.Stdout, hits)
+		}
+		return writeHitsWithContext(os.Stdout, hits, colorizer(os.Stdout))
+	}
+
 	// Collect entries
 	var allEntries []models.ConversationEntry
 
 	if resolvedSessionID != "" {
 		if resolvedAgentID != "" {
 			// Query specific agent - read agent's JSONL file directly
-			entries, err := queryAgentFile(projectDir, resolvedSessionID, resolvedAgentID, filterOpts)
+			entries, err := queryAgentFileWith(projectDir, resolvedSessionID, resolvedAgentID, filterOpts, queryIncludeDesc)
 			if err != nil {
 				return err
 			}
@@ -189,6 +355,7 @@ func runQuery(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
+		progress.begin(len(sessions))
 		for _, s := range sessions {
 			var entries []models.ConversationEntry
 			var queryErr error
@@ -198,6 +365,7 @@ func runQuery(cmd *cobra.Command, args []string) error {
 			} else {
 				entries, queryErr = querySession(projectDir, s.ID, filterOpts)
 			}
+			progress.sessionDone(len(entries))
 			if queryErr != nil {
 				// Skip sessions that can't be read
 				continue
@@ -206,11 +374,33 @@ func runQuery(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if len(allEntries) == 0 {
-		fmt.Fprintln(os.Stderr, "No entries found matching criteria")
+	// Pollers pass the last UUID they received to get only what came after it
+	allEntries = session.EntriesAfter(allEntries, queryAfterUUID)
+	defer progress.done(len(allEntries))
+
+	// Counts are printed even when nothing matched, so scripts always get a number
+	if queryCountBy != "" || queryCountOnly {
+		if queryCountBy != "" {
+			if err := writeCountBy(os.Stdout, allEntries, queryCountBy); err != nil {
+				return err
+			}
+		} else {
+			fmt.Println(len(allEntries))
+		}
+		if len(allEntries) == 0 && queryFailOnEmpty {
+			return noMatchesError("No entries found matching criteria")
+		}
 		return nil
 	}
 
+	if len(allEntries) == 0 {
+		// A poll that found nothing new is still JSON for the poller to parse
+		if queryAfterUUID != "" && outputFormat == output.FormatJSON && !queryFailOnEmpty {
+			return output.WriteJSON(os.Stdout, []models.ConversationEntry{})
+		}
+		return noMatches("No entries found matching criteria", queryFailOnEmpty)
+	}
+
 	// Handle HTML format specially - generate and open HTML file
 	if outputFormat == output.FormatHTML {
 		// Build session folder path if we have a session ID
@@ -232,14 +422,196 @@ func runQuery(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	return output.WriteEntries(os.Stdout, allEntries, outputFormat, queryLimit)
+	if outputFormat == output.FormatMarkdown {
+		userLabel, assistantLabel := queryRoleLabels(resolvedAgentID)
+		_, err := io.WriteString(os.Stdout, export.RenderQueryResultsMarkdown(allEntries, userLabel, assistantLabel))
+		return err
+	}
+
+	if outputFormat == output.FormatJSONL {
+		return session.WriteJSONL(os.Stdout, allEntries)
+	}
+
+	return output.WriteEntriesWith(os.Stdout, allEntries, outputFormat, queryLimit, colorizer(os.Stdout))
+}
+
+// validateQueryContext checks that --context is combined only with the options it
+// supports: one session or all of them, without counting, in text or json format.
+func validateQueryContext(outputFormat output.Format, agentID string) error {
+	if queryContext < 0 {
+		return fmt.Errorf("--context must not be negative")
+	}
+	if queryContext == 0 {
+		return nil
+	}
+	switch {
+	case agentID != "" || queryIncludeAgents:
+		return fmt.Errorf("--context cannot be used with --agent or --include-agents")
+	case queryCountOnly || queryCountBy != "":
+		return fmt.Errorf("--context cannot be used with --count-only or --count-by")
+	case outputFormat != output.FormatList && outputFormat != output.FormatJSON:
+		return fmt.Errorf("--context is only supported for text and json formats")
+	}
+	return nil
+}
+
+// queryHitsWithContext runs the query over one session, or every session when
+// sessionID is empty, pairing each match with up to n messages around it from its
+// session (see session.HitsWithContext). --after-uuid drops the matches up to the given
+// one, across sessions, as it does without context. Sessions that cannot be read are
+// skipped when querying all of them. progress, if not nil, is told about each session.
+func queryHitsWithContext(projectDir, sessionID string, opts session.FilterOptions, n int, progress *queryProgress) ([]session.SearchHit, error) {
+	var sessionIDs []string
+	if sessionID != "" {
+		sessionIDs = []string{sessionID}
+	} else {
+		sessions, err := session.ListSessions(projectDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range sessions {
+			sessionIDs = append(sessionIDs, s.ID)
+		}
+	}
+
+	type sessionMatches struct {
+		entries, matches []models.ConversationEntry
+	}
+	var all []sessionMatches
+	var matched []models.ConversationEntry
+	progress.begin(len(sessionIDs))
+	for _, id := range sessionIDs {
+		filePath := paths.JSONLFile(filepath.Join(projectDir, id))
+		if !paths.Exists(filePath) {
+			if sessionID != "" {
+				return nil, fmt.Errorf("%w: no file %s", resolver.ErrSessionNotFound, filePath)
+			}
+			progress.sessionDone(0)
+			continue
+		}
+		entries, err := session.ReadSession(filePath)
+		if err != nil {
+			if sessionID != "" {
+				return nil, err
+			}
+			progress.sessionDone(0)
+			continue
+		}
+		matches := session.FilterEntries(entries, opts)
+		progress.sessionDone(len(matches))
+		all = append(all, sessionMatches{entries: entries, matches: matches})
+		matched = append(matched, matches...)
+	}
+
+	skip := len(matched) - len(session.EntriesAfter(matched, queryAfterUUID))
+	var hits []session.SearchHit
+	for _, s := range all {
+		drop := min(skip, len(s.matches))
+		skip -= drop
+		hits = append(hits, session.HitsWithContext(s.entries, s.matches[drop:], n)...)
+	}
+	return hits, nil
+}
+
+// writeHitsWithContext writes each match with its context, one line per entry (see
+// output.WriteEntryLineWith): matches are marked with "> ", context indented to line up,
+// and passages that do not continue the previous one are separated by "--" lines.
+func writeHitsWithContext(w io.Writer, hits []session.SearchHit, c output.Colorizer) error {
+	var buf strings.Builder
+	writeLine := func(prefix string, entry models.ConversationEntry) {
+		var line strings.Builder
+		if output.WriteEntryLineWith(&line, entry, c) {
+			buf.WriteString(prefix + line.String())
+		}
+	}
+	for i, hit := range hits {
+		if i > 0 && !hit.Joined {
+			buf.WriteString("--\n")
+		}
+		for _, entry := range hit.Before {
+			writeLine("  ", entry)
+		}
+		writeLine("> ", hit.Entry)
+		for _, entry := range hit.After {
+			writeLine("  ", entry)
+		}
+	}
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// queryRoleLabels returns the names for user and assistant entries in query output:
+// "Orchestrator"/"Agent" when querying a subagent, whose user entries are the prompts
+// it received, and "User"/"Assistant" otherwise.
+func queryRoleLabels(agentID string) (userLabel, assistantLabel string) {
+	if agentID != "" {
+		return "Orchestrator", "Agent"
+	}
+	return "User", "Assistant"
+}
+
+// isCountByMode reports whether mode is a valid --count-by value.
+func isCountByMode(mode string) bool {
+	for _, m := range countByModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCountBy writes one "<key>\t<count>" line per group of entries, largest first
+// with ties broken by key. mode must be one of countByModes.
+func writeCountBy(w io.Writer, entries []models.ConversationEntry, mode string) error {
+	counts := make(map[string]int)
+	switch mode {
+	case "type":
+		for t, n := range session.CountEntriesByType(entries) {
+			counts[string(t)] = n
+		}
+	case "tool":
+		counts = session.CountToolCalls(entries)
+	case "agent":
+		for _, entry := range entries {
+			counts[entryAgentKey(entry)]++
+		}
+	default:
+		return fmt.Errorf("invalid --count-by %q (valid: %s)", mode, strings.Join(countByModes, ", "))
+	}
+
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "%s\t%d\n", k, counts[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entryAgentKey returns the agent an entry belongs to for --count-by agent.
+// Main-session entries (including queue operations that name a spawned agent) are "main".
+func entryAgentKey(entry models.ConversationEntry) string {
+	if entry.AgentID == "" || (entry.Type == models.EntryTypeQueueOperation && !entry.IsSidechain) {
+		return "main"
+	}
+	return entry.AgentID
 }
 
 func querySession(projectDir string, sessionID string, opts session.FilterOptions) ([]models.ConversationEntry, error) {
-	filePath := filepath.Join(projectDir, sessionID+".jsonl")
+	filePath := paths.JSONLFile(filepath.Join(projectDir, sessionID))
 
 	if !paths.Exists(filePath) {
-		return nil, fmt.Errorf("session file not found: %s", filePath)
+		return nil, fmt.Errorf("%w: no file %s", resolver.ErrSessionNotFound, filePath)
 	}
 
 	entries, err := session.ReadSession(filePath)
@@ -259,7 +631,7 @@ func getAgentPath(projectDir, sessionID, agentID string) (string, error) {
 	sessionDir := filepath.Join(projectDir, sessionID)
 
 	// Try standard location first
-	agentPath := filepath.Join(sessionDir, "subagents", "agent-"+agentID+".jsonl")
+	agentPath := paths.JSONLFile(filepath.Join(sessionDir, "subagents", "agent-"+agentID))
 	if paths.Exists(agentPath) {
 		return agentPath, nil
 	}
@@ -272,37 +644,97 @@ func getAgentPath(projectDir, sessionID, agentID string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("agent not found: %s", agentID)
+	return "", fmt.Errorf("%w: %s", resolver.ErrAgentNotFound, agentID)
 }
 
-// queryAgentFile reads and queries an agent's JSONL file directly.
+// queryAgentFile reads and queries an agent's JSONL file directly, keeping only the
+// agent's own entries.
 func queryAgentFile(projectDir, sessionID, agentID string, opts session.FilterOptions) ([]models.ConversationEntry, error) {
+	return queryAgentFileWith(projectDir, sessionID, agentID, opts, false)
+}
+
+// queryAgentFileWith reads and queries an agent's JSONL file directly. Entries of
+// nested descendant agents, whether recorded in the agent's file or in their own
+// files under its subagents directory, are excluded unless includeDescendants is set.
+func queryAgentFileWith(projectDir, sessionID, agentID string, opts session.FilterOptions, includeDescendants bool) ([]models.ConversationEntry, error) {
 	agentPath, err := getAgentPath(projectDir, sessionID, agentID)
 	if err != nil {
 		return nil, err
 	}
 
-	entries, err := session.ReadSession(agentPath)
+	entries, err := readAgentEntries(agentPath, agentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read agent file: %w", err)
 	}
 
-	// Apply filters
+	if !includeDescendants {
+		opts.AgentID = agentID
+		return session.FilterEntries(entries, opts), nil
+	}
+
 	filtered := session.FilterEntries(entries, opts)
 
+	// Descendants live under the agent's own directory: agent-<id>/subagents/
+	agentDir, _ := paths.TrimJSONLExt(agentPath)
+	descendants, err := paths.ListAgentFiles(agentDir)
+	if err != nil {
+		return filtered, nil
+	}
+	ids := make([]string, 0, len(descendants))
+	for id := range descendants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		entries, err := readAgentEntries(descendants[id], id)
+		if err != nil {
+			// Skip agents that can't be read
+			continue
+		}
+		filtered = append(filtered, session.FilterEntries(entries, opts)...)
+	}
+
 	return filtered, nil
 }
 
+// readAgentEntries reads an agent's JSONL file. Entries recorded without an agent ID
+// are the agent's own, so they get agentID, letting FilterOptions.AgentID tell them
+// apart from entries of descendant agents.
+func readAgentEntries(agentPath, agentID string) ([]models.ConversationEntry, error) {
+	entries, err := session.ReadSession(agentPath)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].AgentID == "" {
+			entries[i].AgentID = agentID
+		}
+	}
+	return entries, nil
+}
+
 // querySessionWithAgents queries the main session and all subagent files.
 func querySessionWithAgents(projectDir, sessionID string, opts session.FilterOptions) ([]models.ConversationEntry, error) {
 	var allEntries []models.ConversationEntry
 
 	// First, query the main session file
-	mainEntries, err := querySession(projectDir, sessionID, opts)
+	filePath := paths.JSONLFile(filepath.Join(projectDir, sessionID))
+	if !paths.Exists(filePath) {
+		return nil, fmt.Errorf("%w: no file %s", resolver.ErrSessionNotFound, filePath)
+	}
+	mainEntries, err := session.ReadSession(filePath)
 	if err != nil {
 		return nil, err
 	}
-	allEntries = append(allEntries, mainEntries...)
+	// Times relative to the session start count from the main session's start in
+	// agent files too, not from each agent's first entry
+	if opts.SessionStart.IsZero() {
+		if start, ok := session.SessionStartTime(mainEntries); ok {
+			opts.SessionStart = start
+		}
+	}
+	allEntries = append(allEntries, session.FilterEntries(mainEntries, opts)...)
 
 	// Then, query all agent files
 	sessionDir := filepath.Join(projectDir, sessionID)
@@ -390,12 +822,81 @@ func buildFilterOptions(resolvedAgentID string) (session.FilterOptions, error) {
 	// Tool match pattern
 	opts.ToolMatch = queryToolMatch
 
+	// Tool input field patterns
+	fields, err := parseToolFields(queryToolFields)
+	if err != nil {
+		return opts, err
+	}
+	opts.ToolFieldMatch = fields
+
 	// Text search pattern
 	opts.TextSearch = queryText
 
+	// Errored tool calls only
+	opts.ToolErrorsOnly = queryErrors
+
+	// Agent spawn entries only
+	opts.SpawnsOnly = querySpawnsOnly
+
+	// Working directory (relative paths are taken from the current directory)
+	if queryCwd != "" {
+		cwd, err := filepath.Abs(queryCwd)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --cwd: %w", err)
+		}
+		opts.Cwd = cwd
+	}
+
+	// Git branch
+	opts.GitBranch = queryBranch
+
+	// Speaker turns
+	if queryUserTurn < 0 || queryAssistantTurn < 0 {
+		return opts, fmt.Errorf("--user-turn and --assistant-turn must not be negative")
+	}
+	opts.UserTurn = queryUserTurn
+	opts.AssistantTurn = queryAssistantTurn
+
+	// Time relative to the session start (zero means not set)
+	if queryWithin < 0 || querySinceStart < 0 {
+		return opts, fmt.Errorf("--within and --since-start must not be negative")
+	}
+	if queryWithin > 0 {
+		within := queryWithin
+		opts.UntilStart = &within
+	}
+	if querySinceStart > 0 {
+		since := querySinceStart
+		opts.SinceStart = &since
+	}
+
 	return opts, nil
 }
 
+// parseToolFields parses --tool-field values of the form field=regex into a map of
+// field paths to regexes, checking that each regex compiles.
+func parseToolFields(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	fields := make(map[string]string, len(values))
+	for _, v := range values {
+		field, pattern, ok := strings.Cut(v, "=")
+		field = strings.TrimSpace(field)
+		if !ok || field == "" {
+			return nil, fmt.Errorf("invalid --tool-field %q: want field=regex", v)
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid --tool-field %q: %w", v, err)
+		}
+		if _, dup := fields[field]; dup {
+			return nil, fmt.Errorf("invalid --tool-field %q: field %s given more than once", v, field)
+		}
+		fields[field] = pattern
+	}
+	return fields, nil
+}
+
 func parseTime(s string) (time.Time, error) {
 	// Try various formats
 	formats := []string{
@@ -437,13 +938,7 @@ func generateQueryHTML(projectPath, sessionFolderPath string, entries []models.C
 	tmpFile := filepath.Join(os.TempDir(), fileName)
 
 	// Determine role labels based on context
-	userLabel := "User"
-	assistantLabel := "Assistant"
-	if agentID != "" {
-		// For subagent queries, use Orchestrator/Agent labels
-		userLabel = "Orchestrator"
-		assistantLabel = "Agent"
-	}
+	userLabel, assistantLabel := queryRoleLabels(agentID)
 
 	// Render entries as HTML using export package
 	htmlContent, err := export.RenderQueryResults(entries, projectPath, sessionID, sessionFolderPath, agentID, userLabel, assistantLabel)
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+)
+
+// spinnerFrames are drawn in turn at the start of the progress line.
+var spinnerFrames = []string{"|", "/", "-", `\`}
+
+// queryProgress reports on stderr how far a query across all sessions of a project has
+// got: a spinner line, rewritten after each session, with the sessions scanned and the
+// matches found so far, then a summary with the number of matches printed. It writes
+// nothing unless its writer is a terminal, so piped or redirected runs get only their
+// results. A nil *queryProgress is a no-op.
+type queryProgress struct {
+	w       io.Writer
+	total   int // Sessions to scan
+	scanned int // Sessions scanned so far
+	matches int // Matches found so far
+}
+
+// newQueryProgress returns a progress reporter writing to w, or nil if w is not a
+// terminal.
+func newQueryProgress(w io.Writer) *queryProgress {
+	if !isTerminal(w) {
+		return nil
+	}
+	return &queryProgress{w: w}
+}
+
+// begin starts a scan of total sessions.
+func (p *queryProgress) begin(total int) {
+	if p == nil {
+		return
+	}
+	p.total = total
+}
+
+// sessionDone records a scanned session that had matches matching entries.
+func (p *queryProgress) sessionDone(matches int) {
+	if p == nil {
+		return
+	}
+	p.scanned++
+	p.matches += matches
+	fmt.Fprintf(p.w, "\r\033[K%s Scanning sessions: %d/%d, %d %s", spinnerFrames[p.scanned%len(spinnerFrames)],
+		p.scanned, p.total, p.matches, pluralize(p.matches, "match", "matches"))
+}
+
+// done clears the progress line and writes the summary. printed is the number of
+// matches in the output, which can be fewer than those found, e.g. with --after-uuid.
+func (p *queryProgress) done(printed int) {
+	if p == nil {
+		return
+	}
+	fmt.Fprintf(p.w, "\r\033[KScanned %d %s: %d %s\n", p.scanned, pluralize(p.scanned, "session", "sessions"),
+		printed, pluralize(printed, "match", "matches"))
+}
+
+// pluralize returns singular for a count of 1 and plural otherwise.
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
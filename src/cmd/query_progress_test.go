@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestQueryProgress(t *testing.T) {
+	var buf bytes.Buffer
+	p := &queryProgress{w: &buf}
+	p.begin(3)
+	p.sessionDone(2)
+	p.sessionDone(0)
+	p.sessionDone(1)
+	p.done(3)
+
+	out := buf.String()
+	for _, want := range []string{"Scanning sessions: 1/3, 2 matches", "Scanning sessions: 3/3, 3 matches"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("progress missing %q:\n%q", want, out)
+		}
+	}
+	if want := "\r\033[KScanned 3 sessions: 3 matches\n"; !strings.HasSuffix(out, want) {
+		t.Errorf("progress should end with the summary %q, got:\n%q", want, out)
+	}
+
+	buf.Reset()
+	p = &queryProgress{w: &buf}
+	p.begin(1)
+	p.sessionDone(1)
+	p.done(1)
+	if !strings.HasSuffix(buf.String(), "Scanned 1 session: 1 match\n") {
+		t.Errorf("summary should be singular for one, got %q", buf.String())
+	}
+}
+
+func TestQueryProgress_SilentWithoutTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	p := newQueryProgress(&buf)
+	if p != nil {
+		t.Fatal("newQueryProgress() should be nil for a writer that is not a terminal")
+	}
+	// A nil reporter does nothing
+	p.begin(2)
+	p.sessionDone(1)
+	p.done(1)
+	if buf.Len() != 0 {
+		t.Errorf("nil progress wrote %q", buf.String())
+	}
+}
@@ -1,9 +1,17 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/randlee/claude-history/pkg/models"
 	"github.com/randlee/claude-history/pkg/session"
@@ -199,6 +207,94 @@ func TestQueryAgentFile(t *testing.T) {
 	})
 }
 
+func TestQueryAgentFileWith_Descendants(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := createTestProjectStructure(t, tmpDir)
+	sessionID := "679761ba-80c0-4cd3-a586-cc6a1fc56308"
+	createNestedAgentStructureForQuery(t, projectDir, sessionID)
+	agentID := "nested1-111-222-333-444555666777"
+
+	// A descendant entry recorded inline, and an own entry without an agent ID
+	agentFile := filepath.Join(projectDir, sessionID, "subagents", "agent-"+agentID+".jsonl")
+	f, err := os.OpenFile(agentFile, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteString(`{"uuid":"n3","agentId":"nested2-aaa-bbb-ccc-dddeeefffggg","type":"assistant","timestamp":"2026-02-01T11:05:10.000Z","message":"Inline descendant response"}
+{"uuid":"n4","type":"assistant","timestamp":"2026-02-01T11:06:00.000Z","message":"Nested agent 1 wrap-up"}
+`)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uuids := func(entries []models.ConversationEntry) string {
+		var ids []string
+		for _, e := range entries {
+			ids = append(ids, e.UUID)
+		}
+		return strings.Join(ids, ",")
+	}
+	assistants := session.FilterOptions{Types: []models.EntryType{models.EntryTypeAssistant}}
+
+	t.Run("own entries only by default", func(t *testing.T) {
+		entries, err := queryAgentFileWith(projectDir, sessionID, agentID, assistants, false)
+		if err != nil {
+			t.Fatalf("queryAgentFileWith() error: %v", err)
+		}
+		if got := uuids(entries); got != "n2,n4" {
+			t.Errorf("entries = %s, want n2,n4", got)
+		}
+		for _, e := range entries {
+			if e.AgentID != agentID {
+				t.Errorf("entry %s has agentId %q, want %q", e.UUID, e.AgentID, agentID)
+			}
+		}
+	})
+
+	t.Run("include descendants", func(t *testing.T) {
+		entries, err := queryAgentFileWith(projectDir, sessionID, agentID, assistants, true)
+		if err != nil {
+			t.Fatalf("queryAgentFileWith() error: %v", err)
+		}
+		if got := uuids(entries); got != "n2,n3,n4,nn2" {
+			t.Errorf("entries = %s, want n2,n3,n4,nn2", got)
+		}
+	})
+
+	t.Run("leaf agent has no descendants", func(t *testing.T) {
+		entries, err := queryAgentFileWith(projectDir, sessionID, "nested2-aaa-bbb-ccc-dddeeefffggg", assistants, true)
+		if err != nil {
+			t.Fatalf("queryAgentFileWith() error: %v", err)
+		}
+		if got := uuids(entries); got != "nn2" {
+			t.Errorf("entries = %s, want nn2", got)
+		}
+	})
+}
+
+func TestQueryRoleLabels(t *testing.T) {
+	if user, assistant := queryRoleLabels("abc"); user != "Orchestrator" || assistant != "Agent" {
+		t.Errorf("queryRoleLabels(agent) = %q, %q; want Orchestrator, Agent", user, assistant)
+	}
+	if user, assistant := queryRoleLabels(""); user != "User" || assistant != "Assistant" {
+		t.Errorf("queryRoleLabels(\"\") = %q, %q; want User, Assistant", user, assistant)
+	}
+}
+
+func TestRunQuery_IncludeDescendantsRequiresAgent(t *testing.T) {
+	tmpDir, _, projectPath := setupTestProject(t, "descendants-project")
+
+	oldClaudeDir, oldDesc := claudeDir, queryIncludeDesc
+	defer func() { claudeDir, queryIncludeDesc = oldClaudeDir, oldDesc }()
+	claudeDir = tmpDir
+	queryIncludeDesc = true
+
+	if err := runQuery(queryCmd, []string{projectPath}); err == nil || !strings.Contains(err.Error(), "--include-descendants requires --agent") {
+		t.Errorf("--include-descendants without --agent = %v, want requires --agent error", err)
+	}
+}
+
 func TestQuerySessionWithAgents(t *testing.T) {
 	tmpDir := t.TempDir()
 	projectDir := createTestProjectStructure(t, tmpDir)
@@ -362,3 +458,548 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestBuildFilterOptions_Errors(t *testing.T) {
+	oldErrors, oldTools := queryErrors, queryTools
+	defer func() { queryErrors, queryTools = oldErrors, oldTools }()
+
+	queryErrors = true
+	queryTools = "bash"
+
+	opts, err := buildFilterOptions("")
+	if err != nil {
+		t.Fatalf("buildFilterOptions() error = %v", err)
+	}
+	if !opts.ToolErrorsOnly {
+		t.Error("ToolErrorsOnly should be set by --errors")
+	}
+	if len(opts.ToolTypes) != 1 || opts.ToolTypes[0] != "bash" {
+		t.Errorf("ToolTypes = %v, want [bash]", opts.ToolTypes)
+	}
+}
+
+func TestWriteCountBy(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := createTestProjectStructure(t, tmpDir)
+	sessionID := "679761ba-80c0-4cd3-a586-cc6a1fc56308"
+
+	entries, err := querySessionWithAgents(projectDir, sessionID, session.FilterOptions{})
+	if err != nil {
+		t.Fatalf("querySessionWithAgents() error = %v", err)
+	}
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{"type", "user\t4\nassistant\t3\n"},
+		{"agent", "abc123def-456-789-0ab-cdef12345678\t3\nmain\t2\nxyz789abc-123-456-789-abcdef123456\t2\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeCountBy(&buf, entries, tt.mode); err != nil {
+				t.Fatalf("writeCountBy() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("writeCountBy(%q) =\n%q\nwant\n%q", tt.mode, buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCountBy_CountsAfterFilter(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls"}},{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"a"}}]}`)},
+		{Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t3","name":"Bash","input":{"command":"pwd"}}]}`)},
+		{Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t4","name":"Grep","input":{"pattern":"x"}}]}`)},
+	}
+
+	filtered := session.FilterEntries(entries, session.FilterOptions{ToolTypes: []string{"bash"}})
+	if len(filtered) != 2 {
+		t.Fatalf("filtered %d entries, want 2", len(filtered))
+	}
+
+	var buf bytes.Buffer
+	if err := writeCountBy(&buf, filtered, "tool"); err != nil {
+		t.Fatalf("writeCountBy() error = %v", err)
+	}
+	if want := "Bash\t2\nRead\t1\n"; buf.String() != want {
+		t.Errorf("writeCountBy(tool) = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteCountBy_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeCountBy(&buf, nil, "model"); err == nil {
+		t.Error("writeCountBy() should reject unknown modes")
+	}
+	if isCountByMode("model") || !isCountByMode("tool") {
+		t.Error("isCountByMode() gave the wrong answer")
+	}
+}
+
+func TestEntryAgentKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry models.ConversationEntry
+		want  string
+	}{
+		{"main entry", models.ConversationEntry{Type: models.EntryTypeUser}, "main"},
+		{"agent entry", models.ConversationEntry{Type: models.EntryTypeUser, AgentID: "a1"}, "a1"},
+		{"spawn in main session", models.ConversationEntry{Type: models.EntryTypeQueueOperation, AgentID: "a1"}, "main"},
+		{"spawn in sidechain", models.ConversationEntry{Type: models.EntryTypeQueueOperation, AgentID: "a1", IsSidechain: true}, "a1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := entryAgentKey(tt.entry); got != tt.want {
+				t.Errorf("entryAgentKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if got := exitCode(errors.New("boom")); got != exitError {
+		t.Errorf("exitCode(error) = %d, want %d", got, exitError)
+	}
+	if got := exitCode(noMatchesError("none")); got != exitNoMatches {
+		t.Errorf("exitCode(noMatchesError) = %d, want %d", got, exitNoMatches)
+	}
+	if got := exitCode(fmt.Errorf("wrapped: %w", noMatchesError("none"))); got != exitNoMatches {
+		t.Errorf("exitCode(wrapped noMatchesError) = %d, want %d", got, exitNoMatches)
+	}
+}
+
+func TestNoMatches(t *testing.T) {
+	if err := noMatches("nothing", false); err != nil {
+		t.Errorf("noMatches() without --fail-on-empty should succeed, got %v", err)
+	}
+	err := noMatches("nothing", true)
+	var nm noMatchesError
+	if !errors.As(err, &nm) || err.Error() != "nothing" {
+		t.Errorf("noMatches() with --fail-on-empty = %v, want noMatchesError", err)
+	}
+}
+
+func TestRunQuery_FailOnEmpty(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestProjectStructure(t, filepath.Join(tmpDir, "projects"))
+
+	oldClaudeDir, oldText, oldFail, oldCount := claudeDir, queryText, queryFailOnEmpty, queryCountOnly
+	defer func() {
+		claudeDir, queryText, queryFailOnEmpty, queryCountOnly = oldClaudeDir, oldText, oldFail, oldCount
+	}()
+	claudeDir = tmpDir
+	queryText = "no such text anywhere"
+
+	queryFailOnEmpty = false
+	if err := runQuery(queryCmd, []string{"/test/project"}); err != nil {
+		t.Errorf("empty result without --fail-on-empty should succeed, got %v", err)
+	}
+
+	queryFailOnEmpty = true
+	if err := runQuery(queryCmd, []string{"/test/project"}); exitCode(err) != exitNoMatches {
+		t.Errorf("empty result with --fail-on-empty = %v, want exit status %d", err, exitNoMatches)
+	}
+
+	queryCountOnly = true
+	if err := runQuery(queryCmd, []string{"/test/project"}); exitCode(err) != exitNoMatches {
+		t.Errorf("--count-only with no matches = %v, want exit status %d", err, exitNoMatches)
+	}
+
+	queryCountOnly = false
+	queryText = "Hello main session"
+	if err := runQuery(queryCmd, []string{"/test/project"}); err != nil {
+		t.Errorf("matching query with --fail-on-empty should succeed, got %v", err)
+	}
+}
+
+func TestRunQuery_JSONLFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestProjectStructure(t, filepath.Join(tmpDir, "projects"))
+
+	oldClaudeDir, oldFormat, oldText := claudeDir, format, queryText
+	defer func() { claudeDir, format, queryText = oldClaudeDir, oldFormat, oldText }()
+	claudeDir = tmpDir
+	format = "jsonl"
+	queryText = "Hello main session"
+
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	runErr := runQuery(queryCmd, []string{"/test/project"})
+	_ = w.Close()
+	os.Stdout = oldStdout
+	if runErr != nil {
+		t.Fatalf("runQuery() error = %v", runErr)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 JSONL line, got %d:\n%s", len(lines), out)
+	}
+	var entry models.ConversationEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil || !strings.Contains(entry.GetTextContent(), "Hello main session") {
+		t.Errorf("output line is not the matching entry: %s (%v)", lines[0], err)
+	}
+}
+
+func TestRunQuery_AfterUUID(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestProjectStructure(t, filepath.Join(tmpDir, "projects"))
+
+	oldClaudeDir, oldFormat, oldSession, oldAfter := claudeDir, format, querySessionID, queryAfterUUID
+	defer func() {
+		claudeDir, format, querySessionID, queryAfterUUID = oldClaudeDir, oldFormat, oldSession, oldAfter
+	}()
+	claudeDir = tmpDir
+	format = "json"
+	querySessionID = "679761ba"
+
+	poll := func(after string) []models.ConversationEntry {
+		t.Helper()
+		queryAfterUUID = after
+		oldStdout := os.Stdout
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Stdout = w
+		runErr := runQuery(queryCmd, []string{"/test/project"})
+		_ = w.Close()
+		os.Stdout = oldStdout
+		if runErr != nil {
+			t.Fatalf("runQuery(--after-uuid %q) error = %v", after, runErr)
+		}
+		var entries []models.ConversationEntry
+		if err := json.NewDecoder(r).Decode(&entries); err != nil {
+			t.Fatalf("--after-uuid %q output is not a JSON array: %v", after, err)
+		}
+		return entries
+	}
+
+	all := poll("")
+	if len(all) < 2 {
+		t.Fatalf("initial load returned %d entries, want the whole session", len(all))
+	}
+	if got := poll("unknown-uuid"); len(got) != len(all) {
+		t.Errorf("unknown UUID returned %d entries, want all %d", len(got), len(all))
+	}
+	if got := poll(all[0].UUID); len(got) != len(all)-1 || got[0].UUID != all[1].UUID {
+		t.Errorf("after %q = %d entries, want the %d after it", all[0].UUID, len(got), len(all)-1)
+	}
+	if got := poll(all[len(all)-1].UUID); len(got) != 0 {
+		t.Errorf("after the last entry = %d entries, want an empty array", len(got))
+	}
+}
+
+func TestRunQuery_Zstd(t *testing.T) {
+	tmpDir, projectDir, projectPath := setupTestProject(t, "zstd-query")
+	sessionID := createTestSessionWithAgents(t, projectDir, 1)
+	compressJSONLFiles(t, projectDir)
+
+	oldClaudeDir, oldFormat, oldSession, oldAgent := claudeDir, format, querySessionID, queryAgentID
+	defer func() {
+		claudeDir, format, querySessionID, queryAgentID = oldClaudeDir, oldFormat, oldSession, oldAgent
+	}()
+	claudeDir = tmpDir
+	format = "json"
+	querySessionID = sessionID[:8]
+
+	query := func(agentID string) []models.ConversationEntry {
+		t.Helper()
+		queryAgentID = agentID
+		oldStdout := os.Stdout
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Stdout = w
+		runErr := runQuery(queryCmd, []string{projectPath})
+		_ = w.Close()
+		os.Stdout = oldStdout
+		if runErr != nil {
+			t.Fatalf("runQuery(--agent %q) of a compressed session error = %v", agentID, runErr)
+		}
+		var entries []models.ConversationEntry
+		if err := json.NewDecoder(r).Decode(&entries); err != nil {
+			t.Fatalf("output is not a JSON array: %v", err)
+		}
+		return entries
+	}
+
+	if entries := query(""); len(entries) != 6 || entries[0].UUID != "entry-1" {
+		t.Errorf("session query returned %d entries, want the 6 of the compressed session", len(entries))
+	}
+	if entries := query("agent-1"); len(entries) != 2 || entries[0].UUID != "agent-1-entry-1" {
+		t.Errorf("agent query returned %d entries, want the 2 of the compressed agent file", len(entries))
+	}
+}
+
+func TestRunQuery_Context(t *testing.T) {
+	tmpDir, projectDir, projectPath := setupTestProject(t, "context-project")
+	var lines []string
+	for i, text := range []string{"one", "two", "needle three", "four", "five", "six", "seven", "needle eight"} {
+		lines = append(lines, fmt.Sprintf(`{"uuid":"u%d","type":"user","timestamp":"2026-02-01T10:00:%02dZ","message":"%s"}`, i+1, i, text))
+	}
+	sessionFile := filepath.Join(projectDir, "10000000-0000-0000-0000-000000000000.jsonl")
+	if err := os.WriteFile(sessionFile, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldClaudeDir, oldFormat, oldText, oldContext := claudeDir, format, queryText, queryContext
+	defer func() { claudeDir, format, queryText, queryContext = oldClaudeDir, oldFormat, oldText, oldContext }()
+	claudeDir = tmpDir
+	queryText = "needle"
+	queryContext = 1
+
+	run := func() string {
+		t.Helper()
+		oldStdout := os.Stdout
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Stdout = w
+		runErr := runQuery(queryCmd, []string{projectPath})
+		_ = w.Close()
+		os.Stdout = oldStdout
+		if runErr != nil {
+			t.Fatalf("runQuery(--context) error = %v", runErr)
+		}
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(out)
+	}
+
+	format = ""
+	text := run()
+	for _, want := range []string{"  [10:00:01] user: two\n> [10:00:02] user: needle three\n  [10:00:03] user: four\n--\n", "  [10:00:06] user: seven\n> [10:00:07] user: needle eight\n"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text output missing %q:\n%s", want, text)
+		}
+	}
+
+	format = "json"
+	var hits []session.SearchHit
+	if err := json.Unmarshal([]byte(run()), &hits); err != nil {
+		t.Fatalf("json output is not a list of hits: %v", err)
+	}
+	if len(hits) != 2 || hits[0].Entry.UUID != "u3" || len(hits[0].Before) != 1 || hits[0].After[0].UUID != "u4" || hits[1].Before[0].UUID != "u7" {
+		t.Errorf("json hits = %+v, want each needle with one message either side", hits)
+	}
+}
+
+func TestRunQuery_ContextErrors(t *testing.T) {
+	tmpDir, _, projectPath := setupTestProject(t, "context-errors")
+
+	oldClaudeDir, oldFormat, oldContext, oldCount := claudeDir, format, queryContext, queryCountOnly
+	defer func() {
+		claudeDir, format, queryContext, queryCountOnly = oldClaudeDir, oldFormat, oldContext, oldCount
+	}()
+	claudeDir = tmpDir
+
+	queryContext = -1
+	if err := runQuery(queryCmd, []string{projectPath}); err == nil || !strings.Contains(err.Error(), "--context must not be negative") {
+		t.Errorf("negative --context = %v, want an error", err)
+	}
+	queryContext = 2
+	format = "markdown"
+	if err := runQuery(queryCmd, []string{projectPath}); err == nil || !strings.Contains(err.Error(), "only supported for text and json") {
+		t.Errorf("--context with markdown = %v, want a format error", err)
+	}
+	format = ""
+	queryCountOnly = true
+	if err := runQuery(queryCmd, []string{projectPath}); err == nil || !strings.Contains(err.Error(), "--count-only") {
+		t.Errorf("--context with --count-only = %v, want a conflict error", err)
+	}
+}
+
+func TestRunQuery_Latest(t *testing.T) {
+	tmpDir, projectDir, projectPath := setupTestProject(t, "latest-project")
+	base := time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)
+	for i, text := range []string{"older session text", "newer session text"} {
+		id := fmt.Sprintf("%d0000000-0000-0000-0000-000000000000", i+1)
+		path := filepath.Join(projectDir, id+".jsonl")
+		line := `{"uuid":"u","type":"user","timestamp":"2026-02-01T10:00:00Z","message":"` + text + `"}` + "\n"
+		if err := os.WriteFile(path, []byte(line), 0644); err != nil {
+			t.Fatal(err)
+		}
+		modified := base.Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldClaudeDir, oldLatest, oldText, oldFail, oldCount := claudeDir, queryLatest, queryText, queryFailOnEmpty, queryCountOnly
+	defer func() {
+		claudeDir, queryLatest, queryText, queryFailOnEmpty, queryCountOnly = oldClaudeDir, oldLatest, oldText, oldFail, oldCount
+	}()
+	claudeDir = tmpDir
+	queryLatest = true
+	queryFailOnEmpty = true
+	queryCountOnly = true
+
+	queryText = "newer session"
+	if err := runQuery(queryCmd, []string{projectPath}); err != nil {
+		t.Errorf("--latest should query the newer session, got %v", err)
+	}
+	queryText = "older session"
+	if err := runQuery(queryCmd, []string{projectPath}); exitCode(err) != exitNoMatches {
+		t.Errorf("--latest should not include the older session, got %v", err)
+	}
+}
+
+func TestRunQuery_LatestErrors(t *testing.T) {
+	tmpDir, _, projectPath := setupTestProject(t, "empty-project")
+
+	oldClaudeDir, oldLatest, oldSession := claudeDir, queryLatest, querySessionID
+	defer func() { claudeDir, queryLatest, querySessionID = oldClaudeDir, oldLatest, oldSession }()
+	claudeDir = tmpDir
+	queryLatest = true
+
+	if err := runQuery(queryCmd, []string{projectPath}); err == nil || !strings.Contains(err.Error(), "no sessions found") {
+		t.Errorf("--latest in an empty project = %v, want no sessions error", err)
+	}
+
+	querySessionID = "abc"
+	if err := runQuery(queryCmd, []string{projectPath}); err == nil || !strings.Contains(err.Error(), "--latest cannot be combined with --session") {
+		t.Errorf("--latest with --session = %v, want conflict error", err)
+	}
+}
+
+func TestParseToolFields(t *testing.T) {
+	fields, err := parseToolFields([]string{"command=^git (push|pull)", "options.timeout=a=b"})
+	if err != nil {
+		t.Fatalf("parseToolFields() error = %v", err)
+	}
+	want := map[string]string{"command": "^git (push|pull)", "options.timeout": "a=b"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("parseToolFields() = %v, want %v", fields, want)
+	}
+
+	if fields, err := parseToolFields(nil); err != nil || fields != nil {
+		t.Errorf("parseToolFields(nil) = %v, %v; want nil, nil", fields, err)
+	}
+
+	for _, bad := range [][]string{{"command"}, {"=git"}, {"command=("}, {"command=a", "command=b"}} {
+		if _, err := parseToolFields(bad); err == nil || !strings.Contains(err.Error(), "invalid --tool-field") {
+			t.Errorf("parseToolFields(%q) error = %v, want invalid --tool-field", bad, err)
+		}
+	}
+}
+
+func TestBuildFilterOptions_ToolFields(t *testing.T) {
+	old := queryToolFields
+	defer func() { queryToolFields = old }()
+
+	queryToolFields = []string{"command=git"}
+	opts, err := buildFilterOptions("")
+	if err != nil {
+		t.Fatalf("buildFilterOptions() error = %v", err)
+	}
+	if opts.ToolFieldMatch["command"] != "git" {
+		t.Errorf("ToolFieldMatch = %v, want command=git", opts.ToolFieldMatch)
+	}
+
+	queryToolFields = []string{"command"}
+	if _, err := buildFilterOptions(""); err == nil {
+		t.Error("buildFilterOptions() should reject a --tool-field without =")
+	}
+}
+
+func TestBuildFilterOptions_SpawnsOnly(t *testing.T) {
+	old := querySpawnsOnly
+	defer func() { querySpawnsOnly = old }()
+
+	querySpawnsOnly = true
+	opts, err := buildFilterOptions("")
+	if err != nil {
+		t.Fatalf("buildFilterOptions() error = %v", err)
+	}
+	if !opts.SpawnsOnly {
+		t.Error("SpawnsOnly should be set by --spawns-only")
+	}
+}
+
+func TestBuildFilterOptions_CwdAndBranch(t *testing.T) {
+	oldCwd, oldBranch := queryCwd, queryBranch
+	defer func() { queryCwd, queryBranch = oldCwd, oldBranch }()
+
+	queryCwd = "/work/app"
+	queryBranch = "feature/x"
+	opts, err := buildFilterOptions("")
+	if err != nil {
+		t.Fatalf("buildFilterOptions() error = %v", err)
+	}
+	if opts.Cwd != filepath.Clean("/work/app") || opts.GitBranch != "feature/x" {
+		t.Errorf("Cwd, GitBranch = %q, %q", opts.Cwd, opts.GitBranch)
+	}
+
+	// Relative directories are resolved against the current directory
+	queryCwd = "web"
+	opts, err = buildFilterOptions("")
+	if err != nil {
+		t.Fatalf("buildFilterOptions() error = %v", err)
+	}
+	wd, _ := os.Getwd()
+	if opts.Cwd != filepath.Join(wd, "web") {
+		t.Errorf("Cwd = %q, want %q", opts.Cwd, filepath.Join(wd, "web"))
+	}
+}
+
+func TestBuildFilterOptions_Turns(t *testing.T) {
+	oldUser, oldAssistant := queryUserTurn, queryAssistantTurn
+	defer func() { queryUserTurn, queryAssistantTurn = oldUser, oldAssistant }()
+
+	queryUserTurn, queryAssistantTurn = 3, 2
+	opts, err := buildFilterOptions("")
+	if err != nil {
+		t.Fatalf("buildFilterOptions() error = %v", err)
+	}
+	if opts.UserTurn != 3 || opts.AssistantTurn != 2 {
+		t.Errorf("UserTurn, AssistantTurn = %d, %d, want 3, 2", opts.UserTurn, opts.AssistantTurn)
+	}
+
+	queryUserTurn = -1
+	if _, err := buildFilterOptions(""); err == nil {
+		t.Error("buildFilterOptions() should reject a negative --user-turn")
+	}
+}
+
+func TestBuildFilterOptions_RelativeToStart(t *testing.T) {
+	oldWithin, oldSince := queryWithin, querySinceStart
+	defer func() { queryWithin, querySinceStart = oldWithin, oldSince }()
+
+	opts, err := buildFilterOptions("")
+	if err != nil {
+		t.Fatalf("buildFilterOptions() error = %v", err)
+	}
+	if opts.UntilStart != nil || opts.SinceStart != nil {
+		t.Errorf("UntilStart, SinceStart = %v, %v, want unset", opts.UntilStart, opts.SinceStart)
+	}
+
+	queryWithin, querySinceStart = 5*time.Minute, time.Minute
+	opts, err = buildFilterOptions("")
+	if err != nil {
+		t.Fatalf("buildFilterOptions() error = %v", err)
+	}
+	if opts.UntilStart == nil || *opts.UntilStart != 5*time.Minute || opts.SinceStart == nil || *opts.SinceStart != time.Minute {
+		t.Errorf("UntilStart, SinceStart = %v, %v, want 5m, 1m", opts.UntilStart, opts.SinceStart)
+	}
+
+	queryWithin = -time.Minute
+	if _, err := buildFilterOptions(""); err == nil {
+		t.Error("buildFilterOptions() should reject a negative --within")
+	}
+}
@@ -150,7 +150,7 @@ func resolveSessionGlobal(sessionID string, outputFormat output.Format) error {
 		}
 	}
 
-	return fmt.Errorf("session %s not found", sessionID)
+	return &ExitError{Code: exitSessionNotFound, Err: fmt.Errorf("session %s not found", sessionID)}
 }
 
 func outputResult(path string, format output.Format) error {
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/randlee/claude-history/pkg/resolver"
 	"github.com/spf13/cobra"
 )
 
@@ -26,25 +28,130 @@ It supports:
   - Path resolution between filesystem paths and Claude's encoded storage
   - Querying conversation history with date and type filters
   - Displaying agent hierarchy trees
-  - Listing projects and sessions`,
-	SilenceUsage: true,
+  - Listing projects and sessions
+
+Defaults for any option can be set in a config file, by default
+~/.config/claude-history/config.yaml (override with $CLAUDE_HISTORY_CONFIG).
+Top-level keys set global options and a section per command sets that
+command's options. Options given on the command line always win:
+
+  claude-dir: /data/claude
+  export:
+    format: jsonl
+  query:
+    limit: 50
+
+Use --print-config to show the options a command would run with.
+
+Exit status:
+  0  success
+  1  the command failed
+  2  nothing matched (commands run with --fail-on-empty)
+  3  the session or agent named does not exist
+  4  invalid flags, arguments, or config file`,
+	SilenceUsage:      true,
+	SilenceErrors:     true, // Execute prints them, colored like other terminal output
+	PersistentPreRunE: loadConfigDefaults,
 }
 
 // SetVersion sets the version information
 func SetVersion(version, commit, date string) {
+	buildVersion = version
 	versionInfo = fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date)
 	rootCmd.Version = versionInfo
 }
 
+// Exit statuses set by Execute, listed in the root command's help for scripts
+const (
+	exitError           = 1 // The command failed
+	exitNoMatches       = 2 // Run with --fail-on-empty and nothing matched
+	exitSessionNotFound = 3 // The session or agent named does not exist
+	exitBadInput        = 4 // Invalid flags, arguments, or config file
+)
+
+// ExitError is an error that exits the process with Code when returned by a command.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// badInput marks err as invalid input (exitBadInput). A nil err stays nil.
+func badInput(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ExitError{Code: exitBadInput, Err: err}
+}
+
+// noMatchesError is returned by commands run with --fail-on-empty when nothing matched,
+// so scripts can tell an empty result (exitNoMatches) from a failure (exitError).
+type noMatchesError string
+
+func (e noMatchesError) Error() string { return string(e) }
+
+// noMatches handles an empty result. With failOnEmpty it returns a noMatchesError
+// carrying msg; otherwise it prints msg to stderr and returns nil.
+func noMatches(msg string, failOnEmpty bool) error {
+	if failOnEmpty {
+		return noMatchesError(msg)
+	}
+	fmt.Fprintln(os.Stderr, msg)
+	return nil
+}
+
+// exitCode returns the process exit status for an error returned by a command: the
+// code of an ExitError, exitNoMatches for a noMatchesError, exitSessionNotFound for a
+// failed session or agent lookup, and exitError otherwise.
+func exitCode(err error) int {
+	var ee *ExitError
+	if errors.As(err, &ee) {
+		return ee.Code
+	}
+	var nm noMatchesError
+	if errors.As(err, &nm) {
+		return exitNoMatches
+	}
+	if errors.Is(err, resolver.ErrSessionNotFound) || errors.Is(err, resolver.ErrAgentNotFound) {
+		return exitSessionNotFound
+	}
+	return exitError
+}
+
+// markBadInput makes the flag and argument errors of cmd and its subcommands exit with
+// exitBadInput.
+func markBadInput(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return badInput(err)
+	})
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			return badInput(validate(cmd, args))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markBadInput(sub)
+	}
+}
+
 // Execute runs the root command
 func Execute() {
+	markBadInput(rootCmd)
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		if errors.Is(err, errConfigPrinted) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, colorizer(os.Stderr).Error("Error: "+err.Error()))
+		os.Exit(exitCode(err))
 	}
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&claudeDir, "claude-dir", "", "Custom ~/.claude directory location")
-	rootCmd.PersistentFlags().StringVar(&format, "format", "", "Output format (json, path, list, summary, ascii, dot)")
+	rootCmd.PersistentFlags().StringVar(&format, "format", "", "Output format (json, jsonl, path, list, summary, ascii, dot, html, markdown, prom)")
+	rootCmd.PersistentFlags().BoolVar(&printConfig, "print-config", false, "Print the effective options (config file merged with flags) and exit")
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", colorAuto, "Color terminal output: auto (only on a terminal), always, never")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (same as --color never; NO_COLOR is also honored)")
 }
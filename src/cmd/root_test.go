@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/resolver"
+	"github.com/spf13/cobra"
+)
+
+func TestExitCode_Taxonomy(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"exit error", &ExitError{Code: exitBadInput, Err: errors.New("bad")}, exitBadInput},
+		{"wrapped exit error", fmt.Errorf("context: %w", badInput(errors.New("bad"))), exitBadInput},
+		{"session not found", fmt.Errorf("failed to resolve session ID: %w", resolver.ErrSessionNotFound), exitSessionNotFound},
+		{"agent not found", fmt.Errorf("%w: a12eb64", resolver.ErrAgentNotFound), exitSessionNotFound},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("%s: exitCode(%v) = %d, want %d", tt.name, tt.err, got, tt.want)
+		}
+	}
+	if badInput(nil) != nil {
+		t.Error("badInput(nil) should be nil")
+	}
+}
+
+func TestMarkBadInput(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	sub := &cobra.Command{Use: "sub", Args: cobra.ExactArgs(1), RunE: func(*cobra.Command, []string) error { return nil }}
+	sub.Flags().Int("count", 0, "")
+	root.AddCommand(sub)
+	markBadInput(root)
+
+	for _, args := range [][]string{{"sub"}, {"sub", "x", "--count", "many"}} {
+		root.SetArgs(args)
+		root.SilenceErrors, root.SilenceUsage = true, true
+		err := root.Execute()
+		if got := exitCode(err); got != exitBadInput {
+			t.Errorf("%v: exitCode(%v) = %d, want %d", args, err, got, exitBadInput)
+		}
+	}
+}
+
+func TestRootHelp_DocumentsExitStatus(t *testing.T) {
+	for _, want := range []string{"Exit status:", "3  the session or agent named does not exist", "4  invalid flags"} {
+		if !strings.Contains(rootCmd.Long, want) {
+			t.Errorf("root help should document %q", want)
+		}
+	}
+}
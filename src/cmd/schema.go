@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/randlee/claude-history/pkg/export"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for the JSON export format",
+	Long: `Print a JSON Schema (draft 2020-12) document describing the output of
+'claude-history export --format json'.
+
+The schema's format_version matches the format_version field of every
+JSON export, so downstream tools can validate exports against it.
+
+Examples:
+  # Save the schema for validation
+  claude-history schema > claude-history-export.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: runSchema,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	if _, err := os.Stdout.Write(export.ExportJSONSchema()); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	return nil
+}
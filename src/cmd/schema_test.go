@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestRunSchema_PrintsValidJSON(t *testing.T) {
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+
+	runErr := runSchema(schemaCmd, nil)
+	_ = w.Close()
+	os.Stdout = oldStdout
+
+	if runErr != nil {
+		t.Fatalf("runSchema() error = %v", runErr)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(out, &schema); err != nil {
+		t.Fatalf("schema output is not valid JSON: %v", err)
+	}
+	if schema["$schema"] == nil || schema["$id"] == nil {
+		t.Error("schema output should declare $schema and $id")
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/randlee/claude-history/pkg/paths"
+	"github.com/randlee/claude-history/pkg/resolver"
+	"github.com/randlee/claude-history/pkg/session"
+)
+
+// scriptOutputDelimiter starts the heredoc delimiter around recorded output. It is
+// lengthened when the output contains it as a line, so the heredoc cannot end early.
+const scriptOutputDelimiter = "CLAUDE_HISTORY_OUTPUT"
+
+var (
+	scriptSessionID   string
+	scriptWithOutput  bool
+	scriptOutputFile  string
+	scriptFailOnEmpty bool
+)
+
+var scriptCmd = &cobra.Command{
+	Use:   "script <project-path>",
+	Short: "Write a session's Bash commands as a shell script",
+	Long: `Write the commands a session ran with the Bash tool, in order, as a shell
+script for reproducing the session.
+
+Each command is preceded by a comment with the UUID and timestamp of the message
+that ran it, and its description if it had one. Other tools are left out. With
+--with-output, the output recorded for each command follows it in a heredoc
+passed to ":" (a no-op), so the script still only runs the commands.
+
+The script does not stop on the first failing command; commands that failed in
+the session are marked. Review the script before running it: it repeats
+whatever the session did, including deleting files.
+
+Examples:
+  # Print the Bash commands of the most recent session
+  claude-history script /path/to/project
+
+  # A specific session, with the output each command produced
+  claude-history script /path/to/project --session abc123 --with-output
+
+  # Save as an executable script
+  claude-history script /path/to/project --session abc123 -o replay.sh`,
+	Args: cobra.ExactArgs(1),
+	RunE: runScript,
+}
+
+func init() {
+	rootCmd.AddCommand(scriptCmd)
+
+	scriptCmd.Flags().StringVar(&scriptSessionID, "session", "", "Session ID (default: most recent session)")
+	scriptCmd.Flags().BoolVar(&scriptWithOutput, "with-output", false, "Include each command's recorded output as a heredoc comment")
+	scriptCmd.Flags().StringVarP(&scriptOutputFile, "output", "o", "", "Write the script to this file (made executable) instead of stdout")
+	scriptCmd.Flags().BoolVar(&scriptFailOnEmpty, "fail-on-empty", false, "Exit with status 2 if the session ran no Bash commands")
+}
+
+func runScript(cmd *cobra.Command, args []string) error {
+	projectPath := args[0]
+	projectDir, err := paths.ProjectDir(claudeDir, projectPath)
+	if err != nil {
+		return err
+	}
+	if !paths.Exists(projectDir) {
+		return fmt.Errorf("project not found: %s", projectPath)
+	}
+
+	sessionID := scriptSessionID
+	if sessionID == "" {
+		sessions, err := session.ListSessions(projectDir)
+		if err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			return fmt.Errorf("no sessions found in project")
+		}
+		sessionID = sessions[0].ID
+	} else {
+		resolvedSessionID, err := resolver.ResolveSessionID(projectDir, sessionID)
+		if err != nil {
+			return fmt.Errorf("failed to resolve session ID: %w", err)
+		}
+		sessionID = resolvedSessionID
+	}
+
+	entries, err := session.ReadSession(paths.JSONLFile(filepath.Join(projectDir, sessionID)))
+	if err != nil {
+		return fmt.Errorf("failed to read session: %w", err)
+	}
+
+	commands := session.ExtractBashCommands(entries)
+	if len(commands) == 0 {
+		return noMatches("No Bash commands found", scriptFailOnEmpty)
+	}
+
+	if scriptOutputFile == "" {
+		return writeScript(cmd.OutOrStdout(), sessionID, commands, scriptWithOutput)
+	}
+
+	f, err := os.OpenFile(scriptOutputFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0750) //nolint:gosec // G304: output path from CLI input is expected
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", scriptOutputFile, err)
+	}
+	if err := writeScript(f, sessionID, commands, scriptWithOutput); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s to %s\n", commandCount(len(commands)), scriptOutputFile)
+	return nil
+}
+
+// writeScript writes commands as a bash script: a header naming the session, then each
+// command after a comment with its message UUID and timestamp. withOutput adds the
+// recorded output of each command (see writeScriptOutput).
+func writeScript(w io.Writer, sessionID string, commands []session.BashCommand, withOutput bool) error {
+	var sb strings.Builder
+	sb.WriteString("#!/usr/bin/env bash\n")
+	sb.WriteString(fmt.Sprintf("# Bash commands from Claude Code session %s (%s)\n", sessionID, commandCount(len(commands))))
+	sb.WriteString("# Generated by claude-history script. Review before running.\n")
+
+	for i, c := range commands {
+		sb.WriteString(fmt.Sprintf("\n# [%d] message %s at %s\n", i+1, c.EntryUUID, c.Timestamp))
+		if c.Description != "" {
+			sb.WriteString("# " + strings.Join(strings.Fields(c.Description), " ") + "\n")
+		}
+		if c.IsError {
+			sb.WriteString("# (failed in the session)\n")
+		}
+		sb.WriteString(strings.TrimRight(c.Command, "\n") + "\n")
+		if withOutput {
+			writeScriptOutput(&sb, c)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// commandCount returns "1 command" or "n commands".
+func commandCount(n int) string {
+	if n == 1 {
+		return "1 command"
+	}
+	return fmt.Sprintf("%d commands", n)
+}
+
+// writeScriptOutput writes a command's recorded output as a quoted heredoc passed to
+// ":", which bash neither expands nor runs. Commands without a recorded result say so.
+func writeScriptOutput(sb *strings.Builder, c session.BashCommand) {
+	if !c.HasResult {
+		sb.WriteString("# (no output recorded)\n")
+		return
+	}
+	output := strings.TrimRight(c.Output, "\n")
+
+	delimiter := scriptOutputDelimiter
+	for containsLine(output, delimiter) {
+		delimiter += "_"
+	}
+	sb.WriteString(fmt.Sprintf(": <<'%s'\n", delimiter))
+	if output != "" {
+		sb.WriteString(output + "\n")
+	}
+	sb.WriteString(delimiter + "\n")
+}
+
+// containsLine reports whether line is one of the lines of s.
+func containsLine(s, line string) bool {
+	for _, l := range strings.Split(s, "\n") {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/session"
+)
+
+// createScriptTestProject writes a project with one session that ran two Bash commands
+// (the first failing) and a Read, and returns the claude dir.
+func createScriptTestProject(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "projects", "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `{"uuid":"u1","type":"user","timestamp":"2026-02-01T10:00:00.000Z","message":"Build it"}
+{"uuid":"a1","type":"assistant","timestamp":"2026-02-01T10:00:05.000Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"make build","description":"Build the\nproject"}}]}}
+{"uuid":"r1","type":"user","timestamp":"2026-02-01T10:00:06.000Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"make: *** No rule","is_error":true}]}}
+{"uuid":"a2","type":"assistant","timestamp":"2026-02-01T10:01:00.000Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"/Makefile"}},{"type":"tool_use","id":"t3","name":"Bash","input":{"command":"go build ./..."}}]}}
+{"uuid":"r2","type":"user","timestamp":"2026-02-01T10:01:01.000Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t3","content":"ok\n"}]}}
+`
+	if err := os.WriteFile(filepath.Join(projectDir, "5c0e0000-0000-0000-0000-000000000001.jsonl"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return tmpDir
+}
+
+// saveScriptFlags restores the script command flags when the test ends.
+func saveScriptFlags(t *testing.T) {
+	t.Helper()
+	oldClaudeDir, oldSession, oldWith, oldOut, oldFail := claudeDir, scriptSessionID, scriptWithOutput, scriptOutputFile, scriptFailOnEmpty
+	t.Cleanup(func() {
+		claudeDir, scriptSessionID, scriptWithOutput, scriptOutputFile, scriptFailOnEmpty = oldClaudeDir, oldSession, oldWith, oldOut, oldFail
+		scriptCmd.SetOut(nil)
+	})
+}
+
+// runScriptOutput runs the script command on the test project and returns its output.
+func runScriptOutput(t *testing.T) string {
+	t.Helper()
+	var buf bytes.Buffer
+	scriptCmd.SetOut(&buf)
+	if err := runScript(scriptCmd, []string{"/test/project"}); err != nil {
+		t.Fatalf("runScript() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestRunScript(t *testing.T) {
+	saveScriptFlags(t)
+	claudeDir = createScriptTestProject(t)
+	scriptSessionID, scriptWithOutput, scriptOutputFile = "5c0e", false, ""
+
+	got := runScriptOutput(t)
+	want := `#!/usr/bin/env bash
+# Bash commands from Claude Code session 5c0e0000-0000-0000-0000-000000000001 (2 commands)
+# Generated by claude-history script. Review before running.
+
+# [1] message a1 at 2026-02-01T10:00:05.000Z
+# Build the project
+# (failed in the session)
+make build
+
+# [2] message a2 at 2026-02-01T10:01:00.000Z
+go build ./...
+`
+	if got != want {
+		t.Errorf("script =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRunScript_WithOutput(t *testing.T) {
+	saveScriptFlags(t)
+	claudeDir = createScriptTestProject(t)
+	scriptSessionID, scriptWithOutput, scriptOutputFile = "", true, ""
+
+	got := runScriptOutput(t)
+	for _, want := range []string{
+		"make build\n: <<'CLAUDE_HISTORY_OUTPUT'\nmake: *** No rule\nCLAUDE_HISTORY_OUTPUT\n",
+		"go build ./...\n: <<'CLAUDE_HISTORY_OUTPUT'\nok\nCLAUDE_HISTORY_OUTPUT\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("script missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRunScript_OutputFile(t *testing.T) {
+	saveScriptFlags(t)
+	claudeDir = createScriptTestProject(t)
+	scriptSessionID, scriptWithOutput = "", false
+	scriptOutputFile = filepath.Join(t.TempDir(), "replay.sh")
+
+	if err := runScript(scriptCmd, []string{"/test/project"}); err != nil {
+		t.Fatalf("runScript() error = %v", err)
+	}
+	data, err := os.ReadFile(scriptOutputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "#!/usr/bin/env bash\n") || !strings.Contains(string(data), "go build ./...\n") {
+		t.Errorf("script file = %q", data)
+	}
+	info, err := os.Stat(scriptOutputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Errorf("script file mode = %v, want executable", info.Mode())
+	}
+}
+
+func TestRunScript_NoCommands(t *testing.T) {
+	saveScriptFlags(t)
+	claudeDir = createPromptsTestProject(t)
+	scriptSessionID, scriptOutputFile, scriptFailOnEmpty = "aaaa", "", true
+
+	if err := runScript(scriptCmd, []string{"/test/project"}); exitCode(err) != exitNoMatches {
+		t.Errorf("session without Bash commands = %v, want no-matches exit", err)
+	}
+}
+
+func TestWriteScriptOutput_DelimiterInOutput(t *testing.T) {
+	var sb strings.Builder
+	writeScriptOutput(&sb, session.BashCommand{HasResult: true, Output: "a\nCLAUDE_HISTORY_OUTPUT\nb"})
+	want := ": <<'CLAUDE_HISTORY_OUTPUT_'\na\nCLAUDE_HISTORY_OUTPUT\nb\nCLAUDE_HISTORY_OUTPUT_\n"
+	if sb.String() != want {
+		t.Errorf("writeScriptOutput() = %q, want %q", sb.String(), want)
+	}
+
+	sb.Reset()
+	writeScriptOutput(&sb, session.BashCommand{})
+	if sb.String() != "# (no output recorded)\n" {
+		t.Errorf("without a result = %q", sb.String())
+	}
+}
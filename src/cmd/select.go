@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+	"github.com/randlee/claude-history/pkg/session"
+)
+
+// selectLabelLen is the number of characters of a session's summary or first prompt
+// shown in the picker.
+const selectLabelLen = 70
+
+// selectSession lists the sessions in projectDir, most recently modified first (the
+// ListSessions order), and returns the ID of the one chosen on in. The menu and prompts
+// go to out. When interactive, a line that is not a number narrows the list to the
+// sessions it fuzzy-matches (see fuzzyMatch) and an empty line resets it; otherwise the
+// menu is printed once and the first line must be a number from it.
+func selectSession(projectDir string, in io.Reader, out io.Writer, interactive bool) (string, error) {
+	sessions, err := session.ListSessions(projectDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to list sessions: %w", err)
+	}
+	if len(sessions) == 0 {
+		return "", fmt.Errorf("no sessions found in %s", projectDir)
+	}
+	return pickSession(sessions, in, out, interactive)
+}
+
+// pickSession runs the picker of selectSession over sessions, kept in the order given.
+func pickSession(sessions []models.Session, in io.Reader, out io.Writer, interactive bool) (string, error) {
+	scanner := bufio.NewScanner(in)
+	shown := sessions
+	for {
+		writeSessionMenu(out, shown)
+		if interactive {
+			fmt.Fprint(out, "Select a session by number, or type to filter: ")
+		} else {
+			fmt.Fprint(out, "Select a session by number: ")
+		}
+
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", fmt.Errorf("failed to read selection: %w", err)
+			}
+			return "", badInput(fmt.Errorf("no session selected"))
+		}
+		line := strings.TrimSpace(scanner.Text())
+
+		if n, err := strconv.Atoi(line); err == nil {
+			if n >= 1 && n <= len(shown) {
+				return shown[n-1].ID, nil
+			}
+			if !interactive {
+				return "", badInput(fmt.Errorf("invalid selection %d: choose 1-%d", n, len(shown)))
+			}
+			fmt.Fprintf(out, "No session %d; choose 1-%d\n", n, len(shown))
+			continue
+		}
+		if !interactive {
+			return "", badInput(fmt.Errorf("invalid selection %q: enter a number", line))
+		}
+
+		if line == "" {
+			shown = sessions
+			continue
+		}
+		var matches []models.Session
+		for _, s := range sessions {
+			if fuzzyMatch(line, s.ID+" "+sessionLabel(s)) {
+				matches = append(matches, s)
+			}
+		}
+		if len(matches) == 0 {
+			fmt.Fprintf(out, "No sessions match %q\n", line)
+			continue
+		}
+		shown = matches
+	}
+}
+
+// writeSessionMenu writes one numbered line per session: its short ID, modification
+// time, and summary or first prompt.
+func writeSessionMenu(w io.Writer, sessions []models.Session) {
+	width := len(strconv.Itoa(len(sessions)))
+	for i, s := range sessions {
+		id := s.ID
+		if len(id) > 8 {
+			id = id[:8]
+		}
+		fmt.Fprintf(w, "%*d) %s  %s  %s\n", width, i+1, id, s.Modified.Local().Format("2006-01-02 15:04"),
+			truncateString(sessionLabel(s), selectLabelLen))
+	}
+}
+
+// sessionLabel returns a session's summary, or its first prompt when it has none, on a
+// single line.
+func sessionLabel(s models.Session) string {
+	label := s.Summary
+	if label == "" {
+		label = s.FirstPrompt
+	}
+	return strings.Join(strings.Fields(label), " ")
+}
+
+// fuzzyMatch reports whether the characters of pattern appear in text in order, ignoring
+// case and the spaces in pattern, so "oauth fl" matches "Fix the OAuth flow".
+func fuzzyMatch(pattern, text string) bool {
+	text = strings.ToLower(text)
+	for _, r := range strings.ToLower(pattern) {
+		if r == ' ' {
+			continue
+		}
+		i := strings.IndexRune(text, r)
+		if i < 0 {
+			return false
+		}
+		text = text[i+len(string(r)):]
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func selectTestSessions() []models.Session {
+	now := time.Now()
+	return []models.Session{
+		{ID: "aaaa1111-0000", Summary: "Fix the OAuth flow", Modified: now},
+		{ID: "bbbb2222-0000", FirstPrompt: "Add a\ndark mode", Modified: now.Add(-time.Hour)},
+		{ID: "cccc3333-0000", Summary: "Refactor the parser", Modified: now.Add(-2 * time.Hour)},
+	}
+}
+
+func TestPickSession_Number(t *testing.T) {
+	var out bytes.Buffer
+	id, err := pickSession(selectTestSessions(), strings.NewReader("2\n"), &out, false)
+	if err != nil || id != "bbbb2222-0000" {
+		t.Fatalf("pickSession() = %q, %v", id, err)
+	}
+	menu := out.String()
+	if !strings.Contains(menu, "1) aaaa1111") || !strings.Contains(menu, "2) bbbb2222") || !strings.Contains(menu, "Add a dark mode") {
+		t.Errorf("menu should number the sessions with their labels on one line, got:\n%s", menu)
+	}
+	if strings.Index(menu, "aaaa1111") > strings.Index(menu, "cccc3333") {
+		t.Errorf("menu should keep the given (latest-first) order, got:\n%s", menu)
+	}
+}
+
+func TestPickSession_NonInteractiveRejectsText(t *testing.T) {
+	for _, input := range []string{"oauth\n", "7\n", ""} {
+		_, err := pickSession(selectTestSessions(), strings.NewReader(input), &bytes.Buffer{}, false)
+		var exitErr *ExitError
+		if err == nil || !errors.As(err, &exitErr) || exitErr.Code != exitBadInput {
+			t.Errorf("pickSession(%q) error = %v, want bad input", input, err)
+		}
+	}
+}
+
+func TestPickSession_InteractiveFilter(t *testing.T) {
+	// A bad number and a filter without matches are reported and asked again;
+	// after filtering, numbers refer to the narrowed list
+	var out bytes.Buffer
+	id, err := pickSession(selectTestSessions(), strings.NewReader("9\nzzz\nparser\n1\n"), &out, true)
+	if err != nil || id != "cccc3333-0000" {
+		t.Fatalf("pickSession() = %q, %v", id, err)
+	}
+	if !strings.Contains(out.String(), "No session 9") || !strings.Contains(out.String(), `No sessions match "zzz"`) {
+		t.Errorf("expected retry messages, got:\n%s", out.String())
+	}
+
+	// An empty line shows every session again
+	id, err = pickSession(selectTestSessions(), strings.NewReader("parser\n\n1\n"), &bytes.Buffer{}, true)
+	if err != nil || id != "aaaa1111-0000" {
+		t.Errorf("pickSession() after reset = %q, %v", id, err)
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		want          bool
+	}{
+		{"oauth", "Fix the OAuth flow", true},
+		{"oauth fl", "Fix the OAuth flow", true},
+		{"fxoa", "Fix the OAuth flow", true},
+		{"flow oauth", "Fix the OAuth flow", false},
+		{"bbbb", "bbbb2222-0000 Add a dark mode", true},
+	}
+	for _, tt := range tests {
+		if got := fuzzyMatch(tt.pattern, tt.text); got != tt.want {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.pattern, tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/randlee/claude-history/pkg/export"
+	"github.com/randlee/claude-history/pkg/paths"
+	"github.com/randlee/claude-history/pkg/server"
+)
+
+var (
+	serveAddr          string
+	serveRelativeTimes bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve [project-path]",
+	Short: "Browse sessions in a local web server",
+	Long: `Run a local HTTP server that lists a project's sessions and renders
+each one on demand, without writing export files.
+
+Subagent sections are lazy-loaded from the server, just like in an HTML export.
+The server binds to localhost by default; pass --addr to listen elsewhere.
+
+Examples:
+  # Browse sessions for the current directory at http://127.0.0.1:8080
+  claude-history serve
+
+  # Browse a specific project on another port
+  claude-history serve /path/to/project --addr 127.0.0.1:9000`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", server.DefaultAddr, "Address to listen on")
+	serveCmd.Flags().BoolVar(&serveRelativeTimes, "relative-times", false, "Show relative message times (absolute time on hover)")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	// Get project path (default to current directory)
+	projectPath := "."
+	if len(args) > 0 {
+		projectPath = args[0]
+	}
+
+	if !filepath.IsAbs(projectPath) {
+		absPath, err := filepath.Abs(projectPath)
+		if err != nil {
+			return fmt.Errorf("failed to resolve project path: %w", err)
+		}
+		projectPath = absPath
+	}
+
+	projectDir, err := paths.ProjectDir(claudeDir, projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+	if !paths.Exists(projectDir) {
+		return fmt.Errorf("project not found: %s", projectPath)
+	}
+
+	handler := server.NewHandler(projectDir, projectPath, export.ExportOptions{
+		RelativeTimes: serveRelativeTimes,
+		SummaryMaxLen: export.DefaultSummaryMaxLen,
+	})
+
+	srv := &http.Server{
+		Addr:              serveAddr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Fprintf(os.Stderr, "Serving %s at http://%s/ (Ctrl+C to stop)\n", projectPath, serveAddr)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	return nil
+}
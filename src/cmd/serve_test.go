@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestServeCmd_Flags(t *testing.T) {
+	addr := serveCmd.Flags().Lookup("addr")
+	if addr == nil {
+		t.Fatal("serve command should have --addr flag")
+	}
+	if !strings.HasPrefix(addr.DefValue, "127.0.0.1:") {
+		t.Errorf("--addr default = %q, should bind to localhost", addr.DefValue)
+	}
+}
+
+func TestServeCmd_MissingProject(t *testing.T) {
+	oldClaudeDir := claudeDir
+	defer func() { claudeDir = oldClaudeDir }()
+	claudeDir = t.TempDir()
+
+	err := runServe(serveCmd, []string{"/nonexistent/project"})
+	if err == nil || !strings.Contains(err.Error(), "project not found") {
+		t.Errorf("expected project not found error, got %v", err)
+	}
+}
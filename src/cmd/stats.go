@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/randlee/claude-history/internal/output"
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/export"
+	"github.com/randlee/claude-history/pkg/paths"
+	"github.com/randlee/claude-history/pkg/resolver"
+	"github.com/randlee/claude-history/pkg/session"
+)
+
+var (
+	statsSessionID string
+	statsAll       bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats <project-path>",
+	Short: "Show session statistics",
+	Long: `Show the statistics of a session: message counts by role, tool calls,
+duration, and subagent count, as in the header of an export. A session with
+subagents also gets its totals including subagents: the entries and tool calls of
+every nested agent, and the time from the first to the last entry of any of them.
+
+Without --session the most recent session is used; --all reports every session
+in the project, most recently modified first.
+
+With --format prom the statistics are written as Prometheus text-format gauges
+labeled with the session ID and project path. With --all each metric family is
+written once with a sample per session, ready to be served to a scraper (for
+example through node_exporter's textfile collector).
+
+Examples:
+  # Statistics of the most recent session
+  claude-history stats /path/to/project
+
+  # Statistics of a specific session as JSON
+  claude-history stats /path/to/project --session 679761ba --format json
+
+  # Metrics for every session, for a dashboard
+  claude-history stats /path/to/project --all --format prom > claude_history.prom`,
+	Args: cobra.ExactArgs(1),
+	RunE: runStats,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVar(&statsSessionID, "session", "", "Session ID or prefix (default: most recent session)")
+	statsCmd.Flags().BoolVar(&statsAll, "all", false, "Report every session in the project")
+}
+
+// sessionStatsJSON is one session in the JSON output of the stats command.
+type sessionStatsJSON struct {
+	SessionID          string           `json:"session_id"`
+	ProjectPath        string           `json:"project_path"`
+	Stats              export.JSONStats `json:"stats"`
+	IncludingSubagents treeTotalJSON    `json:"including_subagents"`
+}
+
+// treeTotalJSON is the total of a session and its subagents (see agent.AggregateTree)
+// in the JSON output of the stats command.
+type treeTotalJSON struct {
+	Agents    int    `json:"agents"`
+	Entries   int    `json:"entries"`
+	ToolCalls int    `json:"tool_calls"`
+	Span      string `json:"span,omitempty"`
+}
+
+// sessionReport is the statistics of one session reported by the stats command.
+type sessionReport struct {
+	stats *export.SessionStats
+	total agent.TreeAggregate // The session with all its subagents
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	projectPath := args[0]
+	outputFormat := output.ParseFormat(format)
+
+	if statsAll && statsSessionID != "" {
+		return fmt.Errorf("--all cannot be combined with --session")
+	}
+
+	// Get the project directory
+	projectDir, err := paths.ProjectDir(claudeDir, projectPath)
+	if err != nil {
+		return err
+	}
+
+	if !paths.Exists(projectDir) {
+		return fmt.Errorf("project not found: %s", projectPath)
+	}
+
+	// Pick the sessions to report
+	var sessionIDs []string
+	switch {
+	case statsSessionID != "":
+		resolvedSessionID, err := resolver.ResolveSessionID(projectDir, statsSessionID)
+		if err != nil {
+			return fmt.Errorf("failed to resolve session ID: %w", err)
+		}
+		sessionIDs = []string{resolvedSessionID}
+	default:
+		sessions, err := session.ListSessions(projectDir)
+		if err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			return fmt.Errorf("no sessions found in project")
+		}
+		if !statsAll {
+			sessions = sessions[:1]
+		}
+		for _, s := range sessions {
+			sessionIDs = append(sessionIDs, s.ID)
+		}
+	}
+
+	// Sessions are read concurrently; results keep the order of sessionIDs
+	found := make([]sessionReport, len(sessionIDs))
+	errs := session.ReadConcurrently(len(sessionIDs), func(i int) error {
+		report, err := sessionStats(projectPath, projectDir, sessionIDs[i])
+		found[i] = report
+		return err
+	})
+	if len(sessionIDs) == 1 && errs != nil {
+		return errs[0]
+	}
+
+	// With --all, a session that cannot be read is reported without dropping the rest
+	reports := make([]sessionReport, 0, len(found))
+	for i, report := range found {
+		if errs != nil && errs[i] != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: skipping session: %v\n", errs[i])
+			continue
+		}
+		reports = append(reports, report)
+	}
+	if len(reports) == 0 {
+		return fmt.Errorf("no session could be read")
+	}
+
+	return writeStats(cmd.OutOrStdout(), reports, outputFormat)
+}
+
+// sessionStats computes the statistics of a session and its subagents, labeled with
+// the session's ID (the ID of its file, even for a resumed session) and projectPath,
+// and the session's totals including subagents.
+func sessionStats(projectPath, projectDir, sessionID string) (sessionReport, error) {
+	filePath := paths.JSONLFile(filepath.Join(projectDir, sessionID))
+	if !paths.Exists(filePath) {
+		return sessionReport{}, fmt.Errorf("%w: no file %s", resolver.ErrSessionNotFound, filePath)
+	}
+	entries, err := session.ReadSession(filePath)
+	if err != nil {
+		return sessionReport{}, fmt.Errorf("failed to read session %s: %w", truncateAgentID(sessionID), err)
+	}
+
+	tree, err := agent.BuildNestedTree(projectDir, sessionID)
+	if err != nil {
+		return sessionReport{}, fmt.Errorf("failed to build agent tree: %w", err)
+	}
+	grouped, err := agent.GroupByAgent(projectDir, sessionID)
+	if err != nil {
+		return sessionReport{}, fmt.Errorf("failed to read agents of session %s: %w", truncateAgentID(sessionID), err)
+	}
+	var agentNodes []*agent.TreeNode
+	if tree != nil {
+		agentNodes = tree.Children
+	}
+
+	stats := export.ComputeSessionStats(entries, agentNodes)
+	stats.SessionID = sessionID
+	stats.ProjectPath = projectPath
+	return sessionReport{stats: stats, total: agent.AggregateTree(tree, grouped)}, nil
+}
+
+// writeStats writes session statistics as Prometheus metrics, JSON, or a text block
+// per session. The Prometheus metrics leave out the totals including subagents.
+func writeStats(w io.Writer, reports []sessionReport, format output.Format) error {
+	switch format {
+	case output.FormatProm:
+		allStats := make([]*export.SessionStats, 0, len(reports))
+		for _, report := range reports {
+			allStats = append(allStats, report.stats)
+		}
+		return export.WritePrometheusStats(w, allStats)
+	case output.FormatJSON:
+		docs := make([]sessionStatsJSON, 0, len(reports))
+		for _, report := range reports {
+			stats := report.stats
+			docs = append(docs, sessionStatsJSON{
+				SessionID:          stats.SessionID,
+				ProjectPath:        stats.ProjectPath,
+				Stats:              export.NewJSONStats(stats),
+				IncludingSubagents: newTreeTotalJSON(report.total),
+			})
+		}
+		return output.WriteJSON(w, docs)
+	}
+
+	for i, report := range reports {
+		stats := report.stats
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Session %s\n", stats.SessionID)
+		fmt.Fprintf(w, "  Messages: %d user, %d assistant, %d subagent\n", stats.UserMessages, stats.AssistantMessages, stats.TotalAgentMessages)
+		fmt.Fprintf(w, "  Tool calls: %d\n", stats.ToolCallCount)
+		if stats.Duration != "" {
+			fmt.Fprintf(w, "  Duration: %s\n", stats.Duration)
+		}
+		fmt.Fprintf(w, "  Agents: %d\n", stats.AgentCount)
+		if total := report.total; total.Nodes > 1 {
+			fmt.Fprintf(w, "  Including subagents: %d entries, %d tool calls", total.Entries, total.ToolCalls)
+			if span := total.Span(); span > 0 {
+				fmt.Fprintf(w, ", %s span", span.Round(time.Second))
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	return nil
+}
+
+// newTreeTotalJSON converts a session's totals including subagents to their JSON form.
+func newTreeTotalJSON(total agent.TreeAggregate) treeTotalJSON {
+	doc := treeTotalJSON{Agents: total.Nodes - 1, Entries: total.Entries, ToolCalls: total.ToolCalls}
+	if span := total.Span(); span > 0 {
+		doc.Span = span.Round(time.Second).String()
+	}
+	return doc
+}
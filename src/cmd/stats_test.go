@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// saveStatsFlags restores the stats command flags when the test ends.
+func saveStatsFlags(t *testing.T) {
+	t.Helper()
+	oldClaudeDir, oldFormat, oldSession, oldAll := claudeDir, format, statsSessionID, statsAll
+	t.Cleanup(func() {
+		claudeDir, format, statsSessionID, statsAll = oldClaudeDir, oldFormat, oldSession, oldAll
+		statsCmd.SetOut(nil)
+	})
+}
+
+// setupStatsProject creates a project with a session with two subagents and a second
+// session without any, and points claudeDir at it.
+func setupStatsProject(t *testing.T) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "projects", "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	createTestSessionWithAgents(t, projectDir, 2)
+	other := `{"type":"user","timestamp":"2026-02-02T10:00:00Z","sessionId":"87654321-0000-0000-0000-000000000000","uuid":"o-1","message":"Say \"hi\""}
+{"type":"assistant","timestamp":"2026-02-02T10:00:30Z","sessionId":"87654321-0000-0000-0000-000000000000","uuid":"o-2","message":[{"type":"text","text":"hi"}]}
+`
+	if err := os.WriteFile(filepath.Join(projectDir, "87654321-0000-0000-0000-000000000000.jsonl"), []byte(other), 0644); err != nil {
+		t.Fatal(err)
+	}
+	claudeDir = tmpDir
+}
+
+func runStatsOutput(t *testing.T) string {
+	t.Helper()
+	var buf bytes.Buffer
+	statsCmd.SetOut(&buf)
+	if err := runStats(statsCmd, []string{"/test/project"}); err != nil {
+		t.Fatalf("runStats() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestRunStats_Text(t *testing.T) {
+	saveStatsFlags(t)
+	setupStatsProject(t)
+	format, statsSessionID, statsAll = "", "8765", false
+
+	got := runStatsOutput(t)
+	for _, want := range []string{"Session 87654321-0000-0000-0000-000000000000\n", "Messages: 1 user, 1 assistant, 0 subagent\n", "Duration: 30s\n", "Agents: 0\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("stats output should contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Including subagents") {
+		t.Errorf("a session without subagents should have no total, got:\n%s", got)
+	}
+
+	statsSessionID = "12345678"
+	if got := runStatsOutput(t); !strings.Contains(got, "  Including subagents: ") {
+		t.Errorf("a session with subagents should show its total, got:\n%s", got)
+	}
+}
+
+func TestRunStats_JSON(t *testing.T) {
+	saveStatsFlags(t)
+	setupStatsProject(t)
+	format, statsSessionID, statsAll = "json", "12345678", false
+
+	var docs []sessionStatsJSON
+	if err := json.Unmarshal([]byte(runStatsOutput(t)), &docs); err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].SessionID != "12345678-1234-1234-1234-123456789abc" || docs[0].Stats.AgentCount != 2 {
+		t.Fatalf("stats JSON = %+v", docs)
+	}
+	total := docs[0].IncludingSubagents
+	if total.Agents != 2 || total.ToolCalls != docs[0].Stats.ToolCalls || total.Entries <= docs[0].Stats.UserMessages+docs[0].Stats.AssistantMessages {
+		t.Errorf("including_subagents = %+v, want the session's counts plus its 2 agents", total)
+	}
+}
+
+func TestRunStats_PromAll(t *testing.T) {
+	saveStatsFlags(t)
+	setupStatsProject(t)
+	format, statsSessionID, statsAll = "prom", "", true
+
+	got := runStatsOutput(t)
+	// One family header across both sessions, with a sample per session
+	if n := strings.Count(got, "# TYPE claude_history_session_agents gauge\n"); n != 1 {
+		t.Errorf("expected one agents family, got %d in:\n%s", n, got)
+	}
+	for _, want := range []string{
+		`claude_history_session_agents{session="12345678-1234-1234-1234-123456789abc",project="/test/project"} 2`,
+		`claude_history_session_agents{session="87654321-0000-0000-0000-000000000000",project="/test/project"} 0`,
+		`claude_history_session_duration_seconds{session="87654321-0000-0000-0000-000000000000",project="/test/project"} 30`,
+	} {
+		if !strings.Contains(got, want+"\n") {
+			t.Errorf("metrics should contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestRunStats_AllWithSession(t *testing.T) {
+	saveStatsFlags(t)
+	statsSessionID, statsAll = "abc", true
+
+	if err := runStats(statsCmd, []string{"/test/project"}); err == nil || !strings.Contains(err.Error(), "--all cannot be combined with --session") {
+		t.Errorf("runStats() error = %v, want conflict error", err)
+	}
+}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 
@@ -16,6 +15,7 @@ import (
 var (
 	treeSessionID string
 	treeDepth     int
+	treeJSON      bool
 )
 
 var treeCmd = &cobra.Command{
@@ -23,7 +23,9 @@ var treeCmd = &cobra.Command{
 	Short: "Display agent hierarchy tree",
 	Long: `Display the agent hierarchy for a Claude Code session.
 
-Shows the main conversation and all spawned agents in a tree structure.
+Shows the main conversation and all spawned agents in a tree structure, with
+each agent's type and entry count. Nested agents are indented under the agent
+that spawned them.
 
 Examples:
   # Show tree for most recent session
@@ -33,9 +35,12 @@ Examples:
   claude-history tree /path/to/project --session 679761ba-80c0-4cd3-a586-cc6a1fc56308
 
   # Output formats
-  claude-history tree /path/to/project --format ascii   # Default: ASCII art
-  claude-history tree /path/to/project --format json    # JSON structure
-  claude-history tree /path/to/project --format dot     # GraphViz DOT format`,
+  claude-history tree /path/to/project --format ascii   # Default: indented tree
+  claude-history tree /path/to/project --json           # JSON structure (same as --format json)
+  claude-history tree /path/to/project --format dot     # GraphViz DOT format
+
+  # Render the tree as an image with GraphViz
+  claude-history tree /path/to/project --session 679761ba --format dot | dot -Tpng -o agents.png`,
 	Args: cobra.ExactArgs(1),
 	RunE: runTree,
 }
@@ -45,6 +50,7 @@ func init() {
 
 	treeCmd.Flags().StringVar(&treeSessionID, "session", "", "Session ID to display")
 	treeCmd.Flags().IntVar(&treeDepth, "depth", 0, "Maximum tree depth (0 = unlimited)")
+	treeCmd.Flags().BoolVar(&treeJSON, "json", false, "Output the tree structure as JSON")
 }
 
 func runTree(cmd *cobra.Command, args []string) error {
@@ -55,6 +61,12 @@ func runTree(cmd *cobra.Command, args []string) error {
 	if format == "" {
 		outputFormat = output.FormatASCII
 	}
+	if treeJSON {
+		outputFormat = output.FormatJSON
+	}
+	if treeDepth < 0 {
+		return fmt.Errorf("--depth must not be negative")
+	}
 
 	// Get the project directory
 	projectDir, err := paths.ProjectDir(claudeDir, projectPath)
@@ -88,11 +100,12 @@ func runTree(cmd *cobra.Command, args []string) error {
 	}
 
 	// Build the tree
-	tree, err := agent.BuildTree(projectDir, sessionID)
+	tree, err := agent.BuildNestedTreeWithDepth(projectDir, sessionID, treeDepth)
 	if err != nil {
 		return err
 	}
+	agent.SortChildren(tree, agent.SortBySpawnTime)
 
 	// Write output
-	return output.WriteTree(os.Stdout, tree, outputFormat)
+	return output.WriteTree(cmd.OutOrStdout(), tree, outputFormat)
 }
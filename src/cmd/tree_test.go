@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// saveTreeFlags restores the tree command flags when the test ends.
+func saveTreeFlags(t *testing.T) {
+	t.Helper()
+	oldClaudeDir, oldFormat, oldSession, oldDepth, oldJSON := claudeDir, format, treeSessionID, treeDepth, treeJSON
+	t.Cleanup(func() {
+		claudeDir, format, treeSessionID, treeDepth, treeJSON = oldClaudeDir, oldFormat, oldSession, oldDepth, oldJSON
+		treeCmd.SetOut(nil)
+	})
+}
+
+// runTreeOutput runs the tree command on a project with the given number of agents and
+// returns its output.
+func runTreeOutput(t *testing.T, agentCount int) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "projects", "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	treeSessionID = createTestSessionWithAgents(t, projectDir, agentCount)
+	claudeDir = tmpDir
+
+	var buf bytes.Buffer
+	treeCmd.SetOut(&buf)
+	if err := runTree(treeCmd, []string{"/test/project"}); err != nil {
+		t.Fatalf("runTree() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestRunTree(t *testing.T) {
+	saveTreeFlags(t)
+	format, treeDepth, treeJSON = "", 0, false
+
+	got := runTreeOutput(t, 2)
+	for _, want := range []string{"Session 12345678-1234-1234-1234-123456789abc (", "├── agent-1 (2 entries)\n", "└── agent-2 (2 entries)\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("tree output should contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestRunTree_NoSubagents(t *testing.T) {
+	saveTreeFlags(t)
+	format, treeDepth, treeJSON = "", 0, false
+
+	if got := runTreeOutput(t, 0); !strings.HasSuffix(got, "No subagents\n") {
+		t.Errorf("tree output should say there are no subagents, got:\n%s", got)
+	}
+}
+
+func TestRunTree_JSON(t *testing.T) {
+	saveTreeFlags(t)
+	format, treeDepth, treeJSON = "", 0, true
+
+	var tree struct {
+		SessionID string `json:"sessionId"`
+		Children  []struct {
+			AgentID    string `json:"agentId"`
+			EntryCount int    `json:"entryCount"`
+		} `json:"children"`
+	}
+	if err := json.Unmarshal([]byte(runTreeOutput(t, 2)), &tree); err != nil {
+		t.Fatalf("--json output is not JSON: %v", err)
+	}
+	if len(tree.Children) != 2 || tree.Children[0].AgentID != "agent-1" || tree.Children[0].EntryCount != 2 {
+		t.Errorf("--json tree = %+v, want two agents", tree)
+	}
+}
+
+func TestRunTree_DOT(t *testing.T) {
+	saveTreeFlags(t)
+	format, treeDepth, treeJSON = "dot", 0, false
+
+	got := runTreeOutput(t, 2)
+	for _, want := range []string{
+		"digraph AgentTree {\n",
+		`"12345678-1234-1234-1234-123456789abc" -> "agent-1";`,
+		`"agent-2" [label="agent-2\n2 entries"];`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("--format dot output should contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestRunTree_Zstd(t *testing.T) {
+	saveTreeFlags(t)
+	format, treeDepth, treeJSON = "", 0, false
+
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "projects", "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	treeSessionID = createTestSessionWithAgents(t, projectDir, 1)
+	compressJSONLFiles(t, projectDir)
+	claudeDir = tmpDir
+
+	var buf bytes.Buffer
+	treeCmd.SetOut(&buf)
+	if err := runTree(treeCmd, []string{"/test/project"}); err != nil {
+		t.Fatalf("runTree() error = %v", err)
+	}
+	for _, want := range []string{"Session 12345678-1234-1234-1234-123456789abc (6 entries)\n", "└── agent-1 (2 entries)\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("tree of a compressed session should contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestRunTree_NegativeDepth(t *testing.T) {
+	saveTreeFlags(t)
+	treeDepth = -1
+
+	if err := runTree(treeCmd, []string{"/test/project"}); err == nil || !strings.Contains(err.Error(), "--depth") {
+		t.Errorf("expected --depth error, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/randlee/claude-history/internal/output"
+	"github.com/randlee/claude-history/pkg/paths"
+	"github.com/randlee/claude-history/pkg/session"
+)
+
+// defaultUsageTop is the number of files and days the usage command lists by default.
+const defaultUsageTop = 10
+
+var (
+	usageJSON bool
+	usageTop  int
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage <project-path>",
+	Short: "Report tool usage across every session of a project",
+	Long: `Scan every session of a project, including its subagents, and report the
+total tool calls by tool, the most edited files, and the busiest days.
+
+Files count the calls of the editing tools (Write, Edit, MultiEdit,
+NotebookEdit, ApplyPatch) naming them; reads are not edits. Days are local
+calendar days ranked by the number of entries recorded on them. Sessions are
+read concurrently; files that cannot be read are reported as warnings and left
+out of the totals.
+
+Examples:
+  # Tool usage of a project
+  claude-history usage /path/to/project
+
+  # The 25 most edited files and busiest days, as JSON
+  claude-history usage /path/to/project --top 25 --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUsage,
+}
+
+func init() {
+	rootCmd.AddCommand(usageCmd)
+
+	usageCmd.Flags().BoolVar(&usageJSON, "json", false, "Output the report as JSON")
+	usageCmd.Flags().IntVar(&usageTop, "top", defaultUsageTop, "Number of most edited files and busiest days to list (0 = all)")
+}
+
+func runUsage(cmd *cobra.Command, args []string) error {
+	if usageTop < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+
+	projectPath := args[0]
+	projectDir, err := paths.ProjectDir(claudeDir, projectPath)
+	if err != nil {
+		return err
+	}
+	if !paths.Exists(projectDir) {
+		return fmt.Errorf("project not found: %s", projectPath)
+	}
+
+	report, err := session.ProjectUsage(projectDir)
+	if err != nil {
+		return err
+	}
+	for _, readErr := range report.ReadErrors {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: skipping unreadable file: %v\n", readErr)
+	}
+	if usageTop > 0 {
+		if len(report.Files) > usageTop {
+			report.Files = report.Files[:usageTop]
+		}
+		if len(report.Days) > usageTop {
+			report.Days = report.Days[:usageTop]
+		}
+	}
+
+	if usageJSON || output.ParseFormat(format) == output.FormatJSON {
+		return output.WriteJSON(cmd.OutOrStdout(), report)
+	}
+	return writeUsage(cmd.OutOrStdout(), report)
+}
+
+// writeUsage writes the usage report as three sections: tool calls by tool, the most
+// edited files and the busiest days.
+func writeUsage(w io.Writer, report *session.UsageReport) error {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("Sessions: %d", report.Sessions), fmt.Sprintf("Tool calls: %d", report.ToolCalls))
+
+	if len(report.Tools) > 0 {
+		lines = append(lines, "", "Tools:")
+		for _, tool := range report.Tools {
+			lines = append(lines, fmt.Sprintf("  %6d  %s", tool.Calls, tool.Name))
+		}
+	}
+	if len(report.Files) > 0 {
+		lines = append(lines, "", "Most edited files:")
+		for _, file := range report.Files {
+			lines = append(lines, fmt.Sprintf("  %6d  %s", file.Edits, file.Path))
+		}
+	}
+	if len(report.Days) > 0 {
+		lines = append(lines, "", "Busiest days:")
+		for _, day := range report.Days {
+			lines = append(lines, fmt.Sprintf("  %s  %d entries, %d tool calls", day.Date, day.Entries, day.ToolCalls))
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
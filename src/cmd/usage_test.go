@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/session"
+)
+
+// runUsageOutput runs the usage command on a project that edits main.go three times
+// and util.go once, restoring the command's flags afterwards, and returns its output.
+func runUsageOutput(t *testing.T, top int, asJSON bool) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "projects", "-test-project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	edit := func(ts, path string) string {
+		return `{"type":"assistant","timestamp":"` + ts + `","message":{"role":"assistant","content":[{"type":"tool_use","id":"t","name":"Edit","input":{"file_path":"` + path + `"}}]}}` + "\n"
+	}
+	content := edit("2026-02-01T12:00:00Z", "/src/main.go") + edit("2026-02-01T12:00:01Z", "/src/main.go") +
+		edit("2026-02-01T12:00:02Z", "/src/util.go") + edit("2026-02-02T12:00:00Z", "/src/main.go")
+	if err := os.WriteFile(filepath.Join(projectDir, "aaaa0000-0000-0000-0000-000000000001.jsonl"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldClaudeDir, oldFormat, oldJSON, oldTop := claudeDir, format, usageJSON, usageTop
+	t.Cleanup(func() {
+		claudeDir, format, usageJSON, usageTop = oldClaudeDir, oldFormat, oldJSON, oldTop
+		usageCmd.SetOut(nil)
+	})
+	claudeDir, format, usageJSON, usageTop = tmpDir, "", asJSON, top
+
+	var buf bytes.Buffer
+	usageCmd.SetOut(&buf)
+	if err := runUsage(usageCmd, []string{"/test/project"}); err != nil {
+		t.Fatalf("runUsage() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestRunUsage_Text(t *testing.T) {
+	out := runUsageOutput(t, defaultUsageTop, false)
+	for _, want := range []string{
+		"Sessions: 1\nTool calls: 4\n",
+		"Tools:\n       4  Edit\n",
+		"Most edited files:\n       3  /src/main.go\n       1  /src/util.go\n",
+		"Busiest days:\n",
+		"3 entries, 3 tool calls",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunUsage_JSONTop(t *testing.T) {
+	out := runUsageOutput(t, 1, true)
+	var report session.UsageReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("output is not a usage report: %v\n%s", err, out)
+	}
+	if report.ToolCalls != 4 || len(report.Files) != 1 || report.Files[0].Path != "/src/main.go" || len(report.Days) != 1 {
+		t.Errorf("--top 1 --json report = %+v", report)
+	}
+}
+
+func TestRunUsage_NegativeTop(t *testing.T) {
+	oldTop := usageTop
+	defer func() { usageTop = oldTop }()
+	usageTop = -1
+	if err := runUsage(usageCmd, []string{t.TempDir()}); err == nil {
+		t.Error("negative --top should be rejected")
+	}
+}
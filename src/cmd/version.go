@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/randlee/claude-history/pkg/version"
+)
+
+var (
+	versionCheck        bool
+	versionCheckTimeout time.Duration
+
+	// buildVersion is the bare version of this build (see SetVersion)
+	buildVersion = version.Version
+
+	// latestReleaseURL is where --check looks up the latest release (replaced in tests)
+	latestReleaseURL = version.LatestReleaseURL
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version, optionally checking for updates",
+	Long: `Print the claude-history version and build details.
+
+The version command works offline. With --check it also asks the GitHub releases
+API for the latest release and reports whether an update is available. If the
+check cannot complete (offline, rate limited, or timed out) it says so and still
+exits successfully.
+
+Examples:
+  # Print the version
+  claude-history version
+
+  # Also check whether a newer release is available
+  claude-history version --check
+
+  # Give the update check longer on a slow connection
+  claude-history version --check --timeout 15s`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+
+	versionCmd.Flags().BoolVar(&versionCheck, "check", false, "Check GitHub for a newer release")
+	versionCmd.Flags().DurationVar(&versionCheckTimeout, "timeout", 5*time.Second, "Maximum time to spend on the update check")
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	if versionCheck && versionCheckTimeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+
+	out := cmd.OutOrStdout()
+
+	info := versionInfo
+	if info == "" {
+		info = buildVersion
+	}
+	fmt.Fprintf(out, "claude-history version %s\n", info)
+
+	if !versionCheck {
+		return nil
+	}
+
+	parent := cmd.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, versionCheckTimeout)
+	defer cancel()
+
+	status, err := version.CheckForUpdate(ctx, http.DefaultClient, latestReleaseURL, buildVersion)
+	if err != nil {
+		// The check is best effort: report it, but don't fail the command
+		fmt.Fprintf(out, "Could not check for updates: %v\n", err)
+		return nil
+	}
+
+	if status.UpdateAvailable {
+		fmt.Fprintf(out, "Update available: %s (you have %s)\n", status.Latest.Tag, status.Current)
+		if status.Latest.URL != "" {
+			fmt.Fprintf(out, "  %s\n", status.Latest.URL)
+		}
+	} else {
+		fmt.Fprintf(out, "claude-history is up to date (latest release: %s)\n", status.Latest.Tag)
+	}
+	return nil
+}
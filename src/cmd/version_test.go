@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// saveVersionFlags restores the version command settings when the test ends.
+func saveVersionFlags(t *testing.T) {
+	t.Helper()
+	oldCheck, oldTimeout, oldBuild, oldInfo, oldURL := versionCheck, versionCheckTimeout, buildVersion, versionInfo, latestReleaseURL
+	t.Cleanup(func() {
+		versionCheck, versionCheckTimeout, buildVersion, versionInfo, latestReleaseURL = oldCheck, oldTimeout, oldBuild, oldInfo, oldURL
+		versionCmd.SetOut(nil)
+	})
+}
+
+// runVersionOutput runs the version command and returns its output.
+func runVersionOutput(t *testing.T) string {
+	t.Helper()
+	var buf bytes.Buffer
+	versionCmd.SetOut(&buf)
+	if err := runVersion(versionCmd, nil); err != nil {
+		t.Fatalf("runVersion() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestRunVersion_Offline(t *testing.T) {
+	saveVersionFlags(t)
+	versionCheck = false
+	versionInfo = "0.3.0 (commit: abc, built: today)"
+
+	requested := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { requested = true }))
+	defer srv.Close()
+	latestReleaseURL = srv.URL
+
+	out := runVersionOutput(t)
+	if out != "claude-history version 0.3.0 (commit: abc, built: today)\n" {
+		t.Errorf("output = %q", out)
+	}
+	if requested {
+		t.Error("plain version should not make a network request")
+	}
+}
+
+func TestRunVersion_CheckUpdateAvailable(t *testing.T) {
+	saveVersionFlags(t)
+	versionCheck, versionCheckTimeout, buildVersion = true, time.Second, "0.3.0"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tag_name":"v0.4.0","html_url":"https://example.com/r/v0.4.0"}`))
+	}))
+	defer srv.Close()
+	latestReleaseURL = srv.URL
+
+	out := runVersionOutput(t)
+	if !strings.Contains(out, "Update available: v0.4.0 (you have 0.3.0)") || !strings.Contains(out, "https://example.com/r/v0.4.0") {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestRunVersion_CheckUpToDate(t *testing.T) {
+	saveVersionFlags(t)
+	versionCheck, versionCheckTimeout, buildVersion = true, time.Second, "0.4.0"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tag_name":"v0.4.0"}`))
+	}))
+	defer srv.Close()
+	latestReleaseURL = srv.URL
+
+	if out := runVersionOutput(t); !strings.Contains(out, "up to date (latest release: v0.4.0)") {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestRunVersion_CheckFailsGracefully(t *testing.T) {
+	saveVersionFlags(t)
+	versionCheck, versionCheckTimeout = true, time.Second
+
+	// A server that is already closed: the request fails to connect
+	srv := httptest.NewServer(http.NotFoundHandler())
+	latestReleaseURL = srv.URL
+	srv.Close()
+
+	out := runVersionOutput(t)
+	if !strings.HasPrefix(out, "claude-history version ") || !strings.Contains(out, "Could not check for updates") {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestRunVersion_InvalidTimeout(t *testing.T) {
+	saveVersionFlags(t)
+	versionCheck, versionCheckTimeout = true, 0
+
+	if err := runVersion(versionCmd, nil); err == nil || !strings.Contains(err.Error(), "--timeout must be positive") {
+		t.Errorf("runVersion() error = %v, want timeout error", err)
+	}
+}
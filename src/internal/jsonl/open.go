@@ -0,0 +1,42 @@
+package jsonl
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ZstdExt is the extension of zstd-compressed JSONL files, e.g. "<session>.jsonl.zst".
+const ZstdExt = ".zst"
+
+// Open opens a JSONL file for reading. Files ending in ZstdExt are decompressed as they
+// are read, so a compressed session is never held in memory whole.
+func Open(filePath string) (io.ReadCloser, error) {
+	file, err := os.Open(filePath) //nolint:gosec // G304: file path from CLI input is expected
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(filePath, ZstdExt) {
+		return file, nil
+	}
+
+	decoder, err := zstd.NewReader(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &zstdFile{Decoder: decoder, file: file}, nil
+}
+
+// zstdFile closes both the decoder and the compressed file beneath it.
+type zstdFile struct {
+	*zstd.Decoder
+	file *os.File
+}
+
+func (z *zstdFile) Close() error {
+	z.Decoder.Close()
+	return z.file.Close()
+}
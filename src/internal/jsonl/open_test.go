@@ -0,0 +1,51 @@
+package jsonl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// writeZstd writes content compressed with zstd, as "<name>.jsonl.zst" files are stored.
+func writeZstd(t *testing.T, path, content string) {
+	t.Helper()
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = encoder.Close() }()
+	if err := os.WriteFile(path, encoder.EncodeAll([]byte(content), nil), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScanner_ScanZstd(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.jsonl.zst")
+	writeZstd(t, testFile, "{\"id\": 1}\n\n{\"id\": 2}\n")
+
+	var lineNums []int
+	err := NewScanner().ScanNumbered(testFile, func(lineNum int, line json.RawMessage) error {
+		lineNums = append(lineNums, lineNum)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanNumbered() error: %v", err)
+	}
+	if len(lineNums) != 2 || lineNums[0] != 1 || lineNums[1] != 3 {
+		t.Errorf("ScanNumbered() line numbers = %v, want [1 3]", lineNums)
+	}
+}
+
+func TestOpen_CorruptZstd(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.jsonl.zst")
+	if err := os.WriteFile(testFile, []byte("{\"id\": 1}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewScanner().Scan(testFile, func(json.RawMessage) error { return nil }); err == nil {
+		t.Error("Scan() should fail for a .zst file that is not zstd-compressed")
+	}
+}
@@ -3,10 +3,13 @@ package jsonl
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
-	"os"
 )
 
+// utf8BOM is the byte order mark some editors write at the start of UTF-8 files.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
 // Scanner reads JSONL files line by line with streaming support.
 type Scanner struct {
 	// MaxLineSize is the maximum size of a single line in bytes.
@@ -25,7 +28,18 @@ func NewScanner() *Scanner {
 // Lines that fail to parse as JSON are silently skipped.
 // If fn returns an error, scanning stops and that error is returned.
 func (s *Scanner) Scan(filePath string, fn func(line json.RawMessage) error) error {
-	file, err := os.Open(filePath) //nolint:gosec // G304: file path from CLI input is expected
+	return s.ScanNumbered(filePath, func(_ int, line json.RawMessage) error {
+		return fn(line)
+	})
+}
+
+// ScanNumbered reads a JSONL file (or a zstd-compressed one, see Open) like Scan, also
+// passing each line's 1-based line number in the file. Skipped lines (blank or not
+// JSON) still count toward the numbering. A UTF-8 byte order mark at the start of the
+// file is ignored, lines are passed with surrounding whitespace removed, and
+// whitespace-only lines are skipped.
+func (s *Scanner) ScanNumbered(filePath string, fn func(lineNum int, line json.RawMessage) error) error {
+	file, err := Open(filePath)
 	if err != nil {
 		return err
 	}
@@ -41,18 +55,17 @@ func (s *Scanner) Scan(filePath string, fn func(line json.RawMessage) error) err
 	buf := make([]byte, 0, 64*1024) // 64KB initial buffer
 	scanner.Buffer(buf, maxSize)
 
+	lineNum := 0
 	for scanner.Scan() {
+		lineNum++
 		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
+		if lineNum == 1 {
+			line = bytes.TrimPrefix(line, utf8BOM)
 		}
+		line = bytes.TrimSpace(line)
 
 		// Quick JSON validation - must start with { or [
-		trimmed := line
-		for len(trimmed) > 0 && (trimmed[0] == ' ' || trimmed[0] == '\t') {
-			trimmed = trimmed[1:]
-		}
-		if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		if len(line) == 0 || (line[0] != '{' && line[0] != '[') {
 			continue
 		}
 
@@ -60,7 +73,7 @@ func (s *Scanner) Scan(filePath string, fn func(line json.RawMessage) error) err
 		lineCopy := make([]byte, len(line))
 		copy(lineCopy, line)
 
-		if err := fn(json.RawMessage(lineCopy)); err != nil {
+		if err := fn(lineNum, json.RawMessage(lineCopy)); err != nil {
 			return err
 		}
 	}
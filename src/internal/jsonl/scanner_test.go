@@ -68,6 +68,34 @@ also not valid
 	}
 }
 
+func TestScanner_ScanNumbered(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.jsonl")
+
+	content := `{"id": 1}
+
+not valid json
+{"id": 2}
+`
+	if err := os.WriteFile(testFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var lineNums []int
+	err := NewScanner().ScanNumbered(testFile, func(lineNum int, line json.RawMessage) error {
+		lineNums = append(lineNums, lineNum)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanNumbered failed: %v", err)
+	}
+
+	// Blank and invalid lines are skipped but still counted
+	if len(lineNums) != 2 || lineNums[0] != 1 || lineNums[1] != 4 {
+		t.Errorf("Expected line numbers [1 4], got %v", lineNums)
+	}
+}
+
 func TestScanInto(t *testing.T) {
 	tmpDir := t.TempDir()
 	testFile := filepath.Join(tmpDir, "test.jsonl")
@@ -160,3 +188,28 @@ invalid
 		t.Errorf("Expected 4 valid lines, got %d", count)
 	}
 }
+
+func TestScanner_BOMAndBlankLines(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.jsonl")
+	content := "\xEF\xBB\xBF{\"a\": 1}\n  \t\n\r\n{\"b\": 2}  \r\n\n"
+	if err := os.WriteFile(testFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	var lineNums []int
+	err := NewScanner().ScanNumbered(testFile, func(lineNum int, line json.RawMessage) error {
+		got = append(got, string(line))
+		lineNums = append(lineNums, lineNum)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanNumbered failed: %v", err)
+	}
+	if len(got) != 2 || got[0] != `{"a": 1}` || got[1] != `{"b": 2}` {
+		t.Errorf("lines = %q, want the two entries without BOM or whitespace", got)
+	}
+	if len(lineNums) != 2 || lineNums[0] != 1 || lineNums[1] != 4 {
+		t.Errorf("line numbers = %v, want [1 4]", lineNums)
+	}
+}
@@ -0,0 +1,60 @@
+package output
+
+import (
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// ANSI SGR sequences of the terminal color scheme.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiDim     = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+)
+
+// Colorizer applies the color scheme shared by all terminal output, so each kind of
+// text has the same color in every command: errors red, warnings yellow, timestamps
+// dim, tool calls magenta, and entry types by role. The zero value is disabled and
+// returns text unchanged.
+type Colorizer struct {
+	Enabled bool
+}
+
+// paint wraps s in the given SGR sequence when c is enabled.
+func (c Colorizer) paint(sgr, s string) string {
+	if !c.Enabled || s == "" {
+		return s
+	}
+	return sgr + s + ansiReset
+}
+
+// Error colors an error message.
+func (c Colorizer) Error(s string) string { return c.paint(ansiBold+ansiRed, s) }
+
+// Warning colors a warning or hint.
+func (c Colorizer) Warning(s string) string { return c.paint(ansiYellow, s) }
+
+// Timestamp colors a timestamp.
+func (c Colorizer) Timestamp(s string) string { return c.paint(ansiDim, s) }
+
+// Tool colors a tool call summary such as "[Bash] go test".
+func (c Colorizer) Tool(s string) string { return c.paint(ansiMagenta, s) }
+
+// EntryType colors the name of an entry type by role: user cyan, assistant green,
+// system yellow, and others unchanged.
+func (c Colorizer) EntryType(t models.EntryType) string {
+	switch t {
+	case models.EntryTypeUser:
+		return c.paint(ansiCyan, string(t))
+	case models.EntryTypeAssistant:
+		return c.paint(ansiGreen, string(t))
+	case models.EntryTypeSystem:
+		return c.paint(ansiYellow, string(t))
+	default:
+		return string(t)
+	}
+}
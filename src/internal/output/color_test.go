@@ -0,0 +1,90 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestColorizer_Disabled(t *testing.T) {
+	var c Colorizer
+	for _, got := range []string{
+		c.Error("boom"), c.Warning("careful"), c.Timestamp("[10:00:00]"), c.Tool("[Bash] ls"),
+		c.EntryType(models.EntryTypeUser),
+	} {
+		if strings.Contains(got, "\x1b[") {
+			t.Errorf("disabled colorizer returned %q with escape codes", got)
+		}
+	}
+}
+
+func TestColorizer_Enabled(t *testing.T) {
+	c := Colorizer{Enabled: true}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"error", c.Error("boom"), "\x1b[1m\x1b[31mboom\x1b[0m"},
+		{"warning", c.Warning("careful"), "\x1b[33mcareful\x1b[0m"},
+		{"timestamp", c.Timestamp("[10:00:00]"), "\x1b[2m[10:00:00]\x1b[0m"},
+		{"tool", c.Tool("[Bash] ls"), "\x1b[35m[Bash] ls\x1b[0m"},
+		{"user", c.EntryType(models.EntryTypeUser), "\x1b[36muser\x1b[0m"},
+		{"assistant", c.EntryType(models.EntryTypeAssistant), "\x1b[32massistant\x1b[0m"},
+		{"system", c.EntryType(models.EntryTypeSystem), "\x1b[33msystem\x1b[0m"},
+		{"other type", c.EntryType(models.EntryTypeQueueOperation), string(models.EntryTypeQueueOperation)},
+		{"empty", c.Error(""), ""},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestWriteEntryLineWith_Colors(t *testing.T) {
+	c := Colorizer{Enabled: true}
+	entry := models.ConversationEntry{
+		Type:      models.EntryTypeUser,
+		Timestamp: "2026-02-01T10:00:07Z",
+		Message:   []byte(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"no such file","is_error":true}]}`),
+	}
+
+	var buf bytes.Buffer
+	WriteEntryLineWith(&buf, entry, c)
+	want := c.Timestamp("[10:00:07]") + " " + c.EntryType(models.EntryTypeUser) + ": " + c.Error("(tool error)") + "\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	var plain bytes.Buffer
+	WriteEntryLine(&plain, entry)
+	if plain.String() != "[10:00:07] user: (tool error)\n" {
+		t.Errorf("uncolored line = %q", plain.String())
+	}
+}
+
+func TestWriteEntriesWith_ColorsTextOnly(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:00Z", Message: []byte(`"Done"`)},
+	}
+	c := Colorizer{Enabled: true}
+
+	var text bytes.Buffer
+	if err := WriteEntriesWith(&text, entries, FormatList, 0, c); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), c.EntryType(models.EntryTypeAssistant)) {
+		t.Errorf("text output should be colored, got %q", text.String())
+	}
+
+	var js bytes.Buffer
+	if err := WriteEntriesWith(&js, entries, FormatJSON, 0, c); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(js.String(), "\x1b[") {
+		t.Errorf("JSON output should never be colored, got %q", js.String())
+	}
+}
@@ -17,13 +17,16 @@ import (
 type Format string
 
 const (
-	FormatJSON    Format = "json"
-	FormatList    Format = "list"
-	FormatSummary Format = "summary"
-	FormatASCII   Format = "ascii"
-	FormatDOT     Format = "dot"
-	FormatPath    Format = "path"
-	FormatHTML    Format = "html"
+	FormatJSON     Format = "json"
+	FormatList     Format = "list"
+	FormatSummary  Format = "summary"
+	FormatASCII    Format = "ascii"
+	FormatDOT      Format = "dot"
+	FormatPath     Format = "path"
+	FormatHTML     Format = "html"
+	FormatMarkdown Format = "markdown"
+	FormatJSONL    Format = "jsonl"
+	FormatProm     Format = "prom"
 )
 
 // ParseFormat parses a format string, returning FormatList as default.
@@ -43,6 +46,12 @@ func ParseFormat(s string) Format {
 		return FormatPath
 	case "html":
 		return FormatHTML
+	case "markdown", "md":
+		return FormatMarkdown
+	case "jsonl":
+		return FormatJSONL
+	case "prom", "prometheus":
+		return FormatProm
 	default:
 		return FormatList
 	}
@@ -62,7 +71,8 @@ func WriteList(w io.Writer, items []string) {
 	}
 }
 
-// WriteSessions writes sessions in list format.
+// WriteSessions writes sessions in list format. The git branch, when known, is shown
+// in brackets before the first prompt.
 func WriteSessions(w io.Writer, sessions []models.Session, format Format) error {
 	switch format {
 	case FormatJSON:
@@ -74,6 +84,9 @@ func WriteSessions(w io.Writer, sessions []models.Session, format Format) error
 			if len(prompt) > 50 {
 				prompt = prompt[:50] + "..."
 			}
+			if s.GitBranch != "" {
+				prompt = "[" + s.GitBranch + "]  " + prompt
+			}
 			fmt.Fprintf(w, "%s  %s  %d msgs  %s\n", s.ID, modified, s.MessageCount, prompt)
 		}
 	}
@@ -95,17 +108,23 @@ func WriteProjects(w io.Writer, projects []models.Project, format Format) error
 
 // WriteEntries writes conversation entries.
 func WriteEntries(w io.Writer, entries []models.ConversationEntry, format Format, limit int) error {
+	return WriteEntriesWith(w, entries, format, limit, Colorizer{})
+}
+
+// WriteEntriesWith writes conversation entries like WriteEntries, coloring the text
+// format with c. JSON and summary output are never colored.
+func WriteEntriesWith(w io.Writer, entries []models.ConversationEntry, format Format, limit int, c Colorizer) error {
 	switch format {
 	case FormatJSON:
 		return WriteJSON(w, entries)
 	case FormatSummary:
 		return writeEntrySummary(w, entries)
 	default:
-		return writeEntryList(w, entries, limit)
+		return writeEntryList(w, entries, limit, c)
 	}
 }
 
-func writeEntryList(w io.Writer, entries []models.ConversationEntry, limit int) error {
+func writeEntryList(w io.Writer, entries []models.ConversationEntry, limit int, c Colorizer) error {
 	// Filter out entries with no text content first
 	var textEntries []models.ConversationEntry
 	for _, e := range entries {
@@ -120,7 +139,7 @@ func writeEntryList(w io.Writer, entries []models.ConversationEntry, limit int)
 
 	// Default mode (limit=100): Show preview format
 	if limit == 100 && len(textEntries) > 2 {
-		return writeEntryPreview(w, textEntries)
+		return writeEntryPreview(w, textEntries, c)
 	}
 
 	// Full output mode (limit=0) or custom limit: Show all entries
@@ -133,13 +152,13 @@ func writeEntryList(w io.Writer, entries []models.ConversationEntry, limit int)
 			text = text[:limit] + "..."
 		}
 		text = strings.ReplaceAll(text, "\n", " ")
-		fmt.Fprintf(w, "[%s] %s: %s\n", ts.Format("15:04:05"), e.Type, text)
+		fmt.Fprintf(w, "%s %s: %s\n", c.Timestamp("["+ts.Format("15:04:05")+"]"), c.EntryType(e.Type), text)
 	}
 	return nil
 }
 
 // writeEntryPreview shows first entry, count, and last entry with preview
-func writeEntryPreview(w io.Writer, entries []models.ConversationEntry) error {
+func writeEntryPreview(w io.Writer, entries []models.ConversationEntry, c Colorizer) error {
 	first := entries[0]
 	last := entries[len(entries)-1]
 
@@ -150,7 +169,7 @@ func writeEntryPreview(w io.Writer, entries []models.ConversationEntry) error {
 		firstText = firstText[:100] + "..."
 	}
 	firstText = strings.ReplaceAll(firstText, "\n", " ")
-	fmt.Fprintf(w, "[%s] %s: %s\n", firstTS.Format("15:04:05"), first.Type, firstText)
+	fmt.Fprintf(w, "%s %s: %s\n", c.Timestamp("["+firstTS.Format("15:04:05")+"]"), c.EntryType(first.Type), firstText)
 
 	// Show count of middle entries
 	if len(entries) > 2 {
@@ -161,7 +180,7 @@ func writeEntryPreview(w io.Writer, entries []models.ConversationEntry) error {
 	lastTS, _ := last.GetTimestamp()
 	lastText := last.GetTextContent()
 
-	fmt.Fprintf(w, "[%s] %s: ", lastTS.Format("15:04:05"), last.Type)
+	fmt.Fprintf(w, "%s %s: ", c.Timestamp("["+lastTS.Format("15:04:05")+"]"), c.EntryType(last.Type))
 
 	// Split into lines and show first 10
 	lines := strings.Split(lastText, "\n")
@@ -179,11 +198,11 @@ func writeEntryPreview(w io.Writer, entries []models.ConversationEntry) error {
 	}
 
 	if len(lines) > previewLines {
-		fmt.Fprintf(w, "                     ... (%d of %d lines shown - TRUNCATED)\n", previewLines, len(lines))
+		fmt.Fprintf(w, "                     %s\n", c.Warning(fmt.Sprintf("... (%d of %d lines shown - TRUNCATED)", previewLines, len(lines))))
 	}
 
 	// Show help message with exact command
-	fmt.Fprintf(w, "\n⚠️  Text format truncates long outputs. For full content:\n")
+	fmt.Fprintf(w, "\n%s\n", c.Warning("⚠️  Text format truncates long outputs. For full content:"))
 	fmt.Fprintf(w, "   --format json | jq -r '.[-1].message.content[0].text'\n")
 
 	return nil
@@ -220,6 +239,52 @@ func writeEntrySummary(w io.Writer, entries []models.ConversationEntry) error {
 	return nil
 }
 
+// WriteEntryLine writes entry on a single line as "[15:04:05] type: text", with
+// newlines in the text flattened to spaces. Entries without text are summarized by
+// their tool calls or tool results; it reports false, writing nothing, when an entry
+// has neither.
+func WriteEntryLine(w io.Writer, entry models.ConversationEntry) bool {
+	return WriteEntryLineWith(w, entry, Colorizer{})
+}
+
+// WriteEntryLineWith writes entry like WriteEntryLine, coloring it with c.
+func WriteEntryLineWith(w io.Writer, entry models.ConversationEntry, c Colorizer) bool {
+	text := strings.Join(strings.Fields(entry.GetTextContent()), " ")
+	if text == "" {
+		text = entryActivity(entry, c)
+	}
+	if text == "" {
+		return false
+	}
+	ts, _ := entry.GetTimestamp()
+	fmt.Fprintf(w, "%s %s: %s\n", c.Timestamp("["+ts.Format("15:04:05")+"]"), c.EntryType(entry.Type), text)
+	return true
+}
+
+// entryActivity summarizes the tool calls or tool results of an entry without text,
+// colored with c.
+func entryActivity(entry models.ConversationEntry, c Colorizer) string {
+	if calls := entry.ExtractToolCalls(); len(calls) > 0 {
+		tools := make([]ToolUse, len(calls))
+		for i, call := range calls {
+			tools[i] = ToolUse{ID: call.ID, Name: call.Name, Input: call.Input}
+		}
+		return c.Tool(FormatToolSummary(tools))
+	}
+	results := entry.ExtractToolResults()
+	switch len(results) {
+	case 0:
+		return ""
+	case 1:
+		if results[0].IsError {
+			return c.Error("(tool error)")
+		}
+		return "(tool result)"
+	default:
+		return fmt.Sprintf("(%d tool results)", len(results))
+	}
+}
+
 // WritePath writes a single path.
 func WritePath(w io.Writer, path string) {
 	fmt.Fprintln(w, path)
@@ -579,6 +579,10 @@ func TestParseFormat(t *testing.T) {
 		{"ascii", FormatASCII},
 		{"dot", FormatDOT},
 		{"path", FormatPath},
+		{"markdown", FormatMarkdown},
+		{"md", FormatMarkdown},
+		{"prom", FormatProm},
+		{"prometheus", FormatProm},
 		{"unknown", FormatList},
 		{"", FormatList},
 	}
@@ -646,6 +650,34 @@ func TestWriteSessions(t *testing.T) {
 		}
 	})
 
+	t.Run("list format with branch", func(t *testing.T) {
+		withBranch := []models.Session{sessions[0]}
+		withBranch[0].GitBranch = "feature/x"
+		var buf bytes.Buffer
+		if err := WriteSessions(&buf, withBranch, FormatList); err != nil {
+			t.Fatalf("WriteSessions() error = %v", err)
+		}
+		want := "session-123  2026-01-31T10:30:00Z  5 msgs  [feature/x]  Hello world\n"
+		if buf.String() != want {
+			t.Errorf("WriteSessions() = %q, want %q", buf.String(), want)
+		}
+	})
+
+	t.Run("json format includes cwd and branch", func(t *testing.T) {
+		withContext := []models.Session{sessions[0]}
+		withContext[0].GitBranch = "main"
+		withContext[0].Cwd = "/work/app"
+		var buf bytes.Buffer
+		if err := WriteSessions(&buf, withContext, FormatJSON); err != nil {
+			t.Fatalf("WriteSessions() error = %v", err)
+		}
+		for _, want := range []string{`"gitBranch": "main"`, `"cwd": "/work/app"`} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("JSON missing %s:\n%s", want, buf.String())
+			}
+		}
+	})
+
 	t.Run("json format", func(t *testing.T) {
 		var buf bytes.Buffer
 		err := WriteSessions(&buf, sessions, FormatJSON)
@@ -669,3 +701,78 @@ func TestWritePath(t *testing.T) {
 		t.Errorf("WritePath() = %q, want %q", buf.String(), expected)
 	}
 }
+
+func TestWriteEntryLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry models.ConversationEntry
+		want  string
+	}{
+		{
+			name: "text flattened to one line",
+			entry: models.ConversationEntry{
+				Type:      models.EntryTypeUser,
+				Timestamp: "2026-02-01T10:00:05Z",
+				Message:   []byte(`"Fix the\n  failing   test"`),
+			},
+			want: "[10:00:05] user: Fix the failing test\n",
+		},
+		{
+			name: "tool call summarized",
+			entry: models.ConversationEntry{
+				Type:      models.EntryTypeAssistant,
+				Timestamp: "2026-02-01T10:00:06Z",
+				Message:   []byte(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}}]}`),
+			},
+			want: "[10:00:06] assistant: [Bash] go test ./...\n",
+		},
+		{
+			name: "tool result",
+			entry: models.ConversationEntry{
+				Type:      models.EntryTypeUser,
+				Timestamp: "2026-02-01T10:00:07Z",
+				Message:   []byte(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}`),
+			},
+			want: "[10:00:07] user: (tool result)\n",
+		},
+		{
+			name: "tool error",
+			entry: models.ConversationEntry{
+				Type:      models.EntryTypeUser,
+				Timestamp: "2026-02-01T10:00:08Z",
+				Message:   []byte(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"boom","is_error":true}]}`),
+			},
+			want: "[10:00:08] user: (tool error)\n",
+		},
+		{
+			name: "several tool results",
+			entry: models.ConversationEntry{
+				Type:      models.EntryTypeUser,
+				Timestamp: "2026-02-01T10:00:09Z",
+				Message:   []byte(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"a"},{"type":"tool_result","tool_use_id":"t2","content":"b"}]}`),
+			},
+			want: "[10:00:09] user: (2 tool results)\n",
+		},
+		{
+			name: "nothing to show",
+			entry: models.ConversationEntry{
+				Type:      models.EntryTypeSystem,
+				Timestamp: "2026-02-01T10:00:10Z",
+			},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			wrote := WriteEntryLine(&buf, tt.entry)
+			if buf.String() != tt.want {
+				t.Errorf("WriteEntryLine() wrote %q, want %q", buf.String(), tt.want)
+			}
+			if wrote != (tt.want != "") {
+				t.Errorf("WriteEntryLine() = %v, want %v", wrote, tt.want != "")
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@
 package agent
 
 import (
+	"encoding/json"
+	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/randlee/claude-history/internal/jsonl"
 	"github.com/randlee/claude-history/pkg/models"
@@ -27,17 +30,17 @@ func DiscoverAgents(sessionDir string) ([]models.Agent, error) {
 		// Determine agent type from filename
 		agent.AgentType = parseAgentType(agentID)
 
-		// Count entries in the agent file
-		count, err := jsonl.CountLines(filePath)
+		// Count the entries that parse; a damaged file is still listed
+		entries, parseErrors, err := ReadAgentFile(filePath)
 		if err == nil {
-			agent.EntryCount = count
+			agent.EntryCount = len(entries)
+			agent.ParseErrors = parseErrors
 		}
 
-		// Try to get session ID from first entry
-		_ = jsonl.ScanInto(filePath, func(entry models.ConversationEntry) error {
-			agent.SessionID = entry.SessionID
-			return StopIteration // Stop after first entry
-		})
+		// Take the session ID from the first entry
+		if len(entries) > 0 {
+			agent.SessionID = entries[0].SessionID
+		}
 
 		agents = append(agents, agent)
 	}
@@ -69,16 +72,70 @@ func parseAgentType(agentID string) string {
 	return ""
 }
 
+// ShortAgentIDLength is the number of characters shown for an agent ID in displays.
+const ShortAgentIDLength = 8
+
+// agentTypeLabels maps agent types parsed from IDs to human-readable labels.
+var agentTypeLabels = map[string]string{
+	"prompt_suggestion": "Prompt suggestion",
+	"explore":           "Explore",
+}
+
+// NormalizeAgentID returns a consistent short display form and a human-readable
+// type label for an agent ID. Raw hex IDs have no type label.
+// Examples:
+//
+//	"a12eb64f9c"                -> ("a12eb64f", "")
+//	"aprompt_suggestion-abc123" -> ("abc123", "Prompt suggestion")
+//	"aexplore-def456"           -> ("def456", "Explore")
+//
+// Whitespace and non-printable characters are dropped from the short form;
+// callers must still escape the full ID when embedding it in HTML.
+func NormalizeAgentID(id string) (short, typeLabel string) {
+	return NormalizeAgentIDLength(id, ShortAgentIDLength)
+}
+
+// NormalizeAgentIDLength is like NormalizeAgentID but keeps up to length characters
+// in the short form. A length of 0 or less keeps them all.
+func NormalizeAgentIDLength(id string, length int) (short, typeLabel string) {
+	id = strings.TrimSpace(id)
+
+	rest := id
+	if agentType := parseAgentType(id); agentType != "" {
+		typeLabel = agentTypeLabels[agentType]
+		rest = strings.TrimPrefix(id, "a"+agentType+"-")
+	}
+
+	var sb strings.Builder
+	count := 0
+	for _, r := range rest {
+		if length > 0 && count == length {
+			break
+		}
+		if !unicode.IsPrint(r) || unicode.IsSpace(r) {
+			continue
+		}
+		sb.WriteRune(r)
+		count++
+	}
+	return sb.String(), typeLabel
+}
+
 // FindAgentSpawns scans a session file to find entries that spawn agents.
 // Returns a map of agent ID to the UUID of the entry that spawned it.
-// Agent spawns are detected via user entries with toolUseResult where status is "async_launched".
+// Agent spawns are detected via user entries with toolUseResult where status is "async_launched",
+// and via legacy queue-operation entries (see models.ConversationEntry.SpawnTargetAgentID).
+// When an agent has both, the toolUseResult entry wins.
 func FindAgentSpawns(sessionFilePath string) (map[string]string, error) {
 	spawns := make(map[string]string)
 
 	err := jsonl.ScanInto(sessionFilePath, func(entry models.ConversationEntry) error {
 		if entry.IsAgentSpawn() {
-			agentID := entry.GetSpawnedAgentID()
-			spawns[agentID] = entry.UUID
+			spawns[entry.GetSpawnedAgentID()] = entry.UUID
+		} else if agentID := entry.SpawnTargetAgentID(); agentID != "" {
+			if _, ok := spawns[agentID]; !ok {
+				spawns[agentID] = entry.UUID
+			}
 		}
 		return nil
 	})
@@ -93,8 +150,8 @@ func GetAgent(sessionDir string, agentID string) (*models.Agent, error) {
 
 	var filePath string
 	testPaths := []string{
-		filepath.Join(subagentsDir, "agent-"+agentID+".jsonl"),
-		filepath.Join(subagentsDir, agentID+".jsonl"),
+		paths.JSONLFile(filepath.Join(subagentsDir, "agent-"+agentID)),
+		paths.JSONLFile(filepath.Join(subagentsDir, agentID)),
 	}
 
 	for _, p := range testPaths {
@@ -114,14 +171,41 @@ func GetAgent(sessionDir string, agentID string) (*models.Agent, error) {
 		AgentType: parseAgentType(agentID),
 	}
 
-	count, err := jsonl.CountLines(filePath)
+	entries, parseErrors, err := ReadAgentFile(filePath)
 	if err == nil {
-		agent.EntryCount = count
+		agent.EntryCount = len(entries)
+		agent.ParseErrors = parseErrors
 	}
 
 	return agent, nil
 }
 
+// ReadAgentFile reads an agent's JSONL file like session.ReadSession, recording each
+// entry's SourceLine and RawLine, without giving up on a damaged file: lines that are not
+// valid entries are skipped and counted in parseErrors, and a read that fails partway
+// (a line over the scanner's size limit, corrupt compression) keeps the entries before
+// it and counts as one more. err is only for a file that cannot be opened.
+func ReadAgentFile(filePath string) (entries []models.ConversationEntry, parseErrors int, err error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, 0, err
+	}
+	scanErr := jsonl.NewScanner().ScanNumbered(filePath, func(lineNum int, line json.RawMessage) error {
+		var entry models.ConversationEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			parseErrors++
+			return nil
+		}
+		entry.SourceLine = lineNum
+		entry.RawLine = line
+		entries = append(entries, entry)
+		return nil
+	})
+	if scanErr != nil {
+		parseErrors++
+	}
+	return entries, parseErrors, nil
+}
+
 // ReadAgentEntries reads all entries from an agent's JSONL file.
 func ReadAgentEntries(filePath string) ([]models.ConversationEntry, error) {
 	return jsonl.ReadAll[models.ConversationEntry](filePath)
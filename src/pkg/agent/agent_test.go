@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 // mustMkdirAll creates directories or fails the test
@@ -116,6 +119,106 @@ func TestDiscoverAgents(t *testing.T) {
 	}
 }
 
+func TestDiscoverAgents_Zstd(t *testing.T) {
+	sessionDir := filepath.Join(t.TempDir(), "679761ba-80c0-4cd3-a586-cc6a1fc56308")
+	subagentsDir := filepath.Join(sessionDir, "subagents")
+	mustMkdirAll(t, subagentsDir)
+
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := `{"uuid":"1","sessionId":"test","type":"user"}
+{"uuid":"2","sessionId":"test","type":"assistant"}
+`
+	mustWriteFile(t, filepath.Join(subagentsDir, "agent-a12eb64.jsonl.zst"), encoder.EncodeAll([]byte(content), nil))
+	_ = encoder.Close()
+
+	agents, err := DiscoverAgents(sessionDir)
+	if err != nil {
+		t.Fatalf("DiscoverAgents() error: %v", err)
+	}
+	if len(agents) != 1 || agents[0].ID != "a12eb64" {
+		t.Fatalf("DiscoverAgents() = %+v, want agent a12eb64", agents)
+	}
+	if agents[0].EntryCount != 2 || agents[0].SessionID != "test" {
+		t.Errorf("DiscoverAgents() read %d entries of session %q, want 2 of test", agents[0].EntryCount, agents[0].SessionID)
+	}
+
+	got, err := GetAgent(sessionDir, "a12eb64")
+	if err != nil || got.FilePath != filepath.Join(subagentsDir, "agent-a12eb64.jsonl.zst") {
+		t.Errorf("GetAgent() = %+v, %v; want the compressed file", got, err)
+	}
+}
+
+func TestReadAgentFile_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent-a12eb64.jsonl")
+	content := `{"uuid":"1","sessionId":"test","type":"user"}
+{"uuid":"2","sessionId":"test","type":
+{"uuid":"3","sessionId":"test","type":"assistant"}
+[1, 2]
+`
+	mustWriteFile(t, path, []byte(content))
+
+	entries, parseErrors, err := ReadAgentFile(path)
+	if err != nil {
+		t.Fatalf("ReadAgentFile() error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].UUID != "1" || entries[1].UUID != "3" || entries[1].SourceLine != 3 {
+		t.Errorf("ReadAgentFile() entries = %+v, want lines 1 and 3", entries)
+	}
+	if parseErrors != 2 {
+		t.Errorf("ReadAgentFile() parseErrors = %d, want 2", parseErrors)
+	}
+
+	if _, _, err := ReadAgentFile(filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Error("ReadAgentFile() should fail for a missing file")
+	}
+}
+
+func TestReadAgentFile_LineTooLong(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent-a12eb64.jsonl")
+	content := `{"uuid":"1","sessionId":"test","type":"user"}
+{"uuid":"2","sessionId":"test","type":"user","message":"` + strings.Repeat("x", 11*1024*1024) + `"}
+{"uuid":"3","sessionId":"test","type":"assistant"}
+`
+	mustWriteFile(t, path, []byte(content))
+
+	entries, parseErrors, err := ReadAgentFile(path)
+	if err != nil {
+		t.Fatalf("ReadAgentFile() error: %v", err)
+	}
+	if len(entries) != 1 || parseErrors != 1 {
+		t.Errorf("ReadAgentFile() = %d entries, %d parse errors; want the entry before the long line and 1 error", len(entries), parseErrors)
+	}
+}
+
+func TestDiscoverAgents_Corrupt(t *testing.T) {
+	sessionDir := filepath.Join(t.TempDir(), "679761ba-80c0-4cd3-a586-cc6a1fc56308")
+	subagentsDir := filepath.Join(sessionDir, "subagents")
+	mustMkdirAll(t, subagentsDir)
+	mustWriteFile(t, filepath.Join(subagentsDir, "agent-a12eb64.jsonl"), []byte(`{"uuid":"1","sessionId":"test","type":"user"}
+{"uuid":"2","sessionId":"te
+{"uuid":"3","sessionId":"test","type":"assistant"}
+`))
+
+	agents, err := DiscoverAgents(sessionDir)
+	if err != nil {
+		t.Fatalf("DiscoverAgents() error: %v", err)
+	}
+	if len(agents) != 1 || agents[0].EntryCount != 2 || agents[0].ParseErrors != 1 || agents[0].SessionID != "test" {
+		t.Errorf("DiscoverAgents() = %+v, want 2 entries of session test and 1 parse error", agents)
+	}
+
+	tree, err := BuildNestedTree(filepath.Dir(sessionDir), filepath.Base(sessionDir))
+	if err != nil {
+		t.Fatalf("BuildNestedTree() error: %v", err)
+	}
+	if len(tree.Children) != 1 || tree.Children[0].EntryCount != 2 || tree.Children[0].ParseErrors != 1 {
+		t.Errorf("BuildNestedTree() children = %+v, want the agent with 2 entries and 1 parse error", tree.Children)
+	}
+}
+
 func TestFindAgentSpawns(t *testing.T) {
 	tmpDir := t.TempDir()
 	sessionFile := filepath.Join(tmpDir, "session.jsonl")
@@ -753,3 +856,93 @@ func TestBuildNestedTree_MissingSubagentsDirectory(t *testing.T) {
 		t.Errorf("Root has %d children, want 0 (subagents dir missing)", len(tree.Children))
 	}
 }
+
+func TestNormalizeAgentID(t *testing.T) {
+	tests := []struct {
+		agentID   string
+		wantShort string
+		wantLabel string
+	}{
+		{"a12eb64", "a12eb64", ""},
+		{"a12eb64f9c0d1e2", "a12eb64f", ""},
+		{"aprompt_suggestion-abc123", "abc123", "Prompt suggestion"},
+		{"aexplore-def456789abc", "def45678", "Explore"},
+		{"  a12eb64  ", "a12eb64", ""},
+		{"a1 2\"<b>\n", "a12\"<b>", ""},
+		{"agent-éèü", "agent-éè", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.agentID, func(t *testing.T) {
+			short, label := NormalizeAgentID(tt.agentID)
+			if short != tt.wantShort || label != tt.wantLabel {
+				t.Errorf("NormalizeAgentID(%q) = (%q, %q), want (%q, %q)",
+					tt.agentID, short, label, tt.wantShort, tt.wantLabel)
+			}
+		})
+	}
+}
+
+func TestNormalizeAgentIDLength(t *testing.T) {
+	tests := []struct {
+		agentID   string
+		length    int
+		wantShort string
+		wantLabel string
+	}{
+		{"a12eb64f9c0d1e2", 10, "a12eb64f9c", ""},
+		{"a12eb64f9c0d1e2", 0, "a12eb64f9c0d1e2", ""},
+		{"a12eb64f9c0d1e2", -1, "a12eb64f9c0d1e2", ""},
+		{"aexplore-def456789abc", 0, "def456789abc", "Explore"},
+		{"agent-éèüö", 9, "agent-éèü", ""},
+	}
+
+	for _, tt := range tests {
+		short, label := NormalizeAgentIDLength(tt.agentID, tt.length)
+		if short != tt.wantShort || label != tt.wantLabel {
+			t.Errorf("NormalizeAgentIDLength(%q, %d) = (%q, %q), want (%q, %q)",
+				tt.agentID, tt.length, short, label, tt.wantShort, tt.wantLabel)
+		}
+	}
+}
+
+func TestNormalizeAgentID_PreservesParseAgentTypeMappings(t *testing.T) {
+	for agentType := range agentTypeLabels {
+		id := "a" + agentType + "-abc"
+		if got := parseAgentType(id); got != agentType {
+			t.Errorf("parseAgentType(%q) = %q, want %q", id, got, agentType)
+		}
+		if _, label := NormalizeAgentID(id); label == "" {
+			t.Errorf("NormalizeAgentID(%q) should return a type label", id)
+		}
+	}
+}
+
+func TestFindAgentSpawns_LegacyQueueOperations(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionFile := filepath.Join(tmpDir, "session.jsonl")
+
+	// a12eb64 has both spawn formats; a68b8c0 only a legacy queue operation
+	content := `{"uuid":"1","type":"user"}` + "\n"
+	content += createAgentSpawnEntry("2", "test-session", "a12eb64", "assistant-1")
+	content += `{"uuid":"2b","type":"queue-operation","agentId":"a12eb64"}` + "\n"
+	content += `{"uuid":"3","type":"queue-operation","agentId":"a68b8c0"}` + "\n"
+
+	mustWriteFile(t, sessionFile, []byte(content))
+
+	spawns, err := FindAgentSpawns(sessionFile)
+	if err != nil {
+		t.Fatalf("FindAgentSpawns() error: %v", err)
+	}
+
+	if len(spawns) != 2 {
+		t.Errorf("FindAgentSpawns() found %d spawns, want 2", len(spawns))
+	}
+	if spawns["a12eb64"] != "2" {
+		t.Errorf("Spawn for a12eb64 = %q, want the toolUseResult entry '2'", spawns["a12eb64"])
+	}
+	if spawns["a68b8c0"] != "3" {
+		t.Errorf("Spawn for a68b8c0 = %q, want '3'", spawns["a68b8c0"])
+	}
+}
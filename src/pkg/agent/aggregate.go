@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// TreeAggregate sums the activity of an agent tree: the main session and every agent
+// nested in it.
+type TreeAggregate struct {
+	Nodes     int       // Distinct nodes counted, the root included
+	Entries   int       // Entries across all counted nodes
+	ToolCalls int       // Tool calls made by assistant entries across all counted nodes
+	Start     time.Time // Earliest entry timestamp (zero if no entry has one)
+	End       time.Time // Latest entry timestamp (zero if no entry has one)
+}
+
+// Span returns the time between the earliest and latest entry of the tree, or 0 if
+// the entries have no timestamps.
+func (a TreeAggregate) Span() time.Duration {
+	if a.Start.IsZero() || a.End.IsZero() {
+		return 0
+	}
+	return a.End.Sub(a.Start)
+}
+
+// AggregateTree sums the entries, tool calls and time span of every node in the tree
+// under root, reading each node's entries from entriesByAgent as returned by
+// GroupByAgent (the root's under MainAgentID). A node without entries in the map, such
+// as an agent whose spawn failed, contributes its EntryCount only. Each agent is
+// counted once: a node reached again through a cycle in Children, or a second node
+// with the same agent ID, is skipped along with its children.
+func AggregateTree(root *TreeNode, entriesByAgent map[string][]models.ConversationEntry) TreeAggregate {
+	var agg TreeAggregate
+	visited := make(map[string]bool)
+	aggregateNode(root, entriesByAgent, visited, &agg)
+	return agg
+}
+
+func aggregateNode(node *TreeNode, entriesByAgent map[string][]models.ConversationEntry, visited map[string]bool, agg *TreeAggregate) {
+	if node == nil {
+		return
+	}
+	key := node.AgentID
+	if node.IsRoot {
+		key = MainAgentID
+	}
+	if visited[key] {
+		return
+	}
+	visited[key] = true
+
+	agg.Nodes++
+	entries, ok := entriesByAgent[key]
+	if !ok {
+		agg.Entries += node.EntryCount
+	}
+	agg.Entries += len(entries)
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Type == models.EntryTypeAssistant {
+			agg.ToolCalls += len(entry.ExtractToolCalls())
+		}
+		if entry.Timestamp == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
+		if err != nil {
+			continue
+		}
+		if agg.Start.IsZero() || t.Before(agg.Start) {
+			agg.Start = t
+		}
+		if agg.End.IsZero() || t.After(agg.End) {
+			agg.End = t
+		}
+	}
+
+	for _, child := range node.Children {
+		aggregateNode(child, entriesByAgent, visited, agg)
+	}
+}
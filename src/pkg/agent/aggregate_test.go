@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func aggregateEntry(typ models.EntryType, timestamp, message string) models.ConversationEntry {
+	return models.ConversationEntry{Type: typ, Timestamp: timestamp, Message: json.RawMessage(message)}
+}
+
+func TestAggregateTree(t *testing.T) {
+	toolCall := `{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Read","input":{}},{"type":"tool_use","id":"t2","name":"Bash","input":{}}]}`
+	grouped := map[string][]models.ConversationEntry{
+		MainAgentID: {
+			aggregateEntry(models.EntryTypeUser, "2026-01-01T10:00:00Z", `"go"`),
+			aggregateEntry(models.EntryTypeAssistant, "2026-01-01T10:05:00Z", toolCall),
+		},
+		"a1": {
+			aggregateEntry(models.EntryTypeUser, "2026-01-01T10:01:00Z", `"task"`),
+			aggregateEntry(models.EntryTypeAssistant, "", toolCall),
+		},
+		"a2": {
+			aggregateEntry(models.EntryTypeAssistant, "2026-01-01T10:20:00Z", toolCall),
+		},
+	}
+	nested := &TreeNode{AgentID: "a2", EntryCount: 1}
+	failed := &TreeNode{AgentID: "a3", EntryCount: 4, SpawnStatus: "failed"}
+	root := &TreeNode{IsRoot: true, Children: []*TreeNode{
+		{AgentID: "a1", EntryCount: 2, Children: []*TreeNode{nested}},
+		failed,
+	}}
+
+	agg := AggregateTree(root, grouped)
+	if agg.Nodes != 4 || agg.Entries != 9 || agg.ToolCalls != 6 {
+		t.Errorf("AggregateTree() = %+v, want 4 nodes, 9 entries, 6 tool calls", agg)
+	}
+	if agg.Span() != 20*time.Minute {
+		t.Errorf("Span() = %v, want 20m from the main session start to the nested agent's end", agg.Span())
+	}
+}
+
+func TestAggregateTree_Cycles(t *testing.T) {
+	grouped := map[string][]models.ConversationEntry{
+		MainAgentID: {aggregateEntry(models.EntryTypeUser, "", `"go"`)},
+		"a1":        {aggregateEntry(models.EntryTypeUser, "", `"a"`)},
+		"a2":        {aggregateEntry(models.EntryTypeUser, "", `"b"`)},
+	}
+	root := &TreeNode{IsRoot: true}
+	a1 := &TreeNode{AgentID: "a1"}
+	a2 := &TreeNode{AgentID: "a2"}
+	// a2 points back to a1 and the root, and a1 also appears twice
+	root.Children = []*TreeNode{a1, {AgentID: "a1"}}
+	a1.Children = []*TreeNode{a2}
+	a2.Children = []*TreeNode{a1, root}
+
+	agg := AggregateTree(root, grouped)
+	if agg.Nodes != 3 || agg.Entries != 3 {
+		t.Errorf("AggregateTree() = %+v, want each of the 3 agents counted once", agg)
+	}
+	if agg.Span() != 0 {
+		t.Errorf("Span() = %v, want 0 without timestamps", agg.Span())
+	}
+
+	if agg := AggregateTree(nil, grouped); agg != (TreeAggregate{}) {
+		t.Errorf("AggregateTree(nil) = %+v, want zero", agg)
+	}
+}
+
+func TestAggregateTree_GroupByAgent(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionID := "679761ba-80c0-4cd3-a586-cc6a1fc56308"
+
+	sessionContent := `{"uuid":"main-1","sessionId":"` + sessionID + `","type":"user"}` + "\n"
+	sessionContent += `{"uuid":"main-2","sessionId":"` + sessionID + `","type":"assistant"}` + "\n"
+	sessionContent += createAgentSpawnEntry("spawn-a12", sessionID, "a12eb64", "main-2")
+	mustWriteFile(t, filepath.Join(tmpDir, sessionID+".jsonl"), []byte(sessionContent))
+
+	subagentsDir := filepath.Join(tmpDir, sessionID, "subagents")
+	mustMkdirAll(t, subagentsDir)
+	mustWriteFile(t, filepath.Join(subagentsDir, "agent-a12eb64.jsonl"), []byte(`{"uuid":"a1-1","type":"user"}
+{"uuid":"a1-2","type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Read","input":{}}]}}
+`))
+
+	tree, err := BuildNestedTree(tmpDir, sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grouped, err := GroupByAgent(tmpDir, sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	agg := AggregateTree(tree, grouped)
+	if agg.Entries != CountTotalEntries(tree) {
+		t.Errorf("AggregateTree() entries = %d, CountTotalEntries() = %d", agg.Entries, CountTotalEntries(tree))
+	}
+	if agg.Nodes != 2 || agg.ToolCalls != 1 {
+		t.Errorf("AggregateTree() = %+v, want 2 nodes and 1 tool call", agg)
+	}
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+	"github.com/randlee/claude-history/pkg/paths"
+)
+
+// MainAgentID is the key of the main session's entries in the map returned by GroupByAgent.
+const MainAgentID = ""
+
+// GroupByAgent reads the entries of a session and of every agent it spawned, keyed by
+// agent ID, with the main session's entries under MainAgentID. Agents are found the way
+// DiscoverAgents finds them for BuildNestedTree, so nested agents are included. An error
+// is returned if the session file or any agent file cannot be read.
+func GroupByAgent(projectDir, sessionID string) (map[string][]models.ConversationEntry, error) {
+	sessionPath := paths.JSONLFile(filepath.Join(projectDir, sessionID))
+	mainEntries, err := ReadAgentEntries(sessionPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	agents, err := DiscoverAgents(filepath.Join(projectDir, sessionID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover agents: %w", err)
+	}
+
+	grouped := make(map[string][]models.ConversationEntry, len(agents)+1)
+	grouped[MainAgentID] = mainEntries
+	for _, agent := range agents {
+		entries, err := ReadAgentEntries(agent.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read agent %s: %w", agent.ID, err)
+		}
+		grouped[agent.ID] = entries
+	}
+
+	return grouped, nil
+}
+
+// FlattenSession returns the entries of a session and of every agent it spawned (see
+// GroupByAgent) as one stream sorted by timestamp, each with its AgentID set to the
+// agent it came from (MainAgentID for the main session). An entry without a valid
+// timestamp sorts as if it had the timestamp of the entry before it in its own file,
+// so it stays right after that entry (entries before any timestamp come first). Entries with equal timestamps keep the order of
+// the main session first, then the agents by ID, then their order within each file.
+func FlattenSession(projectDir, sessionID string) ([]models.ConversationEntry, error) {
+	grouped, err := GroupByAgent(projectDir, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	agentIDs := make([]string, 0, len(grouped))
+	for agentID := range grouped {
+		if agentID != MainAgentID {
+			agentIDs = append(agentIDs, agentID)
+		}
+	}
+	sort.Strings(agentIDs)
+	agentIDs = append([]string{MainAgentID}, agentIDs...)
+
+	type timedEntry struct {
+		entry models.ConversationEntry
+		at    time.Time // The entry's timestamp, or the last one before it in its file
+	}
+	var timed []timedEntry
+	for _, agentID := range agentIDs {
+		var last time.Time
+		for _, entry := range grouped[agentID] {
+			if ts, err := entry.GetTimestamp(); err == nil {
+				last = ts
+			}
+			entry.AgentID = agentID
+			timed = append(timed, timedEntry{entry: entry, at: last})
+		}
+	}
+	sort.SliceStable(timed, func(i, j int) bool { return timed[i].at.Before(timed[j].at) })
+
+	entries := make([]models.ConversationEntry, len(timed))
+	for i, t := range timed {
+		entries[i] = t.entry
+	}
+	return entries, nil
+}
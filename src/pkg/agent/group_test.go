@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGroupByAgent_NestedAgents(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionID := "679761ba-80c0-4cd3-a586-cc6a1fc56308"
+
+	// Main session spawns agent-a12eb64
+	sessionContent := `{"uuid":"main-1","sessionId":"` + sessionID + `","type":"user"}` + "\n"
+	sessionContent += `{"uuid":"main-2","sessionId":"` + sessionID + `","type":"assistant"}` + "\n"
+	sessionContent += createAgentSpawnEntry("spawn-a12", sessionID, "a12eb64", "main-2")
+	mustWriteFile(t, filepath.Join(tmpDir, sessionID+".jsonl"), []byte(sessionContent))
+
+	subagentsDir := filepath.Join(tmpDir, sessionID, "subagents")
+	mustMkdirAll(t, subagentsDir)
+
+	// Agent a12eb64 spawns nested-child, stored in its own nested subagents directory
+	agent1Content := `{"uuid":"a1-1","type":"user"}` + "\n"
+	agent1Content += createAgentSpawnEntry("spawn-nested", sessionID, "nested-child", "a12eb64")
+	mustWriteFile(t, filepath.Join(subagentsDir, "agent-a12eb64.jsonl"), []byte(agent1Content))
+
+	nestedDir := filepath.Join(subagentsDir, "agent-a12eb64", "subagents")
+	mustMkdirAll(t, nestedDir)
+	mustWriteFile(t, filepath.Join(nestedDir, "agent-nested-child.jsonl"), []byte(`{"uuid":"n1","type":"user"}
+{"uuid":"n2","type":"assistant"}
+`))
+
+	grouped, err := GroupByAgent(tmpDir, sessionID)
+	if err != nil {
+		t.Fatalf("GroupByAgent() error: %v", err)
+	}
+
+	if len(grouped) != 3 {
+		t.Fatalf("GroupByAgent() returned %d groups, want 3: %v", len(grouped), grouped)
+	}
+
+	tests := []struct {
+		agentID string
+		uuids   []string
+	}{
+		{MainAgentID, []string{"main-1", "main-2", "spawn-a12"}},
+		{"a12eb64", []string{"a1-1", "spawn-nested"}},
+		{"nested-child", []string{"n1", "n2"}},
+	}
+	for _, tt := range tests {
+		entries, ok := grouped[tt.agentID]
+		if !ok {
+			t.Errorf("missing group %q", tt.agentID)
+			continue
+		}
+		if len(entries) != len(tt.uuids) {
+			t.Errorf("group %q has %d entries, want %d", tt.agentID, len(entries), len(tt.uuids))
+			continue
+		}
+		for i, uuid := range tt.uuids {
+			if entries[i].UUID != uuid {
+				t.Errorf("group %q entry %d UUID = %q, want %q", tt.agentID, i, entries[i].UUID, uuid)
+			}
+		}
+	}
+}
+
+func TestGroupByAgent_NoSubagents(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionID := "test-session"
+
+	mustWriteFile(t, filepath.Join(tmpDir, sessionID+".jsonl"), []byte(`{"uuid":"1","type":"user"}
+{"uuid":"2","type":"assistant"}
+`))
+
+	grouped, err := GroupByAgent(tmpDir, sessionID)
+	if err != nil {
+		t.Fatalf("GroupByAgent() error: %v", err)
+	}
+	if len(grouped) != 1 {
+		t.Errorf("GroupByAgent() returned %d groups, want only the main session", len(grouped))
+	}
+	if len(grouped[MainAgentID]) != 2 {
+		t.Errorf("main session has %d entries, want 2", len(grouped[MainAgentID]))
+	}
+}
+
+func TestGroupByAgent_MissingSession(t *testing.T) {
+	if _, err := GroupByAgent(t.TempDir(), "missing-session"); err == nil {
+		t.Error("GroupByAgent() should fail when the session file does not exist")
+	}
+}
+
+func TestFlattenSession_NestedAgents(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionID := "679761ba-80c0-4cd3-a586-cc6a1fc56308"
+
+	entry := func(uuid, timestamp string) string {
+		if timestamp == "" {
+			return `{"uuid":"` + uuid + `","type":"user"}` + "\n"
+		}
+		return `{"uuid":"` + uuid + `","type":"user","timestamp":"` + timestamp + `"}` + "\n"
+	}
+
+	// The main session spawns a12eb64 at 10:00:00, which spawns nested-child
+	sessionContent := entry("main-1", "2026-01-15T09:59:00Z")
+	sessionContent += createAgentSpawnEntry("spawn-a12", sessionID, "a12eb64", "main-1")
+	sessionContent += entry("main-2", "2026-01-15T10:05:00Z")
+	sessionContent += entry("main-3", "")
+	mustWriteFile(t, filepath.Join(tmpDir, sessionID+".jsonl"), []byte(sessionContent))
+
+	subagentsDir := filepath.Join(tmpDir, sessionID, "subagents")
+	mustMkdirAll(t, subagentsDir)
+	agentContent := entry("a1-1", "2026-01-15T10:01:00Z")
+	agentContent += entry("a1-2", "")
+	agentContent += entry("a1-3", "2026-01-15T10:03:00Z")
+	mustWriteFile(t, filepath.Join(subagentsDir, "agent-a12eb64.jsonl"), []byte(agentContent))
+
+	nestedDir := filepath.Join(subagentsDir, "agent-a12eb64", "subagents")
+	mustMkdirAll(t, nestedDir)
+	mustWriteFile(t, filepath.Join(nestedDir, "agent-nested-child.jsonl"), []byte(
+		entry("n1", "2026-01-15T10:02:00Z")+entry("n2", "2026-01-15T10:03:00Z")))
+
+	entries, err := FlattenSession(tmpDir, sessionID)
+	if err != nil {
+		t.Fatalf("FlattenSession() error: %v", err)
+	}
+
+	want := []struct{ uuid, agentID string }{
+		{"main-1", MainAgentID},
+		{"spawn-a12", MainAgentID},
+		{"a1-1", "a12eb64"},
+		{"a1-2", "a12eb64"}, // No timestamp: stays after a1-1
+		{"n1", "nested-child"},
+		{"a1-3", "a12eb64"}, // Ties with n2: a12eb64 sorts before nested-child
+		{"n2", "nested-child"},
+		{"main-2", MainAgentID},
+		{"main-3", MainAgentID},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("FlattenSession() returned %d entries, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		if entries[i].UUID != w.uuid || entries[i].AgentID != w.agentID {
+			t.Errorf("entry %d = %s (agent %q), want %s (agent %q)", i, entries[i].UUID, entries[i].AgentID, w.uuid, w.agentID)
+		}
+	}
+}
+
+func TestFlattenSession_MissingSession(t *testing.T) {
+	if _, err := FlattenSession(t.TempDir(), "missing-session"); err == nil {
+		t.Error("FlattenSession() should fail when the session file does not exist")
+	}
+}
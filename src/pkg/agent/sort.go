@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SortMode selects how SortChildren orders the children of each tree node.
+type SortMode int
+
+const (
+	// SortBySpawnTime orders agents by when they were spawned, earliest first.
+	// Agents with an unknown spawn time are placed after all others.
+	SortBySpawnTime SortMode = iota
+	// SortByEntryCount orders agents by entry count, largest first.
+	SortByEntryCount
+)
+
+// sortModeNames maps command-line names to sort modes.
+var sortModeNames = map[string]SortMode{
+	"spawn":   SortBySpawnTime,
+	"entries": SortByEntryCount,
+}
+
+// String returns the command-line name of the sort mode.
+func (m SortMode) String() string {
+	for name, mode := range sortModeNames {
+		if mode == m {
+			return name
+		}
+	}
+	return fmt.Sprintf("SortMode(%d)", int(m))
+}
+
+// ParseSortMode converts a name ("spawn" or "entries") to a SortMode.
+func ParseSortMode(name string) (SortMode, error) {
+	mode, ok := sortModeNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, fmt.Errorf("unknown agent sort order %q (valid: entries, spawn)", name)
+	}
+	return mode, nil
+}
+
+// SortChildren reorders the children of every node in the tree, recursively.
+// The sort is stable, so agents that tie keep their discovery order.
+func SortChildren(tree *TreeNode, by SortMode) {
+	if tree == nil {
+		return
+	}
+
+	children := tree.Children
+	switch by {
+	case SortByEntryCount:
+		sort.SliceStable(children, func(i, j int) bool {
+			return children[i].EntryCount > children[j].EntryCount
+		})
+	default:
+		sort.SliceStable(children, func(i, j int) bool {
+			a, b := children[i].SpawnTime, children[j].SpawnTime
+			if a.IsZero() || b.IsZero() {
+				return !a.IsZero() && b.IsZero()
+			}
+			return a.Before(b)
+		})
+	}
+
+	for _, child := range children {
+		SortChildren(child, by)
+	}
+}
@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// childIDs returns the agent IDs of a node's children in order.
+func childIDs(node *TreeNode) string {
+	var ids []string
+	for _, child := range node.Children {
+		ids = append(ids, child.AgentID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestSortChildren_BySpawnTime(t *testing.T) {
+	base := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	tree := &TreeNode{IsRoot: true, Children: []*TreeNode{
+		{AgentID: "c", SpawnTime: base.Add(3 * time.Minute)},
+		{AgentID: "unknown"},
+		{AgentID: "a", SpawnTime: base.Add(1 * time.Minute), Children: []*TreeNode{
+			{AgentID: "a2", SpawnTime: base.Add(5 * time.Minute)},
+			{AgentID: "a1", SpawnTime: base.Add(2 * time.Minute)},
+		}},
+		{AgentID: "b", SpawnTime: base.Add(1 * time.Minute)},
+	}}
+
+	SortChildren(tree, SortBySpawnTime)
+
+	// Ties (a, b) keep discovery order; unknown spawn times go last
+	if got := childIDs(tree); got != "a,b,c,unknown" {
+		t.Errorf("root children = %s, want a,b,c,unknown", got)
+	}
+	if got := childIDs(tree.Children[0]); got != "a1,a2" {
+		t.Errorf("nested children = %s, want a1,a2 (sort should be recursive)", got)
+	}
+}
+
+func TestSortChildren_ByEntryCount(t *testing.T) {
+	tree := &TreeNode{IsRoot: true, Children: []*TreeNode{
+		{AgentID: "small", EntryCount: 2},
+		{AgentID: "big", EntryCount: 50, Children: []*TreeNode{
+			{AgentID: "x", EntryCount: 1},
+			{AgentID: "y", EntryCount: 9},
+			{AgentID: "z", EntryCount: 9},
+		}},
+		{AgentID: "medium", EntryCount: 10},
+		{AgentID: "medium2", EntryCount: 10},
+	}}
+
+	SortChildren(tree, SortByEntryCount)
+
+	if got := childIDs(tree); got != "big,medium,medium2,small" {
+		t.Errorf("root children = %s, want big,medium,medium2,small", got)
+	}
+	if got := childIDs(tree.Children[0]); got != "y,z,x" {
+		t.Errorf("nested children = %s, want y,z,x", got)
+	}
+}
+
+func TestSortChildren_Nil(t *testing.T) {
+	SortChildren(nil, SortBySpawnTime) // must not panic
+	SortChildren(&TreeNode{IsRoot: true}, SortByEntryCount)
+}
+
+func TestParseSortMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    SortMode
+		wantErr bool
+	}{
+		{"spawn", SortBySpawnTime, false},
+		{"entries", SortByEntryCount, false},
+		{" Entries ", SortByEntryCount, false},
+		{"size", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSortMode(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSortMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseSortMode(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+
+	if SortByEntryCount.String() != "entries" || SortBySpawnTime.String() != "spawn" {
+		t.Errorf("String() should round-trip names, got %q and %q", SortByEntryCount, SortBySpawnTime)
+	}
+}
+
+func TestBuildSpawnInfoMap_RecordsSpawnTime(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionID := "test-session-123"
+	sessionFile := filepath.Join(tmpDir, sessionID+".jsonl")
+	mustWriteFile(t, sessionFile, []byte(createToolUseResultEntry("spawn-1", sessionID, "agent-alpha", "assistant-1", "async_launched")))
+
+	spawnMap := buildSpawnInfoMap(sessionFile, filepath.Join(tmpDir, sessionID), nil)
+
+	want := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	if info := spawnMap["agent-alpha"]; info == nil || !info.SpawnTime.Equal(want) {
+		t.Errorf("SpawnTime = %+v, want %v", info, want)
+	}
+}
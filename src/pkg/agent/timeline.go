@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"sort"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// AgentSpan is the active time range of a single agent, from its first to its last entry.
+type AgentSpan struct {
+	AgentID    string    `json:"agentId"`
+	AgentType  string    `json:"agentType,omitempty"`
+	Depth      int       `json:"depth"` // 1 for agents spawned by the main session
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	EntryCount int       `json:"entryCount"`
+}
+
+// Duration returns the length of the span (zero for single-entry agents).
+func (s AgentSpan) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// AgentTimeSpans computes the active time span of every agent in the tree.
+// entriesByAgent maps agent IDs to their entries; agents without any parseable
+// timestamps are omitted. The root session node is not included.
+// Spans are sorted by start time, then by agent ID.
+func AgentTimeSpans(tree *TreeNode, entriesByAgent map[string][]models.ConversationEntry) []AgentSpan {
+	if tree == nil {
+		return nil
+	}
+
+	var spans []AgentSpan
+	var walk func(node *TreeNode, depth int)
+	walk = func(node *TreeNode, depth int) {
+		if !node.IsRoot && node.AgentID != "" {
+			if span, ok := agentSpan(node.AgentID, entriesByAgent[node.AgentID]); ok {
+				span.AgentType = node.AgentType
+				span.Depth = depth
+				spans = append(spans, span)
+			}
+		}
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+
+	// The root node represents the main session; its children are depth 1
+	if tree.IsRoot {
+		for _, child := range tree.Children {
+			walk(child, 1)
+		}
+	} else {
+		walk(tree, 1)
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		if !spans[i].Start.Equal(spans[j].Start) {
+			return spans[i].Start.Before(spans[j].Start)
+		}
+		return spans[i].AgentID < spans[j].AgentID
+	})
+	return spans
+}
+
+// agentSpan finds the earliest and latest timestamps in an agent's entries.
+func agentSpan(agentID string, entries []models.ConversationEntry) (AgentSpan, bool) {
+	span := AgentSpan{AgentID: agentID, EntryCount: len(entries)}
+	found := false
+	for i := range entries {
+		ts, err := entries[i].GetTimestamp()
+		if err != nil || ts.IsZero() {
+			continue
+		}
+		if !found || ts.Before(span.Start) {
+			span.Start = ts
+		}
+		if !found || ts.After(span.End) {
+			span.End = ts
+		}
+		found = true
+	}
+	return span, found
+}
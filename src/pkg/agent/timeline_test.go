@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func timelineEntries(timestamps ...string) []models.ConversationEntry {
+	entries := make([]models.ConversationEntry, 0, len(timestamps))
+	for _, ts := range timestamps {
+		entries = append(entries, models.ConversationEntry{Type: models.EntryTypeAssistant, Timestamp: ts})
+	}
+	return entries
+}
+
+func TestAgentTimeSpans(t *testing.T) {
+	tree := &TreeNode{
+		IsRoot: true,
+		Children: []*TreeNode{
+			{AgentID: "agent-b", Children: []*TreeNode{
+				{AgentID: "agent-c", AgentType: "explore"},
+			}},
+			{AgentID: "agent-a"},
+		},
+	}
+	entriesByAgent := map[string][]models.ConversationEntry{
+		"agent-a": timelineEntries("2026-02-01T10:00:05Z", "2026-02-01T10:00:01Z", "2026-02-01T10:00:03Z"),
+		"agent-b": timelineEntries("2026-02-01T10:00:02Z", "2026-02-01T10:00:10Z"),
+		"agent-c": timelineEntries("2026-02-01T10:00:04Z"),
+	}
+
+	spans := AgentTimeSpans(tree, entriesByAgent)
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+
+	wantOrder := []string{"agent-a", "agent-b", "agent-c"}
+	for i, id := range wantOrder {
+		if spans[i].AgentID != id {
+			t.Errorf("spans[%d].AgentID = %q, want %q (sorted by start)", i, spans[i].AgentID, id)
+		}
+	}
+
+	a := spans[0]
+	if !a.Start.Equal(time.Date(2026, 2, 1, 10, 0, 1, 0, time.UTC)) || !a.End.Equal(time.Date(2026, 2, 1, 10, 0, 5, 0, time.UTC)) {
+		t.Errorf("agent-a span = %v – %v, want 10:00:01 – 10:00:05", a.Start, a.End)
+	}
+	if a.EntryCount != 3 || a.Depth != 1 {
+		t.Errorf("agent-a EntryCount=%d Depth=%d, want 3 and 1", a.EntryCount, a.Depth)
+	}
+
+	c := spans[2]
+	if c.Depth != 2 || c.AgentType != "explore" {
+		t.Errorf("agent-c Depth=%d AgentType=%q, want 2 and explore", c.Depth, c.AgentType)
+	}
+	if c.Duration() != 0 {
+		t.Errorf("single-entry agent Duration() = %v, want 0", c.Duration())
+	}
+}
+
+func TestAgentTimeSpans_SkipsAgentsWithoutTimestamps(t *testing.T) {
+	tree := &TreeNode{
+		IsRoot: true,
+		Children: []*TreeNode{
+			{AgentID: "no-entries"},
+			{AgentID: "bad-timestamps"},
+			{AgentID: "ok"},
+		},
+	}
+	entriesByAgent := map[string][]models.ConversationEntry{
+		"bad-timestamps": timelineEntries("", "not a time"),
+		"ok":             timelineEntries("", "2026-02-01T10:00:00Z"),
+	}
+
+	spans := AgentTimeSpans(tree, entriesByAgent)
+	if len(spans) != 1 || spans[0].AgentID != "ok" {
+		t.Fatalf("expected only the agent with timestamps, got %+v", spans)
+	}
+	if spans[0].EntryCount != 2 {
+		t.Errorf("EntryCount = %d, want 2", spans[0].EntryCount)
+	}
+}
+
+func TestAgentTimeSpans_NilTree(t *testing.T) {
+	if spans := AgentTimeSpans(nil, nil); spans != nil {
+		t.Errorf("AgentTimeSpans(nil) = %v, want nil", spans)
+	}
+}
@@ -2,6 +2,8 @@ package agent
 
 import (
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/randlee/claude-history/internal/jsonl"
 	"github.com/randlee/claude-history/pkg/models"
@@ -19,13 +21,39 @@ type TreeNode struct {
 	Children   []*TreeNode `json:"children,omitempty"`
 	ParentUUID string      `json:"parentUuid,omitempty"` // UUID of parent agent or main session
 	UUID       string      `json:"uuid,omitempty"`       // UUID of the entry that spawned this agent
+	SpawnTime  time.Time   `json:"-"`                    // Timestamp of the spawn entry (zero if unknown)
+
+	// Description is what the agent was spawned to do, from the Task call's description
+	// (or the first line of its prompt); empty if the spawn entry did not record it.
+	Description string `json:"description,omitempty"`
+
+	// DepthLimited marks an agent nested deeper than the tree's maximum depth, attached
+	// to its deepest allowed ancestor instead (see BuildNestedTreeWithDepth).
+	DepthLimited bool `json:"depthLimited,omitempty"`
+
+	// SpawnStatus is the status of the agent's spawn when it failed ("failed" or
+	// "error", see SpawnFailureStatus); empty for agents spawned successfully.
+	SpawnStatus string `json:"spawnStatus,omitempty"`
+
+	// ParseErrors counts the lines of the agent's file that could not be parsed (see
+	// ReadAgentFile); EntryCount counts only the entries that could. Non-zero marks an
+	// agent that is only partially loaded.
+	ParseErrors int `json:"parseErrors,omitempty"`
 }
 
 // SpawnInfo contains information about agent spawn relationships.
 type SpawnInfo struct {
-	AgentID    string // The ID of the spawned agent
-	SpawnUUID  string // UUID of the user entry that contains the spawn result
-	ParentUUID string // UUID of the assistant message that triggered the spawn (sourceToolAssistantUUID)
+	AgentID    string    // The ID of the spawned agent
+	SpawnUUID  string    // UUID of the user entry that contains the spawn result
+	ParentUUID string    // UUID of the assistant message that triggered the spawn (sourceToolAssistantUUID)
+	SpawnTime  time.Time // Timestamp of the spawn entry (zero if unparseable)
+
+	// Description is the spawning Task call's description, or the first line of its
+	// prompt (see spawnDescription).
+	Description string
+
+	// Status is the status of a failed spawn (see SpawnFailureStatus), or empty.
+	Status string
 }
 
 // BuildTree constructs an agent hierarchy tree for a session.
@@ -37,8 +65,17 @@ func BuildTree(projectDir string, sessionID string) (*TreeNode, error) {
 
 // BuildNestedTree constructs a properly nested agent hierarchy tree for a session.
 // It uses toolUseResult from user entries to detect agent spawns and build parent-child relationships.
+// Nesting is unlimited; see BuildNestedTreeWithDepth.
 func BuildNestedTree(projectDir string, sessionID string) (*TreeNode, error) {
-	sessionPath := filepath.Join(projectDir, sessionID+".jsonl")
+	return BuildNestedTreeWithDepth(projectDir, sessionID, 0)
+}
+
+// BuildNestedTreeWithDepth constructs an agent tree like BuildNestedTree, nesting agents at
+// most maxDepth levels below the main session (0 means unlimited). Agents nested deeper
+// are attached directly to their ancestor at maxDepth, in depth-first order, and marked
+// DepthLimited. Every discovered agent stays in the tree, so CountTotalEntries is unchanged.
+func BuildNestedTreeWithDepth(projectDir string, sessionID string, maxDepth int) (*TreeNode, error) {
+	sessionPath := paths.JSONLFile(filepath.Join(projectDir, sessionID))
 	sessionDir := filepath.Join(projectDir, sessionID)
 
 	// Create root node for the main session
@@ -78,17 +115,21 @@ func BuildNestedTree(projectDir string, sessionID string) (*TreeNode, error) {
 
 	for _, agent := range agents {
 		node := &TreeNode{
-			AgentID:    agent.ID,
-			SessionID:  agent.SessionID,
-			FilePath:   agent.FilePath,
-			EntryCount: agent.EntryCount,
-			AgentType:  agent.AgentType,
+			AgentID:     agent.ID,
+			SessionID:   agent.SessionID,
+			FilePath:    agent.FilePath,
+			EntryCount:  agent.EntryCount,
+			AgentType:   agent.AgentType,
+			ParseErrors: agent.ParseErrors,
 		}
 
 		// Get spawn info for this agent
 		if info, ok := spawnInfoMap[agent.ID]; ok {
 			node.UUID = info.SpawnUUID
 			node.ParentUUID = info.ParentUUID
+			node.SpawnTime = info.SpawnTime
+			node.Description = info.Description
+			node.SpawnStatus = info.Status
 		}
 
 		nodeMap[agent.ID] = node
@@ -108,23 +149,44 @@ func BuildNestedTree(projectDir string, sessionID string) (*TreeNode, error) {
 		parent.Children = append(parent.Children, node)
 	}
 
+	if maxDepth > 0 {
+		limitDepth(root, 0, maxDepth)
+	}
+
 	return root, nil
 }
 
+// limitDepth flattens the subtrees below maxDepth: each node at maxDepth takes all of its
+// descendants as direct children, marked DepthLimited.
+func limitDepth(node *TreeNode, depth, maxDepth int) {
+	if depth < maxDepth {
+		for _, child := range node.Children {
+			limitDepth(child, depth+1, maxDepth)
+		}
+		return
+	}
+
+	var descendants []*TreeNode
+	for _, child := range node.Children {
+		descendants = append(descendants, FlattenTree(child)...)
+	}
+	for _, d := range descendants {
+		d.Children = nil
+		d.DepthLimited = true
+	}
+	node.Children = descendants
+}
+
 // buildSpawnInfoMap extracts spawn information from session and agent files.
-// It looks for user entries with toolUseResult where status is "async_launched".
+// It looks for user entries with toolUseResult where status is "async_launched", or
+// where the spawn failed (see SpawnFailureStatus).
 func buildSpawnInfoMap(sessionPath string, sessionDir string, agents []models.Agent) map[string]*SpawnInfo {
 	result := make(map[string]*SpawnInfo)
 
 	// Scan main session for agent spawns (user entries with toolUseResult)
 	_ = jsonl.ScanInto(sessionPath, func(entry models.ConversationEntry) error {
-		if entry.IsAgentSpawn() {
-			agentID := entry.GetSpawnedAgentID()
-			result[agentID] = &SpawnInfo{
-				AgentID:    agentID,
-				SpawnUUID:  entry.UUID,
-				ParentUUID: entry.SourceToolAssistantUUID,
-			}
+		if info := spawnInfo(entry, entry.SourceToolAssistantUUID); info != nil {
+			result[info.AgentID] = info
 		}
 		return nil
 	})
@@ -132,15 +194,10 @@ func buildSpawnInfoMap(sessionPath string, sessionDir string, agents []models.Ag
 	// Scan each agent file for nested agent spawns
 	for _, agent := range agents {
 		_ = jsonl.ScanInto(agent.FilePath, func(entry models.ConversationEntry) error {
-			if entry.IsAgentSpawn() {
-				agentID := entry.GetSpawnedAgentID()
-				// For nested agents spawned from this agent's file,
-				// the parent is this agent (identified by agent.ID), not the entry UUID
-				result[agentID] = &SpawnInfo{
-					AgentID:    agentID,
-					SpawnUUID:  entry.UUID,
-					ParentUUID: agent.ID, // Use agent ID as parent, not entry UUID
-				}
+			// For nested agents spawned from this agent's file,
+			// the parent is this agent (identified by agent.ID), not the entry UUID
+			if info := spawnInfo(entry, agent.ID); info != nil {
+				result[info.AgentID] = info
 			}
 			return nil
 		})
@@ -149,6 +206,66 @@ func buildSpawnInfoMap(sessionPath string, sessionDir string, agents []models.Ag
 	return result
 }
 
+// spawnInfo returns the spawn information of an entry that spawned an agent, or tried to
+// and failed, with parentUUID as its parent; nil for other entries.
+func spawnInfo(entry models.ConversationEntry, parentUUID string) *SpawnInfo {
+	agentID, status, failed := SpawnFailureStatus(entry)
+	if !failed {
+		if !entry.IsAgentSpawn() {
+			return nil
+		}
+		agentID = entry.GetSpawnedAgentID()
+	}
+	return &SpawnInfo{
+		AgentID:     agentID,
+		SpawnUUID:   entry.UUID,
+		ParentUUID:  parentUUID,
+		SpawnTime:   spawnTime(entry),
+		Description: spawnDescription(entry),
+		Status:      status,
+	}
+}
+
+// spawnFailureStatuses are the toolUseResult statuses of a spawn that failed.
+var spawnFailureStatuses = map[string]bool{"failed": true, "error": true}
+
+// SpawnFailureStatus reports whether entry records a failed agent spawn: a toolUseResult
+// naming the agent with the status "failed" or "error". It returns the agent's ID and
+// the status; ok is false for other entries, including successful spawns.
+func SpawnFailureStatus(entry models.ConversationEntry) (agentID, status string, ok bool) {
+	result := entry.ToolUseResult
+	if result == nil || result.AgentID == "" || !spawnFailureStatuses[result.Status] {
+		return "", "", false
+	}
+	return result.AgentID, result.Status, true
+}
+
+// spawnTime returns the timestamp of a spawn entry, or the zero time if it cannot be parsed.
+func spawnTime(entry models.ConversationEntry) time.Time {
+	ts, err := entry.GetTimestamp()
+	if err != nil {
+		return time.Time{}
+	}
+	return ts
+}
+
+// spawnDescription returns what a spawn entry's agent was asked to do: the Task call's
+// description, or failing that the first non-blank line of its prompt.
+func spawnDescription(entry models.ConversationEntry) string {
+	if entry.ToolUseResult == nil {
+		return ""
+	}
+	if description := strings.TrimSpace(entry.ToolUseResult.Description); description != "" {
+		return description
+	}
+	for _, line := range strings.Split(entry.ToolUseResult.Prompt, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
 // findParentNode resolves a sourceToolAssistantUUID to find the parent node.
 // It looks up nodes by agent ID or by their UUID field (assistant message UUID).
 // Handles circular references by tracking visited nodes.
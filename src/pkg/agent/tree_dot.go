@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderTreeDOT renders an agent tree as a Graphviz DOT digraph, for `dot -Tpng` and
+// the like: a box for the main session and one for each subagent, labelled with its ID,
+// its type when it has one (see NormalizeAgentID), its entry count and any spawn failure
+// or depth limit, with an edge from each parent to its children. Node IDs are the
+// session and agent IDs as quoted strings, so any ID is valid. Each node is drawn once:
+// a node reached again, as spawn data with cycles can make it, is not drawn again and
+// gets no edge, so the graph is always acyclic. A nil tree renders as an empty graph.
+//
+//	digraph AgentTree {
+//	  rankdir=TB;
+//	  node [shape=box];
+//
+//	  "679761ba" [label="Session\n679761ba\n(42 entries)"];
+//	  "a12eb64f9c" [label="a12eb64f9c\n12 entries"];
+//	  "679761ba" -> "a12eb64f9c";
+//	}
+func RenderTreeDOT(root *TreeNode) string {
+	var sb strings.Builder
+	sb.WriteString("digraph AgentTree {\n")
+	sb.WriteString("  rankdir=TB;\n")
+	sb.WriteString("  node [shape=box];\n")
+	if root != nil {
+		sb.WriteString("\n")
+		rootID := dotQuote(root.SessionID)
+		fmt.Fprintf(&sb, "  %s [label=%s];\n", rootID,
+			dotQuote("Session\n"+root.SessionID+"\n("+entryCountLabel(root.EntryCount)+")"))
+		visited := map[string]bool{rootID: true}
+		renderTreeDOTChildren(&sb, root.Children, rootID, visited)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// renderTreeDOTChildren writes a node and an edge from parentID for each node not yet
+// visited, recursing into its children.
+func renderTreeDOTChildren(sb *strings.Builder, nodes []*TreeNode, parentID string, visited map[string]bool) {
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		nodeID := dotQuote(node.AgentID)
+		if visited[nodeID] {
+			continue
+		}
+		visited[nodeID] = true
+
+		lines := []string{node.AgentID}
+		if _, typeLabel := NormalizeAgentID(node.AgentID); typeLabel != "" {
+			lines = append(lines, "["+typeLabel+"]")
+		} else if node.AgentType != "" {
+			lines = append(lines, "["+node.AgentType+"]")
+		}
+		lines = append(lines, entryCountLabel(node.EntryCount))
+		if node.SpawnStatus != "" {
+			lines = append(lines, "[spawn "+node.SpawnStatus+"]")
+		}
+		if node.DepthLimited {
+			lines = append(lines, "[depth limit reached]")
+		}
+		fmt.Fprintf(sb, "  %s [label=%s];\n", nodeID, dotQuote(strings.Join(lines, "\n")))
+		fmt.Fprintf(sb, "  %s -> %s;\n", parentID, nodeID)
+
+		renderTreeDOTChildren(sb, node.Children, nodeID, visited)
+	}
+}
+
+// dotQuote returns s as a DOT double-quoted string. Backslashes and quotes are escaped
+// so they appear literally, and newlines become the \n line breaks of a label.
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderTreeDOT(t *testing.T) {
+	root := &TreeNode{
+		SessionID:  "679761ba-80c0",
+		EntryCount: 42,
+		IsRoot:     true,
+		Children: []*TreeNode{
+			{AgentID: "a12eb64f9c", EntryCount: 1, Children: []*TreeNode{
+				{AgentID: "aexplore-def456", AgentType: "explore", EntryCount: 3},
+				{AgentID: "a5", DepthLimited: true, SpawnStatus: "failed"},
+			}},
+		},
+	}
+
+	want := `digraph AgentTree {
+  rankdir=TB;
+  node [shape=box];
+
+  "679761ba-80c0" [label="Session\n679761ba-80c0\n(42 entries)"];
+  "a12eb64f9c" [label="a12eb64f9c\n1 entry"];
+  "679761ba-80c0" -> "a12eb64f9c";
+  "aexplore-def456" [label="aexplore-def456\n[Explore]\n3 entries"];
+  "a12eb64f9c" -> "aexplore-def456";
+  "a5" [label="a5\n0 entries\n[spawn failed]\n[depth limit reached]"];
+  "a12eb64f9c" -> "a5";
+}
+`
+	if got := RenderTreeDOT(root); got != want {
+		t.Errorf("RenderTreeDOT() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderTreeDOT_QuotesIDs(t *testing.T) {
+	root := &TreeNode{SessionID: `s"1`, IsRoot: true, Children: []*TreeNode{
+		{AgentID: `a\b" -> x; "c`},
+	}}
+
+	got := RenderTreeDOT(root)
+	for _, want := range []string{
+		`"s\"1" [label="Session\ns\"1\n(0 entries)"];`,
+		`"a\\b\" -> x; \"c" [label="a\\b\" -> x; \"c\n0 entries"];`,
+		`"s\"1" -> "a\\b\" -> x; \"c";`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderTreeDOT() missing %s:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderTreeDOT_BreaksCycles(t *testing.T) {
+	// a1 and a2 claim each other as children, and a2 also lists the session's ID
+	a1 := &TreeNode{AgentID: "a1", EntryCount: 1}
+	a2 := &TreeNode{AgentID: "a2", EntryCount: 1}
+	root := &TreeNode{SessionID: "s1", IsRoot: true, Children: []*TreeNode{a1, a2}}
+	a1.Children = []*TreeNode{a2}
+	a2.Children = []*TreeNode{a1, {AgentID: "s1"}, nil}
+
+	got := RenderTreeDOT(root)
+	if edges := strings.Count(got, "->"); edges != 2 {
+		t.Errorf("RenderTreeDOT() has %d edges, want 2:\n%s", edges, got)
+	}
+	for _, want := range []string{`"s1" -> "a1";`, `"a1" -> "a2";`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("RenderTreeDOT() missing %s:\n%s", want, got)
+		}
+	}
+	if strings.Count(got, `"a2" [label=`) != 1 {
+		t.Errorf("a node reached twice should be drawn once:\n%s", got)
+	}
+}
+
+func TestRenderTreeDOT_Nil(t *testing.T) {
+	if got, want := RenderTreeDOT(nil), "digraph AgentTree {\n  rankdir=TB;\n  node [shape=box];\n}\n"; got != want {
+		t.Errorf("RenderTreeDOT(nil) = %q, want %q", got, want)
+	}
+}
@@ -3,7 +3,11 @@ package agent
 import (
 	"encoding/json"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
 )
 
 // createToolUseResultEntry creates a JSONL entry with toolUseResult for testing.
@@ -419,3 +423,239 @@ func TestFlattenTree_Nil(t *testing.T) {
 		t.Errorf("FlattenTree(nil) returned %d nodes, want 0", len(nodes))
 	}
 }
+
+func TestBuildNestedTreeWithDepth_FlattensBelowLimit(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionID := "679761ba-80c0-4cd3-a586-cc6a1fc56308"
+
+	sessionContent := `{"uuid":"main-1","type":"user"}` + "\n"
+	sessionContent += createAgentSpawnEntry("spawn-l1", sessionID, "level1", "main-1")
+	mustWriteFile(t, filepath.Join(tmpDir, sessionID+".jsonl"), []byte(sessionContent))
+
+	subagentsDir := filepath.Join(tmpDir, sessionID, "subagents")
+	mustMkdirAll(t, subagentsDir)
+
+	level1Content := `{"uuid":"l1-1","type":"user"}` + "\n"
+	level1Content += createAgentSpawnEntry("spawn-l2", sessionID, "level2", "level1")
+	level2Content := `{"uuid":"l2-1","type":"user"}` + "\n"
+	level2Content += createAgentSpawnEntry("spawn-l3", sessionID, "level3", "level2")
+	level3Content := `{"uuid":"l3-1","type":"user"}` + "\n"
+	mustWriteFile(t, filepath.Join(subagentsDir, "agent-level1.jsonl"), []byte(level1Content))
+	mustWriteFile(t, filepath.Join(subagentsDir, "agent-level2.jsonl"), []byte(level2Content))
+	mustWriteFile(t, filepath.Join(subagentsDir, "agent-level3.jsonl"), []byte(level3Content))
+
+	unlimited, err := BuildNestedTree(tmpDir, sessionID)
+	if err != nil {
+		t.Fatalf("BuildNestedTree() error: %v", err)
+	}
+
+	tree, err := BuildNestedTreeWithDepth(tmpDir, sessionID, 1)
+	if err != nil {
+		t.Fatalf("BuildNestedTreeWithDepth() error: %v", err)
+	}
+
+	if len(tree.Children) != 1 || tree.Children[0].AgentID != "level1" {
+		t.Fatalf("root children = %v, want [level1]", tree.Children)
+	}
+	level1 := tree.Children[0]
+	if level1.DepthLimited {
+		t.Error("level1 is within the limit but marked DepthLimited")
+	}
+
+	var got []string
+	for _, child := range level1.Children {
+		got = append(got, child.AgentID)
+		if !child.DepthLimited {
+			t.Errorf("%s attached past the limit but not marked DepthLimited", child.AgentID)
+		}
+		if len(child.Children) != 0 {
+			t.Errorf("%s has %d children, want 0", child.AgentID, len(child.Children))
+		}
+	}
+	if strings.Join(got, ",") != "level2,level3" {
+		t.Errorf("level1 children = %v, want [level2 level3]", got)
+	}
+
+	if CountTotalEntries(tree) != CountTotalEntries(unlimited) {
+		t.Errorf("CountTotalEntries() = %d, want %d (same as unlimited)",
+			CountTotalEntries(tree), CountTotalEntries(unlimited))
+	}
+}
+
+func TestLimitDepth(t *testing.T) {
+	newTree := func() *TreeNode {
+		return &TreeNode{
+			IsRoot:     true,
+			EntryCount: 10,
+			Children: []*TreeNode{
+				{
+					AgentID:    "a",
+					EntryCount: 5,
+					Children: []*TreeNode{
+						{
+							AgentID:    "a1",
+							EntryCount: 3,
+							Children:   []*TreeNode{{AgentID: "a1x", EntryCount: 1}},
+						},
+						{AgentID: "a2", EntryCount: 2},
+					},
+				},
+				{AgentID: "b", EntryCount: 7},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		maxDepth int
+		want     map[string][]string // parent -> children
+	}{
+		{
+			name:     "depth 1 flattens under first level",
+			maxDepth: 1,
+			want:     map[string][]string{"root": {"a", "b"}, "a": {"a1", "a1x", "a2"}},
+		},
+		{
+			name:     "depth 2 flattens under second level",
+			maxDepth: 2,
+			want:     map[string][]string{"root": {"a", "b"}, "a": {"a1", "a2"}, "a1": {"a1x"}},
+		},
+		{
+			name:     "limit deeper than tree leaves it unchanged",
+			maxDepth: 5,
+			want:     map[string][]string{"root": {"a", "b"}, "a": {"a1", "a2"}, "a1": {"a1x"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newTree()
+			limitDepth(root, 0, tt.maxDepth)
+
+			got := make(map[string][]string)
+			for _, node := range FlattenTree(root) {
+				name := node.AgentID
+				if node.IsRoot {
+					name = "root"
+				}
+				for _, child := range node.Children {
+					got[name] = append(got[name], child.AgentID)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tree = %v, want %v", got, tt.want)
+			}
+
+			if total := CountTotalEntries(root); total != 28 {
+				t.Errorf("CountTotalEntries() = %d, want 28", total)
+			}
+		})
+	}
+}
+
+func TestLimitDepth_MarksOnlyMovedNodes(t *testing.T) {
+	root := &TreeNode{
+		IsRoot: true,
+		Children: []*TreeNode{
+			{AgentID: "a", Children: []*TreeNode{{AgentID: "a1"}}},
+		},
+	}
+
+	limitDepth(root, 0, 2)
+
+	for _, node := range FlattenTree(root) {
+		if node.DepthLimited {
+			t.Errorf("%s marked DepthLimited in a tree within the limit", node.AgentID)
+		}
+	}
+}
+
+func TestBuildNestedTree_Descriptions(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionID := "desc-session"
+
+	promptOnly, _ := json.Marshal(map[string]any{
+		"uuid": "spawn-2", "sessionId": sessionID, "type": "user", "timestamp": "2026-01-15T10:00:01Z",
+		"toolUseResult": map[string]any{"status": "async_launched", "agentId": "agent-prompt", "prompt": "\n  Find the flaky test\nthen fix it"},
+	})
+	noDescription, _ := json.Marshal(map[string]any{
+		"uuid": "spawn-3", "sessionId": sessionID, "type": "user", "timestamp": "2026-01-15T10:00:02Z",
+		"toolUseResult": map[string]any{"status": "async_launched", "agentId": "agent-bare"},
+	})
+	sessionContent := createToolUseResultEntry("spawn-1", sessionID, "agent-desc", "", "async_launched") +
+		string(promptOnly) + "\n" + string(noDescription) + "\n"
+	mustWriteFile(t, filepath.Join(tmpDir, sessionID+".jsonl"), []byte(sessionContent))
+
+	subagentsDir := filepath.Join(tmpDir, sessionID, "subagents")
+	mustMkdirAll(t, subagentsDir)
+	for _, id := range []string{"agent-desc", "agent-prompt", "agent-bare"} {
+		mustWriteFile(t, filepath.Join(subagentsDir, "agent-"+id+".jsonl"), []byte(`{"uuid":"x","type":"user"}`+"\n"))
+	}
+
+	tree, err := BuildNestedTree(tmpDir, sessionID)
+	if err != nil {
+		t.Fatalf("BuildNestedTree() error: %v", err)
+	}
+	got := make(map[string]string)
+	for _, child := range tree.Children {
+		got[child.AgentID] = child.Description
+	}
+	want := map[string]string{
+		"agent-desc":   "Test agent spawn",
+		"agent-prompt": "Find the flaky test",
+		"agent-bare":   "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("descriptions = %v, want %v", got, want)
+	}
+}
+
+func TestBuildNestedTree_FailedSpawns(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionID := "failed-session"
+
+	sessionContent := createToolUseResultEntry("spawn-1", sessionID, "agent-ok", "", "async_launched") +
+		createToolUseResultEntry("spawn-2", sessionID, "agent-failed", "", "failed") +
+		createToolUseResultEntry("spawn-3", sessionID, "agent-error", "", "error")
+	mustWriteFile(t, filepath.Join(tmpDir, sessionID+".jsonl"), []byte(sessionContent))
+
+	subagentsDir := filepath.Join(tmpDir, sessionID, "subagents")
+	mustMkdirAll(t, subagentsDir)
+	for _, id := range []string{"agent-ok", "agent-failed", "agent-error"} {
+		mustWriteFile(t, filepath.Join(subagentsDir, "agent-"+id+".jsonl"), []byte(`{"uuid":"x","type":"user"}`+"\n"))
+	}
+
+	tree, err := BuildNestedTree(tmpDir, sessionID)
+	if err != nil {
+		t.Fatalf("BuildNestedTree() error: %v", err)
+	}
+	got := make(map[string]string)
+	for _, child := range tree.Children {
+		got[child.AgentID] = child.SpawnStatus
+	}
+	want := map[string]string{"agent-ok": "", "agent-failed": "failed", "agent-error": "error"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("spawn statuses = %v, want %v", got, want)
+	}
+}
+
+func TestSpawnFailureStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *models.ToolUseResult
+		wantID string
+		wantOK bool
+	}{
+		{"failed", &models.ToolUseResult{Status: "failed", AgentID: "a1"}, "a1", true},
+		{"error", &models.ToolUseResult{Status: "error", AgentID: "a1"}, "a1", true},
+		{"launched", &models.ToolUseResult{Status: "async_launched", AgentID: "a1"}, "", false},
+		{"no agent", &models.ToolUseResult{Status: "failed"}, "", false},
+		{"no result", nil, "", false},
+	}
+	for _, tt := range tests {
+		id, _, ok := SpawnFailureStatus(models.ConversationEntry{ToolUseResult: tt.result})
+		if id != tt.wantID || ok != tt.wantOK {
+			t.Errorf("%s: SpawnFailureStatus() = %q, %v, want %q, %v", tt.name, id, ok, tt.wantID, tt.wantOK)
+		}
+	}
+}
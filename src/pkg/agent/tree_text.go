@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RenderTreeText renders an agent tree as an indented outline, like tree(1): the main
+// session on the first line, then each subagent under its parent with box-drawing
+// connectors, its type when its ID has one (see NormalizeAgentID), and its entry count.
+// A tree without subagents renders as the session line followed by "No subagents".
+//
+//	Session 679761ba-80c0-4cd3-a586-cc6a1fc56308 (42 entries)
+//	├── a12eb64f9c (12 entries)
+//	│   └── aexplore-def456 [Explore] (3 entries)
+//	└── a98c0d1e2b (1 entry)
+func RenderTreeText(root *TreeNode) string {
+	if root == nil {
+		return "No subagents\n"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Session %s (%s)\n", root.SessionID, entryCountLabel(root.EntryCount))
+	if len(root.Children) == 0 {
+		sb.WriteString("No subagents\n")
+		return sb.String()
+	}
+	renderTreeTextChildren(&sb, root.Children, "")
+	return sb.String()
+}
+
+// renderTreeTextChildren writes one line per node, recursing into each node's children
+// with the prefix extended by a continuation bar or blank column.
+func renderTreeTextChildren(sb *strings.Builder, nodes []*TreeNode, prefix string) {
+	for i, node := range nodes {
+		connector, childPrefix := "├── ", prefix+"│   "
+		if i == len(nodes)-1 {
+			connector, childPrefix = "└── ", prefix+"    "
+		}
+
+		label := node.AgentID
+		if _, typeLabel := NormalizeAgentID(node.AgentID); typeLabel != "" {
+			label += " [" + typeLabel + "]"
+		} else if node.AgentType != "" {
+			label += " [" + node.AgentType + "]"
+		}
+		fmt.Fprintf(sb, "%s%s%s (%s)", prefix, connector, label, entryCountLabel(node.EntryCount))
+		if node.SpawnStatus != "" {
+			sb.WriteString(" [spawn " + node.SpawnStatus + "]")
+		}
+		if node.DepthLimited {
+			sb.WriteString(" [depth limit reached]")
+		}
+		sb.WriteString("\n")
+
+		renderTreeTextChildren(sb, node.Children, childPrefix)
+	}
+}
+
+// entryCountLabel formats an entry count as "1 entry" or "N entries".
+func entryCountLabel(n int) string {
+	if n == 1 {
+		return "1 entry"
+	}
+	return fmt.Sprintf("%d entries", n)
+}
@@ -0,0 +1,40 @@
+package agent
+
+import "testing"
+
+func TestRenderTreeText(t *testing.T) {
+	root := &TreeNode{
+		SessionID:  "s1",
+		EntryCount: 42,
+		IsRoot:     true,
+		Children: []*TreeNode{
+			{AgentID: "a12eb64f9c", EntryCount: 12, Children: []*TreeNode{
+				{AgentID: "aexplore-def456", AgentType: "explore", EntryCount: 3},
+				{AgentID: "a5", EntryCount: 0, DepthLimited: true, SpawnStatus: "failed"},
+			}},
+			{AgentID: "aprompt_suggestion-abc", AgentType: "prompt_suggestion", EntryCount: 1, Children: []*TreeNode{
+				{AgentID: "a6", EntryCount: 2},
+			}},
+		},
+	}
+
+	want := `Session s1 (42 entries)
+├── a12eb64f9c (12 entries)
+│   ├── aexplore-def456 [Explore] (3 entries)
+│   └── a5 (0 entries) [spawn failed] [depth limit reached]
+└── aprompt_suggestion-abc [Prompt suggestion] (1 entry)
+    └── a6 (2 entries)
+`
+	if got := RenderTreeText(root); got != want {
+		t.Errorf("RenderTreeText() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderTreeText_NoSubagents(t *testing.T) {
+	if got, want := RenderTreeText(&TreeNode{SessionID: "s1", EntryCount: 1, IsRoot: true}), "Session s1 (1 entry)\nNo subagents\n"; got != want {
+		t.Errorf("RenderTreeText() = %q, want %q", got, want)
+	}
+	if got := RenderTreeText(nil); got != "No subagents\n" {
+		t.Errorf("RenderTreeText(nil) = %q", got)
+	}
+}
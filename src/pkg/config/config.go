@@ -0,0 +1,211 @@
+// Package config loads the claude-history configuration file, which sets default
+// values for command-line flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPath is the environment variable that overrides the config file location.
+const EnvPath = "CLAUDE_HISTORY_CONFIG"
+
+// Config holds flag defaults read from a config file. Keys are flag names without
+// the leading dashes and values are in the form the flag would accept on the
+// command line.
+//
+// A config file looks like:
+//
+//	claude-dir: /data/claude   # top-level keys set global flags
+//	export:                      # a section sets flags of one command
+//	  format: jsonl
+//	  relative-times: true
+//	query:
+//	  limit: 50
+//	filters:                     # named filter profiles, used with --filter
+//	  bash-errors:
+//	    tool: Bash
+//	    errors: true
+type Config struct {
+	// Path is the file the config was loaded from, whether or not it exists.
+	Path string
+
+	// Global holds the top-level keys.
+	Global map[string]string
+
+	// Commands holds the per-command sections, keyed by command name.
+	Commands map[string]map[string]string
+
+	// Filters holds the named filter profiles of the filters section. Each profile maps
+	// query filter flag names to values, like a command section.
+	Filters map[string]map[string]string
+}
+
+// FiltersKey is the top-level key of the filter profiles section.
+const FiltersKey = "filters"
+
+// DefaultPath returns the config file location: $CLAUDE_HISTORY_CONFIG if set,
+// otherwise claude-history/config.yaml under $XDG_CONFIG_HOME (default ~/.config).
+func DefaultPath() (string, error) {
+	if p := os.Getenv(EnvPath); p != "" {
+		return p, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "claude-history", "config.yaml"), nil
+}
+
+// LoadConfig loads the config file at DefaultPath.
+func LoadConfig() (*Config, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate config file: %w", err)
+	}
+	return Load(path)
+}
+
+// Load reads the config file at path. A missing file is not an error: it yields
+// an empty Config, so every flag keeps its built-in default.
+func Load(path string) (*Config, error) {
+	cfg := &Config{
+		Path:     path,
+		Global:   map[string]string{},
+		Commands: map[string]map[string]string{},
+		Filters:  map[string]map[string]string{},
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // G304: config path is user-controlled by design
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	if err := cfg.parse(data); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// parse fills cfg from YAML data.
+func (cfg *Config) parse(data []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil // Empty file
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping of option names to values", root.Line)
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if value.Kind != yaml.MappingNode {
+			v, err := scalarValue(key.Value, value)
+			if err != nil {
+				return err
+			}
+			cfg.Global[key.Value] = v
+			continue
+		}
+
+		if key.Value == FiltersKey {
+			if err := cfg.parseFilters(value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		section, err := parseSection(key.Value, value)
+		if err != nil {
+			return err
+		}
+		cfg.Commands[key.Value] = section
+	}
+	return nil
+}
+
+// parseFilters fills cfg.Filters from the filters section, a mapping of profile names
+// to option mappings.
+func (cfg *Config) parseFilters(node *yaml.Node) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name, value := node.Content[i], node.Content[i+1]
+		if value.Kind != yaml.MappingNode {
+			return fmt.Errorf("line %d: %s.%s: expected a mapping of filter options to values", value.Line, FiltersKey, name.Value)
+		}
+		profile, err := parseSection(FiltersKey+"."+name.Value, value)
+		if err != nil {
+			return err
+		}
+		cfg.Filters[name.Value] = profile
+	}
+	return nil
+}
+
+// parseSection converts a mapping of option names to values, named name in errors.
+func parseSection(name string, node *yaml.Node) (map[string]string, error) {
+	section := map[string]string{}
+	for j := 0; j+1 < len(node.Content); j += 2 {
+		k, v := node.Content[j], node.Content[j+1]
+		s, err := scalarValue(name+"."+k.Value, v)
+		if err != nil {
+			return nil, err
+		}
+		section[k.Value] = s
+	}
+	return section, nil
+}
+
+// FilterProfile returns the filter profile called name. An unknown name is an error
+// that lists the profiles the config defines.
+func (cfg *Config) FilterProfile(name string) (map[string]string, error) {
+	if profile, ok := cfg.Filters[name]; ok {
+		return profile, nil
+	}
+	if len(cfg.Filters) == 0 {
+		return nil, fmt.Errorf("unknown filter profile %q: config %s defines no filter profiles", name, cfg.Path)
+	}
+	names := make([]string, 0, len(cfg.Filters))
+	for n := range cfg.Filters {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown filter profile %q: config %s defines %s", name, cfg.Path, strings.Join(names, ", "))
+}
+
+// scalarValue converts a YAML value to its flag string form. Lists are joined with
+// commas, as list flags accept them.
+func scalarValue(name string, node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value, nil
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return "", fmt.Errorf("line %d: %s: list items must be plain values", item.Line, name)
+			}
+			items = append(items, item.Value)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("line %d: %s: expected a value, got a nested mapping", node.Line, name)
+	}
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes content to a config file in a temp dir and returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nope", "config.yaml")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v, want nil for a missing file", err)
+	}
+	if cfg.Path != path {
+		t.Errorf("Path = %q, want %q", cfg.Path, path)
+	}
+	if len(cfg.Global) != 0 || len(cfg.Commands) != 0 {
+		t.Errorf("missing file should give an empty config, got %+v", cfg)
+	}
+}
+
+func TestLoad_EmptyFile(t *testing.T) {
+	cfg, err := Load(writeConfig(t, "# only a comment\n"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Global) != 0 || len(cfg.Commands) != 0 {
+		t.Errorf("empty file should give an empty config, got %+v", cfg)
+	}
+}
+
+func TestLoad_GlobalAndCommands(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+claude-dir: /data/.claude
+export:
+  format: markdown
+  relative-times: true
+  fields: [uuid, type]
+query:
+  limit: 50
+`))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got := cfg.Global["claude-dir"]; got != "/data/.claude" {
+		t.Errorf("Global[claude-dir] = %q, want /data/.claude", got)
+	}
+	wantExport := map[string]string{"format": "markdown", "relative-times": "true", "fields": "uuid,type"}
+	for k, want := range wantExport {
+		if got := cfg.Commands["export"][k]; got != want {
+			t.Errorf("Commands[export][%s] = %q, want %q", k, got, want)
+		}
+	}
+	if got := cfg.Commands["query"]["limit"]; got != "50" {
+		t.Errorf("Commands[query][limit] = %q, want 50", got)
+	}
+}
+
+func TestLoad_FilterProfiles(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+filters:
+  bash-errors:
+    tool: Bash
+    errors: true
+  go-edits:
+    tool: [Edit, Write]
+`))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, ok := cfg.Commands[FiltersKey]; ok {
+		t.Error("filters should not be read as a command section")
+	}
+
+	profile, err := cfg.FilterProfile("bash-errors")
+	if err != nil {
+		t.Fatalf("FilterProfile() error = %v", err)
+	}
+	if profile["tool"] != "Bash" || profile["errors"] != "true" {
+		t.Errorf("FilterProfile(bash-errors) = %v", profile)
+	}
+	if got := cfg.Filters["go-edits"]["tool"]; got != "Edit,Write" {
+		t.Errorf("Filters[go-edits][tool] = %q, want Edit,Write", got)
+	}
+}
+
+func TestFilterProfile_Unknown(t *testing.T) {
+	cfg := &Config{Path: "/test/config.yaml", Filters: map[string]map[string]string{
+		"go-edits": {}, "bash-errors": {},
+	}}
+	_, err := cfg.FilterProfile("bash")
+	if err == nil || !strings.Contains(err.Error(), `unknown filter profile "bash"`) || !strings.Contains(err.Error(), "defines bash-errors, go-edits") {
+		t.Errorf("FilterProfile() error = %v, want the defined profiles listed", err)
+	}
+
+	_, err = (&Config{Path: "/test/config.yaml"}).FilterProfile("bash")
+	if err == nil || !strings.Contains(err.Error(), "defines no filter profiles") {
+		t.Errorf("FilterProfile() error = %v, want no profiles defined", err)
+	}
+}
+
+func TestLoad_Malformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"invalid yaml", "export: [\n", "failed to parse config"},
+		{"not a mapping", "- a\n- b\n", "expected a mapping"},
+		{"nested too deep", "export:\n  format:\n    x: y\n", "export.format: expected a value"},
+		{"nested list item", "export:\n  fields:\n    - a: b\n", "list items must be plain values"},
+		{"filter profile not a mapping", "filters:\n  bash-errors: true\n", "filters.bash-errors: expected a mapping"},
+		{"filter option nested", "filters:\n  p:\n    tool:\n      x: y\n", "filters.p.tool: expected a value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, tt.content)
+			_, err := Load(path)
+			if err == nil {
+				t.Fatal("Load() error = nil, want error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), path) {
+				t.Errorf("error = %q, want it to name the file %s", err, path)
+			}
+		})
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Run("env override", func(t *testing.T) {
+		t.Setenv(EnvPath, "/custom/config.yaml")
+		got, err := DefaultPath()
+		if err != nil {
+			t.Fatalf("DefaultPath() error = %v", err)
+		}
+		if got != "/custom/config.yaml" {
+			t.Errorf("DefaultPath() = %q, want /custom/config.yaml", got)
+		}
+	})
+
+	t.Run("xdg config home", func(t *testing.T) {
+		t.Setenv(EnvPath, "")
+		t.Setenv("XDG_CONFIG_HOME", "/xdg")
+		got, err := DefaultPath()
+		if err != nil {
+			t.Fatalf("DefaultPath() error = %v", err)
+		}
+		want := filepath.Join("/xdg", "claude-history", "config.yaml")
+		if got != want {
+			t.Errorf("DefaultPath() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("home directory", func(t *testing.T) {
+		t.Setenv(EnvPath, "")
+		t.Setenv("XDG_CONFIG_HOME", "")
+		home, err := os.UserHomeDir()
+		if err != nil {
+			t.Skipf("no home directory: %v", err)
+		}
+		got, err := DefaultPath()
+		if err != nil {
+			t.Fatalf("DefaultPath() error = %v", err)
+		}
+		want := filepath.Join(home, ".config", "claude-history", "config.yaml")
+		if got != want {
+			t.Errorf("DefaultPath() = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestLoadConfig_UsesDefaultPath(t *testing.T) {
+	path := writeConfig(t, "claude-dir: /x\n")
+	t.Setenv(EnvPath, path)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.Path != path || cfg.Global["claude-dir"] != "/x" {
+		t.Errorf("LoadConfig() = %+v, want config from %s", cfg, path)
+	}
+}
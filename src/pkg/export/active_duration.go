@@ -0,0 +1,48 @@
+package export
+
+import (
+	"sort"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// DefaultIdleThreshold is the longest pause between consecutive entries that still counts
+// towards SessionStats.ActiveDuration when no ExportOptions.IdleThreshold is set.
+const DefaultIdleThreshold = 10 * time.Minute
+
+// activeDuration returns the time spent working in entries: the sum of the pauses between
+// consecutive timestamps that are no longer than threshold. Timestamps are sorted first, so
+// entries written out of order do not produce negative or inflated pauses. Entries without a
+// parseable timestamp are skipped, and fewer than two timestamps give zero.
+func activeDuration(entries []models.ConversationEntry, threshold time.Duration) time.Duration {
+	times := make([]time.Time, 0, len(entries))
+	for i := range entries {
+		if t, err := entries[i].GetTimestamp(); err == nil {
+			times = append(times, t)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	var active time.Duration
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap <= threshold {
+			active += gap
+		}
+	}
+	return active
+}
+
+// statsWithIdleThreshold returns stats with the active duration measured against
+// opts.IdleThreshold. ComputeSessionStats uses DefaultIdleThreshold, so stats is returned
+// as is without a threshold, or if it has no measured duration; otherwise the result is a
+// copy and stats is left unchanged.
+func statsWithIdleThreshold(stats *SessionStats, entries []models.ConversationEntry, opts ExportOptions) *SessionStats {
+	if stats == nil || opts.IdleThreshold <= 0 || stats.ActiveDuration == "" {
+		return stats
+	}
+	adjusted := *stats
+	adjusted.activeDuration = activeDuration(entries, opts.IdleThreshold)
+	adjusted.ActiveDuration = formatDuration(adjusted.activeDuration)
+	return &adjusted
+}
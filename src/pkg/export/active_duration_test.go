@@ -0,0 +1,90 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func timedEntries(timestamps ...string) []models.ConversationEntry {
+	entries := make([]models.ConversationEntry, len(timestamps))
+	for i, ts := range timestamps {
+		entries[i] = models.ConversationEntry{Type: models.EntryTypeUser, Timestamp: ts}
+	}
+	return entries
+}
+
+func TestActiveDuration(t *testing.T) {
+	entries := timedEntries(
+		"2026-01-01T10:00:00Z",
+		"2026-01-01T10:05:00Z", // 5m, active
+		"2026-01-01T10:45:00Z", // 40m, idle
+		"2026-01-01T10:55:00Z", // 10m, active (threshold is inclusive)
+		"",                     // no timestamp
+	)
+
+	if got := activeDuration(entries, DefaultIdleThreshold); got != 15*time.Minute {
+		t.Errorf("activeDuration(default) = %v, want 15m", got)
+	}
+	if got := activeDuration(entries, time.Hour); got != 55*time.Minute {
+		t.Errorf("activeDuration(1h) = %v, want 55m", got)
+	}
+}
+
+func TestActiveDuration_OutOfOrder(t *testing.T) {
+	entries := timedEntries(
+		"2026-01-01T10:05:00Z",
+		"2026-01-01T10:00:00Z",
+		"2026-01-01T10:08:00Z",
+	)
+	if got := activeDuration(entries, DefaultIdleThreshold); got != 8*time.Minute {
+		t.Errorf("activeDuration() = %v, want 8m", got)
+	}
+}
+
+func TestComputeSessionStats_ActiveDuration(t *testing.T) {
+	stats := ComputeSessionStats(timedEntries(
+		"2026-01-01T10:00:00Z",
+		"2026-01-01T10:03:00Z",
+		"2026-01-01T11:00:00Z",
+	), nil)
+	if stats.Duration != "1h 0m" || stats.ActiveDuration != "3m" {
+		t.Errorf("Duration = %q, ActiveDuration = %q, want 1h 0m and 3m", stats.Duration, stats.ActiveDuration)
+	}
+
+	single := ComputeSessionStats(timedEntries("2026-01-01T10:00:00Z"), nil)
+	if single.activeDuration != 0 || single.ActiveDuration != "0s" {
+		t.Errorf("single entry ActiveDuration = %q, want 0s", single.ActiveDuration)
+	}
+}
+
+func TestRenderConversation_ActiveDurationInHeader(t *testing.T) {
+	entries := timedEntries(
+		"2026-01-01T10:00:00Z",
+		"2026-01-01T10:03:00Z",
+		"2026-01-01T11:00:00Z",
+	)
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if !strings.Contains(html, "Duration: 1h 0m (active 3m)") {
+		t.Error("header should show the total and active duration")
+	}
+
+	// A longer idle threshold counts the 57 minute pause as active
+	stats := ComputeSessionStats(entries, nil)
+	html, err = RenderConversationWithOptions(entries, nil, stats, ExportOptions{IdleThreshold: time.Hour})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if !strings.Contains(html, "Duration: 1h 0m (active 1h 0m)") {
+		t.Error("header should measure the active duration with IdleThreshold")
+	}
+	if stats.ActiveDuration != "3m" {
+		t.Errorf("IdleThreshold should not change the caller's stats, got %q", stats.ActiveDuration)
+	}
+}
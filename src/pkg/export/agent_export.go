@@ -0,0 +1,151 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+	"github.com/randlee/claude-history/pkg/paths"
+	"github.com/randlee/claude-history/pkg/resolver"
+)
+
+// ExportAgent exports one subagent and all of its nested descendants, without the rest
+// of the session. The agent's own JSONL file becomes MainSessionFile
+// (source/agent-{id}.jsonl) and descendants are copied to source/agents/.
+// Session and agent IDs may be prefixes. opts.Resume is not supported and is ignored.
+func ExportAgent(projectPath, sessionID, agentID string, opts ExportOptions) (*ExportResult, error) {
+	projectDir, err := ProjectDir(projectPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedSessionID, err := resolver.ResolveSessionID(projectDir, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve session ID: %w", err)
+	}
+	resolvedAgentID, err := resolver.ResolveAgentID(projectDir, resolvedSessionID, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve agent ID: %w", err)
+	}
+
+	tree, err := agent.BuildNestedTree(projectDir, resolvedSessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build agent tree: %w", err)
+	}
+	node := findAgentNode(tree, resolvedAgentID)
+	if node == nil {
+		return nil, fmt.Errorf("%w: %s", resolver.ErrAgentNotFound, resolvedAgentID)
+	}
+
+	outputDir := opts.OutputDir
+	if outputDir == "" {
+		info, err := os.Stat(node.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat agent file: %w", err)
+		}
+		outputDir, err = generateTempPath(resolvedAgentID, info.ModTime())
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate temp path: %w", err)
+		}
+	}
+
+	sourceDir := filepath.Join(outputDir, "source")
+	agentsDir := filepath.Join(sourceDir, "agents")
+	if err := os.MkdirAll(agentsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	result := &ExportResult{
+		OutputDir:  outputDir,
+		SessionID:  resolvedSessionID,
+		AgentID:    resolvedAgentID,
+		SourceDir:  sourceDir,
+		AgentFiles: make(map[string]string),
+	}
+
+	// Copy the agent's own file
+	mainFile := filepath.Join(sourceDir, "agent-"+resolvedAgentID+paths.JSONLExtOf(node.FilePath))
+	if err := copyFile(node.FilePath, mainFile); err != nil {
+		return nil, fmt.Errorf("failed to copy agent file: %w", err)
+	}
+	result.MainSessionFile = mainFile
+
+	if node.ParseErrors > 0 {
+		result.Errors = append(result.Errors, partialAgentError(resolvedAgentID, node.ParseErrors))
+	}
+
+	// Copy every nested descendant
+	for _, descendant := range agent.FlattenTree(node)[1:] {
+		destPath := filepath.Join(agentsDir, "agent-"+descendant.AgentID+paths.JSONLExtOf(descendant.FilePath))
+		if err := copyFile(descendant.FilePath, destPath); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to copy agent %s: %v", descendant.AgentID, err))
+			continue
+		}
+		result.AgentFiles[descendant.AgentID] = destPath
+		result.TotalAgents++
+		if descendant.ParseErrors > 0 {
+			result.Errors = append(result.Errors, partialAgentError(descendant.AgentID, descendant.ParseErrors))
+		}
+	}
+
+	return result, nil
+}
+
+// findAgentNode returns the tree node for agentID, or nil if it is not in the tree.
+func findAgentNode(tree *agent.TreeNode, agentID string) *agent.TreeNode {
+	for _, node := range agent.FlattenTree(tree) {
+		if !node.IsRoot && node.AgentID == agentID {
+			return node
+		}
+	}
+	return nil
+}
+
+// ReadAgentExportEntries reads the entries of an ExportAgent result: the agent's own
+// entries merged with those of its descendants, in chronological order. Entries with
+// equal timestamps keep file order, the exported agent's entries first; entries without
+// a parseable timestamp sort before all others. Lines that cannot be parsed are skipped
+// (see agent.ReadAgentFile).
+func ReadAgentExportEntries(result *ExportResult) ([]models.ConversationEntry, error) {
+	entries, _, err := agent.ReadAgentFile(result.MainSessionFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent %s: %w", result.AgentID, err)
+	}
+
+	// Map iteration order is random; read descendants in a stable order
+	ids := make([]string, 0, len(result.AgentFiles))
+	for id := range result.AgentFiles {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		agentEntries, _, err := agent.ReadAgentFile(result.AgentFiles[id])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read agent %s: %w", id, err)
+		}
+		entries = append(entries, agentEntries...)
+	}
+
+	// Sort an index so each timestamp is parsed once
+	times := make([]time.Time, len(entries))
+	for i := range entries {
+		times[i], _ = entries[i].GetTimestamp()
+	}
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return times[order[a]].Before(times[order[b]])
+	})
+
+	sorted := make([]models.ConversationEntry, len(entries))
+	for i, idx := range order {
+		sorted[i] = entries[idx]
+	}
+	return sorted, nil
+}
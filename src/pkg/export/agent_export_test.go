@@ -0,0 +1,122 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupAgentSubtree adds a nested agent (spawned by agent a1b2c3d4) and an unrelated
+// sibling agent to the session created by setupTestSession.
+func setupAgentSubtree(t *testing.T, projectDir, sessionID string) {
+	t.Helper()
+	subagentsDir := filepath.Join(projectDir, sessionID, "subagents")
+
+	parent := `{"type":"user","timestamp":"2026-02-01T10:02:00Z","agentId":"a1b2c3d4","uuid":"p-1","message":"Investigate"}
+{"type":"user","timestamp":"2026-02-01T10:02:30Z","agentId":"a1b2c3d4","uuid":"p-2","toolUseResult":{"isAsync":true,"status":"async_launched","agentId":"c9c9c9c9"}}
+{"type":"assistant","timestamp":"2026-02-01T10:05:00Z","agentId":"a1b2c3d4","uuid":"p-3","message":{"role":"assistant","content":"Parent done"}}
+`
+	nested := `{"type":"assistant","timestamp":"2026-02-01T10:03:00Z","agentId":"c9c9c9c9","uuid":"c-1","message":{"role":"assistant","content":"Nested result"}}
+`
+	sibling := `{"type":"assistant","timestamp":"2026-02-01T10:04:00Z","agentId":"f0f0f0f0","uuid":"s-1","message":{"role":"assistant","content":"Sibling work"}}
+`
+	for name, content := range map[string]string{
+		"agent-a1b2c3d4.jsonl": parent,
+		"agent-c9c9c9c9.jsonl": nested,
+		"agent-f0f0f0f0.jsonl": sibling,
+	} {
+		if err := os.WriteFile(filepath.Join(subagentsDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestExportAgent_IncludesDescendants(t *testing.T) {
+	tempDir := t.TempDir()
+	projectDir, sessionID := setupTestSession(t, tempDir)
+	setupAgentSubtree(t, projectDir, sessionID)
+	outputDir := filepath.Join(tempDir, "agent-export")
+
+	result, err := ExportAgent("/test/project", sessionID[:8], "a1b2", ExportOptions{OutputDir: outputDir, ClaudeDir: tempDir})
+	if err != nil {
+		t.Fatalf("ExportAgent() error = %v", err)
+	}
+
+	if result.AgentID != "a1b2c3d4" || result.SessionID != sessionID {
+		t.Errorf("result IDs = %q/%q, want resolved agent and session", result.AgentID, result.SessionID)
+	}
+	if want := filepath.Join(outputDir, "source", "agent-a1b2c3d4.jsonl"); result.MainSessionFile != want {
+		t.Errorf("MainSessionFile = %q, want %q", result.MainSessionFile, want)
+	}
+	if result.TotalAgents != 1 || result.AgentFiles["c9c9c9c9"] == "" {
+		t.Errorf("AgentFiles = %v, want only the nested agent c9c9c9c9", result.AgentFiles)
+	}
+	if _, ok := result.AgentFiles["f0f0f0f0"]; ok {
+		t.Error("sibling agents outside the subtree should not be exported")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "source", "session.jsonl")); !os.IsNotExist(err) {
+		t.Error("the main session file should not be exported")
+	}
+}
+
+func TestExportAgent_UnknownAgent(t *testing.T) {
+	tempDir := t.TempDir()
+	_, sessionID := setupTestSession(t, tempDir)
+
+	_, err := ExportAgent("/test/project", sessionID, "zzzz", ExportOptions{OutputDir: filepath.Join(tempDir, "out"), ClaudeDir: tempDir})
+	if err == nil || !strings.Contains(err.Error(), "failed to resolve agent ID") {
+		t.Errorf("expected agent resolution error, got %v", err)
+	}
+}
+
+func TestReadAgentExportEntries_Chronological(t *testing.T) {
+	tempDir := t.TempDir()
+	projectDir, sessionID := setupTestSession(t, tempDir)
+	setupAgentSubtree(t, projectDir, sessionID)
+
+	result, err := ExportAgent("/test/project", sessionID, "a1b2c3d4", ExportOptions{OutputDir: filepath.Join(tempDir, "out"), ClaudeDir: tempDir})
+	if err != nil {
+		t.Fatalf("ExportAgent() error = %v", err)
+	}
+
+	entries, err := ReadAgentExportEntries(result)
+	if err != nil {
+		t.Fatalf("ReadAgentExportEntries() error = %v", err)
+	}
+	var uuids []string
+	for _, e := range entries {
+		uuids = append(uuids, e.UUID)
+	}
+	if got := strings.Join(uuids, ","); got != "p-1,p-2,c-1,p-3" {
+		t.Errorf("entry order = %s, want p-1,p-2,c-1,p-3", got)
+	}
+}
+
+func TestRenderQueryResults_AgentSubtreeShowsNestedAgentID(t *testing.T) {
+	tempDir := t.TempDir()
+	projectDir, sessionID := setupTestSession(t, tempDir)
+	setupAgentSubtree(t, projectDir, sessionID)
+
+	result, err := ExportAgent("/test/project", sessionID, "a1b2c3d4", ExportOptions{OutputDir: filepath.Join(tempDir, "out"), ClaudeDir: tempDir})
+	if err != nil {
+		t.Fatalf("ExportAgent() error = %v", err)
+	}
+	entries, err := ReadAgentExportEntries(result)
+	if err != nil {
+		t.Fatalf("ReadAgentExportEntries() error = %v", err)
+	}
+
+	html, err := RenderQueryResults(entries, "/test/project", sessionID, "", result.AgentID, "Orchestrator", "Agent")
+	if err != nil {
+		t.Fatalf("RenderQueryResults() error = %v", err)
+	}
+	for _, want := range []string{"Nested result", "Parent done", "Orchestrator", "c9c9c9c9", "<style>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("agent page should contain %q", want)
+		}
+	}
+	if strings.Contains(html, `<link rel="stylesheet"`) {
+		t.Error("agent page should be self-contained (inline CSS)")
+	}
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/agent"
+)
+
+// agentOverflowID is the element ID of the section listing agents beyond
+// ExportOptions.MaxAgents.
+const agentOverflowID = "agent-overflow"
+
+// OverflowAgents returns the IDs of the agents in the tree that are not rendered under
+// a limit of maxAgents: all but the maxAgents agents with the most entries (ties go to
+// the lower ID). They are ordered by entry count, largest first. A maxAgents of 0 or
+// less renders every agent and returns nil.
+func OverflowAgents(agents []*agent.TreeNode, maxAgents int) []string {
+	return overflowAgents(buildAgentMap(agents), maxAgents)
+}
+
+// overflowAgents is OverflowAgents for an agent map (see buildAgentMap).
+func overflowAgents(agentMap map[string]int, maxAgents int) []string {
+	if maxAgents <= 0 || len(agentMap) <= maxAgents {
+		return nil
+	}
+
+	return agentsByEntryCount(agentMap)[maxAgents:]
+}
+
+// agentsByEntryCount returns the agent IDs of agentMap ordered by entry count, largest
+// first, with ties going to the lower ID.
+func agentsByEntryCount(agentMap map[string]int) []string {
+	ids := make([]string, 0, len(agentMap))
+	for id := range agentMap {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if agentMap[ids[i]] != agentMap[ids[j]] {
+			return agentMap[ids[i]] > agentMap[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// agentDetail is one agent of the header's agent tooltip (see agentDetailsJSON).
+type agentDetail struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+// agentDetailsJSON encodes agentDetails (agent IDs to message counts) for the header's
+// agent tooltip as a JSON array of {"id","count"} objects in agentsByEntryCount order,
+// so the tooltip needs no sorting and the same session always encodes the same way.
+func agentDetailsJSON(agentDetails map[string]int) string {
+	details := make([]agentDetail, 0, len(agentDetails))
+	for _, id := range agentsByEntryCount(agentDetails) {
+		details = append(details, agentDetail{ID: id, Count: agentDetails[id]})
+	}
+	jsonBytes, err := json.Marshal(details)
+	if err != nil {
+		return "[]"
+	}
+	return string(jsonBytes)
+}
+
+// renderAgentOverflow renders the collapsible "… and N more agents" section listing the
+// agents left out by ExportOptions.MaxAgents, by ID with their entry counts. Each item
+// carries the agent's subagent anchor, so agent ID links still lead somewhere.
+func renderAgentOverflow(overflow []string, agentMap map[string]int, sessionID, projectPath string, shortIDs map[string]string) string {
+	if len(overflow) == 0 {
+		return ""
+	}
+
+	noun := "agents"
+	if len(overflow) == 1 {
+		noun = "agent"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<details class="agent-overflow" id="%s">`, agentOverflowID))
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf(`  <summary>… and %d more %s</summary>`, len(overflow), noun))
+	sb.WriteString("\n")
+	sb.WriteString(`  <ul class="agent-overflow-list">`)
+	sb.WriteString("\n")
+	for _, id := range overflow {
+		shortID, typeLabel := shortAgentID(id, shortIDs)
+		typeBadge := ""
+		if typeLabel != "" {
+			typeBadge = fmt.Sprintf(` <span class="subagent-type">%s</span>`, escapeHTML(typeLabel))
+		}
+		sb.WriteString(fmt.Sprintf(`    <li id="%s" data-agent-id="%s"><code>%s</code>%s <span class="subagent-meta">(%d entries)</span>%s</li>`,
+			escapeHTML(subagentAnchorID(id)),
+			escapeHTML(id),
+			escapeHTML(shortID),
+			typeBadge,
+			agentMap[id],
+			renderSubagentBadgeWithCopy(id, sessionID, projectPath)))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("  </ul>\n")
+	sb.WriteString("</details>\n")
+	return sb.String()
+}
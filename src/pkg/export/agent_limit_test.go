@@ -0,0 +1,165 @@
+package export
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestOverflowAgents(t *testing.T) {
+	agents := []*agent.TreeNode{
+		{AgentID: "small", EntryCount: 2},
+		{AgentID: "big", EntryCount: 50, Children: []*agent.TreeNode{
+			{AgentID: "nested", EntryCount: 30},
+		}},
+		{AgentID: "tie-b", EntryCount: 10},
+		{AgentID: "tie-a", EntryCount: 10},
+	}
+
+	tests := []struct {
+		name      string
+		maxAgents int
+		want      []string
+	}{
+		{"unlimited", 0, nil},
+		{"negative", -1, nil},
+		{"limit above count", 6, nil},
+		{"limit equals count", 5, nil},
+		{"top two", 2, []string{"tie-a", "tie-b", "small"}},
+		{"tie goes to lower ID", 3, []string{"tie-b", "small"}},
+		{"one", 1, []string{"nested", "tie-a", "tie-b", "small"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OverflowAgents(agents, tt.maxAgents); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OverflowAgents(%d) = %v, want %v", tt.maxAgents, got, tt.want)
+			}
+		})
+	}
+}
+
+// manyAgentsSession returns a session spawning agents a1..a4 (with 4..1 entries) and
+// the matching agent tree.
+func manyAgentsSession() ([]models.ConversationEntry, []*agent.TreeNode) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: json.RawMessage(`"Start"`)},
+	}
+	var agents []*agent.TreeNode
+	for i, id := range []string{"a1", "a2", "a3", "a4"} {
+		entries = append(entries, models.ConversationEntry{UUID: "q-" + id, Type: models.EntryTypeQueueOperation, AgentID: id, Timestamp: "2026-02-01T10:00:01Z"})
+		agents = append(agents, &agent.TreeNode{AgentID: id, EntryCount: 4 - i})
+	}
+	entries = append(entries, models.ConversationEntry{
+		UUID: "m1", Type: models.EntryTypeAssistant, AgentID: "a4", Timestamp: "2026-02-01T10:00:02Z",
+		Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"From a4"}]}`),
+	})
+	return entries, agents
+}
+
+func TestRenderConversation_MaxAgents(t *testing.T) {
+	entries, agents := manyAgentsSession()
+
+	html, err := RenderConversationWithOptions(entries, agents, nil, ExportOptions{MaxAgents: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"a1", "a2"} {
+		if !strings.Contains(html, `<div class="subagent collapsible collapsed" id="agent-`+id+`"`) {
+			t.Errorf("top agent %s should keep its subagent section", id)
+		}
+	}
+	for _, id := range []string{"a3", "a4"} {
+		if strings.Contains(html, `<div class="subagent collapsible collapsed" id="agent-`+id+`"`) {
+			t.Errorf("agent %s beyond the limit should not get a subagent section", id)
+		}
+		if !strings.Contains(html, `<li id="agent-`+id+`" data-agent-id="`+id+`">`) {
+			t.Errorf("agent %s beyond the limit should be listed in the overflow section", id)
+		}
+	}
+	if !strings.Contains(html, `<details class="agent-overflow" id="agent-overflow">`) ||
+		!strings.Contains(html, "<summary>… and 2 more agents</summary>") {
+		t.Error("overflow section should summarize the hidden agents")
+	}
+
+	// The header still counts every agent
+	if !strings.Contains(html, "Subagents[4]") {
+		t.Error("stats header should report the true agent count")
+	}
+
+	// Hidden agents' ID badges link to their overflow entry
+	if !strings.Contains(html, `href="#agent-a4"`) {
+		t.Error("agent ID badge of a hidden agent should link to its overflow entry")
+	}
+}
+
+func TestRenderConversation_MaxAgentsBlocks(t *testing.T) {
+	entries, agents := manyAgentsSession()
+
+	blocks := renderConversationBlocks(entries, buildAgentMap(agents), ComputeSessionStats(entries, agents), ExportOptions{MaxAgents: 3})
+
+	subagents := 0
+	for _, b := range blocks {
+		if b.Kind == BlockSubagent {
+			subagents++
+		}
+	}
+	if subagents != 3 {
+		t.Errorf("got %d subagent blocks, want 3", subagents)
+	}
+	if last := blocks[len(blocks)-1]; last.Kind != BlockAgentOverflow || !strings.Contains(string(last.HTML), "… and 1 more agent<") {
+		t.Errorf("last block = %+v, want the overflow section", last)
+	}
+}
+
+func TestRenderConversation_MaxAgentsUnlimited(t *testing.T) {
+	entries, agents := manyAgentsSession()
+
+	for _, max := range []int{0, 4, 10} {
+		html, err := RenderConversationWithOptions(entries, agents, nil, ExportOptions{MaxAgents: max})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(html, "agent-overflow\"") {
+			t.Errorf("MaxAgents %d should not add an overflow section", max)
+		}
+		if got := strings.Count(html, `<div class="subagent collapsible collapsed"`); got != 4 {
+			t.Errorf("MaxAgents %d rendered %d subagent sections, want 4", max, got)
+		}
+	}
+}
+
+func TestAgentDetailsJSON(t *testing.T) {
+	details := map[string]int{"b": 3, "a": 3, "c": 10, "d": 1}
+
+	want := `[{"id":"c","count":10},{"id":"a","count":3},{"id":"b","count":3},{"id":"d","count":1}]`
+	if got := agentDetailsJSON(details); got != want {
+		t.Errorf("agentDetailsJSON() = %s, want %s", got, want)
+	}
+	if got := agentDetailsJSON(nil); got != "[]" {
+		t.Errorf("agentDetailsJSON(nil) = %s, want []", got)
+	}
+}
+
+func TestRenderHTMLHeader_AgentDetailsStable(t *testing.T) {
+	stats := &SessionStats{SessionID: "s1", AgentCount: 20, TotalAgentMessages: 210}
+	details := make(map[string]int)
+	for i := 0; i < 20; i++ {
+		details[string(rune('a'+i))+"-agent"] = i % 4
+	}
+
+	first := renderHTMLHeader(stats, details, localizer{})
+	for i := 0; i < 10; i++ {
+		if renderHTMLHeader(stats, details, localizer{}) != first {
+			t.Fatal("header should render identically for the same agents")
+		}
+	}
+	if !strings.Contains(first, `data-agent-details='[{&#34;id&#34;:&#34;d-agent&#34;,&#34;count&#34;:3},`) {
+		t.Error("agent details should be an array ordered by count, then ID")
+	}
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRenderAgentIDWithCopyWith_Anchor(t *testing.T) {
+	entry := models.ConversationEntry{AgentID: "a12eb64f9c0d1e2f"}
+
+	linked := renderAgentIDWithCopyWith(entry, entry.AgentID, "", "", "", "Assistant", nil, map[string]bool{entry.AgentID: true})
+	if !strings.Contains(linked, `<a class="agent-id-link" href="#agent-a12eb64f9c0d1e2f" title="Go to subagent section">a12eb64f</a>`) {
+		t.Errorf("badge should link to the subagent section, got %s", linked)
+	}
+	if !strings.Contains(linked, "copy-btn") {
+		t.Error("linked badge should keep the copy button")
+	}
+
+	plain := renderAgentIDWithCopyWith(entry, entry.AgentID, "", "", "", "Assistant", nil, map[string]bool{"other": true})
+	if strings.Contains(plain, "<a ") {
+		t.Errorf("badge for an agent without a section should be copy-only, got %s", plain)
+	}
+}
+
+func TestRenderSubagentPlaceholder_AnchorID(t *testing.T) {
+	html := renderSubagentPlaceholder("a12eb64f9c0d1e2f", map[string]int{"a12eb64f9c0d1e2f": 3}, "", "")
+	if !strings.Contains(html, `id="agent-a12eb64f9c0d1e2f" data-agent-id="a12eb64f9c0d1e2f"`) {
+		t.Errorf("placeholder should carry the anchor ID, got %s", html)
+	}
+}
+
+func TestSubagentAnchors(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "q1", Type: models.EntryTypeQueueOperation, AgentID: "spawned"},
+		{UUID: "q2", Type: models.EntryTypeQueueOperation, AgentID: "not-in-tree"},
+		{UUID: "a1", Type: models.EntryTypeAssistant, AgentID: "nested"},
+	}
+	agentMap := map[string]int{"spawned": 2, "nested": 1}
+
+	anchors := subagentAnchors(entries, agentMap)
+	if !anchors["spawned"] {
+		t.Error("an agent in the tree with a placeholder should be anchored")
+	}
+	if anchors["not-in-tree"] {
+		t.Error("an agent missing from the tree should not be anchored")
+	}
+	if anchors["nested"] {
+		t.Error("an agent without a placeholder on the page should not be anchored")
+	}
+}
+
+func TestRenderConversation_AgentIDLinks(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: json.RawMessage(`"Start"`)},
+		{UUID: "q1", Type: models.EntryTypeQueueOperation, AgentID: "a1b2c3d4e5", Timestamp: "2026-02-01T10:00:01Z"},
+		{UUID: "a1", Type: models.EntryTypeAssistant, AgentID: "a1b2c3d4e5", Timestamp: "2026-02-01T10:00:02Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Agent reporting"}]}`)},
+		{UUID: "a2", Type: models.EntryTypeAssistant, AgentID: "f9e8d7c6b5", Timestamp: "2026-02-01T10:00:03Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Unknown agent"}]}`)},
+	}
+	agents := []*agent.TreeNode{{AgentID: "a1b2c3d4e5", EntryCount: 1}}
+
+	html, err := RenderConversationWithStats(entries, agents, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, `href="#agent-a1b2c3d4e5"`) || !strings.Contains(html, `id="agent-a1b2c3d4e5"`) {
+		t.Error("badge of an exported agent should link to its placeholder")
+	}
+	if strings.Contains(html, `href="#agent-f9e8d7c6b5"`) {
+		t.Error("badge of an agent missing from the export should not link")
+	}
+}
@@ -0,0 +1,81 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// AnnotationsFileName is the sidecar file in a session's folder (next to its subagents)
+// that the export command reads annotations from (see LoadAnnotations).
+const AnnotationsFileName = "annotations.json"
+
+// Annotation is a reviewer's note on one message of an export, from an annotations
+// sidecar (see LoadAnnotations).
+type Annotation struct {
+	Note string   `json:"note,omitempty"` // Free text shown under the message
+	Tags []string `json:"tags,omitempty"` // Short labels shown as chips, e.g. "bug" or "good-prompt"
+}
+
+// LoadAnnotations reads an annotations sidecar: a JSON object mapping entry UUIDs to
+// annotations, e.g. {"<uuid>": {"note": "Check this", "tags": ["bug"]}}. Tags are
+// trimmed, and annotations left with neither a note nor tags are dropped. A missing
+// file is not an error and returns no annotations; a file that is not such an object
+// returns an error.
+func LoadAnnotations(path string) (map[string]Annotation, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read annotations: %w", err)
+	}
+
+	var raw map[string]Annotation
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid annotations file %s: %w", path, err)
+	}
+
+	annotations := make(map[string]Annotation, len(raw))
+	for uuid, annotation := range raw {
+		var tags []string
+		for _, tag := range annotation.Tags {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		annotation.Note = strings.TrimSpace(annotation.Note)
+		annotation.Tags = tags
+		if uuid == "" || (annotation.Note == "" && len(tags) == 0) {
+			continue
+		}
+		annotations[uuid] = annotation
+	}
+	return annotations, nil
+}
+
+// annotationMarker flags an annotated message in its header, so reviewers notice it
+// before reaching the note.
+const annotationMarker = `<span class="annotation-marker" title="This message is annotated">✎ annotated</span>`
+
+// renderAnnotation renders an annotation below the content of its message: the note,
+// keeping its line breaks, then its tags.
+func renderAnnotation(annotation Annotation) string {
+	var sb strings.Builder
+	sb.WriteString(`    <aside class="annotation" role="note">`)
+	if annotation.Note != "" {
+		sb.WriteString(fmt.Sprintf(`<div class="annotation-note">%s</div>`, escapeHTML(annotation.Note)))
+	}
+	if len(annotation.Tags) > 0 {
+		sb.WriteString(`<div class="annotation-tags">`)
+		for _, tag := range annotation.Tags {
+			sb.WriteString(fmt.Sprintf(`<span class="annotation-tag">%s</span>`, escapeHTML(tag)))
+		}
+		sb.WriteString(`</div>`)
+	}
+	sb.WriteString("</aside>\n")
+	return sb.String()
+}
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestLoadAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, AnnotationsFileName)
+	content := `{
+  "u1": {"note": "  Check this <script>  ", "tags": ["bug", " ", " perf "]},
+  "u2": {"tags": ["good-prompt"]},
+  "u3": {"note": "   "},
+  "": {"note": "no entry"}
+}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	annotations, err := LoadAnnotations(path)
+	if err != nil {
+		t.Fatalf("LoadAnnotations() error = %v", err)
+	}
+	if len(annotations) != 2 {
+		t.Fatalf("LoadAnnotations() = %+v, want u1 and u2 only", annotations)
+	}
+	if got := annotations["u1"]; got.Note != "Check this <script>" || strings.Join(got.Tags, ",") != "bug,perf" {
+		t.Errorf("u1 = %+v", got)
+	}
+
+	// A missing sidecar is not an error
+	if annotations, err := LoadAnnotations(filepath.Join(dir, "missing.json")); err != nil || annotations != nil {
+		t.Errorf("LoadAnnotations(missing) = %v, %v; want nil, nil", annotations, err)
+	}
+
+	for _, invalid := range []string{`not json`, `["u1"]`, `{"u1": "a note"}`} {
+		if err := os.WriteFile(path, []byte(invalid), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadAnnotations(path); err == nil {
+			t.Errorf("LoadAnnotations(%s) should fail", invalid)
+		}
+	}
+}
+
+func TestRenderEntry_Annotation(t *testing.T) {
+	entry := models.ConversationEntry{UUID: "u1", Type: models.EntryTypeUser, Message: json.RawMessage(`"Fix the build"`)}
+	opts := ExportOptions{Annotations: map[string]Annotation{
+		"u1": {Note: "Too vague <b>\nask for the error", Tags: []string{"prompt & review"}},
+	}}
+
+	html := renderEntryWith(entry, nil, "", "", "", "User", "Assistant", entryRenderOptions{opts: opts})
+	for _, want := range []string{
+		`<div class="message-row user annotated" data-uuid="u1">`,
+		annotationMarker,
+		`<div class="annotation-note">Too vague &lt;b&gt;` + "\nask for the error</div>",
+		`<span class="annotation-tag">prompt &amp; review</span>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("annotated entry missing %q:\n%s", want, html)
+		}
+	}
+	if strings.Index(html, `class="annotation"`) < strings.Index(html, `class="message-content"`) {
+		t.Error("the annotation should follow the message content")
+	}
+
+	other := models.ConversationEntry{UUID: "u2", Type: models.EntryTypeUser, Message: json.RawMessage(`"Thanks"`)}
+	html = renderEntryWith(other, nil, "", "", "", "User", "Assistant", entryRenderOptions{opts: opts})
+	if strings.Contains(html, "annotat") {
+		t.Errorf("entries without an annotation should be unchanged:\n%s", html)
+	}
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// renderAPIError renders a failed API request (see models.ConversationEntry.IsAPIError)
+// as a warning marker: the status and error type, the error message and, while Claude
+// Code was retrying, when and how often it retried.
+func renderAPIError(entry models.ConversationEntry, ro entryRenderOptions) string {
+	detail, _ := entry.APIErrorDetail()
+
+	label := "API error"
+	if detail.IsRateLimit() {
+		label = "Rate limited"
+	}
+	if detail.Status != 0 {
+		label += fmt.Sprintf(" %d", detail.Status)
+	}
+	if detail.Type != "" {
+		label += fmt.Sprintf(" (%s)", detail.Type)
+	}
+
+	var parts []string
+	if detail.Message != "" {
+		parts = append(parts, detail.Message)
+	}
+	if retry := formatAPIErrorRetry(detail); retry != "" {
+		parts = append(parts, retry)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<div class="api-error-marker" role="alert" data-uuid="%s"><span class="api-error-icon" aria-hidden="true">⚠</span> <span class="api-error-label">%s</span>`,
+		escapeHTML(entry.UUID), escapeHTML(label)))
+	if len(parts) > 0 {
+		sb.WriteString(fmt.Sprintf(` <span class="api-error-detail">%s</span>`, escapeHTML(strings.Join(parts, " · "))))
+	}
+	sb.WriteString(renderTimestampSpan(entry.Timestamp, formatTimestampReadable(entry.Timestamp), ro))
+	sb.WriteString("</div>\n")
+	return sb.String()
+}
+
+// formatAPIErrorRetry describes the retry of a failed request, e.g.
+// "retrying in 5s (attempt 2 of 10)". It returns "" if the request was not retried.
+func formatAPIErrorRetry(detail models.APIError) string {
+	if detail.RetryIn <= 0 && detail.RetryAttempt == 0 {
+		return ""
+	}
+	retry := "retrying"
+	switch {
+	case detail.RetryIn >= time.Second:
+		retry += " in " + formatDuration(detail.RetryIn)
+	case detail.RetryIn > 0:
+		retry += fmt.Sprintf(" in %dms", detail.RetryIn.Milliseconds())
+	}
+	switch {
+	case detail.RetryAttempt > 0 && detail.MaxRetries > 0:
+		retry += fmt.Sprintf(" (attempt %d of %d)", detail.RetryAttempt, detail.MaxRetries)
+	case detail.RetryAttempt > 0:
+		retry += fmt.Sprintf(" (attempt %d)", detail.RetryAttempt)
+	}
+	return retry
+}
@@ -0,0 +1,98 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// apiErrorSession returns a session with a rate-limited request that Claude Code retried
+// and a failed Bash command, which is a tool error rather than an API error.
+func apiErrorSession() []models.ConversationEntry {
+	return []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: json.RawMessage(`"Run the tests"`)},
+		{
+			UUID: "s1", Type: models.EntryTypeSystem, Timestamp: "2026-02-01T10:00:01Z",
+			Subtype: "api_error", Level: "error",
+			Error:     json.RawMessage(`{"status":429,"error":{"type":"error","error":{"type":"rate_limit_error","message":"Too <many> requests"}}}`),
+			RetryInMs: 5000, RetryAttempt: 1, MaxRetries: 10,
+		},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:10Z", Message: json.RawMessage(`{"role":"assistant","content":[
+			{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}}]}`)},
+		{UUID: "u2", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:11Z", Message: json.RawMessage(`{"role":"user","content":[
+			{"type":"tool_result","tool_use_id":"t1","content":"FAIL","is_error":true}]}`)},
+	}
+}
+
+func TestRenderAPIError(t *testing.T) {
+	entries := apiErrorSession()
+	html := renderAPIError(entries[1], entryRenderOptions{})
+
+	for _, want := range []string{
+		`<div class="api-error-marker" role="alert" data-uuid="s1">`,
+		`<span class="api-error-label">Rate limited 429 (rate_limit_error)</span>`,
+		`Too &lt;many&gt; requests · retrying in 5s (attempt 1 of 10)`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("marker missing %q:\n%s", want, html)
+		}
+	}
+}
+
+func TestFormatAPIErrorRetry(t *testing.T) {
+	tests := []struct {
+		detail models.APIError
+		want   string
+	}{
+		{models.APIError{}, ""},
+		{models.APIError{RetryIn: 2 * time.Minute}, "retrying in 2m"},
+		{models.APIError{RetryIn: 500 * time.Millisecond, RetryAttempt: 3}, "retrying in 500ms (attempt 3)"},
+		{models.APIError{RetryAttempt: 2, MaxRetries: 10}, "retrying (attempt 2 of 10)"},
+	}
+	for _, tt := range tests {
+		if got := formatAPIErrorRetry(tt.detail); got != tt.want {
+			t.Errorf("formatAPIErrorRetry(%+v) = %q, want %q", tt.detail, got, tt.want)
+		}
+	}
+}
+
+func TestRenderConversation_APIErrors(t *testing.T) {
+	entries := apiErrorSession()
+	stats := ComputeSessionStats(entries, nil)
+
+	html, err := RenderConversationWithOptions(entries, nil, stats, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(html, `class="api-error-marker"`); got != 1 {
+		t.Errorf("got %d API error markers, want 1 (the tool error is not one)", got)
+	}
+	if !strings.Contains(html, "⚠ API errors: 1") {
+		t.Error("header should count the API errors")
+	}
+}
+
+func TestComputeSessionStats_APIErrorCount(t *testing.T) {
+	stats := ComputeSessionStats(apiErrorSession(), nil)
+	if stats.APIErrorCount != 1 {
+		t.Errorf("APIErrorCount = %d, want 1", stats.APIErrorCount)
+	}
+
+	lines := statsSummaryLines(stats, newLocalizer(""))
+	found := false
+	for _, line := range lines {
+		if line[0] == "API errors" && line[1] == "1" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("stats lines should include the API error count: %v", lines)
+	}
+
+	if stats := ComputeSessionStats(apiErrorSession()[2:], nil); stats.APIErrorCount != 0 {
+		t.Errorf("APIErrorCount = %d for a tool error, want 0", stats.APIErrorCount)
+	}
+}
@@ -0,0 +1,99 @@
+package export
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// WriteArchive streams the export in dir to w as a zip archive. Files keep their paths
+// relative to dir (index.html, static/style.css, source/session.jsonl, ...) under a
+// top-level folder named root, so the HTML finds its assets once extracted. An empty
+// root puts the files at the top of the archive.
+func WriteArchive(w io.Writer, dir, root string) error {
+	zw := zip.NewWriter(w)
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+		return addArchiveEntry(zw, p, path.Join(root, filepath.ToSlash(rel)), d)
+	})
+	if err != nil {
+		zw.Close()
+		return fmt.Errorf("failed to archive export: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to archive export: %w", err)
+	}
+	return nil
+}
+
+// addArchiveEntry adds the directory or file at src to zw as name, keeping its
+// modification time and mode. Files are compressed and copied in.
+func addArchiveEntry(zw *zip.Writer, src, name string, d fs.DirEntry) error {
+	info, err := d.Info()
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	if d.IsDir() {
+		header.Name = name + "/"
+		_, err := zw.CreateHeader(header)
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dst, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, f)
+	return err
+}
+
+// ArchiveExport writes the export in dir to the zip file zipPath (see WriteArchive).
+// The archive's top-level folder is named after zipPath without its .zip extension.
+func ArchiveExport(dir, zipPath string) (err error) {
+	f, err := os.Create(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("failed to write archive: %w", cerr)
+		}
+		if err != nil {
+			os.Remove(zipPath)
+		}
+	}()
+
+	root := filepath.Base(zipPath)
+	root = root[:len(root)-len(filepath.Ext(root))]
+	return WriteArchive(f, dir, root)
+}
@@ -0,0 +1,131 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeExportTree creates a small export layout under dir.
+func writeExportTree(t *testing.T, dir string) {
+	t.Helper()
+	files := map[string]string{
+		"index.html":              `<link rel="stylesheet" href="static/style.css">`,
+		"static/style.css":        "body {}",
+		"source/session.jsonl":    `{"type":"user"}`,
+		"source/agents/agent.txt": "agent",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readArchive returns the file names and contents of a zip archive.
+func readArchive(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		if strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestWriteArchive_PreservesLayout(t *testing.T) {
+	dir := t.TempDir()
+	writeExportTree(t, dir)
+
+	var buf bytes.Buffer
+	if err := WriteArchive(&buf, dir, "export"); err != nil {
+		t.Fatalf("WriteArchive() error = %v", err)
+	}
+
+	files := readArchive(t, buf.Bytes())
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	want := []string{"export/index.html", "export/source/agents/agent.txt", "export/source/session.jsonl", "export/static/style.css"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("archive files = %v, want %v", names, want)
+	}
+	if files["export/static/style.css"] != "body {}" {
+		t.Errorf("style.css content = %q", files["export/static/style.css"])
+	}
+}
+
+func TestWriteArchive_NoRoot(t *testing.T) {
+	dir := t.TempDir()
+	writeExportTree(t, dir)
+
+	var buf bytes.Buffer
+	if err := WriteArchive(&buf, dir, ""); err != nil {
+		t.Fatalf("WriteArchive() error = %v", err)
+	}
+	if _, ok := readArchive(t, buf.Bytes())["index.html"]; !ok {
+		t.Error("files should be at the top of the archive without a root")
+	}
+}
+
+func TestWriteArchive_MissingDir(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteArchive(&buf, filepath.Join(t.TempDir(), "missing"), "export")
+	if err == nil || !strings.Contains(err.Error(), "failed to archive export") {
+		t.Errorf("expected archive error, got %v", err)
+	}
+}
+
+func TestArchiveExport(t *testing.T) {
+	dir := t.TempDir()
+	writeExportTree(t, dir)
+	zipPath := filepath.Join(t.TempDir(), "session-abc.zip")
+
+	if err := ArchiveExport(dir, zipPath); err != nil {
+		t.Fatalf("ArchiveExport() error = %v", err)
+	}
+	data, err := os.ReadFile(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := readArchive(t, data)["session-abc/index.html"]; !ok {
+		t.Error("archive root should be named after the zip file")
+	}
+}
+
+func TestArchiveExport_RemovesPartialFile(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "broken.zip")
+
+	if err := ArchiveExport(filepath.Join(t.TempDir(), "missing"), zipPath); err == nil {
+		t.Fatal("ArchiveExport() should fail for a missing export")
+	}
+	if _, err := os.Stat(zipPath); !os.IsNotExist(err) {
+		t.Error("a failed archive should not be left behind")
+	}
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// attachmentRe matches a file pasted or referenced in a user message, such as
+	// <file path="main.go">...</file>. Opening and closing tag names are checked to match.
+	attachmentRe = regexp.MustCompile(`(?s)<(file|attachment|document)(\s[^>]*)>(.*?)</(file|attachment|document)>`)
+
+	// attachmentPathRe extracts the file path from an attachment's attributes.
+	attachmentPathRe = regexp.MustCompile(`\b(?:path|file_path|filename|name)="([^"]*)"`)
+)
+
+// Attachments longer than either limit start collapsed.
+const (
+	attachmentCollapseLines = 20
+	attachmentCollapseBytes = 2000
+)
+
+// formatUserContentWith formats user message content like formatUserContent. Attached
+// files (file, attachment or document tags with a path attribute) are rendered as
+// collapsible code blocks headed by the file name and a file:// link; relative paths
+// are resolved against projectPath, as are file @-mentions (see formatMentions). Other
+// tags keep the formatXMLTags handling.
+func formatUserContentWith(content, projectPath string) string {
+	if content == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	lastEnd := 0
+	for _, m := range attachmentRe.FindAllStringSubmatchIndex(content, -1) {
+		tag, attrs, closing := content[m[2]:m[3]], content[m[4]:m[5]], content[m[8]:m[9]]
+		pathMatch := attachmentPathRe.FindStringSubmatch(attrs)
+		if tag != closing || pathMatch == nil {
+			continue
+		}
+
+		sb.WriteString(formatXMLTags(content[lastEnd:m[0]], projectPath))
+		sb.WriteString(renderAttachment(html.UnescapeString(pathMatch[1]), content[m[6]:m[7]], projectPath))
+		lastEnd = m[1]
+	}
+	sb.WriteString(formatXMLTags(content[lastEnd:], projectPath))
+
+	return sb.String()
+}
+
+// renderAttachment renders an attached file as a <details> block with the file name and
+// a link to the file, collapsed when the content is large.
+func renderAttachment(path, content, projectPath string) string {
+	// Drop the newlines that usually pad the content inside the tags
+	content = strings.TrimPrefix(strings.TrimSuffix(content, "\n"), "\n")
+
+	absPath := path
+	if !filepath.IsAbs(path) && projectPath != "" {
+		absPath = filepath.Join(projectPath, path)
+	}
+	absPath = filepath.Clean(absPath)
+
+	lines := strings.Count(content, "\n") + 1
+	open := " open"
+	if lines > attachmentCollapseLines || len(content) > attachmentCollapseBytes {
+		open = ""
+	}
+
+	lineLabel := "lines"
+	if lines == 1 {
+		lineLabel = "line"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<details class="attachment"%s>`, open))
+	sb.WriteString(fmt.Sprintf(`<summary class="attachment-header"><span class="attachment-name">%s</span>`, escapeHTML(filepath.Base(absPath))))
+	sb.WriteString(fmt.Sprintf(`<a href="%s" class="file-link" title="%s">%s</a>`, escapeHTML(buildFileURL(absPath)), escapeHTML(absPath), escapeHTML(path)))
+	sb.WriteString(fmt.Sprintf(`<span class="attachment-size">%d %s</span></summary>`, lines, lineLabel))
+	sb.WriteString(renderCodeBlock(CodeBlock{
+		Language: strings.ToLower(strings.TrimPrefix(filepath.Ext(absPath), ".")),
+		Code:     content,
+	}))
+	sb.WriteString(`</details>`)
+	return sb.String()
+}
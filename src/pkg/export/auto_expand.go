@@ -0,0 +1,14 @@
+package export
+
+import "strings"
+
+// autoExpandsTool reports whether calls of the named tool start expanded in the HTML
+// export, per opts.AutoExpandTools.
+func autoExpandsTool(name string, opts ExportOptions) bool {
+	for _, tool := range opts.AutoExpandTools {
+		if strings.EqualFold(tool, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestAutoExpandsTool(t *testing.T) {
+	opts := ExportOptions{AutoExpandTools: []string{"edit", "BASH"}}
+	for name, want := range map[string]bool{"Edit": true, "Bash": true, "Read": false, "": false} {
+		if got := autoExpandsTool(name, opts); got != want {
+			t.Errorf("autoExpandsTool(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if autoExpandsTool("Edit", ExportOptions{}) {
+		t.Error("no tools should auto-expand by default")
+	}
+}
+
+func TestRenderEntry_AutoExpandTools(t *testing.T) {
+	entry := models.ConversationEntry{
+		UUID: "a1",
+		Type: models.EntryTypeAssistant,
+		Message: json.RawMessage(`{"role":"assistant","content":[` +
+			`{"type":"tool_use","id":"toolu_edit","name":"Edit","input":{"file_path":"main.go"}},` +
+			`{"type":"tool_use","id":"toolu_bash","name":"Bash","input":{"command":"go test"}},` +
+			`{"type":"tool_use","id":"toolu_read","name":"Read","input":{"file_path":"go.mod"}}]}`),
+	}
+
+	render := func(noJS bool) string {
+		opts := ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen, AutoExpandTools: []string{"edit", "bash"}, NoJS: noJS}
+		return renderEntryWith(entry, nil, "", "", "", "User", "Assistant", entryRenderOptions{opts: opts})
+	}
+
+	html := render(false)
+	for _, id := range []string{"toolu_edit", "toolu_bash"} {
+		if !strings.Contains(html, `<div class="tool-call collapsible" id="tool-`+id+`"`) {
+			t.Errorf("%s should start expanded", id)
+		}
+	}
+	if !strings.Contains(html, `<div class="tool-call collapsible collapsed" id="tool-toolu_read"`) {
+		t.Error("Read is not listed and should start collapsed")
+	}
+	if got := strings.Count(html, `<div class="tool-body collapsible-content">`); got != 2 {
+		t.Errorf("expected 2 visible tool bodies, got %d", got)
+	}
+	if got := strings.Count(html, `<div class="tool-body hidden collapsible-content collapsed">`); got != 1 {
+		t.Errorf("expected 1 hidden tool body, got %d", got)
+	}
+
+	html = render(true)
+	if !strings.Contains(html, `<details class="tool-call" id="tool-toolu_edit" data-tool-id="toolu_edit" open>`) {
+		t.Error("expanded tool should render as an open <details> without JS")
+	}
+	if !strings.Contains(html, `<details class="tool-call" id="tool-toolu_read" data-tool-id="toolu_read">`) {
+		t.Error("Read should render as a closed <details> without JS")
+	}
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// MaxAvatarInitials is the most characters of Avatar.Initials an avatar shows.
+const MaxAvatarInitials = 3
+
+// Avatar is what the avatar beside a message shows instead of its built-in letter
+// (see ExportOptions.Avatars). An image takes precedence over initials. The avatar
+// stays hidden from screen readers either way: the message header names the role.
+type Avatar struct {
+	// Initials are shown in the avatar circle, e.g. "RL". Only the first
+	// MaxAvatarInitials characters are shown.
+	Initials string
+
+	// ImageURL is an image shown in the avatar circle: an http(s) URL, a data:image/
+	// URL, or a path relative to the exported page. Other URLs are not shown.
+	ImageURL string
+}
+
+// Validate reports whether the avatar can be shown as given: its initials must fit in
+// MaxAvatarInitials characters and its image URL must be one ValidAvatarImageURL accepts.
+func (a Avatar) Validate() error {
+	if n := utf8.RuneCountInString(strings.TrimSpace(a.Initials)); n > MaxAvatarInitials {
+		return fmt.Errorf("avatar initials %q are longer than %d characters", a.Initials, MaxAvatarInitials)
+	}
+	if a.ImageURL != "" && !ValidAvatarImageURL(a.ImageURL) {
+		return fmt.Errorf("avatar image %q must be an http(s) URL, a data:image/ URL, or a relative path", a.ImageURL)
+	}
+	return nil
+}
+
+// ValidAvatarImageURL reports whether rawURL can be used as an avatar image: an http or
+// https URL, a data:image/ URL, or a relative path. Other schemes, such as javascript:
+// or file:, are rejected.
+func ValidAvatarImageURL(rawURL string) bool {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" || (isScriptURL(rawURL) && !isDataImageURL(rawURL)) {
+		return false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "":
+		// A relative path, but not a protocol-relative URL ("//host/x.png")
+		return u.Host == "" && !strings.HasPrefix(rawURL, "//")
+	case "http", "https":
+		return u.Host != ""
+	case "data":
+		return isDataImageURL(rawURL)
+	default:
+		return false
+	}
+}
+
+// isDataImageURL reports whether rawURL is a data: URL of an image.
+func isDataImageURL(rawURL string) bool {
+	return strings.HasPrefix(strings.ToLower(rawURL), "data:image/")
+}
+
+// renderAvatar returns the avatar beside a message of type entryType: the avatar
+// configured in avatars, or the built-in placeholder, which style.css fills with a
+// letter. An image URL that ValidAvatarImageURL rejects is ignored.
+func renderAvatar(entryType models.EntryType, avatars map[models.EntryType]Avatar) string {
+	entryClass := getEntryClass(entryType)
+	avatar := avatars[entryType]
+
+	if avatar.ImageURL != "" && ValidAvatarImageURL(avatar.ImageURL) {
+		return fmt.Sprintf(`<div class="avatar %s avatar-image" aria-hidden="true"><img src="%s" alt=""></div>`,
+			entryClass, escapeHTML(strings.TrimSpace(avatar.ImageURL)))
+	}
+
+	initials := []rune(strings.TrimSpace(avatar.Initials))
+	if len(initials) > MaxAvatarInitials {
+		initials = initials[:MaxAvatarInitials]
+	}
+	if len(initials) > 0 {
+		return fmt.Sprintf(`<div class="avatar %s avatar-initials" aria-hidden="true">%s</div>`,
+			entryClass, escapeHTML(string(initials)))
+	}
+
+	return fmt.Sprintf(`<div class="avatar %s" aria-hidden="true"></div>`, entryClass)
+}
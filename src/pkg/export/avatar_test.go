@@ -0,0 +1,152 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestValidAvatarImageURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/me.png", true},
+		{"http://example.com/me.png", true},
+		{"HTTPS://example.com/me.png", true},
+		{"static/me.png", true},
+		{"../avatars/me.png", true},
+		{"data:image/png;base64,iVBORw0KGgo=", true},
+		{"", false},
+		{"javascript:alert(1)", false},
+		{"JavaScript:alert(1)", false},
+		{"java\tscript:alert(1)", false},
+		{"vbscript:msgbox", false},
+		{"data:text/html,<script>alert(1)</script>", false},
+		{"file:///etc/passwd", false},
+		{"//evil.example/me.png", false},
+		{"https://", false},
+	}
+	for _, tt := range tests {
+		if got := ValidAvatarImageURL(tt.url); got != tt.want {
+			t.Errorf("ValidAvatarImageURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestAvatar_Validate(t *testing.T) {
+	if err := (Avatar{Initials: "RL", ImageURL: "https://example.com/me.png"}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := (Avatar{Initials: "ÉMI"}).Validate(); err != nil {
+		t.Errorf("three characters should be allowed, got %v", err)
+	}
+	if err := (Avatar{Initials: "ABCD"}).Validate(); err == nil {
+		t.Error("Validate() should reject initials longer than MaxAvatarInitials")
+	}
+	if err := (Avatar{ImageURL: "javascript:alert(1)"}).Validate(); err == nil {
+		t.Error("Validate() should reject a script URL")
+	}
+}
+
+func TestRenderAvatar(t *testing.T) {
+	tests := []struct {
+		name    string
+		avatars map[models.EntryType]Avatar
+		want    string
+	}{
+		{
+			name: "default placeholder",
+			want: `<div class="avatar user" aria-hidden="true"></div>`,
+		},
+		{
+			name:    "other type configured",
+			avatars: map[models.EntryType]Avatar{models.EntryTypeAssistant: {Initials: "C"}},
+			want:    `<div class="avatar user" aria-hidden="true"></div>`,
+		},
+		{
+			name:    "initials",
+			avatars: map[models.EntryType]Avatar{models.EntryTypeUser: {Initials: " RL "}},
+			want:    `<div class="avatar user avatar-initials" aria-hidden="true">RL</div>`,
+		},
+		{
+			name:    "initials truncated and escaped",
+			avatars: map[models.EntryType]Avatar{models.EntryTypeUser: {Initials: "<b>x"}},
+			want:    `<div class="avatar user avatar-initials" aria-hidden="true">&lt;b&gt;</div>`,
+		},
+		{
+			name:    "image wins over initials",
+			avatars: map[models.EntryType]Avatar{models.EntryTypeUser: {Initials: "RL", ImageURL: `https://example.com/a.png?x=1&y="2"`}},
+			want:    `<div class="avatar user avatar-image" aria-hidden="true"><img src="https://example.com/a.png?x=1&amp;y=&#34;2&#34;" alt=""></div>`,
+		},
+		{
+			name:    "invalid image falls back to initials",
+			avatars: map[models.EntryType]Avatar{models.EntryTypeUser: {Initials: "RL", ImageURL: "javascript:alert(1)"}},
+			want:    `<div class="avatar user avatar-initials" aria-hidden="true">RL</div>`,
+		},
+		{
+			name:    "invalid image without initials keeps the placeholder",
+			avatars: map[models.EntryType]Avatar{models.EntryTypeUser: {ImageURL: "file:///me.png"}},
+			want:    `<div class="avatar user" aria-hidden="true"></div>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderAvatar(models.EntryTypeUser, tt.avatars); got != tt.want {
+				t.Errorf("renderAvatar() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderConversation_Avatars(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: []byte(`"Hello"`)},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:05Z", Message: []byte(`{"role":"assistant","content":[{"type":"text","text":"Hi"}]}`)},
+	}
+
+	plain, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if !strings.Contains(plain, `<div class="avatar user" aria-hidden="true"></div>`) || strings.Contains(plain, "avatar-initials") {
+		t.Error("avatars should be empty placeholders by default")
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{Avatars: map[models.EntryType]Avatar{
+		models.EntryTypeUser:      {Initials: "RL"},
+		models.EntryTypeAssistant: {ImageURL: "static/claude.png"},
+	}})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	for _, want := range []string{
+		`<div class="avatar user avatar-initials" aria-hidden="true">RL</div>`,
+		`<div class="avatar assistant avatar-image" aria-hidden="true"><img src="static/claude.png" alt=""></div>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+}
+
+func TestRenderTodoEvolutionWith_Avatar(t *testing.T) {
+	calls := []models.ToolUse{
+		{ID: "t1", Name: "TodoWrite", Input: map[string]any{"todos": []any{}}},
+		{ID: "t2", Name: "TodoWrite", Input: map[string]any{"todos": []any{}}},
+	}
+	html := renderTodoEvolutionWith(models.ConversationEntry{}, calls, entryRenderOptions{opts: ExportOptions{Avatars: map[models.EntryType]Avatar{models.EntryTypeAssistant: {Initials: "C"}}}})
+	if !strings.Contains(html, `<div class="avatar assistant avatar-initials" aria-hidden="true">C</div>`) {
+		t.Errorf("todo checklist should use the assistant avatar:\n%s", html)
+	}
+}
+
+func TestCSSContent_ConfiguredAvatarsHideLetter(t *testing.T) {
+	css := GetStyleCSS()
+	for _, want := range []string{".avatar.avatar-initials::before", ".avatar.avatar-image::before", ".avatar.avatar-image img"} {
+		if !strings.Contains(css, want) {
+			t.Errorf("style.css missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package export
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// bashExitCodeRe matches the "Exit code N" line Claude Code puts at the start of the
+// result of a failed Bash command.
+var bashExitCodeRe = regexp.MustCompile(`^Exit code (-?\d+)(?:\r?\n|$)`)
+
+// bashExitStatus splits the exit code off the start of a Bash result. ok is false if the
+// result does not report one, in which case output is content unchanged.
+func bashExitStatus(content string) (code, output string, ok bool) {
+	m := bashExitCodeRe.FindStringSubmatch(content)
+	if m == nil {
+		return "", content, false
+	}
+	return m[1], content[len(m[0]):], true
+}
+
+// bashStreamRe matches a Bash result made only of <bash-stdout> and <bash-stderr> blocks,
+// the form Claude Code records for commands the user runs directly.
+var bashStreamRe = regexp.MustCompile(`(?s)^\s*<bash-stdout>(.*?)</bash-stdout>\s*<bash-stderr>(.*?)</bash-stderr>\s*$`)
+
+// bashStreams returns the separate stdout and stderr of a Bash result, from the result's
+// structured fields or else its <bash-stdout>/<bash-stderr> blocks. ok is false if the
+// result records neither, in which case it is shown as a single output.
+func bashStreams(result models.ToolResult, output string) (stdout, stderr string, ok bool) {
+	if result.HasStreams() {
+		return result.Stdout, result.Stderr, true
+	}
+	if m := bashStreamRe.FindStringSubmatch(output); m != nil {
+		return m[1], m[2], true
+	}
+	return "", "", false
+}
+
+// renderBashExitBadge renders the exit code shown in a Bash call's header, flagging a
+// nonzero code so failed commands stand out while collapsed.
+func renderBashExitBadge(code string) string {
+	class := "bash-exit-badge"
+	if code != "0" {
+		class += " error"
+	}
+	return fmt.Sprintf(`<span class="%s" data-exit-code="%s">exit %s</span>`, class, code, code)
+}
+
+// renderBashToolCall renders a Bash tool call as a terminal: the command after a "$ "
+// prompt, then its output and exit status (when the result reports one). Results that
+// record stdout and stderr separately (see bashStreams) show each in its own pane, with
+// the exit status in the header. Multi-line commands keep their line breaks. The header,
+// result links, truncation and line numbers follow ro as for renderToolCallWith.
+func renderBashToolCall(tool models.ToolUse, result models.ToolResult, hasResult bool, ro entryRenderOptions) string {
+	var sb strings.Builder
+	maxOutputBytes, lineNumbers := ro.opts.MaxToolOutputBytes, numbersToolOutput(tool.Name, ro.opts)
+
+	command, _ := tool.Input["command"].(string)
+
+	code, output, hasCode := "", "", false
+	stdout, stderr, hasStreams := "", "", false
+	status := ""
+	if hasResult {
+		code, output, hasCode = bashExitStatus(result.Content)
+		stdout, stderr, hasStreams = bashStreams(result, output)
+		if hasCode {
+			status = renderBashExitBadge(code)
+		}
+	}
+
+	sb.WriteString(renderToolCallHeader(tool, hasResult, status, ro))
+	sb.WriteString(`    <div class="bash-terminal">`)
+	sb.WriteString("\n")
+
+	sb.WriteString(fmt.Sprintf(`    <pre class="bash-command"><span class="bash-prompt" aria-hidden="true">$ </span>%s</pre>`,
+		escapeHTML(command)))
+	sb.WriteString("\n")
+
+	if hasResult {
+		sb.WriteString(fmt.Sprintf(`    <div class="tool-connector">%s</div>`, renderToolPairLink(tool.ID, false, ro.opts.NoJS)))
+		sb.WriteString("\n")
+	}
+
+	switch {
+	case hasStreams:
+		sb.WriteString(fmt.Sprintf(`    <div class="bash-streams"%s>`, toolResultAttrs(result)))
+		sb.WriteString("\n")
+		sb.WriteString(renderBashStream("stdout", stdout, result.IsError, maxOutputBytes, lineAnchor(tool, "-stdout", lineNumbers)))
+		sb.WriteString(renderBashStream("stderr", stderr, result.IsError, maxOutputBytes, lineAnchor(tool, "-stderr", lineNumbers)))
+		sb.WriteString("    </div>\n")
+	case hasResult:
+		outputClass := "tool-output bash-output"
+		if result.IsError {
+			outputClass += " error"
+		}
+		if lineNumbers {
+			outputClass += numberedOutputClass
+		}
+		truncated := false
+		if !result.IsError {
+			output, truncated = truncateUTF8(output, maxOutputBytes)
+		}
+		sb.WriteString(fmt.Sprintf(`    <pre class="%s"%s>%s</pre>`, outputClass, toolResultAttrs(result), renderOutputLines(output, lineAnchor(tool, "", lineNumbers))))
+		sb.WriteString("\n")
+		if truncated {
+			sb.WriteString(renderTruncatedNotice(result.Content))
+		}
+		if hasCode {
+			statusClass := "bash-exit-status"
+			if code != "0" {
+				statusClass += " error"
+			}
+			sb.WriteString(fmt.Sprintf(`    <div class="%s" data-exit-code="%s">exit %s</div>`, statusClass, code, code))
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("    </div>\n") // Close bash-terminal
+	sb.WriteString(renderToolCallClose(ro.opts.NoJS))
+
+	return sb.String()
+}
+
+// renderBashStream renders one output stream of a Bash result as a labelled pane. Empty
+// streams are omitted. Like single-pane output, a stream is truncated beyond
+// maxOutputBytes unless the command failed. With lineAnchor set, its lines are numbered
+// (see renderOutputLines).
+func renderBashStream(name, content string, isError bool, maxOutputBytes int, lineAnchor string) string {
+	if strings.TrimSpace(content) == "" {
+		return ""
+	}
+	output, truncated := content, false
+	if !isError {
+		output, truncated = truncateUTF8(content, maxOutputBytes)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`    <div class="bash-stream-label">%s</div>`, name))
+	sb.WriteString("\n")
+	outputClass := "tool-output bash-output bash-" + name
+	if lineAnchor != "" {
+		outputClass += numberedOutputClass
+	}
+	sb.WriteString(fmt.Sprintf(`    <pre class="%s" data-stream="%s">%s</pre>`, outputClass, name, renderOutputLines(output, lineAnchor)))
+	sb.WriteString("\n")
+	if truncated {
+		sb.WriteString(renderTruncatedNotice(content))
+	}
+	return sb.String()
+}
@@ -0,0 +1,188 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestBashExitStatus(t *testing.T) {
+	tests := []struct {
+		content    string
+		wantCode   string
+		wantOutput string
+		wantOK     bool
+	}{
+		{"Exit code 1\nno such file", "1", "no such file", true},
+		{"Exit code 127", "127", "", true},
+		{"Exit code 2\r\nusage", "2", "usage", true},
+		{"all good", "", "all good", false},
+		{"output\nExit code 1", "", "output\nExit code 1", false},
+	}
+	for _, tt := range tests {
+		code, output, ok := bashExitStatus(tt.content)
+		if code != tt.wantCode || output != tt.wantOutput || ok != tt.wantOK {
+			t.Errorf("bashExitStatus(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.content, code, output, ok, tt.wantCode, tt.wantOutput, tt.wantOK)
+		}
+	}
+}
+
+func TestRenderToolCall_BashTerminal(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Bash", Input: map[string]any{"command": "cd src &&\n  go test ./...", "description": "Run tests"}}
+	result := models.ToolResult{ToolUseID: "toolu_1", Content: "ok  \tpkg\t0.1s"}
+
+	html := renderToolCall(tool, result, true)
+
+	for _, want := range []string{
+		`<div class="bash-terminal">`,
+		`<span class="bash-prompt" aria-hidden="true">$ </span>cd src &amp;&amp;` + "\n  go test ./...</pre>",
+		`<pre class="tool-output bash-output" id="tool-result-toolu_1" data-tool-result-for="toolu_1">ok  ` + "\tpkg\t0.1s</pre>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing %q in:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, `class="tool-input"`) {
+		t.Error("Bash call should not render the generic input pane")
+	}
+	if strings.Contains(html, "bash-exit-status") {
+		t.Error("no exit status should be shown when the result does not report one")
+	}
+}
+
+func TestRenderToolCall_BashExitStatus(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Bash", Input: map[string]any{"command": "ls missing"}}
+	result := models.ToolResult{ToolUseID: "toolu_1", Content: "Exit code 2\nls: missing: No such file or directory", IsError: true}
+
+	html := renderToolCall(tool, result, true)
+
+	if !strings.Contains(html, `<div class="bash-exit-status error" data-exit-code="2">exit 2</div>`) {
+		t.Errorf("missing exit status in:\n%s", html)
+	}
+	if !strings.Contains(html, `class="tool-output bash-output error"`) {
+		t.Error("failed command output should keep the error styling")
+	}
+	if strings.Contains(html, "Exit code 2") {
+		t.Error("exit code line should be moved out of the output")
+	}
+}
+
+func TestRenderToolCallWith_BashTruncation(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Bash", Input: map[string]any{"command": "seq 1000"}}
+	result := models.ToolResult{ToolUseID: "toolu_1", Content: strings.Repeat("x", 100)}
+
+	html := renderToolCallWith(tool, result, true, "", entryRenderOptions{opts: ExportOptions{MaxToolOutputBytes: 10, SummaryMaxLen: DefaultSummaryMaxLen}})
+
+	if !strings.Contains(html, ">xxxxxxxxxx</pre>") || !strings.Contains(html, "truncated, 100 bytes total") {
+		t.Errorf("Bash output should be truncated like other tools, got:\n%s", html)
+	}
+}
+
+func TestRenderToolCall_NonBashKeepsGenericPanes(t *testing.T) {
+	read := models.ToolUse{ID: "toolu_1", Name: "Read", Input: map[string]any{"file_path": "/a.go"}}
+	html := renderToolCall(read, models.ToolResult{ToolUseID: "toolu_1", Content: "package a"}, true)
+	if !strings.Contains(html, `class="tool-input"`) || strings.Contains(html, "bash-terminal") {
+		t.Errorf("non-Bash tools should keep the generic rendering, got:\n%s", html)
+	}
+
+	// A Bash call without a command string has nothing to show after the prompt
+	noCommand := models.ToolUse{ID: "toolu_2", Name: "Bash", Input: map[string]any{}}
+	if html := renderToolCall(noCommand, models.ToolResult{}, false); strings.Contains(html, "bash-terminal") {
+		t.Error("Bash call without a command should fall back to the generic rendering")
+	}
+}
+
+func TestBashStreams(t *testing.T) {
+	tests := []struct {
+		name             string
+		result           models.ToolResult
+		output           string
+		wantOut, wantErr string
+		wantOK           bool
+	}{
+		{"structured fields", models.ToolResult{Stdout: "built", Stderr: "warning: unused"}, "built\nwarning: unused", "built", "warning: unused", true},
+		{"stderr only", models.ToolResult{Stderr: "fatal"}, "fatal", "", "fatal", true},
+		{"xml blocks", models.ToolResult{}, "<bash-stdout>hi\n</bash-stdout><bash-stderr>oops</bash-stderr>", "hi\n", "oops", true},
+		{"plain output", models.ToolResult{}, "hi", "", "", false},
+		{"quoted xml", models.ToolResult{}, "see <bash-stdout>x</bash-stdout><bash-stderr></bash-stderr>", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, ok := bashStreams(tt.result, tt.output)
+			if stdout != tt.wantOut || stderr != tt.wantErr || ok != tt.wantOK {
+				t.Errorf("bashStreams() = (%q, %q, %v), want (%q, %q, %v)", stdout, stderr, ok, tt.wantOut, tt.wantErr, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRenderToolCall_BashStreams(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Bash", Input: map[string]any{"command": "make"}}
+	result := models.ToolResult{
+		ToolUseID: "toolu_1",
+		Content:   "Exit code 2\ncompiling\nerror: missing ;",
+		IsError:   true,
+		Stdout:    "compiling",
+		Stderr:    "error: missing ;",
+	}
+
+	html := renderToolCall(tool, result, true)
+
+	for _, want := range []string{
+		`<span class="bash-exit-badge error" data-exit-code="2">exit 2</span><span class="chevron down">`,
+		`<div class="bash-streams" id="tool-result-toolu_1" data-tool-result-for="toolu_1">`,
+		`<pre class="tool-output bash-output bash-stdout" data-stream="stdout">compiling</pre>`,
+		`<pre class="tool-output bash-output bash-stderr" data-stream="stderr">error: missing ;</pre>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing %q in:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "bash-exit-status") {
+		t.Error("the exit status should only be shown in the header")
+	}
+}
+
+func TestRenderToolCall_BashStreamsOmitEmpty(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Bash", Input: map[string]any{"command": "echo hi"}}
+	result := models.ToolResult{ToolUseID: "toolu_1", Content: "<bash-stdout>hi</bash-stdout><bash-stderr></bash-stderr>"}
+
+	html := renderToolCall(tool, result, true)
+
+	if !strings.Contains(html, `data-stream="stdout">hi</pre>`) {
+		t.Errorf("stdout pane missing in:\n%s", html)
+	}
+	if strings.Contains(html, `data-stream="stderr"`) {
+		t.Error("an empty stderr should have no pane")
+	}
+	if strings.Contains(html, "bash-exit-badge") {
+		t.Error("no exit badge should be shown when the result does not report a code")
+	}
+}
+
+func TestRenderToolCall_BashExitBadgeFallback(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Bash", Input: map[string]any{"command": "true"}}
+	result := models.ToolResult{ToolUseID: "toolu_1", Content: "Exit code 0\ndone"}
+
+	html := renderToolCall(tool, result, true)
+
+	if !strings.Contains(html, `<span class="bash-exit-badge" data-exit-code="0">exit 0</span>`) {
+		t.Errorf("a zero exit code should get an unflagged badge:\n%s", html)
+	}
+	if !strings.Contains(html, `<pre class="tool-output bash-output" id="tool-result-toolu_1"`) {
+		t.Error("results without streams should keep the single output pane")
+	}
+}
+
+func TestRenderToolCallWith_BashStreamTruncation(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Bash", Input: map[string]any{"command": "seq 1000"}}
+	result := models.ToolResult{ToolUseID: "toolu_1", Content: strings.Repeat("x", 100), Stdout: strings.Repeat("x", 100)}
+
+	html := renderToolCallWith(tool, result, true, "", entryRenderOptions{opts: ExportOptions{MaxToolOutputBytes: 10, SummaryMaxLen: DefaultSummaryMaxLen}})
+
+	if !strings.Contains(html, `data-stream="stdout">xxxxxxxxxx</pre>`) || !strings.Contains(html, "truncated, 100 bytes total") {
+		t.Errorf("stream panes should be truncated like other output, got:\n%s", html)
+	}
+}
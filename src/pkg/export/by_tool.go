@@ -0,0 +1,162 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+	"github.com/randlee/claude-history/pkg/version"
+)
+
+// ByToolFileName is the page of an HTML export listing the tool calls grouped by tool
+// (see ExportOptions.GroupByTool).
+const ByToolFileName = "tools.html"
+
+// toolGroup is the calls of one tool, in session order.
+type toolGroup struct {
+	name  string
+	calls []toolGroupCall
+}
+
+// toolGroupCall is one call in a toolGroup, with the index of its entry in the session.
+type toolGroupCall struct {
+	tool      models.ToolUse
+	entry     int
+	timestamp string
+}
+
+// groupToolCalls groups the tool calls of the assistant entries by tool name, counting
+// calls as ComputeSessionStats does, with the most used tools first (ties by name).
+// Tools without calls have no group.
+func groupToolCalls(entries []models.ConversationEntry) []toolGroup {
+	byName := make(map[string]*toolGroup)
+	var groups []*toolGroup
+	for i, entry := range entries {
+		if entry.Type != models.EntryTypeAssistant {
+			continue
+		}
+		for _, tool := range entry.ExtractToolCalls() {
+			group, ok := byName[tool.Name]
+			if !ok {
+				group = &toolGroup{name: tool.Name}
+				byName[tool.Name] = group
+				groups = append(groups, group)
+			}
+			group.calls = append(group.calls, toolGroupCall{tool: tool, entry: i, timestamp: entry.Timestamp})
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i].calls) != len(groups[j].calls) {
+			return len(groups[i].calls) > len(groups[j].calls)
+		}
+		return groups[i].name < groups[j].name
+	})
+	result := make([]toolGroup, len(groups))
+	for i, group := range groups {
+		result[i] = *group
+	}
+	return result
+}
+
+// RenderByTool renders the tool calls of a session grouped by tool, as the standalone
+// tools.html page of an export: a collapsible section per tool that was called, most
+// used first, listing each call with its input summary, its result, and a link back to
+// the call in the conversation (index.html). The call counts add up to the session's
+// SessionStats.ToolCallCount.
+func RenderByTool(entries []models.ConversationEntry) (string, error) {
+	return renderByTool(entries, func(int) string { return PageIndexFileName }), nil
+}
+
+// renderByTool renders the page of RenderByTool, linking each call to the tool call
+// anchor on the page pageOf returns for the index of its entry.
+func renderByTool(entries []models.ConversationEntry, pageOf func(entry int) string) string {
+	groups := groupToolCalls(entries)
+	toolResults := buildToolResultsMap(entries)
+
+	total := 0
+	for _, group := range groups {
+		total += len(group.calls)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Claude Code Session Tools [v%s]</title>
+    <link rel="stylesheet" href="static/style.css">
+</head>
+<body class="by-tool">
+<header class="page-header">
+    <h1>Tool calls by tool</h1>
+    <div class="session-metadata">
+        <span class="by-tool-summary">%d calls to %d tools</span>
+        <a class="by-tool-back" href="%s">Back to the conversation</a>
+    </div>
+</header>
+<div class="conversation by-tool-groups">
+`, version.Version, total, len(groups), PageIndexFileName))
+
+	for _, group := range groups {
+		sb.WriteString(renderToolGroup(group, toolResults, pageOf))
+	}
+
+	sb.WriteString("</div>\n</body>\n</html>\n")
+	return sb.String()
+}
+
+// renderToolGroup renders the collapsible section of one tool.
+func renderToolGroup(group toolGroup, toolResults map[string]models.ToolResult, pageOf func(entry int) string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<details class="tool-group" id="tool-group-%s">`, escapeHTML(group.name)))
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf(`  <summary class="tool-group-header"><span class="tool-group-name">%s</span> <span class="tool-group-count">%d</span></summary>`,
+		escapeHTML(group.name), len(group.calls)))
+	sb.WriteString("\n")
+	sb.WriteString(`  <ol class="tool-group-calls">`)
+	sb.WriteString("\n")
+	for _, call := range group.calls {
+		result, hasResult := toolResults[call.tool.ID]
+		sb.WriteString(renderToolGroupCall(call, result, hasResult, pageOf(call.entry)))
+	}
+	sb.WriteString("  </ol>\n")
+	sb.WriteString("</details>\n")
+	return sb.String()
+}
+
+// renderToolGroupCall renders one call in a tool's section: its input summary, result
+// status and a link to it on page, then its result, collapsed.
+func renderToolGroupCall(call toolGroupCall, result models.ToolResult, hasResult bool, page string) string {
+	summary := extractToolDisplayValue(call.tool.Name, call.tool.Input)
+	if summary == "" {
+		summary = call.tool.ID
+	}
+
+	status := toolOrphanMarker
+	if hasResult && result.IsError {
+		status = `<span class="tool-group-status error">error</span>`
+	} else if hasResult {
+		status = `<span class="tool-group-status">ok</span>`
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`    <li class="tool-group-call" data-tool-id="%s">`, escapeHTML(call.tool.ID)))
+	sb.WriteString(fmt.Sprintf(`<span class="tool-group-summary">%s</span>%s`, escapeHTML(truncateSummary(summary, DefaultSummaryMaxLen)), status))
+	if call.timestamp != "" {
+		sb.WriteString(fmt.Sprintf(` <span class="timestamp">%s</span>`, escapeHTML(formatTimestamp(call.timestamp))))
+	}
+	sb.WriteString(fmt.Sprintf(` <a class="tool-group-link" href="%s#tool-%s" title="Show this call in the conversation">message ↗</a>`,
+		escapeHTML(page), escapeHTML(call.tool.ID)))
+	if hasResult {
+		outputClass := "tool-output"
+		if result.IsError {
+			outputClass = "tool-output error"
+		}
+		sb.WriteString(fmt.Sprintf("\n      <details class=\"tool-group-result\"><summary>Result</summary><pre class=\"%s\">%s</pre></details>\n    ",
+			outputClass, escapeHTML(result.Content)))
+	}
+	sb.WriteString("</li>\n")
+	return sb.String()
+}
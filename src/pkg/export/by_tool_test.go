@@ -0,0 +1,107 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// byToolEntries has three Read calls, one failing, and a Bash call, two of them in one entry.
+func byToolEntries() []models.ConversationEntry {
+	return []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Message: json.RawMessage(`"Look around"`)},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:00Z", Message: json.RawMessage(`{"role":"assistant","content":[` +
+			`{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"main.go"}},` +
+			`{"type":"tool_use","id":"t2","name":"Bash","input":{"command":"go test"}}]}`)},
+		{UUID: "r1", Type: models.EntryTypeUser, Message: json.RawMessage(`[` +
+			`{"type":"tool_result","tool_use_id":"t1","content":"package main"},` +
+			`{"type":"tool_result","tool_use_id":"t2","content":"FAIL <pkg>","is_error":true}]`)},
+		{UUID: "a2", Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[` +
+			`{"type":"tool_use","id":"t3","name":"Read","input":{"file_path":"go.mod"}}]}`)},
+		{UUID: "a3", Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[` +
+			`{"type":"tool_use","id":"t4","name":"Read","input":{"file_path":"gone.go"}}]}`)},
+		{UUID: "r2", Type: models.EntryTypeUser, Message: json.RawMessage(`[{"type":"tool_result","tool_use_id":"t4","content":"missing","is_error":true}]`)},
+	}
+}
+
+func TestGroupToolCalls_MatchesStats(t *testing.T) {
+	entries := byToolEntries()
+	groups := groupToolCalls(entries)
+
+	if len(groups) != 2 || groups[0].name != "Read" || groups[1].name != "Bash" {
+		t.Fatalf("groups = %+v, want Read then Bash", groups)
+	}
+	total := 0
+	for _, group := range groups {
+		total += len(group.calls)
+	}
+	if want := ComputeSessionStats(entries, nil).ToolCallCount; total != want {
+		t.Errorf("grouped %d calls, stats count %d", total, want)
+	}
+	if ids := []string{groups[0].calls[0].tool.ID, groups[0].calls[1].tool.ID, groups[0].calls[2].tool.ID}; strings.Join(ids, ",") != "t1,t3,t4" {
+		t.Errorf("Read calls = %v, want session order", ids)
+	}
+
+	if groups := groupToolCalls(entries[:1]); len(groups) != 0 {
+		t.Errorf("groupToolCalls() = %+v, want no groups without calls", groups)
+	}
+}
+
+func TestRenderByTool(t *testing.T) {
+	html, err := RenderByTool(byToolEntries())
+	if err != nil {
+		t.Fatalf("RenderByTool() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`<link rel="stylesheet" href="static/style.css">`,
+		`4 calls to 2 tools`,
+		`<details class="tool-group" id="tool-group-Read">`,
+		`<span class="tool-group-name">Read</span> <span class="tool-group-count">3</span>`,
+		`<span class="tool-group-name">Bash</span> <span class="tool-group-count">1</span>`,
+		`<span class="tool-group-summary">main.go</span><span class="tool-group-status">ok</span>`,
+		`<span class="tool-group-summary">go test</span><span class="tool-group-status error">error</span>`,
+		`<span class="tool-group-summary">go.mod</span><span class="tool-orphan"`,
+		`href="index.html#tool-t3"`,
+		`<pre class="tool-output error">FAIL &lt;pkg&gt;</pre>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("RenderByTool() missing %q:\n%s", want, html)
+		}
+	}
+	if strings.Index(html, "tool-group-Read") > strings.Index(html, "tool-group-Bash") {
+		t.Error("the most used tool should come first")
+	}
+	if strings.Contains(html, "tool-group-Write") {
+		t.Error("tools without calls should not appear")
+	}
+}
+
+func TestRenderConversationPages_GroupByTool(t *testing.T) {
+	entries := byToolEntries()
+
+	pages, err := RenderConversationPages(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, page := range pages {
+		if page.FileName == ByToolFileName {
+			t.Fatal("tools.html should only be rendered with GroupByTool")
+		}
+	}
+
+	// Paginated, each call links to the page holding it
+	pages, err = RenderConversationPages(entries, nil, nil, ExportOptions{GroupByTool: true, PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := pages[len(pages)-1]
+	if last.FileName != ByToolFileName {
+		t.Fatalf("last page = %s, want %s", last.FileName, ByToolFileName)
+	}
+	if !strings.Contains(last.HTML, `href="page-1.html#tool-t1"`) || !strings.Contains(last.HTML, `href="page-2.html#tool-t4"`) {
+		t.Errorf("calls should link to their pages:\n%s", last.HTML)
+	}
+}
@@ -198,7 +198,7 @@ func TestRenderHTMLHeader_WithStats(t *testing.T) {
 		ToolCallCount:      247,
 	}
 
-	html := renderHTMLHeader(stats, nil)
+	html := renderHTMLHeader(stats, nil, localizer{})
 
 	// Check structure
 	if !strings.Contains(html, "<!DOCTYPE html>") {
@@ -258,7 +258,7 @@ func TestRenderHTMLHeader_WithStats(t *testing.T) {
 
 // TestRenderHTMLHeader_NilStats tests header generation without stats.
 func TestRenderHTMLHeader_NilStats(t *testing.T) {
-	html := renderHTMLHeader(nil, nil)
+	html := renderHTMLHeader(nil, nil, localizer{})
 
 	// Should still have basic structure
 	if !strings.Contains(html, "<!DOCTYPE html>") {
@@ -275,7 +275,7 @@ func TestRenderHTMLHeader_NilStats(t *testing.T) {
 // TestRenderHTMLHeader_EmptyStats tests header with empty stats.
 func TestRenderHTMLHeader_EmptyStats(t *testing.T) {
 	stats := &SessionStats{}
-	html := renderHTMLHeader(stats, nil)
+	html := renderHTMLHeader(stats, nil, localizer{})
 
 	// Should have basic structure
 	if !strings.Contains(html, "<header class=\"page-header\">") {
@@ -290,6 +290,33 @@ func TestRenderHTMLHeader_EmptyStats(t *testing.T) {
 	}
 }
 
+// TestRenderHTMLHeader_SessionLineage tests the breadcrumb of a resumed session.
+func TestRenderHTMLHeader_SessionLineage(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{Type: models.EntryTypeUser, SessionID: "parent-session-1"},
+		{Type: models.EntryTypeAssistant, SessionID: "parent-session-1"},
+		{Type: models.EntryTypeUser, SessionID: "child-session-2"},
+	}
+	stats := ComputeSessionStats(entries, nil)
+	if stats.SessionID != "child-session-2" {
+		t.Errorf("SessionID = %q, want child-session-2", stats.SessionID)
+	}
+
+	html := renderHTMLHeader(stats, nil, localizer{})
+	if !strings.Contains(html, `class="meta-item session-lineage"`) {
+		t.Fatal("Missing session lineage breadcrumb")
+	}
+	if !strings.Contains(html, `href="../parent-session-1/index.html"`) {
+		t.Error("Breadcrumb should link to the parent session's export")
+	}
+
+	// A session without lineage renders no breadcrumb
+	stats = ComputeSessionStats(entries[2:], nil)
+	if html := renderHTMLHeader(stats, nil, localizer{}); strings.Contains(html, "session-lineage") {
+		t.Error("Breadcrumb rendered for a session without lineage")
+	}
+}
+
 // TestRenderHTMLFooter_WithStats tests footer generation with stats.
 func TestRenderHTMLFooter_WithStats(t *testing.T) {
 	stats := &SessionStats{
@@ -510,7 +537,7 @@ func TestRenderHTMLHeader_XSSPrevention(t *testing.T) {
 		ProjectPath: "<img onerror='alert(1)'>",
 	}
 
-	html := renderHTMLHeader(stats, nil)
+	html := renderHTMLHeader(stats, nil, localizer{})
 
 	// Script and img tags should be escaped
 	if strings.Contains(html, "<script>alert") {
@@ -544,7 +571,7 @@ func TestCopyButtonIntegration(t *testing.T) {
 		ProjectPath: "/path/to/project",
 	}
 
-	html := renderHTMLHeader(stats, nil)
+	html := renderHTMLHeader(stats, nil, localizer{})
 
 	// Check session ID copy button
 	if !strings.Contains(html, "class=\"copy-btn\"") {
@@ -646,7 +673,7 @@ func TestSessionStats_Struct(t *testing.T) {
 
 // TestRenderHTMLHeader_Controls tests that controls are included in header.
 func TestRenderHTMLHeader_Controls(t *testing.T) {
-	html := renderHTMLHeader(nil, nil)
+	html := renderHTMLHeader(nil, nil, localizer{})
 
 	// Check controls are present
 	if !strings.Contains(html, "id=\"expand-all-btn\"") {
@@ -671,7 +698,7 @@ func TestRenderHTMLHeader_Controls(t *testing.T) {
 
 // TestRenderHTMLHeader_Accessibility tests accessibility attributes.
 func TestRenderHTMLHeader_Accessibility(t *testing.T) {
-	html := renderHTMLHeader(nil, nil)
+	html := renderHTMLHeader(nil, nil, localizer{})
 
 	if !strings.Contains(html, "role=\"toolbar\"") {
 		t.Error("Missing toolbar role")
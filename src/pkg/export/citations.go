@@ -0,0 +1,108 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+var (
+	// WebSearch results list their sources on a line like: Links: [{"title":"...","url":"..."}]
+	webSearchLinksRe = regexp.MustCompile(`(?m)^Links:\s*(\[.*\])\s*$`)
+
+	// Citation markers: [1], [2], ... (not followed by "(" which would be a markdown link)
+	citationRe = regexp.MustCompile(`\[(\d{1,3})\]`)
+)
+
+// webSearchLink is a single source entry in a WebSearch tool result.
+type webSearchLink struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// extractWebSearchSources parses the ordered list of source URLs from a WebSearch tool result.
+// Returns nil if the result does not contain a recognizable source list.
+func extractWebSearchSources(content string) []string {
+	match := webSearchLinksRe.FindStringSubmatch(content)
+	if match == nil {
+		return nil
+	}
+
+	var links []webSearchLink
+	if err := json.Unmarshal([]byte(match[1]), &links); err != nil {
+		return nil
+	}
+
+	var sources []string
+	for _, link := range links {
+		if strings.HasPrefix(link.URL, "http://") || strings.HasPrefix(link.URL, "https://") {
+			sources = append(sources, link.URL)
+		}
+	}
+	return sources
+}
+
+// collectWebSearchSources returns the sources from any WebSearch calls in an assistant entry.
+// Sources from multiple searches are concatenated in call order.
+func collectWebSearchSources(entry models.ConversationEntry, toolResults map[string]models.ToolResult) []string {
+	if entry.Type != models.EntryTypeAssistant {
+		return nil
+	}
+
+	var sources []string
+	for _, tool := range entry.ExtractToolCalls() {
+		if tool.Name != "WebSearch" {
+			continue
+		}
+		result, ok := toolResults[tool.ID]
+		if !ok || result.IsError {
+			continue
+		}
+		sources = append(sources, extractWebSearchSources(result.Content)...)
+	}
+	return sources
+}
+
+// linkifyCitations replaces [n] markers with links to the nth source, storing the
+// rendered links in placeholders so they survive HTML escaping.
+// Markers outside the range of available sources are left untouched.
+func linkifyCitations(content string, sources []string, placeholders map[string]string) string {
+	if len(sources) == 0 {
+		return content
+	}
+
+	idx := 0
+	matches := citationRe.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return content
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		// Skip markdown link syntax [n](url) - already handled by the link pass
+		if m[1] < len(content) && content[m[1]] == '(' {
+			continue
+		}
+		n, err := strconv.Atoi(content[m[2]:m[3]])
+		if err != nil || n < 1 || n > len(sources) {
+			continue
+		}
+
+		placeholder := fmt.Sprintf("\x00CITATION_%d\x00", idx)
+		placeholders[placeholder] = fmt.Sprintf(`<a href="%s" class="md-link citation" title="%s" target="_blank" rel="noopener">[%d]</a>`,
+			escapeHTML(sources[n-1]), escapeHTML(sources[n-1]), n)
+		idx++
+
+		sb.WriteString(content[last:m[0]])
+		sb.WriteString(placeholder)
+		last = m[1]
+	}
+	sb.WriteString(content[last:])
+
+	return sb.String()
+}
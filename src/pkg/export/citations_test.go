@@ -0,0 +1,176 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+const webSearchResultContent = `Web search results for query: "go generics"
+
+Links: [{"title":"Go Blog","url":"https://go.dev/blog/intro-generics"},{"title":"Tutorial","url":"https://go.dev/doc/tutorial/generics"}]
+
+Generics were added in Go 1.18.`
+
+func TestExtractWebSearchSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "links list",
+			content: webSearchResultContent,
+			want:    []string{"https://go.dev/blog/intro-generics", "https://go.dev/doc/tutorial/generics"},
+		},
+		{
+			name:    "no links line",
+			content: "No results found",
+			want:    nil,
+		},
+		{
+			name:    "malformed json",
+			content: "Links: [{not json}]",
+			want:    nil,
+		},
+		{
+			name:    "non-http urls dropped",
+			content: `Links: [{"title":"x","url":"javascript:alert(1)"},{"title":"y","url":"https://example.com"}]`,
+			want:    []string{"https://example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractWebSearchSources(tt.content)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("extractWebSearchSources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownWithCitations(t *testing.T) {
+	sources := []string{"https://a.example", "https://b.example"}
+
+	result := renderMarkdownWithCitations("Generics landed in 1.18 [1] and are documented [2].", "", sources)
+	if !strings.Contains(result, `href="https://a.example"`) || !strings.Contains(result, `href="https://b.example"`) {
+		t.Errorf("expected citation links, got %q", result)
+	}
+	if !strings.Contains(result, `class="md-link citation"`) {
+		t.Errorf("expected citation class, got %q", result)
+	}
+}
+
+func TestRenderMarkdownWithCitations_OutOfRange(t *testing.T) {
+	result := renderMarkdownWithCitations("See [3] for details.", "", []string{"https://a.example"})
+	if strings.Contains(result, "citation") {
+		t.Errorf("out-of-range marker should not be linked, got %q", result)
+	}
+	if !strings.Contains(result, "[3]") {
+		t.Errorf("out-of-range marker should be preserved, got %q", result)
+	}
+}
+
+func TestRenderMarkdownWithCitations_IgnoresCode(t *testing.T) {
+	sources := []string{"https://a.example"}
+	content := "Index with `arr[1]` or:\n```go\nx := arr[1]\n```\n"
+
+	result := renderMarkdownWithCitations(content, "", sources)
+	if strings.Contains(result, "citation") {
+		t.Errorf("markers in code should not be linked, got %q", result)
+	}
+}
+
+func TestRenderMarkdownWithCitations_NoSources(t *testing.T) {
+	result := renderMarkdownWithCitations("Plain [1] marker.", "", nil)
+	if result != RenderMarkdown("Plain [1] marker.", "") {
+		t.Errorf("nil sources should match RenderMarkdown output, got %q", result)
+	}
+	if strings.Contains(result, "citation") {
+		t.Errorf("no sources should not link markers, got %q", result)
+	}
+}
+
+func TestRenderMarkdownWithCitations_PreservesMarkdownLinks(t *testing.T) {
+	result := renderMarkdownWithCitations("[1](https://other.example)", "", []string{"https://a.example"})
+	if !strings.Contains(result, `href="https://other.example"`) {
+		t.Errorf("markdown link should be preserved, got %q", result)
+	}
+	if strings.Contains(result, "citation") {
+		t.Errorf("markdown link text should not be treated as a citation, got %q", result)
+	}
+}
+
+func citationTestEntries(t *testing.T, resultContent string) []models.ConversationEntry {
+	t.Helper()
+	resultJSON, err := json.Marshal(resultContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []models.ConversationEntry{
+		{
+			UUID:      "a1",
+			Type:      models.EntryTypeAssistant,
+			Timestamp: "2026-01-01T10:00:00Z",
+			Message:   json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"ws1","name":"WebSearch","input":{"query":"go generics"}}]}`),
+		},
+		{
+			UUID:      "u1",
+			Type:      models.EntryTypeUser,
+			Timestamp: "2026-01-01T10:00:01Z",
+			Message:   json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"ws1","content":` + string(resultJSON) + `}]}`),
+		},
+		{
+			UUID:      "a2",
+			Type:      models.EntryTypeAssistant,
+			Timestamp: "2026-01-01T10:00:02Z",
+			Message:   json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Generics arrived in Go 1.18 [1]. See also [2]."}]}`),
+		},
+	}
+}
+
+func TestRenderConversation_LinksWebSearchCitations(t *testing.T) {
+	html, err := RenderConversation(citationTestEntries(t, webSearchResultContent), nil)
+	if err != nil {
+		t.Fatalf("RenderConversation() error = %v", err)
+	}
+	if !strings.Contains(html, `href="https://go.dev/blog/intro-generics" class="md-link citation"`) {
+		t.Error("expected [1] to link to the first WebSearch source")
+	}
+	if !strings.Contains(html, `href="https://go.dev/doc/tutorial/generics" class="md-link citation"`) {
+		t.Error("expected [2] to link to the second WebSearch source")
+	}
+}
+
+func TestRenderConversation_NoCitationsWithoutSources(t *testing.T) {
+	html, err := RenderConversation(citationTestEntries(t, "No links in this result"), nil)
+	if err != nil {
+		t.Fatalf("RenderConversation() error = %v", err)
+	}
+	if strings.Contains(html, "md-link citation") {
+		t.Error("citations should not be linked when the WebSearch result has no sources")
+	}
+}
+
+func TestRenderConversation_CitationsResetOnUserPrompt(t *testing.T) {
+	entries := citationTestEntries(t, webSearchResultContent)
+	// Insert a real user prompt between the search and the answer
+	prompt := models.ConversationEntry{
+		UUID:      "u2",
+		Type:      models.EntryTypeUser,
+		Timestamp: "2026-01-01T10:00:01Z",
+		Message:   json.RawMessage(`"Actually, never mind"`),
+	}
+	entries = append(entries[:2], append([]models.ConversationEntry{prompt}, entries[2:]...)...)
+
+	html, err := RenderConversation(entries, nil)
+	if err != nil {
+		t.Fatalf("RenderConversation() error = %v", err)
+	}
+	if strings.Contains(html, "md-link citation") {
+		t.Error("sources should not carry over past a new user prompt")
+	}
+}
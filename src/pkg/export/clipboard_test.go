@@ -358,6 +358,30 @@ func TestExtractFilePath_AllFileTools(t *testing.T) {
 			input:      map[string]any{"notebook_path": "/notebooks/analysis.ipynb"},
 			expectPath: "/notebooks/analysis.ipynb",
 		},
+		{
+			name:       "MultiEdit with file_path",
+			toolName:   "MultiEdit",
+			input:      map[string]any{"file_path": "/src/main.go", "edits": []any{}},
+			expectPath: "/src/main.go",
+		},
+		{
+			name:       "ApplyPatch with file_path",
+			toolName:   "ApplyPatch",
+			input:      map[string]any{"file_path": "/src/patch.go"},
+			expectPath: "/src/patch.go",
+		},
+		{
+			name:       "ApplyPatch from patch header",
+			toolName:   "ApplyPatch",
+			input:      map[string]any{"input": "*** Begin Patch\n*** Update File: src/app.go\n@@\n-a\n+b\n*** End Patch"},
+			expectPath: "src/app.go",
+		},
+		{
+			name:       "ApplyPatch without a file",
+			toolName:   "ApplyPatch",
+			input:      map[string]any{"patch": "no headers here"},
+			expectPath: "",
+		},
 		{
 			name:       "Bash (no file path)",
 			toolName:   "Bash",
@@ -394,6 +418,23 @@ func TestExtractFilePath_AllFileTools(t *testing.T) {
 	}
 }
 
+func TestRenderToolCall_MultiEditFilePathCopyButton(t *testing.T) {
+	tool := models.ToolUse{
+		ID:   "toolu_multi",
+		Name: "MultiEdit",
+		Input: map[string]any{
+			"file_path": "/src/main.go",
+			"edits":     []any{map[string]any{"old_string": "a", "new_string": "b"}},
+		},
+	}
+
+	html := renderToolCall(tool, models.ToolResult{}, false)
+
+	if !strings.Contains(html, `data-copy-text="/src/main.go" data-copy-type="file-path"`) {
+		t.Errorf("MultiEdit should have a file path copy button, got:\n%s", html)
+	}
+}
+
 func TestRenderToolCall_HasBothToolIDAndFilePathCopyButtons(t *testing.T) {
 	tool := models.ToolUse{
 		ID:    "toolu_01XYZ",
@@ -618,3 +659,72 @@ func TestRenderSubagentPlaceholder_StructureWithCopyButton(t *testing.T) {
 		t.Error("Subagent header missing copy button")
 	}
 }
+
+func TestBuildFullSessionContext(t *testing.T) {
+	stats := &SessionStats{
+		SessionID:          "679761ba-80c0-4cd3-a586-cc6a1fc56308",
+		ProjectPath:        "/home/user/project",
+		SessionStart:       "2026-02-01 10:00:00",
+		Duration:           "2h 35m",
+		UserMessages:       12,
+		AssistantMessages:  30,
+		AgentCount:         2,
+		TotalAgentMessages: 45,
+		ToolCallCount:      57,
+	}
+
+	want := "Session: 679761ba-80c0-4cd3-a586-cc6a1fc56308\n" +
+		"Project: /home/user/project\n" +
+		"Started: 2026-02-01 10:00:00\n" +
+		"Duration: 2h 35m\n" +
+		"Messages: User: 12 | Assistant: 30 | Subagents[2]: 45 messages\n" +
+		"Tools: 57 calls\n" +
+		"Resume: cd /home/user/project && claude --resume 679761ba-80c0-4cd3-a586-cc6a1fc56308\n" +
+		"claude-history query /home/user/project --session 679761ba-80c0-4cd3-a586-cc6a1fc56308"
+	if got := buildFullSessionContext(stats); got != want {
+		t.Errorf("buildFullSessionContext() =\n%s\nwant\n%s", got, want)
+	}
+
+	// Shares its opening lines with the session ID copy context
+	sessionContext := buildSessionCopyContext(stats.SessionID, stats.ProjectPath, "")
+	identity := sessionContext[:strings.LastIndex(sessionContext, "\n")+1]
+	if !strings.HasPrefix(want, identity) {
+		t.Errorf("full context should start like the session copy context %q", identity)
+	}
+}
+
+func TestBuildFullSessionContext_Minimal(t *testing.T) {
+	if got := buildFullSessionContext(nil); got != "" {
+		t.Errorf("nil stats = %q, want empty", got)
+	}
+	if got := buildFullSessionContext(&SessionStats{ToolCallCount: 3}); got != "" {
+		t.Errorf("no session ID = %q, want empty", got)
+	}
+
+	got := buildFullSessionContext(&SessionStats{SessionID: "abc"})
+	for _, line := range []string{"Resume: claude --resume abc\n", "claude-history query /path/to/project --session abc"} {
+		if !strings.Contains(got, line) {
+			t.Errorf("context without project should contain %q, got:\n%s", line, got)
+		}
+	}
+	if strings.Contains(got, "Project:") || strings.Contains(got, "Started:") || strings.Contains(got, "Duration:") {
+		t.Errorf("unknown fields should be omitted, got:\n%s", got)
+	}
+}
+
+func TestRenderHTMLHeader_ShareContextButton(t *testing.T) {
+	stats := &SessionStats{SessionID: "abc-123", ProjectPath: "/p", UserMessages: 1, ToolCallCount: 2}
+
+	html := renderHTMLHeader(stats, nil, localizer{})
+	if !strings.Contains(html, `<span class="meta-item session-context">Share context`) {
+		t.Error("header should have a share context item")
+	}
+	wantButton := renderCopyButton(buildFullSessionContext(stats), "session-context", "Copy full session context")
+	if !strings.Contains(html, wantButton) {
+		t.Error("share context button should copy buildFullSessionContext")
+	}
+
+	if html := renderHTMLHeader(nil, nil, localizer{}); strings.Contains(html, "session-context") {
+		t.Error("header without stats should not offer a share context button")
+	}
+}
@@ -0,0 +1,123 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// CollectCodeBlocks returns every fenced code block in the text of the assistant
+// entries, in conversation order, with UUID and Timestamp set to the entry each block
+// came from. If lang is non-empty, only blocks tagged with that language are returned;
+// the comparison is case-insensitive, so "go" matches "Go" and "GO".
+func CollectCodeBlocks(entries []models.ConversationEntry, lang string) []CodeBlock {
+	var blocks []CodeBlock
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Type != models.EntryTypeAssistant {
+			continue
+		}
+		for _, block := range ExtractCodeBlocks(entry.GetTextContent()) {
+			if lang != "" && !strings.EqualFold(block.Language, lang) {
+				continue
+			}
+			block.UUID = entry.UUID
+			block.Timestamp = entry.Timestamp
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// codeLanguageInfo maps lowercase language tags to a file extension and line comment
+// prefix. Languages without line comments use a block comment (see commentSuffix).
+var codeLanguageInfo = map[string]struct{ ext, comment string }{
+	"go":         {"go", "//"},
+	"javascript": {"js", "//"},
+	"js":         {"js", "//"},
+	"typescript": {"ts", "//"},
+	"ts":         {"ts", "//"},
+	"tsx":        {"tsx", "//"},
+	"jsx":        {"jsx", "//"},
+	"java":       {"java", "//"},
+	"kotlin":     {"kt", "//"},
+	"swift":      {"swift", "//"},
+	"c":          {"c", "//"},
+	"cpp":        {"cpp", "//"},
+	"csharp":     {"cs", "//"},
+	"cs":         {"cs", "//"},
+	"rust":       {"rs", "//"},
+	"rs":         {"rs", "//"},
+	"php":        {"php", "//"},
+	"scala":      {"scala", "//"},
+	"python":     {"py", "#"},
+	"py":         {"py", "#"},
+	"ruby":       {"rb", "#"},
+	"rb":         {"rb", "#"},
+	"bash":       {"sh", "#"},
+	"sh":         {"sh", "#"},
+	"shell":      {"sh", "#"},
+	"zsh":        {"sh", "#"},
+	"powershell": {"ps1", "#"},
+	"yaml":       {"yaml", "#"},
+	"yml":        {"yaml", "#"},
+	"toml":       {"toml", "#"},
+	"dockerfile": {"dockerfile", "#"},
+	"makefile":   {"mk", "#"},
+	"r":          {"r", "#"},
+	"perl":       {"pl", "#"},
+	"sql":        {"sql", "--"},
+	"lua":        {"lua", "--"},
+	"haskell":    {"hs", "--"},
+	"html":       {"html", "<!--"},
+	"xml":        {"xml", "<!--"},
+	"markdown":   {"md", "<!--"},
+	"md":         {"md", "<!--"},
+	"css":        {"css", "/*"},
+}
+
+// CodeBlockExtension returns the file extension (without the dot) for a code block
+// language tag, or "txt" for unknown and empty tags.
+func CodeBlockExtension(lang string) string {
+	if info, ok := codeLanguageInfo[strings.ToLower(lang)]; ok {
+		return info.ext
+	}
+	return "txt"
+}
+
+// FormatCodeBlock returns block's code preceded by a comment line attributing it to its
+// source message, e.g. "// claude-history: code block 3 from message <uuid> at <timestamp>".
+// The comment uses the language's syntax; unknown languages use "#". The result ends
+// with a newline.
+func FormatCodeBlock(block CodeBlock, index int) string {
+	comment := "#"
+	if info, ok := codeLanguageInfo[strings.ToLower(block.Language)]; ok {
+		comment = info.comment
+	}
+
+	header := fmt.Sprintf("claude-history: code block %d from message %s", index, block.UUID)
+	if block.Timestamp != "" {
+		header += " at " + block.Timestamp
+	}
+
+	var sb strings.Builder
+	sb.WriteString(comment + " " + header + commentSuffix(comment) + "\n")
+	sb.WriteString(block.Code)
+	if !strings.HasSuffix(block.Code, "\n") {
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// commentSuffix returns the text that closes a comment opened with prefix.
+func commentSuffix(prefix string) string {
+	switch prefix {
+	case "<!--":
+		return " -->"
+	case "/*":
+		return " */"
+	default:
+		return ""
+	}
+}
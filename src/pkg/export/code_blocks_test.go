@@ -0,0 +1,120 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// codeTestEntry returns an entry of the given type whose text content is text.
+func codeTestEntry(t *testing.T, entryType models.EntryType, uuid, timestamp, text string) models.ConversationEntry {
+	t.Helper()
+	content, err := json.Marshal(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := json.RawMessage(content)
+	if entryType == models.EntryTypeAssistant {
+		msg = json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":` + string(content) + `}]}`)
+	}
+	return models.ConversationEntry{UUID: uuid, Type: entryType, Timestamp: timestamp, Message: msg}
+}
+
+func TestCollectCodeBlocks(t *testing.T) {
+	entries := []models.ConversationEntry{
+		codeTestEntry(t, models.EntryTypeUser, "u1", "2026-02-01T10:00:00Z", "Fix this:\n```go\nfunc broken() {}\n```"),
+		codeTestEntry(t, models.EntryTypeAssistant, "a1", "2026-02-01T10:00:05Z",
+			"Here:\n```go\nfunc fixed() {}\n```\nAnd run:\n```bash\ngo test ./...\n```"),
+		codeTestEntry(t, models.EntryTypeAssistant, "a2", "2026-02-01T10:01:00Z", "No code here."),
+		codeTestEntry(t, models.EntryTypeAssistant, "a3", "2026-02-01T10:02:00Z", "```Go\nvar x = 1\n```\n```\nplain\n```"),
+	}
+
+	all := CollectCodeBlocks(entries, "")
+	if len(all) != 4 {
+		t.Fatalf("CollectCodeBlocks(all) returned %d blocks, want 4 (user blocks excluded)", len(all))
+	}
+	wantUUIDs := []string{"a1", "a1", "a3", "a3"}
+	for i, block := range all {
+		if block.UUID != wantUUIDs[i] {
+			t.Errorf("block %d UUID = %q, want %q", i, block.UUID, wantUUIDs[i])
+		}
+	}
+	if all[0].Code != "func fixed() {}" || all[0].Timestamp != "2026-02-01T10:00:05Z" {
+		t.Errorf("first block = %+v", all[0])
+	}
+
+	for _, lang := range []string{"go", "GO", "Go"} {
+		goBlocks := CollectCodeBlocks(entries, lang)
+		if len(goBlocks) != 2 {
+			t.Errorf("CollectCodeBlocks(%q) returned %d blocks, want 2", lang, len(goBlocks))
+			continue
+		}
+		if goBlocks[0].Code != "func fixed() {}" || goBlocks[1].Code != "var x = 1" {
+			t.Errorf("CollectCodeBlocks(%q) = %+v", lang, goBlocks)
+		}
+	}
+
+	if got := CollectCodeBlocks(entries, "rust"); len(got) != 0 {
+		t.Errorf("CollectCodeBlocks(rust) = %+v, want none", got)
+	}
+}
+
+func TestCodeBlockExtension(t *testing.T) {
+	tests := map[string]string{
+		"go":      "go",
+		"Python":  "py",
+		"bash":    "sh",
+		"SQL":     "sql",
+		"":        "txt",
+		"unknown": "txt",
+	}
+	for lang, want := range tests {
+		if got := CodeBlockExtension(lang); got != want {
+			t.Errorf("CodeBlockExtension(%q) = %q, want %q", lang, got, want)
+		}
+	}
+}
+
+func TestFormatCodeBlock(t *testing.T) {
+	tests := []struct {
+		name  string
+		block CodeBlock
+		want  string
+	}{
+		{
+			name:  "go line comment",
+			block: CodeBlock{Language: "go", Code: "x := 1", UUID: "a1", Timestamp: "2026-02-01T10:00:05Z"},
+			want:  "// claude-history: code block 2 from message a1 at 2026-02-01T10:00:05Z\nx := 1\n",
+		},
+		{
+			name:  "python hash comment",
+			block: CodeBlock{Language: "Python", Code: "print(1)\n", UUID: "a2"},
+			want:  "# claude-history: code block 2 from message a2\nprint(1)\n",
+		},
+		{
+			name:  "html block comment",
+			block: CodeBlock{Language: "html", Code: "<p>hi</p>", UUID: "a3", Timestamp: "t"},
+			want:  "<!-- claude-history: code block 2 from message a3 at t -->\n<p>hi</p>\n",
+		},
+		{
+			name:  "unknown language",
+			block: CodeBlock{Code: "text", UUID: "a4"},
+			want:  "# claude-history: code block 2 from message a4\ntext\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatCodeBlock(tt.block, 2); got != tt.want {
+				t.Errorf("FormatCodeBlock() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	css := FormatCodeBlock(CodeBlock{Language: "css", Code: "a {}", UUID: "a5"}, 1)
+	if !strings.HasPrefix(css, "/* claude-history: code block 1 from message a5 */\n") {
+		t.Errorf("css header = %q", css)
+	}
+}
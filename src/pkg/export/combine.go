@@ -0,0 +1,184 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// assistantTurn returns the indices of the assistant entries that make up the logical
+// turn starting at entries[start], in order. Each later member must continue the previous
+// member's parent chain, either directly or through the user entries carrying its tool
+// results, which are the only entries allowed in between. Members must share the first
+// entry's agent, must not go back in time, and must render as ordinary messages.
+// A single-element result means there is nothing to combine.
+func assistantTurn(entries []models.ConversationEntry, start int, toolCallIDs map[string]bool) []int {
+	if !isCombinableAssistant(entries[start]) {
+		return []int{start}
+	}
+
+	turn := []int{start}
+	last := start
+	for j := last + 1; j < len(entries); j++ {
+		candidate := entries[j]
+		if candidate.Type != models.EntryTypeAssistant {
+			// Only invisible tool-result carriers may sit between members
+			if !isHiddenToolResultEntry(candidate, toolCallIDs) {
+				break
+			}
+			continue
+		}
+
+		if !isCombinableAssistant(candidate) ||
+			candidate.AgentID != entries[start].AgentID ||
+			entryBefore(candidate, entries[last]) ||
+			!continuesTurn(entries, last, j) {
+			break
+		}
+		turn = append(turn, j)
+		last = j
+	}
+	return turn
+}
+
+// cutTurnAt returns turn up to its first member that breaks reports a break before,
+// given the member before it. Cutting a turn where a gap marker or day separator falls
+// keeps the marker between bubbles instead of losing it inside one.
+func cutTurnAt(entries []models.ConversationEntry, turn []int, breaks func(prev, next *models.ConversationEntry) bool) []int {
+	for k := 1; k < len(turn); k++ {
+		if breaks(&entries[turn[k-1]], &entries[turn[k]]) {
+			return turn[:k]
+		}
+	}
+	return turn
+}
+
+// isCombinableAssistant reports whether entry is an assistant message that renders as
+// an ordinary bubble (not a TodoWrite checklist or an inline marker).
+func isCombinableAssistant(entry models.ConversationEntry) bool {
+	return entry.Type == models.EntryTypeAssistant && hasContent(entry) && !isTodoWriteOnly(entry) && !entry.IsAPIError()
+}
+
+// isHiddenToolResultEntry reports whether entry is a user entry that only carries tool
+// results shown with their calls, and so renders nothing on its own.
+func isHiddenToolResultEntry(entry models.ConversationEntry, toolCallIDs map[string]bool) bool {
+	return entry.Type == models.EntryTypeUser &&
+		!hasContent(entry) &&
+		!entry.IsInterruption() &&
+		len(entry.ExtractToolResults()) > 0 &&
+		len(orphanToolResults(entry, toolCallIDs)) == 0
+}
+
+// continuesTurn reports whether entries[next]'s parent chain reaches entries[prev],
+// passing only through the entries between them.
+func continuesTurn(entries []models.ConversationEntry, prev, next int) bool {
+	between := make(map[string]models.ConversationEntry)
+	for k := prev + 1; k < next; k++ {
+		between[entries[k].UUID] = entries[k]
+	}
+
+	parent := entries[next].ParentUUID
+	for parent != nil {
+		if *parent == entries[prev].UUID {
+			return true
+		}
+		e, ok := between[*parent]
+		if !ok {
+			return false
+		}
+		delete(between, *parent) // guard against cycles
+		parent = e.ParentUUID
+	}
+	return false
+}
+
+// entryBefore reports whether a's timestamp is earlier than b's. Entries without a
+// parseable timestamp are not ordered.
+func entryBefore(a, b models.ConversationEntry) bool {
+	ta, errA := time.Parse(time.RFC3339Nano, a.Timestamp)
+	tb, errB := time.Parse(time.RFC3339Nano, b.Timestamp)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return ta.Before(tb)
+}
+
+// renderCombinedTurn renders the assistant entries at indices turn as one message bubble.
+// parts holds each member's pre-rendered content (see renderEntryContent); each is wrapped
+// with its entry's UUID so links to individual entries keep working (see
+// renderMessagePart). The header's copy-as-markdown button copies the whole turn, with
+// each entry's tool calls paired with their results in toolResults.
+func renderCombinedTurn(entries []models.ConversationEntry, turn []int, parts []string, toolResults map[string]models.ToolResult, projectPath, assistantLabel string, ro entryRenderOptions) string {
+	first := entries[turn[0]]
+	entryClass := getEntryClass(first.Type)
+
+	rowClass := ""
+	for _, idx := range turn {
+		if _, annotated := ro.opts.Annotations[entries[idx].UUID]; annotated {
+			rowClass = " annotated"
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<div class="message-row %s combined%s" data-uuid="%s">`, entryClass, rowClass, escapeHTML(first.UUID)))
+	sb.WriteString("\n")
+	sb.WriteString("  " + renderAvatar(first.Type, ro.opts.Avatars))
+	sb.WriteString("\n")
+	sb.WriteString(`  <div class="message-bubble">`)
+	sb.WriteString("\n")
+
+	// Header comes from the first entry of the turn
+	sb.WriteString(`    <div class="message-header">`)
+	sb.WriteString(fmt.Sprintf(`<span class="role">%s</span>`, escapeHTML(assistantLabel)))
+	sb.WriteString(renderModelBadge(first, ro.defaultModel))
+	if displayAgentID := determineDisplayAgentID(first, "", ""); displayAgentID != "" {
+		sb.WriteString(renderAgentIDWithCopyWith(first, displayAgentID, "", "", projectPath, assistantLabel, ro.shortIDs, ro.agentAnchors))
+	}
+	sb.WriteString(renderTimestampSpan(first.Timestamp, formatTimestampReadable(first.Timestamp), ro))
+	sb.WriteString(renderRawLink(first, ro))
+	sb.WriteString(renderCopyTurnButton(entries, turn, toolResults, assistantLabel))
+	sb.WriteString("</div>\n")
+
+	sb.WriteString(`    <div class="message-content">`)
+	for k, idx := range turn {
+		sb.WriteString(renderMessagePart(entries[idx], parts[k], ro))
+	}
+	sb.WriteString("</div>\n") // Close message-content
+	for k, idx := range turn {
+		sb.WriteString(renderRawInspector(entries[idx], fmt.Sprintf("%d/%d", k+1, len(turn)), ro))
+	}
+	sb.WriteString("  </div>\n") // Close message-bubble
+	sb.WriteString("</div>\n")   // Close message-row
+
+	return sb.String()
+}
+
+// renderMessagePart renders one entry of a combined turn: its content, led by the
+// markers the entry would show in its own header and followed by its edit history and
+// annotation, so they stay with the entry they belong to.
+func renderMessagePart(entry models.ConversationEntry, content string, ro entryRenderOptions) string {
+	var markers, after strings.Builder
+	annotation, annotated := ro.opts.Annotations[entry.UUID]
+	if annotated {
+		markers.WriteString(annotationMarker)
+	}
+	if entry.HasRevisions() {
+		markers.WriteString(revisionMarker)
+		after.WriteString(strings.TrimSpace(renderRevisions(entry)))
+	}
+	if annotated {
+		after.WriteString(strings.TrimSpace(renderAnnotation(annotation)))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<div class="message-part" data-uuid="%s">`, escapeHTML(entry.UUID)))
+	if markers.Len() > 0 {
+		sb.WriteString(`<div class="message-part-markers">` + markers.String() + `</div>`)
+	}
+	sb.WriteString(content)
+	sb.WriteString(after.String())
+	sb.WriteString("</div>")
+	return sb.String()
+}
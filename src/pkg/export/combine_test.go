@@ -0,0 +1,231 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func strPtr(s string) *string { return &s }
+
+// combineTestEntries builds a prompt followed by one assistant turn spread over three
+// entries (text, tool call, tool result carrier, final text) and a second prompt.
+func combineTestEntries() []models.ConversationEntry {
+	return []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z",
+			Message: json.RawMessage(`{"role":"user","content":"list files"}`)},
+		{UUID: "a1", ParentUUID: strPtr("u1"), Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:01Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Let me look."}]}`)},
+		{UUID: "a2", ParentUUID: strPtr("a1"), Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:02Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls"}}]}`)},
+		{UUID: "r1", ParentUUID: strPtr("a2"), Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:03Z",
+			Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"main.go"}]}`)},
+		{UUID: "a3", ParentUUID: strPtr("r1"), Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:04Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"There is one file."}]}`)},
+		{UUID: "u2", ParentUUID: strPtr("a3"), Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:05Z",
+			Message: json.RawMessage(`{"role":"user","content":"thanks"}`)},
+	}
+}
+
+func TestAssistantTurn(t *testing.T) {
+	entries := combineTestEntries()
+	callIDs := buildToolCallIDSet(entries)
+
+	if got := assistantTurn(entries, 1, callIDs); !equalInts(got, []int{1, 2, 4}) {
+		t.Errorf("assistantTurn() = %v, want [1 2 4]", got)
+	}
+	if got := assistantTurn(entries, 0, callIDs); !equalInts(got, []int{0}) {
+		t.Errorf("assistantTurn() on a user entry = %v, want [0]", got)
+	}
+}
+
+func TestAssistantTurn_StopsAtBrokenChain(t *testing.T) {
+	entries := combineTestEntries()
+	entries[4].ParentUUID = strPtr("elsewhere")
+
+	if got := assistantTurn(entries, 1, buildToolCallIDSet(entries)); !equalInts(got, []int{1, 2}) {
+		t.Errorf("assistantTurn() = %v, want [1 2]", got)
+	}
+}
+
+func TestAssistantTurn_StopsWhenTimeGoesBackwards(t *testing.T) {
+	entries := combineTestEntries()
+	entries[2].Timestamp = "2026-02-01T09:59:00Z"
+
+	if got := assistantTurn(entries, 1, buildToolCallIDSet(entries)); !equalInts(got, []int{1}) {
+		t.Errorf("assistantTurn() = %v, want [1]", got)
+	}
+}
+
+func TestAssistantTurn_StopsAtVisibleEntry(t *testing.T) {
+	entries := combineTestEntries()
+	// A tool result carrier that also has text is rendered, so it ends the turn
+	entries[3].Message = json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"main.go"},{"type":"text","text":"also check tests"}]}`)
+
+	if got := assistantTurn(entries, 1, buildToolCallIDSet(entries)); !equalInts(got, []int{1, 2}) {
+		t.Errorf("assistantTurn() = %v, want [1 2]", got)
+	}
+}
+
+func TestRenderConversation_CombineToolMessages(t *testing.T) {
+	entries := combineTestEntries()
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen, CombineToolMessages: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+
+	if n := strings.Count(html, `class="message-row assistant`); n != 1 {
+		t.Errorf("expected 1 assistant bubble, got %d", n)
+	}
+	if !strings.Contains(html, `<div class="message-row assistant combined" data-uuid="a1">`) {
+		t.Error("combined bubble should carry the first entry's UUID")
+	}
+	for _, uuid := range []string{"a1", "a2", "a3"} {
+		if !strings.Contains(html, `<div class="message-part" data-uuid="`+uuid+`">`) {
+			t.Errorf("missing message part for %s", uuid)
+		}
+	}
+	// Parts stay in order
+	first := strings.Index(html, "Let me look.")
+	tool := strings.Index(html, `data-tool-id="t1"`)
+	last := strings.Index(html, "There is one file.")
+	if !(first < tool && tool < last) {
+		t.Errorf("parts out of order: text %d, tool %d, text %d", first, tool, last)
+	}
+	if strings.Contains(html, "TOOL: Bash") {
+		t.Error("combined turn should not use a separate tool-only header")
+	}
+}
+
+func TestRenderConversation_CombineToolMessagesAnnotations(t *testing.T) {
+	opts := ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen, CombineToolMessages: true,
+		Annotations: map[string]Annotation{"a2": {Note: "Check this call", Tags: []string{"bug"}}}}
+
+	html, err := RenderConversationWithOptions(combineTestEntries(), nil, nil, opts)
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+
+	if !strings.Contains(html, `<div class="message-row assistant combined annotated" data-uuid="a1">`) {
+		t.Error("combined bubble with an annotated part should be marked annotated")
+	}
+	part := html[strings.Index(html, `<div class="message-part" data-uuid="a2">`):strings.Index(html, `<div class="message-part" data-uuid="a3">`)]
+	for _, want := range []string{annotationMarker, `<div class="annotation-note">Check this call</div>`, `<span class="annotation-tag">bug</span>`} {
+		if !strings.Contains(part, want) {
+			t.Errorf("annotated part missing %q:\n%s", want, part)
+		}
+	}
+	if strings.Count(html, "annotation-marker") != 1 || strings.Count(html, "annotation-note") != 1 {
+		t.Error("only the annotated part should show the annotation")
+	}
+}
+
+func TestRenderConversation_CombineToolMessagesRevisions(t *testing.T) {
+	entries := combineTestEntries()
+	entries[4].Revisions = []models.MessageRevision{
+		{Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"There are files."}]}`)},
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen, CombineToolMessages: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+
+	part := html[strings.Index(html, `<div class="message-part" data-uuid="a3">`):]
+	for _, want := range []string{revisionMarker, `Edit history (1 earlier version)`, `<ins class="diff-ins">`} {
+		if !strings.Contains(part, want) {
+			t.Errorf("edited part missing %q:\n%s", want, part)
+		}
+	}
+	if strings.Count(html, "revision-marker") != 1 {
+		t.Error("only the edited part should be marked")
+	}
+}
+
+func TestRenderConversation_CombineToolMessagesCopyMarkdown(t *testing.T) {
+	html, err := RenderConversationWithOptions(combineTestEntries(), nil, nil, ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen, CombineToolMessages: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+
+	row := html[strings.Index(html, `<div class="message-row assistant combined"`):]
+	row = row[:strings.Index(row, `<div class="message-content">`)]
+	md := copiedMarkdown(t, row)
+	first, tool, last := strings.Index(md, "Let me look."), strings.Index(md, "**Tool:** `Bash`"), strings.Index(md, "There is one file.")
+	if first < 0 || !(first < tool && tool < last) {
+		t.Errorf("copied markdown should hold every entry of the turn in order:\n%s", md)
+	}
+	if !strings.Contains(md, "main.go") {
+		t.Errorf("copied markdown missing the tool output:\n%s", md)
+	}
+}
+
+func TestRenderConversation_CombineToolMessagesSplitsAtGapsAndDays(t *testing.T) {
+	gapEntries := combineTestEntries()
+	gapEntries[3].Timestamp = "2026-02-01T10:00:03Z"
+	gapEntries[4].Timestamp = "2026-02-01T10:20:04Z"
+	gapEntries[5].Timestamp = "2026-02-01T10:20:05Z"
+	dayEntries := combineTestEntries()
+	dayEntries[4].Timestamp = "2026-02-02T09:00:00Z"
+	dayEntries[5].Timestamp = "2026-02-02T09:00:01Z"
+
+	tests := []struct {
+		name    string
+		entries []models.ConversationEntry
+		opts    ExportOptions
+		marker  string
+	}{
+		{"gap", gapEntries, ExportOptions{ShowGaps: true}, `class="time-gap"`},
+		{"day", dayEntries, ExportOptions{DaySeparators: true}, `class="day-separator"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.SummaryMaxLen = DefaultSummaryMaxLen
+			tt.opts.CombineToolMessages = true
+			html, err := RenderConversationWithOptions(tt.entries, nil, nil, tt.opts)
+			if err != nil {
+				t.Fatalf("RenderConversationWithOptions() error = %v", err)
+			}
+
+			if !strings.Contains(html, `<div class="message-row assistant combined" data-uuid="a1">`) {
+				t.Error("entries before the break should still be combined")
+			}
+			marker, last := strings.LastIndex(html, tt.marker), strings.Index(html, `data-uuid="a3"`)
+			if marker < 0 || marker > last || marker < strings.Index(html, `data-uuid="a2"`) {
+				t.Errorf("%s should sit between a2 and a3 (at %d, a3 at %d)", tt.marker, marker, last)
+			}
+			if strings.Contains(html, `<div class="message-part" data-uuid="a3">`) {
+				t.Error("a3 should not be part of the combined turn")
+			}
+		})
+	}
+}
+
+func TestRenderConversation_SeparateByDefault(t *testing.T) {
+	html, err := RenderConversationWithOptions(combineTestEntries(), nil, nil, ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+
+	if n := strings.Count(html, `class="message-row assistant`); n != 3 {
+		t.Errorf("expected 3 separate assistant bubbles, got %d", n)
+	}
+	if strings.Contains(html, "message-part") || strings.Contains(html, " combined") {
+		t.Error("default rendering should not combine entries")
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
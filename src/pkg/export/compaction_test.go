@@ -0,0 +1,67 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func compactedSession() []models.ConversationEntry {
+	return []models.ConversationEntry{
+		{Type: models.EntryTypeSummary, UUID: "title"},
+		{Type: models.EntryTypeUser, UUID: "u1", Message: json.RawMessage(`"Refactor the parser"`)},
+		{Type: models.EntryTypeSystem, UUID: "b1", Subtype: "compact_boundary", Content: json.RawMessage(`"Conversation compacted"`)},
+		{Type: models.EntryTypeUser, UUID: "s1", IsCompactSummary: true, Message: json.RawMessage(`"This session is being continued from a previous conversation. Summary: ..."`)},
+		{Type: models.EntryTypeUser, UUID: "u2", Message: json.RawMessage(`"Keep going"`)},
+		// An older version recorded only the summary
+		{Type: models.EntryTypeUser, UUID: "s2", Message: json.RawMessage(`"This session is being continued from a previous conversation. Summary: ..."`)},
+	}
+}
+
+func TestRenderConversation_CompactionDividers(t *testing.T) {
+	entries := compactedSession()
+	blocks := renderConversationBlocks(entries, nil, ComputeSessionStats(entries, nil), ExportOptions{})
+
+	var kinds []string
+	for _, block := range blocks {
+		kinds = append(kinds, block.Kind+":"+block.UUID)
+	}
+	got := strings.Join(kinds, " ")
+	want := "message:u1 compaction:b1 message:s1 message:u2 compaction:s2 message:s2"
+	if got != want {
+		t.Errorf("blocks = %s, want %s", got, want)
+	}
+
+	html, err := RenderConversationWithStats(entries, nil, nil)
+	if err != nil {
+		t.Fatalf("RenderConversationWithStats() error = %v", err)
+	}
+	if strings.Count(html, `<div class="compaction-divider" role="separator"`) != 2 {
+		t.Error("each compaction should get one divider")
+	}
+	if !strings.Contains(html, "— context compacted here —") {
+		t.Error("divider text missing")
+	}
+}
+
+func TestComputeSessionStats_CompactionCount(t *testing.T) {
+	stats := ComputeSessionStats(compactedSession(), nil)
+	if stats.CompactionCount != 2 {
+		t.Errorf("CompactionCount = %d, want 2", stats.CompactionCount)
+	}
+
+	header := renderHTMLHeader(stats, nil, localizer{})
+	if !strings.Contains(header, "Compactions: 2") {
+		t.Error("header should report the compactions")
+	}
+	if header := renderHTMLHeader(&SessionStats{}, nil, localizer{}); strings.Contains(header, "Compactions") {
+		t.Error("header should not mention compactions when there were none")
+	}
+
+	doc := buildJSONExport(compactedSession(), nil, stats)
+	if doc.Stats.Compactions != 2 {
+		t.Errorf("JSON compactions = %d, want 2", doc.Stats.Compactions)
+	}
+}
@@ -52,6 +52,66 @@ func TestGetControlsJS_HasStorageKey(t *testing.T) {
 	}
 }
 
+func TestGetControlsJS_StateScopedPerSession(t *testing.T) {
+	content := GetControlsJS()
+
+	// Saved state is keyed by the page's session ID, not one shared key
+	for _, want := range []string{
+		"getStorageKey",
+		"data-session-id",
+		"STORAGE_KEY + ':' + ",
+		"expandedIds",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("controls.js missing %q", want)
+		}
+	}
+	if strings.Contains(content, "localStorage.getItem(STORAGE_KEY)") || strings.Contains(content, "localStorage.setItem(STORAGE_KEY,") {
+		t.Error("controls.js should not read or write the unscoped STORAGE_KEY")
+	}
+}
+
+func TestGetControlsJS_StorageFailuresAreSilent(t *testing.T) {
+	content := GetControlsJS()
+
+	if !strings.Contains(content, "function getStorage()") {
+		t.Fatal("controls.js should probe storage availability in getStorage")
+	}
+	// Every storage access goes through getStorage, inside try/catch, without logging
+	if strings.Count(content, "window.localStorage") != 1 {
+		t.Error("controls.js should only touch window.localStorage in getStorage")
+	}
+	for _, noisy := range []string{"Failed to load controls state", "Failed to save controls state"} {
+		if strings.Contains(content, noisy) {
+			t.Errorf("controls.js should degrade silently, found %q", noisy)
+		}
+	}
+}
+
+func TestGetControlsJS_RestoresLoadedAgentContent(t *testing.T) {
+	if !strings.Contains(GetControlsJS(), "restoreState: restoreState") {
+		t.Error("ControlsAPI should expose restoreState")
+	}
+	if !strings.Contains(GetNavigationJS(), "ControlsAPI.restoreState(container)") {
+		t.Error("navigation.js should restore saved state in loaded agent content")
+	}
+	if !strings.Contains(GetScriptJS(), "ControlsAPI.restoreState(container)") {
+		t.Error("script.js should restore saved state in loaded agent content")
+	}
+}
+
+func TestRenderHTMLHeader_BodySessionID(t *testing.T) {
+	html := renderHTMLHeader(&SessionStats{SessionID: "abc-123"}, nil, localizer{})
+	if !strings.Contains(html, `<body data-session-id="abc-123">`) {
+		t.Errorf("body should carry the session ID, got header:\n%s", html[:strings.Index(html, "<header")])
+	}
+
+	html = renderHTMLHeader(nil, nil, localizer{})
+	if !strings.Contains(html, "<body>\n") {
+		t.Error("body should have no session ID without stats")
+	}
+}
+
 func TestGetControlsJS_HasKeyboardShortcuts(t *testing.T) {
 	content := GetControlsJS()
 
@@ -527,3 +587,126 @@ func TestGetControlsJS_WrapAroundNavigation(t *testing.T) {
 		t.Error("controls.js should wrap to last match when going before first")
 	}
 }
+
+func TestControlPanel_HasSearchOptionToggles(t *testing.T) {
+	headers := map[string]string{
+		"htmlHeader":       htmlHeader,
+		"renderHTMLHeader": renderHTMLHeader(&SessionStats{}, nil, localizer{}),
+	}
+	for name, header := range headers {
+		for _, want := range []string{
+			`id="search-case-btn"`,
+			`id="search-word-btn"`,
+			`class="search-option-btn" aria-pressed="false"`,
+			`aria-label="Match case"`,
+			`aria-label="Match whole word"`,
+		} {
+			if !strings.Contains(header, want) {
+				t.Errorf("%s missing %s", name, want)
+			}
+		}
+
+		// Toggles sit inside the search container next to the box
+		container := header[strings.Index(header, `<div class="search-container">`):]
+		container = container[:strings.Index(container, "</div>")]
+		if !strings.Contains(container, `id="search-case-btn"`) || !strings.Contains(container, `id="search-word-btn"`) {
+			t.Errorf("%s: toggles should be inside the search container", name)
+		}
+	}
+}
+
+func TestGetControlsJS_SearchOptions(t *testing.T) {
+	content := GetControlsJS()
+
+	for _, want := range []string{
+		"caseSensitive: false",
+		"wholeWord: false",
+		"function buildSearchRegex",
+		"function setSearchOption",
+		"setSearchOption: setSearchOption",
+		// Case-insensitive unless the toggle is on
+		"searchOptions.caseSensitive ? 'g' : 'gi'",
+		// Toggle state is reflected for assistive technology
+		"aria-pressed",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("controls.js missing %q", want)
+		}
+	}
+
+	// Toggling re-runs the search with the query still in the box
+	setOption := content[strings.Index(content, "function setSearchOption"):]
+	setOption = setOption[:strings.Index(setOption, "\n    }\n")]
+	if !strings.Contains(setOption, "performSearch(searchBox.value)") {
+		t.Error("setSearchOption should re-run the current query")
+	}
+	if strings.Contains(setOption, "searchBox.value = ") {
+		t.Error("setSearchOption should not modify the query")
+	}
+}
+
+func TestCSSContent_HasSearchOptionStyles(t *testing.T) {
+	css := GetStyleCSS()
+	if !strings.Contains(css, ".search-option-btn") {
+		t.Error("CSS should style search option toggles")
+	}
+	if !strings.Contains(css, `.search-option-btn[aria-pressed="true"]`) {
+		t.Error("CSS should highlight active search option toggles")
+	}
+}
+
+func TestRenderTypeFilters(t *testing.T) {
+	filters := renderTypeFilters("Orchestrator", "Agent")
+	for _, entryType := range displayedEntryTypes {
+		want := `data-entry-type="` + getEntryClass(entryType) + `" checked> ` + getRoleLabel(entryType, "Orchestrator", "Agent")
+		if !strings.Contains(filters, want) {
+			t.Errorf("type filters missing %s checkbox %q", entryType, want)
+		}
+	}
+	for _, want := range []string{`id="type-filter-remember"`, `class="type-filter-count" aria-live="polite"`} {
+		if !strings.Contains(filters, want) {
+			t.Errorf("type filters missing %q", want)
+		}
+	}
+	if strings.Contains(filters, `id="type-filter-remember" checked`) {
+		t.Error("remembering hidden types should be off until chosen")
+	}
+}
+
+func TestRenderHTMLHeader_HasTypeFilters(t *testing.T) {
+	header := renderHTMLHeader(&SessionStats{}, nil, localizer{})
+	controls := header[strings.Index(header, `class="controls"`):]
+	if !strings.Contains(controls, `class="controls-group type-filters"`) || !strings.Contains(controls, `data-entry-type="system" checked> System`) {
+		t.Error("header controls should include the message type checkboxes")
+	}
+}
+
+func TestCSSContent_HidesEveryFilterableType(t *testing.T) {
+	css := GetStyleCSS()
+	for _, entryType := range displayedEntryTypes {
+		class := getEntryClass(entryType)
+		if rule := "body.hide-type-" + class + " .message-row." + class; !strings.Contains(css, rule) {
+			t.Errorf("style.css missing type filter rule %q", rule)
+		}
+	}
+}
+
+func TestGetControlsJS_TypeFilters(t *testing.T) {
+	content := GetControlsJS()
+	for _, want := range []string{
+		"function setTypeVisible",
+		"function isHiddenByType",
+		"function updateTypeFilterCount",
+		"HIDE_TYPE_CLASS_PREFIX = 'hide-type-'",
+		// Search skips messages hidden by type
+		"if (isHiddenByType(entry)) return;",
+		// Persisting is opt-in via the Remember checkbox
+		"remember && remember.checked",
+		"setTypeVisible: setTypeVisible",
+		"initTypeFilters();",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("controls.js missing %q", want)
+		}
+	}
+}
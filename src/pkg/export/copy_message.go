@@ -0,0 +1,42 @@
+package export
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// renderCopyMessageButton renders the message header button that copies the entry as
+// markdown: its role heading, text, and tool calls with their inputs and outputs in fenced
+// blocks, as the markdown export renders it (see renderEntryMarkdownWith). The markdown is
+// base64-encoded in data-copy-markdown, which clipboard.js decodes on click, so the
+// message's text and tool output are not repeated in the page as plain text.
+func renderCopyMessageButton(entry models.ConversationEntry, toolResults map[string]models.ToolResult, userLabel, assistantLabel string) string {
+	return renderCopyMarkdownButton(strings.TrimRight(renderEntryMarkdownWith(entry, toolResults, userLabel, assistantLabel), "\n"))
+}
+
+// renderCopyTurnButton renders the copy-as-markdown button of a combined turn (see
+// renderCombinedTurn): the markdown of each of its entries, as renderCopyMessageButton
+// copies it, one after another.
+func renderCopyTurnButton(entries []models.ConversationEntry, turn []int, toolResults map[string]models.ToolResult, assistantLabel string) string {
+	var parts []string
+	for _, idx := range turn {
+		if md := strings.TrimRight(renderEntryMarkdownWith(entries[idx], toolResults, "", assistantLabel), "\n"); md != "" {
+			parts = append(parts, md)
+		}
+	}
+	return renderCopyMarkdownButton(strings.Join(parts, "\n\n"))
+}
+
+// renderCopyMarkdownButton renders a copy-as-markdown button for md, or "" if md is empty.
+func renderCopyMarkdownButton(md string) string {
+	if md == "" {
+		return ""
+	}
+	return fmt.Sprintf(
+		` <button class="copy-btn copy-message-btn" data-copy-markdown="%s" data-copy-type="message-markdown" title="Copy message as markdown"><span class="copy-icon">&#128203;</span></button>`,
+		base64.StdEncoding.EncodeToString([]byte(md+"\n")),
+	)
+}
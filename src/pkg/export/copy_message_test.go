@@ -0,0 +1,63 @@
+package export
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+var copyMarkdownAttr = regexp.MustCompile(`data-copy-markdown="([^"]*)"`)
+
+// copiedMarkdown returns the decoded markdown of the first copy-as-markdown button in html.
+func copiedMarkdown(t *testing.T, html string) string {
+	t.Helper()
+	m := copyMarkdownAttr.FindStringSubmatch(html)
+	if m == nil {
+		t.Fatalf("no copy-as-markdown button in:\n%s", html)
+	}
+	md, err := base64.StdEncoding.DecodeString(m[1])
+	if err != nil {
+		t.Fatalf("data-copy-markdown is not base64: %v", err)
+	}
+	return string(md)
+}
+
+func TestRenderEntry_CopyMessageMarkdown(t *testing.T) {
+	entry := models.ConversationEntry{
+		UUID:      "a1",
+		Type:      models.EntryTypeAssistant,
+		Timestamp: "2026-01-15T10:00:00Z",
+		Message: json.RawMessage(`{"role":"assistant","content":[` +
+			`{"type":"text","text":"Listing <files> & more"},` +
+			`{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"ls"}}]}`),
+	}
+	results := map[string]models.ToolResult{"toolu_1": {ToolUseID: "toolu_1", Content: "main.go"}}
+
+	html := renderEntry(entry, results, "", "", "", "User", "Assistant")
+	if !strings.Contains(html, `class="copy-btn copy-message-btn"`) {
+		t.Fatal("message header should have a copy-as-markdown button")
+	}
+
+	md := copiedMarkdown(t, html)
+	for _, want := range []string{"## Assistant", "Listing <files> & more", "**Tool:** `Bash`", "```json\n", `"command": "ls"`, "Output:\n\n```\nmain.go\n```"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("copied markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "&lt;") || strings.Contains(md, "<div") {
+		t.Errorf("copied markdown should not contain HTML artifacts:\n%s", md)
+	}
+}
+
+func TestCSSContent_CopyMessageHiddenInPrint(t *testing.T) {
+	css := GetStyleCSS()
+	print := css[strings.Index(css, "@media print"):]
+	rule := print[:strings.Index(print, "display: none")]
+	if !strings.Contains(rule, ".copy-message-btn") {
+		t.Error("copy-as-markdown buttons should be hidden when printing")
+	}
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// daySeparatorFormat is the label of a day separator, e.g. "February 1, 2026".
+const daySeparatorFormat = "January 2, 2006"
+
+// dayTracker decides where ExportOptions.DaySeparators headers go: before the first
+// dated entry of each calendar day in loc. Entries without a parseable timestamp never
+// start a new day.
+type dayTracker struct {
+	enabled bool
+	loc     *time.Location
+	lastDay string // "2006-01-02" of the last dated entry seen
+}
+
+// newDayTracker returns a tracker for entries. It is disabled unless opts.DaySeparators
+// is set and the entries span more than one day in opts.Location (UTC if nil).
+func newDayTracker(entries []models.ConversationEntry, opts ExportOptions) *dayTracker {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	d := &dayTracker{loc: loc}
+	if !opts.DaySeparators {
+		return d
+	}
+
+	first := ""
+	for _, entry := range entries {
+		day, ok := d.day(entry.Timestamp)
+		if !ok {
+			continue
+		}
+		if first == "" {
+			first = day
+		} else if day != first {
+			d.enabled = true
+			break
+		}
+	}
+	return d
+}
+
+// day returns the calendar day of timestamp in the tracker's zone.
+func (d *dayTracker) day(timestamp string) (string, bool) {
+	if timestamp == "" {
+		return "", false
+	}
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return "", false
+	}
+	return t.In(d.loc).Format("2006-01-02"), true
+}
+
+// separatorFor returns the separator markup to insert before entry, or "" if entry does
+// not start a new day.
+func (d *dayTracker) separatorFor(entry *models.ConversationEntry) string {
+	if !d.enabled || entry == nil {
+		return ""
+	}
+	day, ok := d.day(entry.Timestamp)
+	if !ok || day == d.lastDay {
+		return ""
+	}
+	d.lastDay = day
+
+	t, _ := time.ParseInLocation("2006-01-02", day, d.loc)
+	return fmt.Sprintf(`<div class="day-separator" role="separator"><time datetime="%s">%s</time></div>`+"\n",
+		day, t.Format(daySeparatorFormat))
+}
+
+// breaksBetween reports whether separatorFor would put a separator before next if prev
+// were the entry before it.
+func (d *dayTracker) breaksBetween(prev, next *models.ConversationEntry) bool {
+	if !d.enabled {
+		return false
+	}
+	prevDay, okPrev := d.day(prev.Timestamp)
+	nextDay, okNext := d.day(next.Timestamp)
+	return okPrev && okNext && prevDay != nextDay
+}
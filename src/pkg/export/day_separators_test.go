@@ -0,0 +1,173 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// dayTestEntries returns alternating user/assistant text entries with the given
+// timestamps, with UUIDs e0, e1, ...
+func dayTestEntries(timestamps ...string) []models.ConversationEntry {
+	entries := paginateTestEntries(len(timestamps))
+	for i, ts := range timestamps {
+		entries[i].Timestamp = ts
+	}
+	return entries
+}
+
+func TestRenderConversationWithOptions_DaySeparators(t *testing.T) {
+	entries := dayTestEntries(
+		"2026-02-01T10:00:00Z",
+		"2026-02-01T23:30:00Z",
+		"2026-02-02T09:00:00Z",
+		"2026-02-02T09:05:00Z",
+		"2026-02-04T12:00:00Z",
+	)
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{DaySeparators: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+
+	if got := strings.Count(html, `class="day-separator"`); got != 3 {
+		t.Fatalf("day separator count = %d, want 3", got)
+	}
+	for _, want := range []string{
+		`<time datetime="2026-02-01">February 1, 2026</time>`,
+		`<time datetime="2026-02-02">February 2, 2026</time>`,
+		`<time datetime="2026-02-04">February 4, 2026</time>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing separator %s", want)
+		}
+	}
+
+	// Each separator directly precedes the first message of its day
+	order := []string{"February 1, 2026", `data-uuid="e0"`, `data-uuid="e1"`, "February 2, 2026", `data-uuid="e2"`, `data-uuid="e3"`, "February 4, 2026", `data-uuid="e4"`}
+	last := -1
+	for _, marker := range order {
+		idx := strings.Index(html, marker)
+		if idx <= last {
+			t.Fatalf("%s is out of order", marker)
+		}
+		last = idx
+	}
+}
+
+func TestRenderConversationWithOptions_DaySeparatorsOffByDefault(t *testing.T) {
+	entries := dayTestEntries("2026-02-01T10:00:00Z", "2026-02-02T10:00:00Z")
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if strings.Contains(html, `class="day-separator"`) {
+		t.Error("day separators should not be emitted by default")
+	}
+}
+
+func TestRenderConversationWithOptions_DaySeparatorsSingleDay(t *testing.T) {
+	entries := dayTestEntries("2026-02-01T00:00:00Z", "2026-02-01T12:00:00Z", "2026-02-01T23:59:59Z")
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{DaySeparators: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if strings.Contains(html, `class="day-separator"`) {
+		t.Error("single-day sessions should not get day separators")
+	}
+}
+
+func TestRenderConversationWithOptions_DaySeparatorsMissingTimestamps(t *testing.T) {
+	entries := dayTestEntries(
+		"2026-02-01T10:00:00Z",
+		"",
+		"not a timestamp",
+		"2026-02-01T11:00:00Z",
+		"",
+		"2026-02-02T10:00:00Z",
+	)
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{DaySeparators: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if got := strings.Count(html, `class="day-separator"`); got != 2 {
+		t.Errorf("day separator count = %d, want 2 (undated entries must not start a day)", got)
+	}
+	// The second day starts at e5, not at the undated e4 before it
+	if strings.Index(html, "February 2, 2026") < strings.Index(html, `data-uuid="e4"`) {
+		t.Error("separator for February 2 should follow the undated entry e4")
+	}
+}
+
+func TestRenderConversationWithOptions_DaySeparatorsTimezone(t *testing.T) {
+	// 01:00 and 23:00 UTC on Feb 1 fall on different days in New York (Jan 31 and Feb 1)
+	entries := dayTestEntries("2026-02-01T01:00:00Z", "2026-02-01T23:00:00Z")
+
+	utc, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{DaySeparators: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if strings.Contains(utc, `class="day-separator"`) {
+		t.Error("entries on the same UTC day should not get separators without a location")
+	}
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{DaySeparators: true, Location: ny})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	for _, want := range []string{"January 31, 2026", "February 1, 2026"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing separator %q for New York days", want)
+		}
+	}
+}
+
+func TestRenderConversationBlocks_DayKind(t *testing.T) {
+	entries := dayTestEntries("2026-02-01T10:00:00Z", "2026-02-02T10:00:00Z")
+
+	blocks := renderConversationBlocks(entries, nil, &SessionStats{}, ExportOptions{DaySeparators: true})
+
+	var kinds []string
+	for _, b := range blocks {
+		kinds = append(kinds, b.Kind)
+	}
+	want := []string{BlockDay, BlockMessage, BlockDay, BlockMessage}
+	if fmt.Sprint(kinds) != fmt.Sprint(want) {
+		t.Errorf("block kinds = %v, want %v", kinds, want)
+	}
+	if blocks[2].Timestamp != "2026-02-02T10:00:00Z" || blocks[2].UUID != "" {
+		t.Errorf("day block = %+v, want the day's first timestamp and no UUID", blocks[2])
+	}
+}
+
+func TestDayTracker_SeparatorFor(t *testing.T) {
+	entries := dayTestEntries("2026-03-01T10:00:00Z", "2026-03-02T10:00:00Z")
+	days := newDayTracker(entries, ExportOptions{DaySeparators: true})
+
+	if got := days.separatorFor(nil); got != "" {
+		t.Errorf("separatorFor(nil) = %q, want empty", got)
+	}
+	first := days.separatorFor(&entries[0])
+	if !strings.Contains(first, "March 1, 2026") {
+		t.Errorf("first separator = %q, want March 1, 2026", first)
+	}
+	if again := days.separatorFor(&entries[0]); again != "" {
+		t.Errorf("same day again = %q, want empty", again)
+	}
+
+	undated := models.ConversationEntry{Message: json.RawMessage(`"x"`)}
+	if got := days.separatorFor(&undated); got != "" {
+		t.Errorf("undated entry separator = %q, want empty", got)
+	}
+}
@@ -0,0 +1,63 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// debugPreviewBytes caps the raw message preview shown in a debug row.
+const debugPreviewBytes = 160
+
+// emptyEntryReason describes why hasContent dropped entry, for ExportOptions.ShowAll.
+func emptyEntryReason(entry models.ConversationEntry) string {
+	raw := bytes.TrimSpace(entry.Message)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "no message"
+	}
+	if results := entry.ExtractToolResults(); len(results) > 0 {
+		if len(results) == 1 {
+			return "1 tool result (shown with its call)"
+		}
+		return fmt.Sprintf("%d tool results (shown with their calls)", len(results))
+	}
+	if text := entry.GetTextContent(); text != "" && strings.TrimSpace(text) == "" {
+		return "whitespace-only text"
+	}
+	return "no displayable content"
+}
+
+// renderDebugRow renders an entry that the conversation normally hides (see hasContent)
+// as a faint one-line row: its type, timestamp, why it is hidden and a preview of the
+// raw message. Used only with ExportOptions.ShowAll.
+func renderDebugRow(entry models.ConversationEntry, ro entryRenderOptions) string {
+	entryType := string(entry.Type)
+	if entryType == "" {
+		entryType = "unknown"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<div class="debug-row" data-uuid="%s" data-entry-type="%s">`,
+		escapeHTML(entry.UUID), escapeHTML(entryType)))
+	sb.WriteString(fmt.Sprintf(`<span class="debug-type">%s</span>`, escapeHTML(entryType)))
+	if entry.Timestamp != "" {
+		sb.WriteString(renderTimestampSpan(entry.Timestamp, formatTimestampReadable(entry.Timestamp), ro))
+	}
+	sb.WriteString(fmt.Sprintf(` <span class="debug-reason">%s</span>`, escapeHTML(emptyEntryReason(entry))))
+
+	raw := strings.TrimSpace(string(entry.Message))
+	if raw != "" && raw != "null" {
+		preview, truncated := truncateUTF8(raw, debugPreviewBytes)
+		if truncated {
+			preview += "…"
+		}
+		sb.WriteString(fmt.Sprintf(` <code class="debug-raw">%s</code>`, escapeHTML(preview)))
+	}
+	sb.WriteString(renderRawLink(entry, ro))
+	sb.WriteString("</div>\n")
+	// The one-line row clips its content, so the inspector follows it
+	sb.WriteString(renderRawInspector(entry, "", ro))
+	return sb.String()
+}
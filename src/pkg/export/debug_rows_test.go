@@ -0,0 +1,133 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// showAllTestEntries returns a conversation mixing visible messages with entries that
+// hasContent drops.
+func showAllTestEntries() []models.ConversationEntry {
+	return []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: json.RawMessage(`"Hello"`)},
+		{UUID: "null1", Type: models.EntryTypeSystem, Timestamp: "2026-02-01T10:00:01Z", Message: json.RawMessage(`null`)},
+		{UUID: "none1", Type: models.EntryTypeSummary},
+		{UUID: "ws1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:02Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"  \n\t "}]}`)},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:03Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Read","input":{"file_path":"/a.go"}}]}`)},
+		{UUID: "r1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:04Z",
+			Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"package a"}]}`)},
+	}
+}
+
+func TestRenderConversationWithOptions_ShowAllOffByDefault(t *testing.T) {
+	html, err := RenderConversationWithOptions(showAllTestEntries(), nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if strings.Contains(html, `class="debug-row"`) {
+		t.Error("debug rows should not be rendered by default")
+	}
+	for _, uuid := range []string{"null1", "none1", "ws1", "r1"} {
+		if strings.Contains(html, `data-uuid="`+uuid+`"`) {
+			t.Errorf("hidden entry %s should not be rendered by default", uuid)
+		}
+	}
+}
+
+func TestRenderConversationWithOptions_ShowAll(t *testing.T) {
+	html, err := RenderConversationWithOptions(showAllTestEntries(), nil, nil, ExportOptions{ShowAll: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+
+	if got := strings.Count(html, `class="debug-row"`); got != 4 {
+		t.Errorf("debug row count = %d, want 4", got)
+	}
+	wantReasons := map[string]string{
+		"null1": "no message",
+		"none1": "no message",
+		"ws1":   "whitespace-only text",
+		"r1":    "1 tool result (shown with its call)",
+	}
+	for uuid, reason := range wantReasons {
+		start := strings.Index(html, `<div class="debug-row" data-uuid="`+uuid+`"`)
+		if start < 0 {
+			t.Errorf("missing debug row for %s", uuid)
+			continue
+		}
+		row := html[start : start+strings.Index(html[start:], "</div>")]
+		if !strings.Contains(row, reason) {
+			t.Errorf("debug row for %s = %q, want reason %q", uuid, row, reason)
+		}
+	}
+
+	// Visible entries render normally, in conversation order with the debug rows
+	if strings.Contains(html, `class="debug-row" data-uuid="u1"`) || strings.Contains(html, `class="debug-row" data-uuid="a1"`) {
+		t.Error("entries with content should not become debug rows")
+	}
+	if strings.Index(html, `data-uuid="u1"`) > strings.Index(html, `data-uuid="null1"`) ||
+		strings.Index(html, `data-uuid="ws1"`) > strings.Index(html, `data-uuid="a1"`) {
+		t.Error("debug rows should appear in conversation order")
+	}
+}
+
+func TestRenderConversationWithOptions_ShowAllKeepsOrphansAndPlaceholders(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "q1", Type: models.EntryTypeQueueOperation, AgentID: "agent1", Timestamp: "2026-02-01T10:00:00Z"},
+		{UUID: "o1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:01Z",
+			Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"missing","content":"lost"}]}`)},
+	}
+
+	blocks := renderConversationBlocks(entries, nil, &SessionStats{}, ExportOptions{ShowAll: true})
+
+	var kinds []string
+	for _, b := range blocks {
+		kinds = append(kinds, b.Kind)
+	}
+	want := []string{BlockDebug, BlockSubagent, BlockMessage}
+	if strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Fatalf("block kinds = %v, want %v (debug row, placeholder, orphan results)", kinds, want)
+	}
+	if !strings.Contains(string(blocks[2].HTML), "orphan-tool-results") {
+		t.Error("orphan results should render as before, not as a debug row")
+	}
+}
+
+func TestRenderDebugRow(t *testing.T) {
+	long := strings.Repeat("x", 500)
+	entry := models.ConversationEntry{
+		UUID:       "e1",
+		Type:       models.EntryTypeSystem,
+		Timestamp:  "2026-02-01T10:00:00Z",
+		Message:    json.RawMessage(`{"content":"` + long + `"}`),
+		SourceLine: 7,
+	}
+
+	row := renderDebugRow(entry, entryRenderOptions{opts: ExportOptions{RawSource: "source/s.jsonl"}})
+
+	for _, want := range []string{
+		`data-uuid="e1"`,
+		`data-entry-type="system"`,
+		`<span class="debug-type">system</span>`,
+		`<span class="timestamp">10:00 AM</span>`,
+		`<span class="debug-reason">no displayable content</span>`,
+		`href="source/s.jsonl#L7"`,
+	} {
+		if !strings.Contains(row, want) {
+			t.Errorf("debug row missing %q:\n%s", want, row)
+		}
+	}
+	if strings.Contains(row, long) || !strings.Contains(row, "…</code>") {
+		t.Error("raw preview should be truncated with an ellipsis")
+	}
+
+	unknown := renderDebugRow(models.ConversationEntry{UUID: "e2"}, entryRenderOptions{})
+	if !strings.Contains(unknown, `<span class="debug-type">unknown</span>`) || strings.Contains(unknown, "debug-raw") {
+		t.Errorf("entry without type or message = %q", unknown)
+	}
+}
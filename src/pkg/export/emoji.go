@@ -0,0 +1,111 @@
+package export
+
+import "strings"
+
+// emojiShortcodes maps the common :name: shortcodes to their emoji, for
+// ExportOptions.EmojiShortcodes. Names follow GitHub's.
+var emojiShortcodes = map[string]string{
+	"+1":                       "👍",
+	"-1":                       "👎",
+	"100":                      "💯",
+	"boom":                     "💥",
+	"brain":                    "🧠",
+	"bug":                      "🐛",
+	"bulb":                     "💡",
+	"chart_with_upwards_trend": "📈",
+	"clap":                     "👏",
+	"clipboard":                "📋",
+	"construction":             "🚧",
+	"cry":                      "😢",
+	"exclamation":              "❗",
+	"eyes":                     "👀",
+	"fire":                     "🔥",
+	"gear":                     "⚙️",
+	"hammer_and_wrench":        "🛠️",
+	"heart":                    "❤️",
+	"heavy_check_mark":         "✔️",
+	"hourglass":                "⌛",
+	"information_source":       "ℹ️",
+	"joy":                      "😂",
+	"key":                      "🔑",
+	"laughing":                 "😆",
+	"link":                     "🔗",
+	"lock":                     "🔒",
+	"mag":                      "🔍",
+	"memo":                     "📝",
+	"no_entry":                 "⛔",
+	"ok_hand":                  "👌",
+	"package":                  "📦",
+	"pencil":                   "📝",
+	"pencil2":                  "✏️",
+	"point_right":              "👉",
+	"pray":                     "🙏",
+	"question":                 "❓",
+	"recycle":                  "♻️",
+	"red_circle":               "🔴",
+	"rocket":                   "🚀",
+	"rotating_light":           "🚨",
+	"smile":                    "😄",
+	"smiley":                   "😃",
+	"sparkles":                 "✨",
+	"star":                     "⭐",
+	"tada":                     "🎉",
+	"thinking":                 "🤔",
+	"thumbsdown":               "👎",
+	"thumbsup":                 "👍",
+	"warning":                  "⚠️",
+	"wave":                     "👋",
+	"white_check_mark":         "✅",
+	"wink":                     "😉",
+	"wrench":                   "🔧",
+	"x":                        "❌",
+	"zap":                      "⚡",
+}
+
+// isShortcodeChar reports whether c can appear in the name of a shortcode.
+func isShortcodeChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '+' || c == '-'
+}
+
+// isAlphanumeric reports whether c is an ASCII letter or digit.
+func isAlphanumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// replaceEmojiShortcodes replaces the :name: shortcodes in content that emojiShortcodes
+// knows with their emoji. Unknown shortcodes are left as written, as is a shortcode
+// directly after a letter or digit (as in "host:8080:"), which is not one. Code and URLs
+// are placeholders by the time this runs, so colons in them are untouched.
+func replaceEmojiShortcodes(content string) string {
+	if strings.Count(content, ":") < 2 {
+		return content
+	}
+
+	var sb strings.Builder
+	last := 0
+	for i := 0; i < len(content); i++ {
+		if content[i] != ':' || (i > 0 && isAlphanumeric(content[i-1])) {
+			continue
+		}
+		end := i + 1
+		for end < len(content) && isShortcodeChar(content[end]) {
+			end++
+		}
+		if end == i+1 || end >= len(content) || content[end] != ':' {
+			continue
+		}
+		emoji, ok := emojiShortcodes[content[i+1:end]]
+		if !ok {
+			continue
+		}
+		sb.WriteString(content[last:i])
+		sb.WriteString(emoji)
+		last = end + 1
+		i = end
+	}
+	if last == 0 {
+		return content
+	}
+	sb.WriteString(content[last:])
+	return sb.String()
+}
@@ -0,0 +1,81 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestReplaceEmojiShortcodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"known", "Shipped :rocket:", "Shipped 🚀"},
+		{"several", ":tada::tada: done :+1:", "🎉🎉 done 👍"},
+		{"unknown left literal", "see :not_an_emoji: here", "see :not_an_emoji: here"},
+		{"unknown then known", ":nope::fire:", ":nope:🔥"},
+		{"after a word", "host:fire: and 10:30:00", "host:fire: and 10:30:00"},
+		{"uppercase is not a shortcode", ":Rocket:", ":Rocket:"},
+		{"unclosed", "ratio 1:2 :rocket", "ratio 1:2 :rocket"},
+		{"empty name", "a :: b", "a :: b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replaceEmojiShortcodes(tt.input); got != tt.want {
+				t.Errorf("replaceEmojiShortcodes(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownWith_EmojiShortcodes(t *testing.T) {
+	content := "Deployed :rocket: with `:rocket:` and\n\n```\nx := map[string]int{\":fire:\": 1}\n```\n\nSee https://example.com/:fire:/x and [:tada:](https://example.com/:zap:)"
+
+	got := renderMarkdownWith(content, "", markdownOptions{emojiShortcodes: true})
+
+	if !strings.Contains(got, "Deployed 🚀") {
+		t.Errorf("shortcode in text should become an emoji:\n%s", got)
+	}
+	if !strings.Contains(got, `<code class="inline-code">:rocket:</code>`) {
+		t.Errorf("inline code should be left as written:\n%s", got)
+	}
+	for _, want := range []string{":fire:", "https://example.com/:fire:/x", "https://example.com/:zap:"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q: code and URLs should be left as written:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "🔥") || strings.Contains(got, "⚡") {
+		t.Errorf("shortcodes in code or URLs should not be replaced:\n%s", got)
+	}
+}
+
+func TestRenderMarkdown_EmojiShortcodesOffByDefault(t *testing.T) {
+	if got := RenderMarkdown("Deployed :rocket:", ""); !strings.Contains(got, ":rocket:") {
+		t.Errorf("RenderMarkdown should leave shortcodes as written, got %q", got)
+	}
+}
+
+func TestRenderConversation_EmojiShortcodes(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:05Z", Message: []byte(`{"role":"assistant","content":[{"type":"text","text":"All green :white_check_mark:"}]}`)},
+	}
+
+	plain, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if !strings.Contains(plain, ":white_check_mark:") {
+		t.Error("shortcodes should be left as written by default")
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{EmojiShortcodes: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if !strings.Contains(html, "All green ✅") {
+		t.Error("EmojiShortcodes should replace shortcodes in assistant messages")
+	}
+}
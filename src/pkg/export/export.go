@@ -6,10 +6,12 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
 	"github.com/randlee/claude-history/pkg/paths"
 	"github.com/randlee/claude-history/pkg/resolver"
 	"github.com/randlee/claude-history/pkg/session"
@@ -23,6 +25,9 @@ type ExportResult struct {
 	// SessionID is the ID of the exported session.
 	SessionID string `json:"sessionId"`
 
+	// AgentID is the exported agent for ExportAgent results (empty for whole sessions).
+	AgentID string `json:"agentId,omitempty"`
+
 	// SourceDir is the path to the source/ subdirectory containing JSONL files.
 	SourceDir string `json:"sourceDir"`
 
@@ -37,6 +42,22 @@ type ExportResult struct {
 
 	// Errors contains any non-fatal errors encountered during export.
 	Errors []string `json:"errors,omitempty"`
+
+	// Resumed is true when verified source files from a previous export were reused.
+	Resumed bool `json:"resumed,omitempty"`
+
+	// HTMLFile is the primary artifact of the export, the file to open: index.html for
+	// html, conversation.<ext> for the other document formats, and the copied session
+	// file for jsonl (see ExportOptions.Format). Rendered files are written after
+	// ExportSession returns.
+	HTMLFile string `json:"htmlFile,omitempty"`
+
+	// ManifestFile is the path to manifest.json; empty if it could not be written.
+	ManifestFile string `json:"manifestFile,omitempty"`
+
+	// StaticDir is the directory of the CSS and JavaScript of an html export (empty for
+	// other formats).
+	StaticDir string `json:"staticDir,omitempty"`
 }
 
 // ExportOptions configures the export operation.
@@ -46,6 +67,296 @@ type ExportOptions struct {
 
 	// ClaudeDir is the custom Claude directory. If empty, uses default ~/.claude.
 	ClaudeDir string
+
+	// EncodedProjectDir names the project's directory in ClaudeDir/projects exactly
+	// (e.g. "-Users-me-my-app"), overriding the directory derived by encoding the
+	// project path. Use it when that lossy encoding does not match the directory Claude
+	// created. See ProjectDir.
+	EncodedProjectDir string
+
+	// RelativeTimes shows message timestamps as "5 minutes ago" with the absolute time on hover.
+	RelativeTimes bool
+
+	// Paginate inserts print page breaks between logical sections (every PageBreakEvery
+	// messages and before each subagent) for printing to PDF. Screen display is unaffected.
+	Paginate bool
+
+	// PageBreakEvery is the number of messages per printed page when Paginate is set (0 uses 20).
+	PageBreakEvery int
+
+	// Deterministic makes rendered output independent of the wall clock by using the
+	// last entry's timestamp wherever the export time would otherwise be used.
+	Deterministic bool
+
+	// Timeline, when non-empty, renders a bar chart of subagent activity spans
+	// above the conversation (see agent.AgentTimeSpans).
+	Timeline []agent.AgentSpan
+
+	// AgentSpans are the active time spans of the subagents (see agent.AgentTimeSpans).
+	// Each subagent header shows its span's duration; agents without a span, whose
+	// timestamps are unknown, get no duration badge.
+	AgentSpans []agent.AgentSpan
+
+	// Minify shrinks the HTML, CSS, and JavaScript of an HTML export by dropping the
+	// indentation and blank lines kept for readability. Text is never changed: the
+	// content of <pre> elements and messages keeps its whitespace exactly.
+	Minify bool
+
+	// Format is the output format the export is rendered in, a registered exporter name
+	// or "jsonl", which decides ExportResult.HTMLFile and StaticDir. Empty means html.
+	Format string
+
+	// Resume reuses the source files of a previous export in OutputDir instead of
+	// copying them again, provided manifest.json verifies their sizes and line counts.
+	// If verification fails, the files are copied again.
+	Resume bool
+
+	// MaxToolOutputBytes truncates successful tool output in the HTML beyond this many
+	// bytes; the copy button still copies the full output. 0 means no limit.
+	MaxToolOutputBytes int
+
+	// HideToolResults leaves the output of tool calls out of the HTML, for reading just
+	// the conversation when outputs are noisy logs. Each call keeps its header and input;
+	// the header marks calls that had a result, flagging errors. Stats still count every
+	// call.
+	HideToolResults bool
+
+	// GroupByTool also renders tools.html (see RenderByTool), listing the main session's
+	// tool calls grouped by tool, for analyzing tool usage; each call links back to its
+	// page of the conversation.
+	GroupByTool bool
+
+	// CollapseRepeats collapses repeated tool calls: a Read of a file already read
+	// earlier in the same conversation (the main session or one subagent) is rendered in
+	// a collapsed "re-read (Nx)" group linking to the first read, which shows in full
+	// with links to the re-reads.
+	CollapseRepeats bool
+
+	// ToolOutputLineNumbers numbers the lines of Read and Bash output in a gutter, each
+	// number linking to its line (e.g. #tool-result-<id>-L40). The numbers are drawn by
+	// CSS, so copying the output copies only its text.
+	ToolOutputLineNumbers bool
+
+	// Annotations maps entry UUIDs to reviewers' notes (see LoadAnnotations), shown below
+	// the annotated messages, which get a marker in their header. Messages rendered as
+	// inline markers (interruptions, API errors, slash commands) are not annotated.
+	Annotations map[string]Annotation
+
+	// CollapseCodeLines collapses fenced code blocks in assistant messages longer than
+	// this many lines behind an "N lines — click to expand" summary. The language badge
+	// and copy button stay visible, and copying still copies the whole block. 0 never
+	// collapses; the CLI defaults to DefaultCollapseCodeLines.
+	CollapseCodeLines int
+
+	// IncludePreamble shows the context entries a session starts with (system entries
+	// and the meta user entries Claude Code adds, before the first real message) in a
+	// collapsed "Session context" panel in the page header instead of leaving them out
+	// or in the conversation. The text is shown as recorded; there is no redaction.
+	IncludePreamble bool
+
+	// ShowFirstPrompt repeats the session's first prompt in full (see
+	// session.FirstPrompt) in a highlighted card in the page header, to recall at a
+	// glance what the session was about. Sessions whose user messages have no text get
+	// no card.
+	ShowFirstPrompt bool
+
+	// ShowFilesTouched adds a collapsed "Files modified" panel to the page header,
+	// listing each file the session's Write, Edit, MultiEdit, NotebookEdit and
+	// ApplyPatch calls changed with its number of edits and reads and a link to it,
+	// followed by the files it only read.
+	ShowFilesTouched bool
+
+	// SummaryMaxLen truncates inline tool summaries (tool headers and the tool-only
+	// message label) to this many characters. 0 means no truncation; the non-options
+	// render functions use DefaultSummaryMaxLen.
+	SummaryMaxLen int
+
+	// CombineToolMessages renders consecutive assistant entries that form one logical
+	// turn (each continuing the previous one's parent chain, possibly through tool
+	// results) in a single message bubble instead of separate "TOOL: X" bubbles.
+	CombineToolMessages bool
+
+	// GroupParallelTools shows the tool calls of an assistant entry that made two or
+	// more at once under one "Parallel tools (N)" header. Each call stays collapsible
+	// and keeps its own result. Entries with a single call are unchanged.
+	GroupParallelTools bool
+
+	// ShowLegend adds a legend to the page footer explaining the message colors (with
+	// the role names the messages use) and the tool-call and error styling. The legend
+	// is not printed.
+	ShowLegend bool
+
+	// Highlight pre-marks every occurrence of this literal term in message text with
+	// <mark class="export-highlight">. Markup (such as code blocks) is left intact.
+	Highlight string
+
+	// HighlightIgnoreCase matches Highlight case-insensitively.
+	HighlightIgnoreCase bool
+
+	// Locale formats the numbers and durations in the session statistics for a BCP 47
+	// locale (see SupportedLocales). Empty keeps the default English output; unsupported
+	// locales fall back to English.
+	Locale string
+
+	// ShowAll also renders the entries normally hidden for having no displayable
+	// content (empty or whitespace-only messages, tool-result-only user entries, bare
+	// system and queue entries), each as a faint one-line debug row.
+	ShowAll bool
+
+	// ToolIcons maps tool names to the icon shown before their tool call headers,
+	// adding to and overriding DefaultToolIcons. Tools with no icon get GenericToolIcon;
+	// map a tool to "" to show none.
+	ToolIcons map[string]string
+
+	// NoToolIcons omits the icons from tool call headers.
+	NoToolIcons bool
+
+	// MaxAgents renders subagent sections for only the MaxAgents agents with the most
+	// entries; the rest are listed by ID in a collapsible section at the end of the
+	// conversation (see OverflowAgents). Session statistics still count every agent.
+	// 0 means no limit.
+	MaxAgents int
+
+	// RenderResultMarkdown renders the successful results of the tools in
+	// MarkdownResultTools as markdown instead of preformatted text. Bash output always
+	// stays literal.
+	RenderResultMarkdown bool
+
+	// MarkdownResultTools names the tools whose results RenderResultMarkdown applies to.
+	// Empty means DefaultMarkdownResultTools.
+	MarkdownResultTools []string
+
+	// AutoExpandTools names the tools whose calls start expanded, showing their input and
+	// result, while other tools start collapsed as usual. Names match case-insensitively.
+	// The Expand All and Collapse All buttons still apply to every call.
+	AutoExpandTools []string
+
+	// ToolIndex, when set, supplies the tool calls and results of the rendered entries
+	// instead of parsing every message again (see NewToolIndex). nil parses as usual.
+	ToolIndex *ToolIndex
+
+	// DaySeparators inserts a date header (e.g. "February 1, 2026") before the first
+	// message of each day, when the session spans more than one day.
+	DaySeparators bool
+
+	// Location is the time zone that decides where one day ends for DaySeparators.
+	// nil means UTC.
+	Location *time.Location
+
+	// ShowGaps inserts a "⏱ 12m gap" marker between consecutive messages more than
+	// GapThreshold apart, showing where time was spent. Entries without a timestamp
+	// never get or end a gap.
+	ShowGaps bool
+
+	// GapThreshold is the shortest pause ShowGaps marks. 0 means DefaultGapThreshold.
+	GapThreshold time.Duration
+
+	// Sidebar adds a fixed sidebar listing the main session and every subagent, indented
+	// by nesting depth, as links that open and scroll to each section. The link of the
+	// section in view is highlighted; on narrow screens the list starts collapsed.
+	Sidebar bool
+
+	// IdleThreshold is the longest pause between entries that counts towards the active
+	// duration shown next to the session duration. 0 means DefaultIdleThreshold.
+	IdleThreshold time.Duration
+
+	// RawSource is the path, relative to the export root, of the JSONL file the rendered
+	// entries were read from (e.g. "source/session.jsonl"). When set, each message header
+	// links to its entry's line in that file (see models.ConversationEntry.SourceLine).
+	// Empty omits the links.
+	RawSource string
+
+	// DebugInspector adds a collapsed "🔧 raw" block to each message showing the entry's
+	// original JSON line, pretty-printed (see models.ConversationEntry.RawLine). It is
+	// meant for debugging the exporter: the raw JSON includes everything the entry
+	// recorded, such as full tool output, not just what the export renders.
+	DebugInspector bool
+
+	// PageSize splits the HTML export into pages of this many messages (page-1.html,
+	// page-2.html, ...) with previous/next links and an index.html listing them; see
+	// RenderConversationPages. Search on a page covers that page only. 0 writes one file.
+	PageSize int
+
+	// TemplateFile is an html/template file that replaces the built-in page layout
+	// (templates/layout.html). It is executed with a LayoutData; message bodies are
+	// still rendered by the exporter. Empty uses the built-in layout, which adds nothing
+	// around the header, conversation and footer, so the output is byte-identical to the
+	// page rendered without a layout. That page is not frozen across releases: the type
+	// filter checkboxes, jump buttons, copy-message buttons and share-context button
+	// added since are part of it by default.
+	TemplateFile string
+
+	// SearchIndex embeds an index of the words in each message, so the page's search
+	// only scans the messages that can match instead of every message. Common words are
+	// left out to bound its size; searches the index cannot narrow scan every message
+	// as they do without one. On a paged export each page indexes its own messages.
+	SearchIndex bool
+
+	// Avatars sets what the avatar beside the messages of each entry type shows:
+	// initials or an image (see Avatar). Types without an entry keep the built-in
+	// placeholder letter.
+	Avatars map[models.EntryType]Avatar
+
+	// TypeColors overrides the color of the messages of entry types, keyed by their
+	// CSS class (see TypeColorNames), e.g. {"system": "gray"}. Each color must pass
+	// ValidCSSColor; rendering fails otherwise (see ValidateTypeColors). Types without
+	// an entry keep the built-in colors.
+	TypeColors map[string]string
+
+	// ToolOutputEncoding is the encoding assumed for tool output bytes that are not
+	// valid UTF-8, such as Latin-1 output of a Bash command, e.g. "windows-1252" (see
+	// models.LookupEncoding). Those bytes are converted to UTF-8; valid UTF-8 is left
+	// as it is. Empty shows such bytes as U+FFFD replacement characters.
+	ToolOutputEncoding string
+
+	// EmojiShortcodes replaces :name: shortcodes in assistant messages, such as
+	// :rocket:, with their emoji. Only common shortcodes are known; others, and colons
+	// in code and URLs, are left as written.
+	EmojiShortcodes bool
+
+	// AllowSafeHTML renders <br>, <sub>, <sup> and <kbd> in assistant messages as HTML
+	// instead of escaping them. Only the bare tags pass, closed on the same line for
+	// all but <br>; any other HTML, and any tag with attributes, is still escaped.
+	AllowSafeHTML bool
+
+	// ReplayMode adds Play and Show All buttons to the page header for demos. Messages
+	// start hidden, and Play reveals them one at a time, ReplayDelay apart, as if the
+	// conversation were unfolding; Show All, or a search, reveals the rest at once.
+	// Other blocks, such as day separators, appear with the next message.
+	ReplayMode bool
+
+	// ReplayDelay is the pause between messages revealed by ReplayMode. 0 means
+	// DefaultReplayDelay.
+	ReplayDelay time.Duration
+
+	// WrapWidth hard-wraps the message text of text and markdown exports at this many
+	// columns, at word boundaries. Newlines already in the text are kept, and code,
+	// tables and words longer than the width, such as URLs, are never broken. 0 does
+	// not wrap.
+	WrapWidth int
+
+	// NoJS renders HTML that works without JavaScript, for archival or for browsers that
+	// block scripts: tool calls, subagent sections and the TodoWrite history collapse with
+	// native <details> elements, subagent conversations are inlined from AgentEntries
+	// instead of loaded on demand, and the page has no scripts, search, expand/collapse
+	// or copy controls. ReplayMode and SearchIndex are ignored.
+	NoJS bool
+
+	// AgentEntries holds the entries of each subagent by agent ID, rendered inline in
+	// their subagent sections when NoJS is set. Agents without entries get an empty
+	// section.
+	AgentEntries map[string][]models.ConversationEntry
+
+	// StripIDs replaces the session, agent, message and tool IDs of an HTML export with
+	// sequential labels such as sess-1, agent-2 and tool-3, everywhere they appear:
+	// anchors, data attributes, copy buttons and the CLI commands shown. A tool call and
+	// its result get the same label, so links between them still work.
+	StripIDs bool
+
+	// IDMap holds the labels StripIDs assigns. Share one IDMapper between the pages and
+	// agent fragments of an export so they agree on labels; nil uses a new IDMapper for
+	// each render.
+	IDMap *IDMapper
 }
 
 // ExportSession exports a session's JSONL files to the specified output directory.
@@ -53,9 +364,9 @@ type ExportOptions struct {
 // Supports session ID prefixes (like git) which are automatically resolved to full IDs.
 func ExportSession(projectPath, sessionID string, opts ExportOptions) (*ExportResult, error) {
 	// Resolve the project directory
-	projectDir, err := paths.ProjectDir(opts.ClaudeDir, projectPath)
+	projectDir, err := ProjectDir(projectPath, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve project directory: %w", err)
+		return nil, err
 	}
 
 	// Resolve session ID prefix to full ID (supports partial IDs like git)
@@ -64,10 +375,21 @@ func ExportSession(projectPath, sessionID string, opts ExportOptions) (*ExportRe
 		return nil, fmt.Errorf("failed to resolve session ID: %w", err)
 	}
 
+	format := strings.ToLower(strings.TrimSpace(opts.Format))
+	if format == "" {
+		format = "html"
+	}
+	var exporter Exporter
+	if format != "jsonl" {
+		if exporter, err = GetExporter(format); err != nil {
+			return nil, err
+		}
+	}
+
 	// Find the session
 	sess, err := session.FindSession(projectDir, resolvedSessionID)
 	if err != nil {
-		return nil, fmt.Errorf("session not found: %w", err)
+		return nil, fmt.Errorf("%w: %w", resolver.ErrSessionNotFound, err)
 	}
 
 	// Determine output directory
@@ -79,6 +401,19 @@ func ExportSession(projectPath, sessionID string, opts ExportOptions) (*ExportRe
 		}
 	}
 
+	// Reuse a previous export's source files if they verify against its manifest
+	var resumeWarning string
+	if opts.Resume {
+		result, err := resumeExport(outputDir, resolvedSessionID)
+		if err == nil {
+			result.ManifestFile = filepath.Join(outputDir, ManifestFileName)
+			recordPartialAgents(result)
+			setArtifactPaths(result, exporter)
+			return result, nil
+		}
+		resumeWarning = fmt.Sprintf("cannot resume, copying source files again: %v", err)
+	}
+
 	// Create output directory structure
 	sourceDir := filepath.Join(outputDir, "source")
 	agentsDir := filepath.Join(sourceDir, "agents")
@@ -93,10 +428,13 @@ func ExportSession(projectPath, sessionID string, opts ExportOptions) (*ExportRe
 		SourceDir:  sourceDir,
 		AgentFiles: make(map[string]string),
 	}
+	if resumeWarning != "" {
+		result.Errors = append(result.Errors, resumeWarning)
+	}
 
 	// Copy main session file
-	sessionFilePath := filepath.Join(projectDir, resolvedSessionID+".jsonl")
-	destSessionFile := filepath.Join(sourceDir, "session.jsonl")
+	sessionFilePath := paths.JSONLFile(filepath.Join(projectDir, resolvedSessionID))
+	destSessionFile := filepath.Join(sourceDir, "session"+paths.JSONLExtOf(sessionFilePath))
 	if err := copyFile(sessionFilePath, destSessionFile); err != nil {
 		return nil, fmt.Errorf("failed to copy session file: %w", err)
 	}
@@ -108,10 +446,34 @@ func ExportSession(projectPath, sessionID string, opts ExportOptions) (*ExportRe
 		// Non-fatal: add to errors but continue
 		result.Errors = append(result.Errors, fmt.Sprintf("error copying agent files: %v", err))
 	}
+	recordPartialAgents(result)
+
+	// Record the copies so an interrupted export can be resumed
+	if err := writeSourceManifest(projectDir, result); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	} else {
+		result.ManifestFile = filepath.Join(outputDir, ManifestFileName)
+	}
 
+	setArtifactPaths(result, exporter)
 	return result, nil
 }
 
+// setArtifactPaths sets the paths of the files rendered by exporter (nil for jsonl):
+// HTMLFile and, for html, StaticDir.
+func setArtifactPaths(result *ExportResult, exporter Exporter) {
+	_, isHTML := exporter.(HTMLExporter)
+	switch {
+	case exporter == nil:
+		result.HTMLFile = result.MainSessionFile
+	case isHTML:
+		result.HTMLFile = filepath.Join(result.OutputDir, PageIndexFileName)
+		result.StaticDir = filepath.Join(result.OutputDir, StaticDirName)
+	default:
+		result.HTMLFile = filepath.Join(result.OutputDir, "conversation"+exporter.Extension())
+	}
+}
+
 // generateTempPath creates a temp folder path with the session ID prefix and timestamp.
 // Format: {os.TempDir()}/claude-history/{sessionId-prefix-8chars}-{ISO-timestamp}/
 func generateTempPath(sessionID string, lastModified time.Time) (string, error) {
@@ -205,7 +567,7 @@ func copyAgentFilesRecursive(srcDir, destDir, parentPath string, result *ExportR
 					result.Errors = append(result.Errors, fmt.Sprintf("error copying nested agents from %s: %v", srcPath, err))
 				}
 			}
-		} else if strings.HasPrefix(entry.Name(), "agent-") && strings.HasSuffix(entry.Name(), ".jsonl") {
+		} else if base, ok := paths.TrimJSONLExt(entry.Name()); ok && strings.HasPrefix(base, "agent-") {
 			// This is an agent JSONL file
 			destPath := filepath.Join(destDir, entry.Name())
 			if parentPath != "" {
@@ -217,8 +579,8 @@ func copyAgentFilesRecursive(srcDir, destDir, parentPath string, result *ExportR
 				continue
 			}
 
-			// Extract agent ID from filename: agent-{id}.jsonl -> {id}
-			agentID := strings.TrimPrefix(strings.TrimSuffix(entry.Name(), ".jsonl"), "agent-")
+			// Extract agent ID from filename: agent-{id}.jsonl[.zst] -> {id}
+			agentID := strings.TrimPrefix(base, "agent-")
 			result.AgentFiles[agentID] = destPath
 			result.TotalAgents++
 		}
@@ -227,6 +589,27 @@ func copyAgentFilesRecursive(srcDir, destDir, parentPath string, result *ExportR
 	return nil
 }
 
+// recordPartialAgents adds an error to result for each exported agent file with lines
+// that could not be parsed. Such agents are still rendered, from the entries that could.
+func recordPartialAgents(result *ExportResult) {
+	agentIDs := make([]string, 0, len(result.AgentFiles))
+	for agentID := range result.AgentFiles {
+		agentIDs = append(agentIDs, agentID)
+	}
+	sort.Strings(agentIDs)
+	for _, agentID := range agentIDs {
+		if _, parseErrors, err := agent.ReadAgentFile(result.AgentFiles[agentID]); err == nil && parseErrors > 0 {
+			result.Errors = append(result.Errors, partialAgentError(agentID, parseErrors))
+		}
+	}
+}
+
+// partialAgentError is the ExportResult error for an agent loaded without the lines of
+// its file that could not be parsed.
+func partialAgentError(agentID string, parseErrors int) string {
+	return fmt.Sprintf("agent %s partially loaded: %d line(s) could not be parsed", agentID, parseErrors)
+}
+
 // GetExportTreeInfo builds tree info for an export, useful for manifest generation.
 // This is a convenience wrapper around agent.BuildNestedTree.
 func GetExportTreeInfo(projectDir, sessionID string) (*agent.TreeNode, error) {
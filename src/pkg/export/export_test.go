@@ -1,12 +1,15 @@
 package export
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/randlee/claude-history/pkg/resolver"
 )
 
 // Helper to create a test session structure
@@ -340,6 +343,64 @@ func TestExportSession_Success(t *testing.T) {
 	}
 }
 
+func TestExportSession_ArtifactPaths(t *testing.T) {
+	tempDir := t.TempDir()
+	_, sessionID := setupTestSession(t, tempDir)
+
+	tests := []struct {
+		format    string
+		htmlFile  string
+		staticDir string
+	}{
+		{"", "index.html", "static"},
+		{"html", "index.html", "static"},
+		{"markdown", "conversation.md", ""},
+		{"ipynb", "conversation.ipynb", ""},
+		{"jsonl", filepath.Join("source", "session.jsonl"), ""},
+	}
+	for _, tt := range tests {
+		outputDir := filepath.Join(tempDir, "out-"+tt.format)
+		result, err := ExportSession("/test/project", sessionID, ExportOptions{OutputDir: outputDir, ClaudeDir: tempDir, Format: tt.format})
+		if err != nil {
+			t.Fatalf("ExportSession(%q) error = %v", tt.format, err)
+		}
+
+		if want := filepath.Join(outputDir, tt.htmlFile); result.HTMLFile != want {
+			t.Errorf("ExportSession(%q) HTMLFile = %q, want %q", tt.format, result.HTMLFile, want)
+		}
+		wantStatic := ""
+		if tt.staticDir != "" {
+			wantStatic = filepath.Join(outputDir, tt.staticDir)
+		}
+		if result.StaticDir != wantStatic {
+			t.Errorf("ExportSession(%q) StaticDir = %q, want %q", tt.format, result.StaticDir, wantStatic)
+		}
+		if _, err := os.Stat(result.ManifestFile); err != nil || result.ManifestFile != filepath.Join(outputDir, ManifestFileName) {
+			t.Errorf("ExportSession(%q) ManifestFile = %q (%v)", tt.format, result.ManifestFile, err)
+		}
+	}
+
+	// A resumed export reports the same paths
+	outputDir := filepath.Join(tempDir, "out-markdown")
+	result, err := ExportSession("/test/project", sessionID, ExportOptions{OutputDir: outputDir, ClaudeDir: tempDir, Format: "markdown", Resume: true})
+	if err != nil || !result.Resumed {
+		t.Fatalf("ExportSession(resume) = %+v, %v", result, err)
+	}
+	if result.HTMLFile != filepath.Join(outputDir, "conversation.md") || result.ManifestFile == "" {
+		t.Errorf("resumed export paths = %q, %q", result.HTMLFile, result.ManifestFile)
+	}
+}
+
+func TestExportSession_UnknownFormat(t *testing.T) {
+	tempDir := t.TempDir()
+	_, sessionID := setupTestSession(t, tempDir)
+
+	_, err := ExportSession("/test/project", sessionID, ExportOptions{OutputDir: filepath.Join(tempDir, "out"), ClaudeDir: tempDir, Format: "pdf"})
+	if err == nil || !strings.Contains(err.Error(), "unknown export format: pdf") {
+		t.Errorf("ExportSession() error = %v, want unknown format", err)
+	}
+}
+
 func TestExportSession_WithTempDir(t *testing.T) {
 	tempDir := t.TempDir()
 	setupTestSession(t, tempDir)
@@ -436,6 +497,9 @@ func TestExportSession_SessionNotFound(t *testing.T) {
 	if !strings.Contains(errorMsg, "not found") && !strings.Contains(errorMsg, "no sessions found") {
 		t.Errorf("ExportSession() error = %v, should contain 'not found' or 'no sessions found'", err)
 	}
+	if !errors.Is(err, resolver.ErrSessionNotFound) {
+		t.Errorf("ExportSession() error = %v, should match resolver.ErrSessionNotFound", err)
+	}
 }
 
 func TestExportSession_NoAgents(t *testing.T) {
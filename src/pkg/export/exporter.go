@@ -0,0 +1,207 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// Exporter renders a conversation into a single output document.
+// Implementations are registered by format name and selected by the export command.
+type Exporter interface {
+	// Render produces the document for the given entries, agent hierarchy, and stats.
+	// stats may be nil, in which case implementations compute them from entries/agents.
+	Render(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats) ([]byte, error)
+
+	// Extension returns the file extension for the output, including the leading dot (e.g., ".html").
+	Extension() string
+}
+
+var (
+	exportersMu sync.RWMutex
+	exporters   = map[string]Exporter{
+		"html":     HTMLExporter{Options: ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen}},
+		"markdown": MarkdownExporter{},
+		"json":     JSONExporter{},
+		"text":     TextExporter{},
+		"csv":      CSVExporter{},
+		"ipynb":    NotebookExporter{},
+		"vtt":      VTTExporter{},
+	}
+)
+
+// RegisterExporter makes an exporter available under the given format name.
+// Format names are case-insensitive. Returns an error if the name is empty,
+// the exporter is nil, or the name is already registered.
+func RegisterExporter(name string, e Exporter) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return fmt.Errorf("exporter name cannot be empty")
+	}
+	if e == nil {
+		return fmt.Errorf("exporter %q cannot be nil", name)
+	}
+
+	exportersMu.Lock()
+	defer exportersMu.Unlock()
+
+	if _, exists := exporters[name]; exists {
+		return fmt.Errorf("exporter %q is already registered", name)
+	}
+	exporters[name] = e
+	return nil
+}
+
+// GetExporter returns the exporter registered under the given format name.
+func GetExporter(name string) (Exporter, error) {
+	exportersMu.RLock()
+	defer exportersMu.RUnlock()
+
+	e, ok := exporters[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return nil, fmt.Errorf("unknown export format: %s (valid: %s)", name, strings.Join(exporterNamesLocked(), ", "))
+	}
+	return e, nil
+}
+
+// ExporterNames returns the registered format names in sorted order.
+func ExporterNames() []string {
+	exportersMu.RLock()
+	defer exportersMu.RUnlock()
+	return exporterNamesLocked()
+}
+
+// exporterNamesLocked returns sorted exporter names. Caller must hold exportersMu.
+func exporterNamesLocked() []string {
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HTMLExporter renders the main conversation page via RenderConversationWithOptions.
+type HTMLExporter struct {
+	Options ExportOptions // Rendering settings (SummaryMaxLen: DefaultSummaryMaxLen matches RenderConversationWithStats)
+}
+
+// Render implements Exporter.
+func (e HTMLExporter) Render(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats) ([]byte, error) {
+	html, err := RenderConversationWithOptions(entries, agents, stats, e.Options)
+	if err != nil {
+		return nil, err
+	}
+	if e.Options.Minify {
+		html = minifyHTML(html)
+	}
+	return []byte(html), nil
+}
+
+// RenderPages renders the files of the main conversation: index.html alone, or with
+// Options.PageSize set, an index plus one file per page (see RenderConversationPages).
+func (e HTMLExporter) RenderPages(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats) ([]RenderedPage, error) {
+	pages, err := RenderConversationPages(entries, agents, stats, e.Options)
+	if err != nil {
+		return nil, err
+	}
+	if e.Options.Minify {
+		for i := range pages {
+			pages[i].HTML = minifyHTML(pages[i].HTML)
+		}
+	}
+	return pages, nil
+}
+
+// Extension implements Exporter.
+func (HTMLExporter) Extension() string { return ".html" }
+
+// MarkdownExporter renders the conversation as a markdown document.
+type MarkdownExporter struct {
+	NoStats     bool   // Omit the trailing session statistics block
+	Locale      string // Locale for stats numbers and durations (see ExportOptions.Locale)
+	WrapWidth   int    // Column to wrap message text at (see ExportOptions.WrapWidth)
+	FrontMatter bool   // Start with YAML front matter for static-site generators (see renderFrontMatter)
+}
+
+// Render implements Exporter.
+func (e MarkdownExporter) Render(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats) ([]byte, error) {
+	if stats == nil {
+		stats = ComputeSessionStats(entries, agents)
+	}
+	md, err := renderConversationMarkdown(entries, agents, stats, !e.NoStats, newLocalizer(e.Locale), e.WrapWidth)
+	if err != nil {
+		return nil, err
+	}
+	if e.FrontMatter {
+		md = renderFrontMatter(stats) + md
+	}
+	return []byte(md), nil
+}
+
+// Extension implements Exporter.
+func (MarkdownExporter) Extension() string { return ".md" }
+
+// JSONExporter renders the conversation as a structured JSON document.
+type JSONExporter struct {
+	Fields []string // Optional entry keys to include (nil includes all)
+}
+
+// Render implements Exporter.
+func (e JSONExporter) Render(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats) ([]byte, error) {
+	return RenderConversationJSON(entries, agents, stats, e.Fields)
+}
+
+// Extension implements Exporter.
+func (JSONExporter) Extension() string { return ".json" }
+
+// TextExporter renders the conversation as plain text.
+type TextExporter struct {
+	NoStats   bool   // Omit the trailing session statistics block
+	Locale    string // Locale for stats numbers and durations (see ExportOptions.Locale)
+	WrapWidth int    // Column to wrap message text at (see ExportOptions.WrapWidth)
+}
+
+// Render implements Exporter.
+func (e TextExporter) Render(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats) ([]byte, error) {
+	text, err := renderConversationText(entries, agents, stats, !e.NoStats, newLocalizer(e.Locale), e.WrapWidth)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(text), nil
+}
+
+// Extension implements Exporter.
+func (TextExporter) Extension() string { return ".txt" }
+
+// CSVExporter renders the session's tool calls as CSV rows.
+type CSVExporter struct {
+	Fields []string // Optional columns in output order (nil uses the default columns)
+}
+
+// Render implements Exporter.
+func (e CSVExporter) Render(entries []models.ConversationEntry, _ []*agent.TreeNode, _ *SessionStats) ([]byte, error) {
+	return RenderToolCallsCSV(entries, e.Fields)
+}
+
+// Extension implements Exporter.
+func (CSVExporter) Extension() string { return ".csv" }
+
+// VTTExporter renders the conversation as a WebVTT timed transcript.
+type VTTExporter struct{}
+
+// Render implements Exporter.
+func (VTTExporter) Render(entries []models.ConversationEntry, _ []*agent.TreeNode, _ *SessionStats) ([]byte, error) {
+	vtt, err := RenderConversationVTT(entries)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(vtt), nil
+}
+
+// Extension implements Exporter.
+func (VTTExporter) Extension() string { return ".vtt" }
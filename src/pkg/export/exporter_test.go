@@ -0,0 +1,143 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// exporterTestEntries returns a small conversation with text, a tool call, and its result.
+func exporterTestEntries() []models.ConversationEntry {
+	return []models.ConversationEntry{
+		{
+			UUID:      "u1",
+			SessionID: "session-1",
+			Type:      models.EntryTypeUser,
+			Timestamp: "2026-01-01T10:00:00Z",
+			Message:   json.RawMessage(`"List the files"`),
+		},
+		{
+			UUID:      "a1",
+			SessionID: "session-1",
+			Type:      models.EntryTypeAssistant,
+			Timestamp: "2026-01-01T10:00:05Z",
+			Message:   json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Listing **now**."},{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"ls -la"}}]}`),
+		},
+		{
+			UUID:      "u2",
+			SessionID: "session-1",
+			Type:      models.EntryTypeUser,
+			Timestamp: "2026-01-01T10:00:06Z",
+			Message:   json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"main.go\ngo.mod"}]}`),
+		},
+	}
+}
+
+type stubExporter struct{}
+
+func (stubExporter) Render(entries []models.ConversationEntry, _ []*agent.TreeNode, _ *SessionStats) ([]byte, error) {
+	return []byte("stub"), nil
+}
+
+func (stubExporter) Extension() string { return ".stub" }
+
+func TestGetExporter_BuiltIns(t *testing.T) {
+	tests := []struct {
+		name string
+		ext  string
+	}{
+		{"html", ".html"},
+		{"markdown", ".md"},
+		{"json", ".json"},
+		{"text", ".txt"},
+		{"csv", ".csv"},
+		{"ipynb", ".ipynb"},
+		{"vtt", ".vtt"},
+		{"HTML", ".html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := GetExporter(tt.name)
+			if err != nil {
+				t.Fatalf("GetExporter(%q) error = %v", tt.name, err)
+			}
+			if e.Extension() != tt.ext {
+				t.Errorf("Extension() = %q, want %q", e.Extension(), tt.ext)
+			}
+		})
+	}
+}
+
+func TestGetExporter_Unknown(t *testing.T) {
+	_, err := GetExporter("pdf")
+	if err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+	if !strings.Contains(err.Error(), "html") || !strings.Contains(err.Error(), "markdown") {
+		t.Errorf("error should list valid formats, got: %v", err)
+	}
+}
+
+func TestRegisterExporter_Custom(t *testing.T) {
+	if err := RegisterExporter("stub-test", stubExporter{}); err != nil {
+		t.Fatalf("RegisterExporter() error = %v", err)
+	}
+	defer func() {
+		exportersMu.Lock()
+		delete(exporters, "stub-test")
+		exportersMu.Unlock()
+	}()
+
+	e, err := GetExporter("stub-test")
+	if err != nil {
+		t.Fatalf("GetExporter() error = %v", err)
+	}
+	out, err := e.Render(nil, nil, nil)
+	if err != nil || string(out) != "stub" {
+		t.Errorf("Render() = %q, %v; want \"stub\", nil", out, err)
+	}
+
+	found := false
+	for _, name := range ExporterNames() {
+		if name == "stub-test" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("ExporterNames() should include registered exporter")
+	}
+}
+
+func TestRegisterExporter_Errors(t *testing.T) {
+	if err := RegisterExporter("", stubExporter{}); err == nil {
+		t.Error("expected error for empty name")
+	}
+	if err := RegisterExporter("nil-exporter", nil); err == nil {
+		t.Error("expected error for nil exporter")
+	}
+	if err := RegisterExporter("html", stubExporter{}); err == nil {
+		t.Error("expected error for duplicate name")
+	}
+}
+
+func TestHTMLExporter_MatchesRenderConversationWithStats(t *testing.T) {
+	entries := exporterTestEntries()
+	stats := &SessionStats{SessionID: "session-1", ExportTime: "fixed"}
+
+	want, err := RenderConversationWithStats(entries, nil, stats)
+	if err != nil {
+		t.Fatalf("RenderConversationWithStats() error = %v", err)
+	}
+	got, err := HTMLExporter{}.Render(entries, nil, stats)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !bytes.Equal(got, []byte(want)) {
+		t.Error("HTMLExporter output should match RenderConversationWithStats")
+	}
+}
@@ -0,0 +1,60 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRenderSubagentPlaceholder_FailedSpawn(t *testing.T) {
+	agentMap := map[string]int{"abc1234": 2}
+
+	html := renderSubagentPlaceholderWith("abc1234", agentMap, "s1", "", subagentDetails{spawnStatus: "failed"}, entryRenderOptions{})
+	for _, want := range []string{
+		`<div class="subagent spawn-failed collapsible collapsed"`,
+		`<span class="subagent-spawn-failed" title="The spawn of this agent ended with status: failed">✗ spawn failed</span>`,
+		`(2 entries)`,
+		`onclick="loadAgent(this)"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("failed spawn with a transcript missing %q:\n%s", want, html)
+		}
+	}
+
+	// Without a transcript there is nothing to load
+	html = renderSubagentPlaceholderWith("gone567", agentMap, "s1", "", subagentDetails{spawnStatus: "error"}, entryRenderOptions{})
+	if !strings.Contains(html, `<div class="subagent spawn-failed" id="agent-gone567"`) || !strings.Contains(html, "✗ spawn failed") ||
+		!strings.Contains(html, "(no transcript)") {
+		t.Errorf("failed spawn without a transcript should render a marker:\n%s", html)
+	}
+	if strings.Contains(html, "loadAgent") || strings.Contains(html, "chevron") {
+		t.Errorf("failed spawn without a transcript should not be expandable:\n%s", html)
+	}
+
+	// Successful spawns are unchanged
+	if got, want := renderSubagentPlaceholderWith("abc1234", agentMap, "s1", "", subagentDetails{}, entryRenderOptions{}),
+		renderSubagentPlaceholder("abc1234", agentMap, "s1", ""); got != want || strings.Contains(got, "spawn-failed") {
+		t.Errorf("successful spawn rendered differently:\n%s", got)
+	}
+}
+
+func TestBuildFailedSpawns(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, ToolUseResult: &models.ToolUseResult{Status: "failed", AgentID: "no-file"}},
+		{UUID: "u2", Type: models.EntryTypeUser, ToolUseResult: &models.ToolUseResult{Status: "async_launched", AgentID: "ok"}},
+	}
+	agents := []*agent.TreeNode{
+		{AgentID: "ok"},
+		{AgentID: "parent", Children: []*agent.TreeNode{{AgentID: "nested", SpawnStatus: "error"}}},
+	}
+
+	got := buildFailedSpawns(entries, agents)
+	if len(got) != 2 || got["no-file"] != "failed" || got["nested"] != "error" {
+		t.Errorf("buildFailedSpawns() = %v", got)
+	}
+	if got := buildFailedSpawns(entries[1:], agents[:1]); got != nil {
+		t.Errorf("buildFailedSpawns() = %v, want nil when every spawn succeeded", got)
+	}
+}
@@ -0,0 +1,66 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/session"
+)
+
+// renderFilesTouched renders the collapsed "Files modified" panel of the page header
+// (see ExportOptions.ShowFilesTouched): the files the session changed, each linked with
+// its number of edits and reads, then the files it only read. Returns "" if the session
+// touched no files.
+func renderFilesTouched(files []session.FileActivity) string {
+	if len(files) == 0 {
+		return ""
+	}
+	var modified, readOnly []session.FileActivity
+	for _, file := range files {
+		if file.Writes > 0 {
+			modified = append(modified, file)
+		} else {
+			readOnly = append(readOnly, file)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`    <details class="files-touched">` + "\n")
+	sb.WriteString(fmt.Sprintf(`        <summary>Files modified <span class="files-touched-count">(%d modified, %d read only)</span></summary>`+"\n",
+		len(modified), len(readOnly)))
+	if len(modified) > 0 {
+		sb.WriteString(renderFileActivityList("modified", modified))
+	}
+	if len(readOnly) > 0 {
+		sb.WriteString(`        <div class="files-touched-label">Read only</div>` + "\n")
+		sb.WriteString(renderFileActivityList("read-only", readOnly))
+	}
+	sb.WriteString("    </details>\n")
+	return sb.String()
+}
+
+// renderFileActivityList renders files as a list of links to them with their counts.
+func renderFileActivityList(kind string, files []session.FileActivity) string {
+	count := func(n int, noun string) string {
+		if n == 1 {
+			return "1 " + noun
+		}
+		return fmt.Sprintf("%d %ss", n, noun)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`        <ul class="files-touched-list %s">`+"\n", kind))
+	for _, file := range files {
+		var counts []string
+		if file.Writes > 0 {
+			counts = append(counts, count(file.Writes, "edit"))
+		}
+		if file.Reads > 0 {
+			counts = append(counts, count(file.Reads, "read"))
+		}
+		sb.WriteString(fmt.Sprintf(`            <li><a href="%s" class="file-link" title="%s">%s</a> <span class="file-activity">%s</span></li>`+"\n",
+			escapeHTML(buildFileURL(file.Path)), escapeHTML(file.Path), escapeHTML(file.Path), strings.Join(counts, ", ")))
+	}
+	sb.WriteString("        </ul>\n")
+	return sb.String()
+}
@@ -0,0 +1,53 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/session"
+)
+
+func TestRenderFilesTouched(t *testing.T) {
+	if got := renderFilesTouched(nil); got != "" {
+		t.Errorf("renderFilesTouched(nil) = %q, want empty", got)
+	}
+
+	html := renderFilesTouched([]session.FileActivity{
+		{Path: "/README.md", Reads: 2},
+		{Path: "/src/main.go", Reads: 1, Writes: 3},
+		{Path: "/src/a&b.go", Writes: 1},
+	})
+	for _, want := range []string{
+		`<summary>Files modified <span class="files-touched-count">(2 modified, 1 read only)</span></summary>`,
+		`<li><a href="file:///src/main.go" class="file-link" title="/src/main.go">/src/main.go</a> <span class="file-activity">3 edits, 1 read</span></li>`,
+		`<a href="file:///src/a&amp;b.go" class="file-link" title="/src/a&amp;b.go">/src/a&amp;b.go</a> <span class="file-activity">1 edit</span>`,
+		`<div class="files-touched-label">Read only</div>`,
+		`/README.md</a> <span class="file-activity">2 reads</span>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("panel missing %q:\n%s", want, html)
+		}
+	}
+	if strings.Index(html, "/src/main.go") > strings.Index(html, "/README.md") {
+		t.Error("files only read should follow the modified files")
+	}
+}
+
+func TestRenderConversationWithOptions_ShowFilesTouched(t *testing.T) {
+	entries := rereadEntries()
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{ShowFilesTouched: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, `(0 modified, 2 read only)`) {
+		t.Error("header should list the files the session read")
+	}
+
+	plain, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(plain, `class="files-touched"`) {
+		t.Error("the files panel should be off by default")
+	}
+}
@@ -0,0 +1,112 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// csvToolCallHeader is the default column order for RenderToolCallsCSV.
+var csvToolCallHeader = []string{"uuid", "timestamp", "agent_id", "tool", "tool_id", "summary", "is_error", "output_bytes"}
+
+// csvToolCallRow holds the data for a single tool call row.
+type csvToolCallRow struct {
+	entry     models.ConversationEntry
+	tool      models.ToolUse
+	result    models.ToolResult
+	hasResult bool
+}
+
+// csvToolCallFields is the allow-list of columns that can be selected with --fields.
+var csvToolCallFields = map[string]func(csvToolCallRow) string{
+	"uuid":      func(r csvToolCallRow) string { return r.entry.UUID },
+	"type":      func(r csvToolCallRow) string { return string(r.entry.Type) },
+	"timestamp": func(r csvToolCallRow) string { return r.entry.Timestamp },
+	"agent_id":  func(r csvToolCallRow) string { return r.entry.AgentID },
+	"tool":      func(r csvToolCallRow) string { return r.tool.Name },
+	"tool_id":   func(r csvToolCallRow) string { return r.tool.ID },
+	"summary":   func(r csvToolCallRow) string { return extractToolDisplayValue(r.tool.Name, r.tool.Input) },
+	"input":     func(r csvToolCallRow) string { return formatToolInput(r.tool.Input) },
+	"is_error": func(r csvToolCallRow) string {
+		if !r.hasResult {
+			return ""
+		}
+		return strconv.FormatBool(r.result.IsError)
+	},
+	"output_bytes": func(r csvToolCallRow) string {
+		if !r.hasResult {
+			return ""
+		}
+		return strconv.Itoa(len(r.result.Content))
+	},
+}
+
+// RenderToolCallsCSV generates a CSV document with one row per tool call.
+// Rows are emitted in conversation order; is_error and output_bytes are empty when no result was recorded.
+// fields optionally selects and orders the columns (nil uses the default columns).
+func RenderToolCallsCSV(entries []models.ConversationEntry, fields []string) ([]byte, error) {
+	if err := ValidateCSVFields(fields); err != nil {
+		return nil, err
+	}
+	columns := fields
+	if len(columns) == 0 {
+		columns = csvToolCallHeader
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(columns); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	toolResults := buildToolResultsMap(entries)
+	for _, entry := range entries {
+		if entry.Type != models.EntryTypeAssistant {
+			continue
+		}
+		for _, tool := range entry.ExtractToolCalls() {
+			result, hasResult := toolResults[tool.ID]
+			r := csvToolCallRow{entry: entry, tool: tool, result: result, hasResult: hasResult}
+
+			record := make([]string, len(columns))
+			for i, column := range columns {
+				record[i] = csvToolCallFields[column](r)
+			}
+			if err := w.Write(record); err != nil {
+				return nil, fmt.Errorf("failed to write CSV row: %w", err)
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ValidateCSVFields checks that every requested field is a known CSV column.
+func ValidateCSVFields(fields []string) error {
+	for _, field := range fields {
+		if _, ok := csvToolCallFields[field]; !ok {
+			return fmt.Errorf("unknown field %q (valid fields: %s)", field, strings.Join(CSVFieldNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// CSVFieldNames returns the selectable CSV columns in sorted order.
+func CSVFieldNames() []string {
+	names := make([]string, 0, len(csvToolCallFields))
+	for name := range csvToolCallFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
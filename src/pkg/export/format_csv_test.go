@@ -0,0 +1,72 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestRenderToolCallsCSV(t *testing.T) {
+	data, err := RenderToolCallsCSV(exporterTestEntries(), nil)
+	if err != nil {
+		t.Fatalf("RenderToolCallsCSV() error = %v", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("len(records) = %d, want header + 1 row", len(records))
+	}
+
+	want := []string{"a1", "2026-01-01T10:00:05Z", "", "Bash", "toolu_1", "ls -la", "false", "14"}
+	for i, v := range want {
+		if records[1][i] != v {
+			t.Errorf("column %s = %q, want %q", records[0][i], records[1][i], v)
+		}
+	}
+}
+
+func TestRenderToolCallsCSV_NoToolCalls(t *testing.T) {
+	data, err := RenderToolCallsCSV(nil, nil)
+	if err != nil {
+		t.Fatalf("RenderToolCallsCSV() error = %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if len(records) != 1 {
+		t.Errorf("expected header only, got %d records", len(records))
+	}
+}
+
+func TestRenderToolCallsCSV_FieldsOrder(t *testing.T) {
+	data, err := RenderToolCallsCSV(exporterTestEntries(), []string{"tool", "type", "uuid"})
+	if err != nil {
+		t.Fatalf("RenderToolCallsCSV() error = %v", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if strings.Join(records[0], ",") != "tool,type,uuid" {
+		t.Errorf("header = %v, want requested order", records[0])
+	}
+	if strings.Join(records[1], ",") != "Bash,assistant,a1" {
+		t.Errorf("row = %v, want [Bash assistant a1]", records[1])
+	}
+}
+
+func TestRenderToolCallsCSV_UnknownField(t *testing.T) {
+	_, err := RenderToolCallsCSV(exporterTestEntries(), []string{"nope"})
+	if err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+	if !strings.Contains(err.Error(), "valid fields: agent_id") {
+		t.Errorf("error should list valid fields, got: %v", err)
+	}
+}
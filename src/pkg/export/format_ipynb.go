@@ -0,0 +1,215 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// Notebook format version written by RenderConversationNotebook. nbformat 4.4 cells
+// carry no IDs, which keeps the output stable across exports of the same session.
+const (
+	notebookFormat      = 4
+	notebookFormatMinor = 4
+)
+
+// notebook is a Jupyter notebook document (nbformat 4).
+type notebook struct {
+	Cells         []notebookCell `json:"cells"`
+	Metadata      map[string]any `json:"metadata"`
+	NBFormat      int            `json:"nbformat"`
+	NBFormatMinor int            `json:"nbformat_minor"`
+}
+
+// notebookCell is a markdown, raw, or code cell. Only code cells have an execution
+// count (always null) and outputs (always empty): the conversation is not executed.
+type notebookCell struct {
+	CellType       string          `json:"cell_type"`
+	Metadata       map[string]any  `json:"metadata"`
+	Source         []string        `json:"source"`
+	ExecutionCount json.RawMessage `json:"execution_count,omitempty"`
+	Outputs        *[]any          `json:"outputs,omitempty"`
+}
+
+// RenderConversationNotebook generates a Jupyter notebook (.ipynb) for a conversation.
+// Each message becomes a markdown cell headed by its role and time; fenced code blocks
+// in assistant text (see ExtractCodeBlocks) become code cells, with their language in
+// the cell metadata. Tool calls become markdown cells showing the input, followed by a
+// raw cell holding the tool output.
+func RenderConversationNotebook(entries []models.ConversationEntry) ([]byte, error) {
+	toolResults := buildToolResultsMap(entries)
+
+	var cells []notebookCell
+	sessionID := ""
+	for _, entry := range entries {
+		if sessionID == "" {
+			sessionID = entry.SessionID
+		}
+		if hasContent(entry) {
+			cells = append(cells, notebookEntryCells(entry, toolResults)...)
+		}
+
+		// Note subagent spawns inline
+		if entry.Type == models.EntryTypeQueueOperation && entry.AgentID != "" {
+			cells = append(cells, markdownCell(fmt.Sprintf("> Subagent `%s` spawned", entry.AgentID)))
+		}
+	}
+
+	metadata := map[string]any{}
+	if sessionID != "" {
+		metadata["claude_history"] = map[string]any{"session_id": sessionID}
+	}
+
+	nb := notebook{
+		Cells:         cells,
+		Metadata:      metadata,
+		NBFormat:      notebookFormat,
+		NBFormatMinor: notebookFormatMinor,
+	}
+	if nb.Cells == nil {
+		nb.Cells = []notebookCell{}
+	}
+
+	data, err := json.MarshalIndent(nb, "", " ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notebook: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// notebookEntryCells renders one conversation entry as notebook cells.
+func notebookEntryCells(entry models.ConversationEntry, toolResults map[string]models.ToolResult) []notebookCell {
+	var cells []notebookCell
+
+	heading := getRoleLabel(entry.Type, "User", "Assistant")
+	if entry.AgentID != "" {
+		heading += fmt.Sprintf(" (agent %s)", truncateID(entry.AgentID, 8))
+	}
+	if ts := formatTimestampReadable(entry.Timestamp); ts != "" {
+		heading += " · " + ts
+	}
+
+	// Prose goes to markdown cells; the heading joins the first of them
+	pending := "## " + heading
+	flush := func() {
+		if strings.TrimSpace(pending) != "" {
+			cells = append(cells, markdownCell(pending))
+		}
+		pending = ""
+	}
+	addProse := func(text string) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return
+		}
+		if pending != "" {
+			pending += "\n\n"
+		}
+		pending += text
+	}
+
+	text := entry.GetTextContent()
+	if entry.Type == models.EntryTypeAssistant {
+		pos := 0
+		for _, block := range ExtractCodeBlocks(text) {
+			addProse(text[pos:block.StartPos])
+			flush()
+			cells = append(cells, codeCell(block))
+			pos = block.EndPos
+		}
+		addProse(text[pos:])
+	} else {
+		addProse(text)
+	}
+	flush()
+
+	if entry.Type == models.EntryTypeAssistant {
+		for _, tool := range entry.ExtractToolCalls() {
+			result, hasResult := toolResults[tool.ID]
+			cells = append(cells, notebookToolCells(tool, result, hasResult)...)
+		}
+	}
+
+	return cells
+}
+
+// notebookToolCells renders a tool call as a markdown cell with its input, followed
+// by a raw cell with its output when there is any.
+func notebookToolCells(tool models.ToolUse, result models.ToolResult, hasResult bool) []notebookCell {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("**Tool:** `%s`", tool.Name))
+	if display := extractToolDisplayValue(tool.Name, tool.Input); display != "" {
+		sb.WriteString(" — " + strings.ReplaceAll(display, "\n", " "))
+	}
+	sb.WriteString("\n\n")
+	sb.WriteString(fencedBlock("json", formatToolInput(tool.Input)))
+
+	hasOutput := hasResult && strings.TrimSpace(result.Content) != ""
+	if hasOutput {
+		if result.IsError {
+			sb.WriteString("Error:")
+		} else {
+			sb.WriteString("Output:")
+		}
+	}
+
+	cells := []notebookCell{markdownCell(strings.TrimSpace(sb.String()))}
+	if hasOutput {
+		cells = append(cells, notebookCell{
+			CellType: "raw",
+			Metadata: map[string]any{"claude_history": map[string]any{"tool_use_id": tool.ID, "is_error": result.IsError}},
+			Source:   notebookSource(result.Content),
+		})
+	}
+	return cells
+}
+
+// markdownCell returns a markdown cell with the given source.
+func markdownCell(source string) notebookCell {
+	return notebookCell{CellType: "markdown", Metadata: map[string]any{}, Source: notebookSource(source)}
+}
+
+// codeCell returns an unexecuted code cell for block, noting its language in the
+// cell metadata when it has one.
+func codeCell(block CodeBlock) notebookCell {
+	metadata := map[string]any{}
+	if block.Language != "" {
+		metadata["language"] = block.Language
+	}
+	outputs := []any{}
+	return notebookCell{
+		CellType:       "code",
+		Metadata:       metadata,
+		Source:         notebookSource(block.Code),
+		ExecutionCount: json.RawMessage("null"),
+		Outputs:        &outputs,
+	}
+}
+
+// notebookSource splits text into the line list notebooks store cell sources as: each
+// line keeps its trailing newline except the last.
+func notebookSource(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if lines == nil {
+		return []string{}
+	}
+	return lines
+}
+
+// NotebookExporter renders the conversation as a Jupyter notebook via
+// RenderConversationNotebook.
+type NotebookExporter struct{}
+
+// Render implements Exporter.
+func (NotebookExporter) Render(entries []models.ConversationEntry, _ []*agent.TreeNode, _ *SessionStats) ([]byte, error) {
+	return RenderConversationNotebook(entries)
+}
+
+// Extension implements Exporter.
+func (NotebookExporter) Extension() string { return ".ipynb" }
@@ -0,0 +1,212 @@
+package export
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// notebookTestEntries returns a user prompt, an assistant reply with prose around a Go
+// code block, a Bash call with its output, and a failing Read call.
+func notebookTestEntries() []models.ConversationEntry {
+	return []models.ConversationEntry{
+		{UUID: "u1", SessionID: "sess-1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z",
+			Message: json.RawMessage(`"Write a hello world"`)},
+		{UUID: "a1", SessionID: "sess-1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:05Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Here it is:\n\n` + "```go\\npackage main\\n\\nfunc main() {}\\n```" + `\n\nRun it with go run."}]}`)},
+		{UUID: "a2", SessionID: "sess-1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:06Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"go run main.go"}},{"type":"tool_use","id":"toolu_2","name":"Read","input":{"file_path":"/missing"}}]}`)},
+		{UUID: "r1", SessionID: "sess-1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:07Z",
+			Message: json.RawMessage(`[{"type":"tool_result","tool_use_id":"toolu_1","content":"hello\nworld\n"},{"type":"tool_result","tool_use_id":"toolu_2","content":"file not found","is_error":true}]`)},
+		{UUID: "q1", SessionID: "sess-1", Type: models.EntryTypeQueueOperation, Timestamp: "2026-02-01T10:00:08Z", AgentID: "a12eb64"},
+	}
+}
+
+// checkNotebookSchema checks the nbformat 4.4 rules a notebook must follow to open
+// without validation errors: the top-level keys, and for each cell type exactly the
+// allowed keys, a metadata object, and a source list of strings.
+func checkNotebookSchema(t *testing.T, data []byte) []map[string]any {
+	t.Helper()
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("notebook is not valid JSON: %v", err)
+	}
+	if keys := sortedKeys(doc); strings.Join(keys, ",") != "cells,metadata,nbformat,nbformat_minor" {
+		t.Errorf("top-level keys = %v", keys)
+	}
+	if doc["nbformat"] != float64(4) || doc["nbformat_minor"] != float64(4) {
+		t.Errorf("nbformat = %v.%v, want 4.4", doc["nbformat"], doc["nbformat_minor"])
+	}
+	if _, ok := doc["metadata"].(map[string]any); !ok {
+		t.Error("notebook metadata should be an object")
+	}
+
+	rawCells, ok := doc["cells"].([]any)
+	if !ok {
+		t.Fatalf("cells should be a list, got %T", doc["cells"])
+	}
+	allowed := map[string]string{
+		"markdown": "cell_type,metadata,source",
+		"raw":      "cell_type,metadata,source",
+		"code":     "cell_type,execution_count,metadata,outputs,source",
+	}
+
+	var cells []map[string]any
+	for i, raw := range rawCells {
+		cell, ok := raw.(map[string]any)
+		if !ok {
+			t.Fatalf("cell %d is not an object", i)
+		}
+		cellType, _ := cell["cell_type"].(string)
+		want, known := allowed[cellType]
+		if !known {
+			t.Errorf("cell %d has unknown type %q", i, cellType)
+			continue
+		}
+		if keys := strings.Join(sortedKeys(cell), ","); keys != want {
+			t.Errorf("%s cell %d keys = %s, want %s", cellType, i, keys, want)
+		}
+		if _, ok := cell["metadata"].(map[string]any); !ok {
+			t.Errorf("cell %d metadata should be an object", i)
+		}
+		source, ok := cell["source"].([]any)
+		if !ok {
+			t.Errorf("cell %d source should be a list", i)
+		}
+		for _, line := range source {
+			if _, ok := line.(string); !ok {
+				t.Errorf("cell %d source should hold strings", i)
+			}
+		}
+		if cellType == "code" {
+			if cell["execution_count"] != nil {
+				t.Errorf("code cell %d execution_count = %v, want null", i, cell["execution_count"])
+			}
+			if outputs, ok := cell["outputs"].([]any); !ok || len(outputs) != 0 {
+				t.Errorf("code cell %d outputs = %v, want []", i, cell["outputs"])
+			}
+		}
+		cells = append(cells, cell)
+	}
+	return cells
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// cellText joins a cell's source lines.
+func cellText(cell map[string]any) string {
+	var sb strings.Builder
+	for _, line := range cell["source"].([]any) {
+		sb.WriteString(line.(string))
+	}
+	return sb.String()
+}
+
+func TestRenderConversationNotebook(t *testing.T) {
+	data, err := RenderConversationNotebook(notebookTestEntries())
+	if err != nil {
+		t.Fatalf("RenderConversationNotebook() error = %v", err)
+	}
+	cells := checkNotebookSchema(t, data)
+
+	type cellSummary struct{ kind, text string }
+	want := []cellSummary{
+		{"markdown", "## User · 10:00 AM\n\nWrite a hello world"},
+		{"markdown", "## Assistant · 10:00 AM\n\nHere it is:"},
+		{"code", "package main\n\nfunc main() {}"},
+		{"markdown", "Run it with go run."},
+		{"markdown", "## Assistant · 10:00 AM"},
+		{"markdown", "**Tool:** `Bash`"},
+		{"raw", "hello\nworld\n"},
+		{"markdown", "**Tool:** `Read`"},
+		{"raw", "file not found"},
+		{"markdown", "> Subagent `a12eb64` spawned"},
+	}
+	if len(cells) != len(want) {
+		for i, c := range cells {
+			t.Logf("cell %d: %s %q", i, c["cell_type"], cellText(c))
+		}
+		t.Fatalf("got %d cells, want %d", len(cells), len(want))
+	}
+	for i, w := range want {
+		if cells[i]["cell_type"] != w.kind || !strings.HasPrefix(cellText(cells[i]), w.text) {
+			t.Errorf("cell %d = %s %q, want %s starting %q", i, cells[i]["cell_type"], cellText(cells[i]), w.kind, w.text)
+		}
+	}
+
+	// Code cells record their language; tool cells show input and label the output
+	if lang := cells[2]["metadata"].(map[string]any)["language"]; lang != "go" {
+		t.Errorf("code cell language = %v, want go", lang)
+	}
+	if text := cellText(cells[5]); !strings.Contains(text, `"command": "go run main.go"`) || !strings.HasSuffix(text, "Output:") {
+		t.Errorf("Bash tool cell = %q", text)
+	}
+	if text := cellText(cells[7]); !strings.HasSuffix(text, "Error:") {
+		t.Errorf("Read tool cell should label the error output, got %q", text)
+	}
+	if meta := cells[8]["metadata"].(map[string]any)["claude_history"].(map[string]any); meta["tool_use_id"] != "toolu_2" || meta["is_error"] != true {
+		t.Errorf("error output metadata = %v", meta)
+	}
+
+	// The session ID is recorded in the notebook metadata
+	var doc notebook
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.Metadata["claude_history"].(map[string]any)["session_id"]; got != "sess-1" {
+		t.Errorf("notebook session_id = %v", got)
+	}
+}
+
+func TestRenderConversationNotebook_Empty(t *testing.T) {
+	data, err := RenderConversationNotebook(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cells := checkNotebookSchema(t, data); len(cells) != 0 {
+		t.Errorf("empty conversation should give no cells, got %d", len(cells))
+	}
+	if !strings.Contains(string(data), `"cells": []`) {
+		t.Error("cells should be an empty list, not null")
+	}
+}
+
+func TestRenderConversationNotebook_Deterministic(t *testing.T) {
+	first, err := RenderConversationNotebook(notebookTestEntries())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := RenderConversationNotebook(notebookTestEntries())
+	if string(first) != string(second) {
+		t.Error("the same conversation should give identical notebooks")
+	}
+}
+
+func TestNotebookSource(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", []string{}},
+		{"one", []string{"one"}},
+		{"one\ntwo", []string{"one\n", "two"}},
+		{"one\n", []string{"one\n"}},
+	}
+	for _, tt := range tests {
+		got := notebookSource(tt.in)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("notebookSource(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// JSONExport is the top-level document produced by RenderConversationJSON.
+type JSONExport struct {
+	FormatVersion string      `json:"format_version"`
+	SessionID     string      `json:"session_id"`
+	ProjectPath   string      `json:"project_path,omitempty"`
+	Stats         JSONStats   `json:"stats"`
+	Agents        []JSONAgent `json:"agents,omitempty"`
+	Entries       []JSONEntry `json:"entries"`
+}
+
+// JSONStats is the session statistics block of a JSON export.
+type JSONStats struct {
+	SessionStart      string   `json:"session_start,omitempty"`
+	SessionEnd        string   `json:"session_end,omitempty"`
+	Duration          string   `json:"duration,omitempty"`
+	UserMessages      int      `json:"user_messages"`
+	AssistantMessages int      `json:"assistant_messages"`
+	ToolCalls         int      `json:"tool_calls"`
+	AgentCount        int      `json:"agent_count"`
+	AgentMessages     int      `json:"agent_messages"`
+	Models            []string `json:"models,omitempty"`
+	IncompleteReason  string   `json:"incomplete_reason,omitempty"`
+	APIErrors         int      `json:"api_errors,omitempty"`
+	Compactions       int      `json:"compactions,omitempty"`
+}
+
+// JSONAgent describes a subagent in a JSON export.
+type JSONAgent struct {
+	AgentID    string      `json:"agent_id"`
+	AgentType  string      `json:"agent_type,omitempty"`
+	EntryCount int         `json:"entry_count"`
+	Children   []JSONAgent `json:"children,omitempty"`
+}
+
+// JSONEntry is a single conversation entry in a JSON export.
+type JSONEntry struct {
+	UUID       string         `json:"uuid"`
+	Type       string         `json:"type"`
+	Timestamp  string         `json:"timestamp,omitempty"`
+	SessionID  string         `json:"session_id,omitempty"`
+	AgentID    string         `json:"agent_id,omitempty"`
+	ParentUUID string         `json:"parent_uuid,omitempty"`
+	Text       string         `json:"text,omitempty"`
+	ToolCalls  []JSONToolCall `json:"tool_calls,omitempty"`
+}
+
+// JSONToolCall is a tool invocation with its result in a JSON export.
+type JSONToolCall struct {
+	ID      string         `json:"id"`
+	Name    string         `json:"name"`
+	Input   map[string]any `json:"input,omitempty"`
+	Output  *string        `json:"output,omitempty"`
+	IsError bool           `json:"is_error,omitempty"`
+}
+
+// jsonEntryFields is the allow-list of entry keys that can be selected with --fields.
+var jsonEntryFields = map[string]func(JSONEntry) any{
+	"uuid":        func(e JSONEntry) any { return e.UUID },
+	"type":        func(e JSONEntry) any { return e.Type },
+	"timestamp":   func(e JSONEntry) any { return e.Timestamp },
+	"session_id":  func(e JSONEntry) any { return e.SessionID },
+	"agent_id":    func(e JSONEntry) any { return e.AgentID },
+	"parent_uuid": func(e JSONEntry) any { return e.ParentUUID },
+	"text":        func(e JSONEntry) any { return e.Text },
+	"tool_calls":  func(e JSONEntry) any { return e.ToolCalls },
+	"tool": func(e JSONEntry) any {
+		names := make([]string, 0, len(e.ToolCalls))
+		for _, call := range e.ToolCalls {
+			names = append(names, call.Name)
+		}
+		return names
+	},
+}
+
+// jsonProjectedExport replaces the full entries with field-selected maps.
+// The outer Entries field shadows JSONExport.Entries during marshaling.
+type jsonProjectedExport struct {
+	JSONExport
+	Entries []map[string]any `json:"entries"`
+}
+
+// RenderConversationJSON generates an indented JSON document for a conversation.
+// Tool results are attached to the tool calls that produced them.
+// stats contains optional session statistics (if nil, stats are computed from entries/agents).
+// fields optionally restricts each entry to the named keys (nil includes all fields).
+func RenderConversationJSON(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats, fields []string) ([]byte, error) {
+	if err := ValidateJSONFields(fields); err != nil {
+		return nil, err
+	}
+
+	doc := buildJSONExport(entries, agents, stats)
+
+	var payload any = doc
+	if len(fields) > 0 {
+		projected := jsonProjectedExport{JSONExport: doc, Entries: make([]map[string]any, 0, len(doc.Entries))}
+		for _, entry := range doc.Entries {
+			row := make(map[string]any, len(fields))
+			for _, field := range fields {
+				row[field] = jsonEntryFields[field](entry)
+			}
+			projected.Entries = append(projected.Entries, row)
+		}
+		payload = projected
+	}
+
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON export: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// ValidateJSONFields checks that every requested field is a known JSON entry key.
+func ValidateJSONFields(fields []string) error {
+	for _, field := range fields {
+		if _, ok := jsonEntryFields[field]; !ok {
+			return fmt.Errorf("unknown field %q (valid fields: %s)", field, strings.Join(JSONFieldNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// JSONFieldNames returns the selectable JSON entry fields in sorted order.
+func JSONFieldNames() []string {
+	names := make([]string, 0, len(jsonEntryFields))
+	for name := range jsonEntryFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewJSONStats returns the statistics block of a JSON export for stats.
+func NewJSONStats(stats *SessionStats) JSONStats {
+	return JSONStats{
+		SessionStart:      stats.SessionStart,
+		SessionEnd:        stats.SessionEnd,
+		Duration:          stats.Duration,
+		UserMessages:      stats.UserMessages,
+		AssistantMessages: stats.AssistantMessages,
+		ToolCalls:         stats.ToolCallCount,
+		AgentCount:        stats.AgentCount,
+		AgentMessages:     stats.TotalAgentMessages,
+		Models:            stats.Models,
+		IncompleteReason:  stats.IncompleteReason,
+		APIErrors:         stats.APIErrorCount,
+		Compactions:       stats.CompactionCount,
+	}
+}
+
+// buildJSONExport assembles the JSON export document.
+func buildJSONExport(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats) JSONExport {
+	if stats == nil {
+		stats = ComputeSessionStats(entries, agents)
+	}
+	toolResults := buildToolResultsMap(entries)
+
+	doc := JSONExport{
+		FormatVersion: ExportFormatVersion,
+		SessionID:     stats.SessionID,
+		ProjectPath:   stats.ProjectPath,
+		Stats:         NewJSONStats(stats),
+		Agents:        convertJSONAgents(agents),
+		Entries:       make([]JSONEntry, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		if !hasContent(entry) {
+			continue
+		}
+		doc.Entries = append(doc.Entries, buildJSONEntry(entry, toolResults))
+	}
+
+	return doc
+}
+
+// buildJSONEntry converts a conversation entry into its JSON export form.
+func buildJSONEntry(entry models.ConversationEntry, toolResults map[string]models.ToolResult) JSONEntry {
+	je := JSONEntry{
+		UUID:      entry.UUID,
+		Type:      string(entry.Type),
+		Timestamp: entry.Timestamp,
+		SessionID: entry.SessionID,
+		AgentID:   entry.AgentID,
+		Text:      entry.GetTextContent(),
+	}
+	if entry.ParentUUID != nil {
+		je.ParentUUID = *entry.ParentUUID
+	}
+
+	if entry.Type == models.EntryTypeAssistant {
+		for _, tool := range entry.ExtractToolCalls() {
+			call := JSONToolCall{ID: tool.ID, Name: tool.Name, Input: tool.Input}
+			if result, ok := toolResults[tool.ID]; ok {
+				output := result.Content
+				call.Output = &output
+				call.IsError = result.IsError
+			}
+			je.ToolCalls = append(je.ToolCalls, call)
+		}
+	}
+
+	return je
+}
+
+// convertJSONAgents converts the agent hierarchy into its JSON export form.
+func convertJSONAgents(nodes []*agent.TreeNode) []JSONAgent {
+	if len(nodes) == 0 {
+		return nil
+	}
+	result := make([]JSONAgent, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, JSONAgent{
+			AgentID:    node.AgentID,
+			AgentType:  node.AgentType,
+			EntryCount: node.EntryCount,
+			Children:   convertJSONAgents(node.Children),
+		})
+	}
+	return result
+}
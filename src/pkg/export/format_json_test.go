@@ -0,0 +1,106 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/agent"
+)
+
+func TestRenderConversationJSON(t *testing.T) {
+	agents := []*agent.TreeNode{
+		{AgentID: "agent-1", EntryCount: 4, Children: []*agent.TreeNode{{AgentID: "agent-2", EntryCount: 2}}},
+	}
+
+	data, err := RenderConversationJSON(exporterTestEntries(), agents, nil, nil)
+	if err != nil {
+		t.Fatalf("RenderConversationJSON() error = %v", err)
+	}
+
+	var doc JSONExport
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+
+	if doc.FormatVersion != ExportFormatVersion {
+		t.Errorf("FormatVersion = %q, want %q", doc.FormatVersion, ExportFormatVersion)
+	}
+	if doc.SessionID != "session-1" {
+		t.Errorf("SessionID = %q, want session-1", doc.SessionID)
+	}
+	// The tool-result-only user entry is folded into the tool call
+	if len(doc.Entries) != 2 {
+		t.Fatalf("len(Entries) = %d, want 2", len(doc.Entries))
+	}
+	if doc.Stats.ToolCalls != 1 {
+		t.Errorf("Stats.ToolCalls = %d, want 1", doc.Stats.ToolCalls)
+	}
+
+	calls := doc.Entries[1].ToolCalls
+	if len(calls) != 1 {
+		t.Fatalf("len(ToolCalls) = %d, want 1", len(calls))
+	}
+	if calls[0].Name != "Bash" || calls[0].Output == nil || *calls[0].Output != "main.go\ngo.mod" {
+		t.Errorf("unexpected tool call: %+v", calls[0])
+	}
+
+	if len(doc.Agents) != 1 || len(doc.Agents[0].Children) != 1 {
+		t.Errorf("agent hierarchy not preserved: %+v", doc.Agents)
+	}
+}
+
+func TestRenderConversationJSON_Empty(t *testing.T) {
+	data, err := RenderConversationJSON(nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("RenderConversationJSON() error = %v", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if entries, ok := doc["entries"].([]any); !ok || len(entries) != 0 {
+		t.Errorf("entries should be an empty array, got %v", doc["entries"])
+	}
+}
+
+func TestRenderConversationJSON_Fields(t *testing.T) {
+	data, err := RenderConversationJSON(exporterTestEntries(), nil, nil, []string{"uuid", "tool"})
+	if err != nil {
+		t.Fatalf("RenderConversationJSON() error = %v", err)
+	}
+
+	var doc struct {
+		FormatVersion string           `json:"format_version"`
+		Entries       []map[string]any `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if doc.FormatVersion != ExportFormatVersion {
+		t.Error("document metadata should be preserved when selecting fields")
+	}
+	if len(doc.Entries) != 2 {
+		t.Fatalf("len(Entries) = %d, want 2", len(doc.Entries))
+	}
+	for _, entry := range doc.Entries {
+		if len(entry) != 2 {
+			t.Errorf("entry should only have selected keys, got %v", entry)
+		}
+	}
+	tools, ok := doc.Entries[1]["tool"].([]any)
+	if !ok || len(tools) != 1 || tools[0] != "Bash" {
+		t.Errorf("tool = %v, want [Bash]", doc.Entries[1]["tool"])
+	}
+}
+
+func TestRenderConversationJSON_UnknownField(t *testing.T) {
+	_, err := RenderConversationJSON(exporterTestEntries(), nil, nil, []string{"uuid", "bogus"})
+	if err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+	if !strings.Contains(err.Error(), `"bogus"`) || !strings.Contains(err.Error(), "timestamp") {
+		t.Errorf("error should name the field and list valid fields, got: %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// RenderConversationMarkdown generates a markdown document for a conversation.
+// Assistant text is emitted as-is (it is already markdown), user text is emitted verbatim,
+// and tool calls are rendered with their input and output in fenced code blocks.
+// stats contains optional session statistics (if nil, stats are computed from entries/agents).
+// The document ends with a session statistics block (see markdownStatsHeading).
+func RenderConversationMarkdown(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats) (string, error) {
+	return renderConversationMarkdown(entries, agents, stats, true, localizer{}, 0)
+}
+
+// renderConversationMarkdown renders the markdown document, optionally ending with the stats block.
+// Stats numbers and durations are formatted with loc. The prose of each message is wrapped
+// at wrapWidth columns (see wrapText); 0 does not wrap.
+func renderConversationMarkdown(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats, includeStats bool, loc localizer, wrapWidth int) (string, error) {
+	var sb strings.Builder
+
+	if stats == nil {
+		stats = ComputeSessionStats(entries, agents)
+	}
+	agentMap := buildAgentMap(agents)
+	toolResults := buildToolResultsMap(entries)
+
+	// Title and metadata
+	if stats.SessionID != "" {
+		sb.WriteString(fmt.Sprintf("# Session %s\n\n", stats.SessionID))
+	} else {
+		sb.WriteString("# Claude Conversation\n\n")
+	}
+	if stats.ProjectPath != "" {
+		sb.WriteString(fmt.Sprintf("- **Project:** %s\n", stats.ProjectPath))
+	}
+	if stats.SessionStart != "" {
+		sb.WriteString(fmt.Sprintf("- **Started:** %s\n", stats.SessionStart))
+	}
+	if stats.Duration != "" {
+		sb.WriteString(fmt.Sprintf("- **Duration:** %s\n", loc.statsDuration(stats)))
+	}
+	sb.WriteString("\n")
+
+	for _, entry := range entries {
+		if hasContent(entry) {
+			sb.WriteString(renderEntryMarkdownWrapped(entry, toolResults, "User", "Assistant", wrapWidth))
+		}
+
+		// Note subagent spawns inline
+		if entry.Type == models.EntryTypeQueueOperation && entry.AgentID != "" {
+			sb.WriteString(fmt.Sprintf("> Subagent `%s` spawned (%d entries)\n\n", entry.AgentID, agentMap[entry.AgentID]))
+		}
+	}
+
+	if includeStats {
+		sb.WriteString(renderStatsMarkdown(stats, loc))
+	}
+
+	return sb.String(), nil
+}
+
+// markdownStatsHeading introduces the trailing statistics block. The block starts at the
+// "---" rule immediately before this heading and runs to the end of the document.
+const markdownStatsHeading = "## Session statistics"
+
+// renderStatsMarkdown renders the trailing session statistics block.
+func renderStatsMarkdown(stats *SessionStats, loc localizer) string {
+	var sb strings.Builder
+	sb.WriteString("---\n\n" + markdownStatsHeading + "\n\n")
+	for _, line := range statsSummaryLines(stats, loc) {
+		sb.WriteString(fmt.Sprintf("- **%s:** %s\n", line[0], line[1]))
+	}
+	return sb.String()
+}
+
+// statsSummaryLines returns the label/value pairs shown in the markdown and text stats blocks,
+// with numbers and durations formatted by loc.
+func statsSummaryLines(stats *SessionStats, loc localizer) [][2]string {
+	lines := [][2]string{
+		{"Messages", fmt.Sprintf("%s (%s user, %s assistant)", loc.number(stats.UserMessages+stats.AssistantMessages), loc.number(stats.UserMessages), loc.number(stats.AssistantMessages))},
+		{"Tool calls", loc.number(stats.ToolCallCount)},
+	}
+	if stats.AgentCount > 0 {
+		lines = append(lines, [2]string{"Subagents", fmt.Sprintf("%s (%s messages)", loc.number(stats.AgentCount), loc.number(stats.TotalAgentMessages))})
+	}
+	if stats.Duration != "" {
+		lines = append(lines, [2]string{"Duration", loc.statsDuration(stats)})
+	}
+	if len(stats.Models) > 0 {
+		lines = append(lines, [2]string{"Models", strings.Join(stats.Models, ", ")})
+	}
+	if stats.APIErrorCount > 0 {
+		lines = append(lines, [2]string{"API errors", loc.number(stats.APIErrorCount)})
+	}
+	if stats.CompactionCount > 0 {
+		lines = append(lines, [2]string{"Compactions", loc.number(stats.CompactionCount)})
+	}
+	if stats.IncompleteReason != "" {
+		lines = append(lines, [2]string{"Status", "incomplete: " + stats.IncompleteReason})
+	}
+	return lines
+}
+
+// RenderQueryResultsMarkdown renders query results as markdown: one section per entry
+// with content, without the document title and statistics of a full export.
+// userLabel and assistantLabel specify the role names to use (e.g., "User"/"Assistant"
+// or "Orchestrator"/"Agent" for a subagent's entries).
+func RenderQueryResultsMarkdown(entries []models.ConversationEntry, userLabel, assistantLabel string) string {
+	var sb strings.Builder
+	toolResults := buildToolResultsMap(entries)
+	for _, entry := range entries {
+		if hasContent(entry) {
+			sb.WriteString(renderEntryMarkdownWith(entry, toolResults, userLabel, assistantLabel))
+		}
+	}
+	return sb.String()
+}
+
+// renderEntryMarkdown renders a single conversation entry as a markdown section.
+func renderEntryMarkdown(entry models.ConversationEntry, toolResults map[string]models.ToolResult) string {
+	return renderEntryMarkdownWith(entry, toolResults, "User", "Assistant")
+}
+
+// renderEntryMarkdownWith renders an entry as a markdown section headed by its role,
+// using userLabel and assistantLabel for user and assistant entries.
+func renderEntryMarkdownWith(entry models.ConversationEntry, toolResults map[string]models.ToolResult, userLabel, assistantLabel string) string {
+	return renderEntryMarkdownWrapped(entry, toolResults, userLabel, assistantLabel, 0)
+}
+
+// renderEntryMarkdownWrapped renders an entry like renderEntryMarkdownWith, wrapping the
+// prose of its text at wrapWidth columns (0 does not wrap). Tool calls are not wrapped.
+func renderEntryMarkdownWrapped(entry models.ConversationEntry, toolResults map[string]models.ToolResult, userLabel, assistantLabel string, wrapWidth int) string {
+	var sb strings.Builder
+
+	heading := getRoleLabel(entry.Type, userLabel, assistantLabel)
+	if entry.AgentID != "" {
+		heading += fmt.Sprintf(" (agent %s)", truncateID(entry.AgentID, 8))
+	}
+	if ts := formatTimestampReadable(entry.Timestamp); ts != "" {
+		heading += " · " + ts
+	}
+	sb.WriteString(fmt.Sprintf("## %s\n\n", heading))
+
+	if text := strings.TrimSpace(entry.GetTextContent()); text != "" {
+		sb.WriteString(wrapText(text, wrapWidth))
+		sb.WriteString("\n\n")
+	}
+
+	if entry.Type == models.EntryTypeAssistant {
+		for _, tool := range entry.ExtractToolCalls() {
+			result, hasResult := toolResults[tool.ID]
+			sb.WriteString(renderToolCallMarkdown(tool, result, hasResult))
+		}
+	}
+
+	return sb.String()
+}
+
+// renderToolCallMarkdown renders a tool call with its input and output as fenced blocks.
+func renderToolCallMarkdown(tool models.ToolUse, result models.ToolResult, hasResult bool) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("**Tool:** `%s`", tool.Name))
+	if display := extractToolDisplayValue(tool.Name, tool.Input); display != "" {
+		sb.WriteString(" — " + strings.ReplaceAll(display, "\n", " "))
+	}
+	sb.WriteString("\n\n")
+
+	sb.WriteString(fencedBlock("json", formatToolInput(tool.Input)))
+
+	if hasResult {
+		label := "Output"
+		if result.IsError {
+			label = "Error"
+		}
+		sb.WriteString(fmt.Sprintf("%s:\n\n", label))
+		sb.WriteString(fencedBlock("", result.Content))
+	}
+
+	return sb.String()
+}
+
+// fencedBlock wraps content in a fenced code block whose fence is longer than
+// any backtick run inside the content, so embedded fences cannot terminate it early.
+func fencedBlock(lang, content string) string {
+	longest := 0
+	run := 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	fenceLen := 3
+	if longest >= fenceLen {
+		fenceLen = longest + 1
+	}
+	fence := strings.Repeat("`", fenceLen)
+
+	return fmt.Sprintf("%s%s\n%s\n%s\n\n", fence, lang, strings.TrimRight(content, "\n"), fence)
+}
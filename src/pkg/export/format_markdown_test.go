@@ -0,0 +1,139 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRenderConversationMarkdown(t *testing.T) {
+	md, err := RenderConversationMarkdown(exporterTestEntries(), nil, nil)
+	if err != nil {
+		t.Fatalf("RenderConversationMarkdown() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"# Session session-1",
+		"## User",
+		"List the files",
+		"## Assistant",
+		"Listing **now**.",
+		"**Tool:** `Bash` — ls -la",
+		"```json\n",
+		"Output:\n\n```\nmain.go\ngo.mod\n```",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown should contain %q, got:\n%s", want, md)
+		}
+	}
+
+	// Tool-result-only user entries should not produce their own section
+	if strings.Count(md, "## User") != 1 {
+		t.Errorf("expected exactly one user section, got:\n%s", md)
+	}
+	if strings.Contains(md, "<div") {
+		t.Error("markdown output should not contain HTML")
+	}
+}
+
+func TestRenderQueryResultsMarkdown(t *testing.T) {
+	md := RenderQueryResultsMarkdown(exporterTestEntries(), "Orchestrator", "Agent")
+
+	for _, want := range []string{"## Orchestrator", "List the files", "## Agent", "**Tool:** `Bash` — ls -la", "main.go"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown should contain %q, got:\n%s", want, md)
+		}
+	}
+	for _, unwanted := range []string{"## User", "## Assistant", "# Session", markdownStatsHeading} {
+		if strings.Contains(md, unwanted) {
+			t.Errorf("query markdown should not contain %q, got:\n%s", unwanted, md)
+		}
+	}
+}
+
+func TestRenderConversationMarkdown_ErrorResult(t *testing.T) {
+	tool := models.ToolUse{ID: "t1", Name: "Bash", Input: map[string]any{"command": "false"}}
+	md := renderToolCallMarkdown(tool, models.ToolResult{Content: "exit 1", IsError: true}, true)
+	if !strings.Contains(md, "Error:") {
+		t.Errorf("error results should be labeled, got:\n%s", md)
+	}
+}
+
+func TestFencedBlock(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantFence string
+	}{
+		{"plain", "hello", "```"},
+		{"inline backticks", "use `x`", "```"},
+		{"embedded fence", "```go\nx\n```", "````"},
+		{"long run", "`````", "``````"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fencedBlock("", tt.content)
+			if !strings.HasPrefix(got, tt.wantFence+"\n") {
+				t.Errorf("fencedBlock(%q) = %q, want fence %q", tt.content, got, tt.wantFence)
+			}
+			if !strings.HasSuffix(got, "\n"+tt.wantFence+"\n\n") {
+				t.Errorf("fencedBlock(%q) = %q, want closing fence %q", tt.content, got, tt.wantFence)
+			}
+		})
+	}
+}
+
+func TestRenderConversationMarkdown_StatsBlock(t *testing.T) {
+	stats := &SessionStats{SessionID: "s", UserMessages: 2, AssistantMessages: 3, ToolCallCount: 4, AgentCount: 1, TotalAgentMessages: 7, Duration: "5m"}
+	md, err := RenderConversationMarkdown(exporterTestEntries(), nil, stats)
+	if err != nil {
+		t.Fatalf("RenderConversationMarkdown() error = %v", err)
+	}
+
+	// The block is delimited by a rule and heading and runs to the end of the document
+	idx := strings.LastIndex(md, "---\n\n"+markdownStatsHeading+"\n\n")
+	if idx == -1 {
+		t.Fatalf("missing delimited stats block, got:\n%s", md)
+	}
+	block := md[idx:]
+	for _, want := range []string{
+		"- **Messages:** 5 (2 user, 3 assistant)\n",
+		"- **Tool calls:** 4\n",
+		"- **Subagents:** 1 (7 messages)\n",
+		"- **Duration:** 5m\n",
+	} {
+		if !strings.Contains(block, want) {
+			t.Errorf("stats block should contain %q, got:\n%s", want, block)
+		}
+	}
+	if strings.Contains(md[:idx], markdownStatsHeading) {
+		t.Error("stats heading should appear only in the trailing block")
+	}
+}
+
+func TestMarkdownExporter_NoStats(t *testing.T) {
+	out, err := MarkdownExporter{NoStats: true}.Render(exporterTestEntries(), nil, nil)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if strings.Contains(string(out), markdownStatsHeading) {
+		t.Errorf("NoStats should omit the stats block, got:\n%s", out)
+	}
+
+	withStats, _ := MarkdownExporter{}.Render(exporterTestEntries(), nil, nil)
+	if !strings.HasPrefix(string(withStats), string(out)) {
+		t.Error("the stats block should only be appended; the rest of the document is unchanged")
+	}
+}
+
+func TestStatsSummaryLines_OmitsEmptyOptionalLines(t *testing.T) {
+	lines := statsSummaryLines(&SessionStats{UserMessages: 1}, localizer{})
+	if len(lines) != 2 {
+		t.Fatalf("expected only messages and tool calls, got %v", lines)
+	}
+	if lines[0][1] != "1 (1 user, 0 assistant)" || lines[1][1] != "0" {
+		t.Errorf("unexpected lines %v", lines)
+	}
+}
@@ -0,0 +1,90 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// promMetric is one metric family written by WritePrometheusStats: a sample per
+// session, plus a label for families split by role.
+type promMetric struct {
+	name  string
+	help  string
+	value func(*SessionStats) []promSample
+}
+
+// promSample is one value of a metric family, with its extra label if any.
+type promSample struct {
+	label, labelValue string
+	value             float64
+}
+
+// promMetrics are the metric families of WritePrometheusStats, in output order.
+var promMetrics = []promMetric{
+	{
+		name: "claude_history_session_messages",
+		help: "Messages in the session by role; subagent counts every subagent entry.",
+		value: func(s *SessionStats) []promSample {
+			return []promSample{
+				{"role", "user", float64(s.UserMessages)},
+				{"role", "assistant", float64(s.AssistantMessages)},
+				{"role", "subagent", float64(s.TotalAgentMessages)},
+			}
+		},
+	},
+	{
+		name:  "claude_history_session_tool_calls",
+		help:  "Tool calls made by the main session's assistant messages.",
+		value: func(s *SessionStats) []promSample { return []promSample{{value: float64(s.ToolCallCount)}} },
+	},
+	{
+		name: "claude_history_session_duration_seconds",
+		help: "Time from the session's first to last entry; left out when the timestamps are unknown.",
+		value: func(s *SessionStats) []promSample {
+			if s.Duration == "" {
+				return nil
+			}
+			return []promSample{{value: s.duration.Seconds()}}
+		},
+	},
+	{
+		name:  "claude_history_session_agents",
+		help:  "Subagents spawned by the session, at any depth.",
+		value: func(s *SessionStats) []promSample { return []promSample{{value: float64(s.AgentCount)}} },
+	},
+}
+
+// WritePrometheusStats writes the statistics of one or more sessions as metrics in the
+// Prometheus text exposition format. Each metric family is written once, with a sample
+// per session labeled by session ID and project path, so the output of several sessions
+// can be scraped as a whole. All families are gauges: they describe the sessions as
+// they are now rather than counting events since a restart.
+func WritePrometheusStats(w io.Writer, stats []*SessionStats) error {
+	var sb strings.Builder
+	for _, metric := range promMetrics {
+		fmt.Fprintf(&sb, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(&sb, "# TYPE %s gauge\n", metric.name)
+		for _, s := range stats {
+			for _, sample := range metric.value(s) {
+				labels := fmt.Sprintf(`session="%s",project="%s"`, escapePromLabel(s.SessionID), escapePromLabel(s.ProjectPath))
+				if sample.label != "" {
+					labels += fmt.Sprintf(`,%s="%s"`, sample.label, escapePromLabel(sample.labelValue))
+				}
+				fmt.Fprintf(&sb, "%s{%s} %s\n", metric.name, labels, strconv.FormatFloat(sample.value, 'g', -1, 64))
+			}
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// promLabelEscaper escapes the characters the Prometheus text format requires escaped
+// in label values.
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapePromLabel escapes a label value for the Prometheus text format.
+func escapePromLabel(s string) string {
+	return promLabelEscaper.Replace(s)
+}
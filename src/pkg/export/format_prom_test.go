@@ -0,0 +1,50 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWritePrometheusStats(t *testing.T) {
+	stats := []*SessionStats{
+		{SessionID: "s1", ProjectPath: `/work/my "app"`, UserMessages: 3, AssistantMessages: 4, TotalAgentMessages: 10,
+			ToolCallCount: 5, AgentCount: 2, Duration: "1m", duration: 90 * time.Second},
+		{SessionID: "s2", ProjectPath: "C:\\work\nnext", UserMessages: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := WritePrometheusStats(&buf, stats); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"# HELP claude_history_session_messages ",
+		"# TYPE claude_history_session_messages gauge\n",
+		`claude_history_session_messages{session="s1",project="/work/my \"app\"",role="user"} 3` + "\n",
+		`claude_history_session_messages{session="s1",project="/work/my \"app\"",role="subagent"} 10` + "\n",
+		`claude_history_session_tool_calls{session="s1",project="/work/my \"app\""} 5` + "\n",
+		`claude_history_session_duration_seconds{session="s1",project="/work/my \"app\""} 90` + "\n",
+		`claude_history_session_agents{session="s2",project="C:\\work\nnext"} 0` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics should contain %q, got:\n%s", want, got)
+		}
+	}
+
+	// Every family is written once, and a session without a known duration has no sample
+	if n := strings.Count(got, "# TYPE claude_history_session_tool_calls"); n != 1 {
+		t.Errorf("expected one tool_calls family, got %d", n)
+	}
+	if strings.Contains(got, `claude_history_session_duration_seconds{session="s2"`) {
+		t.Error("a session without timestamps should have no duration sample")
+	}
+}
+
+func TestEscapePromLabel(t *testing.T) {
+	if got, want := escapePromLabel("a\\b\"c\nd"), `a\\b\"c\nd`; got != want {
+		t.Errorf("escapePromLabel() = %q, want %q", got, want)
+	}
+}
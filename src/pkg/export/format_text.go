@@ -0,0 +1,127 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// RenderConversationText generates a plain-text transcript of a conversation.
+// Each entry is written as a "ROLE [time]:" line followed by its indented text,
+// with tool calls summarized on "-> " lines.
+// stats contains optional session statistics (if nil, stats are computed from entries/agents).
+// The transcript ends with a session statistics block (see textStatsHeading).
+func RenderConversationText(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats) (string, error) {
+	return renderConversationText(entries, agents, stats, true, localizer{}, 0)
+}
+
+// renderConversationText renders the transcript, optionally ending with the stats block.
+// Stats numbers and durations are formatted with loc. Message text is wrapped at
+// wrapWidth columns, indentation included (see wrapText); 0 does not wrap.
+func renderConversationText(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats, includeStats bool, loc localizer, wrapWidth int) (string, error) {
+	var sb strings.Builder
+
+	if stats == nil {
+		stats = ComputeSessionStats(entries, agents)
+	}
+	toolResults := buildToolResultsMap(entries)
+
+	if stats.SessionID != "" {
+		sb.WriteString(fmt.Sprintf("Session: %s\n", stats.SessionID))
+	}
+	if stats.ProjectPath != "" {
+		sb.WriteString(fmt.Sprintf("Project: %s\n", stats.ProjectPath))
+	}
+	if stats.SessionStart != "" {
+		sb.WriteString(fmt.Sprintf("Started: %s\n", stats.SessionStart))
+	}
+	sb.WriteString("\n")
+
+	for _, entry := range entries {
+		if hasContent(entry) {
+			sb.WriteString(renderEntryTextWith(entry, toolResults, wrapWidth))
+		}
+
+		if entry.Type == models.EntryTypeQueueOperation && entry.AgentID != "" {
+			sb.WriteString(fmt.Sprintf("[subagent %s spawned]\n\n", entry.AgentID))
+		}
+	}
+
+	if includeStats {
+		sb.WriteString(renderStatsText(stats, loc))
+	}
+
+	return sb.String(), nil
+}
+
+// textStatsHeading introduces the trailing statistics block. The block starts at the
+// rule line immediately before this heading and runs to the end of the transcript.
+const textStatsHeading = "SESSION STATISTICS"
+
+// renderStatsText renders the trailing session statistics block.
+func renderStatsText(stats *SessionStats, loc localizer) string {
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat("-", 40) + "\n" + textStatsHeading + "\n")
+	for _, line := range statsSummaryLines(stats, loc) {
+		sb.WriteString(fmt.Sprintf("%-12s%s\n", line[0]+":", line[1]))
+	}
+	return sb.String()
+}
+
+// textIndent indents the text of each entry in the plain-text transcript.
+const textIndent = "  "
+
+// renderEntryText renders a single conversation entry as plain text.
+func renderEntryText(entry models.ConversationEntry, toolResults map[string]models.ToolResult) string {
+	return renderEntryTextWith(entry, toolResults, 0)
+}
+
+// renderEntryTextWith renders an entry like renderEntryText, wrapping its text so that
+// indented lines fit in wrapWidth columns (0 does not wrap).
+func renderEntryTextWith(entry models.ConversationEntry, toolResults map[string]models.ToolResult, wrapWidth int) string {
+	var sb strings.Builder
+
+	header := strings.ToUpper(getRoleLabel(entry.Type, "User", "Assistant"))
+	if entry.AgentID != "" {
+		header += fmt.Sprintf(" (agent %s)", truncateID(entry.AgentID, 8))
+	}
+	if ts := formatTimestampReadable(entry.Timestamp); ts != "" {
+		header += fmt.Sprintf(" [%s]", ts)
+	}
+	sb.WriteString(header + ":\n")
+
+	if text := strings.TrimSpace(entry.GetTextContent()); text != "" {
+		if wrapWidth > 0 {
+			text = wrapText(text, max(wrapWidth-len(textIndent), 1))
+		}
+		sb.WriteString(indentText(text, textIndent))
+		sb.WriteString("\n")
+	}
+
+	if entry.Type == models.EntryTypeAssistant {
+		for _, tool := range entry.ExtractToolCalls() {
+			// One line per call, even for multi-line commands
+			sb.WriteString("  -> " + strings.ReplaceAll(formatToolSummary(tool), "\n", " "))
+			if result, ok := toolResults[tool.ID]; ok && result.IsError {
+				sb.WriteString(" (error)")
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// indentText prefixes every line of s with indent.
+func indentText(s, indent string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,66 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRenderConversationText(t *testing.T) {
+	text, err := RenderConversationText(exporterTestEntries(), nil, nil)
+	if err != nil {
+		t.Fatalf("RenderConversationText() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"Session: session-1",
+		"USER [10:00 AM]:\n  List the files",
+		"ASSISTANT [10:00 AM]:\n  Listing **now**.",
+		"  -> [Bash] ls -la",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text should contain %q, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestRenderEntryText_MultiLineCommand(t *testing.T) {
+	entry := models.ConversationEntry{UUID: "a1", Type: models.EntryTypeAssistant,
+		Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"cd src\ngo test ./..."}}]}`)}
+
+	text := renderEntryText(entry, nil)
+	if !strings.Contains(text, "  -> [Bash] cd src go test ./...\n") {
+		t.Errorf("multi-line command should stay on the tool line, got:\n%s", text)
+	}
+}
+
+func TestIndentText(t *testing.T) {
+	got := indentText("a\n\nb", "  ")
+	if got != "  a\n\n  b" {
+		t.Errorf("indentText() = %q", got)
+	}
+}
+
+func TestRenderConversationText_StatsBlock(t *testing.T) {
+	stats := &SessionStats{SessionID: "s", UserMessages: 1, AssistantMessages: 1, ToolCallCount: 1, Duration: "1m", Models: []string{"claude-x"}}
+	text, err := RenderConversationText(exporterTestEntries(), nil, stats)
+	if err != nil {
+		t.Fatalf("RenderConversationText() error = %v", err)
+	}
+
+	want := strings.Repeat("-", 40) + "\n" + textStatsHeading + "\n" +
+		"Messages:   2 (1 user, 1 assistant)\n" +
+		"Tool calls: 1\n" +
+		"Duration:   1m\n" +
+		"Models:     claude-x\n"
+	if !strings.HasSuffix(text, want) {
+		t.Errorf("text should end with stats block %q, got:\n%s", want, text)
+	}
+
+	noStats, _ := TextExporter{NoStats: true}.Render(exporterTestEntries(), nil, stats)
+	if strings.Contains(string(noStats), textStatsHeading) {
+		t.Errorf("NoStats should omit the stats block, got:\n%s", noStats)
+	}
+}
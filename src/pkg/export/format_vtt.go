@@ -0,0 +1,150 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+const (
+	// vttCueSpacing separates cues whose times cannot be interpolated between two
+	// recorded timestamps (see vttCueTimes).
+	vttCueSpacing = 5 * time.Second
+
+	// vttMinCueDuration is the shortest a cue is shown, so messages written at the same
+	// moment still appear; such cues overlap the next one.
+	vttMinCueDuration = time.Second
+)
+
+// vttCue is one message of a WebVTT transcript.
+type vttCue struct {
+	id         string
+	voice      string
+	text       string
+	start, end time.Duration
+}
+
+// RenderConversationVTT generates a WebVTT timed transcript of a conversation, for
+// overlaying it onto a screen recording. Each user and assistant message with text
+// becomes a cue holding its plain text (see MarkdownToPlainText) in a voice span naming
+// the speaker. Cue times are relative to the earliest timestamp in entries; a cue lasts
+// until the next one starts, and at least vttMinCueDuration, so messages written at the
+// same moment overlap instead of vanishing. Messages without a usable timestamp are
+// spaced evenly between their neighbours (see vttCueTimes).
+func RenderConversationVTT(entries []models.ConversationEntry) (string, error) {
+	var cues []vttCue
+	var stamps []time.Time // Timestamp of each cue; zero when missing
+	var sessionStart time.Time
+	for _, entry := range entries {
+		ts, err := entry.GetTimestamp()
+		if err != nil {
+			ts = time.Time{}
+		} else if sessionStart.IsZero() || ts.Before(sessionStart) {
+			sessionStart = ts
+		}
+
+		if entry.IsMeta || (entry.Type != models.EntryTypeUser && entry.Type != models.EntryTypeAssistant) {
+			continue
+		}
+		text := vttCueText(MarkdownToPlainText(entry.GetTextContent()))
+		if text == "" {
+			continue
+		}
+		cues = append(cues, vttCue{id: entry.UUID, voice: getRoleLabel(entry.Type, "User", "Assistant"), text: text})
+		stamps = append(stamps, ts)
+	}
+
+	starts := vttCueTimes(stamps, sessionStart)
+	for i := range cues {
+		cues[i].start = starts[i]
+		// Timestamps can be out of order; cues must not start before the previous one
+		if i > 0 && cues[i].start < cues[i-1].start {
+			cues[i].start = cues[i-1].start
+		}
+	}
+	for i := range cues {
+		end := cues[i].start + vttCueSpacing
+		if i+1 < len(cues) {
+			end = cues[i+1].start
+		}
+		cues[i].end = max(end, cues[i].start+vttMinCueDuration)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("WEBVTT\n")
+	for _, cue := range cues {
+		sb.WriteString("\n")
+		if cue.id != "" && !strings.Contains(cue.id, "-->") {
+			sb.WriteString(cue.id + "\n")
+		}
+		sb.WriteString(fmt.Sprintf("%s --> %s\n", formatVTTTime(cue.start), formatVTTTime(cue.end)))
+		sb.WriteString(fmt.Sprintf("<v %s>%s\n", cue.voice, cue.text))
+	}
+	return sb.String(), nil
+}
+
+// vttCueTimes returns the start of each cue relative to sessionStart. A zero stamp is
+// missing: a run of missing stamps is spread evenly between the recorded stamps around
+// it, or placed vttCueSpacing apart after (or before) the only recorded neighbour. With
+// no recorded stamps at all, cues are vttCueSpacing apart from zero.
+func vttCueTimes(stamps []time.Time, sessionStart time.Time) []time.Duration {
+	starts := make([]time.Duration, len(stamps))
+	known := make([]bool, len(stamps))
+	for i, ts := range stamps {
+		if !ts.IsZero() {
+			starts[i], known[i] = ts.Sub(sessionStart), true
+		}
+	}
+
+	for i := 0; i < len(stamps); {
+		if known[i] {
+			i++
+			continue
+		}
+		// stamps[i:j] are missing
+		j := i
+		for j < len(stamps) && !known[j] {
+			j++
+		}
+		n := j - i
+		for k := 0; k < n; k++ {
+			switch {
+			case i > 0 && j < len(stamps):
+				starts[i+k] = starts[i-1] + (starts[j]-starts[i-1])*time.Duration(k+1)/time.Duration(n+1)
+			case i > 0:
+				starts[i+k] = starts[i-1] + vttCueSpacing*time.Duration(k+1)
+			case j < len(stamps):
+				starts[i+k] = max(starts[j]-vttCueSpacing*time.Duration(n-k), 0)
+			default:
+				starts[i+k] = vttCueSpacing * time.Duration(k)
+			}
+		}
+		i = j
+	}
+	return starts
+}
+
+// vttCueText makes plain text safe for a cue payload: markup characters are escaped
+// (which also keeps "-->" out of it) and blank lines, which would end the cue, are
+// dropped.
+func vttCueText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	text = strings.Join(lines, "\n")
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// formatVTTTime formats d as a WebVTT timestamp, HH:MM:SS.mmm.
+func formatVTTTime(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
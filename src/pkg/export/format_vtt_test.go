@@ -0,0 +1,102 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func vttEntry(uuid string, entryType models.EntryType, timestamp, text string) models.ConversationEntry {
+	content, _ := json.Marshal(text)
+	return models.ConversationEntry{UUID: uuid, Type: entryType, Timestamp: timestamp,
+		Message: json.RawMessage(`{"role":"` + string(entryType) + `","content":[{"type":"text","text":` + string(content) + `}]}`)}
+}
+
+func TestRenderConversationVTT(t *testing.T) {
+	entries := []models.ConversationEntry{
+		vttEntry("u1", models.EntryTypeUser, "2025-01-01T10:00:00Z", "Fix the **build**"),
+		vttEntry("a1", models.EntryTypeAssistant, "2025-01-01T10:00:04.5Z", "Done:\n\n```go\nx := 1\n```\n\nUse a < b"),
+		vttEntry("a2", models.EntryTypeAssistant, "2025-01-01T10:00:04.5Z", "Also this"),
+		{UUID: "s1", Type: models.EntryTypeSystem, Timestamp: "2025-01-01T10:00:05Z"},
+	}
+	got, err := RenderConversationVTT(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "WEBVTT\n" +
+		"\nu1\n00:00:00.000 --> 00:00:04.500\n<v User>Fix the build\n" +
+		// The cue written at the same moment as the next still shows for a second
+		"\na1\n00:00:04.500 --> 00:00:05.500\n<v Assistant>Done:\n[code]\nUse a &lt; b\n" +
+		"\na2\n00:00:04.500 --> 00:00:09.500\n<v Assistant>Also this\n"
+	if got != want {
+		t.Errorf("RenderConversationVTT() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderConversationVTT_OutOfOrderTimestamps(t *testing.T) {
+	entries := []models.ConversationEntry{
+		vttEntry("u1", models.EntryTypeUser, "2025-01-01T10:00:10Z", "First"),
+		vttEntry("a1", models.EntryTypeAssistant, "2025-01-01T10:00:00Z", "Second"),
+	}
+	got, err := RenderConversationVTT(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"00:00:10.000 --> 00:00:11.000\n<v User>First", "00:00:10.000 --> 00:00:15.000\n<v Assistant>Second"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("transcript missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderConversationVTT_Empty(t *testing.T) {
+	got, err := RenderConversationVTT(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "WEBVTT\n" {
+		t.Errorf("RenderConversationVTT(nil) = %q, want only the header", got)
+	}
+}
+
+func TestVTTCueTimes(t *testing.T) {
+	at := func(s int) time.Time { return time.Date(2025, 1, 1, 10, 0, s, 0, time.UTC) }
+	start := at(0)
+	var none time.Time
+	tests := []struct {
+		name   string
+		stamps []time.Time
+		want   []time.Duration
+	}{
+		{"recorded", []time.Time{at(0), at(3)}, []time.Duration{0, 3 * time.Second}},
+		{"between recorded", []time.Time{at(0), none, none, at(9)}, []time.Duration{0, 3 * time.Second, 6 * time.Second, 9 * time.Second}},
+		{"after recorded", []time.Time{at(2), none}, []time.Duration{2 * time.Second, 7 * time.Second}},
+		{"before recorded", []time.Time{none, none, at(7)}, []time.Duration{0, 2 * time.Second, 7 * time.Second}},
+		{"none recorded", []time.Time{none, none, none}, []time.Duration{0, 5 * time.Second, 10 * time.Second}},
+	}
+	for _, tt := range tests {
+		got := vttCueTimes(tt.stamps, start)
+		if len(got) != len(tt.want) {
+			t.Fatalf("%s: vttCueTimes() = %v, want %v", tt.name, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: vttCueTimes() = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFormatVTTTime(t *testing.T) {
+	if got := formatVTTTime(2*time.Hour + 3*time.Minute + 4*time.Second + 56*time.Millisecond); got != "02:03:04.056" {
+		t.Errorf("formatVTTTime() = %q, want 02:03:04.056", got)
+	}
+	if got := formatVTTTime(-time.Second); got != "00:00:00.000" {
+		t.Errorf("negative durations should clamp to zero, got %q", got)
+	}
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// frontMatterDelimiter opens and closes the YAML front matter of a markdown export.
+// Static-site generators only read front matter at the very start of a file and end it
+// at the first delimiter line after that, so a "---" rule later in the body is never
+// mistaken for it.
+const frontMatterDelimiter = "---"
+
+// yamlPlainPattern matches strings that YAML reads back unchanged as plain scalars:
+// no indicators, no colons or " #", and not starting with a digit or sign that could
+// make them a number, date or timestamp.
+var yamlPlainPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_./-]*$`)
+
+// yamlReserved are plain scalars that YAML 1.1 parsers (Hugo, Jekyll) read as booleans or null.
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true,
+	"y": true, "n": true, "null": true,
+}
+
+// renderFrontMatter renders the YAML front matter of a markdown export from stats: the
+// session title and ID, project, start date, duration and the called tools as tags.
+// Fields without a value are omitted. Every value is on one line, so the block ends at
+// its closing delimiter whatever the values contain.
+func renderFrontMatter(stats *SessionStats) string {
+	var sb strings.Builder
+	sb.WriteString(frontMatterDelimiter + "\n")
+
+	title := "Claude Conversation"
+	if stats.SessionID != "" {
+		title = "Session " + stats.SessionID
+	}
+	writeYAMLField(&sb, "title", title)
+	writeYAMLField(&sb, "session_id", stats.SessionID)
+	writeYAMLField(&sb, "project", stats.ProjectPath)
+	if !stats.start.IsZero() {
+		// Unquoted, so generators read it as a timestamp
+		sb.WriteString("date: " + stats.start.Format(time.RFC3339) + "\n")
+	} else {
+		writeYAMLField(&sb, "date", stats.SessionStart)
+	}
+	writeYAMLField(&sb, "duration", stats.Duration)
+	if len(stats.ToolNames) > 0 {
+		sb.WriteString("tags:\n")
+		for _, name := range stats.ToolNames {
+			sb.WriteString("  - " + yamlScalar(name) + "\n")
+		}
+	}
+
+	sb.WriteString(frontMatterDelimiter + "\n\n")
+	return sb.String()
+}
+
+// writeYAMLField writes "key: value" to sb, unless value is empty.
+func writeYAMLField(sb *strings.Builder, key, value string) {
+	if value == "" {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s\n", key, yamlScalar(value)))
+}
+
+// yamlScalar returns s as a YAML scalar: plain when YAML reads it back unchanged (see
+// yamlPlainPattern), double-quoted otherwise, with backslashes, quotes and control
+// characters escaped so the value stays on one line.
+func yamlScalar(s string) string {
+	if yamlPlainPattern.MatchString(s) && !yamlReserved[strings.ToLower(s)] {
+		return s
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				sb.WriteString(fmt.Sprintf(`\x%02x`, r))
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownExporter_FrontMatter(t *testing.T) {
+	out, err := MarkdownExporter{}.Render(exporterTestEntries(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.HasPrefix(string(out), frontMatterDelimiter) {
+		t.Errorf("front matter should be off by default:\n%s", out)
+	}
+
+	out, err = MarkdownExporter{FrontMatter: true}.Render(exporterTestEntries(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := string(out)
+	want := "---\n" +
+		"title: \"Session session-1\"\n" +
+		"session_id: session-1\n" +
+		"date: 2026-01-01T10:00:00Z\n" +
+		"duration: \"6s\"\n" +
+		"tags:\n" +
+		"  - Bash\n" +
+		"---\n\n# Session session-1\n"
+	if !strings.HasPrefix(md, want) {
+		t.Errorf("front matter = \n%s\nwant prefix\n%s", md, want)
+	}
+	// The stats block's rule stays in the body, after the closing delimiter
+	if !strings.Contains(md[len(want):], "---\n\n"+markdownStatsHeading) {
+		t.Errorf("stats block missing after front matter:\n%s", md)
+	}
+}
+
+func TestRenderFrontMatter_Escaping(t *testing.T) {
+	stats := &SessionStats{
+		SessionID:    "abc",
+		ProjectPath:  `C:\work\"app": ---`,
+		SessionStart: "2026-01-01 10:00",
+		ToolNames:    []string{"Read", "mcp__github__create_issue", "yes", "Tool: x\n---"},
+	}
+	fm := renderFrontMatter(stats)
+
+	for _, want := range []string{
+		`project: "C:\\work\\\"app\": ---"`,
+		`date: "2026-01-01 10:00"`,
+		"  - Read\n",
+		"  - mcp__github__create_issue\n",
+		`  - "yes"`,
+		`  - "Tool: x\n---"`,
+	} {
+		if !strings.Contains(fm, want) {
+			t.Errorf("front matter missing %q:\n%s", want, fm)
+		}
+	}
+	if strings.Contains(fm, "duration:") {
+		t.Errorf("empty fields should be omitted:\n%s", fm)
+	}
+
+	// Only the opening and closing lines are delimiters, whatever the values contain
+	lines := strings.Split(strings.TrimSuffix(fm, "\n\n"), "\n")
+	for i, line := range lines {
+		if (line == frontMatterDelimiter) != (i == 0 || i == len(lines)-1) {
+			t.Errorf("line %d = %q breaks the front matter:\n%s", i, line, fm)
+		}
+	}
+}
+
+func TestYAMLScalar(t *testing.T) {
+	tests := map[string]string{
+		"Bash":          "Bash",
+		"session-1":     "session-1",
+		"a: b":          `"a: b"`,
+		"/home/me/proj": `"/home/me/proj"`,
+		"123":           `"123"`,
+		"2026-01-01":    `"2026-01-01"`,
+		"true":          `"true"`,
+		"No":            `"No"`,
+		"#tag":          `"#tag"`,
+		"tab\there":     `"tab\there"`,
+		"bell\a":        `"bell\x07"`,
+	}
+	for in, want := range tests {
+		if got := yamlScalar(in); got != want {
+			t.Errorf("yamlScalar(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
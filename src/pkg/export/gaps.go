@@ -0,0 +1,68 @@
+package export
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// DefaultGapThreshold is the shortest pause between consecutive entries that
+// ExportOptions.ShowGaps marks when no GapThreshold is set.
+const DefaultGapThreshold = 5 * time.Minute
+
+// gapTracker decides where ExportOptions.ShowGaps markers go: between two consecutive
+// rendered entries that both have a parseable timestamp and are more than the threshold
+// apart.
+type gapTracker struct {
+	enabled   bool
+	threshold time.Duration
+	loc       localizer
+	last      time.Time // Timestamp of the previous entry; zero if it had none
+}
+
+// newGapTracker returns a tracker for opts. It is disabled unless opts.ShowGaps is set.
+func newGapTracker(opts ExportOptions) *gapTracker {
+	threshold := opts.GapThreshold
+	if threshold <= 0 {
+		threshold = DefaultGapThreshold
+	}
+	return &gapTracker{enabled: opts.ShowGaps, threshold: threshold, loc: newLocalizer(opts.Locale)}
+}
+
+// markerFor returns the gap marker to insert before entry, or "" if entry follows the
+// previous entry closely or either of them has no timestamp.
+func (g *gapTracker) markerFor(entry *models.ConversationEntry) string {
+	if !g.enabled || entry == nil {
+		return ""
+	}
+
+	prev := g.last
+	t, err := entry.GetTimestamp()
+	if err != nil {
+		g.last = time.Time{}
+		return ""
+	}
+	g.last = t
+
+	if prev.IsZero() {
+		return ""
+	}
+	gap := t.Sub(prev)
+	if gap <= g.threshold {
+		return ""
+	}
+	return fmt.Sprintf(`<div class="time-gap" role="separator" data-gap-seconds="%d">⏱ %s gap</div>`+"\n",
+		int(gap.Seconds()), g.loc.duration(gap))
+}
+
+// breaksBetween reports whether markerFor would put a marker before next if prev were the
+// entry before it.
+func (g *gapTracker) breaksBetween(prev, next *models.ConversationEntry) bool {
+	if !g.enabled {
+		return false
+	}
+	from, errPrev := prev.GetTimestamp()
+	to, errNext := next.GetTimestamp()
+	return errPrev == nil && errNext == nil && to.Sub(from) > g.threshold
+}
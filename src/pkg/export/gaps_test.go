@@ -0,0 +1,90 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderConversationWithOptions_ShowGaps(t *testing.T) {
+	entries := dayTestEntries(
+		"2026-02-01T10:00:00Z",
+		"2026-02-01T10:03:00Z", // 3m: under the default threshold
+		"2026-02-01T10:15:00Z", // 12m
+		"2026-02-01T12:50:00Z", // 2h 35m
+	)
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{ShowGaps: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+
+	if got := strings.Count(html, `class="time-gap"`); got != 2 {
+		t.Fatalf("gap marker count = %d, want 2", got)
+	}
+
+	// Each marker sits between the two entries it separates
+	order := []string{`data-uuid="e1"`, "⏱ 12m gap", `data-uuid="e2"`, "⏱ 2h 35m gap", `data-uuid="e3"`}
+	last := -1
+	for _, marker := range order {
+		idx := strings.Index(html, marker)
+		if idx <= last {
+			t.Fatalf("%s is out of order", marker)
+		}
+		last = idx
+	}
+	if !strings.Contains(html, `data-gap-seconds="720"`) {
+		t.Error("marker should carry the gap in seconds")
+	}
+}
+
+func TestRenderConversationWithOptions_ShowGapsThreshold(t *testing.T) {
+	entries := dayTestEntries("2026-02-01T10:00:00Z", "2026-02-01T10:03:00Z", "2026-02-01T10:15:00Z")
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{ShowGaps: true, GapThreshold: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(html, `class="time-gap"`); got != 2 {
+		t.Errorf("gap marker count with a 1m threshold = %d, want 2", got)
+	}
+
+	html, err = RenderConversationWithOptions(entries, nil, nil, ExportOptions{GapThreshold: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, `class="time-gap"`) {
+		t.Error("gap markers should only appear with ShowGaps")
+	}
+}
+
+func TestRenderConversationWithOptions_ShowGapsNeedsBothTimestamps(t *testing.T) {
+	entries := dayTestEntries(
+		"2026-02-01T10:00:00Z",
+		"", // no timestamp: no gap before or after it
+		"2026-02-01T11:00:00Z",
+		"not a time",
+		"2026-02-01T12:00:00Z",
+	)
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{ShowGaps: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, `class="time-gap"`) {
+		t.Error("gaps should only be measured between entries that both have timestamps")
+	}
+}
+
+func TestGapTracker_Locale(t *testing.T) {
+	g := newGapTracker(ExportOptions{ShowGaps: true, Locale: "de"})
+	entries := dayTestEntries("2026-02-01T10:00:00Z", "2026-02-01T12:05:00Z")
+
+	if marker := g.markerFor(&entries[0]); marker != "" {
+		t.Errorf("first entry should have no gap, got %q", marker)
+	}
+	marker := g.markerFor(&entries[1])
+	if want := newLocalizer("de").duration(2*time.Hour + 5*time.Minute); !strings.Contains(marker, "⏱ "+want+" gap") {
+		t.Errorf("marker = %q, want localized duration %q", marker, want)
+	}
+}
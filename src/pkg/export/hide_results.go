@@ -0,0 +1,53 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// renderHiddenResultMarker renders what a tool call header shows in place of the link to
+// its result when ExportOptions.HideToolResults leaves the result out: that the call had
+// one, and whether it was an error. A call without a result is marked as usual.
+func renderHiddenResultMarker(result models.ToolResult, hasResult bool) string {
+	switch {
+	case !hasResult:
+		return toolOrphanMarker
+	case result.IsError:
+		return `<span class="tool-result-hidden error" title="This call returned an error; tool results are hidden in this export">error</span>`
+	default:
+		return `<span class="tool-result-hidden" title="Tool results are hidden in this export">result hidden</span>`
+	}
+}
+
+// renderToolCallWithoutResult renders a tool call for ExportOptions.HideToolResults: the
+// header, with renderHiddenResultMarker in place of the result link, and the call's
+// input, but no output pane. An ExitPlanMode call keeps its plan card and approval
+// status, its file paths linked against projectPath. The header follows ro as for
+// renderToolCallWith.
+func renderToolCallWithoutResult(tool models.ToolUse, result models.ToolResult, hasResult bool, projectPath string, ro entryRenderOptions) string {
+	var sb strings.Builder
+
+	plan, isPlan := "", false
+	if tool.Name == planToolName {
+		plan, isPlan = tool.Input["plan"].(string)
+	}
+
+	status := ""
+	if isPlan {
+		text, class := planStatus(result, hasResult)
+		status = fmt.Sprintf(`<span class="plan-status %s">%s</span>`, class, text)
+	}
+	sb.WriteString(renderToolCallHeaderWith(tool, renderHiddenResultMarker(result, hasResult), status, ro))
+
+	if isPlan {
+		sb.WriteString(renderPlanCard(plan, projectPath))
+	} else {
+		sb.WriteString(renderToolInput(tool.Input))
+	}
+
+	sb.WriteString(renderToolCallClose(ro.opts.NoJS))
+
+	return sb.String()
+}
@@ -0,0 +1,66 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRenderEntry_HideToolResults(t *testing.T) {
+	entry := models.ConversationEntry{
+		UUID: "a1",
+		Type: models.EntryTypeAssistant,
+		Message: json.RawMessage(`{"role":"assistant","content":[` +
+			`{"type":"tool_use","id":"toolu_bash","name":"Bash","input":{"command":"go test ./..."}},` +
+			`{"type":"tool_use","id":"toolu_read","name":"Read","input":{"file_path":"missing.go"}},` +
+			`{"type":"tool_use","id":"toolu_edit","name":"Edit","input":{"file_path":"main.go"}},` +
+			`{"type":"tool_use","id":"toolu_plan","name":"ExitPlanMode","input":{"plan":"1. Fix it"}}]}`),
+	}
+	results := map[string]models.ToolResult{
+		"toolu_bash": {ToolUseID: "toolu_bash", Content: "ok  \tpkg\t0.1s NOISY-LOG"},
+		"toolu_read": {ToolUseID: "toolu_read", Content: "File does not exist.", IsError: true},
+		"toolu_plan": {ToolUseID: "toolu_plan", Content: "User has approved your plan."},
+	}
+
+	opts := ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen, HideToolResults: true}
+	html := renderEntryWith(entry, results, "", "", "", "User", "Assistant", entryRenderOptions{opts: opts})
+
+	for _, unwanted := range []string{"NOISY-LOG", "File does not exist.", "User has approved", "tool-output", "tool-pair-link", `id="tool-result-`} {
+		if strings.Contains(html, unwanted) {
+			t.Errorf("hidden results should leave out %q:\n%s", unwanted, html)
+		}
+	}
+	for _, want := range []string{
+		`[Bash] go test ./...`,
+		`<span class="tool-result-hidden" title="Tool results are hidden in this export">result hidden</span>`,
+		`<span class="tool-result-hidden error"`,
+		`<span class="tool-orphan"`,
+		`<section class="plan-card">`,
+		`<span class="plan-status approved">approved</span>`,
+		`tool-input`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("hidden results missing %q:\n%s", want, html)
+		}
+	}
+	if n := strings.Count(html, `class="tool-result-hidden`); n != 3 {
+		t.Errorf("got %d hidden-result markers, want 3 (one per call with a result)", n)
+	}
+
+	// Without the option the outputs are shown
+	html = renderEntryWith(entry, results, "", "", "", "User", "Assistant", entryRenderOptions{opts: ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen}})
+	if !strings.Contains(html, "NOISY-LOG") || strings.Contains(html, "tool-result-hidden") {
+		t.Error("tool results should be shown by default")
+	}
+}
+
+func TestRenderHiddenResultMarker_NoJS(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Read", Input: map[string]any{"file_path": "a.go"}}
+	html := renderToolCallWithoutResult(tool, models.ToolResult{ToolUseID: "toolu_1", Content: "x"}, true, "",
+		entryRenderOptions{opts: ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen, NoJS: true}})
+	if !strings.HasPrefix(html, `<details class="tool-call"`) || !strings.HasSuffix(html, "</details>\n") {
+		t.Errorf("noJS call should collapse as <details>:\n%s", html)
+	}
+}
@@ -0,0 +1,99 @@
+package export
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// highlightClass marks export-time highlights. It differs from the in-page search's
+// "search-highlight" class so clearing a search leaves these marks in place.
+const highlightClass = "export-highlight"
+
+// highlightPattern compiles opts.Highlight into a literal match pattern, or returns nil
+// if no highlight term is set.
+func highlightPattern(opts ExportOptions) *regexp.Regexp {
+	if opts.Highlight == "" {
+		return nil
+	}
+	pattern := regexp.QuoteMeta(opts.Highlight)
+	if opts.HighlightIgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.MustCompile(pattern)
+}
+
+// highlightHTML wraps matches of re in the text of the rendered HTML fragment s with
+// <mark class="export-highlight">. Only text nodes are changed: tags, attributes, and
+// the contents of script, style, and existing mark elements are copied unchanged, so
+// code blocks and links keep their markup. A nil re returns s unchanged.
+func highlightHTML(s string, re *regexp.Regexp) string {
+	if re == nil || s == "" {
+		return s
+	}
+
+	var sb strings.Builder
+	z := xhtml.NewTokenizer(strings.NewReader(s))
+	skipDepth := 0 // nesting depth inside elements whose text is left alone
+	for {
+		switch z.Next() {
+		case xhtml.ErrorToken:
+			if z.Err() != io.EOF {
+				return s
+			}
+			return sb.String()
+		case xhtml.StartTagToken:
+			if name, _ := z.TagName(); isHighlightSkipTag(string(name)) {
+				skipDepth++
+			}
+			sb.Write(z.Raw())
+		case xhtml.EndTagToken:
+			if name, _ := z.TagName(); isHighlightSkipTag(string(name)) && skipDepth > 0 {
+				skipDepth--
+			}
+			sb.Write(z.Raw())
+		case xhtml.TextToken:
+			raw := string(z.Raw())
+			if skipDepth > 0 {
+				sb.WriteString(raw)
+				continue
+			}
+			sb.WriteString(highlightText(raw, re))
+		default:
+			sb.Write(z.Raw())
+		}
+	}
+}
+
+// highlightText marks matches in one escaped text node. Nodes without a match are
+// returned byte-for-byte unchanged.
+func highlightText(raw string, re *regexp.Regexp) string {
+	text := xhtml.UnescapeString(raw)
+	matches := re.FindAllStringIndex(text, -1)
+	if len(matches) == 0 {
+		return raw
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		sb.WriteString(escapeHTML(text[last:m[0]]))
+		sb.WriteString(`<mark class="` + highlightClass + `">`)
+		sb.WriteString(escapeHTML(text[m[0]:m[1]]))
+		sb.WriteString("</mark>")
+		last = m[1]
+	}
+	sb.WriteString(escapeHTML(text[last:]))
+	return sb.String()
+}
+
+// isHighlightSkipTag reports whether text inside the named element must not be highlighted.
+func isHighlightSkipTag(name string) bool {
+	switch name {
+	case "script", "style", "textarea", "mark":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestHighlightHTML(t *testing.T) {
+	caseSensitive := highlightPattern(ExportOptions{Highlight: "go"})
+	ignoreCase := highlightPattern(ExportOptions{Highlight: "go", HighlightIgnoreCase: true})
+
+	tests := []struct {
+		name  string
+		input string
+		opts  ExportOptions
+		want  string
+	}{
+		{"plain text", "<p>let go now</p>", ExportOptions{Highlight: "go"},
+			`<p>let <mark class="export-highlight">go</mark> now</p>`},
+		{"case sensitive by default", "<p>Go and go</p>", ExportOptions{Highlight: "go"},
+			`<p>Go and <mark class="export-highlight">go</mark></p>`},
+		{"ignore case keeps original text", "<p>Go and go</p>", ExportOptions{Highlight: "go", HighlightIgnoreCase: true},
+			`<p><mark class="export-highlight">Go</mark> and <mark class="export-highlight">go</mark></p>`},
+		{"attributes untouched", `<pre><code class="language-go">go build</code></pre>`, ExportOptions{Highlight: "go"},
+			`<pre><code class="language-go"><mark class="export-highlight">go</mark> build</code></pre>`},
+		{"escaped text stays escaped", `<code>if a &lt; go &amp;&amp; b</code>`, ExportOptions{Highlight: "go"},
+			`<code>if a &lt; <mark class="export-highlight">go</mark> &amp;&amp; b</code>`},
+		{"term containing markup characters", `<p>use &lt;T&gt; here</p>`, ExportOptions{Highlight: "<T>"},
+			`<p>use <mark class="export-highlight">&lt;T&gt;</mark> here</p>`},
+		{"link href untouched", `<a href="https://go.dev">go.dev</a>`, ExportOptions{Highlight: "go"},
+			`<a href="https://go.dev"><mark class="export-highlight">go</mark>.dev</a>`},
+		{"no match unchanged", `<p>it&#39;s fine</p>`, ExportOptions{Highlight: "zzz"},
+			`<p>it&#39;s fine</p>`},
+		{"no term unchanged", "<p>go</p>", ExportOptions{},
+			"<p>go</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightHTML(tt.input, highlightPattern(tt.opts)); got != tt.want {
+				t.Errorf("highlightHTML() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if caseSensitive.MatchString("GO") || !ignoreCase.MatchString("GO") {
+		t.Error("highlightPattern() should only ignore case when asked")
+	}
+}
+
+func TestRenderConversation_Highlight(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z",
+			Message: json.RawMessage(`{"role":"user","content":"Why does my goroutine leak?"}`)},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:05Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Each goroutine needs an exit:\n\n` + "```go\\ngo func() { <-done }()\\n```" + `"},{"type":"tool_use","id":"t1","name":"Grep","input":{"pattern":"goroutine"}}]}`)},
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen, Highlight: "goroutine"})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+
+	if n := strings.Count(html, `<mark class="export-highlight">goroutine</mark>`); n != 2 {
+		t.Errorf("expected 2 highlights in message text, got %d", n)
+	}
+	if !strings.Contains(html, "&lt;-done") {
+		t.Error("code block content should stay escaped")
+	}
+	// Tool input is not message text
+	if strings.Contains(html, `"pattern": "<mark`) {
+		t.Error("tool input should not be highlighted")
+	}
+
+	plain, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if strings.Contains(plain, `<mark class="export-highlight">`) {
+		t.Error("no highlight should be added without a term")
+	}
+}
@@ -5,32 +5,80 @@ import (
 	"encoding/json"
 	"fmt"
 	"html"
+	"html/template"
+	"net/url"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/randlee/claude-history/pkg/agent"
 	"github.com/randlee/claude-history/pkg/models"
+	"github.com/randlee/claude-history/pkg/session"
 	"github.com/randlee/claude-history/pkg/version"
 )
 
 // SessionStats contains statistics about a session for display in the header.
 type SessionStats struct {
-	SessionID          string // Full session ID
-	ProjectPath        string // Project directory path
-	SessionFolderPath  string // Full path to session folder (for file:// links)
-	ExportTime         string // Formatted export timestamp (kept for backward compat, not displayed)
-	SessionStart       string // First entry timestamp (formatted for display)
-	SessionEnd         string // Last entry timestamp (formatted for display)
-	Duration           string // Human-readable duration (e.g., "2h 35m")
-	MessageCount       int    // Count of user + assistant messages (deprecated, kept for backward compat)
-	UserMessages       int    // Count of user messages
-	AssistantMessages  int    // Count of assistant messages (main session only)
-	SubagentMessages   int    // Count of all subagent messages
-	AgentCount         int    // Count of subagents
-	TotalAgentMessages int    // Total messages across all subagents
-	ToolCallCount      int    // Count of tool calls
+	SessionID          string   // Full session ID
+	ProjectPath        string   // Project directory path
+	SessionFolderPath  string   // Full path to session folder (for file:// links)
+	ExportTime         string   // Formatted export timestamp (kept for backward compat, not displayed)
+	SessionStart       string   // First entry timestamp (formatted for display)
+	SessionEnd         string   // Last entry timestamp (formatted for display)
+	Duration           string   // Human-readable duration (e.g., "2h 35m")
+	MessageCount       int      // Count of user + assistant messages (deprecated, kept for backward compat)
+	UserMessages       int      // Count of user messages
+	AssistantMessages  int      // Count of assistant messages (main session only)
+	SubagentMessages   int      // Count of all subagent messages
+	AgentCount         int      // Count of subagents
+	TotalAgentMessages int      // Total messages across all subagents
+	ToolCallCount      int      // Count of tool calls
+	Models             []string // Distinct models used by assistant messages, in first-seen order
+	ToolNames          []string // Distinct tools called by assistant messages, in first-seen order
+	IncompleteReason   string   // Why the session looks truncated (see session.SessionCompleteness); empty if complete
+	APIErrorCount      int      // Count of failed API requests (see models.ConversationEntry.IsAPIError)
+	CompactionCount    int      // Times the context was compacted (see session.CompactionCount)
+	Lineage            []string // Sessions this one was resumed from, oldest first (see session.SessionLineage)
+
+	// AgentDescriptions maps subagent IDs to what they were spawned to do (see
+	// agent.TreeNode.Description), for agents whose spawn recorded it.
+	AgentDescriptions map[string]string
+
+	// FailedSpawns maps the IDs of subagents whose spawn failed to the recorded status
+	// (see agent.SpawnFailureStatus), from the agent tree and the session's own entries,
+	// so agents that never got a file are included.
+	FailedSpawns map[string]string
+
+	// AgentParseErrors maps the IDs of subagents whose files are only partially loaded to
+	// the number of lines that could not be parsed (see agent.TreeNode.ParseErrors).
+	AgentParseErrors map[string]int
+
+	// Outline lists the subagents depth-first with the agents they are nested in, for
+	// the sidebar of ExportOptions.Sidebar.
+	Outline []OutlineAgent
+
+	// Preamble holds the context entries the session starts with (see sessionPreamble),
+	// shown in the header with ExportOptions.IncludePreamble.
+	Preamble []models.ConversationEntry
+
+	// FirstPrompt is the full first prompt of the session (see session.FirstPrompt),
+	// shown in the header with ExportOptions.ShowFirstPrompt.
+	FirstPrompt string
+
+	// FilesTouched lists the files the session's tool calls read or changed (see
+	// session.FilesTouched), shown in the header with ExportOptions.ShowFilesTouched.
+	FilesTouched []session.FileActivity
+
+	// ActiveDuration is the part of Duration spent working: the pauses between entries
+	// no longer than the idle threshold (DefaultIdleThreshold or ExportOptions.IdleThreshold).
+	ActiveDuration string
+
+	start          time.Time     // First entry timestamp, unformatted (see SessionStart)
+	duration       time.Duration // Measured session duration, for localized formatting of Duration
+	activeDuration time.Duration // Measured active duration, for localized formatting of ActiveDuration
 }
 
 // ExportFormatVersion is the current version of the export format.
@@ -133,7 +181,7 @@ func RenderQueryResults(entries []models.ConversationEntry, projectPath, session
 
 	for _, entry := range entries {
 		// Skip entries with no meaningful content
-		if !hasContent(entry) {
+		if !hasContent(entry) && !entry.IsInterruption() && !entry.IsAPIError() && !entry.IsStopNotice() {
 			continue
 		}
 
@@ -177,55 +225,315 @@ func RenderQueryResults(entries []models.ConversationEntry, projectPath, session
 // stats contains optional session statistics for the header (if nil, stats are computed from entries/agents).
 // This function uses "User" and "Assistant" as role labels for full session exports.
 func RenderConversationWithStats(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats) (string, error) {
-	var sb strings.Builder
+	return RenderConversationWithOptions(entries, agents, stats, ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen})
+}
+
+// RenderConversationWithOptions generates a complete HTML page like RenderConversationWithStats,
+// applying the rendering settings in opts (e.g., relative timestamps). If opts.TemplateFile is
+// set, the page layout comes from that html/template file instead of the built-in layout.
+func RenderConversationWithOptions(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats, opts ExportOptions) (string, error) {
+	entries, agents, stats, opts = stripIDs(entries, agents, stats, opts)
 
 	// Calculate stats if not provided
 	if stats == nil {
 		stats = ComputeSessionStats(entries, agents)
 	}
+	stats = statsWithIdleThreshold(stats, entries, opts)
+
+	if err := ValidateTypeColors(opts.TypeColors); err != nil {
+		return "", err
+	}
+	entries, opts, err := transcodeToolOutput(entries, opts)
+	if err != nil {
+		return "", err
+	}
 
 	// Build a map of agent IDs to entry counts for subagent display and tooltip
 	agentMap := buildAgentMap(agents)
 
-	// Write HTML header with metadata and agent details
-	sb.WriteString(renderHTMLHeader(stats, agentMap))
+	blocks := renderConversationBlocks(entries, agentMap, stats, opts)
 
-	// Write conversation entries
-	sb.WriteString(`<div class="conversation">` + "\n")
+	// Wrap the conversation entries
+	var sb strings.Builder
+	if opts.Paginate {
+		sb.WriteString(`<div class="conversation paginated">` + "\n")
+	} else {
+		sb.WriteString(`<div class="conversation">` + "\n")
+	}
+	for _, block := range blocks {
+		sb.WriteString(string(block.HTML))
+	}
+	sb.WriteString("</div>\n")
+	if opts.SearchIndex && !opts.NoJS {
+		sb.WriteString(renderSearchIndex(blocks))
+	}
 
-	// Track tool results for matching with tool calls
-	toolResults := buildToolResultsMap(entries)
+	layout, err := loadLayoutTemplate(opts.TemplateFile)
+	if err != nil {
+		return "", err
+	}
+	loc := newLocalizer(opts.Locale)
+
+	// Header with metadata and agent details; footer with info and keyboard shortcuts
+	return executeLayout(layout, LayoutData{
+		Stats:         stats,
+		Agents:        agents,
+		Entries:       blocks,
+		FormatVersion: ExportFormatVersion,
+		Header:        template.HTML(renderHTMLHeaderWith(stats, agentMap, loc, opts)),
+		Timeline:      template.HTML(renderAgentTimeline(opts.Timeline, loc)),
+		Conversation:  template.HTML(sb.String()),
+		Footer:        template.HTML(renderHTMLFooterWith(stats, opts)),
+	})
+}
+
+// compactionDividerHTML marks where Claude Code compacted the context; the messages after
+// it continue from a summary of those before.
+const compactionDividerHTML = `<div class="compaction-divider" role="separator" title="Earlier messages were summarized to free up context">— context compacted here —</div>` + "\n"
+
+// renderConversationBlocks renders the conversation entries, subagent placeholders, and
+// print page breaks in display order.
+func renderConversationBlocks(entries []models.ConversationEntry, agentMap map[string]int, stats *SessionStats, opts ExportOptions) []RenderedEntry {
+	return renderConversationBlocksWith(entries, entries, agentMap, stats, opts, true)
+}
+
+// renderConversationBlocksWith renders entries, one page of session (see ExportOptions.PageSize).
+// Tool calls are paired with their results, and short agent IDs are chosen, across the whole
+// session. listOverflow adds the list of agents beyond opts.MaxAgents at the end.
+func renderConversationBlocksWith(entries, session []models.ConversationEntry, agentMap map[string]int, stats *SessionStats, opts ExportOptions, listOverflow bool) []RenderedEntry {
+	var blocks []RenderedEntry
+	days := newDayTracker(session, opts)
+	gaps := newGapTracker(opts)
+	add := func(kind string, entry *models.ConversationEntry, content string) {
+		if marker := gaps.markerFor(entry); marker != "" {
+			blocks = append(blocks, RenderedEntry{Kind: BlockGap, Timestamp: entry.Timestamp, HTML: template.HTML(marker)})
+		}
+		if separator := days.separatorFor(entry); separator != "" {
+			blocks = append(blocks, RenderedEntry{Kind: BlockDay, Timestamp: entry.Timestamp, HTML: template.HTML(separator)})
+		}
+		block := RenderedEntry{Kind: kind, HTML: template.HTML(content)}
+		if entry != nil {
+			block.UUID = entry.UUID
+			block.Type = entry.Type
+			block.Timestamp = entry.Timestamp
+		}
+		blocks = append(blocks, block)
+	}
+
+	// Track tool results for matching with tool calls, and calls for spotting orphan results
+	toolResults := opts.ToolIndex.resultsMap(session)
+	toolCallIDs := opts.ToolIndex.callIDSet(session)
+
+	// Settings shared by every entry on the page
+	baseRender := entryRenderOptions{opts: opts, now: referenceTime(session, opts), defaultModel: predominantModel(session), highlight: highlightPattern(opts),
+		shortIDs: ShortenIDs(sessionAgentIDs(session, agentMap)), agentAnchors: subagentAnchors(entries, agentMap), shells: buildBackgroundShells(session, toolResults),
+		rereads: buildRepeatedReads(session, opts)}
+
+	// Print pagination: break before every Nth message and before each subagent section
+	pageBreakEvery := opts.PageBreakEvery
+	if pageBreakEvery <= 0 {
+		pageBreakEvery = defaultPageBreakEvery
+	}
+	messagesOnPage := 0
+	beforeMessage := func() {
+		if !opts.Paginate {
+			return
+		}
+		if messagesOnPage >= pageBreakEvery {
+			add(BlockPageBreak, nil, pageBreakHTML)
+			messagesOnPage = 0
+		}
+		messagesOnPage++
+	}
+	beforeSubagent := func() {
+		if opts.Paginate && messagesOnPage > 0 {
+			add(BlockPageBreak, nil, pageBreakHTML)
+			messagesOnPage = 0
+		}
+	}
+	// Agents beyond opts.MaxAgents get no placeholder; they are listed at the end instead
+	overflow := overflowAgents(agentMap, opts.MaxAgents)
+	hiddenAgents := make(map[string]bool, len(overflow))
+	for _, id := range overflow {
+		hiddenAgents[id] = true
+	}
+	durations := agentDurations(opts.AgentSpans)
+	addSubagent := func(entry *models.ConversationEntry) {
+		if hiddenAgents[entry.AgentID] {
+			return
+		}
+		beforeSubagent()
+		add(BlockSubagent, entry, renderSubagentPlaceholderWith(entry.AgentID, agentMap, stats.SessionID, stats.ProjectPath, subagentDetails{
+			description: stats.AgentDescriptions[entry.AgentID],
+			duration:    durations[entry.AgentID],
+			spawnStatus: stats.FailedSpawns[entry.AgentID],
+			parseErrors: stats.AgentParseErrors[entry.AgentID],
+			content:     renderInlineAgent(entry.AgentID, opts),
+		}, baseRender))
+	}
+
+	// With IncludePreamble, the context entries are shown in the header instead
+	var preamble map[string]bool
+	if opts.IncludePreamble && stats != nil {
+		preamble = preambleUUIDs(stats.Preamble)
+	}
+
+	// Sources from the most recent WebSearch, consumed by the next assistant text
+	var pendingSources []string
+
+	// Consecutive TodoWrite-only entries are buffered and collapsed into one checklist
+	var todoRun []models.ConversationEntry
+	flushTodoRun := func() {
+		var calls []models.ToolUse
+		for _, e := range todoRun {
+			calls = append(calls, e.ExtractToolCalls()...)
+		}
+		if len(calls) > 0 {
+			beforeMessage()
+		}
+		if len(calls) == 1 {
+			add(BlockMessage, &todoRun[0], renderEntryWith(todoRun[0], toolResults, stats.ProjectPath, "", "", sessionUserLabel, sessionAssistantLabel, baseRender))
+		} else if len(calls) > 1 {
+			add(BlockTodos, &todoRun[0], renderTodoEvolutionWith(todoRun[0], calls, baseRender))
+		}
+		todoRun = nil
+	}
+
+	// citationsFor returns the WebSearch sources that entry's text may cite, updating
+	// pendingSources for the turn
+	citationsFor := func(entry *models.ConversationEntry) []string {
+		var citationSources []string
+		if entry.Type == models.EntryTypeAssistant && strings.TrimSpace(entry.GetTextContent()) != "" {
+			citationSources = pendingSources
+			pendingSources = nil
+		} else if entry.Type == models.EntryTypeUser {
+			// A new user prompt starts a new turn; stale sources no longer apply
+			pendingSources = nil
+		}
+		return citationSources
+	}
+
+	// A compaction is marked once, at its boundary, or at its summary when older
+	// versions recorded no boundary
+	afterCompaction := false
+
+	for i := 0; i < len(entries); i++ {
+		entry := &entries[i]
+		if preamble[entry.UUID] {
+			continue
+		}
+
+		boundary := entry.IsCompactionBoundary()
+		if boundary || (entry.IsCompactionSummary() && !afterCompaction) {
+			flushTodoRun()
+			add(BlockCompaction, entry, compactionDividerHTML)
+		}
+		afterCompaction = boundary
+		if boundary {
+			continue
+		}
 
-	for _, entry := range entries {
 		// Skip entries with no meaningful content
-		if !hasContent(entry) {
+		if !hasContent(*entry) && !entry.IsInterruption() && !entry.IsAPIError() && !entry.IsStopNotice() {
+			orphans := orphanToolResults(*entry, toolCallIDs)
+			// With ShowAll, hidden entries get a debug row (orphans are shown below)
+			if opts.ShowAll && len(orphans) == 0 {
+				flushTodoRun()
+				add(BlockDebug, entry, renderDebugRow(*entry, baseRender))
+			}
 			// Still render subagent placeholder if this entry spawned one
 			if entry.Type == models.EntryTypeQueueOperation && entry.AgentID != "" {
-				subagentHTML := renderSubagentPlaceholder(entry.AgentID, agentMap, stats.SessionID, stats.ProjectPath)
-				sb.WriteString(subagentHTML)
+				flushTodoRun()
+				addSubagent(entry)
+			}
+			// Results are normally shown with their call; show orphans on their own
+			if len(orphans) > 0 {
+				flushTodoRun()
+				add(BlockMessage, entry, renderOrphanToolResults(*entry, orphans))
 			}
 			continue
 		}
 
+		if isTodoWriteOnly(*entry) {
+			todoRun = append(todoRun, *entry)
+			continue
+		}
+		flushTodoRun()
+
+		// Optionally render a multi-entry assistant turn as one bubble
+		if opts.CombineToolMessages {
+			turn := cutTurnAt(entries, assistantTurn(entries, i, toolCallIDs), func(prev, next *models.ConversationEntry) bool {
+				return gaps.breaksBetween(prev, next) || days.breaksBetween(prev, next)
+			})
+			if len(turn) > 1 {
+				parts := make([]string, len(turn))
+				for k, idx := range turn {
+					ro := baseRender
+					ro.citationSources = citationsFor(&entries[idx])
+					parts[k] = renderEntryContent(entries[idx], toolResults, stats.ProjectPath, ro)
+					if sources := collectWebSearchSources(entries[idx], toolResults); len(sources) > 0 {
+						pendingSources = sources
+					}
+				}
+				beforeMessage()
+				add(BlockMessage, entry, renderCombinedTurn(entries, turn, parts, toolResults, stats.ProjectPath, "Assistant", baseRender))
+				i = turn[len(turn)-1]
+				continue
+			}
+		}
+
 		// For full conversation exports, pass empty strings for sessionID/agentID (not a filtered query)
-		entryHTML := renderEntry(entry, toolResults, stats.ProjectPath, "", "", "User", "Assistant")
-		sb.WriteString(entryHTML)
+		ro := baseRender
+		ro.citationSources = citationsFor(entry)
+		beforeMessage()
+		add(BlockMessage, entry, renderEntryWith(*entry, toolResults, stats.ProjectPath, "", "", sessionUserLabel, sessionAssistantLabel, ro))
+
+		// A user entry mixing text and tool results shows its text above; results with a
+		// call are shown with it, and the rest on their own
+		if orphans := orphanToolResults(*entry, toolCallIDs); len(orphans) > 0 {
+			add(BlockMessage, entry, renderOrphanToolResults(*entry, orphans))
+		}
+
+		if sources := collectWebSearchSources(*entry, toolResults); len(sources) > 0 {
+			pendingSources = sources
+		}
 
 		// Check if this entry spawned a subagent
 		if entry.Type == models.EntryTypeQueueOperation && entry.AgentID != "" {
-			subagentHTML := renderSubagentPlaceholder(entry.AgentID, agentMap, stats.SessionID, stats.ProjectPath)
-			sb.WriteString(subagentHTML)
+			addSubagent(entry)
 		}
 	}
+	flushTodoRun()
 
-	sb.WriteString("</div>\n")
+	if listOverflow && len(overflow) > 0 {
+		add(BlockAgentOverflow, nil, renderAgentOverflow(overflow, agentMap, stats.SessionID, stats.ProjectPath, baseRender.shortIDs))
+	}
 
-	// Write HTML footer with info and keyboard shortcuts
-	sb.WriteString(renderHTMLFooter(stats))
+	return blocks
+}
 
-	return sb.String(), nil
+// renderInlineAgent renders the conversation of subagent agentID from opts.AgentEntries for
+// its section in a NoJS export, or returns "" otherwise. The agent's entries come from its
+// own file, so links to opts.RawSource lines would point into the wrong file and are left out.
+func renderInlineAgent(agentID string, opts ExportOptions) string {
+	if !opts.NoJS {
+		return ""
+	}
+	opts.RawSource = ""
+	opts.ToolIndex = nil
+	content, err := RenderAgentFragmentWithOptions(agentID, opts.AgentEntries[agentID], opts)
+	if err != nil {
+		return ""
+	}
+	return content
 }
 
+// defaultPageBreakEvery is the number of messages per printed page when paginating.
+const defaultPageBreakEvery = 20
+
+// pageBreakHTML is the marker inserted between printed pages. It is invisible on screen.
+const pageBreakHTML = `<div class="page-break" aria-hidden="true"></div>` + "\n"
+
 // ComputeSessionStats calculates statistics from entries and agents.
 func ComputeSessionStats(entries []models.ConversationEntry, agents []*agent.TreeNode) *SessionStats {
 	stats := &SessionStats{
@@ -241,6 +549,7 @@ func ComputeSessionStats(entries []models.ConversationEntry, agents []*agent.Tre
 				if t, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
 					firstTime = t
 					stats.SessionStart = firstTime.Format("2006-01-02 15:04")
+					stats.start = t
 					break
 				}
 			}
@@ -262,6 +571,9 @@ func ComputeSessionStats(entries []models.ConversationEntry, agents []*agent.Tre
 		if !firstTime.IsZero() && !lastTime.IsZero() {
 			duration := lastTime.Sub(firstTime)
 			stats.Duration = formatDuration(duration)
+			stats.duration = duration
+			stats.activeDuration = activeDuration(entries, DefaultIdleThreshold)
+			stats.ActiveDuration = formatDuration(stats.activeDuration)
 		}
 	}
 
@@ -277,13 +589,30 @@ func ComputeSessionStats(entries []models.ConversationEntry, agents []*agent.Tre
 			// Count tool calls from assistant messages
 			tools := entry.ExtractToolCalls()
 			stats.ToolCallCount += len(tools)
+			for _, tool := range tools {
+				if !slices.Contains(stats.ToolNames, tool.Name) {
+					stats.ToolNames = append(stats.ToolNames, tool.Name)
+				}
+			}
+			if model := entry.ModelName(); model != "" && !slices.Contains(stats.Models, model) {
+				stats.Models = append(stats.Models, model)
+			}
 		}
-		// Extract session ID from first entry if available
-		if stats.SessionID == "" && entry.SessionID != "" {
-			stats.SessionID = entry.SessionID
+		if entry.IsAPIError() {
+			stats.APIErrorCount++
 		}
 	}
 
+	// A resumed session starts with entries carried over from its parent sessions, so
+	// the session itself is the one of the last entries
+	stats.SessionID = session.CurrentSessionID(entries)
+	stats.Lineage = session.SessionLineage(entries)
+	stats.CompactionCount = session.CompactionCount(entries)
+	stats.Preamble = sessionPreamble(entries)
+	stats.FirstPrompt = session.FirstPrompt(entries)
+	stats.FilesTouched = session.FilesTouched(entries)
+	stats.FailedSpawns = buildFailedSpawns(entries, agents)
+
 	// Count agents and subagent messages
 	if len(agents) > 0 {
 		agentMap := buildAgentMap(agents)
@@ -294,25 +623,47 @@ func ComputeSessionStats(entries []models.ConversationEntry, agents []*agent.Tre
 			stats.TotalAgentMessages += count
 		}
 		stats.SubagentMessages = stats.TotalAgentMessages
+		stats.AgentDescriptions = buildAgentDescriptions(agents)
+		stats.AgentParseErrors = buildAgentParseErrors(agents)
+		stats.Outline = buildAgentOutline(agents)
+	}
+
+	if complete, reason := session.SessionCompleteness(entries); !complete {
+		stats.IncompleteReason = reason
 	}
 
 	return stats
 }
 
-// formatDuration formats a duration into a human-readable string.
-// Examples: "2h 35m", "45m", "30s"
-func formatDuration(d time.Duration) string {
-	hours := int(d.Hours())
-	minutes := int(d.Minutes()) % 60
+// sessionLineageSeparator separates the sessions of the lineage breadcrumb.
+const sessionLineageSeparator = ` <span class="lineage-separator" aria-hidden="true">›</span> `
 
-	if hours > 0 {
-		return fmt.Sprintf("%dh %dm", hours, minutes)
-	}
-	if minutes > 0 {
-		return fmt.Sprintf("%dm", minutes)
+// renderSessionLineage renders the breadcrumb of the sessions the exported session was
+// resumed from, oldest first, ending with the exported session itself. Each ancestor
+// links to where its own export would be (see sessionExportLink).
+func renderSessionLineage(lineage []string) string {
+	var sb strings.Builder
+	sb.WriteString(`<nav class="meta-item session-lineage" aria-label="Session lineage">Resumed from: `)
+	for _, id := range lineage {
+		sb.WriteString(fmt.Sprintf(`<a href="%s" class="lineage-link" title="%s"><code>%s</code></a>`,
+			escapeHTML(sessionExportLink(id)), escapeHTML(id), escapeHTML(truncateID(id, 8))))
+		sb.WriteString(sessionLineageSeparator)
 	}
-	seconds := int(d.Seconds())
-	return fmt.Sprintf("%ds", seconds)
+	sb.WriteString(`<span class="lineage-current" aria-current="page">this session</span></nav>`)
+	return sb.String()
+}
+
+// sessionExportLink returns the relative link from an HTML export to the export of
+// session sessionID, assuming sessions are exported side by side into directories named
+// by their session ID (e.g. --output exports/<session-id>).
+func sessionExportLink(sessionID string) string {
+	return "../" + url.PathEscape(sessionID) + "/index.html"
+}
+
+// formatDuration formats a duration into a human-readable string.
+// Examples: "2h 35m", "45m", "30s". See localizer.duration for other locales.
+func formatDuration(d time.Duration) string {
+	return formatDurationWith(fmt.Sprintf, d)
 }
 
 // truncateID truncates an ID to the specified length.
@@ -334,24 +685,57 @@ func TruncateSessionID(sessionID string) string {
 // RenderAgentFragment generates an HTML fragment for a subagent's conversation.
 // This is used for lazy loading subagent content.
 func RenderAgentFragment(agentID string, entries []models.ConversationEntry) (string, error) {
+	return RenderAgentFragmentWithOptions(agentID, entries, ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen})
+}
+
+// RenderAgentFragmentWithOptions generates a subagent fragment like RenderAgentFragment,
+// applying the rendering settings in opts.
+func RenderAgentFragmentWithOptions(agentID string, entries []models.ConversationEntry, opts ExportOptions) (string, error) {
+	if opts.StripIDs {
+		if opts.IDMap == nil {
+			opts.IDMap = NewIDMapper()
+		}
+		agentID = opts.IDMap.Agent(agentID)
+		entries = stripEntryIDs(entries, opts.IDMap)
+		opts = stripOptionIDs(opts, opts.IDMap)
+	}
+	entries, opts, err := transcodeToolOutput(entries, opts)
+	if err != nil {
+		return "", err
+	}
+
 	var sb strings.Builder
+	// Track tool results for this agent's entries, and calls for spotting orphan results
+	toolResults := opts.ToolIndex.resultsMap(entries)
+	toolCallIDs := opts.ToolIndex.callIDSet(entries)
 
-	// Track tool results for this agent's entries
-	toolResults := buildToolResultsMap(entries)
+	ro := entryRenderOptions{opts: opts, now: referenceTime(entries, opts), defaultModel: predominantModel(entries), highlight: highlightPattern(opts),
+		shells: buildBackgroundShells(entries, toolResults), rereads: buildRepeatedReads(entries, opts)}
 
 	for _, entry := range entries {
-		// Skip entries with no meaningful content
-		if !hasContent(entry) {
+		// Skip entries with no meaningful content, but keep results whose call is missing
+		if !hasContent(entry) && !entry.IsInterruption() && !entry.IsAPIError() && !entry.IsStopNotice() {
+			if orphans := orphanToolResults(entry, toolCallIDs); len(orphans) > 0 {
+				sb.WriteString(renderOrphanToolResults(entry, orphans))
+			}
 			continue
 		}
 
 		// RenderAgentFragment doesn't have access to ProjectPath or session context
 		// Use "User"/"Assistant" labels for agent fragments (they're viewed in context of the full export)
 		// Pass empty strings for sessionID/agentID since this is used for lazy-loaded fragments
-		entryHTML := renderEntry(entry, toolResults, "", "", "", "User", "Assistant")
+		entryHTML := renderEntryWith(entry, toolResults, "", "", "", sessionUserLabel, sessionAssistantLabel, ro)
 		sb.WriteString(entryHTML)
+
+		// Results in a text+result user entry whose call is missing still get shown
+		if orphans := orphanToolResults(entry, toolCallIDs); len(orphans) > 0 {
+			sb.WriteString(renderOrphanToolResults(entry, orphans))
+		}
 	}
 
+	if opts.Minify {
+		return minifyHTML(sb.String()), nil
+	}
 	return sb.String(), nil
 }
 
@@ -408,11 +792,51 @@ func hasContent(entry models.ConversationEntry) bool {
 //
 // userLabel and assistantLabel specify the role names to display (e.g., "User"/"Assistant" or "Orchestrator"/"Agent").
 func renderEntry(entry models.ConversationEntry, toolResults map[string]models.ToolResult, projectPath, sessionID, agentID, userLabel, assistantLabel string) string {
+	return renderEntryWith(entry, toolResults, projectPath, sessionID, agentID, userLabel, assistantLabel,
+		entryRenderOptions{opts: ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen}})
+}
+
+// entryRenderOptions carries optional rendering inputs for a single entry.
+// renderEntry uses the zero value with opts.SummaryMaxLen set to DefaultSummaryMaxLen.
+type entryRenderOptions struct {
+	opts            ExportOptions     // Export-wide rendering settings
+	now             time.Time         // Reference time for relative timestamps
+	citationSources []string          // WebSearch sources for [n] markers (nil disables citation linking)
+	defaultModel    string            // Most common model on the page; assistant entries using another model get a badge
+	highlight       *regexp.Regexp    // Term to pre-mark in message text (nil disables highlighting)
+	shortIDs        map[string]string // Display forms of the page's agent IDs (see ShortenIDs); nil uses agent.NormalizeAgentID
+	agentAnchors    map[string]bool   // Agents with a subagent section on the page; their ID badges link to it
+	shells          backgroundShells  // Background shells of the page, for correlating their polls (see buildBackgroundShells)
+	rereads         repeatedReads     // Reads of files read more than once in the conversation (see buildRepeatedReads)
+}
+
+// renderEntryWith renders an entry like renderEntry, applying the given per-entry options.
+func renderEntryWith(entry models.ConversationEntry, toolResults map[string]models.ToolResult, projectPath, sessionID, agentID, userLabel, assistantLabel string, ro entryRenderOptions) string {
 	var sb strings.Builder
 
 	// Get text content
 	textContent := entry.GetTextContent()
 
+	// Interruptions and cancelled tool calls get an inline marker instead of a bubble
+	if entry.IsInterruption() {
+		return renderInterruption(entry, ro)
+	}
+
+	// Failed API requests (rate limits, overloads) get a warning marker
+	if entry.IsAPIError() {
+		return renderAPIError(entry, ro)
+	}
+
+	// Responses cut off or refused before producing anything get an informational marker
+	if entry.IsStopNotice() {
+		return renderStopNotice(entry, ro)
+	}
+
+	// Slash commands (/compact, /clear) get a compact chip instead of a bubble
+	if name, ok := entry.SlashCommand(); ok {
+		return renderSlashCommand(entry, name, ro)
+	}
+
 	// Detect task-notification blocks and render with flattened structure
 	isTaskNotif := entry.Type == models.EntryTypeUser && strings.Contains(textContent, "<task-notification>")
 	if isTaskNotif {
@@ -445,10 +869,7 @@ func renderEntry(entry models.ConversationEntry, toolResults map[string]models.T
 			originalValue := displayValue
 
 			// Truncate if too long for inline display
-			const maxInlineLen = 60
-			if len(displayValue) > maxInlineLen {
-				displayValue = displayValue[:maxInlineLen-3] + "..."
-			}
+			displayValue = truncateSummary(displayValue, ro.opts.SummaryMaxLen)
 			toolSummary = displayValue
 
 			// For file path tools (Read, Write, Edit), make the path clickable
@@ -476,11 +897,19 @@ func renderEntry(entry models.ConversationEntry, toolResults map[string]models.T
 	if isToolOnly {
 		toolOnlyClass = " tool-only"
 	}
+	// With replay, messages start hidden and controls.js reveals them one at a time
+	if ro.opts.ReplayMode && !ro.opts.NoJS {
+		toolOnlyClass += " " + replayHiddenClass
+	}
+	annotation, annotated := ro.opts.Annotations[entry.UUID]
+	if annotated {
+		toolOnlyClass += " annotated"
+	}
 	sb.WriteString(fmt.Sprintf(`<div class="message-row %s%s" data-uuid="%s">`, entryClass, toolOnlyClass, escapeHTML(entry.UUID)))
 	sb.WriteString("\n")
 
-	// Avatar placeholder
-	sb.WriteString(fmt.Sprintf(`  <div class="avatar %s" aria-hidden="true"></div>`, entryClass))
+	// Avatar
+	sb.WriteString("  " + renderAvatar(entry.Type, ro.opts.Avatars))
 	sb.WriteString("\n")
 
 	// Message bubble
@@ -496,6 +925,13 @@ func renderEntry(entry models.ConversationEntry, toolResults map[string]models.T
 		roleClass = "role tool-only-label"
 	}
 	sb.WriteString(fmt.Sprintf(`<span class="%s">%s</span>`, roleClass, escapeHTML(roleLabel)))
+	sb.WriteString(renderModelBadge(entry, ro.defaultModel))
+	if annotated {
+		sb.WriteString(annotationMarker)
+	}
+	if entry.HasRevisions() {
+		sb.WriteString(revisionMarker)
+	}
 
 	// Add inline tool summary if present
 	if toolSummary != "" {
@@ -511,47 +947,97 @@ func renderEntry(entry models.ConversationEntry, toolResults map[string]models.T
 	// Determine which agent ID to display
 	displayAgentID := determineDisplayAgentID(entry, sessionID, agentID)
 	if displayAgentID != "" {
-		sb.WriteString(renderAgentIDWithCopy(entry, displayAgentID, sessionID, agentID, projectPath, roleLabel))
+		sb.WriteString(renderAgentIDWithCopyWith(entry, displayAgentID, sessionID, agentID, projectPath, roleLabel, ro.shortIDs, ro.agentAnchors))
 	}
 
-	sb.WriteString(fmt.Sprintf(` <span class="timestamp">%s</span>`, escapeHTML(timestamp)))
+	sb.WriteString(renderTimestampSpan(entry.Timestamp, timestamp, ro))
+	sb.WriteString(renderRawLink(entry, ro))
+	sb.WriteString(renderCopyMessageButton(entry, toolResults, userLabel, assistantLabel))
 	sb.WriteString("</div>\n")
 
 	// Message content
 	sb.WriteString(`    <div class="message-content">`)
+	sb.WriteString(renderEntryContent(entry, toolResults, projectPath, ro))
+	sb.WriteString("</div>\n") // Close message-content
+	sb.WriteString(renderRevisions(entry))
+	if annotated {
+		sb.WriteString(renderAnnotation(annotation))
+	}
+	sb.WriteString(renderRawInspector(entry, "", ro))
+	sb.WriteString("  </div>\n") // Close message-bubble
+	sb.WriteString("</div>\n")   // Close message-row
+
+	return sb.String()
+}
+
+// renderEntryContent renders the body of a message bubble: the entry's text followed by
+// its tool calls (assistant entries only).
+func renderEntryContent(entry models.ConversationEntry, toolResults map[string]models.ToolResult, projectPath string, ro entryRenderOptions) string {
+	var sb strings.Builder
+	textContent := entry.GetTextContent()
 
 	if textContent != "" {
 		if entry.Type == models.EntryTypeAssistant {
 			// Apply markdown rendering for assistant messages (with file path detection)
-			sb.WriteString(fmt.Sprintf(`<div class="text markdown-content">%s</div>`, RenderMarkdown(textContent, projectPath)))
+			sb.WriteString(fmt.Sprintf(`<div class="text markdown-content">%s</div>`, highlightHTML(renderMarkdownWith(textContent, projectPath, markdownOptions{citationSources: ro.citationSources, emojiShortcodes: ro.opts.EmojiShortcodes, collapseCodeLines: ro.opts.CollapseCodeLines, allowSafeHTML: ro.opts.AllowSafeHTML}), ro.highlight)))
 		} else {
 			// Regular user message - format XML tags for better display
-			sb.WriteString(fmt.Sprintf(`<div class="text user-content">%s</div>`, formatUserContent(textContent)))
+			sb.WriteString(fmt.Sprintf(`<div class="text user-content">%s</div>`, highlightHTML(formatUserContentWith(textContent, projectPath), ro.highlight)))
 		}
 	}
 
 	// Render tool calls for assistant messages
 	if entry.Type == models.EntryTypeAssistant {
 		tools := entry.ExtractToolCalls()
+		grouped := ro.opts.GroupParallelTools && len(tools) > 1
+		if grouped {
+			sb.WriteString(renderParallelToolsHeader(len(tools)))
+		}
 		for _, tool := range tools {
 			toolResult, hasResult := toolResults[tool.ID]
-			toolHTML := renderToolCall(tool, toolResult, hasResult)
-			sb.WriteString(toolHTML)
+			var toolHTML string
+			switch {
+			case ro.opts.HideToolResults:
+				toolHTML = renderToolCallWithoutResult(tool, toolResult, hasResult, projectPath, ro)
+			case isShellTool(tool.Name) && shellToolID(tool.Input) != "":
+				toolHTML = renderShellToolCall(tool, toolResult, hasResult, ro)
+			default:
+				toolHTML = renderToolCallWith(tool, toolResult, hasResult, projectPath, ro)
+			}
+			switch reread := ro.rereads[tool.ID]; {
+			case reread == nil:
+				sb.WriteString(toolHTML)
+			case reread.n == 1:
+				sb.WriteString(toolHTML)
+				sb.WriteString(renderRereadLine(reread))
+			default:
+				sb.WriteString(renderReread(reread, toolHTML))
+			}
+			if shell := ro.shells.forCall(tool); shell != nil {
+				sb.WriteString(renderBackgroundShellLine(shell))
+			}
+		}
+		if grouped {
+			sb.WriteString("</div>\n") // Close parallel-tools
 		}
 	}
 
-	sb.WriteString("</div>\n")   // Close message-content
-	sb.WriteString("  </div>\n") // Close message-bubble
-	sb.WriteString("</div>\n")   // Close message-row
-
 	return sb.String()
 }
 
+// renderParallelToolsHeader opens the group holding the n tool calls an entry made at
+// once (see ExportOptions.GroupParallelTools). The caller writes the calls and closes it.
+func renderParallelToolsHeader(n int) string {
+	return fmt.Sprintf(`<div class="parallel-tools" data-tool-count="%d">`+"\n"+
+		`  <div class="parallel-tools-header"><span class="parallel-tools-icon" aria-hidden="true">⇉</span>Parallel tools (%d)</div>`+"\n", n, n)
+}
+
 // determineDisplayAgentID determines which agent ID should be displayed for a message.
 // For main session queries (agentID == ""), it returns entry.AgentID.
 // For subagent queries (agentID != ""):
 //   - User/Orchestrator messages: return sessionID (the parent orchestrator)
-//   - Assistant/Agent messages: return agentID (the subagent responding)
+//   - Assistant/Agent messages: return the entry's own agent ID if set (a nested agent
+//     in an agent subtree export), otherwise agentID (the subagent responding)
 func determineDisplayAgentID(entry models.ConversationEntry, sessionID, agentID string) string {
 	// If this isn't a subagent query, use the entry's agent ID
 	if agentID == "" {
@@ -564,7 +1050,10 @@ func determineDisplayAgentID(entry models.ConversationEntry, sessionID, agentID
 		// User/Orchestrator messages come from the parent session
 		return sessionID
 	case models.EntryTypeAssistant:
-		// Assistant/Agent messages come from the subagent
+		// Assistant/Agent messages come from the subagent (or one of its descendants)
+		if entry.AgentID != "" {
+			return entry.AgentID
+		}
 		return agentID
 	default:
 		// For other types, use entry's agent ID if available
@@ -580,14 +1069,7 @@ func buildSessionCopyContext(sessionID, projectPath, agentID string) string {
 	}
 
 	var sb strings.Builder
-
-	// Session identification
-	sb.WriteString(fmt.Sprintf("Session: %s\n", sessionID))
-
-	// Add project path if available
-	if projectPath != "" {
-		sb.WriteString(fmt.Sprintf("Project: %s\n", projectPath))
-	}
+	writeSessionIdentity(&sb, sessionID, projectPath)
 
 	// Build CLI command
 	pathArg := projectPath
@@ -606,6 +1088,55 @@ func buildSessionCopyContext(sessionID, projectPath, agentID string) string {
 	return sb.String()
 }
 
+// writeSessionIdentity writes the "Session:" and (if known) "Project:" lines that open
+// every session copy context.
+func writeSessionIdentity(sb *strings.Builder, sessionID, projectPath string) {
+	sb.WriteString(fmt.Sprintf("Session: %s\n", sessionID))
+	if projectPath != "" {
+		sb.WriteString(fmt.Sprintf("Project: %s\n", projectPath))
+	}
+}
+
+// buildFullSessionContext builds the "share context" text for the whole session: the
+// session and project (as in buildSessionCopyContext), the statistics shown in the page
+// header, and the commands to resume the session in Claude Code and to query it.
+func buildFullSessionContext(stats *SessionStats) string {
+	if stats == nil || stats.SessionID == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	writeSessionIdentity(&sb, stats.SessionID, stats.ProjectPath)
+
+	if stats.SessionStart != "" {
+		sb.WriteString(fmt.Sprintf("Started: %s\n", stats.SessionStart))
+	}
+	if stats.Duration != "" {
+		sb.WriteString(fmt.Sprintf("Duration: %s\n", stats.Duration))
+	}
+	if stats.ActiveDuration != "" {
+		sb.WriteString(fmt.Sprintf("Active: %s\n", stats.ActiveDuration))
+	}
+	sb.WriteString(fmt.Sprintf("Messages: User: %d | Assistant: %d | Subagents[%d]: %d messages\n",
+		stats.UserMessages, stats.AssistantMessages, stats.AgentCount, stats.TotalAgentMessages))
+	sb.WriteString(fmt.Sprintf("Tools: %d calls\n", stats.ToolCallCount))
+
+	// Claude Code resumes sessions of the project it is started in
+	if stats.ProjectPath != "" {
+		sb.WriteString(fmt.Sprintf("Resume: cd %s && claude --resume %s\n", stats.ProjectPath, stats.SessionID))
+	} else {
+		sb.WriteString(fmt.Sprintf("Resume: claude --resume %s\n", stats.SessionID))
+	}
+
+	pathArg := stats.ProjectPath
+	if pathArg == "" {
+		pathArg = "/path/to/project"
+	}
+	sb.WriteString(fmt.Sprintf("claude-history query %s --session %s", pathArg, stats.SessionID))
+
+	return sb.String()
+}
+
 // buildAgentIDCopyContext builds the full context string for copying agent ID information.
 // This includes the role, agent ID, and a CLI command to query that specific message stream.
 func buildAgentIDCopyContext(entry models.ConversationEntry, displayAgentID, sessionID, agentID, projectPath, roleLabel string) string {
@@ -652,6 +1183,13 @@ func buildAgentIDCopyContext(entry models.ConversationEntry, displayAgentID, ses
 	return sb.String()
 }
 
+// Role labels of a full session export. Query results for a subagent use
+// "Orchestrator" and "Agent" instead (see RenderQueryResults).
+const (
+	sessionUserLabel      = "User"
+	sessionAssistantLabel = "Assistant"
+)
+
 // getRoleLabel returns a human-readable label for the entry type.
 // userLabel and assistantLabel specify custom role names (e.g., "Orchestrator"/"Agent" for subagent contexts).
 func getRoleLabel(entryType models.EntryType, userLabel, assistantLabel string) string {
@@ -680,18 +1218,240 @@ func formatTimestampReadable(timestamp string) string {
 	return t.Format("3:04 PM")
 }
 
+// formatTimestampRelative formats a timestamp relative to now (e.g., "5 minutes ago").
+// Invalid timestamps are returned unchanged.
+func formatTimestampRelative(ts string, now time.Time) string {
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return ts
+	}
+
+	d := now.Sub(t)
+	if d < time.Minute {
+		return "just now"
+	}
+
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+
+	switch {
+	case d < time.Hour:
+		return plural(int(d.Minutes()), "minute")
+	case d < 24*time.Hour:
+		return plural(int(d.Hours()), "hour")
+	case d < 30*24*time.Hour:
+		return plural(int(d.Hours()/24), "day")
+	case d < 365*24*time.Hour:
+		return plural(int(d.Hours()/(24*30)), "month")
+	default:
+		return plural(int(d.Hours()/(24*365)), "year")
+	}
+}
+
+// referenceTime returns the "now" used for relative timestamps: the export time,
+// or the last entry's timestamp when opts.Deterministic is set.
+func referenceTime(entries []models.ConversationEntry, opts ExportOptions) time.Time {
+	if !opts.Deterministic {
+		return time.Now()
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if t, err := time.Parse(time.RFC3339Nano, entries[i].Timestamp); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// renderInterruption renders a user interruption or cancelled tool call as a compact
+// inline marker. The recorded message is kept in the marker's title.
+func renderInterruption(entry models.ConversationEntry, ro entryRenderOptions) string {
+	detail := strings.TrimSpace(entry.GetTextContent())
+	if detail == "" {
+		for _, r := range entry.ExtractToolResults() {
+			if detail = strings.TrimSpace(r.Content); detail != "" {
+				break
+			}
+		}
+	}
+
+	label := "Interrupted by user"
+	if len(entry.ExtractToolResults()) > 0 {
+		label = "Tool use interrupted by user"
+	}
+
+	return fmt.Sprintf(`<div class="interruption-marker" data-uuid="%s" title="%s"><span class="interruption-icon">⏹</span> <span class="interruption-label">%s</span>%s</div>`+"\n",
+		escapeHTML(entry.UUID), escapeHTML(detail), label,
+		renderTimestampSpan(entry.Timestamp, formatTimestampReadable(entry.Timestamp), ro))
+}
+
+// renderSlashCommand renders a slash command invocation as a one-line chip showing the
+// command name and its arguments, if any.
+func renderSlashCommand(entry models.ConversationEntry, name string, ro entryRenderOptions) string {
+	argsHTML := ""
+	if args := entry.SlashCommandArgs(); args != "" {
+		argsHTML = fmt.Sprintf(` <span class="slash-command-args">%s</span>`, escapeHTML(args))
+	}
+	return fmt.Sprintf(`<div class="slash-command" data-uuid="%s" data-command="%s"><code class="slash-command-chip">%s</code>%s%s</div>`+"\n",
+		escapeHTML(entry.UUID), escapeHTML(name), escapeHTML(name), argsHTML,
+		renderTimestampSpan(entry.Timestamp, formatTimestampReadable(entry.Timestamp), ro))
+}
+
+// renderRawLink renders a "View raw" link from a message header to the entry's line in
+// the exported source JSONL. It returns "" unless ro.opts.RawSource is set and the entry's
+// line is known.
+func renderRawLink(entry models.ConversationEntry, ro entryRenderOptions) string {
+	if ro.opts.RawSource == "" || entry.SourceLine <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(` <a class="raw-link" href="%s#L%d" data-raw-line="%d" target="_blank" rel="noopener" title="Line %d of %s">View raw</a>`,
+		escapeHTML(ro.opts.RawSource), entry.SourceLine, entry.SourceLine, entry.SourceLine, escapeHTML(ro.opts.RawSource))
+}
+
+// renderTimestampSpan renders the message header timestamp. With relative times enabled,
+// the span shows the relative time and carries the absolute time in its title.
+func renderTimestampSpan(rawTimestamp, readable string, ro entryRenderOptions) string {
+	if !ro.opts.RelativeTimes {
+		return fmt.Sprintf(` <span class="timestamp">%s</span>`, escapeHTML(readable))
+	}
+
+	absolute := rawTimestamp
+	if t, err := time.Parse(time.RFC3339Nano, rawTimestamp); err == nil {
+		absolute = t.Format("2006-01-02 15:04:05 MST")
+	}
+	return fmt.Sprintf(` <span class="timestamp" title="%s">%s</span>`,
+		escapeHTML(absolute), escapeHTML(formatTimestampRelative(rawTimestamp, ro.now)))
+}
+
 // renderToolCall renders a single tool call as an expandable HTML section.
 func renderToolCall(tool models.ToolUse, result models.ToolResult, hasResult bool) string {
+	return renderToolCallWith(tool, result, hasResult, "", entryRenderOptions{opts: ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen, NoToolIcons: true}})
+}
+
+// renderToolCallWith renders a tool call like renderToolCall, with the settings of
+// ro.opts. Successful output is truncated beyond MaxToolOutputBytes (0 means no limit;
+// error output is never truncated) and the header summary beyond SummaryMaxLen
+// characters (0 means no limit). The header shows the tool's icon (see toolIcon), and
+// the call starts expanded if it is one of AutoExpandTools. A successful result of one
+// of the markdown tools (see rendersResultMarkdown) is rendered as markdown, with file
+// paths linked against projectPath, instead of preformatted text; with
+// ToolOutputLineNumbers, preformatted output gets numbered, linkable lines (see
+// numbersToolOutput). With NoJS, the call collapses as a <details> element. Bash and
+// ExitPlanMode calls render as a terminal and a plan card (see renderBashToolCall and
+// renderPlanToolCall).
+func renderToolCallWith(tool models.ToolUse, result models.ToolResult, hasResult bool, projectPath string, ro entryRenderOptions) string {
+	if tool.Name == "Bash" {
+		if _, ok := tool.Input["command"].(string); ok {
+			return renderBashToolCall(tool, result, hasResult, ro)
+		}
+	}
+	if tool.Name == planToolName {
+		if _, ok := tool.Input["plan"].(string); ok {
+			return renderPlanToolCall(tool, result, hasResult, projectPath, ro)
+		}
+	}
+
 	var sb strings.Builder
 
-	toolSummary := formatToolSummary(tool)
+	sb.WriteString(renderToolCallHeader(tool, hasResult, "", ro))
 
-	sb.WriteString(fmt.Sprintf(`<div class="tool-call collapsible collapsed" data-tool-id="%s">`, escapeHTML(tool.ID)))
-	sb.WriteString("\n")
+	// Tool input, with large strings such as file contents collapsed
+	sb.WriteString(renderToolInput(tool.Input))
 
-	// Collapsible header with tool ID copy button and chevron
-	sb.WriteString(fmt.Sprintf(`  <div class="tool-header collapsible-trigger" onclick="toggleTool(this)"><span class="tool-summary">%s</span>`,
-		escapeHTML(toolSummary)))
+	// Tool output (if available)
+	if hasResult {
+		outputClass := "tool-output"
+		if result.IsError {
+			outputClass = "tool-output error"
+		}
+		output, truncated := result.Content, false
+		if !result.IsError {
+			output, truncated = truncateUTF8(result.Content, ro.opts.MaxToolOutputBytes)
+		}
+		sb.WriteString(fmt.Sprintf(`    <div class="tool-connector">%s</div>`, renderToolPairLink(tool.ID, false, ro.opts.NoJS)))
+		sb.WriteString("\n")
+		// Images read from disk come back as image content, not text
+		imagePath := ""
+		if !result.IsError {
+			imagePath = readImagePath(tool)
+		}
+		if imagePath != "" {
+			sb.WriteString(renderReadImage(imagePath, result))
+			truncated = false
+		} else if rendersResultMarkdown(tool.Name, ro.opts) && !result.IsError {
+			sb.WriteString(fmt.Sprintf(`    <div class="tool-output markdown-content markdown-result"%s>%s</div>`, toolResultAttrs(result), RenderMarkdown(output, projectPath)))
+		} else {
+			lineNumbers := numbersToolOutput(tool.Name, ro.opts)
+			if lineNumbers {
+				outputClass += numberedOutputClass
+			}
+			sb.WriteString(fmt.Sprintf(`    <pre class="%s"%s>%s</pre>`, outputClass, toolResultAttrs(result), renderOutputLines(output, lineAnchor(tool, "", lineNumbers))))
+		}
+		sb.WriteString("\n")
+		if truncated {
+			sb.WriteString(renderTruncatedNotice(result.Content))
+		}
+	}
+
+	sb.WriteString(renderToolCallClose(ro.opts.NoJS))
+
+	return sb.String()
+}
+
+// renderToolCallHeader opens a tool call: the collapsible container, its header (led by
+// the tool's icon, if any, and ending with the status markup, if any), and the
+// (initially hidden) body. The caller writes the body content and closes both with
+// renderToolCallClose. With ro.opts.NoJS set, the container is a <details> element and
+// the header its <summary>. If the tool is one of ro.opts.AutoExpandTools, the body
+// starts visible instead.
+func renderToolCallHeader(tool models.ToolUse, hasResult bool, status string, ro entryRenderOptions) string {
+	// Link to the paired result, or mark the call as having none
+	resultMarker := toolOrphanMarker
+	if hasResult {
+		resultMarker = renderToolPairLink(tool.ID, true, ro.opts.NoJS)
+	}
+	return renderToolCallHeaderWith(tool, resultMarker, status, ro)
+}
+
+// toolOrphanMarker marks a tool call header whose call has no recorded result.
+const toolOrphanMarker = `<span class="tool-orphan" title="No tool_result was recorded for this call">no result</span>`
+
+// renderToolCallHeaderWith opens a tool call like renderToolCallHeader, showing
+// resultMarker where the header links to the call's result.
+func renderToolCallHeaderWith(tool models.ToolUse, resultMarker, status string, ro entryRenderOptions) string {
+	var sb strings.Builder
+	noJS, icon := ro.opts.NoJS, toolIcon(tool.Name, ro.opts)
+
+	toolSummary := formatToolSummaryWith(tool, ro.opts.SummaryMaxLen)
+	summaryAttrs := ""
+	if full := formatToolSummaryWith(tool, 0); full != toolSummary {
+		summaryAttrs = fmt.Sprintf(` title="%s"`, escapeHTML(full))
+	}
+
+	expanded := autoExpandsTool(tool.Name, ro.opts)
+	collapsed, open := " collapsed", ""
+	if expanded {
+		collapsed, open = "", " open"
+	}
+
+	if noJS {
+		sb.WriteString(fmt.Sprintf(`<details class="tool-call" id="tool-%s" data-tool-id="%s"%s>`, escapeHTML(tool.ID), escapeHTML(tool.ID), open))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf(`  <summary class="tool-header"><span class="tool-summary"%s>`, summaryAttrs))
+	} else {
+		sb.WriteString(fmt.Sprintf(`<div class="tool-call collapsible%s" id="tool-%s" data-tool-id="%s">`, collapsed, escapeHTML(tool.ID), escapeHTML(tool.ID)))
+		sb.WriteString("\n")
+
+		// Collapsible header with tool ID copy button, result link, and chevron
+		sb.WriteString(fmt.Sprintf(`  <div class="tool-header collapsible-trigger" onclick="toggleTool(this)"><span class="tool-summary"%s>`, summaryAttrs))
+	}
+	if icon != "" {
+		sb.WriteString(fmt.Sprintf(`<span class="tool-icon" aria-hidden="true">%s</span>`, escapeHTML(icon)))
+	}
+	sb.WriteString(escapeHTML(toolSummary) + "</span>")
 	sb.WriteString(fmt.Sprintf(`<span class="tool-id">%s</span>`, renderCopyButton(tool.ID, "tool-id", "Copy tool ID")))
 
 	// Add file path copy button for file-related tools
@@ -701,50 +1461,217 @@ func renderToolCall(tool models.ToolUse, result models.ToolResult, hasResult boo
 			renderCopyButton(filePath, "file-path", "Copy file path")))
 	}
 
+	sb.WriteString(resultMarker)
+	sb.WriteString(status)
+
 	// Add chevron indicator
 	sb.WriteString(`<span class="chevron down">▼</span>`)
 
+	if noJS {
+		sb.WriteString("</summary>\n")
+		sb.WriteString(`  <div class="tool-body">`)
+		sb.WriteString("\n")
+		return sb.String()
+	}
+
 	sb.WriteString("</div>\n")
 
-	// Hidden body with input and output (starts collapsed)
-	sb.WriteString(`  <div class="tool-body hidden collapsible-content collapsed">`)
+	// Body, hidden unless the call starts expanded
+	if expanded {
+		sb.WriteString(`  <div class="tool-body collapsible-content">`)
+	} else {
+		sb.WriteString(`  <div class="tool-body hidden collapsible-content collapsed">`)
+	}
 	sb.WriteString("\n")
 
-	// Tool input
-	inputJSON := formatToolInput(tool.Input)
-	sb.WriteString(fmt.Sprintf(`    <pre class="tool-input">%s</pre>`, escapeHTML(inputJSON)))
-	sb.WriteString("\n")
+	return sb.String()
+}
 
-	// Tool output (if available)
-	if hasResult {
+// renderToolCallClose closes the body and container opened by renderToolCallHeader.
+func renderToolCallClose(noJS bool) string {
+	if noJS {
+		return "  </div>\n</details>\n"
+	}
+	return "  </div>\n</div>\n"
+}
+
+// renderTruncatedNotice renders the note shown below truncated tool output, with a
+// button to copy the full content.
+func renderTruncatedNotice(content string) string {
+	return fmt.Sprintf(`    <div class="tool-output-truncated">… (truncated, %d bytes total)%s</div>`,
+		len(content),
+		renderCopyButton(content, "tool-output", "Copy full output")) + "\n"
+}
+
+// renderToolPairLink renders a link between a tool call and its result.
+// toResult selects the direction: from the call header to the result, or back to the call.
+// With noJS set, the link is a plain fragment link without the script that expands the call.
+func renderToolPairLink(toolID string, toResult, noJS bool) string {
+	onclick := ` onclick="jumpToToolPair(event, this)"`
+	if noJS {
+		onclick = ""
+	}
+	if toResult {
+		return fmt.Sprintf(`<a class="tool-pair-link" href="#tool-result-%s"%s title="Jump to result">result ↓</a>`, escapeHTML(toolID), onclick)
+	}
+	return fmt.Sprintf(`<a class="tool-pair-link" href="#tool-%s"%s title="Jump to call">↑ call</a>`, escapeHTML(toolID), onclick)
+}
+
+// toolResultAttrs returns the id and data attributes that link a result element to its call.
+func toolResultAttrs(result models.ToolResult) string {
+	attrs := fmt.Sprintf(` id="tool-result-%s" data-tool-result-for="%s"`, escapeHTML(result.ToolUseID), escapeHTML(result.ToolUseID))
+	if result.EntryUUID != "" {
+		attrs += fmt.Sprintf(` data-result-entry="%s"`, escapeHTML(result.EntryUUID))
+	}
+	return attrs
+}
+
+// buildToolCallIDSet returns the IDs of every tool call in entries.
+func buildToolCallIDSet(entries []models.ConversationEntry) map[string]bool {
+	ids := make(map[string]bool)
+	for i := range entries {
+		for _, tool := range entries[i].ExtractToolCalls() {
+			ids[tool.ID] = true
+		}
+	}
+	return ids
+}
+
+// orphanToolResults returns the tool results in entry that have no matching call in callIDs.
+func orphanToolResults(entry models.ConversationEntry, callIDs map[string]bool) []models.ToolResult {
+	var orphans []models.ToolResult
+	for _, r := range entry.ExtractToolResults() {
+		if !callIDs[r.ToolUseID] {
+			orphans = append(orphans, r)
+		}
+	}
+	return orphans
+}
+
+// renderOrphanToolResults renders tool results whose tool call is missing from the
+// conversation, so they are visible and clearly marked instead of silently dropped.
+func renderOrphanToolResults(entry models.ConversationEntry, results []models.ToolResult) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<div class="orphan-tool-results" data-uuid="%s">`, escapeHTML(entry.UUID)))
+	sb.WriteString("\n")
+	for _, r := range results {
 		outputClass := "tool-output"
-		if result.IsError {
+		if r.IsError {
 			outputClass = "tool-output error"
 		}
-		sb.WriteString(fmt.Sprintf(`    <pre class="%s">%s</pre>`, outputClass, escapeHTML(result.Content)))
+		sb.WriteString(fmt.Sprintf(`  <div class="tool-call orphan-result" data-tool-id="%s">`, escapeHTML(r.ToolUseID)))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf(`    <div class="tool-header"><span class="tool-summary">Tool result without a matching call</span><span class="tool-id">%s</span></div>`,
+			renderCopyButton(r.ToolUseID, "tool-id", "Copy tool ID")))
 		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf(`    <pre class="%s"%s>%s</pre>`, outputClass, toolResultAttrs(r), escapeHTML(r.Content)))
+		sb.WriteString("\n")
+		sb.WriteString("  </div>\n")
 	}
-
-	sb.WriteString("  </div>\n")
 	sb.WriteString("</div>\n")
-
 	return sb.String()
 }
 
 // renderSubagentPlaceholder renders a placeholder for a subagent section.
 // sessionID and projectPath are used to build the full copy context with CLI commands.
 func renderSubagentPlaceholder(agentID string, agentMap map[string]int, sessionID, projectPath string) string {
+	return renderSubagentPlaceholderWith(agentID, agentMap, sessionID, projectPath, subagentDetails{}, entryRenderOptions{})
+}
+
+// subagentDetails is what a subagent placeholder shows of an agent besides its ID and
+// entry count. The zero value shows none of it.
+type subagentDetails struct {
+	description string // Titles the section (see agent.TreeNode.Description)
+	duration    string // Badge after the entry count (see agentDurations)
+	spawnStatus string // Marks a failed spawn (see SessionStats.FailedSpawns)
+	parseErrors int    // Marks a partially loaded agent (see SessionStats.AgentParseErrors)
+	content     string // The agent's rendered conversation, inlined with ExportOptions.NoJS
+}
+
+// subagentDescriptionMaxLen truncates the descriptions shown as subagent titles.
+const subagentDescriptionMaxLen = 60
+
+// renderSubagentPlaceholderWith renders a subagent placeholder like renderSubagentPlaceholder,
+// displaying the agent ID as shortened in ro.shortIDs (see ShortenIDs). A description
+// titles the section, truncated, with the agent ID after it; otherwise the ID does. A
+// duration is shown as a badge after the entry count. A spawn status marks the section
+// as a failed spawn; when the agent also has no entries in agentMap, the section is just
+// that marker, with nothing to expand. Parse errors mark the agent as partially loaded,
+// its entry count covering only the entries that parsed. With ro.opts.NoJS set, the
+// section is a <details> element holding the agent's rendered conversation instead of
+// an empty container that loadAgent fills.
+func renderSubagentPlaceholderWith(agentID string, agentMap map[string]int, sessionID, projectPath string, details subagentDetails, ro entryRenderOptions) string {
 	var sb strings.Builder
 
-	entryCount := agentMap[agentID]
-	shortID := truncateID(agentID, 7)
+	entryCount, hasFile := agentMap[agentID]
+	shortID, typeLabel := shortAgentID(agentID, ro.shortIDs)
 
-	sb.WriteString(fmt.Sprintf(`<div class="subagent collapsible collapsed" data-agent-id="%s">`, escapeHTML(agentID)))
-	sb.WriteString("\n")
-	sb.WriteString(fmt.Sprintf(`  <div class="subagent-header collapsible-trigger" onclick="loadAgent(this)"><span class="subagent-title">Subagent: %s</span> <span class="subagent-meta">(%d entries)</span>%s<span class="chevron down">▼</span></div>`,
-		escapeHTML(shortID),
+	typeBadge := ""
+	if typeLabel != "" {
+		typeBadge = fmt.Sprintf(` <span class="subagent-type">%s</span>`, escapeHTML(typeLabel))
+	}
+
+	heading := fmt.Sprintf(`<span class="subagent-title">Subagent: %s</span>`, escapeHTML(shortID))
+	if details.description != "" {
+		heading = fmt.Sprintf(`<span class="subagent-title" title="%s">Subagent: %s</span> <code class="subagent-id">%s</code>`,
+			escapeHTML(details.description), escapeHTML(truncateSummary(details.description, subagentDescriptionMaxLen)), escapeHTML(shortID))
+	}
+
+	durationBadge := ""
+	if details.duration != "" {
+		durationBadge = fmt.Sprintf(` <span class="subagent-duration" title="Time from the agent's first to last entry">%s</span>`, escapeHTML(details.duration))
+	}
+
+	sectionClass, failedBadge := "subagent", ""
+	if details.spawnStatus != "" {
+		sectionClass = "subagent spawn-failed"
+		failedBadge = fmt.Sprintf(` <span class="subagent-spawn-failed" title="The spawn of this agent ended with status: %s">✗ spawn failed</span>`, escapeHTML(details.spawnStatus))
+	}
+
+	partialBadge := ""
+	if details.parseErrors > 0 {
+		noun := "parse errors"
+		if details.parseErrors == 1 {
+			noun = "parse error"
+		}
+		partialBadge = fmt.Sprintf(` <span class="subagent-partial" title="Lines of the agent file that could not be read were skipped">partially loaded (%d %s)</span>`,
+			details.parseErrors, noun)
+	}
+
+	// A failed spawn that left no transcript has nothing to expand
+	if details.spawnStatus != "" && !hasFile {
+		sb.WriteString(fmt.Sprintf(`<div class="%s" id="%s" data-agent-id="%s">`,
+			sectionClass, escapeHTML(subagentAnchorID(agentID)), escapeHTML(agentID)))
+		sb.WriteString("\n")
+		sb.WriteString(`  <div class="subagent-header">` + heading + typeBadge + failedBadge +
+			` <span class="subagent-meta">(no transcript)</span>` + renderSubagentBadgeWithCopy(agentID, sessionID, projectPath) + "</div>\n")
+		sb.WriteString("</div>\n")
+		return sb.String()
+	}
+
+	title := fmt.Sprintf(`%s%s%s <span class="subagent-meta">(%d entries)</span>%s%s%s<span class="chevron down">▼</span>`,
+		heading,
+		typeBadge,
+		failedBadge,
 		entryCount,
-		renderSubagentBadgeWithCopy(agentID, sessionID, projectPath)))
+		partialBadge,
+		durationBadge,
+		renderSubagentBadgeWithCopy(agentID, sessionID, projectPath))
+
+	if ro.opts.NoJS {
+		sb.WriteString(fmt.Sprintf(`<details class="%s" id="%s" data-agent-id="%s">`,
+			sectionClass, escapeHTML(subagentAnchorID(agentID)), escapeHTML(agentID)))
+		sb.WriteString("\n")
+		sb.WriteString(`  <summary class="subagent-header">` + title + "</summary>\n")
+		sb.WriteString(`  <div class="subagent-content">` + details.content + "</div>\n")
+		sb.WriteString("</details>\n")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf(`<div class="%s collapsible collapsed" id="%s" data-agent-id="%s">`,
+		sectionClass, escapeHTML(subagentAnchorID(agentID)), escapeHTML(agentID)))
+	sb.WriteString("\n")
+	sb.WriteString(`  <div class="subagent-header collapsible-trigger" onclick="loadAgent(this)">` + title + "</div>")
 	sb.WriteString("\n")
 	sb.WriteString(`  <div class="subagent-content"></div>`)
 	sb.WriteString("\n")
@@ -781,18 +1708,38 @@ func renderSessionIDWithCopy(sessionID, projectPath, agentID string) string {
 }
 
 // renderAgentIDWithCopy renders an agent ID badge with truncated display and copy button.
-// The display shows only the first 8 chars for clean UI, but the copy button includes
+// The display uses the normalized short ID for clean UI, but the copy button includes
 // full context (role, agent ID, session, and CLI command) to prevent ID collisions.
 func renderAgentIDWithCopy(entry models.ConversationEntry, displayAgentID, sessionID, agentID, projectPath, roleLabel string) string {
+	return renderAgentIDWithCopyWith(entry, displayAgentID, sessionID, agentID, projectPath, roleLabel, nil, nil)
+}
+
+// renderAgentIDWithCopyWith renders an agent ID badge like renderAgentIDWithCopy,
+// displaying the ID as shortened in shortIDs (see ShortenIDs). When agentAnchors has the
+// agent, the ID links to its subagent section (see subagentAnchors); otherwise the badge
+// only offers the copy button.
+func renderAgentIDWithCopyWith(entry models.ConversationEntry, displayAgentID, sessionID, agentID, projectPath, roleLabel string, shortIDs map[string]string, agentAnchors map[string]bool) string {
 	if displayAgentID == "" {
 		return ""
 	}
 
-	truncatedID := truncateID(displayAgentID, 8)
+	shortID, typeLabel := shortAgentID(displayAgentID, shortIDs)
 	copyContext := buildAgentIDCopyContext(entry, displayAgentID, sessionID, agentID, projectPath, roleLabel)
 
-	return fmt.Sprintf(`<span class="agent-id-badge">%s%s</span>`,
-		escapeHTML(truncatedID),
+	title := ""
+	if typeLabel != "" {
+		title = fmt.Sprintf(` title="%s"`, escapeHTML(typeLabel))
+	}
+
+	label := escapeHTML(shortID)
+	if agentAnchors[displayAgentID] {
+		label = fmt.Sprintf(`<a class="agent-id-link" href="#%s" title="Go to subagent section">%s</a>`,
+			escapeHTML(subagentAnchorID(displayAgentID)), label)
+	}
+
+	return fmt.Sprintf(`<span class="agent-id-badge"%s>%s%s</span>`,
+		title,
+		label,
 		renderCopyButton(copyContext, "agent-id", "Copy agent details"))
 }
 
@@ -840,6 +1787,19 @@ func renderFileLink(path, displayText, cssClass string) string {
 		escapeHTML(fileURL), escapeHTML(cssClass), escapeHTML(displayText))
 }
 
+// truncateUTF8 shortens s to at most maxBytes without splitting a multi-byte rune.
+// It reports whether s was truncated; maxBytes <= 0 means no limit.
+func truncateUTF8(s string, maxBytes int) (string, bool) {
+	if maxBytes <= 0 || len(s) <= maxBytes {
+		return s, false
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut], true
+}
+
 // renderCopyButton generates HTML for a copy-to-clipboard button.
 // text is the value to copy, copyType indicates what kind of value it is (for styling/tracking),
 // and tooltip is the hover text shown to the user.
@@ -882,20 +1842,50 @@ func formatTimestamp(timestamp string) string {
 	return t.Format("15:04:05")
 }
 
-// formatToolSummary creates a summary string for a tool call header.
+// DefaultSummaryMaxLen is the default length, in characters, of inline tool summaries.
+const DefaultSummaryMaxLen = 60
+
+// formatToolSummary creates a summary string for a tool call header,
+// truncated to DefaultSummaryMaxLen characters.
 func formatToolSummary(tool models.ToolUse) string {
+	return formatToolSummaryWith(tool, DefaultSummaryMaxLen)
+}
+
+// formatToolSummaryWith creates a tool call header summary, truncating the display value
+// to maxLen characters (0 means no truncation) with the count of characters hidden (see
+// truncateSummaryWithLength).
+func formatToolSummaryWith(tool models.ToolUse, maxLen int) string {
 	displayValue := extractToolDisplayValue(tool.Name, tool.Input)
 	if displayValue == "" {
 		return fmt.Sprintf("[%s]", tool.Name)
 	}
 
-	// Truncate if too long
-	const maxLen = 60
-	if len(displayValue) > maxLen {
-		displayValue = displayValue[:maxLen-3] + "..."
+	return fmt.Sprintf("[%s] %s", tool.Name, truncateSummaryWithLength(displayValue, maxLen))
+}
+
+// truncateSummaryWithLength shortens s to its first maxLen-1 characters (runes) followed
+// by "…" and the number of characters left out, e.g. "git clone … (+142 chars)", so a
+// truncated summary tells how much it hides. maxLen <= 0 means no limit.
+func truncateSummaryWithLength(s string, maxLen int) string {
+	if maxLen <= 0 || utf8.RuneCountInString(s) <= maxLen {
+		return s
 	}
+	runes := []rune(s)
+	kept := maxLen - 1
+	return fmt.Sprintf("%s… (+%d chars)", string(runes[:kept]), len(runes)-kept)
+}
 
-	return fmt.Sprintf("[%s] %s", tool.Name, displayValue)
+// truncateSummary shortens s to at most maxLen characters (runes), ending with "..."
+// when truncated. Multi-byte characters are never split; maxLen <= 0 means no limit.
+func truncateSummary(s string, maxLen int) string {
+	if maxLen <= 0 || utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	runes := []rune(s)
+	if maxLen <= 3 {
+		return string(runes[:maxLen])
+	}
+	return string(runes[:maxLen-3]) + "..."
 }
 
 // extractToolDisplayValue extracts the most relevant display value from tool input.
@@ -921,6 +1911,19 @@ func extractToolDisplayValue(toolName string, input map[string]any) string {
 		if path, ok := input["file_path"].(string); ok {
 			return path
 		}
+	case "MultiEdit", "ApplyPatch":
+		path := extractFilePath(toolName, input)
+		if path == "" {
+			break
+		}
+		if edits, ok := input["edits"].([]any); ok {
+			noun := "edits"
+			if len(edits) == 1 {
+				noun = "edit"
+			}
+			return fmt.Sprintf("%s (%d %s)", path, len(edits), noun)
+		}
+		return path
 	case "Grep":
 		if pattern, ok := input["pattern"].(string); ok {
 			return pattern
@@ -944,6 +1947,8 @@ func extractToolDisplayValue(toolName string, input map[string]any) string {
 		if query, ok := input["query"].(string); ok {
 			return query
 		}
+	case bashOutputToolName, "KillShell", "KillBash":
+		return shellToolID(input)
 	case "TaskCreate":
 		if subject, ok := input["subject"].(string); ok {
 			return subject
@@ -967,30 +1972,20 @@ func extractToolDisplayValue(toolName string, input map[string]any) string {
 		}
 	case "TaskList":
 		return "List all tasks"
+	case planToolName:
+		if title := planTitle(planText(input)); title != "" {
+			return title
+		}
 	}
 
 	return ""
 }
 
-// extractFilePath extracts the file path from tool input for file-related tools.
-// Returns empty string for non-file tools or if no file path is present.
+// extractFilePath extracts the file path from tool input for file-related tools (see
+// session.ToolFilePath). Returns empty string for non-file tools or if no file path is
+// present.
 func extractFilePath(toolName string, input map[string]any) string {
-	if input == nil {
-		return ""
-	}
-
-	switch toolName {
-	case "Read", "Write", "Edit":
-		if path, ok := input["file_path"].(string); ok {
-			return path
-		}
-	case "NotebookEdit":
-		if path, ok := input["notebook_path"].(string); ok {
-			return path
-		}
-	}
-
-	return ""
+	return session.ToolFilePath(models.ToolUse{Name: toolName, Input: input})
 }
 
 // formatToolInput formats tool input as indented JSON.
@@ -1007,6 +2002,41 @@ func formatToolInput(input map[string]any) string {
 	return string(data)
 }
 
+// sessionAgentIDs returns the agent IDs a page can display: those in agentMap and those
+// of the entries.
+func sessionAgentIDs(entries []models.ConversationEntry, agentMap map[string]int) []string {
+	ids := make([]string, 0, len(agentMap))
+	for id := range agentMap {
+		ids = append(ids, id)
+	}
+	for _, entry := range entries {
+		if entry.AgentID != "" {
+			ids = append(ids, entry.AgentID)
+		}
+	}
+	return ids
+}
+
+// subagentAnchorID returns the element ID of an agent's subagent placeholder.
+func subagentAnchorID(agentID string) string {
+	return "agent-" + agentID
+}
+
+// subagentAnchors returns the agents of the tree (agentMap) that get a subagent
+// placeholder among entries, i.e. whose agent ID badges can link to a section on the page.
+func subagentAnchors(entries []models.ConversationEntry, agentMap map[string]int) map[string]bool {
+	anchors := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Type != models.EntryTypeQueueOperation || entry.AgentID == "" {
+			continue
+		}
+		if _, ok := agentMap[entry.AgentID]; ok {
+			anchors[entry.AgentID] = true
+		}
+	}
+	return anchors
+}
+
 // buildAgentMap creates a map of agent IDs to entry counts from the agent tree.
 func buildAgentMap(agents []*agent.TreeNode) map[string]int {
 	result := make(map[string]int)
@@ -1027,7 +2057,63 @@ func buildAgentMap(agents []*agent.TreeNode) map[string]int {
 	return result
 }
 
+// buildFailedSpawns maps the IDs of the agents whose spawn failed, in the tree or in
+// entries, to the spawn's status; nil when every spawn succeeded.
+func buildFailedSpawns(entries []models.ConversationEntry, agents []*agent.TreeNode) map[string]string {
+	var failed map[string]string
+	record := func(agentID, status string) {
+		if failed == nil {
+			failed = make(map[string]string)
+		}
+		failed[agentID] = status
+	}
+	for _, node := range agent.FlattenTree(&agent.TreeNode{Children: agents}) {
+		if node.AgentID != "" && node.SpawnStatus != "" {
+			record(node.AgentID, node.SpawnStatus)
+		}
+	}
+	for i := range entries {
+		if agentID, status, ok := agent.SpawnFailureStatus(entries[i]); ok {
+			record(agentID, status)
+		}
+	}
+	return failed
+}
+
+// buildAgentDescriptions maps the IDs of the agents in the tree to their descriptions,
+// leaving out agents without one.
+func buildAgentDescriptions(agents []*agent.TreeNode) map[string]string {
+	descriptions := make(map[string]string)
+	var walk func(nodes []*agent.TreeNode)
+	walk = func(nodes []*agent.TreeNode) {
+		for _, node := range nodes {
+			if node.AgentID != "" && node.Description != "" {
+				descriptions[node.AgentID] = node.Description
+			}
+			walk(node.Children)
+		}
+	}
+	walk(agents)
+	return descriptions
+}
+
+// buildAgentParseErrors maps the IDs of the agents in the tree whose files had lines
+// that could not be parsed to the number of such lines; nil when every file parsed.
+func buildAgentParseErrors(agents []*agent.TreeNode) map[string]int {
+	var parseErrors map[string]int
+	for _, node := range agent.FlattenTree(&agent.TreeNode{Children: agents}) {
+		if node.AgentID != "" && node.ParseErrors > 0 {
+			if parseErrors == nil {
+				parseErrors = make(map[string]int)
+			}
+			parseErrors[node.AgentID] = node.ParseErrors
+		}
+	}
+	return parseErrors
+}
+
 // buildToolResultsMap creates a map of tool use IDs to their results.
+// Each result's EntryUUID identifies the user entry that carried it.
 // This allows matching tool calls with their corresponding results.
 func buildToolResultsMap(entries []models.ConversationEntry) map[string]models.ToolResult {
 	result := make(map[string]models.ToolResult)
@@ -1045,8 +2131,15 @@ func buildToolResultsMap(entries []models.ConversationEntry) map[string]models.T
 }
 
 // renderHTMLHeader generates the HTML header with session metadata.
-// agentDetails is an optional map of agent IDs to message counts for the interactive tooltip.
-func renderHTMLHeader(stats *SessionStats, agentDetails map[string]int) string {
+// agentDetails is an optional map of agent IDs to message counts for the interactive tooltip
+// (see agentDetailsJSON).
+func renderHTMLHeader(stats *SessionStats, agentDetails map[string]int, loc localizer) string {
+	return renderHTMLHeaderWith(stats, agentDetails, loc, ExportOptions{})
+}
+
+// renderHTMLHeaderWith generates the HTML header like renderHTMLHeader, adding the replay
+// buttons when opts.ReplayMode is set and the color overrides of opts.TypeColors.
+func renderHTMLHeaderWith(stats *SessionStats, agentDetails map[string]int, loc localizer, opts ExportOptions) string {
 	var sb strings.Builder
 
 	// Build session folder link if we have a path
@@ -1057,23 +2150,43 @@ func renderHTMLHeader(stats *SessionStats, agentDetails map[string]int) string {
 		if sessionFolderName == "" && stats.ProjectPath != "" {
 			sessionFolderName = extractSessionFolderName(stats.ProjectPath)
 		}
-		if stats.SessionFolderPath != "" {
+		// A path with labeled IDs (see stripIDs) points nowhere, so only its name is shown
+		if stats.SessionFolderPath != "" && opts.IDMap == nil {
 			sessionFolderLink = renderFileLink(stats.SessionFolderPath, sessionFolderName, "folder-link")
 		} else if sessionFolderName != "" {
 			sessionFolderLink = escapeHTML(sessionFolderName)
 		}
 	}
 
+	// The session ID scopes state the page saves in the browser (see controls.js)
+	bodyAttrs := ""
+	if stats != nil && stats.SessionID != "" {
+		bodyAttrs = fmt.Sprintf(` data-session-id="%s"`, escapeHTML(stats.SessionID))
+	}
+	// Without scripts, style.css hides the controls that would need them
+	var bodyClasses []string
+	if opts.NoJS {
+		bodyClasses = append(bodyClasses, "no-js")
+	}
+	sidebar := ""
+	if opts.Sidebar && stats != nil {
+		bodyClasses = append(bodyClasses, "has-sidebar")
+		sidebar = renderSessionSidebar(stats)
+	}
+	if len(bodyClasses) > 0 {
+		bodyAttrs += fmt.Sprintf(` class="%s"`, strings.Join(bodyClasses, " "))
+	}
+
 	sb.WriteString(fmt.Sprintf(`<!DOCTYPE html>
 <html>
 <head>
     <meta charset="UTF-8">
     <title>Claude Code Session [v%s]</title>
     <link rel="stylesheet" href="static/style.css">
-</head>
-<body>
-<header class="page-header">
-    <h1>Claude Code Session <span style="font-size: 0.5em; color: #999;">[v%s]</span>`, version.Version, version.Version))
+%s</head>
+<body%s>
+%s<header class="page-header">
+    <h1>Claude Code Session <span style="font-size: 0.5em; color: #999;">[v%s]</span>`, version.Version, renderTypeColorStyle(opts.TypeColors), bodyAttrs, sidebar, version.Version))
 	if sessionFolderLink != "" {
 		sb.WriteString(`: `)
 		sb.WriteString(sessionFolderLink)
@@ -1088,41 +2201,41 @@ func renderHTMLHeader(stats *SessionStats, agentDetails map[string]int) string {
 `, renderSessionIDWithCopy(stats.SessionID, stats.ProjectPath, "")))
 	}
 
+	// Sessions this one was resumed from, linking to their exports
+	if stats != nil && len(stats.Lineage) > 0 {
+		sb.WriteString("        " + renderSessionLineage(stats.Lineage) + "\n")
+	}
+
 	// Session start time
 	if stats != nil && stats.SessionStart != "" {
 		sb.WriteString(fmt.Sprintf(`        <span class="meta-item">Started: %s</span>
 `, escapeHTML(stats.SessionStart)))
 	}
 
-	// Session duration
+	// Session duration, with the time spent working when it was measured
 	if stats != nil && stats.Duration != "" {
+		duration := loc.statsDuration(stats)
+		if stats.ActiveDuration != "" {
+			duration += " (active " + loc.statsActiveDuration(stats) + ")"
+		}
 		sb.WriteString(fmt.Sprintf(`        <span class="meta-item">Duration: %s</span>
-`, escapeHTML(stats.Duration)))
+`, escapeHTML(duration)))
 	}
 
 	// Enhanced message statistics with interactive agent tooltip
 	if stats != nil {
-		// Encode agent details as JSON for JavaScript
-		agentDetailsJSON := "{}"
-		if len(agentDetails) > 0 {
-			jsonBytes, err := json.Marshal(agentDetails)
-			if err == nil {
-				agentDetailsJSON = string(jsonBytes)
-			}
-		}
-
 		// Build the statistics line with interactive agent tooltip
-		sb.WriteString(fmt.Sprintf(`        <span class="meta-item">User: %d | Assistant: %d | `, stats.UserMessages, stats.AssistantMessages))
+		sb.WriteString(fmt.Sprintf(`        <span class="meta-item">User: %s | Assistant: %s | `, loc.number(stats.UserMessages), loc.number(stats.AssistantMessages)))
 
-		// Add interactive agent stats span if there are agents
-		if stats.AgentCount > 0 {
-			sb.WriteString(fmt.Sprintf(`<span class="agent-stats-interactive" data-session-id="%s" data-agent-details='%s' title="Click to copy agent list">Subagents[%d]: %d messages</span>`,
+		// Add interactive agent stats span if there are agents (agent-tooltip.js makes it copy the list)
+		if stats.AgentCount > 0 && !opts.NoJS {
+			sb.WriteString(fmt.Sprintf(`<span class="agent-stats-interactive" data-session-id="%s" data-agent-details='%s' title="Click to copy agent list">Subagents[%s]: %s messages</span>`,
 				escapeHTML(stats.SessionID),
-				escapeHTML(agentDetailsJSON),
-				stats.AgentCount,
-				stats.TotalAgentMessages))
+				escapeHTML(agentDetailsJSON(agentDetails)),
+				loc.number(stats.AgentCount),
+				loc.number(stats.TotalAgentMessages)))
 		} else {
-			sb.WriteString(fmt.Sprintf(`Subagents[%d]: %d messages`, stats.AgentCount, stats.TotalAgentMessages))
+			sb.WriteString(fmt.Sprintf(`Subagents[%s]: %s messages`, loc.number(stats.AgentCount), loc.number(stats.TotalAgentMessages)))
 		}
 
 		sb.WriteString("</span>\n")
@@ -1130,12 +2243,58 @@ func renderHTMLHeader(stats *SessionStats, agentDetails map[string]int) string {
 
 	// Tool call count
 	if stats != nil {
-		sb.WriteString(fmt.Sprintf(`        <span class="meta-item">Tools: %d calls</span>
-`, stats.ToolCallCount))
+		sb.WriteString(fmt.Sprintf(`        <span class="meta-item">Tools: %s calls</span>
+`, loc.number(stats.ToolCallCount)))
 	}
 
-	sb.WriteString(`    </div>
-    <div class="controls" role="toolbar" aria-label="Conversation controls">
+	// Flag failed API requests, a sign of an unreliable session
+	if stats != nil && stats.APIErrorCount > 0 {
+		sb.WriteString(fmt.Sprintf(`        <span class="meta-item api-error-badge" title="Rate limits and failed API requests">⚠ API errors: %s</span>
+`, loc.number(stats.APIErrorCount)))
+	}
+
+	// Compacted sessions continue from a summary, so earlier context was condensed
+	if stats != nil && stats.CompactionCount > 0 {
+		sb.WriteString(fmt.Sprintf(`        <span class="meta-item" title="Times the context was compacted into a summary">Compactions: %s</span>
+`, loc.number(stats.CompactionCount)))
+	}
+
+	// Warn when the session appears to have been cut off
+	if stats != nil && stats.IncompleteReason != "" {
+		sb.WriteString(fmt.Sprintf(`        <span class="meta-item incomplete-badge" title="%s">⚠ Incomplete session</span>
+`, escapeHTML(stats.IncompleteReason)))
+	}
+
+	// One button copying the whole session context, built from the stats shown above
+	if fullContext := buildFullSessionContext(stats); fullContext != "" {
+		sb.WriteString(fmt.Sprintf(`        <span class="meta-item session-context">Share context %s</span>
+`, renderCopyButton(fullContext, "session-context", "Copy full session context")))
+	}
+
+	sb.WriteString("    </div>\n")
+
+	// The system prompt and other context the session starts with, collapsed
+	if opts.IncludePreamble && stats != nil {
+		sb.WriteString(renderSessionPreamble(stats.Preamble))
+	}
+
+	// What the session was about, repeated from the first user message
+	if opts.ShowFirstPrompt && stats != nil {
+		sb.WriteString(renderFirstPromptCard(stats.FirstPrompt))
+	}
+
+	// The files the session changed, for reviewing its work
+	if opts.ShowFilesTouched && stats != nil {
+		sb.WriteString(renderFilesTouched(stats.FilesTouched))
+	}
+
+	// Expanding, search, replay, filters and breadcrumbs are all driven by the scripts
+	if opts.NoJS {
+		sb.WriteString("</header>\n")
+		return sb.String()
+	}
+
+	sb.WriteString(`    <div class="controls" role="toolbar" aria-label="Conversation controls">
         <div class="controls-group">
             <button id="expand-all-btn" type="button" data-shortcut="Ctrl+K" title="Expand all tool calls (Ctrl+K)">Expand All</button>
             <button id="collapse-all-btn" type="button" title="Collapse all tool calls">Collapse All</button>
@@ -1143,11 +2302,17 @@ func renderHTMLHeader(stats *SessionStats, agentDetails map[string]int) string {
         <div class="controls-separator" aria-hidden="true"></div>
         <div class="search-container">
             <input type="search" id="search-box" placeholder="Search messages..." aria-label="Search messages" data-shortcut="Ctrl+F" title="Search messages (Ctrl+F)">
+            <button id="search-case-btn" type="button" class="search-option-btn" aria-pressed="false" title="Match case" aria-label="Match case">Aa</button>
+            <button id="search-word-btn" type="button" class="search-option-btn" aria-pressed="false" title="Match whole word" aria-label="Match whole word">W</button>
             <button id="search-prev-btn" type="button" class="search-nav-btn" title="Previous match (Shift+Enter)" aria-label="Previous match">&lt;</button>
             <button id="search-next-btn" type="button" class="search-nav-btn" title="Next match (Enter)" aria-label="Next match">&gt;</button>
             <span class="search-results" aria-live="polite"></span>
         </div>
-    </div>
+        <div class="controls-separator" aria-hidden="true"></div>
+`)
+	sb.WriteString(renderReplayControls(opts))
+	sb.WriteString(renderTypeFilters(sessionUserLabel, sessionAssistantLabel))
+	sb.WriteString(`    </div>
     <nav class="breadcrumbs" id="breadcrumbs" aria-label="Navigation breadcrumbs">
     </nav>
 </header>
@@ -1158,6 +2323,12 @@ func renderHTMLHeader(stats *SessionStats, agentDetails map[string]int) string {
 
 // renderHTMLFooter generates the HTML footer with export info and keyboard shortcuts.
 func renderHTMLFooter(stats *SessionStats) string {
+	return renderHTMLFooterWith(stats, ExportOptions{})
+}
+
+// renderHTMLFooterWith generates the HTML footer, adding the legend of message colors
+// when opts.ShowLegend is set. With opts.NoJS set, it has no scripts or keyboard shortcuts.
+func renderHTMLFooterWith(stats *SessionStats, opts ExportOptions) string {
 	var sb strings.Builder
 
 	sb.WriteString(`<footer class="page-footer">
@@ -1174,8 +2345,18 @@ func renderHTMLFooter(stats *SessionStats) string {
 `, sourcePath, renderCopyButton(stats.ProjectPath, "source-path", "Copy source path")))
 	}
 
-	sb.WriteString(`    </div>
-    <div class="footer-help">
+	sb.WriteString("    </div>\n")
+	if opts.ShowLegend {
+		sb.WriteString(renderLegend(sessionUserLabel, sessionAssistantLabel))
+	}
+	if opts.NoJS {
+		sb.WriteString(`</footer>
+</body>
+</html>
+`)
+		return sb.String()
+	}
+	sb.WriteString(`    <div class="footer-help">
         <details>
             <summary>Keyboard Shortcuts</summary>
             <ul>
@@ -1186,6 +2367,10 @@ func renderHTMLFooter(stats *SessionStats) string {
         </details>
     </div>
 </footer>
+<div class="jump-buttons" role="group" aria-label="Jump within conversation">
+    <button type="button" id="jump-top-btn" class="jump-btn" title="Jump to top" aria-label="Jump to top" hidden>↑</button>
+    <button type="button" id="jump-latest-btn" class="jump-btn" title="Jump to latest message" aria-label="Jump to latest message" hidden>↓</button>
+</div>
     <script src="static/script.js"></script>
     <script src="static/clipboard.js"></script>
     <script src="static/controls.js"></script>
@@ -1220,6 +2405,8 @@ var htmlHeader = `<!DOCTYPE html>
         <div class="controls-separator" aria-hidden="true"></div>
         <div class="search-container">
             <input type="search" id="search-box" placeholder="Search messages..." aria-label="Search messages" data-shortcut="Ctrl+F" title="Search messages (Ctrl+F)">
+            <button id="search-case-btn" type="button" class="search-option-btn" aria-pressed="false" title="Match case" aria-label="Match case">Aa</button>
+            <button id="search-word-btn" type="button" class="search-option-btn" aria-pressed="false" title="Match whole word" aria-label="Match whole word">W</button>
             <button id="search-prev-btn" type="button" class="search-nav-btn" title="Previous match (Shift+Enter)" aria-label="Previous match">&lt;</button>
             <button id="search-next-btn" type="button" class="search-nav-btn" title="Next match (Enter)" aria-label="Next match">&gt;</button>
             <span class="search-results" aria-live="polite"></span>
@@ -1253,6 +2440,15 @@ type TaskNotificationData struct {
 	Result  string
 }
 
+// Fields of a task-notification XML block, compiled once for parseTaskNotification.
+// The result may span lines, so it uses (?s) for dot-all mode.
+var (
+	taskIDRe      = regexp.MustCompile(`<task-id>(.*?)</task-id>`)
+	taskStatusRe  = regexp.MustCompile(`<status>(.*?)</status>`)
+	taskSummaryRe = regexp.MustCompile(`<summary>(.*?)</summary>`)
+	taskResultRe  = regexp.MustCompile(`(?s)<result>(.*?)</result>`)
+)
+
 // parseTaskNotification extracts structured data from a task-notification XML block.
 func parseTaskNotification(content string) *TaskNotificationData {
 	if !strings.Contains(content, "<task-notification>") {
@@ -1262,22 +2458,22 @@ func parseTaskNotification(content string) *TaskNotificationData {
 	data := &TaskNotificationData{}
 
 	// Extract task-id
-	if matches := regexp.MustCompile(`<task-id>(.*?)</task-id>`).FindStringSubmatch(content); len(matches) > 1 {
+	if matches := taskIDRe.FindStringSubmatch(content); len(matches) > 1 {
 		data.TaskID = strings.TrimSpace(matches[1])
 	}
 
 	// Extract status
-	if matches := regexp.MustCompile(`<status>(.*?)</status>`).FindStringSubmatch(content); len(matches) > 1 {
+	if matches := taskStatusRe.FindStringSubmatch(content); len(matches) > 1 {
 		data.Status = strings.TrimSpace(matches[1])
 	}
 
 	// Extract summary
-	if matches := regexp.MustCompile(`<summary>(.*?)</summary>`).FindStringSubmatch(content); len(matches) > 1 {
+	if matches := taskSummaryRe.FindStringSubmatch(content); len(matches) > 1 {
 		data.Summary = strings.TrimSpace(matches[1])
 	}
 
-	// Extract result (may contain newlines, use (?s) for dot-all mode)
-	if matches := regexp.MustCompile(`(?s)<result>(.*?)</result>`).FindStringSubmatch(content); len(matches) > 1 {
+	// Extract result (may contain newlines)
+	if matches := taskResultRe.FindStringSubmatch(content); len(matches) > 1 {
 		data.Result = strings.TrimSpace(matches[1])
 	}
 
@@ -1487,23 +2683,31 @@ func buildFileURL(path string) string {
 // formatUserContent formats user message content, processing XML-like tags for better display.
 // This improves readability of bash-stdout, bash-stderr, and other tool result XML blocks in USER INPUT messages.
 // Empty tags are hidden, and non-empty tags are wrapped in styled divs with proper spacing.
+// Attached files are rendered as code blocks (see formatUserContentWith).
 func formatUserContent(content string) string {
+	return formatUserContentWith(content, "")
+}
+
+// xmlTagBlockRe matches an XML-like tag block: <tag-name attrs>content</any-tag-name>.
+// Go's regexp doesn't support backreferences, so both tag names are captured and
+// formatXMLTags verifies they match. (?s) makes . match newlines.
+var xmlTagBlockRe = regexp.MustCompile(`(?s)<([a-z][a-z0-9\-]*)((?:\s+[^>]*)?)>(.*?)</([a-z][a-z0-9\-]*)>`)
+
+// formatXMLTags escapes content, wrapping each non-empty XML-like tag block in a styled div
+// and dropping empty ones (the tag handling of formatUserContent). File @-mentions
+// outside the tag blocks are rendered by formatMentions against projectPath.
+func formatXMLTags(content, projectPath string) string {
 	if content == "" {
 		return ""
 	}
 
-	// Go's regexp doesn't support backreferences, so we match both tags and verify they match
-	// Pattern: <tag-name>content</any-tag-name>
-	// Use (?s) flag to make . match newlines
-	tagPattern := regexp.MustCompile(`(?s)<([a-z][a-z0-9\-]*)((?:\s+[^>]*)?)>(.*?)</([a-z][a-z0-9\-]*)>`)
-
 	// Find all XML-like tag blocks
-	matches := tagPattern.FindAllStringSubmatch(content, -1)
-	matchIndices := tagPattern.FindAllStringSubmatchIndex(content, -1)
+	matches := xmlTagBlockRe.FindAllStringSubmatch(content, -1)
+	matchIndices := xmlTagBlockRe.FindAllStringSubmatchIndex(content, -1)
 
 	if len(matches) == 0 {
 		// No XML tags found, just escape and return
-		return escapeHTML(content)
+		return formatMentions(content, projectPath)
 	}
 
 	var result strings.Builder
@@ -1523,7 +2727,7 @@ func formatUserContent(content string) string {
 		// Add any text before this tag
 		if matchIndex[0] > lastEnd {
 			beforeText := content[lastEnd:matchIndex[0]]
-			result.WriteString(escapeHTML(beforeText))
+			result.WriteString(formatMentions(beforeText, projectPath))
 		}
 
 		// Skip empty tags (e.g., <bash-stderr></bash-stderr>)
@@ -1543,7 +2747,7 @@ func formatUserContent(content string) string {
 
 	// Add any remaining text after the last tag
 	if lastEnd < len(content) {
-		result.WriteString(escapeHTML(content[lastEnd:]))
+		result.WriteString(formatMentions(content[lastEnd:], projectPath))
 	}
 
 	return result.String()
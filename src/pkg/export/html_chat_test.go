@@ -61,6 +61,32 @@ func TestChatBubble_UserMessageLayout(t *testing.T) {
 	}
 }
 
+// TestChatBubble_AssistantStringContent verifies assistant messages whose content is a bare
+// string (not an array of blocks) render as a visible assistant bubble.
+func TestChatBubble_AssistantStringContent(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{
+			UUID:      "uuid-assistant-str",
+			SessionID: "session-001",
+			Type:      models.EntryTypeAssistant,
+			Timestamp: "2026-01-31T14:31:00Z",
+			Message:   json.RawMessage(`{"role":"assistant","content":"plain text"}`),
+		},
+	}
+
+	html, err := RenderConversation(entries, nil)
+	if err != nil {
+		t.Fatalf("RenderConversation() error = %v", err)
+	}
+
+	if !strings.Contains(html, `<div class="message-row assistant" data-uuid="uuid-assistant-str">`) {
+		t.Error("String-content assistant message should render as an assistant bubble")
+	}
+	if !strings.Contains(html, `<div class="text markdown-content">plain text</div>`) {
+		t.Error("String-content assistant text not rendered")
+	}
+}
+
 // TestChatBubble_AssistantMessageLayout verifies assistant messages use right-aligned chat bubble layout.
 func TestChatBubble_AssistantMessageLayout(t *testing.T) {
 	entries := []models.ConversationEntry{
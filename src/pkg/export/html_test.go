@@ -7,6 +7,7 @@ import (
 
 	"github.com/randlee/claude-history/pkg/agent"
 	"github.com/randlee/claude-history/pkg/models"
+	"github.com/randlee/claude-history/pkg/session"
 )
 
 func TestRenderConversation_BasicStructure(t *testing.T) {
@@ -181,11 +182,11 @@ func TestRenderConversation_ToolCall(t *testing.T) {
 	if !strings.Contains(html, `class="tool-body hidden collapsible-content collapsed"`) {
 		t.Error("HTML missing hidden tool-body class with collapsible content")
 	}
-	if !strings.Contains(html, `class="tool-input"`) {
-		t.Error("HTML missing tool-input class")
+	if !strings.Contains(html, `<pre class="bash-command"><span class="bash-prompt" aria-hidden="true">$ </span>git status</pre>`) {
+		t.Error("HTML missing Bash command with prompt")
 	}
-	if !strings.Contains(html, `class="tool-output"`) {
-		t.Error("HTML missing tool-output class")
+	if !strings.Contains(html, `class="tool-output bash-output"`) {
+		t.Error("HTML missing bash-output class")
 	}
 	if !strings.Contains(html, "On branch main") {
 		t.Error("HTML missing tool output content")
@@ -697,6 +698,26 @@ func TestFormatToolSummary_AllToolTypes(t *testing.T) {
 			tool:     models.ToolUse{Name: "Edit", Input: map[string]any{"file_path": "/edit/file.go"}},
 			expected: "[Edit] /edit/file.go",
 		},
+		{
+			name:     "MultiEdit",
+			tool:     models.ToolUse{Name: "MultiEdit", Input: map[string]any{"file_path": "/edit/file.go", "edits": []any{map[string]any{}, map[string]any{}, map[string]any{}}}},
+			expected: "[MultiEdit] /edit/file.go (3 edits)",
+		},
+		{
+			name:     "MultiEdit with one edit",
+			tool:     models.ToolUse{Name: "MultiEdit", Input: map[string]any{"file_path": "/edit/file.go", "edits": []any{map[string]any{}}}},
+			expected: "[MultiEdit] /edit/file.go (1 edit)",
+		},
+		{
+			name:     "MultiEdit without edits",
+			tool:     models.ToolUse{Name: "MultiEdit", Input: map[string]any{"file_path": "/edit/file.go"}},
+			expected: "[MultiEdit] /edit/file.go",
+		},
+		{
+			name:     "ApplyPatch",
+			tool:     models.ToolUse{Name: "ApplyPatch", Input: map[string]any{"input": "*** Begin Patch\n*** Add File: docs/new.md\n+hi\n*** End Patch"}},
+			expected: "[ApplyPatch] docs/new.md",
+		},
 		{
 			name:     "Grep",
 			tool:     models.ToolUse{Name: "Grep", Input: map[string]any{"pattern": "func.*Test"}},
@@ -758,12 +779,10 @@ func TestFormatToolSummary_LongInput(t *testing.T) {
 
 	result := formatToolSummary(tool)
 
-	// Should be truncated
-	if len(result) > 70 {
-		t.Errorf("formatToolSummary() length = %d, should be truncated", len(result))
-	}
-	if !strings.HasSuffix(result, "...") {
-		t.Error("Truncated summary should end with ...")
+	// Should be truncated, saying how much is hidden
+	want := "[Bash] " + strings.Repeat("a", DefaultSummaryMaxLen-1) + "… (+41 chars)"
+	if result != want {
+		t.Errorf("formatToolSummary() = %q, want %q", result, want)
 	}
 }
 
@@ -936,11 +955,11 @@ func TestRenderToolCall_NoResult(t *testing.T) {
 	if !strings.Contains(html, `class="tool-call collapsible collapsed"`) {
 		t.Error("HTML missing tool-call class with collapsible collapsed")
 	}
-	if !strings.Contains(html, `class="tool-input"`) {
-		t.Error("HTML missing tool-input class")
+	if !strings.Contains(html, `class="bash-command"`) {
+		t.Error("HTML missing bash-command class")
 	}
 	// Should NOT have tool-output when hasResult is false
-	if strings.Contains(html, `class="tool-output"`) {
+	if strings.Contains(html, `class="tool-output`) {
 		t.Error("HTML should not have tool-output when hasResult is false")
 	}
 }
@@ -1063,7 +1082,7 @@ func TestRenderSubagentPlaceholder_LongAgentID(t *testing.T) {
 
 	html := renderSubagentPlaceholder("a12eb64abc123def456", agentMap, "session-456", "/test/project")
 
-	// Long IDs should be truncated to 7 chars in display
+	// Long IDs should be truncated in display
 	if !strings.Contains(html, "Subagent: a12eb64") {
 		t.Error("Long agent ID should be truncated in display")
 	}
@@ -1073,6 +1092,45 @@ func TestRenderSubagentPlaceholder_LongAgentID(t *testing.T) {
 	}
 }
 
+func TestRenderSubagentPlaceholder_SyntheticAgentID(t *testing.T) {
+	agentID := "aprompt_suggestion-abc123def"
+	html := renderSubagentPlaceholder(agentID, map[string]int{agentID: 2}, "session-456", "/test/project")
+
+	if !strings.Contains(html, "Subagent: abc123de") {
+		t.Errorf("Synthetic agent ID should display its normalized short form, got:\n%s", html)
+	}
+	if !strings.Contains(html, `<span class="subagent-type">Prompt suggestion</span>`) {
+		t.Error("Synthetic agent ID should show its type label")
+	}
+	if !strings.Contains(html, `data-agent-id="aprompt_suggestion-abc123def"`) {
+		t.Error("Full agent ID should be in data attribute")
+	}
+}
+
+func TestRenderSubagentPlaceholder_UnusualCharacters(t *testing.T) {
+	agentID := `a1" onclick="x<y>`
+	html := renderSubagentPlaceholder(agentID, map[string]int{}, "session-456", "/test/project")
+
+	if strings.Contains(html, `onclick="x`) {
+		t.Error("Agent ID quotes must not break out of the data-agent-id attribute")
+	}
+	if !strings.Contains(html, `data-agent-id="a1&#34; onclick=&#34;x&lt;y&gt;"`) {
+		t.Errorf("Agent ID should be escaped in data attribute, got:\n%s", html)
+	}
+}
+
+func TestRenderAgentIDWithCopy_SyntheticAgentID(t *testing.T) {
+	entry := models.ConversationEntry{UUID: "uuid-1", Type: models.EntryTypeAssistant}
+	html := renderAgentIDWithCopy(entry, "aexplore-def456789", "session-1", "aexplore-def456789", "/test/project", "Assistant")
+
+	if !strings.Contains(html, `<span class="agent-id-badge" title="Explore">def45678`) {
+		t.Errorf("Agent badge should use normalized short ID and type label, got:\n%s", html)
+	}
+	if !strings.Contains(html, "aexplore-def456789") {
+		t.Error("Copy context should keep the full agent ID")
+	}
+}
+
 func TestRenderSubagentPlaceholder_ZeroEntries(t *testing.T) {
 	agentMap := map[string]int{}
 
@@ -1084,6 +1142,35 @@ func TestRenderSubagentPlaceholder_ZeroEntries(t *testing.T) {
 	}
 }
 
+func TestRenderConversation_SubagentDescription(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "uuid-001", SessionID: "session-001", Type: models.EntryTypeQueueOperation, AgentID: "a12eb64abc123", Timestamp: "2026-01-31T10:00:00Z", Message: json.RawMessage(`"Agent spawned"`)},
+		{UUID: "uuid-002", SessionID: "session-001", Type: models.EntryTypeQueueOperation, AgentID: "b98f7e6def456", Timestamp: "2026-01-31T10:00:01Z", Message: json.RawMessage(`"Agent spawned"`)},
+	}
+	description := "Audit the <auth> module for token leaks across every handler and middleware layer"
+	agents := []*agent.TreeNode{
+		{AgentID: "a12eb64abc123", SessionID: "session-001", EntryCount: 29, Description: description},
+		{AgentID: "b98f7e6def456", SessionID: "session-001", EntryCount: 3},
+	}
+
+	html, err := RenderConversation(entries, agents)
+	if err != nil {
+		t.Fatalf("RenderConversation() error = %v", err)
+	}
+
+	// The description titles the section, truncated, with the ID after it
+	want := `<span class="subagent-title" title="Audit the &lt;auth&gt; module for token leaks across every handler and middleware layer">` +
+		`Subagent: Audit the &lt;auth&gt; module for token leaks across every hand...</span> <code class="subagent-id">a12eb64a</code>`
+	if !strings.Contains(html, want) {
+		t.Errorf("HTML missing described title %q", want)
+	}
+
+	// Agents without a description keep the ID as their title
+	if !strings.Contains(html, `<span class="subagent-title">Subagent: b98f7e6d</span>`) {
+		t.Error("HTML missing ID title for agent without description")
+	}
+}
+
 func TestRenderConversation_MultilineContent(t *testing.T) {
 	entries := []models.ConversationEntry{
 		{
@@ -1359,3 +1446,103 @@ func TestRenderConversation_ToolOnlyVsTextWithTools(t *testing.T) {
 		t.Error("Second message (tool only) should NOT have 'Assistant' label")
 	}
 }
+
+func TestRenderEntry_ServerToolUse(t *testing.T) {
+	entry := models.ConversationEntry{
+		UUID:      "srv-1",
+		Type:      models.EntryTypeAssistant,
+		Timestamp: "2026-02-01T10:00:00Z",
+		Message:   json.RawMessage(`{"role":"assistant","content":[{"type":"server_tool_use","id":"srvtoolu_01","name":"web_search","input":{"query":"go generics"}}]}`),
+	}
+
+	if !hasContent(entry) {
+		t.Fatal("entry with only a server tool call should have content")
+	}
+	html := renderEntry(entry, nil, "", "", "", "User", "Assistant")
+
+	if !strings.Contains(html, `<div class="tool-call collapsible collapsed" id="tool-srvtoolu_01" data-tool-id="srvtoolu_01">`) {
+		t.Errorf("server tool should use the collapsible tool UI, got:\n%s", html)
+	}
+	if !strings.Contains(html, "go generics") {
+		t.Errorf("server tool input should be rendered, got:\n%s", html)
+	}
+}
+
+func TestRenderEntry_Interruption(t *testing.T) {
+	entry := models.ConversationEntry{
+		UUID:      "int-1",
+		Type:      models.EntryTypeUser,
+		Timestamp: "2026-02-01T10:00:00Z",
+		Message:   json.RawMessage(`{"role":"user","content":[{"type":"text","text":"[Request interrupted by user]"}]}`),
+	}
+
+	html := renderEntry(entry, nil, "", "", "", "User", "Assistant")
+
+	if !strings.Contains(html, `<div class="interruption-marker" data-uuid="int-1"`) {
+		t.Errorf("interruption should render as an inline marker, got:\n%s", html)
+	}
+	if !strings.Contains(html, "Interrupted by user") {
+		t.Error("marker should be labelled")
+	}
+	if strings.Contains(html, `class="message-row`) || strings.Contains(html, "message-bubble") {
+		t.Error("interruption should not render a message bubble")
+	}
+}
+
+func TestRenderConversation_StructuredInterruption(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{
+			UUID:      "a1",
+			Type:      models.EntryTypeAssistant,
+			Timestamp: "2026-02-01T10:00:00Z",
+			Message:   json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"sleep 100"}}]}`),
+		},
+		{
+			UUID:          "u1",
+			Type:          models.EntryTypeUser,
+			Timestamp:     "2026-02-01T10:00:05Z",
+			Message:       json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"partial"}]}`),
+			ToolUseResult: &models.ToolUseResult{Interrupted: true},
+		},
+	}
+
+	html, err := RenderConversation(entries, nil)
+	if err != nil {
+		t.Fatalf("RenderConversation() error = %v", err)
+	}
+
+	if !strings.Contains(html, `<div class="interruption-marker" data-uuid="u1"`) {
+		t.Error("tool-result-only interruption should still be shown as a marker")
+	}
+	if !strings.Contains(html, "Tool use interrupted by user") {
+		t.Error("tool interruption should use the tool label")
+	}
+	if !strings.Contains(html, `data-tool-result-for="t1"`) {
+		t.Error("interrupted output should still be shown with its tool call")
+	}
+}
+
+func TestRenderConversation_SpawnsOnlyKeepsPlaceholders(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: json.RawMessage(`"Explore"`)},
+		{UUID: "spawn", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:01Z",
+			Message:       json.RawMessage(`[{"type":"tool_result","tool_use_id":"toolu_1","content":[]}]`),
+			ToolUseResult: &models.ToolUseResult{Status: "async_launched", AgentID: "a12eb64"}},
+		{UUID: "queue", Type: models.EntryTypeQueueOperation, Timestamp: "2026-02-01T10:00:01Z", AgentID: "a12eb64"},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:02Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Unrelated"}]}`)},
+	}
+	agents := []*agent.TreeNode{{AgentID: "a12eb64", EntryCount: 3}}
+
+	filtered := session.FilterEntries(entries, session.FilterOptions{SpawnsOnly: true})
+	html, err := RenderConversation(filtered, agents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, `<div class="subagent collapsible collapsed" id="agent-a12eb64"`) {
+		t.Error("spawn entries kept by SpawnsOnly should still render their subagent placeholder")
+	}
+	if strings.Contains(html, "Unrelated") {
+		t.Error("non-spawn entries should be filtered out")
+	}
+}
@@ -0,0 +1,68 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// incompleteTestEntries returns a session whose last assistant message is waiting on
+// a tool result that was never written.
+func incompleteTestEntries() []models.ConversationEntry {
+	return []models.ConversationEntry{
+		{
+			UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z",
+			Message: json.RawMessage(`{"role":"user","content":"list files"}`),
+		},
+		{
+			UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:01Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"ls"}}]}`),
+		},
+	}
+}
+
+func TestComputeSessionStats_IncompleteReason(t *testing.T) {
+	stats := ComputeSessionStats(incompleteTestEntries(), nil)
+	if !strings.Contains(stats.IncompleteReason, "without a result (Bash)") {
+		t.Errorf("IncompleteReason = %q, want the unanswered Bash call", stats.IncompleteReason)
+	}
+
+	if stats := ComputeSessionStats(exporterTestEntries(), nil); stats.IncompleteReason != "" {
+		t.Errorf("complete session should have no IncompleteReason, got %q", stats.IncompleteReason)
+	}
+}
+
+func TestRenderHTMLHeader_IncompleteBadge(t *testing.T) {
+	html := renderHTMLHeader(&SessionStats{IncompleteReason: `1 tool call <Bash>`}, nil, localizer{})
+	if !strings.Contains(html, `<span class="meta-item incomplete-badge" title="1 tool call &lt;Bash&gt;">`) {
+		t.Errorf("header should show an escaped incomplete badge, got:\n%s", html)
+	}
+
+	if html := renderHTMLHeader(&SessionStats{}, nil, localizer{}); strings.Contains(html, "incomplete-badge") {
+		t.Error("complete session should not show the incomplete badge")
+	}
+}
+
+func TestStatsSummaryLines_IncompleteStatus(t *testing.T) {
+	lines := statsSummaryLines(&SessionStats{IncompleteReason: "reason"}, localizer{})
+	last := lines[len(lines)-1]
+	if last[0] != "Status" || last[1] != "incomplete: reason" {
+		t.Errorf("expected a trailing Status line, got %v", lines)
+	}
+}
+
+func TestRenderConversationJSON_IncompleteReason(t *testing.T) {
+	entries := incompleteTestEntries()
+	data, err := RenderConversationJSON(entries, nil, ComputeSessionStats(entries, nil), nil)
+	if err != nil {
+		t.Fatalf("RenderConversationJSON() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"incomplete_reason"`) {
+		t.Errorf("JSON stats should include incomplete_reason, got:\n%s", data)
+	}
+	if err := validateAgainstSchema(t, compileExportSchema(t), data); err != nil {
+		t.Errorf("JSON export does not match schema: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// entryRawJSON returns the entry's original JSON line, indented. Entries that were not
+// read from a file (no RawLine) are marshaled from their parsed fields instead.
+func entryRawJSON(entry models.ConversationEntry) string {
+	var buf bytes.Buffer
+	if len(entry.RawLine) > 0 && json.Indent(&buf, entry.RawLine, "", "  ") == nil {
+		return buf.String()
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// renderRawInspector renders the collapsed "🔧 raw" block showing entry's original JSON
+// (see ExportOptions.DebugInspector). label, if not empty, follows "raw" in the toggle,
+// telling apart the entries of a combined turn. It returns "" when the inspector is off.
+func renderRawInspector(entry models.ConversationEntry, label string, ro entryRenderOptions) string {
+	if !ro.opts.DebugInspector {
+		return ""
+	}
+	raw := entryRawJSON(entry)
+	if raw == "" {
+		return ""
+	}
+
+	summary := "🔧 raw"
+	if label != "" {
+		summary += " " + label
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`    <details class="raw-inspector" data-uuid="%s">`, escapeHTML(entry.UUID)))
+	sb.WriteString(fmt.Sprintf(`<summary title="Original JSON of this entry">%s</summary>`, escapeHTML(summary)))
+	sb.WriteString(fmt.Sprintf(`<pre class="raw-json"><code>%s</code></pre>`, escapeHTML(raw)))
+	sb.WriteString("</details>\n")
+	return sb.String()
+}
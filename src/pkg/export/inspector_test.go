@@ -0,0 +1,140 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestEntryRawJSON_IndentsRawLine(t *testing.T) {
+	entry := models.ConversationEntry{
+		UUID:    "u1",
+		RawLine: json.RawMessage(`{"uuid":"u1","type":"user","unmodeled":{"a":1},"message":"hi"}`),
+	}
+	want := `{
+  "uuid": "u1",
+  "type": "user",
+  "unmodeled": {
+    "a": 1
+  },
+  "message": "hi"
+}`
+	if got := entryRawJSON(entry); got != want {
+		t.Errorf("entryRawJSON() = %s, want %s", got, want)
+	}
+}
+
+func TestEntryRawJSON_FallsBackToParsedFields(t *testing.T) {
+	entry := models.ConversationEntry{UUID: "u1", Type: models.EntryTypeUser, Message: json.RawMessage(`"hi"`)}
+	got := entryRawJSON(entry)
+	for _, want := range []string{`"uuid": "u1"`, `"type": "user"`, `"message": "hi"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("entryRawJSON() = %s, missing %s", got, want)
+		}
+	}
+
+	// A raw line that isn't valid JSON is not shown as-is either
+	entry.RawLine = json.RawMessage(`{"uuid":`)
+	if got := entryRawJSON(entry); !strings.Contains(got, `"uuid": "u1"`) {
+		t.Errorf("entryRawJSON() = %s, want marshaled fields", got)
+	}
+}
+
+func TestRenderRawInspector_EscapesHTML(t *testing.T) {
+	entry := models.ConversationEntry{
+		UUID:    `u"1`,
+		RawLine: json.RawMessage(`{"message":"</code></pre><script>alert(1)</script> & more"}`),
+	}
+	html := renderRawInspector(entry, "", entryRenderOptions{opts: ExportOptions{DebugInspector: true}})
+
+	if strings.Contains(html, "<script>") || strings.Contains(html, "</code></pre><script") {
+		t.Errorf("raw JSON was not escaped:\n%s", html)
+	}
+	for _, want := range []string{
+		`<details class="raw-inspector" data-uuid="u&#34;1">`,
+		`<summary title="Original JSON of this entry">🔧 raw</summary>`,
+		`&lt;script&gt;alert(1)&lt;/script&gt; &amp; more`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("inspector missing %s:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<details open") {
+		t.Error("inspector should start collapsed")
+	}
+}
+
+func TestRenderRawInspector_Off(t *testing.T) {
+	entry := models.ConversationEntry{UUID: "u1", RawLine: json.RawMessage(`{"uuid":"u1"}`)}
+	if html := renderRawInspector(entry, "", entryRenderOptions{}); html != "" {
+		t.Errorf("renderRawInspector() = %q, want empty when DebugInspector is off", html)
+	}
+}
+
+func TestRenderConversation_DebugInspector(t *testing.T) {
+	entries := showAllTestEntries()
+	entries[0].RawLine = json.RawMessage(`{"uuid":"u1","type":"user","cwd":"/secret/project","message":"Hello"}`)
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if strings.Contains(html, `class="raw-inspector"`) || strings.Contains(html, "/secret/project") {
+		t.Error("inspector should be off by default")
+	}
+
+	html, err = RenderConversationWithOptions(entries, nil, nil, ExportOptions{DebugInspector: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	// One per rendered message (the user prompt and the tool call), inside its bubble
+	if got := strings.Count(html, `<details class="raw-inspector"`); got != 2 {
+		t.Errorf("inspector count = %d, want 2", got)
+	}
+	row := html[strings.Index(html, `data-uuid="u1"`):]
+	inspector := strings.Index(row, `<details class="raw-inspector" data-uuid="u1">`)
+	bubbleEnd := strings.Index(row, "  </div>\n</div>")
+	if inspector < 0 || inspector > bubbleEnd {
+		t.Errorf("u1 inspector should be inside its message bubble")
+	}
+	if !strings.Contains(row, `&#34;cwd&#34;: &#34;/secret/project&#34;`) {
+		t.Error("inspector should show the full raw line, pretty-printed")
+	}
+	// Raw JSON stays out of the searchable message content
+	content := row[strings.Index(row, `<div class="message-content">`):inspector]
+	if strings.Contains(content, "/secret/project") {
+		t.Error("raw JSON should not be part of the message content")
+	}
+}
+
+func TestRenderConversation_DebugInspectorWithShowAll(t *testing.T) {
+	html, err := RenderConversationWithOptions(showAllTestEntries(), nil, nil, ExportOptions{ShowAll: true, DebugInspector: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	// Debug rows get their inspector right after the row
+	if !strings.Contains(html, "</div>\n    <details class=\"raw-inspector\" data-uuid=\"ws1\">") {
+		t.Error("debug row should be followed by its inspector")
+	}
+}
+
+func TestRenderConversation_DebugInspectorCombinedTurn(t *testing.T) {
+	html, err := RenderConversationWithOptions(combineTestEntries(), nil, nil, ExportOptions{CombineToolMessages: true, DebugInspector: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	for i, uuid := range []string{"a1", "a2", "a3"} {
+		want := `<details class="raw-inspector" data-uuid="` + uuid + `"><summary title="Original JSON of this entry">🔧 raw ` + string(rune('1'+i)) + `/3</summary>`
+		if !strings.Contains(html, want) {
+			t.Errorf("combined turn missing inspector %s", want)
+		}
+	}
+}
+
+func TestControlsJS_ExpandAllSkipsRawInspectors(t *testing.T) {
+	if !strings.Contains(GetControlsJS(), "details:not(.raw-inspector)") {
+		t.Error("Expand All should leave raw inspectors closed")
+	}
+}
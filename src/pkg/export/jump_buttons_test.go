@@ -0,0 +1,60 @@
+package export
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderHTMLFooter_JumpButtons(t *testing.T) {
+	footer := renderHTMLFooterWith(nil, ExportOptions{})
+
+	if strings.Count(footer, `class="jump-buttons"`) != 1 {
+		t.Fatalf("footer should contain the jump buttons once, got:\n%s", footer)
+	}
+	for _, want := range []string{
+		`<button type="button" id="jump-top-btn" class="jump-btn" title="Jump to top" aria-label="Jump to top" hidden>`,
+		`<button type="button" id="jump-latest-btn" class="jump-btn" title="Jump to latest message" aria-label="Jump to latest message" hidden>`,
+	} {
+		if !strings.Contains(footer, want) {
+			t.Errorf("footer missing %q", want)
+		}
+	}
+	if strings.Index(footer, "jump-buttons") > strings.Index(footer, "static/controls.js") {
+		t.Error("jump buttons should come before the scripts that wire them up")
+	}
+
+	// The buttons need scripts, so script-free exports leave them out
+	if strings.Contains(renderHTMLFooterWith(nil, ExportOptions{NoJS: true}), "jump-buttons") {
+		t.Error("no-js footer should not contain the jump buttons")
+	}
+}
+
+func TestGetControlsJS_JumpButtons(t *testing.T) {
+	js := GetControlsJS()
+	for _, want := range []string{
+		"function initJumpButtons()",
+		"function jumpToLatest()",
+		"function updateJumpButtons()",
+		"getElementById('jump-latest-btn')",
+		"element.focus({ preventScroll: true })",
+		"initJumpButtons();",
+	} {
+		if !strings.Contains(js, want) {
+			t.Errorf("controls.js missing %q", want)
+		}
+	}
+}
+
+func TestCSSContent_JumpButtons(t *testing.T) {
+	css := GetStyleCSS()
+	for _, want := range []string{".jump-buttons {", ".jump-btn:focus-visible {", ".jump-btn[hidden] {"} {
+		if !strings.Contains(css, want) {
+			t.Errorf("style.css missing %q", want)
+		}
+	}
+
+	printCSS := css[strings.Index(css, "@media print {"):]
+	if !strings.Contains(printCSS[:strings.Index(printCSS, "display: none;")], ".jump-buttons") {
+		t.Error("jump buttons should be hidden when printing")
+	}
+}
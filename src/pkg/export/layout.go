@@ -0,0 +1,121 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// Kinds of RenderedEntry blocks in a conversation.
+const (
+	BlockMessage       = "message"        // A rendered conversation entry
+	BlockTodos         = "todos"          // Consecutive TodoWrite calls collapsed into one checklist
+	BlockSubagent      = "subagent"       // A lazy-loaded subagent placeholder
+	BlockPageBreak     = "page-break"     // A print page break (only with ExportOptions.Paginate)
+	BlockDay           = "day"            // A date header (only with ExportOptions.DaySeparators)
+	BlockGap           = "gap"            // A long pause between messages (only with ExportOptions.ShowGaps)
+	BlockCompaction    = "compaction"     // Where the context was compacted (see models.ConversationEntry.IsCompactionBoundary)
+	BlockDebug         = "debug"          // An entry without displayable content (only with ExportOptions.ShowAll)
+	BlockAgentOverflow = "agent-overflow" // Agents left out by ExportOptions.MaxAgents, listed by ID
+)
+
+// RenderedEntry is one block of the rendered conversation, in display order.
+type RenderedEntry struct {
+	Kind      string           // One of the Block* constants
+	UUID      string           // UUID of the source entry (empty for page breaks and date headers)
+	Type      models.EntryType // Type of the source entry (empty for page breaks and date headers)
+	Timestamp string           // Raw timestamp of the source entry
+	HTML      template.HTML    // Rendered markup for the block
+}
+
+// LayoutData is the context passed to the HTML page layout template.
+type LayoutData struct {
+	Stats         *SessionStats
+	Agents        []*agent.TreeNode // Top-level agents; nested agents are in Children
+	Entries       []RenderedEntry
+	FormatVersion string
+
+	// Pre-rendered page parts used by the built-in layout
+	Header       template.HTML // Doctype, <head>, and page header with session metadata
+	Timeline     template.HTML // Subagent timeline panel (empty unless ExportOptions.Timeline is set)
+	Conversation template.HTML // All Entries wrapped in <div class="conversation">
+	Footer       template.HTML // Page footer, scripts, and closing tags
+
+	// Page is the position of this page in a paginated export (see ExportOptions.PageSize);
+	// nil for single-file exports and the page index
+	Page    *PageInfo
+	PageNav template.HTML // Previous/next page links, empty unless Page is set
+}
+
+// defaultLayoutName is the embedded layout used when no template file is given.
+const defaultLayoutName = "layout.html"
+
+// layoutTemplate is a parsed page layout together with its source, kept for error context.
+type layoutTemplate struct {
+	tmpl *template.Template
+	src  []byte
+}
+
+// loadLayoutTemplate parses the page layout from path, or the built-in layout if path is empty.
+func loadLayoutTemplate(path string) (*layoutTemplate, error) {
+	name := defaultLayoutName
+	var src []byte
+	var err error
+	if path == "" {
+		src, err = templatesFS.ReadFile("templates/" + defaultLayoutName)
+	} else {
+		name = filepath.Base(path)
+		src, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+
+	tmpl, err := template.New(name).Parse(string(src))
+	if err != nil {
+		return nil, templateError("failed to parse template", err, src)
+	}
+	return &layoutTemplate{tmpl: tmpl, src: src}, nil
+}
+
+// CheckTemplateFile reports whether path is a readable, parseable page layout template.
+// Parse errors include the offending template line.
+func CheckTemplateFile(path string) error {
+	_, err := loadLayoutTemplate(path)
+	return err
+}
+
+// executeLayout renders the page layout with data.
+func executeLayout(layout *layoutTemplate, data LayoutData) (string, error) {
+	var buf bytes.Buffer
+	if err := layout.tmpl.Execute(&buf, data); err != nil {
+		return "", templateError("failed to execute template", err, layout.src)
+	}
+	return buf.String(), nil
+}
+
+// templateLineRe extracts the line number from html/template errors such as
+// "template: page.html:12: unexpected ..." or "template: page.html:12:5: executing ...".
+var templateLineRe = regexp.MustCompile(`^template: [^:]*:(\d+):`)
+
+// templateError wraps a template error, appending the offending source line when known.
+func templateError(msg string, err error, src []byte) error {
+	m := templateLineRe.FindStringSubmatch(err.Error())
+	if m == nil {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+	line, _ := strconv.Atoi(m[1])
+	lines := strings.Split(string(src), "\n")
+	if line < 1 || line > len(lines) {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+	return fmt.Errorf("%s: %w\n  %d | %s", msg, err, line, strings.TrimRight(lines[line-1], "\r"))
+}
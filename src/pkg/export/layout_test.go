@@ -0,0 +1,162 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// writeLayout writes a layout template to a temp file and returns its path.
+func writeLayout(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "layout.html")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write layout: %v", err)
+	}
+	return path
+}
+
+func TestRenderConversationWithOptions_DefaultLayoutIsConcatenation(t *testing.T) {
+	entries := paginateTestEntries(6)
+	entries[0].SessionID = "sess-layout"
+	entries = append(entries, models.ConversationEntry{UUID: "q1", Type: models.EntryTypeQueueOperation, AgentID: "agent-abc"})
+	opts := ExportOptions{Paginate: true, PageBreakEvery: 2}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, opts)
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+
+	// The built-in layout must add nothing around the pre-rendered parts
+	stats := ComputeSessionStats(entries, nil)
+	agentMap := buildAgentMap(nil)
+	var want strings.Builder
+	want.WriteString(renderHTMLHeader(stats, agentMap, localizer{}))
+	want.WriteString(`<div class="conversation paginated">` + "\n")
+	for _, block := range renderConversationBlocks(entries, agentMap, stats, opts) {
+		want.WriteString(string(block.HTML))
+	}
+	want.WriteString("</div>\n")
+	want.WriteString(renderHTMLFooter(stats))
+
+	if html != want.String() {
+		t.Error("default layout output should be exactly header + conversation + footer")
+	}
+
+	// The byte-identical guarantee covers the layout only; the page chrome added since
+	// the layout was introduced is on by default
+	for _, chrome := range []string{`class="controls-group type-filters"`, `id="jump-latest-btn"`, `class="copy-btn copy-message-btn"`, `class="meta-item session-context"`} {
+		if !strings.Contains(html, chrome) {
+			t.Errorf("default page missing %s", chrome)
+		}
+	}
+}
+
+func TestRenderConversationBlocks_Kinds(t *testing.T) {
+	entries := paginateTestEntries(3)
+	entries = append(entries, models.ConversationEntry{UUID: "q1", Type: models.EntryTypeQueueOperation, AgentID: "agent-abc"})
+
+	blocks := renderConversationBlocks(entries, nil, &SessionStats{}, ExportOptions{Paginate: true, PageBreakEvery: 2})
+
+	var kinds []string
+	for _, b := range blocks {
+		kinds = append(kinds, b.Kind)
+	}
+	want := "message,message,page-break,message,page-break,subagent"
+	if got := strings.Join(kinds, ","); got != want {
+		t.Fatalf("block kinds = %s, want %s", got, want)
+	}
+	if blocks[0].UUID != "e0" || blocks[0].Type != models.EntryTypeUser || blocks[0].Timestamp == "" {
+		t.Errorf("message block should carry entry metadata, got %+v", blocks[0])
+	}
+	if blocks[2].UUID != "" {
+		t.Errorf("page break should not carry entry metadata, got %+v", blocks[2])
+	}
+	if blocks[5].UUID != "q1" {
+		t.Errorf("subagent block UUID = %q, want q1", blocks[5].UUID)
+	}
+}
+
+func TestRenderConversationWithOptions_CustomTemplate(t *testing.T) {
+	path := writeLayout(t, `<main data-session="{{.Stats.SessionID}}" data-version="{{.FormatVersion}}">
+{{range .Entries}}{{if eq .Kind "message"}}<article data-entry="{{.UUID}}" data-type="{{.Type}}">{{.HTML}}</article>
+{{end}}{{end}}{{range .Agents}}<nav>{{.AgentID}}</nav>{{end}}
+</main>`)
+
+	stats := &SessionStats{SessionID: "sess-<1>"}
+	agents := []*agent.TreeNode{{AgentID: "agent-abc", EntryCount: 3}}
+	html, err := RenderConversationWithOptions(paginateTestEntries(2), agents, stats, ExportOptions{TemplateFile: path})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`<main data-session="sess-&lt;1&gt;" data-version="2.0">`,
+		`<article data-entry="e0" data-type="user">`,
+		`<article data-entry="e1" data-type="assistant">`,
+		`Answer 1`,
+		`<nav>agent-abc</nav>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("custom layout output should contain %q, got:\n%s", want, html)
+		}
+	}
+	// Entry bodies are inserted as HTML, not escaped
+	if strings.Contains(html, "&lt;div class=") {
+		t.Error("rendered entry HTML should not be escaped")
+	}
+	if strings.Contains(html, "<!DOCTYPE html>") {
+		t.Error("custom layout replaces the built-in header")
+	}
+}
+
+func TestRenderConversationWithOptions_TemplateParseErrorHasLineContext(t *testing.T) {
+	path := writeLayout(t, "<html>\n<body>\n{{if}}\n</body>")
+
+	_, err := RenderConversationWithOptions(paginateTestEntries(1), nil, nil, ExportOptions{TemplateFile: path})
+	if err == nil {
+		t.Fatal("expected parse error")
+	}
+	for _, want := range []string{"failed to parse template", "layout.html:3", "3 | {{if}}"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should contain %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestRenderConversationWithOptions_TemplateExecErrorHasLineContext(t *testing.T) {
+	path := writeLayout(t, "{{.Header}}\n{{.Stats.NoSuchField}}\n")
+
+	_, err := RenderConversationWithOptions(paginateTestEntries(1), nil, nil, ExportOptions{TemplateFile: path})
+	if err == nil {
+		t.Fatal("expected execution error")
+	}
+	for _, want := range []string{"failed to execute template", "NoSuchField", "2 | {{.Stats.NoSuchField}}"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should contain %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestCheckTemplateFile(t *testing.T) {
+	if err := CheckTemplateFile(writeLayout(t, "{{.Header}}{{.Footer}}")); err != nil {
+		t.Errorf("valid template: unexpected error %v", err)
+	}
+	if err := CheckTemplateFile(filepath.Join(t.TempDir(), "missing.html")); err == nil || !strings.Contains(err.Error(), "failed to read template") {
+		t.Errorf("missing template: expected read error, got %v", err)
+	}
+	if err := CheckTemplateFile(writeLayout(t, "{{end}}")); err == nil {
+		t.Error("invalid template: expected parse error")
+	}
+}
+
+func TestTemplateError_NoLineInfo(t *testing.T) {
+	err := templateError("failed", os.ErrNotExist, []byte("a\nb"))
+	if err.Error() != "failed: file does not exist" {
+		t.Errorf("templateError() = %q", err)
+	}
+}
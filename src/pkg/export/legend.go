@@ -0,0 +1,68 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// displayedEntryTypes lists the entry types of message rows, in the order the legend
+// and the header's type filters list them.
+var displayedEntryTypes = []models.EntryType{
+	models.EntryTypeUser,
+	models.EntryTypeAssistant,
+	models.EntryTypeSystem,
+	models.EntryTypeQueueOperation,
+	models.EntryTypeSummary,
+}
+
+// renderLegend renders the footer legend explaining the message colors and the
+// tool-call styling (see ExportOptions.ShowLegend). Each swatch is an avatar with the
+// class from getEntryClass and the name from getRoleLabel, the same sources the
+// messages use, so userLabel and assistantLabel must match the ones the conversation
+// was rendered with.
+func renderLegend(userLabel, assistantLabel string) string {
+	var sb strings.Builder
+
+	sb.WriteString(`    <div class="footer-legend">
+        <details open>
+            <summary>Legend</summary>
+            <ul class="legend-types">
+`)
+	for _, t := range displayedEntryTypes {
+		sb.WriteString(fmt.Sprintf(`                <li><span class="avatar %s" aria-hidden="true"></span> %s</li>
+`, getEntryClass(t), escapeHTML(getRoleLabel(t, userLabel, assistantLabel))))
+	}
+	sb.WriteString(`            </ul>
+            <ul class="legend-tools">
+                <li><span class="legend-sample legend-tool">Tool call</span> Click the header to show input and output</li>
+                <li><span class="legend-sample legend-tool-error">Error</span> Output of a tool call that failed</li>
+            </ul>
+        </details>
+    </div>
+`)
+
+	return sb.String()
+}
+
+// renderTypeFilters renders the header checkboxes that show or hide the messages of each
+// entry type in the browser (see controls.js). Like the legend, each checkbox takes its
+// type from getEntryClass, the class on the message rows it toggles, and its name from
+// getRoleLabel. "Remember" keeps the hidden types for the next visit.
+func renderTypeFilters(userLabel, assistantLabel string) string {
+	var sb strings.Builder
+
+	sb.WriteString(`        <div class="controls-group type-filters" role="group" aria-label="Show message types">
+`)
+	for _, t := range displayedEntryTypes {
+		sb.WriteString(fmt.Sprintf(`            <label class="type-filter"><input type="checkbox" data-entry-type="%s" checked> %s</label>
+`, getEntryClass(t), escapeHTML(getRoleLabel(t, userLabel, assistantLabel))))
+	}
+	sb.WriteString(`            <label class="type-filter type-filter-remember" title="Remember hidden message types for this session"><input type="checkbox" id="type-filter-remember"> Remember</label>
+            <span class="type-filter-count" aria-live="polite"></span>
+        </div>
+`)
+
+	return sb.String()
+}
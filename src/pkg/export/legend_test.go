@@ -0,0 +1,76 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRenderLegend(t *testing.T) {
+	legend := renderLegend("User", "Assistant")
+	for _, want := range []string{
+		`<span class="avatar user" aria-hidden="true"></span> User`,
+		`<span class="avatar assistant" aria-hidden="true"></span> Assistant`,
+		`<span class="avatar system" aria-hidden="true"></span> System`,
+		`<span class="avatar queue-operation" aria-hidden="true"></span> Agent Task`,
+		`<span class="avatar summary" aria-hidden="true"></span> Summary`,
+		`class="legend-sample legend-tool"`,
+		`class="legend-sample legend-tool-error"`,
+	} {
+		if !strings.Contains(legend, want) {
+			t.Errorf("legend missing %q:\n%s", want, legend)
+		}
+	}
+}
+
+func TestRenderLegend_MatchesEntryClassesAndLabels(t *testing.T) {
+	legend := renderLegend("Orchestrator", "Agent")
+	for _, entryType := range displayedEntryTypes {
+		want := `class="avatar ` + getEntryClass(entryType) + `" aria-hidden="true"></span> ` + getRoleLabel(entryType, "Orchestrator", "Agent")
+		if !strings.Contains(legend, want) {
+			t.Errorf("legend missing %s swatch %q", entryType, want)
+		}
+	}
+	if strings.Contains(legend, "> User<") || strings.Contains(legend, "> Assistant<") {
+		t.Error("legend should use the custom role labels")
+	}
+}
+
+func TestRenderLegend_EscapesLabels(t *testing.T) {
+	legend := renderLegend("<b>me</b>", "Agent")
+	if strings.Contains(legend, "<b>me</b>") || !strings.Contains(legend, "&lt;b&gt;me&lt;/b&gt;") {
+		t.Errorf("custom labels should be escaped:\n%s", legend)
+	}
+}
+
+func TestRenderConversation_ShowLegend(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: []byte(`"Hello"`)},
+	}
+
+	plain, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if strings.Contains(plain, `class="footer-legend"`) {
+		t.Error("legend should be off by default")
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{ShowLegend: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	footer := html[strings.Index(html, `<footer class="page-footer">`):]
+	if !strings.Contains(footer, `class="footer-legend"`) {
+		t.Error("ShowLegend should add the legend to the footer")
+	}
+}
+
+func TestLegendHiddenInPrint(t *testing.T) {
+	css := GetStyleCSS()
+	printCSS := css[strings.LastIndex(css, "/* Print styles for footer */"):]
+	if !strings.Contains(printCSS, ".footer-legend") {
+		t.Error("print styles should hide the legend")
+	}
+}
@@ -0,0 +1,49 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// numberedOutputClass marks tool output rendered with line numbers (see
+// renderOutputLines).
+const numberedOutputClass = " numbered-output"
+
+// numbersToolOutput reports whether the output of a call to the named tool is rendered
+// with line numbers: Read and Bash output, when opts.ToolOutputLineNumbers is set.
+func numbersToolOutput(name string, opts ExportOptions) bool {
+	return opts.ToolOutputLineNumbers && (name == "Read" || name == "Bash")
+}
+
+// renderOutputLines renders tool output for a <pre> block. With anchor set, each line is
+// wrapped in a span with the id "<anchor>-L<n>" and led by an empty link to it; style.css
+// numbers the links with a counter, so the numbers show in the gutter but are not part
+// of the text copied from the block. With anchor empty, the output is only escaped.
+func renderOutputLines(output, anchor string) string {
+	if anchor == "" || output == "" {
+		return escapeHTML(output)
+	}
+	lines := strings.SplitAfter(output, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	anchor = escapeHTML(anchor)
+	var sb strings.Builder
+	for i, line := range lines {
+		id := fmt.Sprintf("%s-L%d", anchor, i+1)
+		sb.WriteString(fmt.Sprintf(`<span class="output-line" id="%s"><a class="line-number" href="#%s" aria-hidden="true" tabindex="-1"></a>%s</span>`,
+			id, id, escapeHTML(line)))
+	}
+	return sb.String()
+}
+
+// lineAnchor returns the prefix of the line anchors of the output of tool, its result
+// anchor followed by suffix, or "" without lineNumbers.
+func lineAnchor(tool models.ToolUse, suffix string, lineNumbers bool) string {
+	if !lineNumbers {
+		return ""
+	}
+	return "tool-result-" + tool.ID + suffix
+}
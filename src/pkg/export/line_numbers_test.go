@@ -0,0 +1,90 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRenderOutputLines(t *testing.T) {
+	if got := renderOutputLines("a<b\nc", ""); got != "a&lt;b\nc" {
+		t.Errorf("without an anchor, renderOutputLines() = %q, want the escaped output", got)
+	}
+	if got := renderOutputLines("", "tool-result-t1"); got != "" {
+		t.Errorf("empty output = %q, want empty", got)
+	}
+
+	got := renderOutputLines("one\ntwo<\n", "tool-result-t1")
+	want := `<span class="output-line" id="tool-result-t1-L1"><a class="line-number" href="#tool-result-t1-L1" aria-hidden="true" tabindex="-1"></a>one` + "\n</span>" +
+		`<span class="output-line" id="tool-result-t1-L2"><a class="line-number" href="#tool-result-t1-L2" aria-hidden="true" tabindex="-1"></a>two&lt;` + "\n</span>"
+	if got != want {
+		t.Errorf("renderOutputLines() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNumbersToolOutput(t *testing.T) {
+	on := ExportOptions{ToolOutputLineNumbers: true}
+	for name, want := range map[string]bool{"Read": true, "Bash": true, "Grep": false, "Write": false} {
+		if got := numbersToolOutput(name, on); got != want {
+			t.Errorf("numbersToolOutput(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if numbersToolOutput("Read", ExportOptions{}) {
+		t.Error("line numbers should be off by default")
+	}
+}
+
+func TestRenderConversationWithOptions_ToolOutputLineNumbers(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "a1", Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[` +
+			`{"type":"tool_use","id":"t-read","name":"Read","input":{"file_path":"/src/main.go"}},` +
+			`{"type":"tool_use","id":"t-bash","name":"Bash","input":{"command":"ls"}},` +
+			`{"type":"tool_use","id":"t-grep","name":"Grep","input":{"pattern":"x"}}]}`)},
+		{UUID: "u1", Type: models.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":[` +
+			`{"type":"tool_result","tool_use_id":"t-read","content":"package main\nfunc main() {}"},` +
+			`{"type":"tool_result","tool_use_id":"t-bash","content":"a.txt\nb.txt"},` +
+			`{"type":"tool_result","tool_use_id":"t-grep","content":"x.go\ny.go"}]}`)},
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{ToolOutputLineNumbers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`id="tool-result-t-read-L2"><a class="line-number" href="#tool-result-t-read-L2"`,
+		`id="tool-result-t-bash-L1"`,
+		`<pre class="tool-output bash-output numbered-output"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+	if strings.Contains(html, "tool-result-t-grep-L1") {
+		t.Error("only Read and Bash output should be numbered")
+	}
+	// The numbers are not in the text, so copying the output copies it unchanged
+	if !strings.Contains(html, `></a>package main`+"\n</span>") {
+		t.Error("numbered lines should hold only the output text")
+	}
+
+	plain, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(plain, `class="output-line"`) {
+		t.Error("tool output should not be numbered by default")
+	}
+}
+
+func TestRenderBashToolCall_StreamLineNumbers(t *testing.T) {
+	tool := models.ToolUse{ID: "t1", Name: "Bash", Input: map[string]any{"command": "make"}}
+	result := models.ToolResult{ToolUseID: "t1", Content: "built", Stdout: "built\n", Stderr: "warning\n"}
+	html := renderBashToolCall(tool, result, true, entryRenderOptions{opts: ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen, ToolOutputLineNumbers: true}})
+	for _, want := range []string{`id="tool-result-t1-stdout-L1"`, `id="tool-result-t1-stderr-L1"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("split output missing %q:\n%s", want, html)
+		}
+	}
+}
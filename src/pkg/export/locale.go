@@ -0,0 +1,134 @@
+package export
+
+import (
+	"strconv"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// SupportedLocales lists the locales with translated duration units. Other locales
+// fall back to the default English formatting.
+var SupportedLocales = []language.Tag{
+	language.English,
+	language.German,
+	language.French,
+	language.Spanish,
+	language.Japanese,
+}
+
+// Duration formats used as message keys; English output is the key itself.
+const (
+	durationHoursMinutes = "%dh %dm"
+	durationMinutes      = "%dm"
+	durationSeconds      = "%ds"
+)
+
+// durationCatalog holds the translated duration formats for SupportedLocales.
+var durationCatalog = func() catalog.Catalog {
+	b := catalog.NewBuilder(catalog.Fallback(language.English))
+	translations := map[language.Tag][3]string{
+		language.English:  {durationHoursMinutes, durationMinutes, durationSeconds},
+		language.German:   {"%d Std. %d Min.", "%d Min.", "%d Sek."},
+		language.French:   {"%d h %d min", "%d min", "%d s"},
+		language.Spanish:  {"%d h %d min", "%d min", "%d s"},
+		language.Japanese: {"%d時間%d分", "%d分", "%d秒"},
+	}
+	for tag, t := range translations {
+		_ = b.SetString(tag, durationHoursMinutes, t[0])
+		_ = b.SetString(tag, durationMinutes, t[1])
+		_ = b.SetString(tag, durationSeconds, t[2])
+	}
+	return b
+}()
+
+// localeMatcher picks the closest supported locale for a requested one.
+var localeMatcher = language.NewMatcher(SupportedLocales)
+
+// MatchLocale returns the supported locale closest to locale (a BCP 47 tag such as
+// "de" or "fr-CA"). ok is false if locale is not a valid tag or has no close match,
+// in which case English is returned.
+func MatchLocale(locale string) (tag language.Tag, ok bool) {
+	requested, err := language.Parse(locale)
+	if err != nil {
+		return language.English, false
+	}
+	// The matcher may pick English for unrelated languages, so also require the
+	// same base language
+	_, index, confidence := localeMatcher.Match(requested)
+	requestedBase, _ := requested.Base()
+	matchedBase, _ := SupportedLocales[index].Base()
+	if confidence == language.No || requestedBase != matchedBase {
+		return language.English, false
+	}
+	return SupportedLocales[index], true
+}
+
+// localizer formats numbers and durations for the stats shown in exports.
+// The zero value keeps the original unlocalized output ("1234", "2h 35m").
+type localizer struct {
+	printer *message.Printer
+}
+
+// newLocalizer returns a localizer for locale. An empty locale returns the zero value;
+// unsupported locales fall back to English.
+func newLocalizer(locale string) localizer {
+	if locale == "" {
+		return localizer{}
+	}
+	tag, _ := MatchLocale(locale)
+	return localizer{printer: message.NewPrinter(tag, message.Catalog(durationCatalog))}
+}
+
+// number formats n with the locale's digit grouping.
+func (l localizer) number(n int) string {
+	if l.printer == nil {
+		return strconv.Itoa(n)
+	}
+	return l.printer.Sprintf("%d", n)
+}
+
+// duration formats d like formatDuration, with translated units.
+func (l localizer) duration(d time.Duration) string {
+	if l.printer == nil {
+		return formatDuration(d)
+	}
+	return formatDurationWith(func(format string, a ...any) string {
+		return l.printer.Sprintf(format, a...)
+	}, d)
+}
+
+// statsDuration returns the session duration from stats in the locale. Stats built by
+// hand without a measured duration keep their Duration text.
+func (l localizer) statsDuration(stats *SessionStats) string {
+	if l.printer == nil || stats.duration == 0 {
+		return stats.Duration
+	}
+	return l.duration(stats.duration)
+}
+
+// statsActiveDuration returns the active duration from stats in the locale, like
+// statsDuration.
+func (l localizer) statsActiveDuration(stats *SessionStats) string {
+	if l.printer == nil || stats.activeDuration == 0 {
+		return stats.ActiveDuration
+	}
+	return l.duration(stats.activeDuration)
+}
+
+// formatDurationWith formats d as hours and minutes, minutes, or seconds using sprintf
+// with the duration format keys.
+func formatDurationWith(sprintf func(format string, a ...any) string, d time.Duration) string {
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+
+	if hours > 0 {
+		return sprintf(durationHoursMinutes, hours, minutes)
+	}
+	if minutes > 0 {
+		return sprintf(durationMinutes, minutes)
+	}
+	return sprintf(durationSeconds, int(d.Seconds()))
+}
@@ -0,0 +1,126 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+func TestMatchLocale(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   language.Tag
+		ok     bool
+	}{
+		{"de", language.German, true},
+		{"de-AT", language.German, true},
+		{"fr-CA", language.French, true},
+		{"ja", language.Japanese, true},
+		{"en-GB", language.English, true},
+		{"not a locale!", language.English, false},
+		{"sw", language.English, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			got, ok := MatchLocale(tt.locale)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("MatchLocale(%q) = %v, %v; want %v, %v", tt.locale, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLocalizer_DefaultUnchanged(t *testing.T) {
+	var loc localizer
+	d := 2*time.Hour + 35*time.Minute
+
+	if got := loc.number(12345); got != "12345" {
+		t.Errorf("number() = %q, want %q", got, "12345")
+	}
+	if got := loc.duration(d); got != formatDuration(d) || got != "2h 35m" {
+		t.Errorf("duration() = %q, want %q", got, "2h 35m")
+	}
+	if got := newLocalizer(""); got.printer != nil {
+		t.Error("empty locale should use the default formatting")
+	}
+}
+
+func TestLocalizer_Locales(t *testing.T) {
+	tests := []struct {
+		locale   string
+		number   string
+		duration string
+		minutes  string
+	}{
+		{"en", "12,345", "2h 35m", "45m"},
+		{"de", "12.345", "2 Std. 35 Min.", "45 Min."},
+		{"fr", "12 345", "2 h 35 min", "45 min"},
+		{"ja", "12,345", "2時間35分", "45分"},
+		{"sw", "12,345", "2h 35m", "45m"}, // unsupported falls back to English
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			loc := newLocalizer(tt.locale)
+			if got := loc.number(12345); got != tt.number {
+				t.Errorf("number() = %q, want %q", got, tt.number)
+			}
+			if got := loc.duration(2*time.Hour + 35*time.Minute); got != tt.duration {
+				t.Errorf("duration() = %q, want %q", got, tt.duration)
+			}
+			if got := loc.duration(45 * time.Minute); got != tt.minutes {
+				t.Errorf("duration() = %q, want %q", got, tt.minutes)
+			}
+		})
+	}
+}
+
+func TestLocalizer_StatsDuration(t *testing.T) {
+	loc := newLocalizer("de")
+
+	computed := &SessionStats{Duration: "1h 0m", duration: time.Hour}
+	if got := loc.statsDuration(computed); got != "1 Std. 0 Min." {
+		t.Errorf("statsDuration() = %q, want localized duration", got)
+	}
+
+	// Stats built by hand keep their text
+	manual := &SessionStats{Duration: "about an hour"}
+	if got := loc.statsDuration(manual); got != "about an hour" {
+		t.Errorf("statsDuration() = %q, want %q", got, "about an hour")
+	}
+}
+
+func TestMarkdownExporter_Locale(t *testing.T) {
+	stats := &SessionStats{UserMessages: 1200, AssistantMessages: 34, ToolCallCount: 5678, Duration: "2h 35m", duration: 2*time.Hour + 35*time.Minute}
+
+	md, err := MarkdownExporter{Locale: "de"}.Render(nil, nil, stats)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{"- **Messages:** 1.234 (1.200 user, 34 assistant)", "- **Tool calls:** 5.678", "- **Duration:** 2 Std. 35 Min."} {
+		if !strings.Contains(string(md), want) {
+			t.Errorf("missing %q in:\n%s", want, md)
+		}
+	}
+
+	md, err = MarkdownExporter{}.Render(nil, nil, stats)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(string(md), "- **Messages:** 1234 (1200 user, 34 assistant)") {
+		t.Errorf("default locale output changed:\n%s", md)
+	}
+}
+
+func TestRenderConversationWithOptions_Locale(t *testing.T) {
+	stats := &SessionStats{UserMessages: 1500, ToolCallCount: 2000}
+
+	html, err := RenderConversationWithOptions(nil, nil, stats, ExportOptions{Locale: "de"})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if !strings.Contains(html, "User: 1.500 |") || !strings.Contains(html, "Tools: 2.000 calls") {
+		t.Error("HTML header counts should use the locale's digit grouping")
+	}
+}
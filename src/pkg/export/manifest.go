@@ -39,6 +39,12 @@ type SourceFile struct {
 	Type    string `json:"type"` // "session" or "agent"
 	AgentID string `json:"agent_id,omitempty"`
 	Path    string `json:"path"`
+
+	// ExportPath, Size, and Lines describe the copy inside the export directory
+	// (ExportPath is relative to it). They are used to verify copies on resume.
+	ExportPath string `json:"export_path,omitempty"`
+	Size       int64  `json:"size,omitempty"`
+	Lines      int    `json:"lines,omitempty"`
 }
 
 // GenerateManifest creates a manifest for a session export.
@@ -46,7 +52,7 @@ type SourceFile struct {
 // sessionID is the session identifier.
 // outputDir is the directory where the export will be written.
 func GenerateManifest(projectDir, sessionID, outputDir string) (*Manifest, error) {
-	sessionPath := filepath.Join(projectDir, sessionID+".jsonl")
+	sessionPath := paths.JSONLFile(filepath.Join(projectDir, sessionID))
 
 	// Verify session file exists
 	if !paths.Exists(sessionPath) {
@@ -84,6 +90,9 @@ func GenerateManifest(projectDir, sessionID, outputDir string) (*Manifest, error
 	return manifest, nil
 }
 
+// ManifestFileName is the manifest of an export, in the root of its output directory.
+const ManifestFileName = "manifest.json"
+
 // WriteManifest writes a manifest to the output directory as manifest.json.
 func WriteManifest(manifest *Manifest, outputDir string) error {
 	// Ensure output directory exists
@@ -91,7 +100,7 @@ func WriteManifest(manifest *Manifest, outputDir string) error {
 		return err
 	}
 
-	manifestPath := filepath.Join(outputDir, "manifest.json")
+	manifestPath := filepath.Join(outputDir, ManifestFileName)
 
 	data, err := json.MarshalIndent(manifest, "", "  ")
 	if err != nil {
@@ -103,7 +112,7 @@ func WriteManifest(manifest *Manifest, outputDir string) error {
 
 // ReadManifest reads a manifest from an export directory.
 func ReadManifest(outputDir string) (*Manifest, error) {
-	manifestPath := filepath.Join(outputDir, "manifest.json")
+	manifestPath := filepath.Join(outputDir, ManifestFileName)
 
 	data, err := os.ReadFile(manifestPath)
 	if err != nil {
@@ -150,7 +159,7 @@ func buildSourceFilesList(tree *agent.TreeNode, projectDir, sessionID string) []
 	var files []SourceFile
 
 	// Add main session file
-	sessionPath := filepath.Join(projectDir, sessionID+".jsonl")
+	sessionPath := paths.JSONLFile(filepath.Join(projectDir, sessionID))
 	if paths.Exists(sessionPath) {
 		files = append(files, SourceFile{
 			Type: "session",
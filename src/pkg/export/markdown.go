@@ -4,7 +4,10 @@ package export
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // CodeBlock represents a fenced code block extracted from markdown.
@@ -13,6 +16,10 @@ type CodeBlock struct {
 	Code     string // The code content (without the fence markers)
 	StartPos int    // Start position in the original text
 	EndPos   int    // End position in the original text
+
+	// Source entry of the block; set by CollectCodeBlocks, empty otherwise
+	UUID      string
+	Timestamp string
 }
 
 // Regular expression patterns for markdown parsing
@@ -38,11 +45,33 @@ var (
 	// Links: [text](url)
 	linkRe = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
 
+	// Reference links: [text][ref], or [text][] to use the text as the reference
+	refLinkRe = regexp.MustCompile(`\[([^\]]+)\]\[([^\]]*)\]`)
+
+	// Link definitions: [ref]: url "optional title", alone on a line. A label starting
+	// with ^ is a footnote, not a link definition.
+	linkDefRe = regexp.MustCompile(`(?m)^ {0,3}\[([^\]^][^\]]*)\]:[ \t]*<?([^\s<>]+)>?(?:[ \t]+(?:"[^"\n]*"|'[^'\n]*'|\([^)\n]*\)))?[ \t]*(?:\n|$)`)
+
+	// Autolinks: <https://example.com>
+	autolinkRe = regexp.MustCompile(`<(https?://[^\s<>\x00]+)>`)
+
+	// Bare URLs: https://example.com (trailing punctuation trimmed by trimURLSuffix)
+	bareURLRe = regexp.MustCompile(`https?://[^\s<>"'\x60\x00]+`)
+
+	// Bare email addresses: user@example.com
+	emailRe = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+
+	// HTML character references: &copy;, &#169;, &#xA9; (see htmlEntityAt)
+	htmlEntityRe = regexp.MustCompile(`^&(?:[A-Za-z][A-Za-z0-9]{1,31}|#[0-9]{1,7}|#[xX][0-9A-Fa-f]{1,6});`)
+
 	// Images: ![alt](url)
 	imageRe = regexp.MustCompile(`!\[([^\]]*)\]\(([^)]+)\)`)
 
-	// Blockquotes: > text
-	blockquoteRe = regexp.MustCompile(`(?m)^> (.+)$`)
+	// Blockquotes: > text (one level; the rest of the line may hold more > markers)
+	blockquoteRe = regexp.MustCompile(`^> ?(.*)$`)
+
+	// Quote markers before a fenced code block that starts inside a blockquote
+	quotePrefixRe = regexp.MustCompile(`^(?:> ?)+$`)
 
 	// Horizontal rules: ---, ***, ___
 	hrRe = regexp.MustCompile(`(?m)^(---|\*\*\*|___)$`)
@@ -103,6 +132,26 @@ func ExtractCodeBlocks(content string) []CodeBlock {
 // All plain text is HTML-escaped to prevent XSS attacks.
 // projectPath is used to resolve relative file paths (can be empty string to disable relative path detection).
 func RenderMarkdown(content string, projectPath string) string {
+	return renderMarkdownWith(content, projectPath, markdownOptions{})
+}
+
+// renderMarkdownWithCitations renders markdown like RenderMarkdown and additionally links
+// [n] citation markers to sources[n-1]. Markers inside code are never linked.
+func renderMarkdownWithCitations(content string, projectPath string, sources []string) string {
+	return renderMarkdownWith(content, projectPath, markdownOptions{citationSources: sources})
+}
+
+// markdownOptions carries optional inputs of renderMarkdownWith. The zero value renders
+// like RenderMarkdown.
+type markdownOptions struct {
+	citationSources   []string // WebSearch sources for [n] markers (nil disables citation linking)
+	emojiShortcodes   bool     // Replace :name: shortcodes with emoji (see ExportOptions.EmojiShortcodes)
+	collapseCodeLines int      // Collapse code blocks longer than this many lines (see ExportOptions.CollapseCodeLines)
+	allowSafeHTML     bool     // Pass <br> and safeSpanTags through instead of escaping them (see ExportOptions.AllowSafeHTML)
+}
+
+// renderMarkdownWith renders markdown like RenderMarkdown, applying the given options.
+func renderMarkdownWith(content string, projectPath string, mo markdownOptions) string {
 	if content == "" {
 		return ""
 	}
@@ -116,7 +165,7 @@ func RenderMarkdown(content string, projectPath string) string {
 	for i := len(codeBlocks) - 1; i >= 0; i-- {
 		block := codeBlocks[i]
 		placeholder := fmt.Sprintf("\x00CODE_BLOCK_%d\x00", i)
-		codeBlockPlaceholders[placeholder] = renderCodeBlock(block)
+		codeBlockPlaceholders[placeholder] = renderCodeBlockWith(unquoteCodeBlock(content, block), mo.collapseCodeLines)
 		result = result[:block.StartPos] + placeholder + result[block.EndPos:]
 	}
 
@@ -151,6 +200,17 @@ func RenderMarkdown(content string, projectPath string) string {
 		return placeholder
 	})
 
+	// Pass the allowed inline tags through (after code, so tags shown in code stay
+	// escaped)
+	safeHTMLPlaceholders := make(map[string]string)
+	if mo.allowSafeHTML {
+		result = protectSafeHTML(result, safeHTMLPlaceholders)
+	}
+
+	// Collect and remove link definitions, for reference links below (after code,
+	// so definitions shown in code stay as written)
+	result, linkDefs := collectLinkDefinitions(result)
+
 	// Process images before links (images have ! prefix)
 	// Store rendered images in placeholders to protect URLs from escaping
 	imagePlaceholders := make(map[string]string)
@@ -173,26 +233,41 @@ func RenderMarkdown(content string, projectPath string) string {
 		parts := linkRe.FindStringSubmatch(match)
 		if len(parts) >= 3 {
 			placeholder := fmt.Sprintf("\x00LINK_%d\x00", linkIdx)
-			linkPlaceholders[placeholder] = `<a href="` + escapeHTML(parts[2]) + `" class="md-link">` + escapeHTML(parts[1]) + `</a>`
+			linkPlaceholders[placeholder] = renderLink(parts[1], parts[2])
 			linkIdx++
 			return placeholder
 		}
 		return match
 	})
 
+	// Resolve reference links against their definitions (after inline links, so
+	// [text](url) is untouched)
+	result = resolveReferenceLinks(result, linkDefs, linkPlaceholders, &linkIdx)
+
+	// Autolink bare URLs and <url> forms (after links so [text](url) is untouched)
+	result = linkifyURLs(result, linkPlaceholders, &linkIdx)
+
+	// Autolink bare email addresses (after URLs so user@host inside a URL is untouched)
+	result = linkifyEmails(result, linkPlaceholders, &linkIdx)
+
+	// Link citation markers to WebSearch sources (after links so [n](url) is untouched)
+	citationPlaceholders := make(map[string]string)
+	result = linkifyCitations(result, mo.citationSources, citationPlaceholders)
+
 	// Process file paths and store in placeholders (before escaping remaining text)
 	pathPlaceholders := make(map[string]string)
 	pathIdx := 0
 	result = makePathsClickableWithPlaceholders(result, projectPath, &pathPlaceholders, &pathIdx)
 
-	// Process tables (before escaping so we can detect the | delimiters)
-	result = processMarkdownTables(result)
-
-	// Process lists (before escaping so we can detect the - and * markers)
-	result = processMarkdownLists(result)
+	// Replace emoji shortcodes (after code, links and paths are placeholders, so colons
+	// in them are untouched)
+	if mo.emojiShortcodes {
+		result = replaceEmojiShortcodes(result)
+	}
 
-	// Process blockquotes (before escaping so we can detect the > marker)
-	result = processBlockquotes(result)
+	// Process tables, lists and blockquotes (before escaping so we can detect the
+	// |, -, * and > markers)
+	result = processMarkdownBlocks(result)
 
 	// Process horizontal rules (before escaping)
 	result = hrRe.ReplaceAllString(result, "\x00HR\x00")
@@ -251,12 +326,18 @@ func RenderMarkdown(content string, projectPath string) string {
 	for placeholder, html := range linkPlaceholders {
 		result = strings.ReplaceAll(result, placeholder, html)
 	}
+	for placeholder, html := range citationPlaceholders {
+		result = strings.ReplaceAll(result, placeholder, html)
+	}
 	for placeholder, html := range pathPlaceholders {
 		result = strings.ReplaceAll(result, placeholder, html)
 	}
 	for placeholder, html := range inlineCodePlaceholders {
 		result = strings.ReplaceAll(result, placeholder, html)
 	}
+	for placeholder, html := range safeHTMLPlaceholders {
+		result = strings.ReplaceAll(result, placeholder, html)
+	}
 	for placeholder, html := range codeBlockPlaceholders {
 		result = strings.ReplaceAll(result, placeholder, html)
 	}
@@ -264,6 +345,150 @@ func RenderMarkdown(content string, projectPath string) string {
 	return result
 }
 
+// renderLink returns the HTML of a link to url. A script URL (see isScriptURL) keeps
+// the text but drops the link, which would run script when clicked.
+func renderLink(text, url string) string {
+	if isScriptURL(url) {
+		return escapeHTML(text)
+	}
+	return `<a href="` + escapeHTML(url) + `" class="md-link">` + escapeHTML(text) + `</a>`
+}
+
+// collectLinkDefinitions removes the [ref]: url link definitions from content and
+// returns the remaining content with the URL of each reference, keyed by
+// normalizeLinkLabel. The first definition of a reference wins.
+func collectLinkDefinitions(content string) (string, map[string]string) {
+	defs := make(map[string]string)
+	content = linkDefRe.ReplaceAllStringFunc(content, func(match string) string {
+		parts := linkDefRe.FindStringSubmatch(match)
+		label := normalizeLinkLabel(parts[1])
+		if label == "" {
+			return match
+		}
+		if _, ok := defs[label]; !ok {
+			defs[label] = parts[2]
+		}
+		return ""
+	})
+	return content, defs
+}
+
+// resolveReferenceLinks replaces [text][ref] and [text][] reference links that have a
+// definition in defs with md-link placeholders. References without a definition are
+// left as written, so they render as literal text rather than as broken links.
+func resolveReferenceLinks(content string, defs map[string]string, placeholders map[string]string, idx *int) string {
+	if len(defs) == 0 {
+		return content
+	}
+	return refLinkRe.ReplaceAllStringFunc(content, func(match string) string {
+		parts := refLinkRe.FindStringSubmatch(match)
+		ref := parts[2]
+		if ref == "" {
+			ref = parts[1]
+		}
+		url, ok := defs[normalizeLinkLabel(ref)]
+		if !ok {
+			return match
+		}
+		placeholder := fmt.Sprintf("\x00LINK_%d\x00", *idx)
+		placeholders[placeholder] = renderLink(parts[1], url)
+		*idx++
+		return placeholder
+	})
+}
+
+// normalizeLinkLabel returns the form of a reference label used to match links to
+// definitions: labels match regardless of case and of how whitespace is spaced.
+func normalizeLinkLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// isScriptURL reports whether url uses a scheme that runs script when followed
+// (javascript:, vbscript: or data:). Browsers ignore case, surrounding whitespace and
+// embedded tabs and newlines in the scheme, so those are ignored here too.
+func isScriptURL(url string) bool {
+	normalized := strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, url)
+	for _, scheme := range []string{"javascript:", "vbscript:", "data:"} {
+		if strings.HasPrefix(normalized, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// linkifyURLs replaces <url> autolinks and bare http(s) URLs with md-link placeholders.
+// Code and explicit links are already placeholders by the time this runs, so URLs
+// inside them are never re-processed.
+func linkifyURLs(content string, placeholders map[string]string, idx *int) string {
+	addLink := func(url string) string {
+		placeholder := fmt.Sprintf("\x00LINK_%d\x00", *idx)
+		placeholders[placeholder] = `<a href="` + escapeHTML(url) + `" class="md-link">` + escapeHTML(url) + `</a>`
+		*idx++
+		return placeholder
+	}
+
+	content = autolinkRe.ReplaceAllStringFunc(content, func(match string) string {
+		return addLink(match[1 : len(match)-1])
+	})
+
+	return bareURLRe.ReplaceAllStringFunc(content, func(match string) string {
+		url := trimURLSuffix(match)
+		if strings.HasSuffix(url, "://") {
+			return match
+		}
+		return addLink(url) + match[len(url):]
+	})
+}
+
+// linkifyEmails replaces bare email addresses with mailto: md-link placeholders. An
+// address followed by ":" or "/" is part of something else, such as the SSH remote
+// git@github.com:org/repo.git, and is left alone.
+func linkifyEmails(content string, placeholders map[string]string, idx *int) string {
+	matches := emailRe.FindAllStringIndex(content, -1)
+	if matches == nil {
+		return content
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m[1] < len(content) && (content[m[1]] == ':' || content[m[1]] == '/') {
+			continue
+		}
+		addr := content[m[0]:m[1]]
+		placeholder := fmt.Sprintf("\x00LINK_%d\x00", *idx)
+		placeholders[placeholder] = `<a href="mailto:` + escapeHTML(addr) + `" class="md-link">` + escapeHTML(addr) + `</a>`
+		*idx++
+		sb.WriteString(content[last:m[0]])
+		sb.WriteString(placeholder)
+		last = m[1]
+	}
+	sb.WriteString(content[last:])
+	return sb.String()
+}
+
+// trimURLSuffix drops trailing sentence punctuation and unbalanced closing
+// parentheses so "see https://example.com." does not link the period.
+func trimURLSuffix(url string) string {
+	for len(url) > 0 {
+		last := url[len(url)-1]
+		switch {
+		case strings.IndexByte(".,;:!?*", last) >= 0:
+			url = url[:len(url)-1]
+		case last == ')' && strings.Count(url, "(") < strings.Count(url, ")"):
+			url = url[:len(url)-1]
+		default:
+			return url
+		}
+	}
+	return url
+}
+
 // escapeRemainingText escapes HTML in text that hasn't been processed as markdown.
 // It preserves HTML tags that we've already created and placeholder markers.
 func escapeRemainingText(content string) string {
@@ -296,6 +521,15 @@ func escapeRemainingText(content string) string {
 			}
 		}
 
+		// Keep known character references such as &copy; as written
+		if content[i] == '&' {
+			if n := htmlEntityAt(content[i:]); n > 0 {
+				result.WriteString(content[i : i+n])
+				i += n
+				continue
+			}
+		}
+
 		// Escape this character if it's a special HTML character
 		switch content[i] {
 		case '&':
@@ -316,6 +550,52 @@ func escapeRemainingText(content string) string {
 	return result.String()
 }
 
+// allowedNamedEntities lists the named character references passed through unescaped.
+// Others, even valid ones, are escaped and so shown literally.
+var allowedNamedEntities = map[string]bool{
+	"amp": true, "lt": true, "gt": true, "quot": true, "apos": true, "nbsp": true,
+	"copy": true, "reg": true, "trade": true, "deg": true, "plusmn": true, "times": true,
+	"divide": true, "micro": true, "para": true, "sect": true, "middot": true, "bull": true,
+	"hellip": true, "ndash": true, "mdash": true, "lsquo": true, "rsquo": true, "ldquo": true,
+	"rdquo": true, "laquo": true, "raquo": true, "cent": true, "pound": true, "euro": true,
+	"yen": true, "larr": true, "rarr": true, "uarr": true, "darr": true, "harr": true,
+	"le": true, "ge": true, "ne": true, "asymp": true, "infin": true, "check": true,
+	"ensp": true, "emsp": true, "thinsp": true, "shy": true,
+}
+
+// htmlEntityAt returns the length of the character reference s starts with, or 0 if s
+// does not start with one that may pass through unescaped: an allowed named entity
+// (allowedNamedEntities) or a numeric reference to a printable Unicode character.
+// References only ever decode to text, so none can form a tag.
+func htmlEntityAt(s string) int {
+	ref := htmlEntityRe.FindString(s)
+	if ref == "" {
+		return 0
+	}
+	name := ref[1 : len(ref)-1]
+	if name[0] != '#' {
+		if allowedNamedEntities[name] {
+			return len(ref)
+		}
+		return 0
+	}
+
+	var code int64
+	var err error
+	if name[1] == 'x' || name[1] == 'X' {
+		code, err = strconv.ParseInt(name[2:], 16, 32)
+	} else {
+		code, err = strconv.ParseInt(name[1:], 10, 32)
+	}
+	if err != nil || code > unicode.MaxRune || !utf8.ValidRune(rune(code)) {
+		return 0
+	}
+	if r := rune(code); r != '\t' && r != '\n' && unicode.IsControl(r) {
+		return 0
+	}
+	return len(ref)
+}
+
 // isValidHTMLTag checks if a string looks like an HTML tag we've generated.
 func isValidHTMLTag(tag string) bool {
 	if len(tag) < 3 {
@@ -345,8 +625,62 @@ func isValidHTMLTag(tag string) bool {
 	return false
 }
 
+// safeSpanTags are the inline elements ExportOptions.AllowSafeHTML passes through
+// besides <br>.
+var safeSpanTags = []string{"sub", "sup", "kbd"}
+
+var (
+	// safeBreakRe matches a <br> tag, optionally self-closed, without attributes.
+	safeBreakRe = regexp.MustCompile(`(?i)<br\s*/?>`)
+
+	// safeSpanRes match a safeSpanTags element without attributes and closed on the
+	// same line, capturing its text.
+	safeSpanRes = func() map[string]*regexp.Regexp {
+		res := make(map[string]*regexp.Regexp)
+		for _, tag := range safeSpanTags {
+			res[tag] = regexp.MustCompile(`(?i)<` + tag + `>([^<>\n]*)</` + tag + `>`)
+		}
+		return res
+	}()
+)
+
+// protectSafeHTML replaces <br> and the safeSpanTags in content with placeholders
+// restored to the bare tags, adding them to placeholders. Only exact tags pass: a tag with any
+// attribute, an unclosed <sub>, <sup> or <kbd>, or one holding other markup is left
+// to be escaped like any other HTML. The text inside stays in content, so it is
+// escaped as usual.
+func protectSafeHTML(content string, placeholders map[string]string) string {
+	add := func(html string) string {
+		placeholder := fmt.Sprintf("\x00SAFE_HTML_%d\x00", len(placeholders))
+		placeholders[placeholder] = html
+		return placeholder
+	}
+	content = safeBreakRe.ReplaceAllStringFunc(content, func(string) string {
+		return add("<br>")
+	})
+	for _, tag := range safeSpanTags {
+		re := safeSpanRes[tag]
+		content = re.ReplaceAllStringFunc(content, func(match string) string {
+			text := re.FindStringSubmatch(match)[1]
+			return add("<"+tag+">") + text + add("</"+tag+">")
+		})
+	}
+	return content
+}
+
+// DefaultCollapseCodeLines is the CLI's default for ExportOptions.CollapseCodeLines.
+const DefaultCollapseCodeLines = 30
+
 // renderCodeBlock renders a fenced code block with language badge and copy button.
 func renderCodeBlock(block CodeBlock) string {
+	return renderCodeBlockWith(block, 0)
+}
+
+// renderCodeBlockWith renders a code block like renderCodeBlock, collapsing the code of a
+// block longer than collapseLines lines into a <details> element under a line-count
+// summary (0 never collapses). The header, with the language badge and copy button,
+// stays outside it, so both work whether the code is collapsed or expanded.
+func renderCodeBlockWith(block CodeBlock, collapseLines int) string {
 	var sb strings.Builder
 
 	languageClass := ""
@@ -361,12 +695,30 @@ func renderCodeBlock(block CodeBlock) string {
 	sb.WriteString(`<span class="language-badge">` + languageDisplay + `</span>`)
 	sb.WriteString(`<button class="copy-code-btn" onclick="copyCode(this)" title="Copy code">Copy</button>`)
 	sb.WriteString(`</div>`)
-	sb.WriteString(`<pre class="code-content"><code>` + escapeHTML(block.Code) + `</code></pre>`)
+
+	code := `<pre class="code-content"><code>` + escapeHTML(block.Code) + `</code></pre>`
+	if lines := codeLineCount(block.Code); collapseLines > 0 && lines > collapseLines {
+		sb.WriteString(`<details class="code-collapse" data-line-count="` + strconv.Itoa(lines) + `">`)
+		sb.WriteString(`<summary class="code-collapse-summary">` + strconv.Itoa(lines) + ` lines<span class="code-expand-hint"> — click to expand</span></summary>`)
+		sb.WriteString(code)
+		sb.WriteString(`</details>`)
+	} else {
+		sb.WriteString(code)
+	}
 	sb.WriteString(`</div>`)
 
 	return sb.String()
 }
 
+// codeLineCount returns the number of lines of code, not counting a trailing newline.
+func codeLineCount(code string) int {
+	code = strings.TrimSuffix(code, "\n")
+	if code == "" {
+		return 0
+	}
+	return strings.Count(code, "\n") + 1
+}
+
 // processMarkdownTables converts markdown tables to HTML tables.
 func processMarkdownTables(content string) string {
 	lines := strings.Split(content, "\n")
@@ -602,34 +954,69 @@ func closeListTag(listType string) string {
 	}
 }
 
-// processBlockquotes converts blockquote lines to HTML.
+// processMarkdownBlocks converts tables, lists and blockquotes to HTML. Blockquote
+// content goes through it again, so quotes can nest and hold lists and tables.
+func processMarkdownBlocks(content string) string {
+	content = processMarkdownTables(content)
+	content = processMarkdownLists(content)
+	return processBlockquotes(content)
+}
+
+// processBlockquotes converts blockquote lines to HTML. Each run of consecutive > lines
+// becomes one blockquote; one > is stripped from each line and the rest is rendered
+// with processMarkdownBlocks, so >> lines become a nested blockquote.
 func processBlockquotes(content string) string {
 	lines := strings.Split(content, "\n")
 	var result []string
-	inBlockquote := false
+	var quoted []string
+
+	flush := func() {
+		if len(quoted) == 0 {
+			return
+		}
+		result = append(result, `<blockquote class="md-blockquote">`)
+		// Don't escape here - escapeRemainingText() will handle it
+		result = append(result, processMarkdownBlocks(strings.Join(quoted, "\n")))
+		result = append(result, `</blockquote>`)
+		quoted = nil
+	}
 
 	for _, line := range lines {
 		if match := blockquoteRe.FindStringSubmatch(line); match != nil {
-			if !inBlockquote {
-				result = append(result, `<blockquote class="md-blockquote">`)
-				inBlockquote = true
-			}
-			// Don't escape here - escapeRemainingText() will handle it
-			result = append(result, match[1])
-		} else {
-			if inBlockquote {
-				result = append(result, `</blockquote>`)
-				inBlockquote = false
-			}
-			result = append(result, line)
+			quoted = append(quoted, match[1])
+			continue
 		}
+		flush()
+		result = append(result, line)
 	}
+	flush()
 
-	if inBlockquote {
-		result = append(result, `</blockquote>`)
+	return strings.Join(result, "\n")
+}
+
+// unquoteCodeBlock strips blockquote markers from the code of a fenced block that opens
+// inside a blockquote (after "> " on its line), so quoted code renders without them.
+func unquoteCodeBlock(content string, block CodeBlock) CodeBlock {
+	lineStart := strings.LastIndex(content[:block.StartPos], "\n") + 1
+	prefix := content[lineStart:block.StartPos]
+	if !quotePrefixRe.MatchString(prefix) {
+		return block
 	}
 
-	return strings.Join(result, "\n")
+	depth := strings.Count(prefix, ">")
+	lines := strings.Split(block.Code, "\n")
+	for i, line := range lines {
+		for d := 0; d < depth; d++ {
+			match := blockquoteRe.FindStringSubmatch(line)
+			if match == nil {
+				break
+			}
+			line = match[1]
+		}
+		lines[i] = line
+	}
+	block.Code = strings.TrimSuffix(strings.Join(lines, "\n"), "\n")
+	return block
 }
 
 // convertNewlinesToBr converts newlines to <br> tags, but preserves block element structure.
@@ -694,3 +1081,130 @@ func convertNewlinesToBr(content string) string {
 
 	return strings.Join(result, "")
 }
+
+// PlainTextCodeMarker replaces each fenced code block in MarkdownToPlainText output.
+const PlainTextCodeMarker = "[code]"
+
+// Patterns used only by MarkdownToPlainText
+var (
+	plainHeaderRe     = regexp.MustCompile(`^#{1,6}\s+`)
+	plainQuoteRe      = regexp.MustCompile(`^(?:>\s?)+`)
+	plainListMarkerRe = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?`)
+	plainSpaceRe      = regexp.MustCompile(`[ \t]+`)
+
+	// Emphasis markers, strongest first so *** is not split into ** and *. Each is
+	// applied repeatedly in MarkdownToPlainText until nested emphasis is gone.
+	plainEmphasisRes = []*regexp.Regexp{
+		regexp.MustCompile(`\*\*\*(\S(?:[^\n]*?\S)?)\*\*\*`),
+		regexp.MustCompile(`\*\*(\S(?:[^\n]*?\S)?)\*\*`),
+		regexp.MustCompile(`\*(\S(?:[^*\n]*?\S)?)\*`),
+		regexp.MustCompile(`~~(\S(?:[^\n]*?\S)?)~~`),
+	}
+
+	// Underscore emphasis only counts at word boundaries, so snake_case is left alone
+	plainUnderscoreRes = []*regexp.Regexp{
+		regexp.MustCompile(`(^|[^\w])___(\S(?:[^\n]*?\S)?)___([^\w]|$)`),
+		regexp.MustCompile(`(^|[^\w])__(\S(?:[^\n]*?\S)?)__([^\w]|$)`),
+		regexp.MustCompile(`(^|[^\w])_(\S(?:[^_\n]*?\S)?)_([^\w]|$)`),
+	}
+)
+
+// MarkdownToPlainText strips markdown formatting from md, leaving plain prose for text
+// transcripts and word counts. Fenced code blocks (found with ExtractCodeBlocks) become
+// PlainTextCodeMarker, inline code keeps its text, links and images keep their text or
+// alt text while their URLs are dropped, bare and <url> autolinked URLs are kept as
+// written, headers, blockquotes and rules lose their markers,
+// list items are flattened to plain lines, and table rows become space-separated cells.
+// Nested emphasis such as **bold *italic*** is removed completely.
+func MarkdownToPlainText(md string) string {
+	if md == "" {
+		return ""
+	}
+
+	// Replace code blocks (in reverse to keep positions valid)
+	result := md
+	blocks := ExtractCodeBlocks(result)
+	for i := len(blocks) - 1; i >= 0; i-- {
+		result = result[:blocks[i].StartPos] + "\n" + PlainTextCodeMarker + "\n" + result[blocks[i].EndPos:]
+	}
+
+	// Protect inline code, and below URLs, from the link and emphasis handling
+	var literals []string
+	protect := func(text string) string {
+		literals = append(literals, text)
+		return fmt.Sprintf("\x00LITERAL_%d\x00", len(literals)-1)
+	}
+	result = inlineCodeRe.ReplaceAllStringFunc(result, func(match string) string {
+		return protect(inlineCodeRe.FindStringSubmatch(match)[1])
+	})
+
+	// Keep link text and image alt text, dropping their URLs; keep bare and autolinked
+	// URLs, without trailing punctuation
+	result = imageRe.ReplaceAllString(result, "$1")
+	result = linkRe.ReplaceAllString(result, "$1")
+	result = autolinkRe.ReplaceAllStringFunc(result, func(match string) string {
+		return protect(match[1 : len(match)-1])
+	})
+	result = bareURLRe.ReplaceAllStringFunc(result, func(match string) string {
+		url := trimURLSuffix(match)
+		if strings.HasSuffix(url, "://") {
+			return match
+		}
+		return protect(url) + match[len(url):]
+	})
+
+	// Strip block markers line by line
+	lines := strings.Split(result, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if hrRe.MatchString(line) {
+			continue
+		}
+		if strings.HasPrefix(line, "|") && strings.HasSuffix(line, "|") {
+			if isTableSeparator(line) {
+				continue
+			}
+			cells := parseTableRow(line)
+			for i := range cells {
+				cells[i] = strings.TrimSpace(cells[i])
+			}
+			line = strings.Join(cells, " ")
+		}
+		line = plainHeaderRe.ReplaceAllString(line, "")
+		line = plainQuoteRe.ReplaceAllString(line, "")
+		line = plainListMarkerRe.ReplaceAllString(line, "")
+		kept = append(kept, line)
+	}
+	result = strings.Join(kept, "\n")
+
+	// Remove emphasis, repeating until nested markers are gone
+	for {
+		before := result
+		for _, re := range plainEmphasisRes {
+			result = re.ReplaceAllString(result, "$1")
+		}
+		for _, re := range plainUnderscoreRes {
+			result = re.ReplaceAllString(result, "$1$2$3")
+		}
+		if result == before {
+			break
+		}
+	}
+
+	for i, literal := range literals {
+		result = strings.Replace(result, fmt.Sprintf("\x00LITERAL_%d\x00", i), literal, 1)
+	}
+
+	// Normalize whitespace left behind by removed markup, keeping paragraph breaks
+	lines = strings.Split(result, "\n")
+	kept = lines[:0]
+	for _, line := range lines {
+		line = strings.TrimSpace(plainSpaceRe.ReplaceAllString(line, " "))
+		if line == "" && (len(kept) == 0 || kept[len(kept)-1] == "") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
@@ -294,6 +294,37 @@ func TestRenderMarkdown_CodeBlock_MultipleLanguages(t *testing.T) {
 	}
 }
 
+func TestRenderMarkdown_CodeBlock_Collapse(t *testing.T) {
+	long := "```go\n" + strings.Repeat("x := 1\n", 40) + "```"
+	short := "```go\n" + strings.Repeat("x := 1\n", 30) + "```"
+
+	result := renderMarkdownWith(long, "", markdownOptions{collapseCodeLines: 30})
+	if !strings.Contains(result, `<details class="code-collapse" data-line-count="40"><summary class="code-collapse-summary">40 lines<span class="code-expand-hint"> — click to expand</span></summary><pre class="code-content">`) {
+		t.Errorf("long code block should collapse behind a line-count summary, got:\n%s", result)
+	}
+	header := strings.Index(result, `<span class="language-badge">go</span><button class="copy-code-btn"`)
+	if header < 0 || header > strings.Index(result, "<details") {
+		t.Error("language badge and copy button should stay outside the collapsed code")
+	}
+
+	for name, got := range map[string]string{
+		"at threshold": renderMarkdownWith(short, "", markdownOptions{collapseCodeLines: 30}),
+		"disabled":     renderMarkdownWith(long, "", markdownOptions{}),
+	} {
+		if strings.Contains(got, "code-collapse") {
+			t.Errorf("%s: code block should not collapse, got:\n%s", name, got)
+		}
+	}
+}
+
+func TestCodeLineCount(t *testing.T) {
+	for code, want := range map[string]int{"": 0, "a": 1, "a\n": 1, "a\nb": 2, "a\n\nb\n": 3} {
+		if got := codeLineCount(code); got != want {
+			t.Errorf("codeLineCount(%q) = %d, want %d", code, got, want)
+		}
+	}
+}
+
 func TestRenderMarkdown_InlineCode(t *testing.T) {
 	input := "Use the `fmt.Println` function"
 
@@ -391,6 +422,109 @@ func TestRenderMarkdown_Blockquote_Multiline(t *testing.T) {
 	}
 }
 
+func TestRenderMarkdown_Blockquote_Nested(t *testing.T) {
+	input := `> Outer
+>> Inner
+>> > Deepest
+> Back out`
+
+	result := RenderMarkdown(input, "")
+
+	if got := strings.Count(result, `<blockquote class="md-blockquote">`); got != 3 {
+		t.Errorf("Expected 3 nested blockquotes, got %d in %q", got, result)
+	}
+	if strings.Contains(result, "&gt;") {
+		t.Errorf("Quote markers should not appear in output, got %q", result)
+	}
+
+	inner := strings.Index(result, "Inner")
+	deepest := strings.Index(result, "Deepest")
+	backOut := strings.Index(result, "Back out")
+	// "Back out" follows the two closing tags of the inner quotes
+	closes := strings.Count(result[deepest:backOut], `</blockquote>`)
+	if inner < 0 || deepest < inner || backOut < deepest || closes != 2 {
+		t.Errorf("Nesting is wrong (%d closing tags before \"Back out\"): %q", closes, result)
+	}
+	if !strings.HasSuffix(result, `</blockquote>`) {
+		t.Errorf("Outer blockquote should close last, got %q", result)
+	}
+}
+
+func TestRenderMarkdown_Blockquote_ContainsList(t *testing.T) {
+	input := `> Options:
+> - First
+> - Second
+>   1. Nested
+> Done`
+
+	result := RenderMarkdown(input, "")
+
+	open := strings.Index(result, `<blockquote class="md-blockquote">`)
+	ul := strings.Index(result, `<ul class="md-ul">`)
+	ol := strings.Index(result, `<ol class="md-ol">`)
+	closeQuote := strings.Index(result, `</blockquote>`)
+	if open < 0 || ul < open || ol < ul || closeQuote < ol {
+		t.Fatalf("List should render inside the blockquote, got %q", result)
+	}
+	for _, item := range []string{"<li>First</li>", "<li>Second</li>", "<li>Nested</li>"} {
+		if !strings.Contains(result, item) {
+			t.Errorf("Missing %s in %q", item, result)
+		}
+	}
+	if !strings.Contains(result[:closeQuote], "Done") {
+		t.Errorf("Text after the list should stay in the quote, got %q", result)
+	}
+}
+
+func TestRenderMarkdown_Blockquote_FollowedByList(t *testing.T) {
+	input := `> Quoted
+- Item 1
+- Item 2`
+
+	result := RenderMarkdown(input, "")
+
+	if strings.Count(result, `<blockquote class="md-blockquote">`) != 1 {
+		t.Errorf("Expected one blockquote, got %q", result)
+	}
+	closeQuote := strings.Index(result, `</blockquote>`)
+	ul := strings.Index(result, `<ul class="md-ul">`)
+	if closeQuote < 0 || ul < closeQuote {
+		t.Errorf("List after a quote should not be merged into it, got %q", result)
+	}
+	if !strings.Contains(result[ul:], "<li>Item 1</li>") || !strings.Contains(result[ul:], "<li>Item 2</li>") {
+		t.Errorf("Missing list items after the quote, got %q", result)
+	}
+}
+
+func TestRenderMarkdown_Blockquote_ContainsCodeBlock(t *testing.T) {
+	input := "> Run this:\n> ```go\n> x := 1\n> y := 2\n> ```\n> Then check."
+
+	result := RenderMarkdown(input, "")
+
+	if !strings.Contains(result, "<code>x := 1\ny := 2</code>") {
+		t.Errorf("Quoted code should lose its > markers, got %q", result)
+	}
+	open := strings.Index(result, `<blockquote class="md-blockquote">`)
+	code := strings.Index(result, `<div class="code-block`)
+	closeQuote := strings.Index(result, `</blockquote>`)
+	if open < 0 || code < open || closeQuote < code {
+		t.Errorf("Code block should render inside the blockquote, got %q", result)
+	}
+	if !strings.Contains(result[:closeQuote], "Then check.") {
+		t.Errorf("Text after the code should stay in the quote, got %q", result)
+	}
+}
+
+func TestRenderMarkdown_Blockquote_BlankQuoteLine(t *testing.T) {
+	input := "> Para 1\n>\n> Para 2"
+
+	result := RenderMarkdown(input, "")
+
+	if strings.Count(result, `<blockquote class="md-blockquote">`) != 1 {
+		t.Errorf("A bare > line should not split the quote, got %q", result)
+	}
+}
+
 func TestRenderMarkdown_Table_Basic(t *testing.T) {
 	input := `| Header 1 | Header 2 |
 |----------|----------|
@@ -582,6 +716,57 @@ func TestRenderMarkdown_HTMLEscaping(t *testing.T) {
 	}
 }
 
+func TestRenderMarkdownWith_AllowSafeHTML(t *testing.T) {
+	safe := markdownOptions{allowSafeHTML: true}
+	tests := []struct {
+		name, input, want string
+	}{
+		{"break", "one<br>two<BR/>three<br />four", "one<br>two<br>three<br>four"},
+		{"sub and sup", "H<sub>2</sub>O and x<sup>2</sup>", "H<sub>2</sub>O and x<sup>2</sup>"},
+		{"kbd", "Press <kbd>Ctrl</kbd>+<kbd>C</kbd>", "Press <kbd>Ctrl</kbd>+<kbd>C</kbd>"},
+		{"text inside escaped", "<kbd>a & b</kbd>", "<kbd>a &amp; b</kbd>"},
+	}
+	for _, tt := range tests {
+		if got := renderMarkdownWith(tt.input, "", safe); !strings.Contains(got, tt.want) {
+			t.Errorf("%s: renderMarkdownWith(%q) = %q, want it to contain %q", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRenderMarkdownWith_AllowSafeHTMLEscapesEverythingElse(t *testing.T) {
+	safe := markdownOptions{allowSafeHTML: true}
+	for _, input := range []string{
+		"<script>alert(1)</script>",
+		`<br onload="alert(1)">`,
+		`<sub onclick="alert(1)">x</sub>`,
+		`<kbd style="x">k</kbd>`,
+		"<sup>unclosed",
+		"<sub><script>alert(1)</script></sub>",
+		`<img src="javascript:alert(1)">`,
+		"<sub>split\nlines</sub>",
+	} {
+		got := renderMarkdownWith(input, "", safe)
+		for _, unsafe := range []string{"<script", "<br ", "<sub", "<sup", "<kbd", "<img"} {
+			if strings.Contains(got, unsafe) {
+				t.Errorf("renderMarkdownWith(%q) = %q, should not contain %q", input, got, unsafe)
+			}
+		}
+	}
+
+	// Tags in code stay escaped
+	got := renderMarkdownWith("`a<br>b`\n```\n<kbd>k</kbd>\n```", "", safe)
+	if strings.Contains(got, "a<br>b") || strings.Contains(got, "<kbd>") {
+		t.Errorf("tags in code should stay escaped: %q", got)
+	}
+}
+
+func TestRenderMarkdown_SafeHTMLEscapedByDefault(t *testing.T) {
+	got := RenderMarkdown("H<sub>2</sub>O<br>", "")
+	if strings.Contains(got, "<sub>") || !strings.Contains(got, "&lt;sub&gt;") || !strings.Contains(got, "&lt;br&gt;") {
+		t.Errorf("RenderMarkdown() = %q, want the tags escaped", got)
+	}
+}
+
 func TestRenderMarkdown_HTMLEscaping_InCodeBlock(t *testing.T) {
 	input := "```html\n<div class=\"container\"></div>\n```"
 
@@ -797,6 +982,85 @@ func TestRenderMarkdown_LargeOrderedListNumbers(t *testing.T) {
 	}
 }
 
+func TestRenderMarkdown_ReferenceLinks(t *testing.T) {
+	input := "See [the docs][docs] and [Go][].\n\n[docs]: https://example.com/docs \"Docs\"\n[go]: <https://go.dev>"
+
+	result := RenderMarkdown(input, "")
+
+	if !strings.Contains(result, `<a href="https://example.com/docs" class="md-link">the docs</a>`) {
+		t.Errorf("Missing reference link, got %q", result)
+	}
+	if !strings.Contains(result, `<a href="https://go.dev" class="md-link">Go</a>`) {
+		t.Errorf("Missing collapsed reference link, got %q", result)
+	}
+	if strings.Contains(result, "[docs]:") || strings.Contains(result, "[go]:") || strings.Contains(result, "Docs") {
+		t.Errorf("Link definitions should be removed, got %q", result)
+	}
+}
+
+func TestRenderMarkdown_ReferenceLinks_Undefined(t *testing.T) {
+	input := "See [the docs][missing] here.\n\n[docs]: https://example.com"
+
+	result := RenderMarkdown(input, "")
+
+	if strings.Contains(result, "<a ") {
+		t.Errorf("Undefined reference should not be linked, got %q", result)
+	}
+	if !strings.Contains(result, "[the docs][missing]") {
+		t.Errorf("Undefined reference should stay as literal text, got %q", result)
+	}
+}
+
+func TestRenderMarkdown_ReferenceLinks_Labels(t *testing.T) {
+	input := "[first][My  Ref] and [second][my ref]\n[my ref]: https://one.example\n[MY REF]: https://two.example"
+
+	result := RenderMarkdown(input, "")
+
+	// Labels match regardless of case and spacing, and the first definition wins
+	if strings.Count(result, `<a href="https://one.example" class="md-link">`) != 2 {
+		t.Errorf("Both references should use the first definition, got %q", result)
+	}
+	if strings.Contains(result, "two.example") {
+		t.Errorf("Duplicate definition should be removed, got %q", result)
+	}
+}
+
+func TestRenderMarkdown_ReferenceLinks_ScriptURL(t *testing.T) {
+	input := "[click][x]\n[x]: javascript:alert(1)"
+
+	result := RenderMarkdown(input, "")
+
+	if strings.Contains(result, "<a ") || strings.Contains(result, "javascript") {
+		t.Errorf("Script URL should not be linked, got %q", result)
+	}
+	if !strings.Contains(result, "click") {
+		t.Errorf("Link text should be kept, got %q", result)
+	}
+}
+
+func TestRenderMarkdown_ReferenceLinks_InCode(t *testing.T) {
+	input := "```\n[ref]: https://example.com\n```\n\n`[a][ref]`"
+
+	result := RenderMarkdown(input, "")
+
+	if !strings.Contains(result, "[ref]: https://example.com") {
+		t.Errorf("Definition inside a code block should be kept, got %q", result)
+	}
+	if strings.Contains(result, "<a ") {
+		t.Errorf("Reference inside code should not be linked, got %q", result)
+	}
+}
+
+func TestRenderMarkdown_ReferenceLinks_Footnote(t *testing.T) {
+	input := "Note[^1]\n\n[^1]: a footnote"
+
+	result := RenderMarkdown(input, "")
+
+	if !strings.Contains(result, "a footnote") {
+		t.Errorf("Footnote should not be treated as a link definition, got %q", result)
+	}
+}
+
 func TestRenderMarkdown_LinkWithSpecialChars(t *testing.T) {
 	input := "[Link with spaces](https://example.com/path?q=test&foo=bar)"
 
@@ -845,6 +1109,7 @@ Paragraph`
 // Benchmark tests
 func BenchmarkRenderMarkdown_Simple(b *testing.B) {
 	input := "Hello **world**"
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		RenderMarkdown(input, "")
 	}
@@ -875,6 +1140,7 @@ This is a **bold** paragraph with *italic* text.
 
 [Link](https://example.com)
 `
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		RenderMarkdown(input, "")
 	}
@@ -886,3 +1152,323 @@ func BenchmarkExtractCodeBlocks(b *testing.B) {
 		ExtractCodeBlocks(input)
 	}
 }
+
+func TestRenderMarkdown_Autolinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:  "bare URL",
+			input: "See https://example.com/docs for details",
+			want:  []string{`<a href="https://example.com/docs" class="md-link">https://example.com/docs</a> for details`},
+		},
+		{
+			name:    "angle bracket autolink",
+			input:   "Docs: <https://example.com/a?b=1&c=2>",
+			want:    []string{`<a href="https://example.com/a?b=1&amp;c=2" class="md-link">https://example.com/a?b=1&amp;c=2</a>`},
+			notWant: []string{"&lt;", "&gt;"},
+		},
+		{
+			name:  "trailing period not swallowed",
+			input: "Go to https://example.com.",
+			want:  []string{`<a href="https://example.com" class="md-link">https://example.com</a>.`},
+		},
+		{
+			name:  "trailing comma not swallowed",
+			input: "Try http://a.example, then b",
+			want:  []string{`<a href="http://a.example" class="md-link">http://a.example</a>, then b`},
+		},
+		{
+			name:  "unbalanced closing paren trimmed",
+			input: "(see https://example.com/page)",
+			want:  []string{`<a href="https://example.com/page" class="md-link">https://example.com/page</a>)`},
+		},
+		{
+			name:  "balanced parens kept",
+			input: "https://en.wikipedia.org/wiki/Go_(programming_language)",
+			want:  []string{`href="https://en.wikipedia.org/wiki/Go_(programming_language)"`},
+		},
+		{
+			name:    "explicit link not re-processed",
+			input:   "[https://example.com](https://example.com)",
+			want:    []string{`<a href="https://example.com" class="md-link">https://example.com</a>`},
+			notWant: []string{`<a href="https://example.com" class="md-link"><a`},
+		},
+		{
+			name:    "inline code untouched",
+			input:   "Run `curl https://example.com` now",
+			want:    []string{`<code class="inline-code">curl https://example.com</code>`},
+			notWant: []string{"md-link"},
+		},
+		{
+			name:    "code block untouched",
+			input:   "```\nhttps://example.com\n```",
+			notWant: []string{"md-link"},
+		},
+		{
+			name:    "scheme without host",
+			input:   "the https:// prefix",
+			notWant: []string{"md-link"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RenderMarkdown(tt.input, "")
+			for _, w := range tt.want {
+				if !strings.Contains(result, w) {
+					t.Errorf("result should contain %q, got %q", w, result)
+				}
+			}
+			for _, nw := range tt.notWant {
+				if strings.Contains(result, nw) {
+					t.Errorf("result should not contain %q, got %q", nw, result)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderMarkdown_HTMLEntities(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "named entity kept",
+			input:   "Copyright &copy; 2026 &mdash; all rights reserved",
+			want:    []string{"Copyright &copy; 2026 &mdash; all"},
+			notWant: []string{"&amp;copy;", "&amp;mdash;"},
+		},
+		{
+			name:    "numeric entities kept",
+			input:   "&#169; and &#xA9; and &#x2713;",
+			want:    []string{"&#169; and &#xA9; and &#x2713;"},
+			notWant: []string{"&amp;#"},
+		},
+		{
+			name:  "escaped markup stays text",
+			input: "Write &lt;script&gt; to show a tag",
+			want:  []string{"Write &lt;script&gt; to show"},
+		},
+		{
+			name:    "unknown named entity escaped",
+			input:   "&bogus; &zwj;",
+			want:    []string{"&amp;bogus; &amp;zwj;"},
+			notWant: []string{" &zwj;"},
+		},
+		{
+			name:  "control character references escaped",
+			input: "&#0; &#x1B; &#128;",
+			want:  []string{"&amp;#0; &amp;#x1B; &amp;#128;"},
+		},
+		{
+			name:  "out of range and surrogate references escaped",
+			input: "&#x110000; &#xD800;",
+			want:  []string{"&amp;#x110000; &amp;#xD800;"},
+		},
+		{
+			name:  "unterminated reference escaped",
+			input: "AT&T and &copy",
+			want:  []string{"AT&amp;T and &amp;copy"},
+		},
+		{
+			name:    "entities in inline code stay literal",
+			input:   "Use `&copy;` in HTML",
+			want:    []string{`<code class="inline-code">&amp;copy;</code>`},
+			notWant: []string{"<code class=\"inline-code\">&copy;"},
+		},
+		{
+			name:    "entities in code blocks stay literal",
+			input:   "```\n&copy;\n```",
+			want:    []string{"&amp;copy;"},
+			notWant: []string{">&copy;"},
+		},
+		{
+			name:    "raw tags still escaped",
+			input:   "&copy; <script>alert(1)</script> <img src=x onerror=alert(1)>",
+			want:    []string{"&copy; &lt;script&gt;alert(1)&lt;/script&gt; &lt;img"},
+			notWant: []string{"<script", "<img"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RenderMarkdown(tt.input, "")
+			for _, w := range tt.want {
+				if !strings.Contains(result, w) {
+					t.Errorf("result should contain %q, got %q", w, result)
+				}
+			}
+			for _, nw := range tt.notWant {
+				if strings.Contains(result, nw) {
+					t.Errorf("result should not contain %q, got %q", nw, result)
+				}
+			}
+		})
+	}
+}
+
+func TestHTMLEntityAt(t *testing.T) {
+	tests := map[string]int{
+		"&amp; rest":  5,
+		"&nbsp;":      6,
+		"&#10;":       5,
+		"&#x1F600;":   9,
+		"&#X41;":      6,
+		"&script;":    0,
+		"&;":          0,
+		"& copy;":     0,
+		"&#;":         0,
+		"&#x;":        0,
+		"&#12345678;": 0,
+		"&#x7F;":      0,
+		"plain":       0,
+	}
+	for input, want := range tests {
+		if got := htmlEntityAt(input); got != want {
+			t.Errorf("htmlEntityAt(%q) = %d, want %d", input, got, want)
+		}
+	}
+}
+
+func TestRenderMarkdown_EmailAutolinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:  "bare email",
+			input: "Mail support@example.com for help",
+			want:  []string{`<a href="mailto:support@example.com" class="md-link">support@example.com</a> for help`},
+		},
+		{
+			name:  "trailing period not swallowed",
+			input: "Contact jane.doe+tag@mail.example.org.",
+			want:  []string{`<a href="mailto:jane.doe+tag@mail.example.org" class="md-link">jane.doe+tag@mail.example.org</a>.`},
+		},
+		{
+			name:    "SSH remote untouched",
+			input:   "git clone git@github.com:org/repo.git",
+			notWant: []string{"mailto:"},
+		},
+		{
+			name:    "userinfo in URL untouched",
+			input:   "https://user@example.com/path",
+			want:    []string{`href="https://user@example.com/path"`},
+			notWant: []string{"mailto:"},
+		},
+		{
+			name:    "explicit mailto link not re-processed",
+			input:   "[write](mailto:a@example.com)",
+			want:    []string{`<a href="mailto:a@example.com" class="md-link">write</a>`},
+			notWant: []string{`mailto:<a`, `class="md-link"><a`},
+		},
+		{
+			name:    "inline code untouched",
+			input:   "Set `user.email=a@example.com`",
+			notWant: []string{"mailto:"},
+		},
+		{
+			name:    "no TLD",
+			input:   "ping root@localhost",
+			notWant: []string{"mailto:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := RenderMarkdown(tt.input, "")
+			for _, w := range tt.want {
+				if !strings.Contains(result, w) {
+					t.Errorf("result should contain %q, got %q", w, result)
+				}
+			}
+			for _, nw := range tt.notWant {
+				if strings.Contains(result, nw) {
+					t.Errorf("result should not contain %q, got %q", nw, result)
+				}
+			}
+		})
+	}
+}
+
+func TestTrimURLSuffix(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com":        "https://example.com",
+		"https://example.com.":       "https://example.com",
+		"https://example.com/a?!":    "https://example.com/a",
+		"https://example.com/x)":     "https://example.com/x",
+		"https://example.com/(x)":    "https://example.com/(x)",
+		"https://example.com/(x)).,": "https://example.com/(x)",
+	}
+	for in, want := range tests {
+		if got := trimURLSuffix(in); got != want {
+			t.Errorf("trimURLSuffix(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMarkdownToPlainText(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"plain", "Just words.", "Just words."},
+		{"headers", "# Title\n\n### Section", "Title\n\nSection"},
+		{"emphasis", "Some **bold**, *italic*, ~~gone~~ and __strong__ text", "Some bold, italic, gone and strong text"},
+		{"nested emphasis", "***both*** and **bold *italic* bold** and *it **b** it*", "both and bold italic bold and it b it"},
+		{"snake_case kept", "call read_file_contents with _emphasis_", "call read_file_contents with emphasis"},
+		{"arithmetic kept", "2 * 3 * 4", "2 * 3 * 4"},
+		{"links keep text", "See [the docs](https://example.com/docs) and ![diagram](img.png).", "See the docs and diagram."},
+		{"urls kept", "Visit https://example.com/x or <https://example.org> today", "Visit https://example.com/x or https://example.org today"},
+		{"url before punctuation", "See https://example.com. and [1]", "See https://example.com. and [1]"},
+		{"url with underscores", "Open https://example.com/a_b_c/*x*", "Open https://example.com/a_b_c/*x*"},
+		{"inline code kept", "Run `go test ./...` and `**not bold**`", "Run go test ./... and **not bold**"},
+		{"code block replaced", "Before\n```go\nfunc main() {}\n```\nAfter", "Before\n\n" + PlainTextCodeMarker + "\n\nAfter"},
+		{"lists flattened", "- one\n  - two\n* three\n1. four\n- [x] done\n- [ ] todo", "one\ntwo\nthree\nfour\ndone\ntodo"},
+		{"blockquote and rule", "> quoted\n> > nested\n\n---\n\nafter", "quoted\nnested\n\nafter"},
+		{"table", "| Name | Value |\n|------|-------|\n| a | **1** |", "Name Value\na 1"},
+		{"collapses blank lines", "one\n\n\n\ntwo", "one\n\ntwo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MarkdownToPlainText(tt.md); got != tt.want {
+				t.Errorf("MarkdownToPlainText(%q) =\n%q\nwant\n%q", tt.md, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdown_ScriptLinks(t *testing.T) {
+	tests := []string{
+		"[click](javascript:alert(1))",
+		"[click](JavaScript:alert(1))",
+		"[click](java\tscript:alert(1))",
+		"[click](vbscript:msgbox)",
+		"[click](data:text/html,hi)",
+	}
+	for _, input := range tests {
+		got := RenderMarkdown(input, "")
+		if strings.Contains(got, "<a ") {
+			t.Errorf("RenderMarkdown(%q) = %q, should not link a script URL", input, got)
+		}
+		if !strings.Contains(got, "click") {
+			t.Errorf("RenderMarkdown(%q) = %q, should keep the link text", input, got)
+		}
+	}
+
+	if got := RenderMarkdown("[docs](https://example.com/javascript:x)", ""); !strings.Contains(got, `<a href="https://example.com/javascript:x"`) {
+		t.Errorf("ordinary link should still render, got %q", got)
+	}
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// mentionRe matches an @-mention of a file in a user prompt, such as @src/main.go or
+// @./README.md. The @ must start the text or follow whitespace or an opening bracket or
+// quote, so email addresses (me@example.com) are not matched.
+var mentionRe = regexp.MustCompile(`(?:^|[\s(\["'])(@((?:~|\.{1,2})?/?[\w.\-]+(?:/[\w.\-]+)*/?))`)
+
+// mentionExtRe matches a file extension at the end of a mention.
+var mentionExtRe = regexp.MustCompile(`\.[A-Za-z][\w]*$`)
+
+// isFileMention reports whether a mentioned name looks like a file path rather than a
+// handle (@alice): it has a directory separator or a file extension.
+func isFileMention(name string) bool {
+	return strings.Contains(name, "/") || mentionExtRe.MatchString(name)
+}
+
+// formatMentions escapes text, rendering each plausible file @-mention (see
+// isFileMention) as a styled mention. A mention that names an existing file, with
+// relative paths resolved against projectPath, links to it with a file:// URL.
+func formatMentions(text, projectPath string) string {
+	var sb strings.Builder
+	lastEnd := 0
+	for _, m := range mentionRe.FindAllStringSubmatchIndex(text, -1) {
+		// Punctuation ending the sentence is not part of the path
+		name := strings.TrimRight(text[m[4]:m[5]], ".")
+		if !isFileMention(name) {
+			continue
+		}
+		start, end := m[2], m[4]+len(name)
+		sb.WriteString(escapeHTML(text[lastEnd:start]))
+		sb.WriteString(renderMention(name, projectPath))
+		lastEnd = end
+	}
+	sb.WriteString(escapeHTML(text[lastEnd:]))
+	return sb.String()
+}
+
+// renderMention renders a file @-mention, linked when the file exists.
+func renderMention(name, projectPath string) string {
+	if absPath := resolveMention(name, projectPath); absPath != "" {
+		return fmt.Sprintf(`<a href="%s" class="file-link file-mention" title="%s">@%s</a>`,
+			escapeHTML(buildFileURL(absPath)), escapeHTML(absPath), escapeHTML(name))
+	}
+	return fmt.Sprintf(`<span class="file-mention">@%s</span>`, escapeHTML(name))
+}
+
+// resolveMention returns the absolute path of the file a mention names, or "" if it
+// cannot be resolved or does not exist. Relative mentions need projectPath.
+func resolveMention(name, projectPath string) string {
+	path := filepath.FromSlash(name)
+	if strings.HasPrefix(name, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		path = filepath.Join(home, path[2:])
+	} else if !filepath.IsAbs(path) {
+		if projectPath == "" {
+			return ""
+		}
+		path = filepath.Join(projectPath, path)
+	}
+	path = filepath.Clean(path)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
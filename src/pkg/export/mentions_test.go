@@ -0,0 +1,62 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatMentions(t *testing.T) {
+	projectPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectPath, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	mainGo := filepath.Join(projectPath, "src", "main.go")
+	if err := os.WriteFile(mainGo, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A relative mention of an existing file links to it
+	got := formatMentions("Look at @src/main.go.", projectPath)
+	if !strings.Contains(got, `<a href="`+buildFileURL(mainGo)+`" class="file-link file-mention"`) ||
+		!strings.Contains(got, `>@src/main.go</a>.`) {
+		t.Errorf("existing mention not linked: %s", got)
+	}
+
+	// An absolute mention resolves without the project path
+	if got := formatMentions("see @"+mainGo, ""); !strings.Contains(got, `class="file-link file-mention"`) {
+		t.Errorf("absolute mention not linked: %s", got)
+	}
+
+	// A missing file is styled but not linked
+	got = formatMentions("and (@docs/missing.md)", projectPath)
+	if !strings.Contains(got, `(<span class="file-mention">@docs/missing.md</span>)`) || strings.Contains(got, "<a ") {
+		t.Errorf("missing mention = %s", got)
+	}
+
+	// Relative mentions cannot resolve without a project path
+	if got := formatMentions("@src/main.go", ""); strings.Contains(got, "<a ") {
+		t.Errorf("relative mention linked without project path: %s", got)
+	}
+}
+
+func TestFormatMentions_IgnoresEmailsAndHandles(t *testing.T) {
+	for _, text := range []string{"mail me@example.com", "thanks @alice!", "a <b> & @bob"} {
+		if got := formatMentions(text, t.TempDir()); got != escapeHTML(text) {
+			t.Errorf("formatMentions(%q) = %q, want plain escaped text", text, got)
+		}
+	}
+}
+
+func TestFormatUserContent_MentionOutsideTags(t *testing.T) {
+	projectPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectPath, "notes.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := formatUserContentWith("Read @notes.txt\n<context>see @notes.txt</context>", projectPath)
+	if n := strings.Count(got, `class="file-link file-mention"`); n != 1 {
+		t.Errorf("expected only the mention outside the tag block to be linked, got %d in: %s", n, got)
+	}
+}
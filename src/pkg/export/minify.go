@@ -0,0 +1,159 @@
+package export
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// preservedElements are the elements whose content minifyHTML copies unchanged: their
+// whitespace is significant, or (script, style) they are not HTML text.
+var preservedElements = map[string]bool{
+	"pre":      true,
+	"textarea": true,
+	"script":   true,
+	"style":    true,
+}
+
+// preservedClasses are the classes style.css renders with white-space: pre or pre-wrap,
+// so elements carrying them keep their whitespace in minified output too.
+var preservedClasses = []string{
+	"message-content",
+	"entry-content",
+	"xml-tag-content",
+	"task-result-content",
+	"preamble-text",
+	"first-prompt-text",
+	"raw-json",
+	"thinking-content",
+	"annotation-note",
+	"tool-output",
+	"revision-diff",
+}
+
+var (
+	// htmlTokenRe matches a comment, doctype, or tag.
+	htmlTokenRe = regexp.MustCompile(`(?s)<!--.*?-->|<[!/]?[A-Za-z][^>]*>`)
+
+	// tagNameRe extracts the name of an opening or closing tag.
+	tagNameRe = regexp.MustCompile(`^</?([A-Za-z][A-Za-z0-9-]*)`)
+
+	// classAttrRe extracts the class attribute of a tag.
+	classAttrRe = regexp.MustCompile(`\sclass="([^"]*)"`)
+
+	// indentRe matches a run of whitespace spanning a line break.
+	indentRe = regexp.MustCompile(`[ \t\r]*\n[ \t\r\n]*`)
+)
+
+// minifyHTML shrinks generated HTML (see ExportOptions.Minify) by collapsing each run of
+// whitespace that spans a line break, such as the newline and indentation between tags,
+// to a single newline. Browsers collapse such runs to one space anyway, so the page
+// renders the same. Whitespace on a single line is left alone, as is everything inside
+// preservedElements and elements with preservedClasses.
+func minifyHTML(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+
+	pos := 0
+	for pos < len(s) {
+		loc := htmlTokenRe.FindStringIndex(s[pos:])
+		if loc == nil {
+			sb.WriteString(indentRe.ReplaceAllString(s[pos:], "\n"))
+			break
+		}
+		start, end := pos+loc[0], pos+loc[1]
+		sb.WriteString(indentRe.ReplaceAllString(s[pos:start], "\n"))
+		tag := s[start:end]
+		sb.WriteString(tag)
+		pos = end
+
+		if name, ok := preservedOpening(tag); ok {
+			closeAt := preservedEnd(s, pos, name)
+			sb.WriteString(s[pos:closeAt])
+			pos = closeAt
+		}
+	}
+	return sb.String()
+}
+
+// preservedOpening reports whether tag opens an element whose content is preserved, and
+// its lowercase name.
+func preservedOpening(tag string) (string, bool) {
+	if strings.HasPrefix(tag, "</") || strings.HasPrefix(tag, "<!") || strings.HasSuffix(tag, "/>") {
+		return "", false
+	}
+	m := tagNameRe.FindStringSubmatch(tag)
+	if m == nil {
+		return "", false
+	}
+	name := strings.ToLower(m[1])
+	if preservedElements[name] {
+		return name, true
+	}
+	if class := classAttrRe.FindStringSubmatch(tag); class != nil {
+		for _, c := range strings.Fields(class[1]) {
+			for _, preserved := range preservedClasses {
+				if c == preserved {
+					return name, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// preservedEnd returns the offset in s of the tag closing the name element whose content
+// starts at pos, counting nested elements of the same name, or len(s) if it is unclosed.
+func preservedEnd(s string, pos int, name string) int {
+	closing := "</" + name
+	if name == "script" || name == "style" {
+		if i := strings.Index(strings.ToLower(s[pos:]), closing); i >= 0 {
+			return pos + i
+		}
+		return len(s)
+	}
+
+	depth := 1
+	for _, loc := range htmlTokenRe.FindAllStringIndex(s[pos:], -1) {
+		tag := s[pos+loc[0] : pos+loc[1]]
+		m := tagNameRe.FindStringSubmatch(tag)
+		if m == nil || strings.ToLower(m[1]) != name {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(tag, "</"):
+			depth--
+			if depth == 0 {
+				return pos + loc[0]
+			}
+		case !strings.HasSuffix(tag, "/>"):
+			depth++
+		}
+	}
+	return len(s)
+}
+
+// minifyAsset shrinks a static CSS or JavaScript asset (see ExportOptions.Minify) by
+// dropping indentation, blank lines, and lines that are only a comment. Line breaks are
+// kept, so automatic semicolon insertion and strings are unaffected; the assets use no
+// multi-line template literals.
+func minifyAsset(name, content string) string {
+	isCSS := filepath.Ext(name) == ".css"
+
+	var sb strings.Builder
+	sb.Grow(len(content))
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case isCSS && strings.HasPrefix(line, "/*") && strings.HasSuffix(line, "*/") && strings.Count(line, "/*") == 1:
+			continue
+		case !isCSS && strings.HasPrefix(line, "//"):
+			continue
+		}
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
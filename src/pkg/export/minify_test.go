@@ -0,0 +1,139 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestMinifyHTML(t *testing.T) {
+	input := "<div class=\"entry\">\n    <span>a</span> <span>b</span>\n    <pre class=\"tool-input\">  keep\n\n    this  </pre>\n" +
+		"    <div class=\"message-content\">line one\n\n    <div>  nested\n  </div>\n  tail  </div>\n" +
+		"    <script>\n  if (a) {\n    b();\n  }\n</script>\n</div>\n"
+	want := "<div class=\"entry\">\n<span>a</span> <span>b</span>\n<pre class=\"tool-input\">  keep\n\n    this  </pre>\n" +
+		"<div class=\"message-content\">line one\n\n    <div>  nested\n  </div>\n  tail  </div>\n" +
+		"<script>\n  if (a) {\n    b();\n  }\n</script>\n</div>\n"
+
+	if got := minifyHTML(input); got != want {
+		t.Errorf("minifyHTML() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+// preContents returns the contents of every <pre> element in html.
+func preContents(html string) []string {
+	var contents []string
+	for _, m := range regexp.MustCompile(`(?s)<pre[^>]*>(.*?)</pre>`).FindAllStringSubmatch(html, -1) {
+		contents = append(contents, m[1])
+	}
+	return contents
+}
+
+func TestHTMLExporter_Minify(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-01-01T10:00:00Z",
+			Message: json.RawMessage(`{"role":"user","content":"Indent this:\n    four spaces\n\n\ttab"}`)},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-01-01T10:00:01Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Here:\n\n` + "```go\\nfunc main() {\\n\\tfmt.Println(\\\"  hi  \\\")\\n}\\n```" + `"},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls   -la"}}]}`)},
+		{UUID: "u2", Type: models.EntryTypeUser, Timestamp: "2026-01-01T10:00:02Z",
+			Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"total 0\n   drwx  .\n"}]}`)},
+	}
+
+	pretty, err := HTMLExporter{}.Render(entries, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compact, err := HTMLExporter{Options: ExportOptions{Minify: true}}.Render(entries, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(compact) >= len(pretty) {
+		t.Errorf("minified page is %d bytes, pretty %d", len(compact), len(pretty))
+	}
+	if !strings.Contains(string(pretty), "\n    <div class=\"session-metadata\">") || strings.Contains(string(compact), "\n    <div class=\"session-metadata\">") {
+		t.Error("minified page should drop the indentation of the header")
+	}
+
+	// Preformatted text is byte for byte the same
+	prettyPre, compactPre := preContents(string(pretty)), preContents(string(compact))
+	if len(prettyPre) == 0 || strings.Join(prettyPre, "\x00") != strings.Join(compactPre, "\x00") {
+		t.Errorf("<pre> contents changed:\npretty  %q\ncompact %q", prettyPre, compactPre)
+	}
+	for _, want := range []string{"    four spaces\n\n\ttab", "total 0\n   drwx  ."} {
+		if !strings.Contains(string(compact), want) {
+			t.Errorf("minified page lost %q", want)
+		}
+	}
+}
+
+// TestPreservedClasses_MatchStyleCSS checks that every class style.css renders with
+// significant whitespace is preserved by minifyHTML.
+func TestPreservedClasses_MatchStyleCSS(t *testing.T) {
+	css := regexp.MustCompile(`(?s)/\*.*?\*/`).ReplaceAllString(GetStyleCSS(), "")
+	rules := regexp.MustCompile(`([^{}]+)\{[^{}]*white-space:\s*pre`).FindAllStringSubmatch(css, -1)
+	if len(rules) == 0 {
+		t.Fatal("no white-space: pre rules found in style.css")
+	}
+
+	preserved := make(map[string]bool)
+	for _, c := range preservedClasses {
+		preserved[c] = true
+	}
+	last := regexp.MustCompile(`(?:\.([\w-]+)|([a-z]+))$`)
+	for _, rule := range rules {
+		for _, selector := range strings.Split(rule[1], ",") {
+			m := last.FindStringSubmatch(strings.TrimSpace(selector))
+			if m == nil {
+				t.Errorf("cannot tell the element of selector %q", selector)
+				continue
+			}
+			if m[1] != "" && !preserved[m[1]] || m[2] != "" && !preservedElements[m[2]] {
+				t.Errorf("selector %q keeps whitespace but is not preserved by minifyHTML", strings.TrimSpace(selector))
+			}
+		}
+	}
+}
+
+func TestMinifyAsset(t *testing.T) {
+	js := "// Section\nfunction f() {\n    // note\n    return 'a // b';\n}\n\n\nf();\n"
+	if got, want := minifyAsset("x.js", js), "function f() {\nreturn 'a // b';\n}\nf();\n"; got != want {
+		t.Errorf("minifyAsset(js) = %q, want %q", got, want)
+	}
+
+	css := "/* Header */\n.a {\n    color: red; /* why */\n}\n\n/* one */ .b { }\n"
+	if got, want := minifyAsset("x.css", css), ".a {\ncolor: red; /* why */\n}\n/* one */ .b { }\n"; got != want {
+		t.Errorf("minifyAsset(css) = %q, want %q", got, want)
+	}
+}
+
+func TestWriteStaticAssetsWith_Minify(t *testing.T) {
+	pretty, compact := t.TempDir(), t.TempDir()
+	if err := WriteStaticAssets(pretty); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteStaticAssetsWith(compact, ExportOptions{Minify: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name := range staticAssetGetters {
+		p, err := os.ReadFile(filepath.Join(pretty, StaticDirName, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := os.ReadFile(filepath.Join(compact, StaticDirName, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(p) != staticAssetGetters[name]() {
+			t.Errorf("%s: default output should be unchanged", name)
+		}
+		if len(c) >= len(p) {
+			t.Errorf("%s: minified %d bytes, pretty %d", name, len(c), len(p))
+		}
+	}
+}
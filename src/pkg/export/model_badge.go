@@ -0,0 +1,41 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// predominantModel returns the model used by the most assistant entries, or "" if no
+// entry records a model. Ties go to the model that reached the top count first.
+func predominantModel(entries []models.ConversationEntry) string {
+	counts := make(map[string]int)
+	best := ""
+	for i := range entries {
+		if entries[i].Type != models.EntryTypeAssistant {
+			continue
+		}
+		model := entries[i].ModelName()
+		if model == "" {
+			continue
+		}
+		counts[model]++
+		if best == "" || counts[model] > counts[best] {
+			best = model
+		}
+	}
+	return best
+}
+
+// renderModelBadge renders a badge with the entry's model when it differs from the
+// page's default model. Entries with an unknown model never get a badge.
+func renderModelBadge(entry models.ConversationEntry, defaultModel string) string {
+	if entry.Type != models.EntryTypeAssistant || defaultModel == "" {
+		return ""
+	}
+	model := entry.ModelName()
+	if model == "" || model == defaultModel {
+		return ""
+	}
+	return fmt.Sprintf(`<span class="model-badge" title="Model: %s">%s</span>`, escapeHTML(model), escapeHTML(model))
+}
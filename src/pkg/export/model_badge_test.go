@@ -0,0 +1,118 @@
+package export
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func modelEntry(uuid, model, text string) models.ConversationEntry {
+	msg, _ := json.Marshal(map[string]any{
+		"role":    "assistant",
+		"model":   model,
+		"content": []map[string]string{{"type": "text", "text": text}},
+	})
+	return models.ConversationEntry{UUID: uuid, Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:00Z", Message: msg}
+}
+
+func TestPredominantModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []models.ConversationEntry
+		want    string
+	}{
+		{"no entries", nil, ""},
+		{"unknown models", []models.ConversationEntry{modelEntry("a", "", "x"), modelEntry("b", "<synthetic>", "y")}, ""},
+		{"most common wins", []models.ConversationEntry{
+			modelEntry("a", "claude-haiku", "x"),
+			modelEntry("b", "claude-opus", "y"),
+			modelEntry("c", "claude-opus", "z"),
+		}, "claude-opus"},
+		{"user entries ignored", []models.ConversationEntry{
+			{Type: models.EntryTypeUser, Message: json.RawMessage(`{"role":"user","model":"claude-haiku","content":"q"}`)},
+			modelEntry("b", "claude-opus", "y"),
+		}, "claude-opus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := predominantModel(tt.entries); got != tt.want {
+				t.Errorf("predominantModel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderConversation_ModelBadgeWhenModelDiffers(t *testing.T) {
+	entries := []models.ConversationEntry{
+		modelEntry("a", "claude-opus", "first"),
+		modelEntry("b", "claude-opus", "second"),
+		modelEntry("c", "claude-haiku", "third"),
+		modelEntry("d", "<synthetic>", "fourth"),
+	}
+
+	html, err := RenderConversation(entries, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(html, `class="model-badge"`); got != 1 {
+		t.Errorf("expected exactly one model badge, got %d", got)
+	}
+	if !strings.Contains(html, `<span class="model-badge" title="Model: claude-haiku">claude-haiku</span>`) {
+		t.Error("entry using a non-default model should show a badge")
+	}
+}
+
+func TestRenderConversation_NoModelBadgeWhenConstantOrUnknown(t *testing.T) {
+	tests := map[string][]models.ConversationEntry{
+		"constant": {modelEntry("a", "claude-opus", "one"), modelEntry("b", "claude-opus", "two")},
+		"unknown":  {modelEntry("a", "", "one"), modelEntry("b", "", "two")},
+	}
+	for name, entries := range tests {
+		t.Run(name, func(t *testing.T) {
+			html, err := RenderConversation(entries, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(html, `class="model-badge"`) {
+				t.Error("no model badge expected")
+			}
+		})
+	}
+}
+
+func TestRenderAgentFragment_ModelBadge(t *testing.T) {
+	entries := []models.ConversationEntry{
+		modelEntry("a", "claude-haiku", "one"),
+		modelEntry("b", "claude-haiku", "two"),
+		modelEntry("c", "claude-sonnet", "three"),
+	}
+	html, err := RenderAgentFragment("a1b2c3d", entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, `>claude-sonnet</span>`) || strings.Contains(html, `>claude-haiku</span>`) {
+		t.Errorf("fragment should badge only entries differing from the agent's own default model")
+	}
+}
+
+func TestComputeSessionStats_Models(t *testing.T) {
+	entries := []models.ConversationEntry{
+		modelEntry("a", "claude-opus", "one"),
+		modelEntry("b", "", "two"),
+		modelEntry("c", "claude-haiku", "three"),
+		modelEntry("d", "claude-opus", "four"),
+	}
+
+	stats := ComputeSessionStats(entries, nil)
+	if want := []string{"claude-opus", "claude-haiku"}; !reflect.DeepEqual(stats.Models, want) {
+		t.Errorf("Models = %v, want %v", stats.Models, want)
+	}
+
+	if stats := ComputeSessionStats([]models.ConversationEntry{modelEntry("a", "", "x")}, nil); stats.Models != nil {
+		t.Errorf("Models = %v, want nil when unknown", stats.Models)
+	}
+}
@@ -0,0 +1,104 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRenderToolCall_NoJS(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Read", Input: map[string]any{"file_path": "/tmp/a.go"}}
+	result := models.ToolResult{ToolUseID: "toolu_1", Content: "package a"}
+
+	html := renderToolCallWith(tool, result, true, "", entryRenderOptions{opts: ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen, NoJS: true}})
+
+	if !strings.HasPrefix(html, `<details class="tool-call" id="tool-toolu_1" data-tool-id="toolu_1">`) {
+		t.Errorf("tool call should open a <details> element, got:\n%s", html)
+	}
+	if !strings.Contains(html, `<summary class="tool-header">`) || !strings.HasSuffix(html, "  </div>\n</details>\n") {
+		t.Errorf("tool call should have a summary header and close the details element, got:\n%s", html)
+	}
+	if strings.Contains(html, "onclick") || strings.Contains(html, "collapsible") {
+		t.Errorf("tool call should not depend on scripts, got:\n%s", html)
+	}
+	if !strings.Contains(html, `href="#tool-result-toolu_1"`) {
+		t.Error("result link should remain as a plain fragment link")
+	}
+}
+
+func TestRenderBashToolCall_NoJS(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_2", Name: "Bash", Input: map[string]any{"command": "ls"}}
+	result := models.ToolResult{ToolUseID: "toolu_2", Content: "a.go"}
+
+	html := renderToolCallWith(tool, result, true, "", entryRenderOptions{opts: ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen, NoJS: true}})
+
+	if !strings.HasPrefix(html, `<details class="tool-call"`) || !strings.HasSuffix(html, "</details>\n") {
+		t.Errorf("Bash call should be a <details> element, got:\n%s", html)
+	}
+	if strings.Contains(html, "onclick") {
+		t.Errorf("Bash call should not depend on scripts, got:\n%s", html)
+	}
+}
+
+func TestRenderSubagentPlaceholder_NoJS(t *testing.T) {
+	html := renderSubagentPlaceholderWith("abc1234", map[string]int{"abc1234": 2}, "s1", "", subagentDetails{content: "<p>inlined</p>"},
+		entryRenderOptions{opts: ExportOptions{NoJS: true}})
+
+	if !strings.HasPrefix(html, `<details class="subagent" id="agent-abc1234" data-agent-id="abc1234">`) {
+		t.Errorf("subagent should be a <details> element, got:\n%s", html)
+	}
+	if !strings.Contains(html, `<div class="subagent-content"><p>inlined</p></div>`) {
+		t.Errorf("subagent content should be inlined, got:\n%s", html)
+	}
+	if strings.Contains(html, "loadAgent") {
+		t.Errorf("subagent should not be loaded on demand, got:\n%s", html)
+	}
+}
+
+func TestRenderTodoEvolution_NoJS(t *testing.T) {
+	calls := []models.ToolUse{
+		{ID: "t1", Name: "TodoWrite", Input: map[string]any{"todos": []any{map[string]any{"content": "A", "status": "pending"}}}},
+		{ID: "t2", Name: "TodoWrite", Input: map[string]any{"todos": []any{map[string]any{"content": "A", "status": "completed"}}}},
+	}
+
+	html := renderTodoEvolutionWith(models.ConversationEntry{}, calls, entryRenderOptions{opts: ExportOptions{NoJS: true}})
+
+	if !strings.Contains(html, `<details class="tool-call todo-history"><summary class="tool-header">`) || strings.Contains(html, "onclick") {
+		t.Errorf("todo history should collapse with <details>, got:\n%s", html)
+	}
+}
+
+func TestRenderConversation_NoJS(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, SessionID: "s1", Timestamp: "2026-02-01T10:00:00Z", Message: []byte(`"Hello"`)},
+		{UUID: "q1", Type: models.EntryTypeQueueOperation, SessionID: "s1", Timestamp: "2026-02-01T10:00:01Z", AgentID: "abc1234"},
+	}
+	agents := []*agent.TreeNode{{AgentID: "abc1234", EntryCount: 1}}
+	opts := ExportOptions{
+		NoJS:        true,
+		ReplayMode:  true,
+		SearchIndex: true,
+		AgentEntries: map[string][]models.ConversationEntry{
+			"abc1234": {{UUID: "a1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:01Z", Message: []byte(`"Sub task"`)}},
+		},
+	}
+
+	html, err := RenderConversationWithOptions(entries, agents, nil, opts)
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+
+	if !strings.Contains(html, `<body data-session-id="s1" class="no-js">`) {
+		t.Error("body should be marked no-js")
+	}
+	if !strings.Contains(html, `data-uuid="a1"`) || !strings.Contains(html, "Sub task") {
+		t.Error("subagent conversation should be inlined")
+	}
+	for _, unwanted := range []string{"<script", `id="search-box"`, `id="expand-all-btn"`, "replay-hidden", "agent-stats-interactive", "Keyboard Shortcuts"} {
+		if strings.Contains(html, unwanted) {
+			t.Errorf("no-js export should not contain %q", unwanted)
+		}
+	}
+}
@@ -22,6 +22,8 @@ var toolRegistry = map[string]ToolInfo{
 	"Read":         {Icon: "\xf0\x9f\x93\x84", Hint: "file read", ColorClass: "tool-read", DisplayName: "Read"},
 	"Write":        {Icon: "\xf0\x9f\x93\x9d", Hint: "file write", ColorClass: "tool-write", DisplayName: "Write"},
 	"Edit":         {Icon: "\xe2\x9c\x8f\xef\xb8\x8f", Hint: "file edit", ColorClass: "tool-edit", DisplayName: "Edit"},
+	"MultiEdit":    {Icon: "\xe2\x9c\x8f\xef\xb8\x8f", Hint: "multiple file edits", ColorClass: "tool-edit", DisplayName: "MultiEdit"},
+	"ApplyPatch":   {Icon: "\xe2\x9c\x8f\xef\xb8\x8f", Hint: "apply patch", ColorClass: "tool-edit", DisplayName: "ApplyPatch"},
 	"Grep":         {Icon: "\xf0\x9f\x94\x8d", Hint: "content search", ColorClass: "tool-grep", DisplayName: "Grep"},
 	"Glob":         {Icon: "\xf0\x9f\x93\x81", Hint: "file pattern matching", ColorClass: "tool-glob", DisplayName: "Glob"},
 	"Task":         {Icon: "\xf0\x9f\xa4\x96", Hint: "spawn subagent", ColorClass: "tool-task", DisplayName: "Task"},
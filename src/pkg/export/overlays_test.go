@@ -667,3 +667,40 @@ func TestToolRegistry_Completeness(t *testing.T) {
 		}
 	}
 }
+
+// Thinking blocks are not rendered in message bodies (RenderThinkingOverlay is not wired
+// into the exports), so HTML, markdown and text output leave their text out while still
+// showing the answer of a message that has both.
+func TestExports_OmitThinkingBlocks(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:00Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"thinking","thinking":"secret reasoning","text":"secret reasoning"},{"type":"text","text":"Final answer"}]}`)},
+		{UUID: "a2", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:05Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"thinking","text":"only thinking here"}]}`)},
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	md, err := renderConversationMarkdown(entries, nil, nil, false, localizer{}, 0)
+	if err != nil {
+		t.Fatalf("renderConversationMarkdown() error = %v", err)
+	}
+	text, err := renderConversationText(entries, nil, nil, false, localizer{}, 0)
+	if err != nil {
+		t.Fatalf("renderConversationText() error = %v", err)
+	}
+
+	for name, out := range map[string]string{"html": html, "markdown": md, "text": text} {
+		if !strings.Contains(out, "Final answer") {
+			t.Errorf("%s output should keep the answer of a message with thinking", name)
+		}
+		if strings.Contains(out, "secret reasoning") || strings.Contains(out, "only thinking here") {
+			t.Errorf("%s output should not contain thinking text", name)
+		}
+	}
+	if strings.Contains(html, `data-uuid="a2"`) {
+		t.Error("a message with only thinking should not be rendered")
+	}
+}
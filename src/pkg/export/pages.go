@@ -0,0 +1,278 @@
+package export
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// PageIndexFileName is the page listing the pages of a paginated export.
+const PageIndexFileName = "index.html"
+
+// pagePreviewLen is the longest first-prompt preview shown for a page in the index, in bytes.
+const pagePreviewLen = 120
+
+// PageInfo is the position of one page in a paginated export (see ExportOptions.PageSize).
+type PageInfo struct {
+	Number int // 1-based page number
+	Total  int // Number of pages in the export
+	Start  int // Index of the page's first entry in the session
+	End    int // Index one past the page's last entry
+}
+
+// FileName returns the page's file name, e.g. "page-2.html".
+func (p PageInfo) FileName() string {
+	return PageFileName(p.Number)
+}
+
+// PageFileName returns the file name of page n of a paginated export.
+func PageFileName(n int) string {
+	return fmt.Sprintf("page-%d.html", n)
+}
+
+// RenderedPage is one HTML file of an export.
+type RenderedPage struct {
+	FileName string // Path relative to the export root
+	HTML     string
+}
+
+// isPageMessage reports whether entry is shown as a message and so counts toward a page's size.
+func isPageMessage(entry models.ConversationEntry) bool {
+	return hasContent(entry) || entry.IsInterruption() || entry.IsAPIError() || entry.IsStopNotice()
+}
+
+// SplitPages divides entries into pages of pageSize messages. Hidden entries (tool results,
+// subagent spawns) stay on the page of the message before them. There is always at least
+// one page; pageSize <= 0 puts everything on it.
+func SplitPages(entries []models.ConversationEntry, pageSize int) []PageInfo {
+	var pages []PageInfo
+	start, messages := 0, 0
+	for i, entry := range entries {
+		if !isPageMessage(entry) {
+			continue
+		}
+		if pageSize > 0 && messages == pageSize {
+			pages = append(pages, PageInfo{Start: start, End: i})
+			start, messages = i, 0
+		}
+		messages++
+	}
+	pages = append(pages, PageInfo{Start: start, End: len(entries)})
+
+	for i := range pages {
+		pages[i].Number = i + 1
+		pages[i].Total = len(pages)
+	}
+	return pages
+}
+
+// RenderConversationPages renders a session as an index page plus one page per
+// opts.PageSize messages, linked with previous/next navigation (see RenderConversationPage).
+// With opts.PageSize <= 0 it returns the single page RenderConversationWithOptions renders,
+// as index.html. With opts.GroupByTool, tools.html (see RenderByTool) comes last.
+func RenderConversationPages(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats, opts ExportOptions) ([]RenderedPage, error) {
+	entries, agents, stats, opts = stripIDs(entries, agents, stats, opts)
+	if opts.PageSize <= 0 {
+		html, err := RenderConversationWithOptions(entries, agents, stats, opts)
+		if err != nil {
+			return nil, err
+		}
+		rendered := []RenderedPage{{FileName: PageIndexFileName, HTML: html}}
+		if opts.GroupByTool {
+			rendered = append(rendered, RenderedPage{FileName: ByToolFileName, HTML: renderByTool(entries, func(int) string { return PageIndexFileName })})
+		}
+		return rendered, nil
+	}
+
+	// Stats describe the whole session on every page
+	if stats == nil {
+		stats = ComputeSessionStats(entries, agents)
+	}
+	stats = statsWithIdleThreshold(stats, entries, opts)
+
+	pages := SplitPages(entries, opts.PageSize)
+	index, err := renderPageIndex(entries, pages, agents, stats, opts)
+	if err != nil {
+		return nil, err
+	}
+	rendered := []RenderedPage{{FileName: PageIndexFileName, HTML: index}}
+	for _, page := range pages {
+		html, err := RenderConversationPage(entries, page, agents, stats, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", page.FileName(), err)
+		}
+		rendered = append(rendered, RenderedPage{FileName: page.FileName(), HTML: html})
+	}
+	if opts.GroupByTool {
+		rendered = append(rendered, RenderedPage{FileName: ByToolFileName, HTML: renderByTool(entries, func(entry int) string {
+			return pageOfEntry(pages, entry).FileName()
+		})})
+	}
+	return rendered, nil
+}
+
+// pageOfEntry returns the page holding the entry at index i.
+func pageOfEntry(pages []PageInfo, i int) PageInfo {
+	for _, page := range pages {
+		if i < page.End {
+			return page
+		}
+	}
+	return pages[len(pages)-1]
+}
+
+// RenderConversationPage renders entries[page.Start:page.End] as one page of a paginated
+// export, like RenderConversationWithOptions, with links to the neighbouring pages and the
+// index. entries is the whole session, so tool calls show results written on the next
+// page. Subagent sections load their fragments from agents/ as on a single-file export.
+func RenderConversationPage(entries []models.ConversationEntry, page PageInfo, agents []*agent.TreeNode, stats *SessionStats, opts ExportOptions) (string, error) {
+	if page.Start < 0 || page.End > len(entries) || page.Start > page.End {
+		return "", fmt.Errorf("page %d range [%d, %d) is outside the %d entries", page.Number, page.Start, page.End, len(entries))
+	}
+	entries, agents, stats, opts = stripIDs(entries, agents, stats, opts)
+	if stats == nil {
+		stats = ComputeSessionStats(entries, agents)
+	}
+	stats = statsWithIdleThreshold(stats, entries, opts)
+	if err := ValidateTypeColors(opts.TypeColors); err != nil {
+		return "", err
+	}
+	entries, opts, err := transcodeToolOutput(entries, opts)
+	if err != nil {
+		return "", err
+	}
+	agentMap := buildAgentMap(agents)
+
+	// Agents left out by MaxAgents are listed once, at the end of the last page
+	blocks := renderConversationBlocksWith(entries[page.Start:page.End], entries, agentMap, stats, opts, page.Number == page.Total)
+
+	var sb strings.Builder
+	if opts.Paginate {
+		sb.WriteString(`<div class="conversation paginated">` + "\n")
+	} else {
+		sb.WriteString(`<div class="conversation">` + "\n")
+	}
+	for _, block := range blocks {
+		sb.WriteString(string(block.HTML))
+	}
+	sb.WriteString("</div>\n")
+	if opts.SearchIndex && !opts.NoJS {
+		sb.WriteString(renderSearchIndex(blocks))
+	}
+
+	layout, err := loadLayoutTemplate(opts.TemplateFile)
+	if err != nil {
+		return "", err
+	}
+	loc := newLocalizer(opts.Locale)
+
+	return executeLayout(layout, LayoutData{
+		Stats:         stats,
+		Agents:        agents,
+		Entries:       blocks,
+		FormatVersion: ExportFormatVersion,
+		Header:        template.HTML(renderHTMLHeaderWith(stats, agentMap, loc, opts)),
+		Conversation:  template.HTML(sb.String()),
+		Footer:        template.HTML(renderHTMLFooterWith(stats, opts)),
+		Page:          &page,
+		PageNav:       template.HTML(renderPageNav(page)),
+	})
+}
+
+// renderPageNav renders the bar linking a page to the index and its neighbours.
+func renderPageNav(page PageInfo) string {
+	var sb strings.Builder
+	sb.WriteString(`<nav class="page-nav" aria-label="Pages">`)
+	sb.WriteString(fmt.Sprintf(`<a class="page-nav-index" href="%s">All pages</a>`, PageIndexFileName))
+	if page.Number > 1 {
+		sb.WriteString(fmt.Sprintf(`<a class="page-nav-prev" rel="prev" href="%s">‹ Previous</a>`, PageFileName(page.Number-1)))
+	} else {
+		sb.WriteString(`<span class="page-nav-prev disabled">‹ Previous</span>`)
+	}
+	sb.WriteString(fmt.Sprintf(`<span class="page-nav-position">Page %d of %d</span>`, page.Number, page.Total))
+	if page.Number < page.Total {
+		sb.WriteString(fmt.Sprintf(`<a class="page-nav-next" rel="next" href="%s">Next ›</a>`, PageFileName(page.Number+1)))
+	} else {
+		sb.WriteString(`<span class="page-nav-next disabled">Next ›</span>`)
+	}
+	sb.WriteString(`<span class="page-nav-note">Search covers this page only</span>`)
+	sb.WriteString("</nav>\n")
+	return sb.String()
+}
+
+// renderPageIndex renders index.html of a paginated export: the session header and
+// timeline followed by a list of the pages with their time range and first prompt.
+func renderPageIndex(entries []models.ConversationEntry, pages []PageInfo, agents []*agent.TreeNode, stats *SessionStats, opts ExportOptions) (string, error) {
+	agentMap := buildAgentMap(agents)
+
+	var sb strings.Builder
+	sb.WriteString(`<nav class="page-index" aria-label="Pages">` + "\n")
+	sb.WriteString(fmt.Sprintf(`    <h2>Pages (up to %d messages each)</h2>`+"\n", opts.PageSize))
+	sb.WriteString("    <ol>\n")
+	for _, page := range pages {
+		sb.WriteString(renderPageIndexItem(entries[page.Start:page.End], page))
+	}
+	sb.WriteString("    </ol>\n")
+	sb.WriteString(`    <p class="page-nav-note">Search covers one page at a time</p>` + "\n")
+	sb.WriteString("</nav>\n")
+
+	layout, err := loadLayoutTemplate(opts.TemplateFile)
+	if err != nil {
+		return "", err
+	}
+	loc := newLocalizer(opts.Locale)
+
+	return executeLayout(layout, LayoutData{
+		Stats:         stats,
+		Agents:        agents,
+		FormatVersion: ExportFormatVersion,
+		Header:        template.HTML(renderHTMLHeader(stats, agentMap, loc)),
+		Timeline:      template.HTML(renderAgentTimeline(opts.Timeline, loc)),
+		Conversation:  template.HTML(sb.String()),
+		Footer:        template.HTML(renderHTMLFooter(stats)),
+	})
+}
+
+// renderPageIndexItem renders the index entry for one page: a link, the time span of its
+// entries, its message count, and a preview of its first user prompt.
+func renderPageIndexItem(entries []models.ConversationEntry, page PageInfo) string {
+	var first, last, preview string
+	messages := 0
+	for _, entry := range entries {
+		if entry.Timestamp != "" {
+			if first == "" {
+				first = entry.Timestamp
+			}
+			last = entry.Timestamp
+		}
+		if !isPageMessage(entry) {
+			continue
+		}
+		messages++
+		if preview == "" && entry.Type == models.EntryTypeUser {
+			preview = strings.Join(strings.Fields(entry.GetTextContent()), " ")
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`        <li><a href="%s">Page %d</a>`, page.FileName(), page.Number))
+	if first != "" {
+		sb.WriteString(fmt.Sprintf(` <span class="page-range">%s – %s</span>`, escapeHTML(formatTimestampReadable(first)), escapeHTML(formatTimestampReadable(last))))
+	}
+	noun := "messages"
+	if messages == 1 {
+		noun = "message"
+	}
+	sb.WriteString(fmt.Sprintf(` <span class="page-count">(%d %s)</span>`, messages, noun))
+	if preview != "" {
+		if truncated, cut := truncateUTF8(preview, pagePreviewLen); cut {
+			preview = truncated + "…"
+		}
+		sb.WriteString(fmt.Sprintf(` <span class="page-preview">%s</span>`, escapeHTML(preview)))
+	}
+	sb.WriteString("</li>\n")
+	return sb.String()
+}
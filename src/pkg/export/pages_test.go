@@ -0,0 +1,225 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// pagedSession returns a session of n user/assistant messages alternating, one second apart.
+func pagedSession(n int) []models.ConversationEntry {
+	var entries []models.ConversationEntry
+	for i := 0; i < n; i++ {
+		entryType, text := models.EntryTypeUser, "Question "
+		if i%2 == 1 {
+			entryType, text = models.EntryTypeAssistant, "Answer "
+		}
+		msg, _ := json.Marshal(text + string(rune('A'+i)))
+		entries = append(entries, models.ConversationEntry{
+			UUID:      "m" + string(rune('a'+i)),
+			Type:      entryType,
+			Timestamp: "2026-02-01T10:00:0" + string(rune('0'+i)) + "Z",
+			Message:   msg,
+		})
+	}
+	return entries
+}
+
+func TestSplitPages(t *testing.T) {
+	entries := pagedSession(5)
+	// A hidden entry between the 2nd and 3rd message stays on the earlier page
+	entries = append(entries[:2], append([]models.ConversationEntry{{UUID: "q", Type: models.EntryTypeQueueOperation, AgentID: "x"}}, entries[2:]...)...)
+
+	pages := SplitPages(entries, 2)
+	want := []PageInfo{
+		{Number: 1, Total: 3, Start: 0, End: 3},
+		{Number: 2, Total: 3, Start: 3, End: 5},
+		{Number: 3, Total: 3, Start: 5, End: 6},
+	}
+	if len(pages) != len(want) {
+		t.Fatalf("SplitPages() = %+v, want %+v", pages, want)
+	}
+	for i := range want {
+		if pages[i] != want[i] {
+			t.Errorf("page %d = %+v, want %+v", i+1, pages[i], want[i])
+		}
+	}
+}
+
+func TestSplitPages_SinglePage(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []models.ConversationEntry
+		pageSize int
+	}{
+		{"no size", pagedSession(5), 0},
+		{"exact fit", pagedSession(4), 4},
+		{"larger than session", pagedSession(3), 10},
+		{"empty session", nil, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := SplitPages(tt.entries, tt.pageSize)
+			want := PageInfo{Number: 1, Total: 1, Start: 0, End: len(tt.entries)}
+			if len(pages) != 1 || pages[0] != want {
+				t.Errorf("SplitPages() = %+v, want [%+v]", pages, want)
+			}
+		})
+	}
+}
+
+func TestPageFileName(t *testing.T) {
+	if got := PageFileName(12); got != "page-12.html" {
+		t.Errorf("PageFileName(12) = %q", got)
+	}
+	if got := (PageInfo{Number: 3}).FileName(); got != "page-3.html" {
+		t.Errorf("FileName() = %q", got)
+	}
+}
+
+func TestRenderConversationPages_DefaultIsSingleFile(t *testing.T) {
+	entries := pagedSession(4)
+	opts := ExportOptions{Deterministic: true}
+
+	pages, err := RenderConversationPages(entries, nil, nil, opts)
+	if err != nil {
+		t.Fatalf("RenderConversationPages() error = %v", err)
+	}
+	want, err := RenderConversationWithOptions(entries, nil, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 || pages[0].FileName != "index.html" || pages[0].HTML != want {
+		t.Errorf("without PageSize, want index.html identical to RenderConversationWithOptions")
+	}
+	if strings.Contains(want, "page-nav") {
+		t.Error("single-file export should have no page navigation")
+	}
+}
+
+func TestRenderConversationPages_Navigation(t *testing.T) {
+	pages, err := RenderConversationPages(pagedSession(5), nil, nil, ExportOptions{PageSize: 2, Deterministic: true})
+	if err != nil {
+		t.Fatalf("RenderConversationPages() error = %v", err)
+	}
+
+	var names []string
+	for _, p := range pages {
+		names = append(names, p.FileName)
+	}
+	if got := strings.Join(names, ","); got != "index.html,page-1.html,page-2.html,page-3.html" {
+		t.Fatalf("files = %s", got)
+	}
+
+	first, middle, last := pages[1].HTML, pages[2].HTML, pages[3].HTML
+	if strings.Contains(first, `rel="prev"`) || !strings.Contains(first, `rel="next" href="page-2.html"`) {
+		t.Error("page 1 should link to page 2 only")
+	}
+	if !strings.Contains(middle, `rel="prev" href="page-1.html"`) || !strings.Contains(middle, `rel="next" href="page-3.html"`) {
+		t.Error("page 2 should link to pages 1 and 3")
+	}
+	if !strings.Contains(last, `rel="prev" href="page-2.html"`) || strings.Contains(last, `rel="next"`) {
+		t.Error("page 3 should link to page 2 only")
+	}
+	for _, p := range pages[1:] {
+		if strings.Count(p.HTML, `class="page-nav"`) != 2 {
+			t.Errorf("%s should have navigation above and below the conversation", p.FileName)
+		}
+		if !strings.Contains(p.HTML, `href="index.html"`) || !strings.Contains(p.HTML, "Search covers this page only") {
+			t.Errorf("%s should link the index and note the search scope", p.FileName)
+		}
+	}
+	if !strings.Contains(middle, "Page 2 of 3") {
+		t.Error("page 2 should show its position")
+	}
+	if !strings.Contains(first, "Question A") || strings.Contains(first, "Question C") {
+		t.Error("page 1 should hold only its own messages")
+	}
+	if !strings.Contains(last, "Question E") {
+		t.Error("page 3 should hold the last message")
+	}
+}
+
+func TestRenderConversationPages_Index(t *testing.T) {
+	pages, err := RenderConversationPages(pagedSession(3), nil, nil, ExportOptions{PageSize: 2, Deterministic: true})
+	if err != nil {
+		t.Fatalf("RenderConversationPages() error = %v", err)
+	}
+	index := pages[0].HTML
+	for _, want := range []string{
+		`<a href="page-1.html">Page 1</a>`,
+		`<a href="page-2.html">Page 2</a>`,
+		"(2 messages)",
+		"(1 message)",
+		`<span class="page-preview">Question A</span>`,
+		`<span class="page-preview">Question C</span>`,
+		`class="page-range"`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index missing %q", want)
+		}
+	}
+	if strings.Contains(index, "Answer B") {
+		t.Error("index should not render the conversation")
+	}
+}
+
+func TestRenderConversationPage_ToolResultOnNextPage(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "call", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:00Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls"}}]}`)},
+		{UUID: "text", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:01Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Listing"}]}`)},
+		{UUID: "result", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:02Z",
+			Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"file-from-next-page.txt"}]}`)},
+	}
+	pages := SplitPages(entries, 1)
+	if len(pages) != 2 || pages[1].Start != 1 || pages[1].End != 3 {
+		t.Fatalf("SplitPages() = %+v, want the result on page 2", pages)
+	}
+
+	first, err := RenderConversationPage(entries, pages[0], nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationPage() error = %v", err)
+	}
+	if !strings.Contains(first, "file-from-next-page.txt") {
+		t.Error("tool call on page 1 should show its result written on page 2")
+	}
+
+	second, err := RenderConversationPage(entries, pages[1], nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationPage() error = %v", err)
+	}
+	if strings.Contains(second, "file-from-next-page.txt") {
+		t.Error("result shown with its call should not repeat as an orphan on page 2")
+	}
+}
+
+func TestRenderConversationPage_OverflowOnLastPage(t *testing.T) {
+	entries, agents := manyAgentsSession()
+	opts := ExportOptions{PageSize: 1, MaxAgents: 2}
+
+	pages := SplitPages(entries, opts.PageSize)
+	if len(pages) < 2 {
+		t.Fatalf("SplitPages() = %+v, want several pages", pages)
+	}
+	for _, page := range pages {
+		html, err := RenderConversationPage(entries, page, agents, nil, opts)
+		if err != nil {
+			t.Fatalf("RenderConversationPage(%d) error = %v", page.Number, err)
+		}
+		listed := strings.Contains(html, "agent-overflow")
+		if last := page.Number == page.Total; listed != last {
+			t.Errorf("page %d lists overflow agents = %v, want %v", page.Number, listed, last)
+		}
+	}
+}
+
+func TestRenderConversationPage_InvalidRange(t *testing.T) {
+	_, err := RenderConversationPage(pagedSession(2), PageInfo{Number: 1, Total: 1, Start: 0, End: 5}, nil, nil, ExportOptions{})
+	if err == nil || !strings.Contains(err.Error(), "outside the 2 entries") {
+		t.Errorf("expected range error, got %v", err)
+	}
+}
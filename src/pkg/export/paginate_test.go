@@ -0,0 +1,102 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// paginateTestEntries returns n alternating user/assistant text entries.
+func paginateTestEntries(n int) []models.ConversationEntry {
+	entries := make([]models.ConversationEntry, 0, n)
+	for i := 0; i < n; i++ {
+		entry := models.ConversationEntry{
+			UUID:      fmt.Sprintf("e%d", i),
+			Timestamp: "2026-02-01T10:00:00Z",
+		}
+		if i%2 == 0 {
+			entry.Type = models.EntryTypeUser
+			entry.Message = json.RawMessage(fmt.Sprintf(`"Question %d"`, i))
+		} else {
+			entry.Type = models.EntryTypeAssistant
+			entry.Message = json.RawMessage(fmt.Sprintf(`{"role":"assistant","content":[{"type":"text","text":"Answer %d"}]}`, i))
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRenderConversationWithOptions_PaginateOffByDefault(t *testing.T) {
+	html, err := RenderConversationWithOptions(paginateTestEntries(50), nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if strings.Contains(html, `class="page-break"`) {
+		t.Error("page breaks should not be emitted by default")
+	}
+	if strings.Contains(html, `conversation paginated`) {
+		t.Error("paginated class should not be set by default")
+	}
+}
+
+func TestRenderConversationWithOptions_PaginateEveryN(t *testing.T) {
+	html, err := RenderConversationWithOptions(paginateTestEntries(10), nil, nil, ExportOptions{Paginate: true, PageBreakEvery: 4})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if !strings.Contains(html, `<div class="conversation paginated">`) {
+		t.Error("expected paginated conversation container")
+	}
+	// 10 messages at 4 per page -> breaks before messages 5 and 9
+	if got := strings.Count(html, `class="page-break"`); got != 2 {
+		t.Errorf("page break count = %d, want 2", got)
+	}
+	// First break precedes the fifth message
+	if strings.Index(html, `class="page-break"`) > strings.Index(html, `data-uuid="e4"`) {
+		t.Error("first page break should appear before the fifth message")
+	}
+	if strings.Index(html, `class="page-break"`) < strings.Index(html, `data-uuid="e3"`) {
+		t.Error("first page break should appear after the fourth message")
+	}
+}
+
+func TestRenderConversationWithOptions_PaginateDefaultSize(t *testing.T) {
+	html, err := RenderConversationWithOptions(paginateTestEntries(defaultPageBreakEvery+1), nil, nil, ExportOptions{Paginate: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if got := strings.Count(html, `class="page-break"`); got != 1 {
+		t.Errorf("page break count = %d, want 1", got)
+	}
+}
+
+func TestRenderConversationWithOptions_PaginateAtSubagent(t *testing.T) {
+	entries := paginateTestEntries(2)
+	entries = append(entries, models.ConversationEntry{
+		UUID:    "q1",
+		Type:    models.EntryTypeQueueOperation,
+		AgentID: "agent-abc",
+	})
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{Paginate: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	breakIdx := strings.Index(html, `class="page-break"`)
+	placeholderIdx := strings.Index(html, `data-agent-id="agent-abc"`)
+	if breakIdx == -1 || placeholderIdx == -1 || breakIdx > placeholderIdx {
+		t.Error("expected a page break before the subagent section")
+	}
+}
+
+func TestPrintCSS_PaginationRules(t *testing.T) {
+	css := GetStyleCSS()
+	for _, want := range []string{".page-break {", "break-before: page", ".paginated .tool-call"} {
+		if !strings.Contains(css, want) {
+			t.Errorf("style.css should contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// parallelToolEntry returns an assistant entry making the given tool_use blocks at once.
+func parallelToolEntry(blocks ...string) models.ConversationEntry {
+	return models.ConversationEntry{
+		UUID:      "a1",
+		Type:      models.EntryTypeAssistant,
+		Timestamp: "2026-02-01T10:00:00Z",
+		Message:   json.RawMessage(`{"role":"assistant","content":[` + strings.Join(blocks, ",") + `]}`),
+	}
+}
+
+func toolUseBlock(id, command string) string {
+	return `{"type":"tool_use","id":"` + id + `","name":"Bash","input":{"command":"` + command + `"}}`
+}
+
+func TestRenderEntryContent_GroupParallelTools(t *testing.T) {
+	entry := parallelToolEntry(toolUseBlock("t1", "ls"), toolUseBlock("t2", "pwd"), toolUseBlock("t3", "date"))
+	results := map[string]models.ToolResult{
+		"t1": {ToolUseID: "t1", Content: "out-of-ls"},
+		"t3": {ToolUseID: "t3", Content: "out-of-date", IsError: true},
+	}
+	ro := entryRenderOptions{opts: ExportOptions{GroupParallelTools: true}}
+
+	html := renderEntryContent(entry, results, "", ro)
+
+	if strings.Count(html, `<div class="parallel-tools" data-tool-count="3">`) != 1 || !strings.Contains(html, "Parallel tools (3)") {
+		t.Fatalf("expected one group of 3 tools, got:\n%s", html)
+	}
+	// Every call stays its own collapsible block inside the group, in order
+	start := strings.Index(html, `class="parallel-tools"`)
+	end := strings.LastIndex(html, "</div>")
+	group := html[start:end]
+	last := -1
+	for _, id := range []string{"t1", "t2", "t3"} {
+		pos := strings.Index(group, `<div class="tool-call collapsible collapsed" id="tool-`+id+`"`)
+		if pos < 0 || pos < last {
+			t.Errorf("tool %s missing from the group or out of order", id)
+		}
+		last = pos
+	}
+	// Each call keeps its own result pairing
+	for _, want := range []string{"out-of-ls", "out-of-date"} {
+		if !strings.Contains(group, want) {
+			t.Errorf("group missing result %q", want)
+		}
+	}
+	if strings.Count(group, "no result") != 1 {
+		t.Error("only t2 should be marked as having no result")
+	}
+	if strings.Count(html, "<div") != strings.Count(html, "</div>") {
+		t.Errorf("unbalanced divs: %d open, %d close", strings.Count(html, "<div"), strings.Count(html, "</div>"))
+	}
+}
+
+func TestRenderEntryContent_GroupParallelToolsSingleCallUnchanged(t *testing.T) {
+	entry := parallelToolEntry(toolUseBlock("t1", "ls"))
+	results := map[string]models.ToolResult{"t1": {ToolUseID: "t1", Content: "out"}}
+
+	grouped := renderEntryContent(entry, results, "", entryRenderOptions{opts: ExportOptions{GroupParallelTools: true}})
+	plain := renderEntryContent(entry, results, "", entryRenderOptions{})
+	if grouped != plain {
+		t.Errorf("single-tool entry should render unchanged:\ngot  %s\nwant %s", grouped, plain)
+	}
+	if strings.Contains(grouped, "parallel-tools") {
+		t.Error("single-tool entry should not be grouped")
+	}
+}
+
+func TestRenderEntryContent_ParallelToolsOffByDefault(t *testing.T) {
+	entry := parallelToolEntry(toolUseBlock("t1", "ls"), toolUseBlock("t2", "pwd"))
+	html := renderEntryContent(entry, nil, "", entryRenderOptions{})
+	if strings.Contains(html, "parallel-tools") {
+		t.Error("tools should not be grouped unless GroupParallelTools is set")
+	}
+	if strings.Count(html, `class="tool-call collapsible collapsed"`) != 2 {
+		t.Error("both tool calls should render")
+	}
+}
+
+func TestRenderConversation_GroupParallelToolsWithText(t *testing.T) {
+	entry := parallelToolEntry(`{"type":"text","text":"Checking two things."}`, toolUseBlock("t1", "ls"), toolUseBlock("t2", "pwd"))
+	html, err := RenderConversationWithOptions([]models.ConversationEntry{entry}, nil, nil, ExportOptions{GroupParallelTools: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := strings.Index(html, "Checking two things.")
+	group := strings.Index(html, `class="parallel-tools"`)
+	if text < 0 || group < text {
+		t.Error("the group should follow the message text")
+	}
+}
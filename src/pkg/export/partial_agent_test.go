@@ -0,0 +1,82 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/agent"
+)
+
+func TestRenderSubagentPlaceholder_PartiallyLoaded(t *testing.T) {
+	agentMap := map[string]int{"abc1234": 2}
+
+	html := renderSubagentPlaceholderWith("abc1234", agentMap, "s1", "", subagentDetails{parseErrors: 3}, entryRenderOptions{})
+	for _, want := range []string{
+		`(2 entries)</span> <span class="subagent-partial"`,
+		`partially loaded (3 parse errors)</span>`,
+		`onclick="loadAgent(this)"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("partially loaded agent missing %q:\n%s", want, html)
+		}
+	}
+	if html := renderSubagentPlaceholderWith("abc1234", agentMap, "s1", "", subagentDetails{parseErrors: 1}, entryRenderOptions{}); !strings.Contains(html, "(1 parse error)") {
+		t.Errorf("a single parse error should be singular:\n%s", html)
+	}
+	if html := renderSubagentPlaceholder("abc1234", agentMap, "s1", ""); strings.Contains(html, "subagent-partial") {
+		t.Errorf("a fully loaded agent should not be marked:\n%s", html)
+	}
+}
+
+func TestComputeSessionStats_AgentParseErrors(t *testing.T) {
+	agents := []*agent.TreeNode{
+		{AgentID: "ok", EntryCount: 4},
+		{AgentID: "broken", EntryCount: 2, ParseErrors: 1, Children: []*agent.TreeNode{
+			{AgentID: "nested", EntryCount: 1, ParseErrors: 5},
+		}},
+	}
+	stats := ComputeSessionStats(nil, agents)
+	if len(stats.AgentParseErrors) != 2 || stats.AgentParseErrors["broken"] != 1 || stats.AgentParseErrors["nested"] != 5 {
+		t.Errorf("AgentParseErrors = %v, want broken:1 nested:5", stats.AgentParseErrors)
+	}
+	if stats.TotalAgentMessages != 7 {
+		t.Errorf("TotalAgentMessages = %d, want the 7 parsed entries", stats.TotalAgentMessages)
+	}
+	if stats := ComputeSessionStats(nil, agents[:1]); stats.AgentParseErrors != nil {
+		t.Errorf("AgentParseErrors = %v, want nil when every file parsed", stats.AgentParseErrors)
+	}
+}
+
+func TestExportSession_CorruptAgentFile(t *testing.T) {
+	tempDir := t.TempDir()
+	projectDir, sessionID := setupTestSession(t, tempDir)
+	agentContent := `{"type":"user","timestamp":"2026-02-01T10:02:00Z","sessionId":"12345678-1234-1234-1234-123456789abc","uuid":"agent-entry-1"}
+{"type":"assistant","timestamp":"2026-02-01T10:03:00Z","sessionId":"1234
+`
+	agentFile := filepath.Join(projectDir, sessionID, "subagents", "agent-a1b2c3d4.jsonl")
+	if err := os.WriteFile(agentFile, []byte(agentContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExportSession("/test/project", sessionID, ExportOptions{OutputDir: filepath.Join(tempDir, "export"), ClaudeDir: tempDir})
+	if err != nil {
+		t.Fatalf("ExportSession() error = %v, want the main session exported", err)
+	}
+	if _, ok := result.AgentFiles["a1b2c3d4"]; !ok {
+		t.Error("ExportSession() should still copy the corrupt agent file")
+	}
+	want := "agent a1b2c3d4 partially loaded: 1 line(s) could not be parsed"
+	if len(result.Errors) != 1 || result.Errors[0] != want {
+		t.Errorf("ExportSession() Errors = %q, want [%q]", result.Errors, want)
+	}
+
+	tree, err := GetExportTreeInfo(projectDir, sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Children) != 1 || tree.Children[0].EntryCount != 1 || tree.Children[0].ParseErrors != 1 {
+		t.Errorf("agent tree = %+v, want the agent with 1 entry and 1 parse error", tree.Children)
+	}
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// planToolName is the tool Claude Code calls to leave plan mode; its "plan" input is the
+// plan it proposes, in markdown.
+const planToolName = "ExitPlanMode"
+
+// planText returns the plan of an ExitPlanMode call, or "" if it has none.
+func planText(input map[string]any) string {
+	plan, _ := input["plan"].(string)
+	return plan
+}
+
+// planTitle returns the first non-blank line of a plan, without markdown heading marks,
+// as the summary of an ExitPlanMode call.
+func planTitle(plan string) string {
+	for _, line := range strings.Split(plan, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+		if line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// planStatus describes the user's answer to an ExitPlanMode call: the call's result
+// approves the plan unless it is an error, which is how a rejection is recorded.
+func planStatus(result models.ToolResult, hasResult bool) (status, class string) {
+	switch {
+	case !hasResult:
+		return "awaiting approval", "pending"
+	case result.IsError:
+		return "rejected", "rejected"
+	default:
+		return "approved", "approved"
+	}
+}
+
+// renderPlanToolCall renders an ExitPlanMode call as a "Plan" card: the plan from its
+// "plan" input rendered as markdown (so its text is escaped; file paths are linked
+// against projectPath), with the approval status in the header and the result, such as
+// the user's feedback on a rejected plan, below the card. The header and result links
+// follow ro as for renderToolCallWith.
+func renderPlanToolCall(tool models.ToolUse, result models.ToolResult, hasResult bool, projectPath string, ro entryRenderOptions) string {
+	var sb strings.Builder
+
+	status, statusClass := planStatus(result, hasResult)
+	badge := fmt.Sprintf(`<span class="plan-status %s">%s</span>`, statusClass, status)
+	sb.WriteString(renderToolCallHeader(tool, hasResult, badge, ro))
+
+	sb.WriteString(renderPlanCard(planText(tool.Input), projectPath))
+
+	if hasResult {
+		outputClass := "tool-output"
+		if result.IsError {
+			outputClass = "tool-output error"
+		}
+		sb.WriteString(fmt.Sprintf(`    <div class="tool-connector">%s</div>`, renderToolPairLink(tool.ID, false, ro.opts.NoJS)))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf(`    <pre class="%s"%s>%s</pre>`, outputClass, toolResultAttrs(result), escapeHTML(result.Content)))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(renderToolCallClose(ro.opts.NoJS))
+
+	return sb.String()
+}
+
+// renderPlanCard renders the "Plan" card of an ExitPlanMode call: plan as markdown, with
+// file paths linked against projectPath.
+func renderPlanCard(plan, projectPath string) string {
+	var sb strings.Builder
+	sb.WriteString(`    <section class="plan-card">`)
+	sb.WriteString("\n")
+	sb.WriteString(`    <div class="plan-card-title">Plan</div>`)
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf(`    <div class="plan-content markdown-content">%s</div>`, RenderMarkdown(plan, projectPath)))
+	sb.WriteString("\n")
+	sb.WriteString("    </section>\n")
+	return sb.String()
+}
@@ -0,0 +1,75 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRenderToolCall_ExitPlanMode(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_plan", Name: "ExitPlanMode", Input: map[string]any{
+		"plan": "## Fix login\n\n1. Check **tokens**\n2. Add <script>alert(1)</script> test",
+	}}
+	result := models.ToolResult{ToolUseID: "toolu_plan", Content: "User has approved your plan."}
+
+	html := renderToolCall(tool, result, true)
+
+	for _, want := range []string{
+		`<section class="plan-card">`,
+		`<div class="plan-card-title">Plan</div>`,
+		`<div class="plan-content markdown-content">`,
+		`<strong>tokens</strong>`,
+		`&lt;script&gt;`,
+		`<span class="plan-status approved">approved</span>`,
+		`[ExitPlanMode] Fix login`,
+		`User has approved your plan.`,
+		`href="#tool-result-toolu_plan"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("plan call missing %q:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("plan text was not escaped")
+	}
+	if strings.Contains(html, `"plan"`) || strings.Contains(html, "tool-input") {
+		t.Error("plan call should not show the raw tool input")
+	}
+}
+
+func TestRenderToolCall_ExitPlanModeStatus(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_plan", Name: "ExitPlanMode", Input: map[string]any{"plan": "Do it"}}
+
+	rejected := renderToolCall(tool, models.ToolResult{ToolUseID: "toolu_plan", Content: "Not yet, add tests first", IsError: true}, true)
+	if !strings.Contains(rejected, `<span class="plan-status rejected">rejected</span>`) || !strings.Contains(rejected, `<pre class="tool-output error"`) {
+		t.Errorf("rejected plan should show its status and feedback:\n%s", rejected)
+	}
+
+	pending := renderToolCall(tool, models.ToolResult{}, false)
+	if !strings.Contains(pending, `<span class="plan-status pending">awaiting approval</span>`) || strings.Contains(pending, "tool-connector") {
+		t.Errorf("plan without a result should await approval:\n%s", pending)
+	}
+
+	// Without a plan input, the call renders like any other tool
+	plain := renderToolCall(models.ToolUse{ID: "toolu_x", Name: "ExitPlanMode", Input: map[string]any{}}, models.ToolResult{}, false)
+	if strings.Contains(plain, "plan-card") {
+		t.Errorf("call without a plan should not render a plan card:\n%s", plain)
+	}
+}
+
+func TestPlanTitle(t *testing.T) {
+	tests := []struct {
+		plan string
+		want string
+	}{
+		{"## Fix login\n\nSteps", "Fix login"},
+		{"\n\n  First line  \nSecond", "First line"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := planTitle(tt.plan); got != tt.want {
+			t.Errorf("planTitle(%q) = %q, want %q", tt.plan, got, tt.want)
+		}
+	}
+}
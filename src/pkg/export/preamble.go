@@ -0,0 +1,99 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// sessionPreamble returns the context entries a session starts with: the system entries
+// and meta user entries (see models.ConversationEntry.IsMeta) before the first message
+// the user typed or the assistant wrote. Summaries and other bookkeeping entries among
+// them are passed over, and entries without text are left out.
+func sessionPreamble(entries []models.ConversationEntry) []models.ConversationEntry {
+	var preamble []models.ConversationEntry
+	for _, entry := range entries {
+		switch {
+		case entry.Type == models.EntryTypeAssistant, entry.Type == models.EntryTypeUser && !entry.IsMeta:
+			return preamble
+		case entry.Type != models.EntryTypeSystem && entry.Type != models.EntryTypeUser:
+			continue
+		}
+		if strings.TrimSpace(preambleText(entry)) != "" {
+			preamble = append(preamble, entry)
+		}
+	}
+	return preamble
+}
+
+// preambleText returns the text of a preamble entry: its message text, or for system
+// entries without one, their content.
+func preambleText(entry models.ConversationEntry) string {
+	if text := entry.GetTextContent(); text != "" {
+		return text
+	}
+	if len(entry.Content) == 0 {
+		return ""
+	}
+	var text string
+	if json.Unmarshal(entry.Content, &text) == nil {
+		return text
+	}
+	return string(entry.Content)
+}
+
+// renderFirstPromptCard renders the first prompt of the session in full as a card in
+// the page header (see ExportOptions.ShowFirstPrompt), or "" for a blank prompt. The
+// card repeats the first user message rather than being one, so it is not a .message
+// and neither search nor the message counts see it twice.
+func renderFirstPromptCard(prompt string) string {
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return ""
+	}
+	return `    <section class="first-prompt-card" aria-label="First prompt">` + "\n" +
+		`        <div class="first-prompt-label">First prompt</div>` + "\n" +
+		`        <div class="first-prompt-text">` + escapeHTML(prompt) + "</div>\n" +
+		"    </section>\n"
+}
+
+// preambleUUIDs returns the set of UUIDs of the preamble entries, which the conversation
+// skips when they are shown in the header.
+func preambleUUIDs(preamble []models.ConversationEntry) map[string]bool {
+	uuids := make(map[string]bool, len(preamble))
+	for _, entry := range preamble {
+		if entry.UUID != "" {
+			uuids[entry.UUID] = true
+		}
+	}
+	return uuids
+}
+
+// renderSessionPreamble renders the collapsed "Session context" panel of the page header
+// (see ExportOptions.IncludePreamble), or "" if the session has no preamble.
+func renderSessionPreamble(preamble []models.ConversationEntry) string {
+	if len(preamble) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`    <details class="session-preamble">` + "\n")
+	count := fmt.Sprintf("%d entries", len(preamble))
+	if len(preamble) == 1 {
+		count = "1 entry"
+	}
+	sb.WriteString(fmt.Sprintf(`        <summary>Session context <span class="preamble-count">(%s)</span></summary>`+"\n", count))
+	for _, entry := range preamble {
+		label := getRoleLabel(entry.Type, sessionUserLabel, sessionAssistantLabel)
+		if entry.Subtype != "" {
+			label += " · " + entry.Subtype
+		}
+		sb.WriteString(fmt.Sprintf(`        <div class="preamble-entry %s" data-uuid="%s">`, getEntryClass(entry.Type), escapeHTML(entry.UUID)))
+		sb.WriteString(fmt.Sprintf(`<div class="preamble-label">%s</div>`, escapeHTML(label)))
+		sb.WriteString(fmt.Sprintf(`<pre class="preamble-text">%s</pre></div>`+"\n", escapeHTML(strings.TrimSpace(preambleText(entry)))))
+	}
+	sb.WriteString("    </details>\n")
+	return sb.String()
+}
@@ -0,0 +1,139 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// preambleEntries returns a session that starts with a system entry and a meta user
+// entry before the first real exchange.
+func preambleEntries() []models.ConversationEntry {
+	return []models.ConversationEntry{
+		{UUID: "sys-1", Type: models.EntryTypeSystem, Subtype: "init", Timestamp: "2026-01-01T10:00:00Z",
+			Content: json.RawMessage(`"You are a helpful <assistant>."`)},
+		{UUID: "summary-1", Type: models.EntryTypeSummary},
+		{UUID: "meta-1", Type: models.EntryTypeUser, IsMeta: true, Timestamp: "2026-01-01T10:00:01Z",
+			Message: json.RawMessage(`{"role":"user","content":"Caveat: the messages below were generated by local commands."}`)},
+		{UUID: "user-1", Type: models.EntryTypeUser, Timestamp: "2026-01-01T10:00:02Z",
+			Message: json.RawMessage(`{"role":"user","content":"Fix the build"}`)},
+		{UUID: "sys-2", Type: models.EntryTypeSystem, Timestamp: "2026-01-01T10:00:03Z", // after the preamble
+			Content: json.RawMessage(`"Later system note"`)},
+		{UUID: "asst-1", Type: models.EntryTypeAssistant, Timestamp: "2026-01-01T10:00:04Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Done"}]}`)},
+	}
+}
+
+func TestSessionPreamble(t *testing.T) {
+	preamble := sessionPreamble(preambleEntries())
+
+	var uuids []string
+	for _, entry := range preamble {
+		uuids = append(uuids, entry.UUID)
+	}
+	// Stops at the first real user message; the summary is passed over
+	if got := strings.Join(uuids, ","); got != "sys-1,meta-1" {
+		t.Errorf("sessionPreamble() = %s, want sys-1,meta-1", got)
+	}
+
+	if got := preambleText(preamble[0]); got != "You are a helpful <assistant>." {
+		t.Errorf("preambleText(system) = %q", got)
+	}
+
+	// A session that opens with the user has no preamble
+	if got := sessionPreamble(preambleEntries()[3:]); len(got) != 0 {
+		t.Errorf("sessionPreamble() = %d entries, want none", len(got))
+	}
+}
+
+func TestRenderConversation_IncludePreamble(t *testing.T) {
+	entries := preambleEntries()
+
+	// Off by default
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, "session-preamble") {
+		t.Error("preamble panel should only be rendered with IncludePreamble")
+	}
+
+	html, err = RenderConversationWithOptions(entries, nil, nil, ExportOptions{IncludePreamble: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<details class="session-preamble">`,
+		`Session context <span class="preamble-count">(2 entries)</span>`,
+		`<pre class="preamble-text">You are a helpful &lt;assistant&gt;.</pre>`,
+		`System · init`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html missing %q", want)
+		}
+	}
+	if strings.Contains(html, `<details class="session-preamble" open`) {
+		t.Error("preamble panel should start collapsed")
+	}
+
+	// The preamble entries are shown once, in the header, and the rest of the
+	// conversation is unchanged
+	if strings.Count(html, "Caveat: the messages below") != 1 {
+		t.Error("meta entry should only appear in the preamble panel")
+	}
+	for _, want := range []string{"Fix the build", "Done"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("conversation missing %q", want)
+		}
+	}
+}
+
+func TestRenderConversation_IncludePreambleNoJS(t *testing.T) {
+	html, err := RenderConversationWithOptions(preambleEntries(), nil, nil, ExportOptions{IncludePreamble: true, NoJS: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, `<details class="session-preamble">`) {
+		t.Error("preamble panel should not need scripts")
+	}
+}
+
+func TestRenderConversationWithOptions_ShowFirstPrompt(t *testing.T) {
+	prompt := strings.Repeat("Refactor the <parser> module. ", 20)
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: []byte(`{"role":"user","content":"` + prompt + `"}`)},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:05Z", Message: []byte(`[{"type":"text","text":"On it."}]`)},
+	}
+
+	without, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(without, `class="first-prompt-card"`) {
+		t.Error("the first prompt card should be opt-in")
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{ShowFirstPrompt: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cardIdx := strings.Index(html, `<section class="first-prompt-card"`)
+	if cardIdx < 0 || cardIdx > strings.Index(html, `<div class="conversation">`) {
+		t.Fatal("the first prompt card should render in the header")
+	}
+	// The full prompt, escaped and not truncated
+	if !strings.Contains(html, escapeHTML(strings.TrimSpace(prompt))) {
+		t.Error("the card should show the full prompt")
+	}
+	if got := strings.Count(html, `class="message-row user`); got != 1 {
+		t.Errorf("the card should not add a user message, got %d", got)
+	}
+}
+
+func TestRenderFirstPromptCard_Blank(t *testing.T) {
+	if got := renderFirstPromptCard("  \n "); got != "" {
+		t.Errorf("renderFirstPromptCard(blank) = %q, want empty", got)
+	}
+}
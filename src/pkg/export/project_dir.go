@@ -0,0 +1,64 @@
+package export
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/paths"
+)
+
+// ProjectDir returns the Claude project directory that ExportSession and ExportAgent read
+// from. opts.EncodedProjectDir, when set, names the directory exactly and wins over
+// projectPath; otherwise projectPath is encoded with encoding.EncodePath. Since that
+// encoding is lossy, the derived name does not always match the directory Claude created,
+// so if the directory does not exist the error lists the project directories that do.
+func ProjectDir(projectPath string, opts ExportOptions) (string, error) {
+	projectsDir, err := paths.ProjectsDir(opts.ClaudeDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+
+	var projectDir string
+	if name := opts.EncodedProjectDir; name != "" {
+		if name != filepath.Base(name) || name == "." || name == ".." {
+			return "", fmt.Errorf("invalid encoded project directory %q: must be a directory name in %s", name, projectsDir)
+		}
+		projectDir = filepath.Join(projectsDir, name)
+	} else {
+		projectDir, err = paths.ProjectDir(opts.ClaudeDir, projectPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve project directory: %w", err)
+		}
+	}
+
+	if paths.Exists(projectDir) {
+		return projectDir, nil
+	}
+
+	missing := fmt.Sprintf("project not found: no directory %s in %s", filepath.Base(projectDir), projectsDir)
+	if opts.EncodedProjectDir == "" {
+		missing = fmt.Sprintf("project not found: %s (encoded as %s)", projectPath, filepath.Base(projectDir))
+	}
+	existing, err := existingProjectDirs(opts.ClaudeDir)
+	if err != nil || len(existing) == 0 {
+		return "", fmt.Errorf("%s; no project directories exist in %s", missing, projectsDir)
+	}
+	return "", fmt.Errorf("%s; existing project directories: %s", missing, strings.Join(existing, ", "))
+}
+
+// existingProjectDirs returns the sorted names of the encoded project directories in
+// claudeDir.
+func existingProjectDirs(claudeDir string) ([]string, error) {
+	projects, err := paths.ListProjects(claudeDir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(projects))
+	for name := range projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,85 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjectDir(t *testing.T) {
+	claudeDir := t.TempDir()
+	for _, name := range []string{"-test-project", "-Users-me-my-app"} {
+		if err := os.MkdirAll(filepath.Join(claudeDir, "projects", name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := ProjectDir("/test/project", ExportOptions{ClaudeDir: claudeDir})
+	if err != nil || got != filepath.Join(claudeDir, "projects", "-test-project") {
+		t.Errorf("ProjectDir(derived) = %q, %v", got, err)
+	}
+
+	// The explicit directory wins over the project path
+	got, err = ProjectDir("/test/project", ExportOptions{ClaudeDir: claudeDir, EncodedProjectDir: "-Users-me-my-app"})
+	if err != nil || got != filepath.Join(claudeDir, "projects", "-Users-me-my-app") {
+		t.Errorf("ProjectDir(explicit) = %q, %v", got, err)
+	}
+}
+
+func TestProjectDir_NotFoundListsExisting(t *testing.T) {
+	claudeDir := t.TempDir()
+	for _, name := range []string{"-b-project", "-a-project"} {
+		if err := os.MkdirAll(filepath.Join(claudeDir, "projects", name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, opts := range map[string]ExportOptions{
+		"derived":  {ClaudeDir: claudeDir},
+		"explicit": {ClaudeDir: claudeDir, EncodedProjectDir: "-other-project"},
+	} {
+		_, err := ProjectDir("/other/project", opts)
+		if err == nil {
+			t.Errorf("%s: ProjectDir() should fail for a missing directory", name)
+			continue
+		}
+		if msg := err.Error(); !strings.Contains(msg, "project not found") || !strings.Contains(msg, "existing project directories: -a-project, -b-project") {
+			t.Errorf("%s: error should list the existing directories, got: %v", name, err)
+		}
+	}
+}
+
+func TestProjectDir_NoProjects(t *testing.T) {
+	_, err := ProjectDir("/test/project", ExportOptions{ClaudeDir: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "no project directories exist") {
+		t.Errorf("ProjectDir() error = %v, want no project directories", err)
+	}
+}
+
+func TestProjectDir_InvalidEncodedDir(t *testing.T) {
+	for _, name := range []string{"..", "a/b", "../-test-project"} {
+		_, err := ProjectDir("", ExportOptions{ClaudeDir: t.TempDir(), EncodedProjectDir: name})
+		if err == nil || !strings.Contains(err.Error(), "invalid encoded project directory") {
+			t.Errorf("ProjectDir(%q) error = %v, want invalid name", name, err)
+		}
+	}
+}
+
+func TestExportSession_EncodedProjectDir(t *testing.T) {
+	tempDir := t.TempDir()
+	_, sessionID := setupTestSession(t, tempDir)
+
+	// /elsewhere does not encode to the directory the session is in
+	result, err := ExportSession("/elsewhere", sessionID, ExportOptions{
+		OutputDir:         filepath.Join(tempDir, "out"),
+		ClaudeDir:         tempDir,
+		EncodedProjectDir: "-test-project",
+	})
+	if err != nil {
+		t.Fatalf("ExportSession() error = %v", err)
+	}
+	if result.SessionID != sessionID {
+		t.Errorf("ExportSession() SessionID = %q, want %q", result.SessionID, sessionID)
+	}
+}
@@ -0,0 +1,60 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRenderRawLink(t *testing.T) {
+	entry := models.ConversationEntry{UUID: "u1", SourceLine: 42}
+
+	html := renderRawLink(entry, entryRenderOptions{opts: ExportOptions{RawSource: "source/session.jsonl"}})
+	for _, want := range []string{
+		`class="raw-link"`,
+		`href="source/session.jsonl#L42"`,
+		`data-raw-line="42"`,
+		`title="Line 42 of source/session.jsonl"`,
+		">View raw</a>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing %q in %s", want, html)
+		}
+	}
+
+	if got := renderRawLink(entry, entryRenderOptions{}); got != "" {
+		t.Errorf("no link expected without RawSource, got %q", got)
+	}
+	if got := renderRawLink(models.ConversationEntry{UUID: "u1"}, entryRenderOptions{opts: ExportOptions{RawSource: "s.jsonl"}}); got != "" {
+		t.Errorf("no link expected for an entry with an unknown line, got %q", got)
+	}
+}
+
+func TestRenderConversationWithOptions_RawLinks(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", SourceLine: 3,
+			Message: json.RawMessage(`{"role":"user","content":"hello"}`)},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:01Z", SourceLine: 7,
+			Message: json.RawMessage(`{"role":"assistant","content":"hi"}`)},
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{RawSource: "source/session.jsonl"})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	for _, want := range []string{`href="source/session.jsonl#L3"`, `href="source/session.jsonl#L7"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing raw link %q", want)
+		}
+	}
+
+	html, err = RenderConversation(entries, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, "raw-link") {
+		t.Error("raw links should only be rendered when RawSource is set")
+	}
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// readImageExtensions are the file extensions of images the Read tool returns as image
+// content instead of text.
+var readImageExtensions = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".gif":  true,
+	".webp": true,
+	".bmp":  true,
+}
+
+// maxReadImageBytes is the largest image file shown as a thumbnail; larger ones, like
+// missing ones, are only noted.
+const maxReadImageBytes = 10 << 20
+
+// readImagePath returns the image file a Read call read, or "" if the call did not read
+// a file with a recognized image extension.
+func readImagePath(tool models.ToolUse) string {
+	if tool.Name != "Read" {
+		return ""
+	}
+	path := extractFilePath(tool.Name, tool.Input)
+	if !readImageExtensions[strings.ToLower(filepath.Ext(path))] {
+		return ""
+	}
+	return path
+}
+
+// renderReadImage renders the output of a Read call that read an image: a thumbnail
+// linked to the file, or a "[binary image]" note when the file is not an absolute path
+// that exists on this machine or is larger than maxReadImageBytes.
+func renderReadImage(path string, result models.ToolResult) string {
+	name := escapeHTML(filepath.Base(path))
+	fileURL := escapeHTML(buildFileURL(path))
+
+	var content string
+	if info, err := os.Stat(path); err == nil && filepath.IsAbs(path) && info.Mode().IsRegular() && info.Size() <= maxReadImageBytes {
+		content = fmt.Sprintf(`<a href="%s" class="tool-image-link" title="%s"><img class="tool-image-thumb" src="%s" alt="%s" loading="lazy"></a>`,
+			fileURL, escapeHTML(path), fileURL, name)
+	} else {
+		content = fmt.Sprintf(`<span class="tool-image-missing">[binary image] <a href="%s" class="file-link" title="%s">%s</a></span>`,
+			fileURL, escapeHTML(path), name)
+	}
+	return fmt.Sprintf(`    <div class="tool-output tool-image"%s>%s</div>`, toolResultAttrs(result), content)
+}
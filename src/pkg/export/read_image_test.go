@@ -0,0 +1,87 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestReadImagePath(t *testing.T) {
+	tests := []struct {
+		tool models.ToolUse
+		want string
+	}{
+		{models.ToolUse{Name: "Read", Input: map[string]any{"file_path": "/tmp/shot.PNG"}}, "/tmp/shot.PNG"},
+		{models.ToolUse{Name: "Read", Input: map[string]any{"file_path": "/tmp/photo.jpeg"}}, "/tmp/photo.jpeg"},
+		{models.ToolUse{Name: "Read", Input: map[string]any{"file_path": "/tmp/main.go"}}, ""},
+		{models.ToolUse{Name: "Read", Input: map[string]any{"file_path": "/tmp/logo.svg"}}, ""},
+		{models.ToolUse{Name: "Write", Input: map[string]any{"file_path": "/tmp/shot.png"}}, ""},
+	}
+	for _, tt := range tests {
+		if got := readImagePath(tt.tool); got != tt.want {
+			t.Errorf("readImagePath(%s %v) = %q, want %q", tt.tool.Name, tt.tool.Input, got, tt.want)
+		}
+	}
+}
+
+func TestRenderToolCall_ReadImage(t *testing.T) {
+	dir := t.TempDir()
+	image := filepath.Join(dir, "shot.png")
+	if err := os.WriteFile(image, []byte("\x89PNG\r\n\x1a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := models.ToolUse{ID: "toolu_img", Name: "Read", Input: map[string]any{"file_path": image}}
+	result := models.ToolResult{ToolUseID: "toolu_img", Content: "\x89PNG garbage"}
+	html := renderToolCall(tool, result, true)
+
+	fileURL := buildFileURL(image)
+	for _, want := range []string{
+		`<div class="tool-output tool-image" id="tool-result-toolu_img"`,
+		`<a href="` + fileURL + `" class="tool-image-link"`,
+		`<img class="tool-image-thumb" src="` + fileURL + `" alt="shot.png" loading="lazy">`,
+		`class="file-path-btn"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html missing %q\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "garbage") {
+		t.Error("binary output should not be shown")
+	}
+
+	// Missing files degrade to a note, still linked
+	tool.Input = map[string]any{"file_path": filepath.Join(dir, "gone.png")}
+	html = renderToolCall(tool, result, true)
+	if !strings.Contains(html, `<span class="tool-image-missing">[binary image] <a href="`) || strings.Contains(html, "<img") {
+		t.Errorf("missing image should render a note, got:\n%s", html)
+	}
+
+	// Errors keep the usual output
+	result.IsError = true
+	result.Content = "File does not exist."
+	html = renderToolCall(tool, result, true)
+	if strings.Contains(html, "tool-image") || !strings.Contains(html, "File does not exist.") {
+		t.Errorf("error result should render as text, got:\n%s", html)
+	}
+}
+
+func TestRenderReadImage_TooLarge(t *testing.T) {
+	image := filepath.Join(t.TempDir(), "huge.png")
+	f, err := os.Create(image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(maxReadImageBytes + 1); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	html := renderReadImage(image, models.ToolResult{ToolUseID: "toolu_big"})
+	if !strings.Contains(html, "[binary image]") || strings.Contains(html, "<img") {
+		t.Errorf("oversized image should render a note, got:\n%s", html)
+	}
+}
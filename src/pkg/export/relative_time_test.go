@@ -0,0 +1,134 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestFormatTimestampRelative(t *testing.T) {
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		ts   string
+		want string
+	}{
+		{"seconds", "2026-02-01T11:59:30Z", "just now"},
+		{"future", "2026-02-01T12:05:00Z", "just now"},
+		{"one minute", "2026-02-01T11:59:00Z", "1 minute ago"},
+		{"minutes", "2026-02-01T11:55:00Z", "5 minutes ago"},
+		{"one hour", "2026-02-01T11:00:00Z", "1 hour ago"},
+		{"hours", "2026-02-01T09:00:00Z", "3 hours ago"},
+		{"days", "2026-01-29T12:00:00Z", "3 days ago"},
+		{"months", "2025-11-01T12:00:00Z", "3 months ago"},
+		{"years", "2024-01-01T12:00:00Z", "2 years ago"},
+		{"invalid", "not-a-time", "not-a-time"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatTimestampRelative(tt.ts, now); got != tt.want {
+				t.Errorf("formatTimestampRelative(%q) = %q, want %q", tt.ts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReferenceTime_Deterministic(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{Timestamp: "2026-02-01T10:00:00Z"},
+		{Timestamp: "2026-02-01T11:00:00Z"},
+		{Timestamp: ""},
+	}
+
+	got := referenceTime(entries, ExportOptions{Deterministic: true})
+	want := time.Date(2026, 2, 1, 11, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("referenceTime() = %v, want last entry timestamp %v", got, want)
+	}
+
+	before := time.Now()
+	if got := referenceTime(entries, ExportOptions{}); got.Before(before) {
+		t.Errorf("non-deterministic referenceTime() = %v, want current time", got)
+	}
+}
+
+func TestRenderConversationWithOptions_RelativeTimes(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{
+			UUID:      "u1",
+			Type:      models.EntryTypeUser,
+			Timestamp: "2026-02-01T10:00:00Z",
+			Message:   json.RawMessage(`"First"`),
+		},
+		{
+			UUID:      "a1",
+			Type:      models.EntryTypeAssistant,
+			Timestamp: "2026-02-01T10:05:00Z",
+			Message:   json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Second"}]}`),
+		},
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{RelativeTimes: true, Deterministic: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+
+	if !strings.Contains(html, `<span class="timestamp" title="2026-02-01 10:00:00 UTC">5 minutes ago</span>`) {
+		t.Error("expected relative time with absolute title for first entry")
+	}
+	if !strings.Contains(html, `<span class="timestamp" title="2026-02-01 10:05:00 UTC">just now</span>`) {
+		t.Error("expected 'just now' for last entry in deterministic mode")
+	}
+}
+
+func TestRenderConversationWithOptions_DefaultUnchanged(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{
+			UUID:      "u1",
+			Type:      models.EntryTypeUser,
+			Timestamp: "2026-02-01T10:00:00Z",
+			Message:   json.RawMessage(`"Hello"`),
+		},
+	}
+	stats := &SessionStats{SessionID: "s1"}
+
+	withOpts, err := RenderConversationWithOptions(entries, nil, stats, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	withStats, err := RenderConversationWithStats(entries, nil, stats)
+	if err != nil {
+		t.Fatalf("RenderConversationWithStats() error = %v", err)
+	}
+	if withOpts != withStats {
+		t.Error("zero ExportOptions should match RenderConversationWithStats output")
+	}
+	if !strings.Contains(withOpts, `<span class="timestamp">10:00 AM</span>`) {
+		t.Error("default rendering should show absolute time without title")
+	}
+}
+
+func TestRenderAgentFragmentWithOptions_RelativeTimes(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{
+			UUID:      "a1",
+			Type:      models.EntryTypeAssistant,
+			Timestamp: "2026-02-01T10:05:00Z",
+			Message:   json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Agent"}]}`),
+		},
+	}
+
+	html, err := RenderAgentFragmentWithOptions("agent-1", entries, ExportOptions{RelativeTimes: true, Deterministic: true})
+	if err != nil {
+		t.Fatalf("RenderAgentFragmentWithOptions() error = %v", err)
+	}
+	if !strings.Contains(html, `title="2026-02-01 10:05:00 UTC">just now</span>`) {
+		t.Errorf("fragment should use relative times, got:\n%s", html)
+	}
+}
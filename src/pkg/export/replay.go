@@ -0,0 +1,39 @@
+package export
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultReplayDelay is the pause between messages revealed by replay when
+// ExportOptions.ReplayDelay is 0.
+const DefaultReplayDelay = 1500 * time.Millisecond
+
+// replayHiddenClass marks the message rows that replay reveals. style.css hides them
+// until controls.js reveals them.
+const replayHiddenClass = "replay-hidden"
+
+// replayDelay returns the pause between messages revealed by replay.
+func replayDelay(opts ExportOptions) time.Duration {
+	if opts.ReplayDelay <= 0 {
+		return DefaultReplayDelay
+	}
+	return opts.ReplayDelay
+}
+
+// renderReplayControls renders the header buttons that replay the conversation and skip
+// to the end of it, or nothing unless opts.ReplayMode is set. Without JavaScript the
+// noscript style shows every message, since nothing could reveal them.
+func renderReplayControls(opts ExportOptions) string {
+	if !opts.ReplayMode {
+		return ""
+	}
+	return fmt.Sprintf(`        <div class="controls-group replay-controls">
+            <button id="replay-play-btn" type="button" data-replay-delay="%d" aria-pressed="false" title="Reveal the messages one at a time">Play</button>
+            <button id="replay-all-btn" type="button" title="Skip the replay and show every message">Show All</button>
+            <span class="replay-progress" aria-live="polite"></span>
+            <noscript><style>.%s { display: revert; }</style></noscript>
+        </div>
+        <div class="controls-separator" aria-hidden="true"></div>
+`, replayDelay(opts).Milliseconds(), replayHiddenClass)
+}
@@ -0,0 +1,107 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestReplayDelay(t *testing.T) {
+	if got := replayDelay(ExportOptions{}); got != DefaultReplayDelay {
+		t.Errorf("replayDelay() = %v, want DefaultReplayDelay", got)
+	}
+	if got := replayDelay(ExportOptions{ReplayDelay: 3 * time.Second}); got != 3*time.Second {
+		t.Errorf("replayDelay() = %v, want 3s", got)
+	}
+}
+
+func TestRenderReplayControls(t *testing.T) {
+	if got := renderReplayControls(ExportOptions{}); got != "" {
+		t.Errorf("renderReplayControls() = %q, want nothing without ReplayMode", got)
+	}
+
+	got := renderReplayControls(ExportOptions{ReplayMode: true, ReplayDelay: 2 * time.Second})
+	for _, want := range []string{
+		`id="replay-play-btn"`,
+		`data-replay-delay="2000"`,
+		`id="replay-all-btn"`,
+		`class="replay-progress" aria-live="polite"`,
+		`<noscript><style>.replay-hidden { display: revert; }</style></noscript>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("replay controls missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRenderConversation_ReplayMode(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: []byte(`"Hello"`)},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:05Z", Message: []byte(`{"role":"assistant","content":[{"type":"text","text":"Hi"}]}`)},
+	}
+
+	plain, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if strings.Contains(plain, "replay-hidden") || strings.Contains(plain, `id="replay-play-btn"`) {
+		t.Error("replay should be off by default")
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{ReplayMode: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	for _, want := range []string{
+		`<div class="message-row user replay-hidden" data-uuid="u1">`,
+		`<div class="message-row assistant replay-hidden" data-uuid="a1">`,
+		`id="replay-play-btn" type="button" data-replay-delay="1500"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("replay export missing %q", want)
+		}
+	}
+}
+
+func TestRenderConversationPage_ReplayMode(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: []byte(`"first"`)},
+		{UUID: "u2", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:01:00Z", Message: []byte(`"second"`)},
+	}
+	pages := SplitPages(entries, 1)
+
+	html, err := RenderConversationPage(entries, pages[1], nil, nil, ExportOptions{ReplayMode: true, PageSize: 1})
+	if err != nil {
+		t.Fatalf("RenderConversationPage() error = %v", err)
+	}
+	if !strings.Contains(html, `id="replay-play-btn"`) || !strings.Contains(html, `data-uuid="u2"`) || !strings.Contains(html, "replay-hidden") {
+		t.Error("each page should replay its own messages")
+	}
+}
+
+func TestGetControlsJS_Replay(t *testing.T) {
+	js := GetControlsJS()
+	for _, want := range []string{
+		"function initReplay()",
+		"function playReplay()",
+		"function finishReplay()",
+		"(prefers-reduced-motion: reduce)",
+		"getElementById('replay-all-btn')",
+		"finishReplay();\n\n        var regex = buildSearchRegex",
+	} {
+		if !strings.Contains(js, want) {
+			t.Errorf("controls.js missing %q", want)
+		}
+	}
+}
+
+func TestCSSContent_Replay(t *testing.T) {
+	css := GetStyleCSS()
+	for _, want := range []string{".replay-hidden {", ".replay-revealed {", "@media (prefers-reduced-motion: reduce)"} {
+		if !strings.Contains(css, want) {
+			t.Errorf("style.css missing %q", want)
+		}
+	}
+}
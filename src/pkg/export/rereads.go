@@ -0,0 +1,84 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// repeatedRead places a Read call among the reads of the same file in a conversation.
+type repeatedRead struct {
+	path  string
+	n     int      // 1 for the first read of the file, 2 for the first re-read, ...
+	reads []string // IDs of every Read call of the file, in session order
+}
+
+// repeatedReads maps the IDs of the Read calls of files read more than once to their
+// place among the reads of their file.
+type repeatedReads map[string]*repeatedRead
+
+// buildRepeatedReads finds the files entries read more than once, matching the Read
+// calls' file_path exactly; reads of different files are never grouped. Returns nil
+// unless opts.CollapseRepeats is set, or if no file is read twice.
+func buildRepeatedReads(entries []models.ConversationEntry, opts ExportOptions) repeatedReads {
+	if !opts.CollapseRepeats {
+		return nil
+	}
+
+	var paths []string
+	readsOf := make(map[string][]string)
+	for i := range entries {
+		if entries[i].Type != models.EntryTypeAssistant {
+			continue
+		}
+		for _, tool := range entries[i].ExtractToolCalls() {
+			path, _ := tool.Input["file_path"].(string)
+			if tool.Name != "Read" || path == "" {
+				continue
+			}
+			if readsOf[path] == nil {
+				paths = append(paths, path)
+			}
+			readsOf[path] = append(readsOf[path], tool.ID)
+		}
+	}
+
+	var reads repeatedReads
+	for _, path := range paths {
+		ids := readsOf[path]
+		if len(ids) < 2 {
+			continue
+		}
+		if reads == nil {
+			reads = make(repeatedReads)
+		}
+		for i, id := range ids {
+			reads[id] = &repeatedRead{path: path, n: i + 1, reads: ids}
+		}
+	}
+	return reads
+}
+
+// renderRereadLine renders the line under the first read of a file linking to its
+// re-reads.
+func renderRereadLine(r *repeatedRead) string {
+	noun := "times"
+	if len(r.reads) == 2 {
+		noun = "time"
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<div class="reread-note">Read again %d %s:`, len(r.reads)-1, noun))
+	for i, id := range r.reads[1:] {
+		sb.WriteString(fmt.Sprintf(` <a class="reread-link" href="#tool-%s">#%d</a>`, escapeHTML(id), i+2))
+	}
+	sb.WriteString("</div>\n")
+	return sb.String()
+}
+
+// renderReread wraps the HTML of a re-read of a file in a collapsed "re-read (Nx)"
+// group linking back to the first read, so only the first read shows in full.
+func renderReread(r *repeatedRead, callHTML string) string {
+	return fmt.Sprintf(`<details class="reread" data-file-path="%s"><summary class="reread-summary">re-read (%dx) <code>%s</code> · <a class="reread-first-link" href="#tool-%s">first read</a></summary>`+"\n%s</details>\n",
+		escapeHTML(r.path), r.n, escapeHTML(r.path), escapeHTML(r.reads[0]), callHTML)
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// rereadEntries read main.go three times and util.go once.
+func rereadEntries() []models.ConversationEntry {
+	read := func(uuid, id, path string) models.ConversationEntry {
+		return models.ConversationEntry{UUID: uuid, Type: models.EntryTypeAssistant, Message: json.RawMessage(
+			`{"role":"assistant","content":[{"type":"tool_use","id":"` + id + `","name":"Read","input":{"file_path":"` + path + `"}}]}`)}
+	}
+	return []models.ConversationEntry{
+		read("a1", "t-main1", "/src/main.go"),
+		read("a2", "t-util", "/src/util.go"),
+		read("a3", "t-main2", "/src/main.go"),
+		read("a4", "t-main3", "/src/main.go"),
+	}
+}
+
+func TestBuildRepeatedReads(t *testing.T) {
+	entries := rereadEntries()
+
+	if reads := buildRepeatedReads(entries, ExportOptions{}); reads != nil {
+		t.Errorf("reads should not be grouped without CollapseRepeats, got %v", reads)
+	}
+
+	reads := buildRepeatedReads(entries, ExportOptions{CollapseRepeats: true})
+	if len(reads) != 3 {
+		t.Fatalf("buildRepeatedReads() = %v, want the three reads of main.go", reads)
+	}
+	if reads["t-util"] != nil {
+		t.Error("a file read once should not be grouped")
+	}
+	for i, id := range []string{"t-main1", "t-main2", "t-main3"} {
+		if r := reads[id]; r.n != i+1 || r.path != "/src/main.go" || len(r.reads) != 3 {
+			t.Errorf("%s = %+v, want read %d of main.go", id, r, i+1)
+		}
+	}
+}
+
+func TestRenderConversationWithOptions_CollapseRepeats(t *testing.T) {
+	html, err := RenderConversationWithOptions(rereadEntries(), nil, nil, ExportOptions{CollapseRepeats: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`<div class="reread-note">Read again 2 times: <a class="reread-link" href="#tool-t-main2">#2</a> <a class="reread-link" href="#tool-t-main3">#3</a></div>`,
+		`<summary class="reread-summary">re-read (2x) <code>/src/main.go</code> · <a class="reread-first-link" href="#tool-t-main1">first read</a></summary>`,
+		`re-read (3x)`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+	if got := strings.Count(html, `<details class="reread"`); got != 2 {
+		t.Errorf("page has %d re-read groups, want 2", got)
+	}
+	if strings.Contains(html, `data-file-path="/src/util.go"`) {
+		t.Error("util.go was read once and should not be grouped")
+	}
+
+	plain, err := RenderConversationWithOptions(rereadEntries(), nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(plain, "reread") {
+		t.Error("re-reads should not be collapsed by default")
+	}
+}
+
+func TestRenderAgentFragmentWithOptions_CollapseRepeatsPerConversation(t *testing.T) {
+	// A subagent's first read of a file the main session read is not a re-read
+	entries := rereadEntries()[:1]
+	fragment, err := RenderAgentFragmentWithOptions("agent1", entries, ExportOptions{CollapseRepeats: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(fragment, "reread") {
+		t.Errorf("a single read in a conversation should not be grouped:\n%s", fragment)
+	}
+}
@@ -0,0 +1,25 @@
+package export
+
+// DefaultMarkdownResultTools lists the tools whose results are rendered as markdown when
+// ExportOptions.RenderResultMarkdown is set and ExportOptions.MarkdownResultTools is empty.
+var DefaultMarkdownResultTools = []string{"WebFetch", "WebSearch", "Task"}
+
+// rendersResultMarkdown reports whether results of the named tool are rendered as
+// markdown: opts.RenderResultMarkdown must be set and the tool must be in
+// opts.MarkdownResultTools (DefaultMarkdownResultTools when empty). Bash output always
+// stays literal.
+func rendersResultMarkdown(name string, opts ExportOptions) bool {
+	if !opts.RenderResultMarkdown || name == "Bash" {
+		return false
+	}
+	tools := opts.MarkdownResultTools
+	if len(tools) == 0 {
+		tools = DefaultMarkdownResultTools
+	}
+	for _, tool := range tools {
+		if tool == name {
+			return true
+		}
+	}
+	return false
+}
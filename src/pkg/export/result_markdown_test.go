@@ -0,0 +1,140 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRendersResultMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		opts ExportOptions
+		want bool
+	}{
+		{"off by default", "WebFetch", ExportOptions{}, false},
+		{"default tool", "WebFetch", ExportOptions{RenderResultMarkdown: true}, true},
+		{"not in defaults", "Read", ExportOptions{RenderResultMarkdown: true}, false},
+		{"custom list", "Read", ExportOptions{RenderResultMarkdown: true, MarkdownResultTools: []string{"Read"}}, true},
+		{"custom list replaces defaults", "WebFetch", ExportOptions{RenderResultMarkdown: true, MarkdownResultTools: []string{"Read"}}, false},
+		{"bash never", "Bash", ExportOptions{RenderResultMarkdown: true, MarkdownResultTools: []string{"Bash"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rendersResultMarkdown(tt.tool, tt.opts); got != tt.want {
+				t.Errorf("rendersResultMarkdown(%q) = %v, want %v", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
+
+// toolResultEntries returns an assistant entry calling the named tool with input, and a
+// user entry carrying its result.
+func toolResultEntries(name, input, result string, isError bool) []models.ConversationEntry {
+	resultJSON, _ := json.Marshal(result)
+	errJSON := "false"
+	if isError {
+		errJSON = "true"
+	}
+	return []models.ConversationEntry{
+		{
+			UUID:      "a1",
+			Type:      models.EntryTypeAssistant,
+			Timestamp: "2026-02-01T10:00:00Z",
+			Message:   json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"` + name + `","input":` + input + `}]}`),
+		},
+		{
+			UUID:      "u1",
+			Type:      models.EntryTypeUser,
+			Timestamp: "2026-02-01T10:00:01Z",
+			Message:   json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","is_error":` + errJSON + `,"content":` + string(resultJSON) + `}]}`),
+		},
+	}
+}
+
+func TestRenderConversation_MarkdownResult(t *testing.T) {
+	entries := toolResultEntries("WebFetch", `{"url":"https://example.com"}`, "# Title\n\nSome **bold** text", false)
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{RenderResultMarkdown: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, `<div class="tool-output markdown-content markdown-result" id="tool-result-t1"`) {
+		t.Errorf("WebFetch result should render as markdown:\n%s", html)
+	}
+	if !strings.Contains(html, "<strong>bold</strong>") {
+		t.Error("markdown result should render bold text")
+	}
+	if strings.Contains(html, `<pre class="tool-output"`) {
+		t.Error("markdown result should not also render as preformatted text")
+	}
+}
+
+func TestRenderConversation_MarkdownResultOffByDefault(t *testing.T) {
+	entries := toolResultEntries("WebFetch", `{"url":"https://example.com"}`, "Some **bold** text", false)
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, "markdown-result") || strings.Contains(html, "<strong>bold</strong>") {
+		t.Error("results should stay literal unless RenderResultMarkdown is set")
+	}
+	if !strings.Contains(html, `<pre class="tool-output" id="tool-result-t1"`) {
+		t.Errorf("result should render preformatted:\n%s", html)
+	}
+}
+
+func TestRenderConversation_MarkdownResultSkipsBashAndErrors(t *testing.T) {
+	opts := ExportOptions{RenderResultMarkdown: true, MarkdownResultTools: []string{"Bash", "WebFetch"}}
+
+	bash := toolResultEntries("Bash", `{"command":"cat README.md"}`, "# Not a heading", false)
+	html, err := RenderConversationWithOptions(bash, nil, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, "markdown-result") || !strings.Contains(html, "# Not a heading") {
+		t.Error("Bash output should stay literal")
+	}
+
+	failed := toolResultEntries("WebFetch", `{"url":"https://example.com"}`, "**404** not found", true)
+	html, err = RenderConversationWithOptions(failed, nil, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, "markdown-result") || !strings.Contains(html, `<pre class="tool-output error"`) {
+		t.Error("error results should stay literal")
+	}
+}
+
+func TestRenderConversation_MarkdownResultEscaped(t *testing.T) {
+	entries := toolResultEntries("WebFetch", `{"url":"https://example.com"}`,
+		"<script>alert(1)</script>\n\n[x](javascript:alert(1)) <img src=x onerror=alert(1)>", false)
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{RenderResultMarkdown: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, "<script>alert(1)</script>") || strings.Contains(html, "<img src=x") {
+		t.Error("markdown result must escape raw HTML")
+	}
+	if strings.Contains(html, `href="javascript:`) {
+		t.Error("markdown result must not link javascript: URLs")
+	}
+}
+
+func TestRenderConversation_MarkdownResultTruncated(t *testing.T) {
+	entries := toolResultEntries("WebFetch", `{"url":"https://example.com"}`, strings.Repeat("word ", 100), false)
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{RenderResultMarkdown: true, MaxToolOutputBytes: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, "markdown-result") || !strings.Contains(html, "tool-output-truncated") {
+		t.Error("long markdown result should still be truncated with a notice")
+	}
+}
@@ -0,0 +1,153 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/randlee/claude-history/internal/jsonl"
+)
+
+// RecordExportedFiles fills in the export path, size, and line count of every source
+// file copied by an export, so a later resume can verify the copies are complete.
+// Agent files that were copied but are missing from the manifest are appended.
+func (m *Manifest) RecordExportedFiles(result *ExportResult) error {
+	recorded := make(map[string]bool, len(result.AgentFiles))
+	for i := range m.SourceFiles {
+		file := &m.SourceFiles[i]
+		var exported string
+		switch file.Type {
+		case "session":
+			exported = result.MainSessionFile
+		case "agent":
+			exported = result.AgentFiles[file.AgentID]
+			recorded[file.AgentID] = true
+		}
+		if exported == "" {
+			continue
+		}
+		if err := recordExportedFile(file, result.OutputDir, exported); err != nil {
+			return err
+		}
+	}
+
+	for agentID, exported := range result.AgentFiles {
+		if recorded[agentID] {
+			continue
+		}
+		file := SourceFile{Type: "agent", AgentID: agentID}
+		if err := recordExportedFile(&file, result.OutputDir, exported); err != nil {
+			return err
+		}
+		m.SourceFiles = append(m.SourceFiles, file)
+	}
+	return nil
+}
+
+// recordExportedFile stores the output-relative path, size, and line count of a copied file.
+func recordExportedFile(file *SourceFile, outputDir, exported string) error {
+	rel, err := filepath.Rel(outputDir, exported)
+	if err != nil {
+		return fmt.Errorf("failed to resolve export path for %s: %w", exported, err)
+	}
+	size, lines, err := exportedFileStats(exported)
+	if err != nil {
+		return err
+	}
+	file.ExportPath = filepath.ToSlash(rel)
+	file.Size = size
+	file.Lines = lines
+	return nil
+}
+
+// exportedFileStats returns the size in bytes and valid JSONL line count of a file.
+func exportedFileStats(path string) (int64, int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to stat %s: %w", filepath.Base(path), err)
+	}
+	lines, err := jsonl.CountLines(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count lines in %s: %w", filepath.Base(path), err)
+	}
+	return info.Size(), lines, nil
+}
+
+// VerifyExportedFiles checks that every source file recorded in the manifest exists in
+// outputDir with the recorded size and line count. A mismatch indicates an interrupted
+// or corrupted copy.
+func VerifyExportedFiles(outputDir string, m *Manifest) error {
+	hasSession := false
+	for _, file := range m.SourceFiles {
+		if file.ExportPath == "" {
+			return fmt.Errorf("manifest does not record exported files")
+		}
+		if file.Type == "session" {
+			hasSession = true
+		}
+
+		size, lines, err := exportedFileStats(filepath.Join(outputDir, filepath.FromSlash(file.ExportPath)))
+		if err != nil {
+			return err
+		}
+		if size != file.Size {
+			return fmt.Errorf("%s: size is %d bytes, manifest records %d", file.ExportPath, size, file.Size)
+		}
+		if lines != file.Lines {
+			return fmt.Errorf("%s: has %d lines, manifest records %d", file.ExportPath, lines, file.Lines)
+		}
+	}
+	if !hasSession {
+		return fmt.Errorf("manifest does not record the session file")
+	}
+	return nil
+}
+
+// resumeExport rebuilds an ExportResult from a previous export in outputDir after
+// verifying its copied source files against the manifest.
+func resumeExport(outputDir, sessionID string) (*ExportResult, error) {
+	m, err := ReadManifest(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("no valid manifest: %w", err)
+	}
+	if m.SessionID != sessionID {
+		return nil, fmt.Errorf("manifest is for session %s", m.SessionID)
+	}
+	if err := VerifyExportedFiles(outputDir, m); err != nil {
+		return nil, err
+	}
+
+	result := &ExportResult{
+		OutputDir:  outputDir,
+		SessionID:  sessionID,
+		SourceDir:  filepath.Join(outputDir, "source"),
+		AgentFiles: make(map[string]string),
+		Resumed:    true,
+	}
+	for _, file := range m.SourceFiles {
+		exported := filepath.Join(outputDir, filepath.FromSlash(file.ExportPath))
+		switch file.Type {
+		case "session":
+			result.MainSessionFile = exported
+		case "agent":
+			result.AgentFiles[file.AgentID] = exported
+			result.TotalAgents++
+		}
+	}
+	return result, nil
+}
+
+// writeSourceManifest writes manifest.json recording the files copied into the export.
+func writeSourceManifest(projectDir string, result *ExportResult) error {
+	m, err := GenerateManifest(projectDir, result.SessionID, result.OutputDir)
+	if err != nil {
+		return fmt.Errorf("failed to generate manifest: %w", err)
+	}
+	if err := m.RecordExportedFiles(result); err != nil {
+		return fmt.Errorf("failed to record exported files: %w", err)
+	}
+	if err := WriteManifest(m, result.OutputDir); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// exportForResume runs an initial export that can later be resumed.
+func exportForResume(t *testing.T) (string, string, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	_, sessionID := setupTestSession(t, tempDir)
+	outputDir := filepath.Join(tempDir, "export-output")
+
+	result, err := ExportSession("/test/project", sessionID, ExportOptions{OutputDir: outputDir, ClaudeDir: tempDir})
+	if err != nil {
+		t.Fatalf("ExportSession() error = %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("ExportSession() errors = %v", result.Errors)
+	}
+	return tempDir, sessionID, outputDir
+}
+
+func TestExportSession_WritesVerifiableManifest(t *testing.T) {
+	_, sessionID, outputDir := exportForResume(t)
+
+	m, err := ReadManifest(outputDir)
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	if m.SessionID != sessionID {
+		t.Errorf("manifest SessionID = %q, want %q", m.SessionID, sessionID)
+	}
+
+	var session, agentFile *SourceFile
+	for i := range m.SourceFiles {
+		switch m.SourceFiles[i].Type {
+		case "session":
+			session = &m.SourceFiles[i]
+		case "agent":
+			agentFile = &m.SourceFiles[i]
+		}
+	}
+	if session == nil || session.ExportPath != "source/session.jsonl" || session.Lines != 2 || session.Size == 0 {
+		t.Errorf("session source file not recorded correctly: %+v", session)
+	}
+	if agentFile == nil || agentFile.AgentID != "a1b2c3d4" || agentFile.ExportPath != "source/agents/agent-a1b2c3d4.jsonl" || agentFile.Lines != 2 {
+		t.Errorf("agent source file not recorded correctly: %+v", agentFile)
+	}
+
+	if err := VerifyExportedFiles(outputDir, m); err != nil {
+		t.Errorf("VerifyExportedFiles() on a complete export = %v", err)
+	}
+}
+
+func TestExportSession_Resume(t *testing.T) {
+	tempDir, sessionID, outputDir := exportForResume(t)
+
+	// Changes to the original session must not be picked up when resuming
+	original := filepath.Join(tempDir, "projects", "-test-project", sessionID+".jsonl")
+	if err := os.WriteFile(original, []byte(`{"type":"user","uuid":"changed"}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExportSession("/test/project", sessionID, ExportOptions{OutputDir: outputDir, ClaudeDir: tempDir, Resume: true})
+	if err != nil {
+		t.Fatalf("ExportSession(Resume) error = %v", err)
+	}
+	if !result.Resumed {
+		t.Fatalf("expected export to resume, errors: %v", result.Errors)
+	}
+	if result.MainSessionFile != filepath.Join(outputDir, "source", "session.jsonl") {
+		t.Errorf("MainSessionFile = %q", result.MainSessionFile)
+	}
+	if result.TotalAgents != 1 || result.AgentFiles["a1b2c3d4"] == "" {
+		t.Errorf("agent files not restored: %+v", result.AgentFiles)
+	}
+
+	content, err := os.ReadFile(result.MainSessionFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "changed") {
+		t.Error("resume should not re-copy source files")
+	}
+}
+
+func TestExportSession_ResumeDetectsPartialCopy(t *testing.T) {
+	tests := []struct {
+		name     string
+		truncate func(content []byte) []byte
+		wantErr  string
+	}{
+		{
+			name:     "truncated mid-line",
+			truncate: func(content []byte) []byte { return content[:len(content)-10] },
+			wantErr:  "size is",
+		},
+		{
+			name:     "missing last line",
+			truncate: func(content []byte) []byte { return content[:strings.Index(string(content), "\n")+1] },
+			wantErr:  "size is",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir, sessionID, outputDir := exportForResume(t)
+
+			copied := filepath.Join(outputDir, "source", "agents", "agent-a1b2c3d4.jsonl")
+			content, err := os.ReadFile(copied)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(copied, tt.truncate(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			m, err := ReadManifest(outputDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyExportedFiles(outputDir, m); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("VerifyExportedFiles() = %v, want error containing %q", err, tt.wantErr)
+			}
+
+			// Resume falls back to a fresh copy instead of using bad data
+			result, err := ExportSession("/test/project", sessionID, ExportOptions{OutputDir: outputDir, ClaudeDir: tempDir, Resume: true})
+			if err != nil {
+				t.Fatalf("ExportSession(Resume) error = %v", err)
+			}
+			if result.Resumed {
+				t.Error("resume should not proceed on a partial copy")
+			}
+			if len(result.Errors) == 0 || !strings.Contains(result.Errors[0], "cannot resume") {
+				t.Errorf("expected a resume warning, got %v", result.Errors)
+			}
+			restored, err := os.ReadFile(copied)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(restored) != string(content) {
+				t.Error("partial copy should be replaced by a fresh copy")
+			}
+		})
+	}
+}
+
+func TestVerifyExportedFiles_LineCountMismatch(t *testing.T) {
+	_, _, outputDir := exportForResume(t)
+
+	// Same size, different line count: replace a newline with a space
+	copied := filepath.Join(outputDir, "source", "session.jsonl")
+	content, err := os.ReadFile(copied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined := strings.Replace(string(content), "}\n{", "} {", 1)
+	if err := os.WriteFile(copied, []byte(joined), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ReadManifest(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyExportedFiles(outputDir, m); err == nil || !strings.Contains(err.Error(), "lines") {
+		t.Errorf("VerifyExportedFiles() = %v, want line count mismatch", err)
+	}
+}
+
+func TestVerifyExportedFiles_MissingRecords(t *testing.T) {
+	outputDir := t.TempDir()
+
+	if err := VerifyExportedFiles(outputDir, &Manifest{SourceFiles: []SourceFile{{Type: "session", Path: "/orig.jsonl"}}}); err == nil {
+		t.Error("manifest without export paths should not verify")
+	}
+	if err := VerifyExportedFiles(outputDir, &Manifest{}); err == nil {
+		t.Error("manifest without a session file should not verify")
+	}
+}
+
+func TestExportSession_ResumeWithoutManifest(t *testing.T) {
+	tempDir := t.TempDir()
+	_, sessionID := setupTestSession(t, tempDir)
+	outputDir := filepath.Join(tempDir, "export-output")
+
+	result, err := ExportSession("/test/project", sessionID, ExportOptions{OutputDir: outputDir, ClaudeDir: tempDir, Resume: true})
+	if err != nil {
+		t.Fatalf("ExportSession(Resume) error = %v", err)
+	}
+	if result.Resumed {
+		t.Error("nothing to resume without a manifest")
+	}
+	if _, err := os.Stat(result.MainSessionFile); err != nil {
+		t.Errorf("session file should be copied: %v", err)
+	}
+}
+
+func TestResumeExport_WrongSession(t *testing.T) {
+	_, _, outputDir := exportForResume(t)
+
+	if _, err := resumeExport(outputDir, "99999999-9999-9999-9999-999999999999"); err == nil || !strings.Contains(err.Error(), "manifest is for session") {
+		t.Errorf("resumeExport() = %v, want session mismatch", err)
+	}
+}
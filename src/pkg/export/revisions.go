@@ -0,0 +1,177 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// maxDiffCells caps the size of the table diffTokens fills; longer texts are shown as
+// a whole deletion and insertion instead of a word-level diff.
+const maxDiffCells = 1 << 20
+
+// revisionMarker is shown in the header of an edited message.
+const revisionMarker = `<span class="revision-marker" title="This message was edited">edited</span>`
+
+// renderRevisions renders the edit history of a message below its content: a collapsed
+// list of its earlier versions, each shown as a diff against the version that replaced
+// it. Returns "" for messages that were never edited.
+func renderRevisions(entry models.ConversationEntry) string {
+	if !entry.HasRevisions() {
+		return ""
+	}
+
+	noun := "versions"
+	if len(entry.Revisions) == 1 {
+		noun = "version"
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`    <details class="revisions"><summary class="revisions-summary">Edit history (%d earlier %s)</summary>`+"\n",
+		len(entry.Revisions), noun))
+	for i, revision := range entry.Revisions {
+		next := entry.GetTextContent()
+		if i+1 < len(entry.Revisions) {
+			next = entry.Revisions[i+1].GetTextContent()
+		}
+		sb.WriteString(`<div class="revision"><div class="revision-header">`)
+		sb.WriteString(fmt.Sprintf("Version %d", i+1))
+		if revision.Timestamp != "" {
+			sb.WriteString(" · " + escapeHTML(formatTimestampReadable(revision.Timestamp)))
+		}
+		sb.WriteString(`</div>`)
+		sb.WriteString(fmt.Sprintf(`<div class="revision-diff">%s</div></div>`+"\n", renderTextDiff(revision.GetTextContent(), next)))
+	}
+	sb.WriteString("</details>\n")
+	return sb.String()
+}
+
+// renderTextDiff renders the changes from before to after word by word: removed words
+// in <del class="diff-del">, added words in <ins class="diff-ins">, the rest as plain
+// escaped text.
+func renderTextDiff(before, after string) string {
+	var sb strings.Builder
+	for _, op := range diffTokens(splitWords(before), splitWords(after)) {
+		text := escapeHTML(strings.Join(op.tokens, ""))
+		switch op.kind {
+		case diffDelete:
+			sb.WriteString(`<del class="diff-del">` + text + `</del>`)
+		case diffInsert:
+			sb.WriteString(`<ins class="diff-ins">` + text + `</ins>`)
+		default:
+			sb.WriteString(text)
+		}
+	}
+	return sb.String()
+}
+
+type diffKind int
+
+const (
+	diffEqual diffKind = iota
+	diffDelete
+	diffInsert
+)
+
+// diffOp is a run of tokens kept, deleted or inserted.
+type diffOp struct {
+	kind   diffKind
+	tokens []string
+}
+
+// diffTokens returns the operations turning a into b, from their longest common
+// subsequence, with consecutive operations of the same kind merged. Inputs too large
+// for maxDiffCells diff as a deletion of a followed by an insertion of b.
+func diffTokens(a, b []string) []diffOp {
+	var ops []diffOp
+	add := func(kind diffKind, token string) {
+		if n := len(ops); n > 0 && ops[n-1].kind == kind {
+			ops[n-1].tokens = append(ops[n-1].tokens, token)
+			return
+		}
+		ops = append(ops, diffOp{kind: kind, tokens: []string{token}})
+	}
+
+	// Common prefix and suffix need no table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	for _, token := range a[:prefix] {
+		add(diffEqual, token)
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	if (len(midA)+1)*(len(midB)+1) > maxDiffCells {
+		for _, token := range midA {
+			add(diffDelete, token)
+		}
+		for _, token := range midB {
+			add(diffInsert, token)
+		}
+	} else {
+		// lcs[i][j] is the length of the longest common subsequence of midA[i:] and midB[j:]
+		lcs := make([][]int, len(midA)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(midB)+1)
+		}
+		for i := len(midA) - 1; i >= 0; i-- {
+			for j := len(midB) - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else {
+					lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(midA) && j < len(midB) {
+			switch {
+			case midA[i] == midB[j]:
+				add(diffEqual, midA[i])
+				i++
+				j++
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				add(diffDelete, midA[i])
+				i++
+			default:
+				add(diffInsert, midB[j])
+				j++
+			}
+		}
+		for ; i < len(midA); i++ {
+			add(diffDelete, midA[i])
+		}
+		for ; j < len(midB); j++ {
+			add(diffInsert, midB[j])
+		}
+	}
+
+	for _, token := range a[len(a)-suffix:] {
+		add(diffEqual, token)
+	}
+	return ops
+}
+
+// splitWords splits s into alternating runs of whitespace and non-whitespace, so the
+// tokens joined give back s.
+func splitWords(s string) []string {
+	var tokens []string
+	start, space := 0, false
+	for i, r := range s {
+		if i > start && unicode.IsSpace(r) != space {
+			tokens = append(tokens, s[start:i])
+			start = i
+		}
+		space = unicode.IsSpace(r)
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
@@ -0,0 +1,85 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRenderTextDiff(t *testing.T) {
+	tests := []struct {
+		name, before, after, want string
+	}{
+		{"unchanged", "same text", "same text", "same text"},
+		{"word replaced", "Fix the test", "Fix the tests", `Fix the <del class="diff-del">test</del><ins class="diff-ins">tests</ins>`},
+		{"words added", "Fix it", "Fix it now please", `Fix it<ins class="diff-ins"> now please</ins>`},
+		{"words removed", "a b c", "a c", `a <del class="diff-del">b </del>c`},
+		{"from empty", "", "new", `<ins class="diff-ins">new</ins>`},
+		{"escaped", "<b>", "<i>", `<del class="diff-del">&lt;b&gt;</del><ins class="diff-ins">&lt;i&gt;</ins>`},
+	}
+	for _, tt := range tests {
+		if got := renderTextDiff(tt.before, tt.after); got != tt.want {
+			t.Errorf("%s: renderTextDiff(%q, %q) = %q, want %q", tt.name, tt.before, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestDiffTokens_TooLargeForTable(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 1100; i++ {
+		a = append(a, "a", " ")
+		b = append(b, "b", " ")
+	}
+	ops := diffTokens(append([]string{"same"}, a...), append([]string{"same"}, b...))
+	// The trailing space is a common suffix
+	if len(ops) != 4 || ops[0].kind != diffEqual || ops[1].kind != diffDelete || ops[2].kind != diffInsert || ops[3].kind != diffEqual {
+		t.Fatalf("diffTokens() = %d ops, want the common prefix, one deletion, one insertion and the common suffix", len(ops))
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	s := "héllo  wörld\n x"
+	tokens := splitWords(s)
+	if got := strings.Join(tokens, ""); got != s {
+		t.Errorf("tokens join to %q, want %q", got, s)
+	}
+	if want := []string{"héllo", "  ", "wörld", "\n ", "x"}; strings.Join(tokens, "|") != strings.Join(want, "|") {
+		t.Errorf("splitWords() = %q, want %q", tokens, want)
+	}
+}
+
+func TestRenderConversationWithOptions_EditHistory(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{
+			UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2025-01-01T10:02:00Z",
+			Message: json.RawMessage(`{"role":"user","content":"Fix the failing tests please"}`),
+			Revisions: []models.MessageRevision{
+				{Timestamp: "2025-01-01T10:00:00Z", Message: json.RawMessage(`{"role":"user","content":"Fix the test"}`)},
+				{Message: json.RawMessage(`{"role":"user","content":"Fix the failing tests"}`)},
+			},
+		},
+		{UUID: "u2", Type: models.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":"Thanks"}`)},
+	}
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		revisionMarker,
+		`<summary class="revisions-summary">Edit history (2 earlier versions)</summary>`,
+		`<div class="revision-header">Version 1 · 10:00 AM</div>`,
+		`<div class="revision-header">Version 2</div>`,
+		`Fix the <del class="diff-del">test</del><ins class="diff-ins">failing tests</ins>`,
+		`Fix the failing tests<ins class="diff-ins"> please</ins>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+	if got := strings.Count(html, `class="revision-marker"`); got != 1 {
+		t.Errorf("page has %d edited markers, want 1 (messages without revisions are unmarked)", got)
+	}
+}
@@ -0,0 +1,14 @@
+package export
+
+import _ "embed"
+
+// exportJSONSchema is the JSON Schema for documents produced by RenderConversationJSON.
+// Its format_version constant and $id must match ExportFormatVersion.
+//
+//go:embed schema/export.schema.json
+var exportJSONSchema []byte
+
+// ExportJSONSchema returns the JSON Schema (draft 2020-12) describing the JSON export format.
+func ExportJSONSchema() []byte {
+	return append([]byte(nil), exportJSONSchema...)
+}
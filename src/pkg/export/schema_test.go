@@ -0,0 +1,133 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// compileExportSchema compiles the embedded export schema.
+func compileExportSchema(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	if err := compiler.AddResource("export.schema.json", bytes.NewReader(ExportJSONSchema())); err != nil {
+		t.Fatalf("failed to load schema: %v", err)
+	}
+	schema, err := compiler.Compile("export.schema.json")
+	if err != nil {
+		t.Fatalf("failed to compile schema: %v", err)
+	}
+	return schema
+}
+
+// validateAgainstSchema validates a rendered JSON document against the export schema.
+func validateAgainstSchema(t *testing.T, schema *jsonschema.Schema, data []byte) error {
+	t.Helper()
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("export is not valid JSON: %v", err)
+	}
+	return schema.Validate(doc)
+}
+
+func schemaTestAgents() []*agent.TreeNode {
+	return []*agent.TreeNode{
+		{AgentID: "a1b2c3d", AgentType: "explore", EntryCount: 3, Children: []*agent.TreeNode{
+			{AgentID: "e4f5a6b", EntryCount: 1},
+		}},
+	}
+}
+
+func TestExportJSONSchema_ValidatesExport(t *testing.T) {
+	schema := compileExportSchema(t)
+
+	entries := exporterTestEntries()
+	entries = append(entries, models.ConversationEntry{
+		UUID: "m1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:09Z",
+		Message: json.RawMessage(`{"role":"assistant","model":"claude-opus","content":[{"type":"text","text":"done"}]}`),
+	})
+	stats := ComputeSessionStats(entries, schemaTestAgents())
+	stats.ProjectPath = "/work/project"
+
+	data, err := RenderConversationJSON(entries, schemaTestAgents(), stats, nil)
+	if err != nil {
+		t.Fatalf("RenderConversationJSON() error = %v", err)
+	}
+	if err := validateAgainstSchema(t, schema, data); err != nil {
+		t.Errorf("JSON export does not match schema: %v\n%s", err, data)
+	}
+}
+
+func TestExportJSONSchema_ValidatesFieldProjections(t *testing.T) {
+	schema := compileExportSchema(t)
+
+	for _, fields := range [][]string{{"uuid"}, {"tool", "tool_calls"}, JSONFieldNames()} {
+		data, err := RenderConversationJSON(exporterTestEntries(), nil, nil, fields)
+		if err != nil {
+			t.Fatalf("RenderConversationJSON(%v) error = %v", fields, err)
+		}
+		if err := validateAgainstSchema(t, schema, data); err != nil {
+			t.Errorf("JSON export with fields %v does not match schema: %v", fields, err)
+		}
+	}
+}
+
+func TestExportJSONSchema_RejectsDrift(t *testing.T) {
+	schema := compileExportSchema(t)
+
+	data, err := RenderConversationJSON(exporterTestEntries(), nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	doc["unexpected"] = true
+	if err := schema.Validate(doc); err == nil {
+		t.Error("schema should reject unknown top-level fields")
+	}
+	delete(doc, "unexpected")
+
+	doc["format_version"] = "0.0"
+	if err := schema.Validate(doc); err == nil {
+		t.Error("schema should reject a different format version")
+	}
+}
+
+func TestExportJSONSchema_MatchesFormatVersion(t *testing.T) {
+	var schema struct {
+		ID         string `json:"$id"`
+		Properties struct {
+			FormatVersion struct {
+				Const string `json:"const"`
+			} `json:"format_version"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(ExportJSONSchema(), &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	if schema.Properties.FormatVersion.Const != ExportFormatVersion {
+		t.Errorf("schema format_version = %q, want ExportFormatVersion %q", schema.Properties.FormatVersion.Const, ExportFormatVersion)
+	}
+	if !strings.HasSuffix(schema.ID, "export-"+ExportFormatVersion+".json") {
+		t.Errorf("schema $id %q should be versioned with ExportFormatVersion %q", schema.ID, ExportFormatVersion)
+	}
+}
+
+func TestExportJSONSchema_ReturnsCopy(t *testing.T) {
+	first := ExportJSONSchema()
+	first[0] = 'X'
+	if ExportJSONSchema()[0] == 'X' {
+		t.Error("ExportJSONSchema should return a copy of the embedded schema")
+	}
+}
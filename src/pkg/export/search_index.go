@@ -0,0 +1,120 @@
+package export
+
+import (
+	"encoding/json"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Bounds on the terms in a search index (see ExportOptions.SearchIndex).
+const (
+	searchIndexMinTermLength = 2  // Shorter words are not indexed
+	searchIndexMaxTermLength = 64 // Longer words (hashes, encoded data) are not indexed
+)
+
+// searchIndexStopWords are common English words left out of search indexes. They occur
+// in nearly every message, so indexing them would add the most size and narrow a search
+// the least.
+var searchIndexStopWords = []string{
+	"about", "after", "all", "also", "an", "and", "any", "are", "as", "at",
+	"be", "been", "but", "by", "can", "could", "did", "do", "does", "for", "from",
+	"had", "has", "have", "he", "her", "his", "how", "if", "in", "into", "is",
+	"it", "its", "just", "let", "me", "my", "no", "not", "now", "of", "on", "one",
+	"or", "our", "out", "she", "should", "so", "some", "than", "that", "the", "their",
+	"them", "then", "there", "these", "they", "this", "to", "up", "us", "was", "we",
+	"were", "what", "when", "which", "will", "with", "would", "you", "your",
+}
+
+// htmlTagRe matches an HTML tag, for reducing rendered markup to its text.
+var htmlTagRe = regexp.MustCompile(`<[^>]*>`)
+
+// searchIndex is the inverted index embedded in a page by ExportOptions.SearchIndex and
+// read by the search in controls.js. Rows lists the UUIDs of the indexed messages, and
+// Terms maps each term to the positions in Rows of the messages containing it.
+// Unindexed lists messages with words too long to index, which the search always scans.
+// The search splits queries into terms with the same rules, given by the other fields.
+type searchIndex struct {
+	MinTermLength int              `json:"minTermLength"`
+	MaxTermLength int              `json:"maxTermLength"`
+	StopWords     []string         `json:"stopWords"`
+	Rows          []string         `json:"rows"`
+	Unindexed     []int            `json:"unindexed"`
+	Terms         map[string][]int `json:"terms"`
+}
+
+// buildSearchIndex indexes the text of the message blocks, as the page shows it, by the
+// UUID of each block's entry. Other blocks are left out; the search scans them.
+func buildSearchIndex(blocks []RenderedEntry) searchIndex {
+	stopWords := make(map[string]bool, len(searchIndexStopWords))
+	for _, w := range searchIndexStopWords {
+		stopWords[w] = true
+	}
+
+	index := searchIndex{
+		MinTermLength: searchIndexMinTermLength,
+		MaxTermLength: searchIndexMaxTermLength,
+		StopWords:     searchIndexStopWords,
+		Rows:          []string{},
+		Unindexed:     []int{},
+		Terms:         make(map[string][]int),
+	}
+	for _, block := range blocks {
+		if block.Kind != BlockMessage || block.UUID == "" {
+			continue
+		}
+		row := len(index.Rows)
+		index.Rows = append(index.Rows, block.UUID)
+
+		seen := make(map[string]bool)
+		unindexed := false
+		for _, term := range searchTerms(blockText(string(block.HTML))) {
+			n := utf8.RuneCountInString(term)
+			if n > searchIndexMaxTermLength {
+				unindexed = true
+				continue
+			}
+			if n < searchIndexMinTermLength || stopWords[term] || seen[term] {
+				continue
+			}
+			seen[term] = true
+			// Rows are added in order, so each term's positions stay sorted
+			index.Terms[term] = append(index.Terms[term], row)
+		}
+		if unindexed {
+			index.Unindexed = append(index.Unindexed, row)
+		}
+	}
+	return index
+}
+
+// blockText returns the text of rendered markup as the browser's textContent gives it:
+// tags are removed, without adding space, and character references decoded.
+func blockText(markup string) string {
+	return html.UnescapeString(htmlTagRe.ReplaceAllString(markup, ""))
+}
+
+// searchTerms splits text into lowercase runs of letters and digits. controls.js splits
+// queries the same way.
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// renderSearchIndex returns the search index of blocks as a JSON script element, empty
+// if there are no messages to index.
+func renderSearchIndex(blocks []RenderedEntry) string {
+	index := buildSearchIndex(blocks)
+	if len(index.Rows) == 0 {
+		return ""
+	}
+	// json.Marshal escapes <, > and &, so the data cannot close the script element
+	data, err := json.Marshal(index)
+	if err != nil {
+		return ""
+	}
+	return `<script type="application/json" id="search-index">` + string(data) + "</script>\n"
+}
@@ -0,0 +1,162 @@
+package export
+
+import (
+	"encoding/json"
+	"html/template"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestSearchTerms(t *testing.T) {
+	got := searchTerms("Run `go test ./...` in pkg/export: Überprüfung 42x")
+	want := []string{"run", "go", "test", "in", "pkg", "export", "überprüfung", "42x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("searchTerms() = %q, want %q", got, want)
+	}
+}
+
+func TestBlockText(t *testing.T) {
+	got := blockText(`<p class="x">fo<mark class="export-highlight">ob</mark>ar &amp; &lt;tag&gt;</p>`)
+	if got != "foobar & <tag>" {
+		t.Errorf("blockText() = %q", got)
+	}
+}
+
+func TestBuildSearchIndex(t *testing.T) {
+	blocks := []RenderedEntry{
+		{Kind: BlockMessage, UUID: "u1", HTML: template.HTML(`<div class="message-row user" data-uuid="u1">Fix the parser and the lexer</div>`)},
+		{Kind: BlockDay, HTML: template.HTML(`<div class="day-separator">February parser</div>`)},
+		{Kind: BlockMessage, UUID: "a1", HTML: template.HTML(`<div class="message-row assistant" data-uuid="a1">Parser fixed, parser tests pass</div>`)},
+	}
+
+	index := buildSearchIndex(blocks)
+
+	if !reflect.DeepEqual(index.Rows, []string{"u1", "a1"}) {
+		t.Errorf("Rows = %v, want only the message blocks", index.Rows)
+	}
+	if got := index.Terms["parser"]; !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Errorf("Terms[parser] = %v, want each row once", got)
+	}
+	if got := index.Terms["lexer"]; !reflect.DeepEqual(got, []int{0}) {
+		t.Errorf("Terms[lexer] = %v", got)
+	}
+	if _, ok := index.Terms["february"]; ok {
+		t.Error("non-message blocks should not be indexed")
+	}
+	for _, word := range []string{"the", "and"} {
+		if _, ok := index.Terms[word]; ok {
+			t.Errorf("stop word %q should not be indexed", word)
+		}
+	}
+	if len(index.Unindexed) != 0 {
+		t.Errorf("Unindexed = %v, want none", index.Unindexed)
+	}
+}
+
+func TestBuildSearchIndex_Bounds(t *testing.T) {
+	long := strings.Repeat("f", searchIndexMaxTermLength+1)
+	blocks := []RenderedEntry{
+		{Kind: BlockMessage, UUID: "u1", HTML: template.HTML("x hash " + long)},
+		{Kind: BlockMessage, UUID: "u2", HTML: template.HTML("short words")},
+	}
+
+	index := buildSearchIndex(blocks)
+
+	if _, ok := index.Terms["x"]; ok {
+		t.Error("terms shorter than the minimum should not be indexed")
+	}
+	if _, ok := index.Terms[long]; ok {
+		t.Error("terms longer than the maximum should not be indexed")
+	}
+	if !reflect.DeepEqual(index.Unindexed, []int{0}) {
+		t.Errorf("Unindexed = %v, want the row with the long word", index.Unindexed)
+	}
+	if _, ok := index.Terms["hash"]; !ok {
+		t.Error("other terms of the row should still be indexed")
+	}
+}
+
+func TestRenderSearchIndex(t *testing.T) {
+	blocks := []RenderedEntry{
+		{Kind: BlockMessage, UUID: "u1", HTML: template.HTML(`&lt;/script&gt;&lt;script&gt;alert(1)`)},
+	}
+
+	got := renderSearchIndex(blocks)
+	if !strings.HasPrefix(got, `<script type="application/json" id="search-index">`) {
+		t.Fatalf("renderSearchIndex() = %q", got)
+	}
+	data := strings.TrimSuffix(strings.TrimPrefix(got, `<script type="application/json" id="search-index">`), "</script>\n")
+	if strings.Contains(data, "<") {
+		t.Errorf("index data should not contain markup: %s", data)
+	}
+
+	var index searchIndex
+	if err := json.Unmarshal([]byte(data), &index); err != nil {
+		t.Fatalf("index is not valid JSON: %v", err)
+	}
+	if index.MinTermLength != searchIndexMinTermLength || index.MaxTermLength != searchIndexMaxTermLength || len(index.StopWords) == 0 {
+		t.Errorf("index should carry its term rules, got %+v", index)
+	}
+	if _, ok := index.Terms["alert"]; !ok {
+		t.Errorf("Terms = %v, want alert", index.Terms)
+	}
+
+	if got := renderSearchIndex(nil); got != "" {
+		t.Errorf("renderSearchIndex(nil) = %q, want empty", got)
+	}
+}
+
+func TestRenderConversation_SearchIndex(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: []byte(`"Refactor the tokenizer"`)},
+	}
+
+	plain, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if strings.Contains(plain, `id="search-index"`) {
+		t.Error("search index should be off by default")
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{SearchIndex: true})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if !strings.Contains(html, `<script type="application/json" id="search-index">`) || !strings.Contains(html, `"tokenizer":[0]`) {
+		t.Error("SearchIndex should embed the index of the messages")
+	}
+}
+
+func TestRenderConversationPage_SearchIndex(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: []byte(`"first page words"`)},
+		{UUID: "u2", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:01:00Z", Message: []byte(`"second page words"`)},
+	}
+	pages := SplitPages(entries, 1)
+
+	html, err := RenderConversationPage(entries, pages[1], nil, nil, ExportOptions{SearchIndex: true, PageSize: 1})
+	if err != nil {
+		t.Fatalf("RenderConversationPage() error = %v", err)
+	}
+	if !strings.Contains(html, `"rows":["u2"]`) || strings.Contains(html, `"first"`) {
+		t.Error("each page should index only its own messages")
+	}
+}
+
+func TestGetControlsJS_SearchIndex(t *testing.T) {
+	js := GetControlsJS()
+	for _, want := range []string{
+		"getElementById('search-index')",
+		"function searchIndexCandidates(query)",
+		"function isSearchCandidate(entry, candidates)",
+		"isSearchCandidate(entry, candidates) && regex.test(content.textContent)",
+	} {
+		if !strings.Contains(js, want) {
+			t.Errorf("controls.js missing %q", want)
+		}
+	}
+}
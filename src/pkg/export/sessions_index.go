@@ -0,0 +1,78 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SessionsIndexFileName is the page listing the sessions of a multi-session export.
+const SessionsIndexFileName = "index.html"
+
+// SessionLink is one session listed by RenderSessionsIndex.
+type SessionLink struct {
+	SessionID   string // Session ID as resolved, or as given when it could not be resolved
+	FirstPrompt string // First user prompt, shown as a preview
+	Href        string // Path of the session's export relative to the index; empty if nothing was exported
+	Error       string // Why the session failed to export; empty on success
+}
+
+// sessionsIndexCSS styles the sessions index, which sits outside the session folders and
+// so cannot share their static/style.css.
+const sessionsIndexCSS = `body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; color: #1f2328; }
+.sessions-summary { color: #59636e; }
+.sessions-index li { margin: 0.5rem 0; }
+.sessions-index code { font-size: 0.9em; }
+.session-preview { color: #59636e; }
+.session-error { color: #d1242f; }
+`
+
+// RenderSessionsIndex renders the standalone index.html of a multi-session export: a
+// count of exported and failed sessions, then each session with a link to its export,
+// a preview of its first prompt, and why it failed if it did.
+func RenderSessionsIndex(projectPath string, sessions []SessionLink) string {
+	failed := 0
+	for _, s := range sessions {
+		if s.Error != "" {
+			failed++
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n")
+	sb.WriteString("    <meta charset=\"UTF-8\">\n")
+	sb.WriteString("    <meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\">\n")
+	sb.WriteString(fmt.Sprintf("    <meta name=\"export-format-version\" content=\"%s\">\n", ExportFormatVersion))
+	sb.WriteString(fmt.Sprintf("    <title>Claude Code Sessions - %s</title>\n", escapeHTML(projectPath)))
+	sb.WriteString("    <style>\n" + sessionsIndexCSS + "    </style>\n")
+	sb.WriteString("</head>\n<body>\n")
+	sb.WriteString(fmt.Sprintf("    <h1>Sessions of <code>%s</code></h1>\n", escapeHTML(projectPath)))
+	sb.WriteString(fmt.Sprintf("    <p class=\"sessions-summary\">%d exported, %d failed</p>\n", len(sessions)-failed, failed))
+	sb.WriteString("    <ol class=\"sessions-index\">\n")
+	for _, s := range sessions {
+		sb.WriteString(renderSessionLink(s))
+	}
+	sb.WriteString("    </ol>\n</body>\n</html>\n")
+	return sb.String()
+}
+
+// renderSessionLink renders the index entry for one session.
+func renderSessionLink(s SessionLink) string {
+	id := fmt.Sprintf("<code>%s</code>", escapeHTML(s.SessionID))
+	if s.Href != "" {
+		id = fmt.Sprintf(`<a href="%s">%s</a>`, escapeHTML(s.Href), id)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("        <li>" + id)
+	if preview := strings.Join(strings.Fields(s.FirstPrompt), " "); preview != "" {
+		if truncated, cut := truncateUTF8(preview, pagePreviewLen); cut {
+			preview = truncated + "…"
+		}
+		sb.WriteString(fmt.Sprintf(` <span class="session-preview">%s</span>`, escapeHTML(preview)))
+	}
+	if s.Error != "" {
+		sb.WriteString(fmt.Sprintf(` <span class="session-error">failed: %s</span>`, escapeHTML(s.Error)))
+	}
+	sb.WriteString("</li>\n")
+	return sb.String()
+}
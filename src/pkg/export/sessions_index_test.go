@@ -0,0 +1,26 @@
+package export
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderSessionsIndex(t *testing.T) {
+	html := RenderSessionsIndex("/home/me/<app>", []SessionLink{
+		{SessionID: "aaaa-1111", FirstPrompt: "Fix   the\nbuild", Href: "aaaa-1111/index.html"},
+		{SessionID: "bbbb-2222", Href: "bbbb-2222/", Error: "HTML rendering failed: boom"},
+		{SessionID: "cccc", Error: "session not found"},
+	})
+
+	for _, want := range []string{
+		"<title>Claude Code Sessions - /home/me/&lt;app&gt;</title>",
+		`<p class="sessions-summary">1 exported, 2 failed</p>`,
+		`<li><a href="aaaa-1111/index.html"><code>aaaa-1111</code></a> <span class="session-preview">Fix the build</span></li>`,
+		`<li><a href="bbbb-2222/"><code>bbbb-2222</code></a> <span class="session-error">failed: HTML rendering failed: boom</span></li>`,
+		`<li><code>cccc</code> <span class="session-error">failed: session not found</span></li>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("index missing %q\n%s", want, html)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package export
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// bashOutputToolName polls the output of a background shell started by a Bash call with
+// run_in_background set.
+const bashOutputToolName = "BashOutput"
+
+// killShellToolNames stop a background shell; KillBash is the tool's earlier name.
+var killShellToolNames = map[string]bool{"KillShell": true, "KillBash": true}
+
+// backgroundShellIDRe matches the shell ID in the result of a Bash call run in the
+// background, e.g. "Command running in background with ID: bash_1".
+var backgroundShellIDRe = regexp.MustCompile(`running in background with ID: (\S+)`)
+
+// isShellTool reports whether a tool polls or stops a background shell.
+func isShellTool(name string) bool {
+	return name == bashOutputToolName || killShellToolNames[name]
+}
+
+// shellToolID returns the shell a BashOutput or KillShell call refers to (input bash_id
+// or shell_id), or "" if the input names none.
+func shellToolID(input map[string]any) string {
+	for _, key := range []string{"bash_id", "shell_id"} {
+		if id, ok := input[key].(string); ok && id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// backgroundShell is a background shell of a session: the Bash call that started it and
+// the calls that polled or stopped it, in session order.
+type backgroundShell struct {
+	id      string
+	callID  string // The Bash call that started the shell; empty if not in the entries
+	command string
+	polls   []string // BashOutput call IDs
+	killID  string   // The KillShell call that stopped the shell, if any
+}
+
+// backgroundShells maps shell IDs to their shells.
+type backgroundShells map[string]*backgroundShell
+
+// buildBackgroundShells correlates the background Bash calls of entries, identified by
+// the shell ID in their result, with the BashOutput and KillShell calls naming that
+// shell. Shells only polled or stopped in entries have no starting call. Returns nil if
+// entries use no background shell.
+func buildBackgroundShells(entries []models.ConversationEntry, toolResults map[string]models.ToolResult) backgroundShells {
+	var shells backgroundShells
+	shell := func(id string) *backgroundShell {
+		if shells == nil {
+			shells = make(backgroundShells)
+		}
+		if shells[id] == nil {
+			shells[id] = &backgroundShell{id: id}
+		}
+		return shells[id]
+	}
+
+	for i := range entries {
+		if entries[i].Type != models.EntryTypeAssistant {
+			continue
+		}
+		for _, tool := range entries[i].ExtractToolCalls() {
+			switch {
+			case tool.Name == "Bash":
+				if background, _ := tool.Input["run_in_background"].(bool); !background {
+					continue
+				}
+				m := backgroundShellIDRe.FindStringSubmatch(toolResults[tool.ID].Content)
+				if m == nil {
+					continue
+				}
+				s := shell(m[1])
+				s.callID = tool.ID
+				s.command, _ = tool.Input["command"].(string)
+			case tool.Name == bashOutputToolName:
+				if id := shellToolID(tool.Input); id != "" {
+					s := shell(id)
+					s.polls = append(s.polls, tool.ID)
+				}
+			case killShellToolNames[tool.Name]:
+				if id := shellToolID(tool.Input); id != "" {
+					shell(id).killID = tool.ID
+				}
+			}
+		}
+	}
+	return shells
+}
+
+// forCall returns the shell a background Bash call started, or nil.
+func (shells backgroundShells) forCall(tool models.ToolUse) *backgroundShell {
+	for _, s := range shells {
+		if s.callID != "" && s.callID == tool.ID {
+			return s
+		}
+	}
+	return nil
+}
+
+// renderShellID renders a shell ID with a button copying it.
+func renderShellID(id string) string {
+	return fmt.Sprintf(`<code class="shell-id">%s</code>%s`, escapeHTML(id), renderCopyButton(id, "shell-id", "Copy shell ID"))
+}
+
+// renderBackgroundShellLine renders the line under a background Bash call naming its
+// shell and linking to the calls that polled and stopped it, so the polls of one shell
+// read as a group.
+func renderBackgroundShellLine(s *backgroundShell) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`<div class="background-shell" data-shell-id="%s">Background shell %s`, escapeHTML(s.id), renderShellID(s.id)))
+	if len(s.polls) > 0 {
+		noun := "polls"
+		if len(s.polls) == 1 {
+			noun = "poll"
+		}
+		sb.WriteString(fmt.Sprintf(` · %d %s:`, len(s.polls), noun))
+		for i, pollID := range s.polls {
+			sb.WriteString(fmt.Sprintf(` <a class="shell-poll-link" href="#tool-%s">#%d</a>`, escapeHTML(pollID), i+1))
+		}
+	}
+	if s.killID != "" {
+		sb.WriteString(fmt.Sprintf(` · <a class="shell-kill-link" href="#tool-%s">stopped</a>`, escapeHTML(s.killID)))
+	}
+	sb.WriteString("</div>\n")
+	return sb.String()
+}
+
+// renderShellToolCall renders a BashOutput or KillShell call: the header marks a poll
+// with its number among the shell's polls, and the body names the shell and links to
+// the background Bash call that started it (or says the shell is unknown, when ro.shells
+// has no record of it), before the call's output. Otherwise the call renders like
+// renderToolCallWith.
+func renderShellToolCall(tool models.ToolUse, result models.ToolResult, hasResult bool, ro entryRenderOptions) string {
+	id := shellToolID(tool.Input)
+	shell := ro.shells[id]
+
+	status := ""
+	if tool.Name == bashOutputToolName && shell != nil {
+		for i, pollID := range shell.polls {
+			if pollID == tool.ID {
+				status = fmt.Sprintf(`<span class="shell-poll">poll %d of %d</span>`, i+1, len(shell.polls))
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(renderToolCallHeader(tool, hasResult, status, ro))
+
+	sb.WriteString(fmt.Sprintf(`    <div class="shell-context" data-shell-id="%s">Shell %s`, escapeHTML(id), renderShellID(id)))
+	switch {
+	case shell != nil && shell.callID != "":
+		sb.WriteString(fmt.Sprintf(` started by <a class="shell-origin-link" href="#tool-%s"><code>%s</code></a>`,
+			escapeHTML(shell.callID), escapeHTML(truncateSummary(shell.command, ro.opts.SummaryMaxLen))))
+	default:
+		sb.WriteString(` <span class="shell-origin-unknown">(started outside this conversation)</span>`)
+	}
+	sb.WriteString("</div>\n")
+
+	sb.WriteString(renderToolInput(tool.Input))
+	if hasResult {
+		outputClass := "tool-output bash-output"
+		if result.IsError {
+			outputClass += " error"
+		}
+		output, truncated := result.Content, false
+		if !result.IsError {
+			output, truncated = truncateUTF8(result.Content, ro.opts.MaxToolOutputBytes)
+		}
+		sb.WriteString(fmt.Sprintf(`    <div class="tool-connector">%s</div>`, renderToolPairLink(tool.ID, false, ro.opts.NoJS)))
+		sb.WriteString("\n")
+		sb.WriteString(fmt.Sprintf(`    <pre class="%s"%s>%s</pre>`, outputClass, toolResultAttrs(result), escapeHTML(output)))
+		sb.WriteString("\n")
+		if truncated {
+			sb.WriteString(renderTruncatedNotice(result.Content))
+		}
+	}
+
+	sb.WriteString(renderToolCallClose(ro.opts.NoJS))
+	return sb.String()
+}
@@ -0,0 +1,95 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// shellEntries start a background shell, poll it twice, stop it, and poll a shell
+// started elsewhere.
+func shellEntries() []models.ConversationEntry {
+	call := func(uuid, id, name, input string) models.ConversationEntry {
+		return models.ConversationEntry{UUID: uuid, Type: models.EntryTypeAssistant, Message: json.RawMessage(
+			`{"role":"assistant","content":[{"type":"tool_use","id":"` + id + `","name":"` + name + `","input":` + input + `}]}`)}
+	}
+	result := func(uuid, id, content string) models.ConversationEntry {
+		return models.ConversationEntry{UUID: uuid, Type: models.EntryTypeUser, Message: json.RawMessage(
+			`[{"type":"tool_result","tool_use_id":"` + id + `","content":"` + content + `"}]`)}
+	}
+	return []models.ConversationEntry{
+		call("a1", "t-start", "Bash", `{"command":"npm run dev","run_in_background":true}`),
+		result("r1", "t-start", "Command running in background with ID: bash_1"),
+		call("a2", "t-poll1", "BashOutput", `{"bash_id":"bash_1"}`),
+		result("r2", "t-poll1", "compiling <app>"),
+		call("a3", "t-poll2", "BashOutput", `{"bash_id":"bash_1","filter":"error"}`),
+		result("r3", "t-poll2", "ready on :3000"),
+		call("a4", "t-kill", "KillShell", `{"shell_id":"bash_1"}`),
+		result("r4", "t-kill", "Shell bash_1 killed"),
+		call("a5", "t-other", "BashOutput", `{"bash_id":"bash_9"}`),
+		call("a6", "t-fg", "Bash", `{"command":"ls"}`),
+		result("r6", "t-fg", "main.go"),
+	}
+}
+
+func TestBuildBackgroundShells(t *testing.T) {
+	entries := shellEntries()
+	shells := buildBackgroundShells(entries, buildToolResultsMap(entries))
+
+	if len(shells) != 2 {
+		t.Fatalf("buildBackgroundShells() = %v, want bash_1 and bash_9", shells)
+	}
+	s := shells["bash_1"]
+	if s.callID != "t-start" || s.command != "npm run dev" || strings.Join(s.polls, ",") != "t-poll1,t-poll2" || s.killID != "t-kill" {
+		t.Errorf("bash_1 = %+v", s)
+	}
+	if other := shells["bash_9"]; other.callID != "" || len(other.polls) != 1 {
+		t.Errorf("bash_9 = %+v, want a poll without a starting call", other)
+	}
+
+	if shells := buildBackgroundShells(entries[9:], buildToolResultsMap(entries[9:])); shells != nil {
+		t.Errorf("foreground commands should start no shell, got %v", shells)
+	}
+}
+
+func TestRenderAgentFragment_BackgroundShells(t *testing.T) {
+	html, err := RenderAgentFragment("agent1", shellEntries())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		// The starting call lists the shell's polls
+		`<div class="background-shell" data-shell-id="bash_1">Background shell <code class="shell-id">bash_1</code>`,
+		`data-copy-text="bash_1" data-copy-type="shell-id"`,
+		`2 polls: <a class="shell-poll-link" href="#tool-t-poll1">#1</a> <a class="shell-poll-link" href="#tool-t-poll2">#2</a>`,
+		`<a class="shell-kill-link" href="#tool-t-kill">stopped</a>`,
+		// Each poll is numbered and links back to it
+		`[BashOutput] bash_1`,
+		`<span class="shell-poll">poll 2 of 2</span>`,
+		`started by <a class="shell-origin-link" href="#tool-t-start"><code>npm run dev</code></a>`,
+		`compiling &lt;app&gt;`,
+		`[KillShell] bash_1`,
+		`(started outside this conversation)`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("fragment missing %q:\n%s", want, html)
+		}
+	}
+	if n := strings.Count(html, `class="background-shell"`); n != 1 {
+		t.Errorf("got %d background shell lines, want 1 for the background call only", n)
+	}
+}
+
+func TestRenderEntry_UnknownShellTool(t *testing.T) {
+	// Calls without a shell ID, and tools this exporter does not know, render generically
+	for _, tool := range []string{`{"type":"tool_use","id":"t1","name":"BashOutput","input":{}}`, `{"type":"tool_use","id":"t2","name":"ShellStatus","input":{"shell_id":"bash_1"}}`} {
+		entry := models.ConversationEntry{UUID: "a1", Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[` + tool + `]}`)}
+		html := renderEntryWith(entry, nil, "", "", "", "User", "Assistant", entryRenderOptions{opts: ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen}})
+		if strings.Contains(html, "shell-context") || !strings.Contains(html, "tool-input") {
+			t.Errorf("tool should render generically:\n%s", html)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"sort"
+
+	"github.com/randlee/claude-history/pkg/agent"
+)
+
+// ShortenIDs maps each agent ID to the shortest display form that no other ID in ids
+// shares, like git's abbreviated hashes. Display forms are those of
+// agent.NormalizeAgentID (type prefix and unprintable characters removed) and are never
+// shorter than agent.ShortAgentIDLength, so a lone ID still shortens to the usual
+// length. An ID whose whole display form is a prefix of another's keeps its whole form.
+// Empty IDs are skipped.
+func ShortenIDs(ids []string) map[string]string {
+	forms := make(map[string][]rune, len(ids)) // ID -> full display form
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		full, _ := agent.NormalizeAgentIDLength(id, 0)
+		forms[id] = []rune(full)
+	}
+
+	// After sorting, an ID shares its longest prefix with one of its neighbours
+	unique := make([]string, 0, len(forms))
+	seen := make(map[string]bool, len(forms))
+	for _, form := range forms {
+		if s := string(form); !seen[s] {
+			seen[s] = true
+			unique = append(unique, s)
+		}
+	}
+	sort.Strings(unique)
+
+	need := make(map[string]int, len(unique)) // display form -> shortest unique length
+	for i, s := range unique {
+		n := 0
+		if i > 0 {
+			n = max(n, commonPrefixLen(unique[i-1], s)+1)
+		}
+		if i+1 < len(unique) {
+			n = max(n, commonPrefixLen(s, unique[i+1])+1)
+		}
+		need[s] = n
+	}
+
+	shortIDs := make(map[string]string, len(forms))
+	for id, form := range forms {
+		n := max(need[string(form)], agent.ShortAgentIDLength)
+		if n > len(form) {
+			n = len(form)
+		}
+		shortIDs[id] = string(form[:n])
+	}
+	return shortIDs
+}
+
+// commonPrefixLen returns the number of leading runes a and b have in common.
+func commonPrefixLen(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	n := 0
+	for n < len(ra) && n < len(rb) && ra[n] == rb[n] {
+		n++
+	}
+	return n
+}
+
+// shortAgentID returns the display form and type label of an agent ID, using its entry
+// in shortIDs (see ShortenIDs) when there is one and agent.NormalizeAgentID otherwise.
+func shortAgentID(id string, shortIDs map[string]string) (short, typeLabel string) {
+	short, typeLabel = agent.NormalizeAgentID(id)
+	if s, ok := shortIDs[id]; ok {
+		short = s
+	}
+	return short, typeLabel
+}
@@ -0,0 +1,131 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestShortenIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []string
+		want map[string]string
+	}{
+		{
+			name: "single id keeps readable length",
+			ids:  []string{"a12eb64f9c0d1e2f"},
+			want: map[string]string{"a12eb64f9c0d1e2f": "a12eb64f"},
+		},
+		{
+			name: "distinct ids use the default length",
+			ids:  []string{"a12eb64f9c0d", "b98cd76e5a4f"},
+			want: map[string]string{"a12eb64f9c0d": "a12eb64f", "b98cd76e5a4f": "b98cd76e"},
+		},
+		{
+			name: "shared 8-char prefix extends only the colliding ids",
+			ids:  []string{"a12eb64f9c0d", "a12eb64f9d11", "b98cd76e5a4f"},
+			want: map[string]string{"a12eb64f9c0d": "a12eb64f9c", "a12eb64f9d11": "a12eb64f9d", "b98cd76e5a4f": "b98cd76e"},
+		},
+		{
+			name: "short ids stay whole",
+			ids:  []string{"a12eb64", "abc"},
+			want: map[string]string{"a12eb64": "a12eb64", "abc": "abc"},
+		},
+		{
+			name: "id that prefixes another keeps its whole form",
+			ids:  []string{"a12eb64f9c", "a12eb64f9c0d1e"},
+			want: map[string]string{"a12eb64f9c": "a12eb64f9c", "a12eb64f9c0d1e": "a12eb64f9c0"},
+		},
+		{
+			name: "typed ids compare their display forms",
+			ids:  []string{"aexplore-def456789abc", "aprompt_suggestion-def456789xyz"},
+			want: map[string]string{"aexplore-def456789abc": "def456789a", "aprompt_suggestion-def456789xyz": "def456789x"},
+		},
+		{
+			name: "duplicates and empty ids",
+			ids:  []string{"a12eb64f9c0d", "", "a12eb64f9c0d"},
+			want: map[string]string{"a12eb64f9c0d": "a12eb64f"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ShortenIDs(tt.ids)
+			if len(got) != len(tt.want) {
+				t.Errorf("ShortenIDs() = %v, want %v", got, tt.want)
+			}
+			for id, want := range tt.want {
+				if got[id] != want {
+					t.Errorf("ShortenIDs()[%q] = %q, want %q", id, got[id], want)
+				}
+			}
+		})
+	}
+}
+
+func TestShortenIDs_Unique(t *testing.T) {
+	ids := []string{"aaaaaaaa1", "aaaaaaaa2", "aaaaaaab", "aaaaaaaa11", "baaaaaaa", "aaaaaaaa"}
+	got := ShortenIDs(ids)
+	seen := map[string]string{}
+	for _, id := range ids {
+		short := got[id]
+		if !strings.HasPrefix(id, short) {
+			t.Errorf("%q is not a prefix of %q", short, id)
+		}
+		if other, ok := seen[short]; ok && other != id {
+			t.Errorf("%q and %q both shorten to %q", other, id, short)
+		}
+		seen[short] = id
+	}
+}
+
+func TestRenderConversationWithOptions_CollidingAgentIDs(t *testing.T) {
+	idA := "a12eb64f9c0d1e2f"
+	idB := "a12eb64f9d11aa22"
+	entries := []models.ConversationEntry{
+		{UUID: "q1", Type: models.EntryTypeQueueOperation, AgentID: idA, Timestamp: "2026-02-01T10:00:00Z"},
+		{UUID: "q2", Type: models.EntryTypeQueueOperation, AgentID: idB, Timestamp: "2026-02-01T10:00:01Z"},
+		{UUID: "s1", Type: models.EntryTypeAssistant, AgentID: idA, IsSidechain: true, Timestamp: "2026-02-01T10:00:02Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"From agent A"}]}`)},
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`<span class="subagent-title">Subagent: a12eb64f9c</span>`,
+		`<span class="subagent-title">Subagent: a12eb64f9d</span>`,
+		`data-agent-id="` + idA + `"`,
+		`data-agent-id="` + idB + `"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if strings.Contains(html, "Subagent: a12eb64f<") {
+		t.Error("colliding agent IDs should not display the ambiguous 8-char prefix")
+	}
+}
+
+func TestRenderAgentIDWithCopyWith(t *testing.T) {
+	entry := models.ConversationEntry{AgentID: "a12eb64f9c0d1e2f"}
+	shortIDs := map[string]string{"a12eb64f9c0d1e2f": "a12eb64f9c"}
+
+	html := renderAgentIDWithCopyWith(entry, entry.AgentID, "session-1", "", "/test/project", "Assistant", shortIDs, nil)
+	if !strings.Contains(html, `<span class="agent-id-badge">a12eb64f9c<`) {
+		t.Errorf("badge should show the session-unique short ID, got %s", html)
+	}
+	if !strings.Contains(html, "a12eb64f9c0d1e2f") {
+		t.Error("copy button should keep the full agent ID")
+	}
+
+	fallback := renderAgentIDWithCopyWith(entry, entry.AgentID, "session-1", "", "/test/project", "Assistant", nil, nil)
+	if !strings.Contains(fallback, `<span class="agent-id-badge">a12eb64f<`) {
+		t.Errorf("without shortIDs the badge should use the default length, got %s", fallback)
+	}
+}
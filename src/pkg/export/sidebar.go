@@ -0,0 +1,73 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/agent"
+)
+
+// sidebarLabelMaxLen is the length subagent descriptions are truncated to in the sidebar.
+const sidebarLabelMaxLen = 40
+
+// OutlineAgent is a subagent in the session outline listed by ExportOptions.Sidebar.
+type OutlineAgent struct {
+	AgentID    string
+	Parents    []string // IDs of the agents it is nested in, outermost first; empty for a top-level agent
+	EntryCount int
+}
+
+// buildAgentOutline lists the agents of the tree depth-first, each before the agents it
+// spawned, in the order the tree keeps them.
+func buildAgentOutline(agents []*agent.TreeNode) []OutlineAgent {
+	var outline []OutlineAgent
+	var walk func(nodes []*agent.TreeNode, parents []string)
+	walk = func(nodes []*agent.TreeNode, parents []string) {
+		for _, node := range nodes {
+			if node.AgentID == "" {
+				continue
+			}
+			outline = append(outline, OutlineAgent{AgentID: node.AgentID, Parents: parents, EntryCount: node.EntryCount})
+			walk(node.Children, append(parents[:len(parents):len(parents)], node.AgentID))
+		}
+	}
+	walk(agents, nil)
+	return outline
+}
+
+// renderSessionSidebar renders the fixed sidebar of ExportOptions.Sidebar: a link to the
+// main session followed by one link per subagent of stats.Outline, indented by how deeply
+// it is nested. The links are plain anchors to the subagent sections; script.js makes them
+// load and open the sections (and those they are nested in), marks the link of the section
+// in view as active, and opens the list on narrow screens, where it starts collapsed.
+func renderSessionSidebar(stats *SessionStats) string {
+	var sb strings.Builder
+	sb.WriteString(`<nav class="session-sidebar" aria-label="Session outline">` + "\n")
+	sb.WriteString(`    <button class="sidebar-toggle" type="button" aria-expanded="false" onclick="toggleSidebar(this)">Outline</button>` + "\n")
+	sb.WriteString(`    <ul class="sidebar-list">` + "\n")
+	sb.WriteString(`        <li><a class="sidebar-link sidebar-main active" href="#" data-sidebar-agent="" aria-current="true">Main session</a></li>` + "\n")
+
+	ids := make([]string, len(stats.Outline))
+	for i, item := range stats.Outline {
+		ids[i] = item.AgentID
+	}
+	shortIDs := ShortenIDs(ids)
+
+	for _, item := range stats.Outline {
+		shortID, typeLabel := shortAgentID(item.AgentID, shortIDs)
+		label, title := typeLabel, item.AgentID
+		if description := stats.AgentDescriptions[item.AgentID]; description != "" {
+			label, title = truncateSummary(description, sidebarLabelMaxLen), description
+		}
+		if label == "" {
+			label = "Subagent"
+		}
+		sb.WriteString(fmt.Sprintf(`        <li><a class="sidebar-link" href="#%s" data-sidebar-agent="%s" data-agent-parents="%s" style="--sidebar-depth: %d" title="%s">%s <code class="subagent-id">%s</code> <span class="sidebar-count">%d</span></a></li>`+"\n",
+			escapeHTML(subagentAnchorID(item.AgentID)), escapeHTML(item.AgentID), escapeHTML(strings.Join(item.Parents, " ")),
+			len(item.Parents)+1, escapeHTML(title), escapeHTML(label), escapeHTML(shortID), item.EntryCount))
+	}
+
+	sb.WriteString("    </ul>\n")
+	sb.WriteString("</nav>\n")
+	return sb.String()
+}
@@ -0,0 +1,97 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/agent"
+)
+
+func sidebarTestTree() []*agent.TreeNode {
+	return []*agent.TreeNode{
+		{AgentID: "a111111111", EntryCount: 4, Description: "Explore the parser", Children: []*agent.TreeNode{
+			{AgentID: "a333333333", EntryCount: 2, Children: []*agent.TreeNode{
+				{AgentID: "a444444444", EntryCount: 1},
+			}},
+		}},
+		{AgentID: "a222222222", EntryCount: 3},
+	}
+}
+
+func TestBuildAgentOutline(t *testing.T) {
+	outline := buildAgentOutline(sidebarTestTree())
+
+	want := []struct {
+		id      string
+		parents string
+	}{
+		{"a111111111", ""},
+		{"a333333333", "a111111111"},
+		{"a444444444", "a111111111 a333333333"},
+		{"a222222222", ""},
+	}
+	if len(outline) != len(want) {
+		t.Fatalf("outline has %d agents, want %d", len(outline), len(want))
+	}
+	for i, w := range want {
+		if outline[i].AgentID != w.id || strings.Join(outline[i].Parents, " ") != w.parents {
+			t.Errorf("outline[%d] = %s under %v, want %s under %q", i, outline[i].AgentID, outline[i].Parents, w.id, w.parents)
+		}
+	}
+}
+
+func TestRenderHTMLHeader_Sidebar(t *testing.T) {
+	stats := ComputeSessionStats(nil, sidebarTestTree())
+
+	html := renderHTMLHeaderWith(stats, nil, localizer{}, ExportOptions{Sidebar: true})
+	if !strings.Contains(html, `<body class="has-sidebar">`) {
+		t.Error("body should make room for the sidebar")
+	}
+	if !strings.Contains(html, `<nav class="session-sidebar" aria-label="Session outline">`) {
+		t.Fatal("sidebar missing")
+	}
+	if !strings.Contains(html, `class="sidebar-link sidebar-main active" href="#"`) {
+		t.Error("main session link should lead the sidebar and start active")
+	}
+
+	// Indentation follows nesting depth, and nested links name the agents to open first
+	for _, want := range []string{
+		`href="#agent-a111111111" data-sidebar-agent="a111111111" data-agent-parents="" style="--sidebar-depth: 1" title="Explore the parser">Explore the parser`,
+		`data-sidebar-agent="a333333333" data-agent-parents="a111111111" style="--sidebar-depth: 2"`,
+		`data-sidebar-agent="a444444444" data-agent-parents="a111111111 a333333333" style="--sidebar-depth: 3"`,
+		`data-sidebar-agent="a222222222" data-agent-parents="" style="--sidebar-depth: 1"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("sidebar missing %s", want)
+		}
+	}
+	if strings.Index(html, "a333333333") > strings.Index(html, `data-sidebar-agent="a222222222"`) {
+		t.Error("nested agents should follow their parent")
+	}
+
+	// Without scripts both classes apply
+	html = renderHTMLHeaderWith(stats, nil, localizer{}, ExportOptions{Sidebar: true, NoJS: true})
+	if !strings.Contains(html, `<body class="no-js has-sidebar">`) {
+		t.Error("no-js and has-sidebar classes should combine")
+	}
+}
+
+func TestRenderHTMLHeader_NoSidebarByDefault(t *testing.T) {
+	html := renderHTMLHeaderWith(ComputeSessionStats(nil, sidebarTestTree()), nil, localizer{}, ExportOptions{})
+	if strings.Contains(html, "session-sidebar") || strings.Contains(html, "has-sidebar") {
+		t.Error("sidebar should only render with ExportOptions.Sidebar")
+	}
+}
+
+func TestSidebarAssets(t *testing.T) {
+	js := GetScriptJS()
+	for _, fn := range []string{"function initSidebar", "function sidebarNavigate", "function updateSidebarActive", "function toggleSidebar"} {
+		if !strings.Contains(js, fn) {
+			t.Errorf("JavaScript missing %s", fn)
+		}
+	}
+	css := GetStyleCSS()
+	if !strings.Contains(css, ".session-sidebar:not(.open) .sidebar-list") {
+		t.Error("CSS should collapse the sidebar on narrow screens")
+	}
+}
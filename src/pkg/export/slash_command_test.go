@@ -0,0 +1,62 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRenderEntry_SlashCommand(t *testing.T) {
+	entry := models.ConversationEntry{
+		UUID:      "cmd-1",
+		Type:      models.EntryTypeUser,
+		Timestamp: "2026-02-01T10:00:00Z",
+		Message:   json.RawMessage(`{"role":"user","content":"<command-name>/compact</command-name>\n<command-message>compact</command-message>\n<command-args>keep <the> tests</command-args>"}`),
+	}
+
+	html := renderEntry(entry, nil, "", "", "", "User", "Assistant")
+
+	if !strings.Contains(html, `<div class="slash-command" data-uuid="cmd-1" data-command="/compact">`) {
+		t.Errorf("slash command should render as a chip, got:\n%s", html)
+	}
+	if !strings.Contains(html, `<code class="slash-command-chip">/compact</code>`) {
+		t.Error("chip should show the command name")
+	}
+	if !strings.Contains(html, `<span class="slash-command-args">keep &lt;the&gt; tests</span>`) {
+		t.Error("chip should show the escaped arguments")
+	}
+	if strings.Contains(html, "message-bubble") || strings.Contains(html, "command-message") {
+		t.Errorf("slash command should not render the raw tags in a bubble, got:\n%s", html)
+	}
+}
+
+func TestRenderEntry_SlashLikeMessageKeepsBubble(t *testing.T) {
+	entry := models.ConversationEntry{
+		UUID:    "msg-1",
+		Type:    models.EntryTypeUser,
+		Message: json.RawMessage(`"/etc/hosts is missing an entry"`),
+	}
+
+	html := renderEntry(entry, nil, "", "", "", "User", "Assistant")
+
+	if strings.Contains(html, "slash-command") || !strings.Contains(html, "message-bubble") {
+		t.Errorf("a message starting with a path should stay a message, got:\n%s", html)
+	}
+}
+
+func TestRenderConversation_SlashCommandChip(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "c1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: json.RawMessage(`"/clear"`)},
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:01Z", Message: json.RawMessage(`"Start over"`)},
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if strings.Count(html, `class="slash-command"`) != 1 || strings.Count(html, `class="message-row user`) != 1 {
+		t.Errorf("want one chip and one user bubble, got:\n%s", html)
+	}
+}
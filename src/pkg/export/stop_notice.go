@@ -0,0 +1,29 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// stopNoticeLabels describe the stop reasons a stop notice can show; other reasons are
+// labeled "response stopped".
+var stopNoticeLabels = map[string]string{
+	"max_tokens":                    "response truncated",
+	"model_context_window_exceeded": "response truncated",
+	"refusal":                       "response refused",
+}
+
+// renderStopNotice renders an assistant entry that has nothing but its stop reason (see
+// models.ConversationEntry.IsStopNotice) as an informational marker, e.g.
+// "⚠ response truncated: max_tokens".
+func renderStopNotice(entry models.ConversationEntry, ro entryRenderOptions) string {
+	reason := entry.StopReason()
+	label, ok := stopNoticeLabels[reason]
+	if !ok {
+		label = "response stopped"
+	}
+	return fmt.Sprintf(`<div class="stop-notice" role="note" data-uuid="%s" data-stop-reason="%s"><span class="stop-notice-icon" aria-hidden="true">⚠</span> <span class="stop-notice-label">%s:</span> <code class="stop-notice-reason">%s</code>%s</div>`+"\n",
+		escapeHTML(entry.UUID), escapeHTML(reason), escapeHTML(label), escapeHTML(reason),
+		renderTimestampSpan(entry.Timestamp, formatTimestampReadable(entry.Timestamp), ro))
+}
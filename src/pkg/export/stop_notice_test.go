@@ -0,0 +1,54 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRenderStopNotice(t *testing.T) {
+	tests := map[string]string{
+		"max_tokens": "response truncated:",
+		"refusal":    "response refused:",
+		"pause_turn": "response stopped:",
+	}
+	for reason, label := range tests {
+		entry := models.ConversationEntry{UUID: "a1", Type: models.EntryTypeAssistant,
+			Message: json.RawMessage(`{"role":"assistant","content":[],"stop_reason":"` + reason + `"}`)}
+		html := renderEntry(entry, nil, "", "", "", "User", "Assistant")
+		if !strings.Contains(html, `class="stop-notice"`) || !strings.Contains(html, label) ||
+			!strings.Contains(html, `<code class="stop-notice-reason">`+reason+`</code>`) {
+			t.Errorf("%s: unexpected stop notice:\n%s", reason, html)
+		}
+		if strings.Contains(html, "message-bubble") {
+			t.Errorf("%s: a stop notice should not render as a message bubble", reason)
+		}
+	}
+}
+
+func TestRenderConversation_StopNotices(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Message: json.RawMessage(`"Write the report"`)},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[],"stop_reason":"max_tokens"}`)},
+		{UUID: "a2", Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[],"stop_reason":"end_turn"}`)},
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, ComputeSessionStats(entries, nil), ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(html, `class="stop-notice"`); got != 1 {
+		t.Errorf("got %d stop notices, want 1", got)
+	}
+	if strings.Contains(html, `data-uuid="a2"`) {
+		t.Error("an empty entry that ended normally should still be skipped")
+	}
+}
+
+func TestCSSContent_StopNotice(t *testing.T) {
+	if !strings.Contains(GetStyleCSS(), ".stop-notice {") {
+		t.Error("stop notices should have their own style")
+	}
+}
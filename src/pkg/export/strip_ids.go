@@ -0,0 +1,280 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// IDMapper replaces the session, agent, message and tool IDs of an export with
+// sequential synthetic labels (sess-1, agent-1, msg-1, tool-1) for ExportOptions.StripIDs.
+// An ID gets its label the first time it is seen and keeps it, so a tool call and its
+// result, or a subagent's section and its fragment, get matching labels. An IDMapper is
+// safe for concurrent use.
+type IDMapper struct {
+	mu       sync.Mutex
+	labels   map[string]string
+	counts   map[string]int
+	replacer *strings.Replacer // Replaces every labeled ID; nil when labels changed
+}
+
+// NewIDMapper returns an IDMapper without labels.
+func NewIDMapper() *IDMapper {
+	return &IDMapper{labels: make(map[string]string), counts: make(map[string]int)}
+}
+
+// Session returns the label of a session ID ("" for "").
+func (m *IDMapper) Session(id string) string { return m.label("sess", id) }
+
+// Agent returns the label of a subagent ID ("" for "").
+func (m *IDMapper) Agent(id string) string { return m.label("agent", id) }
+
+// Message returns the label of an entry UUID ("" for "").
+func (m *IDMapper) Message(uuid string) string { return m.label("msg", uuid) }
+
+// Tool returns the label of a tool call ID ("" for "").
+func (m *IDMapper) Tool(id string) string { return m.label("tool", id) }
+
+// label returns the label of id, numbering it as the next of kind if it has none yet.
+func (m *IDMapper) label(kind, id string) string {
+	if id == "" {
+		return ""
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if label, ok := m.labels[id]; ok {
+		return label
+	}
+	m.counts[kind]++
+	label := fmt.Sprintf("%s-%d", kind, m.counts[kind])
+	m.labels[id] = label
+	m.replacer = nil
+	return label
+}
+
+// Replace returns s with every ID that has a label replaced by it, for text that
+// mentions IDs: paths, CLI commands, tool output and raw JSON. Longer IDs are replaced
+// first, so an ID inside another is not replaced on its own.
+func (m *IDMapper) Replace(s string) string {
+	if s == "" {
+		return s
+	}
+	m.mu.Lock()
+	if m.replacer == nil {
+		ids := make([]string, 0, len(m.labels))
+		for id := range m.labels {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			if len(ids[i]) != len(ids[j]) {
+				return len(ids[i]) > len(ids[j])
+			}
+			return ids[i] < ids[j]
+		})
+		pairs := make([]string, 0, 2*len(ids))
+		for _, id := range ids {
+			pairs = append(pairs, id, m.labels[id])
+		}
+		m.replacer = strings.NewReplacer(pairs...)
+	}
+	replacer := m.replacer
+	m.mu.Unlock()
+	return replacer.Replace(s)
+}
+
+// replaceRaw returns raw JSON with the labeled IDs replaced (see Replace). IDs and
+// labels contain no characters JSON escapes, so the result stays valid.
+func (m *IDMapper) replaceRaw(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return raw
+	}
+	return json.RawMessage(m.Replace(string(raw)))
+}
+
+// stripIDs returns the inputs of a render with their IDs replaced by the labels of
+// opts.IDMap (a new IDMapper if nil), when opts.StripIDs is set; otherwise they are
+// returned unchanged. stats is computed from entries first if nil. The session ID is
+// labeled first, then the agents in tree order, then the IDs of the entries in session
+// order. The returned options no longer ask for stripping, so renders they are passed
+// on to do not strip again.
+func stripIDs(entries []models.ConversationEntry, agents []*agent.TreeNode, stats *SessionStats, opts ExportOptions) ([]models.ConversationEntry, []*agent.TreeNode, *SessionStats, ExportOptions) {
+	if !opts.StripIDs {
+		return entries, agents, stats, opts
+	}
+	if stats == nil {
+		stats = ComputeSessionStats(entries, agents)
+	}
+	m := opts.IDMap
+	if m == nil {
+		m = NewIDMapper()
+		opts.IDMap = m
+	}
+
+	m.Session(stats.SessionID)
+	for _, root := range agents {
+		for _, node := range agent.FlattenTree(root) {
+			m.Agent(node.AgentID)
+		}
+	}
+	entries = stripEntryIDs(entries, m)
+	agents = stripTreeIDs(agents, m)
+	stats = stripStatsIDs(stats, m)
+
+	opts = stripOptionIDs(opts, m)
+	return entries, agents, stats, opts
+}
+
+// stripOptionIDs returns a copy of opts with the agent and message IDs it holds labeled
+// by m, no longer asking for stripping.
+func stripOptionIDs(opts ExportOptions, m *IDMapper) ExportOptions {
+	opts.StripIDs = false
+	opts.IDMap = m
+	opts.ToolIndex = nil // Indexes the original IDs
+	opts.Timeline = stripSpanIDs(opts.Timeline, m)
+	opts.AgentSpans = stripSpanIDs(opts.AgentSpans, m)
+	if opts.Annotations != nil {
+		annotations := make(map[string]Annotation, len(opts.Annotations))
+		for uuid, annotation := range opts.Annotations {
+			annotations[m.Message(uuid)] = annotation
+		}
+		opts.Annotations = annotations
+	}
+	if opts.AgentEntries != nil {
+		agentEntries := make(map[string][]models.ConversationEntry, len(opts.AgentEntries))
+		for agentID, agentEntriesOf := range opts.AgentEntries {
+			agentEntries[m.Agent(agentID)] = stripEntryIDs(agentEntriesOf, m)
+		}
+		opts.AgentEntries = agentEntries
+	}
+	return opts
+}
+
+// stripEntryIDs returns copies of entries with their IDs labeled by m: first every ID
+// they hold is labeled, in session order, then replaced in the ID fields and in the
+// message, content and raw line, which mention tool call IDs and may quote other IDs.
+func stripEntryIDs(entries []models.ConversationEntry, m *IDMapper) []models.ConversationEntry {
+	if entries == nil {
+		return nil
+	}
+	for i := range entries {
+		entry := &entries[i]
+		m.Session(entry.SessionID)
+		m.Agent(entry.AgentID)
+		if entry.ToolUseResult != nil {
+			m.Agent(entry.ToolUseResult.AgentID)
+		}
+		m.Message(entry.UUID)
+		for _, tool := range entry.ExtractToolCalls() {
+			m.Tool(tool.ID)
+		}
+		for _, result := range entry.ExtractToolResults() {
+			m.Tool(result.ToolUseID)
+		}
+	}
+
+	out := make([]models.ConversationEntry, len(entries))
+	for i, entry := range entries {
+		entry.UUID = m.Message(entry.UUID)
+		if entry.ParentUUID != nil {
+			parent := m.Message(*entry.ParentUUID)
+			entry.ParentUUID = &parent
+		}
+		entry.SourceToolAssistantUUID = m.Message(entry.SourceToolAssistantUUID)
+		entry.SessionID = m.Session(entry.SessionID)
+		entry.AgentID = m.Agent(entry.AgentID)
+		if entry.ToolUseResult != nil {
+			result := *entry.ToolUseResult
+			result.AgentID = m.Agent(result.AgentID)
+			result.Description = m.Replace(result.Description)
+			result.Prompt = m.Replace(result.Prompt)
+			result.OutputFile = m.Replace(result.OutputFile)
+			result.Stdout = m.Replace(result.Stdout)
+			result.Stderr = m.Replace(result.Stderr)
+			entry.ToolUseResult = &result
+		}
+		entry.Message = m.replaceRaw(entry.Message)
+		entry.Content = m.replaceRaw(entry.Content)
+		entry.Error = m.replaceRaw(entry.Error)
+		entry.RawLine = m.replaceRaw(entry.RawLine)
+		out[i] = entry
+	}
+	return out
+}
+
+// stripTreeIDs returns a copy of the agent trees under nodes with their IDs labeled by m.
+func stripTreeIDs(nodes []*agent.TreeNode, m *IDMapper) []*agent.TreeNode {
+	if nodes == nil {
+		return nil
+	}
+	out := make([]*agent.TreeNode, len(nodes))
+	for i, node := range nodes {
+		stripped := *node
+		stripped.AgentID = m.Agent(node.AgentID)
+		stripped.SessionID = m.Session(node.SessionID)
+		stripped.UUID = m.Message(node.UUID)
+		stripped.ParentUUID = m.Replace(node.ParentUUID)
+		stripped.FilePath = m.Replace(node.FilePath)
+		stripped.Description = m.Replace(node.Description)
+		stripped.Children = stripTreeIDs(node.Children, m)
+		out[i] = &stripped
+	}
+	return out
+}
+
+// stripStatsIDs returns a copy of stats with the IDs it holds labeled by m.
+func stripStatsIDs(stats *SessionStats, m *IDMapper) *SessionStats {
+	stripped := *stats
+	stripped.SessionID = m.Session(stats.SessionID)
+	stripped.SessionFolderPath = m.Replace(stats.SessionFolderPath)
+	stripped.FirstPrompt = m.Replace(stats.FirstPrompt)
+	stripped.Preamble = stripEntryIDs(stats.Preamble, m)
+
+	stripped.Lineage = nil
+	for _, id := range stats.Lineage {
+		stripped.Lineage = append(stripped.Lineage, m.Session(id))
+	}
+	stripped.AgentDescriptions = relabelKeys(stats.AgentDescriptions, m.Agent)
+	stripped.FailedSpawns = relabelKeys(stats.FailedSpawns, m.Agent)
+
+	stripped.Outline = nil
+	for _, agentOutline := range stats.Outline {
+		parents := make([]string, len(agentOutline.Parents))
+		for i, parent := range agentOutline.Parents {
+			parents[i] = m.Agent(parent)
+		}
+		agentOutline.AgentID = m.Agent(agentOutline.AgentID)
+		agentOutline.Parents = parents
+		stripped.Outline = append(stripped.Outline, agentOutline)
+	}
+	return &stripped
+}
+
+// relabelKeys returns a copy of values keyed by the labels of its keys.
+func relabelKeys(values map[string]string, label func(string) string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	out := make(map[string]string, len(values))
+	for key, value := range values {
+		out[label(key)] = value
+	}
+	return out
+}
+
+// stripSpanIDs returns a copy of spans with their agent IDs labeled by m.
+func stripSpanIDs(spans []agent.AgentSpan, m *IDMapper) []agent.AgentSpan {
+	if spans == nil {
+		return nil
+	}
+	out := make([]agent.AgentSpan, len(spans))
+	for i, span := range spans {
+		span.AgentID = m.Agent(span.AgentID)
+		out[i] = span
+	}
+	return out
+}
@@ -0,0 +1,145 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+const (
+	stripSessionID = "5f0c7e2a-9d41-4b8e-a1f3-2c6d8e9b0a17"
+	stripAgentID   = "a7c3e91f"
+	stripToolID    = "toolu_01XyZabcDEF234ghiJKL567"
+	stripUserUUID  = "0b8f6d2c-1e3a-4c5b-9d7e-8f0a1b2c3d4e"
+	stripCallUUID  = "7e6d5c4b-3a29-4180-9f8e-7d6c5b4a3928"
+	stripResultID  = "c1d2e3f4-a5b6-4c7d-8e9f-0a1b2c3d4e5f"
+)
+
+// stripEntries are a session that asks for a file and reads it, with one subagent.
+func stripEntries() ([]models.ConversationEntry, []*agent.TreeNode) {
+	parent := stripUserUUID
+	entries := []models.ConversationEntry{
+		{UUID: stripUserUUID, SessionID: stripSessionID, Type: models.EntryTypeUser, Timestamp: "2026-01-02T10:00:00Z",
+			Message: json.RawMessage(`"Read main.go"`)},
+		{UUID: stripCallUUID, ParentUUID: &parent, SessionID: stripSessionID, Type: models.EntryTypeAssistant, Timestamp: "2026-01-02T10:00:01Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"` + stripToolID + `","name":"Read","input":{"file_path":"main.go"}}]}`)},
+		{UUID: stripResultID, SessionID: stripSessionID, Type: models.EntryTypeUser, Timestamp: "2026-01-02T10:00:02Z",
+			SourceToolAssistantUUID: stripCallUUID,
+			Message:                 json.RawMessage(`[{"type":"tool_result","tool_use_id":"` + stripToolID + `","content":"package main"}]`)},
+	}
+	root := &agent.TreeNode{SessionID: stripSessionID, UUID: stripSessionID, Children: []*agent.TreeNode{
+		{AgentID: stripAgentID, SessionID: stripSessionID, FilePath: "/tmp/" + stripSessionID + "/subagents/agent-" + stripAgentID + ".jsonl", EntryCount: 2},
+	}}
+	return entries, []*agent.TreeNode{root}
+}
+
+func TestIDMapper(t *testing.T) {
+	m := NewIDMapper()
+	if got := m.Session("s"); got != "sess-1" {
+		t.Errorf("Session() = %q, want sess-1", got)
+	}
+	if got := m.Tool("t1"); got != "tool-1" {
+		t.Errorf("Tool() = %q, want tool-1", got)
+	}
+	if got := m.Tool("t2"); got != "tool-2" {
+		t.Errorf("Tool() = %q, want tool-2", got)
+	}
+	if got := m.Tool("t1"); got != "tool-1" {
+		t.Errorf("Tool() of a seen ID = %q, want its first label tool-1", got)
+	}
+	if got := m.Agent(""); got != "" {
+		t.Errorf("Agent(\"\") = %q, want \"\"", got)
+	}
+	if got := m.Replace("t1 then t2 in s"); got != "tool-1 then tool-2 in sess-1" {
+		t.Errorf("Replace() = %q", got)
+	}
+}
+
+func TestRenderConversationWithOptions_StripIDs(t *testing.T) {
+	entries, agents := stripEntries()
+	html, err := RenderConversationWithOptions(entries, agents, nil, ExportOptions{StripIDs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{stripSessionID, stripAgentID, stripToolID, stripUserUUID, stripCallUUID, stripResultID} {
+		if strings.Contains(html, id) {
+			t.Errorf("stripped page still contains %q", id)
+		}
+		if strings.Contains(html, truncateID(id, 8)) {
+			t.Errorf("stripped page still contains the short form of %q", id)
+		}
+	}
+	for _, want := range []string{
+		`&#34;id&#34;:&#34;agent-1&#34;`,
+		`id="tool-tool-1"`,
+		`href="#tool-result-tool-1"`,
+		`data-uuid="msg-1"`,
+		`sess-1`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("stripped page missing %q", want)
+		}
+	}
+	// The input entries are left alone
+	if entries[1].UUID != stripCallUUID {
+		t.Errorf("StripIDs changed the caller's entries: %q", entries[1].UUID)
+	}
+}
+
+func TestRenderConversationWithOptions_StripIDsFolderNotLinked(t *testing.T) {
+	entries, agents := stripEntries()
+	stats := ComputeSessionStats(entries, agents)
+	stats.SessionFolderPath = "/home/me/.claude/projects/-repo/" + stripSessionID
+
+	html, err := RenderConversationWithOptions(entries, agents, stats, ExportOptions{StripIDs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, "/-repo/") || strings.Contains(html, "folder-link") {
+		t.Error("stripped page should not link to a session folder that does not exist")
+	}
+	if !strings.Contains(html, "sess-1") {
+		t.Error("stripped page should still name the session folder")
+	}
+
+	if html, _ := RenderConversationWithOptions(entries, agents, stats, ExportOptions{}); !strings.Contains(html, `class="folder-link"`) {
+		t.Error("the session folder should be linked without StripIDs")
+	}
+}
+
+func TestRenderConversationWithOptions_StripIDsOffByDefault(t *testing.T) {
+	entries, agents := stripEntries()
+	html, err := RenderConversationWithOptions(entries, agents, nil, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, `id="tool-`+stripToolID+`"`) || strings.Contains(html, "tool-1") {
+		t.Error("IDs should be kept without StripIDs")
+	}
+}
+
+func TestRenderAgentFragmentWithOptions_StripIDsSharesLabels(t *testing.T) {
+	entries, agents := stripEntries()
+	opts := ExportOptions{StripIDs: true, IDMap: NewIDMapper()}
+	if _, err := RenderConversationWithOptions(entries, agents, nil, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	fragment, err := RenderAgentFragmentWithOptions(stripAgentID, []models.ConversationEntry{
+		{UUID: "d4c3b2a1-0000-4000-8000-000000000001", SessionID: stripSessionID, AgentID: stripAgentID, Type: models.EntryTypeUser,
+			Message: json.RawMessage(`"Summarize"`)},
+	}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(fragment, stripAgentID) || strings.Contains(fragment, stripSessionID) {
+		t.Errorf("stripped fragment still contains IDs:\n%s", fragment)
+	}
+	if got := opts.IDMap.Agent(stripAgentID); got != "agent-1" {
+		t.Errorf("fragment relabeled the agent as %q, want the page's agent-1", got)
+	}
+}
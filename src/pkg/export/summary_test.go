@@ -0,0 +1,117 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestTruncateSummary(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		maxLen int
+		want   string
+	}{
+		{"short", "ls -la", 60, "ls -la"},
+		{"exact length", "abcdef", 6, "abcdef"},
+		{"truncated", "abcdefghij", 8, "abcde..."},
+		{"zero means no limit", strings.Repeat("x", 200), 0, strings.Repeat("x", 200)},
+		{"multibyte not split", "日本語のファイル名です", 8, "日本語のフ..."},
+		{"tiny limit", "abcdef", 2, "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateSummary(tt.input, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("truncateSummary(%q, %d) = %q, want %q", tt.input, tt.maxLen, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateSummary() produced invalid UTF-8: %q", got)
+			}
+		})
+	}
+}
+
+func TestTruncateSummaryWithLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		maxLen int
+		want   string
+	}{
+		{"short", "ls -la", 10, "ls -la"},
+		{"exact", "abcdefghij", 10, "abcdefghij"},
+		{"truncated", "git clone https://example.com/repo.git", 11, "git clone … (+28 chars)"},
+		{"multibyte not split", "日本語のファイル名です", 8, "日本語のファイ… (+4 chars)"},
+		{"no limit", "abcdefghij", 0, "abcdefghij"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateSummaryWithLength(tt.input, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("truncateSummaryWithLength(%q, %d) = %q, want %q", tt.input, tt.maxLen, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateSummaryWithLength() produced invalid UTF-8: %q", got)
+			}
+		})
+	}
+}
+
+func TestFormatToolSummaryWith(t *testing.T) {
+	tool := models.ToolUse{Name: "Bash", Input: map[string]any{"command": strings.Repeat("a", 100)}}
+
+	if got := formatToolSummaryWith(tool, 0); got != "[Bash] "+strings.Repeat("a", 100) {
+		t.Errorf("maxLen 0 should not truncate, got %q", got)
+	}
+	if got := formatToolSummaryWith(tool, 20); got != "[Bash] "+strings.Repeat("a", 19)+"… (+81 chars)" {
+		t.Errorf("maxLen 20 = %q", got)
+	}
+	if formatToolSummary(tool) != formatToolSummaryWith(tool, DefaultSummaryMaxLen) {
+		t.Error("formatToolSummary should use DefaultSummaryMaxLen")
+	}
+}
+
+func TestRenderConversationWithOptions_SummaryMaxLen(t *testing.T) {
+	longCommand := "echo " + strings.Repeat("word ", 30)
+	input, _ := json.Marshal(map[string]any{"command": longCommand})
+	entries := []models.ConversationEntry{{
+		UUID:      "a1",
+		Type:      models.EntryTypeAssistant,
+		Timestamp: "2026-02-01T10:00:00Z",
+		Message:   json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":` + string(input) + `}]}`),
+	}}
+
+	full, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{SummaryMaxLen: 0})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if !strings.Contains(full, `<span class="tool-summary"><span class="tool-icon" aria-hidden="true">🖥</span>[Bash] `+strings.TrimSpace(escapeHTML(longCommand))) {
+		t.Error("SummaryMaxLen 0 should show the full command in the tool header")
+	}
+
+	short, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{SummaryMaxLen: 20})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	want := truncateSummaryWithLength(longCommand, 20)
+	if !strings.Contains(short, `<span class="tool-summary" title="[Bash] `+escapeHTML(longCommand)+`"><span class="tool-icon" aria-hidden="true">🖥</span>[Bash] `+escapeHTML(want)+`</span>`) {
+		t.Errorf("tool header should be truncated to %q, with the full command as its title", want)
+	}
+	if !strings.Contains(short, escapeHTML(truncateSummary(longCommand, 20))+"</") {
+		t.Error("tool-only role label summary should use the same limit")
+	}
+
+	// The non-options entry point keeps the 60 character default
+	def, err := RenderConversationWithStats(entries, nil, nil)
+	if err != nil {
+		t.Fatalf("RenderConversationWithStats() error = %v", err)
+	}
+	if !strings.Contains(def, escapeHTML(truncateSummaryWithLength(longCommand, DefaultSummaryMaxLen))) {
+		t.Error("default rendering should truncate to DefaultSummaryMaxLen")
+	}
+}
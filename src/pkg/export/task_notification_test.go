@@ -396,3 +396,14 @@ func TestTruncateID(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkParseTaskNotification(b *testing.B) {
+	content := "<task-notification>\n<task-id>b0c3ca8</task-id>\n<status>completed</status>\n" +
+		"<summary>Background command finished</summary>\n<result>line 1\nline 2</result>\n</task-notification>"
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			parseTaskNotification(content)
+		}
+	})
+}
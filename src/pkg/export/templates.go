@@ -10,6 +10,27 @@ import (
 //go:embed templates/*
 var templatesFS embed.FS
 
+// staticAssetGetters maps static asset file names (as referenced by the HTML) to their contents.
+var staticAssetGetters = map[string]func() string{
+	"style.css":        GetStyleCSS,
+	"script.js":        GetScriptJS,
+	"clipboard.js":     GetClipboardJS,
+	"controls.js":      GetControlsJS,
+	"navigation.js":    GetNavigationJS,
+	"agent-tooltip.js": GetAgentTooltipJS,
+}
+
+// StaticAsset returns the contents of a static asset by file name (e.g., "style.css").
+// The second return value is false if the asset does not exist.
+func StaticAsset(name string) (string, bool) {
+	getter, ok := staticAssetGetters[name]
+	if !ok {
+		return "", false
+	}
+	content := getter()
+	return content, content != ""
+}
+
 // GetStyleCSS returns the contents of the embedded CSS file.
 func GetStyleCSS() string {
 	data, err := templatesFS.ReadFile("templates/style.css")
@@ -64,66 +85,34 @@ func GetAgentTooltipJS() string {
 	return string(data)
 }
 
+// StaticDirName is the subdirectory of an HTML export holding its static assets.
+const StaticDirName = "static"
+
 // WriteStaticAssets writes all static assets to the output directory.
 // Creates a 'static' subdirectory containing style.css and script.js.
 func WriteStaticAssets(outputDir string) error {
-	staticDir := filepath.Join(outputDir, "static")
+	return WriteStaticAssetsWith(outputDir, ExportOptions{})
+}
+
+// WriteStaticAssetsWith writes the static assets like WriteStaticAssets, shrinking them
+// when opts.Minify is set.
+func WriteStaticAssetsWith(outputDir string, opts ExportOptions) error {
+	staticDir := filepath.Join(outputDir, StaticDirName)
 
 	// Create static directory
 	if err := os.MkdirAll(staticDir, 0755); err != nil {
 		return err
 	}
 
-	// Write CSS file
-	cssContent := GetStyleCSS()
-	if cssContent != "" {
-		cssPath := filepath.Join(staticDir, "style.css")
-		if err := os.WriteFile(cssPath, []byte(cssContent), 0644); err != nil {
-			return err
-		}
-	}
-
-	// Write JavaScript file
-	jsContent := GetScriptJS()
-	if jsContent != "" {
-		jsPath := filepath.Join(staticDir, "script.js")
-		if err := os.WriteFile(jsPath, []byte(jsContent), 0644); err != nil {
-			return err
-		}
-	}
-
-	// Write clipboard JavaScript file
-	clipboardContent := GetClipboardJS()
-	if clipboardContent != "" {
-		clipboardPath := filepath.Join(staticDir, "clipboard.js")
-		if err := os.WriteFile(clipboardPath, []byte(clipboardContent), 0644); err != nil {
-			return err
+	for name, getter := range staticAssetGetters {
+		content := getter()
+		if content == "" {
+			continue
 		}
-	}
-
-	// Write controls JavaScript file
-	controlsContent := GetControlsJS()
-	if controlsContent != "" {
-		controlsPath := filepath.Join(staticDir, "controls.js")
-		if err := os.WriteFile(controlsPath, []byte(controlsContent), 0644); err != nil {
-			return err
+		if opts.Minify {
+			content = minifyAsset(name, content)
 		}
-	}
-
-	// Write navigation JavaScript file
-	navigationContent := GetNavigationJS()
-	if navigationContent != "" {
-		navigationPath := filepath.Join(staticDir, "navigation.js")
-		if err := os.WriteFile(navigationPath, []byte(navigationContent), 0644); err != nil {
-			return err
-		}
-	}
-
-	// Write agent-tooltip JavaScript file
-	agentTooltipContent := GetAgentTooltipJS()
-	if agentTooltipContent != "" {
-		agentTooltipPath := filepath.Join(staticDir, "agent-tooltip.js")
-		if err := os.WriteFile(agentTooltipPath, []byte(agentTooltipContent), 0644); err != nil {
+		if err := os.WriteFile(filepath.Join(staticDir, name), []byte(content), 0644); err != nil {
 			return err
 		}
 	}
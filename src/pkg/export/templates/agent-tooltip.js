@@ -68,14 +68,24 @@
         copyToClipboard(e.target);
     }
 
-    function showTooltip(target) {
-        var agentDetailsStr = target.dataset.agentDetails || '{}';
-        var agentDetails = {};
-
+    /**
+     * Parse the agent list from a target's data-agent-details attribute: an array of
+     * {id, count} objects, already ordered by message count (largest first).
+     * Returns null if the attribute cannot be parsed.
+     */
+    function parseAgentDetails(target) {
         try {
-            agentDetails = JSON.parse(agentDetailsStr);
+            var agents = JSON.parse(target.dataset.agentDetails || '[]');
+            return Array.isArray(agents) ? agents : [];
         } catch (err) {
             console.error('Failed to parse agent details:', err);
+            return null;
+        }
+    }
+
+    function showTooltip(target) {
+        var agents = parseAgentDetails(target);
+        if (!agents) {
             return;
         }
 
@@ -85,19 +95,12 @@
         var html = '<div class="agent-tooltip-header">Agent Message Counts</div>';
         html += '<div class="agent-tooltip-table">';
 
-        // Sort by message count (descending)
-        var agents = Object.entries(agentDetails).sort(function(a, b) {
-            return b[1] - a[1];
-        });
-
         if (agents.length === 0) {
             html += '<div class="agent-tooltip-empty">No agent data available</div>';
         } else {
-            agents.forEach(function(entry) {
-                var agentId = entry[0];
-                var count = entry[1];
-                html += '<div class="agent-tooltip-id">' + escapeHtml(agentId) + '</div>';
-                html += '<div class="agent-tooltip-count">' + count + '</div>';
+            agents.forEach(function(agent) {
+                html += '<div class="agent-tooltip-id">' + escapeHtml(agent.id) + '</div>';
+                html += '<div class="agent-tooltip-count">' + agent.count + '</div>';
             });
         }
 
@@ -158,13 +161,8 @@
     }
 
     function copyToClipboard(target) {
-        var agentDetailsStr = target.dataset.agentDetails || '{}';
-        var agentDetails = {};
-
-        try {
-            agentDetails = JSON.parse(agentDetailsStr);
-        } catch (err) {
-            console.error('Failed to parse agent details:', err);
+        var agents = parseAgentDetails(target);
+        if (!agents) {
             return;
         }
 
@@ -173,15 +171,8 @@
         // Build clipboard text
         var text = 'session: ' + sessionId + '\n---\n';
 
-        // Sort by message count (descending)
-        var agents = Object.entries(agentDetails).sort(function(a, b) {
-            return b[1] - a[1];
-        });
-
-        agents.forEach(function(entry) {
-            var agentId = entry[0];
-            var count = entry[1];
-            text += agentId + '\t' + count + '\n';
+        agents.forEach(function(agent) {
+            text += agent.id + '\t' + agent.count + '\n';
         });
 
         // Copy to clipboard
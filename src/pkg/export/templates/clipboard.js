@@ -1,6 +1,7 @@
 /**
  * Claude History Export - Clipboard Functionality
- * Provides copy-to-clipboard support for agent IDs, file paths, session IDs, and tool IDs.
+ * Provides copy-to-clipboard support for agent IDs, file paths, session IDs, tool IDs,
+ * and whole messages (as markdown, base64-encoded in the button's data-copy-markdown).
  */
 
 /**
@@ -145,14 +146,37 @@ function showCopyToast(message, isError) {
     }, 2000);
 }
 
+/**
+ * Decode a base64 string holding UTF-8 text.
+ * @param {string} encoded - The base64 string
+ * @returns {string} The decoded text, or '' if it is not valid base64
+ */
+function decodeBase64UTF8(encoded) {
+    try {
+        var binary = atob(encoded);
+        var bytes = new Uint8Array(binary.length);
+        for (var i = 0; i < binary.length; i++) {
+            bytes[i] = binary.charCodeAt(i);
+        }
+        return new TextDecoder().decode(bytes);
+    } catch (err) {
+        return '';
+    }
+}
+
 /**
  * Handle click on a copy button.
- * Extracts the text from data-copy-text attribute and copies it.
+ * Copies the text from the data-copy-text attribute, or the decoded data-copy-markdown
+ * attribute of a message's copy-as-markdown button.
  * @param {Event} event - The click event
  */
 function handleCopyClick(event) {
     var button = event.currentTarget;
     var text = button.getAttribute('data-copy-text');
+    var markdown = button.getAttribute('data-copy-markdown');
+    if (markdown !== null) {
+        text = decodeBase64UTF8(markdown);
+    }
     copyToClipboard(text, button);
 
     // Prevent event from bubbling (e.g., to tool-header toggle)
@@ -14,88 +14,172 @@
     var SEARCH_HIGHLIGHT_CLASS = 'search-highlight';
     var SEARCH_MATCH_CLASS = 'search-match';
     var HIDDEN_BY_SEARCH_CLASS = 'hidden-by-search';
+    var HIDDEN_TYPES_SUFFIX = ':hidden-types';
+    var HIDE_TYPE_CLASS_PREFIX = 'hide-type-';
+    var REPLAY_HIDDEN_CLASS = 'replay-hidden';
+    var REPLAY_REVEALED_CLASS = 'replay-revealed';
+    var DEFAULT_REPLAY_DELAY_MS = 1500;
 
     // ===========================================
     // STATE MANAGEMENT
     // ===========================================
 
+    /**
+     * Get localStorage if it is usable. Storage can be missing, disabled, or throw
+     * on access (e.g. file:// pages in some browsers, private mode, quota).
+     * @returns {Storage|null} localStorage, or null when unavailable
+     */
+    function getStorage() {
+        try {
+            var storage = window.localStorage;
+            if (!storage) return null;
+            var probe = STORAGE_KEY + ':probe';
+            storage.setItem(probe, '1');
+            storage.removeItem(probe);
+            return storage;
+        } catch (e) {
+            return null;
+        }
+    }
+
+    /**
+     * Get the storage key for this export. State is scoped per session so that
+     * two exports opened from the same origin don't overwrite each other's state;
+     * pages without a session ID fall back to their path.
+     * @returns {string} The storage key
+     */
+    function getStorageKey() {
+        var sessionId = document.body && document.body.getAttribute('data-session-id');
+        return STORAGE_KEY + ':' + (sessionId || window.location.pathname);
+    }
+
     /**
      * Load saved state from localStorage.
-     * @returns {Object} The saved state or default values
+     * @returns {Object} The saved state, or the default (everything collapsed)
      */
     function loadState() {
-        try {
-            var saved = localStorage.getItem(STORAGE_KEY);
-            if (saved) {
-                return JSON.parse(saved);
+        var storage = getStorage();
+        if (storage) {
+            try {
+                var saved = JSON.parse(storage.getItem(getStorageKey()));
+                if (saved && Array.isArray(saved.expandedIds)) {
+                    return saved;
+                }
+            } catch (e) {
+                // Unreadable state is treated as no state
             }
-        } catch (e) {
-            console.warn('Failed to load controls state:', e);
         }
-        return {
-            collapsedIds: [],
-            allCollapsed: true
-        };
+        return { expandedIds: [] };
     }
 
     /**
-     * Save state to localStorage.
+     * Save state to localStorage. Does nothing when storage is unavailable.
      * @param {Object} state - The state to save
      */
     function saveState(state) {
+        var storage = getStorage();
+        if (!storage) return;
         try {
-            localStorage.setItem(STORAGE_KEY, JSON.stringify(state));
+            storage.setItem(getStorageKey(), JSON.stringify(state));
         } catch (e) {
-            console.warn('Failed to save controls state:', e);
+            // Storage full or blocked: keep working without persistence
         }
     }
 
     /**
-     * Get current collapse state of all collapsible elements.
-     * @returns {Object} State object with collapsed element IDs
+     * Get the tool call containers that can be expanded, keyed by data-tool-id.
+     * @param {HTMLElement|Document} root - Where to look
+     * @returns {Array<HTMLElement>} Tool calls that have a body
      */
-    function getCurrentState() {
-        var collapsedIds = [];
-        var collapsibles = document.querySelectorAll('.tool-body');
-
-        collapsibles.forEach(function(el, index) {
-            if (el.classList.contains('hidden')) {
-                var toolCall = el.closest('.tool-call');
-                var id = toolCall ? toolCall.dataset.toolId : 'tool-' + index;
-                collapsedIds.push(id);
+    function getToolCalls(root) {
+        var calls = [];
+        root.querySelectorAll('.tool-call[data-tool-id]').forEach(function(toolCall) {
+            if (getToolBody(toolCall)) {
+                calls.push(toolCall);
             }
         });
+        return calls;
+    }
 
-        return {
-            collapsedIds: collapsedIds,
-            allCollapsed: collapsedIds.length === collapsibles.length
-        };
+    /**
+     * Get the body of a tool call (its direct .tool-body child).
+     * @param {HTMLElement} toolCall - The tool call container
+     * @returns {HTMLElement|null} The body, if any
+     */
+    function getToolBody(toolCall) {
+        for (var i = 0; i < toolCall.children.length; i++) {
+            if (toolCall.children[i].classList.contains('tool-body')) {
+                return toolCall.children[i];
+            }
+        }
+        return null;
     }
 
     /**
-     * Restore collapse state from saved state.
-     * @param {Object} state - The saved state to restore
+     * Get current collapse state: the IDs of expanded tool calls.
+     * IDs saved earlier for tool calls not on the page (e.g. in subagent content
+     * that hasn't been loaded yet) are kept.
+     * @returns {Object} State object with expanded tool call IDs
      */
-    function restoreState(state) {
-        if (!state) return;
+    function getCurrentState() {
+        var present = {};
+        var expandedIds = [];
+
+        getToolCalls(document).forEach(function(toolCall) {
+            var id = toolCall.dataset.toolId;
+            present[id] = true;
+            if (!getToolBody(toolCall).classList.contains('hidden') && expandedIds.indexOf(id) === -1) {
+                expandedIds.push(id);
+            }
+        });
 
-        if (state.allCollapsed) {
-            collapseAllTools();
-        } else {
-            // Restore individual states
-            expandAllTools();
-            state.collapsedIds.forEach(function(id) {
-                var toolCall = document.querySelector('.tool-call[data-tool-id="' + id + '"]');
-                if (toolCall) {
-                    var body = toolCall.querySelector('.tool-body');
-                    if (body) {
-                        body.classList.add('hidden');
-                    }
-                }
-            });
+        loadState().expandedIds.forEach(function(id) {
+            if (!present[id] && expandedIds.indexOf(id) === -1) {
+                expandedIds.push(id);
+            }
+        });
+
+        return { expandedIds: expandedIds };
+    }
+
+    /**
+     * Expand or collapse a single tool call.
+     * @param {HTMLElement} toolCall - The tool call container
+     * @param {boolean} expanded - Whether to expand it
+     */
+    function setToolExpanded(toolCall, expanded) {
+        var body = getToolBody(toolCall);
+        if (!body) return;
+
+        body.classList.toggle('hidden', !expanded);
+        body.classList.toggle('collapsed', !expanded);
+        toolCall.classList.toggle('collapsed', !expanded);
+
+        var header = body.previousElementSibling;
+        var toggle = header && header.querySelector('.tool-toggle');
+        if (toggle) {
+            toggle.textContent = expanded ? '[-]' : '[+]';
         }
     }
 
+    /**
+     * Re-expand the saved expanded tool calls within a container. Tool calls start
+     * collapsed, so only the expanded ones need restoring.
+     * @param {HTMLElement|Document} root - The container to restore (default: document)
+     */
+    function restoreState(root) {
+        var expanded = {};
+        loadState().expandedIds.forEach(function(id) {
+            expanded[id] = true;
+        });
+
+        getToolCalls(root || document).forEach(function(toolCall) {
+            if (expanded[toolCall.dataset.toolId]) {
+                setToolExpanded(toolCall, true);
+            }
+        });
+    }
+
     // ===========================================
     // EXPAND/COLLAPSE FUNCTIONALITY
     // ===========================================
@@ -109,8 +193,8 @@
             el.classList.remove('hidden');
         });
 
-        // Handle <details> elements
-        var detailsElements = document.querySelectorAll('details');
+        // Handle <details> elements (raw entry inspectors stay closed)
+        var detailsElements = document.querySelectorAll('details:not(.raw-inspector)');
         detailsElements.forEach(function(el) {
             el.open = true;
         });
@@ -125,7 +209,7 @@
         updateAllToggles(true);
 
         // Save state
-        saveState({ collapsedIds: [], allCollapsed: false });
+        saveState(getCurrentState());
     }
 
     /**
@@ -152,8 +236,8 @@
         // Update toggle indicators
         updateAllToggles(false);
 
-        // Save state
-        saveState(getCurrentState());
+        // Save state, forgetting tool calls in content that isn't loaded too
+        saveState({ expandedIds: [] });
     }
 
     /**
@@ -213,6 +297,140 @@
 
     var currentSearchIndex = -1;
     var currentMatches = [];
+    var searchOptions = {
+        caseSensitive: false,
+        wholeWord: false
+    };
+
+    /**
+     * Build a global regular expression for the query using the current search options.
+     * Whole-word mode only anchors query edges that are word characters, so
+     * queries like "foo(" still match.
+     * @param {string} query - The trimmed search query
+     * @returns {RegExp} Regular expression matching the query
+     */
+    function buildSearchRegex(query) {
+        var pattern = query.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+        if (searchOptions.wholeWord) {
+            if (/^\w/.test(query)) pattern = '\\b' + pattern;
+            if (/\w$/.test(query)) pattern = pattern + '\\b';
+        }
+        return new RegExp(pattern, searchOptions.caseSensitive ? 'g' : 'gi');
+    }
+
+    // ===========================================
+    // SEARCH INDEX
+    // ===========================================
+
+    var searchIndex;        // Parsed #search-index data, null without one (undefined until loaded)
+    var searchIndexTerms;   // Terms of searchIndex, for substring lookups
+    var searchIndexRows;    // Position in searchIndex.rows by message UUID
+    var searchIndexScanned; // Positions of rows the index does not fully cover
+
+    /**
+     * Load the search index embedded by the exporter, if the page has one.
+     * @returns {Object|null} The index, or null when missing or unreadable
+     */
+    function getSearchIndex() {
+        if (searchIndex !== undefined) return searchIndex;
+        searchIndex = null;
+
+        var el = document.getElementById('search-index');
+        if (!el) return null;
+        try {
+            var data = JSON.parse(el.textContent);
+            if (!data || !Array.isArray(data.rows) || !data.terms) return null;
+            searchIndexTerms = Object.keys(data.terms);
+            searchIndexRows = {};
+            data.rows.forEach(function(uuid, i) {
+                searchIndexRows[uuid] = i;
+            });
+            searchIndexScanned = {};
+            (data.unindexed || []).forEach(function(i) {
+                searchIndexScanned[i] = true;
+            });
+            searchIndex = data;
+        } catch (e) {
+            searchIndex = null;
+        }
+        return searchIndex;
+    }
+
+    /**
+     * Split text into lowercase runs of letters and digits, like the exporter does.
+     * @param {string} text - Text to split
+     * @returns {string[]} Terms
+     */
+    function splitSearchTerms(text) {
+        return text.toLowerCase().split(/[^\p{L}\p{Nd}]+/u).filter(function(term) {
+            return term !== '';
+        });
+    }
+
+    /**
+     * Check whether the index can narrow a search for a query term. Terms too short to
+     * be indexed, or that could be part of a stop word, might match only words the
+     * index left out.
+     * @param {Object} index - Search index
+     * @param {string} term - Query term
+     * @returns {boolean} True if the term can be looked up
+     */
+    function isIndexableTerm(index, term) {
+        if (Array.from(term).length < index.minTermLength) return false;
+        return !index.stopWords.some(function(word) {
+            return word.indexOf(term) !== -1;
+        });
+    }
+
+    /**
+     * Find the indexed rows that can match a query. A row can match if, for each
+     * query term the index can narrow, it contains an indexed term containing it.
+     * @param {string} query - The trimmed search query
+     * @returns {Object|null} Set of candidate row positions, or null to scan every row
+     */
+    function searchIndexCandidates(query) {
+        var index = getSearchIndex();
+        if (!index) return null;
+
+        var terms = splitSearchTerms(query).filter(function(term) {
+            return isIndexableTerm(index, term);
+        });
+        if (terms.length === 0) return null;
+
+        var candidates = null;
+        terms.forEach(function(term) {
+            var rows = {};
+            searchIndexTerms.forEach(function(indexed) {
+                if (indexed.indexOf(term) === -1) return;
+                index.terms[indexed].forEach(function(i) {
+                    rows[i] = true;
+                });
+            });
+            if (candidates === null) {
+                candidates = rows;
+                return;
+            }
+            Object.keys(candidates).forEach(function(i) {
+                if (!rows[i]) delete candidates[i];
+            });
+        });
+        return candidates;
+    }
+
+    /**
+     * Check whether a message row must be scanned for a search. Rows outside the
+     * index (such as loaded subagent messages) and rows it does not fully cover are
+     * always scanned.
+     * @param {HTMLElement} entry - The message row
+     * @param {Object|null} candidates - Result of searchIndexCandidates
+     * @returns {boolean} True if the row can match
+     */
+    function isSearchCandidate(entry, candidates) {
+        if (!candidates) return true;
+        var i = searchIndexRows[entry.getAttribute('data-uuid')];
+        if (i === undefined || searchIndexScanned[i]) return true;
+        return candidates[i] === true;
+    }
 
     /**
      * Perform search and highlight matches.
@@ -228,22 +446,30 @@
             return 0;
         }
 
-        var lowerQuery = query.toLowerCase().trim();
+        // Search covers every message, so finish a replay in progress first
+        finishReplay();
+
+        var regex = buildSearchRegex(query.trim());
+        var candidates = searchIndexCandidates(query.trim());
         var entries = document.querySelectorAll('.message-row');
         currentMatches = [];
         currentSearchIndex = -1;
 
         entries.forEach(function(entry) {
+            // Messages hidden by type stay hidden and are not counted as matches
+            if (isHiddenByType(entry)) return;
+
             var content = entry.querySelector('.message-content');
             if (!content) return;
 
-            var textContent = content.textContent.toLowerCase();
-            if (textContent.indexOf(lowerQuery) !== -1) {
+            // With a search index, only scan the rows it says can match
+            regex.lastIndex = 0;
+            if (isSearchCandidate(entry, candidates) && regex.test(content.textContent)) {
                 entry.classList.add(SEARCH_MATCH_CLASS);
                 currentMatches.push(entry);
 
                 // Highlight text matches
-                highlightTextInElement(content, query);
+                highlightTextInElement(content, regex);
             } else {
                 entry.classList.add(HIDDEN_BY_SEARCH_CLASS);
             }
@@ -262,9 +488,9 @@
     /**
      * Highlight text matches within an element.
      * @param {HTMLElement} element - The element to search within
-     * @param {string} query - The text to highlight
+     * @param {RegExp} regex - Global regular expression from buildSearchRegex
      */
-    function highlightTextInElement(element, query) {
+    function highlightTextInElement(element, regex) {
         var walker = document.createTreeWalker(
             element,
             NodeFilter.SHOW_TEXT,
@@ -280,28 +506,27 @@
 
         textNodes.forEach(function(textNode) {
             var text = textNode.textContent;
-            var lowerText = text.toLowerCase();
-            var lowerQuery = query.toLowerCase();
-            var index = lowerText.indexOf(lowerQuery);
+            regex.lastIndex = 0;
+            var match = regex.exec(text);
 
-            if (index !== -1) {
+            if (match) {
                 var fragment = document.createDocumentFragment();
                 var lastIndex = 0;
 
-                while (index !== -1) {
+                while (match) {
                     // Add text before match
-                    if (index > lastIndex) {
-                        fragment.appendChild(document.createTextNode(text.substring(lastIndex, index)));
+                    if (match.index > lastIndex) {
+                        fragment.appendChild(document.createTextNode(text.substring(lastIndex, match.index)));
                     }
 
                     // Add highlighted match
                     var mark = document.createElement('mark');
                     mark.className = SEARCH_HIGHLIGHT_CLASS;
-                    mark.textContent = text.substring(index, index + query.length);
+                    mark.textContent = match[0];
                     fragment.appendChild(mark);
 
-                    lastIndex = index + query.length;
-                    index = lowerText.indexOf(lowerQuery, lastIndex);
+                    lastIndex = match.index + match[0].length;
+                    match = regex.exec(text);
                 }
 
                 // Add remaining text
@@ -314,6 +539,22 @@
         });
     }
 
+    /**
+     * Set a search option and re-run the current query.
+     * The query in the search box is kept; the results count is updated by performSearch.
+     * @param {string} name - Option name ('caseSensitive' or 'wholeWord')
+     * @param {boolean} enabled - Whether the option is enabled
+     */
+    function setSearchOption(name, enabled) {
+        if (!searchOptions.hasOwnProperty(name)) return;
+        searchOptions[name] = !!enabled;
+
+        var searchBox = document.getElementById('search-box');
+        if (searchBox && searchBox.value.trim() !== '') {
+            performSearch(searchBox.value);
+        }
+    }
+
     /**
      * Clear all search highlights and visibility states.
      */
@@ -443,6 +684,285 @@
         }
     }
 
+    // ===========================================
+    // MESSAGE TYPE FILTERS
+    // ===========================================
+
+    var hiddenTypes = {};
+
+    /**
+     * Get the entry types currently hidden by the type filter checkboxes.
+     * @returns {Array<string>} Hidden types (message-row classes such as 'system')
+     */
+    function getHiddenTypes() {
+        return Object.keys(hiddenTypes);
+    }
+
+    /**
+     * Check whether a message row is hidden by its type.
+     * @param {HTMLElement} row - A .message-row element
+     * @returns {boolean} True if the row's type is hidden
+     */
+    function isHiddenByType(row) {
+        for (var type in hiddenTypes) {
+            if (row.classList.contains(type)) return true;
+        }
+        return false;
+    }
+
+    /**
+     * Show or hide the messages of one entry type. Hiding is done with a class on
+     * <body>, so rows added later (e.g. loaded subagent content) follow it too.
+     * An active search is re-run so hidden messages drop out of its matches.
+     * @param {string} type - The entry type class (e.g. 'system')
+     * @param {boolean} visible - Whether its messages are shown
+     */
+    function setTypeVisible(type, visible) {
+        if (!type) return;
+        if (visible) {
+            delete hiddenTypes[type];
+        } else {
+            hiddenTypes[type] = true;
+        }
+        document.body.classList.toggle(HIDE_TYPE_CLASS_PREFIX + type, !visible);
+
+        var checkbox = document.querySelector('.type-filter input[data-entry-type="' + type + '"]');
+        if (checkbox) checkbox.checked = visible;
+
+        var searchBox = document.getElementById('search-box');
+        if (searchBox && searchBox.value.trim() !== '') {
+            performSearch(searchBox.value);
+        }
+
+        updateTypeFilterCount();
+        saveHiddenTypes();
+    }
+
+    /**
+     * Update the "Showing X of Y messages" display. It is empty when no type is hidden.
+     */
+    function updateTypeFilterCount() {
+        var countEl = document.querySelector('.type-filter-count');
+        if (!countEl) return;
+
+        var rows = document.querySelectorAll('.message-row');
+        var hidden = 0;
+        rows.forEach(function(row) {
+            if (isHiddenByType(row)) hidden++;
+        });
+
+        countEl.textContent = hidden > 0
+            ? 'Showing ' + (rows.length - hidden) + ' of ' + rows.length + ' messages'
+            : '';
+    }
+
+    /**
+     * Save the hidden types when "Remember" is checked, or forget them otherwise.
+     * Persisting is optional: without the checkbox or storage, filters last for the visit.
+     */
+    function saveHiddenTypes() {
+        var storage = getStorage();
+        if (!storage) return;
+        var remember = document.getElementById('type-filter-remember');
+        try {
+            if (remember && remember.checked) {
+                storage.setItem(getStorageKey() + HIDDEN_TYPES_SUFFIX, JSON.stringify(getHiddenTypes()));
+            } else {
+                storage.removeItem(getStorageKey() + HIDDEN_TYPES_SUFFIX);
+            }
+        } catch (e) {
+            // Storage full or blocked: keep working without persistence
+        }
+    }
+
+    /**
+     * Load the remembered hidden types.
+     * @returns {Array<string>|null} The saved types, or null if none were remembered
+     */
+    function loadHiddenTypes() {
+        var storage = getStorage();
+        if (!storage) return null;
+        try {
+            var saved = JSON.parse(storage.getItem(getStorageKey() + HIDDEN_TYPES_SUFFIX));
+            return Array.isArray(saved) ? saved : null;
+        } catch (e) {
+            return null;
+        }
+    }
+
+    /**
+     * Initialize the type filter checkboxes and restore remembered hidden types.
+     */
+    function initTypeFilters() {
+        document.querySelectorAll('.type-filter input[data-entry-type]').forEach(function(checkbox) {
+            checkbox.addEventListener('change', function() {
+                setTypeVisible(checkbox.dataset.entryType, checkbox.checked);
+            });
+        });
+
+        var remember = document.getElementById('type-filter-remember');
+        if (remember) {
+            remember.addEventListener('change', saveHiddenTypes);
+        }
+
+        var saved = loadHiddenTypes();
+        if (saved) {
+            if (remember) remember.checked = true;
+            saved.forEach(function(type) {
+                setTypeVisible(type, false);
+            });
+        }
+    }
+
+    // ===========================================
+    // REPLAY
+    // ===========================================
+
+    var replayTimer = null;
+    var replayDelayMs = DEFAULT_REPLAY_DELAY_MS;
+
+    /**
+     * Check whether the user asked the system for reduced motion.
+     * @returns {boolean} True if animations should be avoided
+     */
+    function prefersReducedMotion() {
+        return !!(window.matchMedia && window.matchMedia('(prefers-reduced-motion: reduce)').matches);
+    }
+
+    /**
+     * Get the top-level conversation blocks replay has not revealed yet, in page order.
+     * @returns {Array<HTMLElement>} Hidden blocks
+     */
+    function getReplayPending() {
+        var conversation = document.querySelector('.conversation');
+        if (!conversation) return [];
+        return Array.prototype.filter.call(conversation.children, function(el) {
+            return el.classList.contains(REPLAY_HIDDEN_CLASS);
+        });
+    }
+
+    /**
+     * Reveal the next message, with the blocks before it (day separators, gap markers).
+     * Messages hidden by the type filters are revealed without a pause of their own.
+     * @returns {boolean} True if hidden blocks remain
+     */
+    function revealNextMessage() {
+        var pending = getReplayPending();
+        var animate = !prefersReducedMotion();
+        var last = null;
+        for (var i = 0; i < pending.length; i++) {
+            last = pending[i];
+            last.classList.remove(REPLAY_HIDDEN_CLASS);
+            if (animate) last.classList.add(REPLAY_REVEALED_CLASS);
+            if (last.classList.contains('message-row') && !isHiddenByType(last)) break;
+        }
+        if (last) {
+            last.scrollIntoView({ behavior: animate ? 'smooth' : 'auto', block: 'nearest' });
+        }
+        updateReplayProgress();
+        return getReplayPending().length > 0;
+    }
+
+    /**
+     * Show how many messages replay has revealed, or nothing once all are shown.
+     */
+    function updateReplayProgress() {
+        var progress = document.querySelector('.replay-progress');
+        if (!progress) return;
+        var rows = document.querySelectorAll('.conversation > .message-row');
+        var hidden = document.querySelectorAll('.conversation > .message-row.' + REPLAY_HIDDEN_CLASS).length;
+        progress.textContent = hidden > 0 ? (rows.length - hidden) + ' / ' + rows.length : '';
+    }
+
+    /**
+     * Set the Play button's label and pressed state.
+     * @param {boolean} playing - Whether replay is running
+     */
+    function setReplayButton(playing) {
+        var btn = document.getElementById('replay-play-btn');
+        if (!btn) return;
+        btn.textContent = playing ? 'Pause' : 'Play';
+        btn.setAttribute('aria-pressed', playing ? 'true' : 'false');
+    }
+
+    /**
+     * Start or resume revealing messages one at a time.
+     */
+    function playReplay() {
+        if (replayTimer !== null || getReplayPending().length === 0) return;
+        setReplayButton(true);
+
+        function step() {
+            if (revealNextMessage()) {
+                replayTimer = setTimeout(step, replayDelayMs);
+            } else {
+                replayTimer = null;
+                finishReplay();
+            }
+        }
+        replayTimer = setTimeout(step, 0);
+    }
+
+    /**
+     * Pause replay, keeping the messages revealed so far.
+     */
+    function pauseReplay() {
+        if (replayTimer !== null) {
+            clearTimeout(replayTimer);
+            replayTimer = null;
+        }
+        setReplayButton(false);
+    }
+
+    /**
+     * Stop replay and reveal every remaining message at once.
+     */
+    function finishReplay() {
+        pauseReplay();
+        getReplayPending().forEach(function(el) {
+            el.classList.remove(REPLAY_HIDDEN_CLASS);
+        });
+        updateReplayProgress();
+
+        ['replay-play-btn', 'replay-all-btn'].forEach(function(id) {
+            var btn = document.getElementById(id);
+            if (btn) btn.disabled = true;
+        });
+    }
+
+    /**
+     * Set up replay if the export enabled it: the server hides the message rows, and
+     * the other top-level blocks are hidden here so they appear with the next message.
+     */
+    function initReplay() {
+        var playBtn = document.getElementById('replay-play-btn');
+        var conversation = document.querySelector('.conversation');
+        if (!playBtn || !conversation) return;
+
+        var delay = parseInt(playBtn.getAttribute('data-replay-delay'), 10);
+        if (delay > 0) replayDelayMs = delay;
+
+        Array.prototype.forEach.call(conversation.children, function(el) {
+            if (/^(SCRIPT|STYLE|NOSCRIPT)$/.test(el.tagName)) return;
+            el.classList.add(REPLAY_HIDDEN_CLASS);
+        });
+
+        playBtn.addEventListener('click', function() {
+            if (replayTimer !== null) {
+                pauseReplay();
+            } else {
+                playReplay();
+            }
+        });
+
+        var allBtn = document.getElementById('replay-all-btn');
+        if (allBtn) {
+            allBtn.addEventListener('click', finishReplay);
+        }
+
+        updateReplayProgress();
+    }
+
     // ===========================================
     // SMOOTH SCROLL
     // ===========================================
@@ -525,6 +1045,102 @@
         }
     }
 
+    // ===========================================
+    // JUMP BUTTONS
+    // ===========================================
+
+    // Scrolled less than this far down, the page counts as at the top
+    var JUMP_TOP_THRESHOLD = 200;
+
+    /**
+     * Get the last conversation message that is currently shown (not hidden by the
+     * type filters, a search, or replay).
+     * @returns {HTMLElement|null} The last visible message row
+     */
+    function getLatestMessage() {
+        var rows = document.querySelectorAll('.conversation > .message-row');
+        for (var i = rows.length - 1; i >= 0; i--) {
+            if (rows[i].offsetParent !== null) return rows[i];
+        }
+        return null;
+    }
+
+    /**
+     * Scroll to an element and move keyboard focus to it, so the jump buttons work
+     * from the keyboard without leaving focus on a button that is about to hide.
+     * @param {HTMLElement} element - The element to jump to
+     */
+    function jumpToElement(element) {
+        if (!element) return;
+        element.scrollIntoView({
+            behavior: prefersReducedMotion() ? 'auto' : 'smooth',
+            block: 'start'
+        });
+        if (!element.hasAttribute('tabindex')) {
+            element.setAttribute('tabindex', '-1');
+        }
+        element.focus({ preventScroll: true });
+    }
+
+    /**
+     * Scroll to the latest visible message.
+     */
+    function jumpToLatest() {
+        jumpToElement(getLatestMessage());
+    }
+
+    /**
+     * Scroll to the top of the page.
+     */
+    function jumpToTop() {
+        jumpToElement(document.querySelector('.page-header') || document.body);
+    }
+
+    /**
+     * Show the jump buttons unless the page is already at that end: "top" is hidden
+     * near the top of the page, and "latest" once the latest message is on screen.
+     */
+    function updateJumpButtons() {
+        var topBtn = document.getElementById('jump-top-btn');
+        var latestBtn = document.getElementById('jump-latest-btn');
+        if (topBtn) {
+            topBtn.hidden = window.scrollY < JUMP_TOP_THRESHOLD;
+        }
+        if (latestBtn) {
+            var latest = getLatestMessage();
+            latestBtn.hidden = !latest || latest.getBoundingClientRect().top < window.innerHeight;
+        }
+    }
+
+    /**
+     * Initialize the floating jump-to-top and jump-to-latest buttons.
+     */
+    function initJumpButtons() {
+        var topBtn = document.getElementById('jump-top-btn');
+        var latestBtn = document.getElementById('jump-latest-btn');
+        if (!topBtn && !latestBtn) return;
+
+        if (topBtn) topBtn.addEventListener('click', jumpToTop);
+        if (latestBtn) latestBtn.addEventListener('click', jumpToLatest);
+
+        var ticking = false;
+        function scheduleUpdate() {
+            if (ticking) return;
+            ticking = true;
+            window.requestAnimationFrame(function() {
+                updateJumpButtons();
+                ticking = false;
+            });
+        }
+        window.addEventListener('scroll', scheduleUpdate, { passive: true });
+        window.addEventListener('resize', scheduleUpdate);
+        // Filters, searches, and replay change which message is latest
+        document.addEventListener('click', scheduleUpdate);
+        document.addEventListener('input', scheduleUpdate);
+
+        updateJumpButtons();
+    }
+
     // ===========================================
     // SCROLL SHADOW FOR HEADER
     // ===========================================
@@ -605,6 +1221,20 @@
             });
         }
 
+        // Case-sensitive and whole-word toggles
+        [
+            { id: 'search-case-btn', option: 'caseSensitive' },
+            { id: 'search-word-btn', option: 'wholeWord' }
+        ].forEach(function(toggle) {
+            var btn = document.getElementById(toggle.id);
+            if (!btn) return;
+            btn.addEventListener('click', function() {
+                var enabled = btn.getAttribute('aria-pressed') !== 'true';
+                btn.setAttribute('aria-pressed', enabled ? 'true' : 'false');
+                setSearchOption(toggle.option, enabled);
+            });
+        });
+
         // Previous/Next buttons
         var prevBtn = document.getElementById('search-prev-btn');
         if (prevBtn) {
@@ -638,6 +1268,18 @@
         });
     }
 
+    /**
+     * Save the collapse state after a tool header click. Headers may toggle through
+     * their own onclick handler (see toggleTool), which runs before this listener.
+     */
+    function initStateTracking() {
+        document.addEventListener('click', function(e) {
+            if (e.target.closest && e.target.closest('.tool-header')) {
+                saveState(getCurrentState());
+            }
+        });
+    }
+
     /**
      * Initialize all controls functionality.
      */
@@ -654,9 +1296,18 @@
         // Initialize scroll shadow effect
         initScrollShadow();
 
-        // Restore saved state
-        var savedState = loadState();
-        restoreState(savedState);
+        // Floating jump-to-top and jump-to-latest buttons
+        initJumpButtons();
+
+        // Message type checkboxes
+        initTypeFilters();
+
+        // Replay buttons (exports with replay mode only)
+        initReplay();
+
+        // Restore saved state and keep it up to date
+        restoreState(document);
+        initStateTracking();
     }
 
     // ===========================================
@@ -669,15 +1320,24 @@
         collapseAll: collapseAllTools,
         toggleAll: toggleAllTools,
         search: performSearch,
+        setSearchOption: setSearchOption,
         clearSearch: clearSearch,
+        setTypeVisible: setTypeVisible,
+        getHiddenTypes: getHiddenTypes,
         nextMatch: nextMatch,
         prevMatch: prevMatch,
         focusSearch: focusSearchBox,
         scrollTo: smoothScrollToElement,
         expandParents: expandParentSections,
         getState: getCurrentState,
+        restoreState: restoreState,
         loadState: loadState,
-        saveState: saveState
+        saveState: saveState,
+        playReplay: playReplay,
+        pauseReplay: pauseReplay,
+        finishReplay: finishReplay,
+        jumpToLatest: jumpToLatest,
+        jumpToTop: jumpToTop
     };
 
     // Initialize when DOM is ready
@@ -414,6 +414,11 @@
             window.initCopyButtons(container);
         }
 
+        // Re-expand tool calls saved as expanded
+        if (window.ControlsAPI) {
+            window.ControlsAPI.restoreState(container);
+        }
+
         // Initialize nested subagent headers
         initNestedSubagentHeaders(container);
 
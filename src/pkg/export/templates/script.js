@@ -28,6 +28,37 @@ function toggleTool(header) {
     }
 }
 
+/**
+ * Jump between a tool call and its result, expanding collapsed ancestors.
+ * @param {Event} event - The click event
+ * @param {HTMLElement} link - The tool pair link (href="#tool-..." or "#tool-result-...")
+ */
+function jumpToToolPair(event, link) {
+    // Don't let the click toggle the tool header the link sits in
+    event.preventDefault();
+    event.stopPropagation();
+
+    var target = document.getElementById(link.getAttribute('href').slice(1));
+    if (!target) return;
+
+    // Expand any collapsed tool calls containing the target
+    var el = target.parentElement;
+    while (el) {
+        if (el.classList && el.classList.contains('tool-body') && el.classList.contains('hidden')) {
+            el.classList.remove('hidden');
+            el.classList.remove('collapsed');
+            if (el.parentElement) el.parentElement.classList.remove('collapsed');
+        }
+        el = el.parentElement;
+    }
+
+    target.scrollIntoView({ behavior: 'smooth', block: 'center' });
+    target.classList.add('navigation-highlight');
+    setTimeout(function() {
+        target.classList.remove('navigation-highlight');
+    }, 2000);
+}
+
 /**
  * Toggle visibility of a tool overlay (new collapsible style).
  * @param {HTMLElement} header - The tool header element
@@ -103,7 +134,6 @@ function deepDiveAgent(agentId, event) {
 function loadAgent(header) {
     var container = header.nextElementSibling;
     var parent = header.parentElement;
-    var agentId = parent.dataset.agentId;
 
     // Already loaded check
     if (container && container.innerHTML.trim() !== '' && !container.querySelector('.subagent-loading')) {
@@ -113,11 +143,23 @@ function loadAgent(header) {
         return;
     }
 
+    fetchAgentContent(parent, container);
+}
+
+/**
+ * Fetch a subagent's content into its section and open the section.
+ * @param {HTMLElement} parent - The subagent section element
+ * @param {HTMLElement} container - The section's content element
+ * @returns {Promise} Resolves once the content is shown (or the error message)
+ */
+function fetchAgentContent(parent, container) {
+    var agentId = parent.dataset.agentId;
+
     // Show loading state
     container.innerHTML = '<p class="subagent-loading">Loading agent content...</p>';
 
     // Fetch agent HTML
-    fetch('agents/' + agentId + '.html')
+    return fetch('agents/' + agentId + '.html')
         .then(function(response) {
             if (!response.ok) {
                 throw new Error('Failed to load agent');
@@ -130,6 +172,9 @@ function loadAgent(header) {
             container.classList.remove('collapsed');
             // Initialize any tool toggles in the loaded content
             initToolToggles(container);
+            if (window.ControlsAPI) {
+                window.ControlsAPI.restoreState(container);
+            }
         })
         .catch(function(error) {
             container.innerHTML = '<p class="subagent-error">Failed to load agent: ' + error.message + '</p>';
@@ -413,6 +458,123 @@ function initToolOnlyHeaders() {
     });
 }
 
+/**
+ * Open a subagent section, loading its content first if needed.
+ * @param {string} agentId - The agent ID
+ * @returns {Promise} Resolves with the section element, or null if it is not on the page
+ */
+function openAgent(agentId) {
+    var section = document.getElementById('agent-' + agentId);
+    if (!section) {
+        return Promise.resolve(null);
+    }
+    var container = section.querySelector('.subagent-content');
+    if (container && container.innerHTML.trim() !== '' && !container.querySelector('.subagent-loading')) {
+        section.classList.remove('collapsed');
+        container.classList.remove('collapsed');
+        return Promise.resolve(section);
+    }
+    return fetchAgentContent(section, container).then(function() {
+        return section;
+    });
+}
+
+/**
+ * Open the section a sidebar link points to, after the sections it is nested in
+ * (nested sections only exist once their parent is loaded), and scroll to it.
+ * @param {Event} event - The click event
+ */
+function sidebarNavigate(event) {
+    var link = event.currentTarget;
+    var agentId = link.getAttribute('data-sidebar-agent');
+    var sidebar = link.closest('.session-sidebar');
+    if (sidebar) {
+        sidebar.classList.remove('open');
+    }
+    if (!agentId) {
+        return; // Main session: the # link scrolls to the top
+    }
+    event.preventDefault();
+
+    var parents = link.getAttribute('data-agent-parents');
+    var path = (parents ? parents.split(' ') : []).concat([agentId]);
+    path.reduce(function(opened, id) {
+        return opened.then(function() {
+            return openAgent(id);
+        });
+    }, Promise.resolve(null)).then(function(section) {
+        if (section) {
+            section.scrollIntoView({ behavior: 'smooth', block: 'start' });
+        }
+    });
+}
+
+/**
+ * Show or hide the sidebar list on narrow screens.
+ * @param {HTMLElement} button - The sidebar toggle button
+ */
+function toggleSidebar(button) {
+    var sidebar = button.closest('.session-sidebar');
+    var open = sidebar.classList.toggle('open');
+    button.setAttribute('aria-expanded', open ? 'true' : 'false');
+}
+
+/**
+ * Mark the sidebar link of the innermost subagent section at the top of the
+ * viewport (below the sticky header) as active, or the main session link if none.
+ */
+function updateSidebarActive() {
+    var links = document.querySelectorAll('.sidebar-link');
+    var header = document.querySelector('.page-header');
+    var line = (header ? header.offsetHeight : 0) + 10;
+    var active = document.querySelector('.sidebar-main');
+    var activeTop = -Infinity;
+
+    links.forEach(function(link) {
+        var agentId = link.getAttribute('data-sidebar-agent');
+        var section = agentId ? document.getElementById('agent-' + agentId) : null;
+        if (!section) return;
+        var rect = section.getBoundingClientRect();
+        // Nested sections start below their parents, so the latest top is the innermost
+        if (rect.top <= line && rect.bottom > line && rect.top > activeTop) {
+            active = link;
+            activeTop = rect.top;
+        }
+    });
+
+    links.forEach(function(link) {
+        var isActive = link === active;
+        link.classList.toggle('active', isActive);
+        if (isActive) {
+            link.setAttribute('aria-current', 'true');
+        } else {
+            link.removeAttribute('aria-current');
+        }
+    });
+}
+
+/**
+ * Initialize the sidebar links and the active-section highlight.
+ */
+function initSidebar() {
+    if (!document.querySelector('.session-sidebar')) return;
+
+    document.querySelectorAll('.sidebar-link').forEach(function(link) {
+        link.addEventListener('click', sidebarNavigate);
+    });
+
+    var pending = false;
+    window.addEventListener('scroll', function() {
+        if (pending) return;
+        pending = true;
+        window.requestAnimationFrame(function() {
+            pending = false;
+            updateSidebarActive();
+        });
+    });
+    updateSidebarActive();
+}
+
 /**
  * Initialize the page when DOM is ready.
  */
@@ -429,6 +591,9 @@ function init() {
     // Initialize tool-only message headers
     initToolOnlyHeaders();
 
+    // Initialize the session outline sidebar, if any
+    initSidebar();
+
     // Start with tool bodies collapsed
     collapseAll();
 }
@@ -243,6 +243,43 @@ func TestCSSContent_HasPrintStyles(t *testing.T) {
 	}
 }
 
+func TestCSSContent_ToolOutputScrollsInsteadOfWrapping(t *testing.T) {
+	css := GetStyleCSS()
+
+	// Screen: aligned tool output keeps its columns and scrolls sideways
+	screen := cssRuleBody(t, css, "\npre.tool-output,\n.bash-terminal pre.tool-output {")
+	for _, want := range []string{"font-family: var(--font-mono)", "white-space: pre;", "overflow-x: auto"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("tool output rule missing %q:\n%s", want, screen)
+		}
+	}
+
+	// Prose in messages still wraps
+	if prose := cssRuleBody(t, css, "\n.message-content {"); !strings.Contains(prose, "white-space: pre-wrap") {
+		t.Errorf("message content should keep wrapping:\n%s", prose)
+	}
+
+	// Print: nothing is cut off where the page cannot scroll
+	print := css[strings.Index(css, "@media print"):]
+	printed := cssRuleBody(t, print, "    pre.tool-output,\n    .bash-terminal pre.tool-output {")
+	for _, want := range []string{"white-space: pre-wrap", "overflow-x: visible", "font-size: var(--text-xs)"} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("printed tool output rule missing %q:\n%s", want, printed)
+		}
+	}
+}
+
+// cssRuleBody returns the declarations of the first rule in css starting with selector.
+func cssRuleBody(t *testing.T, css, selector string) string {
+	t.Helper()
+	start := strings.Index(css, selector)
+	if start < 0 {
+		t.Fatalf("CSS missing rule %q", selector)
+	}
+	body := css[start+len(selector):]
+	return body[:strings.Index(body, "}")]
+}
+
 func TestJSContent_HasInitFunction(t *testing.T) {
 	js := GetScriptJS()
 
@@ -578,3 +615,27 @@ func TestHTMLFooter_ScriptOrderNavigation(t *testing.T) {
 		t.Error("navigation.js should be loaded after controls.js")
 	}
 }
+
+func TestStaticAsset(t *testing.T) {
+	for _, name := range []string{"style.css", "script.js", "clipboard.js", "controls.js", "navigation.js", "agent-tooltip.js"} {
+		if content, ok := StaticAsset(name); !ok || content == "" {
+			t.Errorf("StaticAsset(%q) should return embedded content", name)
+		}
+	}
+	if _, ok := StaticAsset("../export.go"); ok {
+		t.Error("StaticAsset should reject unknown names")
+	}
+}
+
+func TestAgentTooltipJS_ReadsAgentDetailsArray(t *testing.T) {
+	js, ok := StaticAsset("agent-tooltip.js")
+	if !ok {
+		t.Fatal("agent-tooltip.js should be embedded")
+	}
+	if !strings.Contains(js, "function parseAgentDetails(") || !strings.Contains(js, "agent.id") || !strings.Contains(js, "agent.count") {
+		t.Error("tooltip should read the {id, count} agent details array")
+	}
+	if strings.Contains(js, "Object.entries(agentDetails)") {
+		t.Error("tooltip should use the agent details order as given instead of re-sorting a map")
+	}
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/agent"
+)
+
+// minTimelineBarPercent is the minimum bar width so single-entry agents stay visible.
+const minTimelineBarPercent = 0.5
+
+// agentDurations maps the agents of spans to their durations formatted by
+// formatDuration. Agents without a span, whose timestamps are unknown, are left out
+// rather than shown as lasting no time.
+func agentDurations(spans []agent.AgentSpan) map[string]string {
+	durations := make(map[string]string, len(spans))
+	for _, span := range spans {
+		durations[span.AgentID] = formatDuration(span.Duration())
+	}
+	return durations
+}
+
+// renderAgentTimeline renders subagent activity spans as a horizontal bar chart.
+// Each agent gets its own row, so concurrent agents are stacked rather than overlapping;
+// rows are indented by nesting depth.
+func renderAgentTimeline(spans []agent.AgentSpan, loc localizer) string {
+	if len(spans) == 0 {
+		return ""
+	}
+
+	start, end := spans[0].Start, spans[0].End
+	for _, span := range spans[1:] {
+		if span.Start.Before(start) {
+			start = span.Start
+		}
+		if span.End.After(end) {
+			end = span.End
+		}
+	}
+	total := end.Sub(start)
+
+	var sb strings.Builder
+	sb.WriteString(`<section class="agent-timeline">`)
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf(`  <div class="agent-timeline-header"><span class="agent-timeline-title">Agent Timeline</span><span class="agent-timeline-range">%s – %s (%s)</span></div>`,
+		escapeHTML(start.Format("15:04:05")),
+		escapeHTML(end.Format("15:04:05")),
+		escapeHTML(loc.duration(total))))
+	sb.WriteString("\n")
+
+	for _, span := range spans {
+		left, width := timelineBarPosition(span, start, total)
+		shortID, typeLabel := agent.NormalizeAgentID(span.AgentID)
+
+		label := escapeHTML(shortID)
+		if typeLabel != "" {
+			label += fmt.Sprintf(` <span class="subagent-type">%s</span>`, escapeHTML(typeLabel))
+		}
+		title := fmt.Sprintf("%s: %s – %s (%s, %s entries)",
+			span.AgentID,
+			span.Start.Format("15:04:05"),
+			span.End.Format("15:04:05"),
+			loc.duration(span.Duration()),
+			loc.number(span.EntryCount))
+
+		sb.WriteString(fmt.Sprintf(`  <div class="agent-timeline-row" data-agent-id="%s" style="--timeline-depth: %d">`,
+			escapeHTML(span.AgentID), span.Depth-1))
+		sb.WriteString(fmt.Sprintf(`<span class="agent-timeline-label">%s</span>`, label))
+		sb.WriteString(`<div class="agent-timeline-track">`)
+		sb.WriteString(fmt.Sprintf(`<div class="agent-timeline-bar" style="left: %.2f%%; width: %.2f%%" title="%s"></div>`,
+			left, width, escapeHTML(title)))
+		sb.WriteString("</div></div>\n")
+	}
+
+	sb.WriteString("</section>\n")
+	return sb.String()
+}
+
+// timelineBarPosition returns a span's left offset and width as percentages of the
+// overall timeline. Bars are at least minTimelineBarPercent wide and never overflow.
+func timelineBarPosition(span agent.AgentSpan, start time.Time, total time.Duration) (left, width float64) {
+	if total <= 0 {
+		return 0, 100
+	}
+	left = float64(span.Start.Sub(start)) / float64(total) * 100
+	width = float64(span.Duration()) / float64(total) * 100
+	if width < minTimelineBarPercent {
+		width = minTimelineBarPercent
+	}
+	if left+width > 100 {
+		left = 100 - width
+	}
+	return left, width
+}
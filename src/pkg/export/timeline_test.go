@@ -0,0 +1,130 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func timelineTestSpans() []agent.AgentSpan {
+	base := time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)
+	return []agent.AgentSpan{
+		{AgentID: "a11111111", Depth: 1, Start: base, End: base.Add(60 * time.Second), EntryCount: 10},
+		{AgentID: "aexplore-b2222222", AgentType: "explore", Depth: 2, Start: base.Add(30 * time.Second), End: base.Add(100 * time.Second), EntryCount: 4},
+		{AgentID: "a33333333", Depth: 1, Start: base.Add(50 * time.Second), End: base.Add(50 * time.Second), EntryCount: 1},
+	}
+}
+
+func TestRenderAgentTimeline(t *testing.T) {
+	html := renderAgentTimeline(timelineTestSpans(), localizer{})
+
+	if !strings.Contains(html, `<section class="agent-timeline">`) {
+		t.Fatalf("timeline section missing:\n%s", html)
+	}
+	if !strings.Contains(html, "10:00:00 – 10:01:40 (1m)") {
+		t.Errorf("timeline should show the overall range, got:\n%s", html)
+	}
+
+	// One row per agent so concurrent agents never overlap
+	if got := strings.Count(html, `class="agent-timeline-row"`); got != 3 {
+		t.Errorf("got %d rows, want 3", got)
+	}
+	if !strings.Contains(html, `style="left: 0.00%; width: 60.00%"`) {
+		t.Error("first agent bar should start at 0 and cover 60%")
+	}
+	if !strings.Contains(html, `style="left: 30.00%; width: 70.00%"`) {
+		t.Error("concurrent agent bar should be positioned on its own row")
+	}
+	if !strings.Contains(html, `style="--timeline-depth: 1"`) {
+		t.Error("nested agent row should be indented by depth")
+	}
+	if !strings.Contains(html, `<span class="subagent-type">Explore</span>`) {
+		t.Error("typed agent should show its type label")
+	}
+}
+
+func TestRenderAgentTimeline_SingleEntryVisible(t *testing.T) {
+	html := renderAgentTimeline(timelineTestSpans(), localizer{})
+
+	if !strings.Contains(html, `style="left: 50.00%; width: 0.50%"`) {
+		t.Errorf("single-entry agent should get a minimum-width bar, got:\n%s", html)
+	}
+}
+
+func TestRenderAgentTimeline_SingleInstant(t *testing.T) {
+	ts := time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)
+	html := renderAgentTimeline([]agent.AgentSpan{{AgentID: "a1", Depth: 1, Start: ts, End: ts, EntryCount: 1}}, localizer{})
+
+	if !strings.Contains(html, `style="left: 0.00%; width: 100.00%"`) {
+		t.Errorf("zero-length timeline should render a full-width bar, got:\n%s", html)
+	}
+}
+
+func TestRenderAgentTimeline_Empty(t *testing.T) {
+	if html := renderAgentTimeline(nil, localizer{}); html != "" {
+		t.Errorf("empty timeline should render nothing, got %q", html)
+	}
+}
+
+func TestTimelineBarPosition_NeverOverflows(t *testing.T) {
+	start := time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(100 * time.Second)
+	left, width := timelineBarPosition(agent.AgentSpan{Start: end, End: end}, start, end.Sub(start))
+	if left+width > 100 {
+		t.Errorf("bar overflows: left=%.2f width=%.2f", left, width)
+	}
+}
+
+func TestRenderConversationWithOptions_Timeline(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: []byte(`"hello"`)},
+	}
+
+	without, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(without, `<section class="agent-timeline">`) {
+		t.Error("timeline should not render by default")
+	}
+
+	with, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{Timeline: timelineTestSpans()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	timelineIdx := strings.Index(with, `<section class="agent-timeline">`)
+	conversationIdx := strings.Index(with, `<div class="conversation">`)
+	if timelineIdx < 0 || timelineIdx > conversationIdx {
+		t.Error("timeline should render above the conversation")
+	}
+}
+
+func TestAgentDurations(t *testing.T) {
+	ts := time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)
+	durations := agentDurations([]agent.AgentSpan{
+		{AgentID: "a1", Start: ts, End: ts.Add(2*time.Minute + 5*time.Second)},
+		{AgentID: "a2", Start: ts, End: ts},
+	})
+	if durations["a1"] != "2m" || durations["a2"] != "0s" {
+		t.Errorf("agentDurations() = %v", durations)
+	}
+	if _, ok := durations["unknown"]; ok {
+		t.Error("agents without a span should have no duration")
+	}
+}
+
+func TestRenderSubagentPlaceholder_DurationBadge(t *testing.T) {
+	agentMap := map[string]int{"abc1234": 2}
+	html := renderSubagentPlaceholderWith("abc1234", agentMap, "s1", "", subagentDetails{duration: "2m"}, entryRenderOptions{})
+	if !strings.Contains(html, `(2 entries)</span> <span class="subagent-duration"`) || !strings.Contains(html, ">2m</span>") {
+		t.Errorf("expected a duration badge after the entry count, got: %s", html)
+	}
+
+	// Unknown timestamps leave the badge out instead of showing 0s
+	if html := renderSubagentPlaceholderWith("abc1234", agentMap, "s1", "", subagentDetails{}, entryRenderOptions{}); strings.Contains(html, "subagent-duration") {
+		t.Errorf("expected no duration badge, got: %s", html)
+	}
+}
@@ -0,0 +1,157 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// todoItem is a single entry in a TodoWrite todo list.
+type todoItem struct {
+	Content    string
+	Status     string
+	ActiveForm string
+}
+
+// parseTodoItems extracts the todo list from a TodoWrite tool input.
+// TodoWrite always sends the complete list, so each call is a full snapshot.
+func parseTodoItems(input map[string]any) []todoItem {
+	rawTodos, ok := input["todos"].([]any)
+	if !ok {
+		return nil
+	}
+
+	items := make([]todoItem, 0, len(rawTodos))
+	for _, raw := range rawTodos {
+		todo, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		item := todoItem{}
+		item.Content, _ = todo["content"].(string)
+		item.Status, _ = todo["status"].(string)
+		item.ActiveForm, _ = todo["activeForm"].(string)
+		items = append(items, item)
+	}
+	return items
+}
+
+// isTodoWriteOnly reports whether an entry is an assistant message with no text
+// whose tool calls are all TodoWrite.
+func isTodoWriteOnly(entry models.ConversationEntry) bool {
+	if entry.Type != models.EntryTypeAssistant || strings.TrimSpace(entry.GetTextContent()) != "" {
+		return false
+	}
+	tools := entry.ExtractToolCalls()
+	if len(tools) == 0 {
+		return false
+	}
+	for _, tool := range tools {
+		if tool.Name != "TodoWrite" {
+			return false
+		}
+	}
+	return true
+}
+
+// renderTodoEvolution renders a run of consecutive TodoWrite calls as a single checklist
+// showing the final state, with a collapsible history of every update.
+func renderTodoEvolution(calls []models.ToolUse) string {
+	return renderTodoEvolutionWith(models.ConversationEntry{}, calls, entryRenderOptions{})
+}
+
+// renderTodoEvolutionWith renders a TodoWrite checklist like renderTodoEvolution, as the
+// row of entry, the first call of the run: the row carries its UUID and timestamp, so
+// permalinks and replay reach it like any other message. The avatar, replay, and
+// --highlight settings come from ro.opts; with NoJS set, the history collapses as a
+// <details> element (see ExportOptions.NoJS).
+func renderTodoEvolutionWith(entry models.ConversationEntry, calls []models.ToolUse, ro entryRenderOptions) string {
+	if len(calls) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	final := parseTodoItems(calls[len(calls)-1].Input)
+
+	rowClass := ""
+	if ro.opts.ReplayMode && !ro.opts.NoJS {
+		rowClass = " " + replayHiddenClass
+	}
+	sb.WriteString(fmt.Sprintf(`<div class="message-row assistant tool-only todo-evolution%s" data-uuid="%s" data-tool-id="%s">`,
+		rowClass, escapeHTML(entry.UUID), escapeHTML(calls[len(calls)-1].ID)))
+	sb.WriteString("\n")
+	sb.WriteString("  " + renderAvatar(models.EntryTypeAssistant, ro.opts.Avatars))
+	sb.WriteString("\n")
+	sb.WriteString(`  <div class="message-bubble">`)
+	sb.WriteString("\n")
+	sb.WriteString(`    <div class="message-header"><span class="role tool-only-label">TOOL: TodoWrite</span>`)
+	sb.WriteString(fmt.Sprintf(`<span class="tool-summary-inline">%s</span>`, escapeHTML(summarizeTodos(final))))
+	if entry.Timestamp != "" {
+		sb.WriteString(renderTimestampSpan(entry.Timestamp, formatTimestampReadable(entry.Timestamp), ro))
+	}
+	sb.WriteString("</div>\n")
+	sb.WriteString(`    <div class="message-content">`)
+	sb.WriteString(highlightHTML(renderTodoList(final), ro.highlight))
+
+	// History of all updates, collapsed by default
+	if len(calls) > 1 {
+		header := fmt.Sprintf(`<span class="tool-summary"><span class="todo-updates-badge">%d updates</span></span><span class="chevron down">▼</span>`, len(calls))
+		if ro.opts.NoJS {
+			sb.WriteString(`<details class="tool-call todo-history">`)
+			sb.WriteString(`<summary class="tool-header">` + header + `</summary>`)
+			sb.WriteString(`<div class="tool-body">`)
+		} else {
+			sb.WriteString(`<div class="tool-call collapsible collapsed todo-history">`)
+			sb.WriteString(`<div class="tool-header collapsible-trigger" onclick="toggleTool(this)">` + header + `</div>`)
+			sb.WriteString(`<div class="tool-body hidden collapsible-content collapsed">`)
+		}
+		for i, call := range calls {
+			sb.WriteString(fmt.Sprintf(`<div class="todo-snapshot"><div class="todo-snapshot-label">Update %d</div>`, i+1))
+			sb.WriteString(highlightHTML(renderTodoList(parseTodoItems(call.Input)), ro.highlight))
+			sb.WriteString(`</div>`)
+		}
+		if ro.opts.NoJS {
+			sb.WriteString(`</div></details>`)
+		} else {
+			sb.WriteString(`</div></div>`)
+		}
+	}
+
+	sb.WriteString("</div>\n")   // Close message-content
+	sb.WriteString("  </div>\n") // Close message-bubble
+	sb.WriteString("</div>\n")   // Close message-row
+
+	return sb.String()
+}
+
+// renderTodoList renders todo items as a read-only checklist.
+func renderTodoList(items []todoItem) string {
+	var sb strings.Builder
+	sb.WriteString(`<ul class="todo-list">`)
+	for _, item := range items {
+		status := item.Status
+		if status == "" {
+			status = "pending"
+		}
+		checked := ""
+		if status == "completed" {
+			checked = " checked"
+		}
+		sb.WriteString(fmt.Sprintf(`<li class="todo-item todo-%s"><input type="checkbox" disabled%s> %s</li>`,
+			escapeHTML(status), checked, escapeHTML(item.Content)))
+	}
+	sb.WriteString(`</ul>`)
+	return sb.String()
+}
+
+// summarizeTodos returns a short progress summary like "3/5 completed".
+func summarizeTodos(items []todoItem) string {
+	completed := 0
+	for _, item := range items {
+		if item.Status == "completed" {
+			completed++
+		}
+	}
+	return fmt.Sprintf("%d/%d completed", completed, len(items))
+}
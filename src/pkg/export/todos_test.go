@@ -0,0 +1,185 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// todoWriteEntry builds an assistant entry with a single TodoWrite call.
+func todoWriteEntry(uuid, toolID, todosJSON string) models.ConversationEntry {
+	return models.ConversationEntry{
+		UUID:      uuid,
+		Type:      models.EntryTypeAssistant,
+		Timestamp: "2026-01-01T10:00:00Z",
+		Message: json.RawMessage(fmt.Sprintf(
+			`{"role":"assistant","content":[{"type":"tool_use","id":"%s","name":"TodoWrite","input":{"todos":%s}}]}`,
+			toolID, todosJSON)),
+	}
+}
+
+func TestParseTodoItems(t *testing.T) {
+	input := map[string]any{
+		"todos": []any{
+			map[string]any{"content": "Write tests", "status": "completed", "activeForm": "Writing tests"},
+			map[string]any{"content": "Ship it", "status": "pending"},
+			"not-a-todo",
+		},
+	}
+
+	items := parseTodoItems(input)
+	if len(items) != 2 {
+		t.Fatalf("len(items) = %d, want 2", len(items))
+	}
+	if items[0].Content != "Write tests" || items[0].Status != "completed" || items[0].ActiveForm != "Writing tests" {
+		t.Errorf("unexpected first item: %+v", items[0])
+	}
+	if parseTodoItems(map[string]any{}) != nil {
+		t.Error("missing todos should return nil")
+	}
+}
+
+func TestIsTodoWriteOnly(t *testing.T) {
+	if !isTodoWriteOnly(todoWriteEntry("a", "t1", `[]`)) {
+		t.Error("TodoWrite-only entry should be detected")
+	}
+
+	withText := models.ConversationEntry{
+		Type:    models.EntryTypeAssistant,
+		Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Updating todos"},{"type":"tool_use","id":"t1","name":"TodoWrite","input":{}}]}`),
+	}
+	if isTodoWriteOnly(withText) {
+		t.Error("entry with text should not be TodoWrite-only")
+	}
+
+	mixed := models.ConversationEntry{
+		Type:    models.EntryTypeAssistant,
+		Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"TodoWrite","input":{}},{"type":"tool_use","id":"t2","name":"Bash","input":{}}]}`),
+	}
+	if isTodoWriteOnly(mixed) {
+		t.Error("entry with other tools should not be TodoWrite-only")
+	}
+}
+
+func TestRenderTodoEvolution_FinalState(t *testing.T) {
+	calls := []models.ToolUse{
+		{ID: "t1", Name: "TodoWrite", Input: map[string]any{"todos": []any{
+			map[string]any{"content": "Step A", "status": "in_progress"},
+			map[string]any{"content": "Step B", "status": "pending"},
+		}}},
+		{ID: "t2", Name: "TodoWrite", Input: map[string]any{"todos": []any{
+			map[string]any{"content": "Step A", "status": "completed"},
+			map[string]any{"content": "Step B", "status": "completed"},
+			map[string]any{"content": "Step C", "status": "pending"},
+		}}},
+	}
+
+	html := renderTodoEvolution(calls)
+
+	if !strings.Contains(html, "2/3 completed") {
+		t.Errorf("summary should reflect final state, got:\n%s", html)
+	}
+	if !strings.Contains(html, "2 updates") {
+		t.Error("expected updates badge")
+	}
+	// Final list is rendered before the history
+	finalList := html[:strings.Index(html, "todo-history")]
+	if !strings.Contains(finalList, `<li class="todo-item todo-completed"><input type="checkbox" disabled checked> Step A</li>`) {
+		t.Errorf("final state should mark Step A completed, got:\n%s", finalList)
+	}
+	if !strings.Contains(finalList, "Step C") {
+		t.Error("final state should include items added later")
+	}
+	if strings.Count(html, "todo-snapshot-label") != 2 {
+		t.Error("history should contain one snapshot per update")
+	}
+}
+
+func TestRenderTodoEvolution_EscapesContent(t *testing.T) {
+	calls := []models.ToolUse{{ID: "t1", Name: "TodoWrite", Input: map[string]any{"todos": []any{
+		map[string]any{"content": "<script>x</script>", "status": "pending"},
+	}}}}
+	html := renderTodoEvolution(calls)
+	if strings.Contains(html, "<script>") {
+		t.Error("todo content should be escaped")
+	}
+}
+
+func TestRenderConversation_CollapsesConsecutiveTodoWrites(t *testing.T) {
+	entries := []models.ConversationEntry{
+		todoWriteEntry("a1", "t1", `[{"content":"A","status":"pending"}]`),
+		todoWriteEntry("a2", "t2", `[{"content":"A","status":"in_progress"}]`),
+		todoWriteEntry("a3", "t3", `[{"content":"A","status":"completed"}]`),
+	}
+
+	html, err := RenderConversation(entries, nil)
+	if err != nil {
+		t.Fatalf("RenderConversation() error = %v", err)
+	}
+	if strings.Count(html, `class="message-row assistant tool-only todo-evolution"`) != 1 {
+		t.Errorf("expected a single todo evolution block")
+	}
+	if !strings.Contains(html, "3 updates") {
+		t.Error("expected 3 updates badge")
+	}
+	if strings.Contains(html, `data-uuid="a2"`) || strings.Contains(html, `data-uuid="a3"`) {
+		t.Error("individual TodoWrite entries should not be rendered separately")
+	}
+}
+
+func TestRenderConversation_TodoEvolutionIsAnchored(t *testing.T) {
+	entries := []models.ConversationEntry{
+		todoWriteEntry("a1", "t1", `[{"content":"Write docs","status":"pending"}]`),
+		todoWriteEntry("a2", "t2", `[{"content":"Write docs","status":"completed"}]`),
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{ReplayMode: true, Highlight: "docs"})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if !strings.Contains(html, `todo-evolution replay-hidden" data-uuid="a1"`) {
+		t.Errorf("todo block should carry the first call's UUID and start hidden for replay:\n%s", html)
+	}
+	if !strings.Contains(html, `<span class="timestamp">`) {
+		t.Error("todo block should show the first call's timestamp")
+	}
+	if !strings.Contains(html, `<mark class="export-highlight">docs</mark>`) {
+		t.Error("todo items should be highlighted")
+	}
+
+	blocks := renderConversationBlocksWith(entries, entries, nil, &SessionStats{}, ExportOptions{}, false)
+	if len(blocks) != 1 || blocks[0].Kind != BlockTodos || blocks[0].UUID != "a1" || blocks[0].Timestamp != entries[0].Timestamp {
+		t.Errorf("todo block = %+v, want one BlockTodos with the first call's UUID and timestamp", blocks)
+	}
+}
+
+func TestRenderConversation_DoesNotMergeNonConsecutiveTodoWrites(t *testing.T) {
+	work := models.ConversationEntry{
+		UUID:    "work",
+		Type:    models.EntryTypeAssistant,
+		Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"b1","name":"Bash","input":{"command":"make"}}]}`),
+	}
+	entries := []models.ConversationEntry{
+		todoWriteEntry("a1", "t1", `[{"content":"A","status":"pending"}]`),
+		todoWriteEntry("a2", "t2", `[{"content":"A","status":"in_progress"}]`),
+		work,
+		todoWriteEntry("a3", "t3", `[{"content":"A","status":"completed"}]`),
+	}
+
+	html, err := RenderConversation(entries, nil)
+	if err != nil {
+		t.Fatalf("RenderConversation() error = %v", err)
+	}
+	if strings.Count(html, "todo-evolution") != 1 {
+		t.Errorf("only the first run should be collapsed")
+	}
+	if !strings.Contains(html, `data-uuid="a3"`) {
+		t.Error("a lone TodoWrite after real work should render normally")
+	}
+	if strings.Index(html, "todo-evolution") > strings.Index(html, `data-uuid="work"`) {
+		t.Error("collapsed run should appear before the interleaved work")
+	}
+}
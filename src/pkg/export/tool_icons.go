@@ -0,0 +1,32 @@
+package export
+
+// GenericToolIcon is shown before the header of a tool call with no icon of its own.
+const GenericToolIcon = "🔧"
+
+// DefaultToolIcons maps tool names to the icon shown before their tool call headers.
+// ExportOptions.ToolIcons adds to and overrides these.
+var DefaultToolIcons = map[string]string{
+	"Bash":  "🖥",
+	"Read":  "📖",
+	"Edit":  "✏️",
+	"Write": "📝",
+	"Grep":  "🔍",
+	"Glob":  "🗂",
+	"Task":  "🤖",
+}
+
+// toolIcon returns the icon for a tool call header: the tool's entry in opts.ToolIcons,
+// then in DefaultToolIcons, then GenericToolIcon. An entry mapping a tool to "" shows no
+// icon for it, and opts.NoToolIcons shows none at all.
+func toolIcon(name string, opts ExportOptions) string {
+	if opts.NoToolIcons {
+		return ""
+	}
+	if icon, ok := opts.ToolIcons[name]; ok {
+		return icon
+	}
+	if icon, ok := DefaultToolIcons[name]; ok {
+		return icon
+	}
+	return GenericToolIcon
+}
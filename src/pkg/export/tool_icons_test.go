@@ -0,0 +1,84 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestToolIcon(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		opts ExportOptions
+		want string
+	}{
+		{"default", "Read", ExportOptions{}, "📖"},
+		{"unknown tool", "mcp__fetch", ExportOptions{}, GenericToolIcon},
+		{"custom", "mcp__fetch", ExportOptions{ToolIcons: map[string]string{"mcp__fetch": "🌐"}}, "🌐"},
+		{"override default", "Bash", ExportOptions{ToolIcons: map[string]string{"Bash": "$"}}, "$"},
+		{"custom keeps other defaults", "Grep", ExportOptions{ToolIcons: map[string]string{"Bash": "$"}}, "🔍"},
+		{"disabled for one tool", "Read", ExportOptions{ToolIcons: map[string]string{"Read": ""}}, ""},
+		{"no icons", "Read", ExportOptions{NoToolIcons: true, ToolIcons: map[string]string{"Read": "R"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toolIcon(tt.tool, tt.opts); got != tt.want {
+				t.Errorf("toolIcon(%q) = %q, want %q", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
+
+// toolCallEntries returns a single assistant entry calling the named tool.
+func toolCallEntries(name string) []models.ConversationEntry {
+	return []models.ConversationEntry{{
+		UUID:      "a1",
+		Type:      models.EntryTypeAssistant,
+		Timestamp: "2026-02-01T10:00:00Z",
+		Message:   json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"` + name + `","input":{"file_path":"/tmp/x.go","command":"ls"}}]}`),
+	}}
+}
+
+func TestRenderConversation_ToolIcons(t *testing.T) {
+	for _, tool := range []string{"Read", "Bash", "mcp__fetch"} {
+		html, err := RenderConversationWithOptions(toolCallEntries(tool), nil, nil, ExportOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `<span class="tool-summary"><span class="tool-icon" aria-hidden="true">` + toolIcon(tool, ExportOptions{}) + `</span>[` + tool + `]`
+		if !strings.Contains(html, want) {
+			t.Errorf("%s header should start with its icon, want %q", tool, want)
+		}
+	}
+}
+
+func TestRenderConversation_ToolIconsEscaped(t *testing.T) {
+	opts := ExportOptions{ToolIcons: map[string]string{"Read": `<img src=x onerror="alert(1)">`}}
+	html, err := RenderConversationWithOptions(toolCallEntries("Read"), nil, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, "<img src=x") {
+		t.Error("icon markup should be escaped")
+	}
+	if !strings.Contains(html, `&lt;img src=x onerror=&#34;alert(1)&#34;&gt;</span>[Read]`) {
+		t.Error("escaped icon should lead the header")
+	}
+}
+
+func TestRenderConversation_NoToolIcons(t *testing.T) {
+	html, err := RenderConversationWithOptions(toolCallEntries("Read"), nil, nil, ExportOptions{NoToolIcons: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, `class="tool-icon"`) {
+		t.Error("NoToolIcons should omit the icons")
+	}
+	if !strings.Contains(html, `<span class="tool-summary">[Read] /tmp/x.go`) {
+		t.Error("header should still show the summary")
+	}
+}
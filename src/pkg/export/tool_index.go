@@ -0,0 +1,97 @@
+package export
+
+import (
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// ToolIndex caches the tool call IDs and tool results parsed from a session's entries,
+// so pages rendered repeatedly from the same session (e.g. by the serve command) skip
+// re-parsing every message. Set it as ExportOptions.ToolIndex.
+//
+// The cache is keyed by entry UUID, so it stays correct for any subset of the entries
+// it was built from: rendering a filtered slice only sees the calls and results of
+// the entries in that slice. Entries it does not know (no UUID, a UUID shared by
+// several entries, or not in the indexed session) are parsed as usual.
+//
+// A ToolIndex is read-only after NewToolIndex and safe for concurrent use.
+type ToolIndex struct {
+	entries map[string]indexedTools // Entry UUID -> parsed tools
+}
+
+// indexedTools holds the parsed tools of one entry.
+type indexedTools struct {
+	callIDs []string
+	results []models.ToolResult
+}
+
+// NewToolIndex parses the tool calls and results of entries once.
+func NewToolIndex(entries []models.ConversationEntry) *ToolIndex {
+	ix := &ToolIndex{entries: make(map[string]indexedTools, len(entries))}
+	ambiguous := make(map[string]bool)
+	for i := range entries {
+		entry := &entries[i]
+		if entry.UUID == "" || ambiguous[entry.UUID] {
+			continue
+		}
+		if _, dup := ix.entries[entry.UUID]; dup {
+			// Two entries share a UUID: neither can be looked up reliably
+			delete(ix.entries, entry.UUID)
+			ambiguous[entry.UUID] = true
+			continue
+		}
+
+		var tools indexedTools
+		for _, call := range entry.ExtractToolCalls() {
+			tools.callIDs = append(tools.callIDs, call.ID)
+		}
+		tools.results = entry.ExtractToolResults()
+		ix.entries[entry.UUID] = tools
+	}
+	return ix
+}
+
+// tools returns the parsed tools of entry, from the index when it has them.
+func (ix *ToolIndex) tools(entry *models.ConversationEntry) indexedTools {
+	if ix != nil && entry.UUID != "" {
+		if tools, ok := ix.entries[entry.UUID]; ok {
+			return tools
+		}
+	}
+	var tools indexedTools
+	for _, call := range entry.ExtractToolCalls() {
+		tools.callIDs = append(tools.callIDs, call.ID)
+	}
+	tools.results = entry.ExtractToolResults()
+	return tools
+}
+
+// resultsMap is buildToolResultsMap using the index. A nil index parses every entry.
+func (ix *ToolIndex) resultsMap(entries []models.ConversationEntry) map[string]models.ToolResult {
+	if ix == nil {
+		return buildToolResultsMap(entries)
+	}
+	result := make(map[string]models.ToolResult)
+	for i := range entries {
+		if entries[i].Type != models.EntryTypeUser {
+			continue
+		}
+		for _, r := range ix.tools(&entries[i]).results {
+			result[r.ToolUseID] = r
+		}
+	}
+	return result
+}
+
+// callIDSet is buildToolCallIDSet using the index. A nil index parses every entry.
+func (ix *ToolIndex) callIDSet(entries []models.ConversationEntry) map[string]bool {
+	if ix == nil {
+		return buildToolCallIDSet(entries)
+	}
+	ids := make(map[string]bool)
+	for i := range entries {
+		for _, id := range ix.tools(&entries[i]).callIDs {
+			ids[id] = true
+		}
+	}
+	return ids
+}
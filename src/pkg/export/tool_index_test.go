@@ -0,0 +1,138 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// toolSessionEntries builds a session of n tool round trips: an assistant tool call
+// followed by the user entry carrying its result.
+func toolSessionEntries(n int) []models.ConversationEntry {
+	entries := make([]models.ConversationEntry, 0, 2*n)
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("toolu_%04d", i)
+		entries = append(entries,
+			models.ConversationEntry{
+				UUID:      fmt.Sprintf("call-%d", i),
+				Type:      models.EntryTypeAssistant,
+				Timestamp: "2026-02-01T10:00:00Z",
+				Message: json.RawMessage(fmt.Sprintf(`{"role":"assistant","content":[
+					{"type":"text","text":"Running step %d"},
+					{"type":"tool_use","id":%q,"name":"Bash","input":{"command":"echo %d"}}]}`, i, id, i)),
+			},
+			models.ConversationEntry{
+				UUID:      fmt.Sprintf("result-%d", i),
+				Type:      models.EntryTypeUser,
+				Timestamp: "2026-02-01T10:00:01Z",
+				Message: json.RawMessage(fmt.Sprintf(`{"role":"user","content":[
+					{"type":"tool_result","tool_use_id":%q,"content":"output %d"}]}`, id, i)),
+			},
+		)
+	}
+	return entries
+}
+
+func TestToolIndex_MatchesParsing(t *testing.T) {
+	entries := toolSessionEntries(5)
+	ix := NewToolIndex(entries)
+
+	if got, want := ix.resultsMap(entries), buildToolResultsMap(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("resultsMap() = %v, want %v", got, want)
+	}
+	if got, want := ix.callIDSet(entries), buildToolCallIDSet(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("callIDSet() = %v, want %v", got, want)
+	}
+}
+
+func TestToolIndex_FilteredEntries(t *testing.T) {
+	entries := toolSessionEntries(4)
+	ix := NewToolIndex(entries)
+
+	// Keep the first round trip and only the call of the second
+	filtered := []models.ConversationEntry{entries[0], entries[1], entries[2]}
+
+	results := ix.resultsMap(filtered)
+	if len(results) != 1 {
+		t.Fatalf("resultsMap() returned %d results, want 1: %v", len(results), results)
+	}
+	if _, ok := results["toolu_0000"]; !ok {
+		t.Error("resultsMap() should contain the result of the kept round trip")
+	}
+
+	ids := ix.callIDSet(filtered)
+	if want := map[string]bool{"toolu_0000": true, "toolu_0001": true}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("callIDSet() = %v, want %v", ids, want)
+	}
+}
+
+func TestToolIndex_UnknownAndDuplicateUUIDs(t *testing.T) {
+	entries := toolSessionEntries(2)
+	ix := NewToolIndex(entries)
+
+	// An entry the index never saw, sharing no UUID with it
+	extra := toolSessionEntries(3)[5]
+	extra.UUID = ""
+	if results := ix.resultsMap([]models.ConversationEntry{extra}); len(results) != 1 || results["toolu_0002"].Content != "output 2" {
+		t.Errorf("unindexed entry should be parsed, got %v", results)
+	}
+
+	// Two entries with the same UUID but different results
+	dup := toolSessionEntries(2)
+	dup[3].UUID = dup[1].UUID
+	ix = NewToolIndex(dup)
+	if got, want := ix.resultsMap(dup), buildToolResultsMap(dup); !reflect.DeepEqual(got, want) {
+		t.Errorf("resultsMap() with duplicate UUIDs = %v, want %v", got, want)
+	}
+}
+
+func TestToolIndex_Nil(t *testing.T) {
+	entries := toolSessionEntries(3)
+	var ix *ToolIndex
+
+	if got, want := ix.resultsMap(entries), buildToolResultsMap(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("nil resultsMap() = %v, want %v", got, want)
+	}
+	if got, want := ix.callIDSet(entries), buildToolCallIDSet(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("nil callIDSet() = %v, want %v", got, want)
+	}
+}
+
+func TestRenderConversation_ToolIndexSameOutput(t *testing.T) {
+	entries := toolSessionEntries(10)
+
+	plain, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexed, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{ToolIndex: NewToolIndex(entries)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain != indexed {
+		t.Error("rendering with a ToolIndex should not change the output")
+	}
+}
+
+func BenchmarkToolMaps(b *testing.B) {
+	entries := toolSessionEntries(5000)
+
+	b.Run("parse", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = buildToolResultsMap(entries)
+			_ = buildToolCallIDSet(entries)
+		}
+	})
+
+	b.Run("index", func(b *testing.B) {
+		ix := NewToolIndex(entries)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = ix.resultsMap(entries)
+			_ = ix.callIDSet(entries)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// largeToolInputBytes is the size above which a string in a tool call's input, such as
+// the content of a Write, is taken out of the input JSON and shown in its own collapsed
+// section.
+const largeToolInputBytes = 2048
+
+// largeToolInputField is a string taken out of a tool input by collapseLargeInputStrings.
+type largeToolInputField struct {
+	Path  string // Dotted path of the value in the input, e.g. "content" or "edits.0.new_string"
+	Value string
+}
+
+// collapseLargeInputStrings returns a copy of v with every string longer than
+// largeToolInputBytes replaced by a short placeholder naming its size, and the replaced
+// strings in the order their paths sort. path is the dotted path of v in the input.
+func collapseLargeInputStrings(v any, path string, fields *[]largeToolInputField) any {
+	switch value := v.(type) {
+	case string:
+		if len(value) <= largeToolInputBytes {
+			return value
+		}
+		*fields = append(*fields, largeToolInputField{Path: path, Value: value})
+		return fmt.Sprintf("[%d bytes, shown below]", len(value))
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make(map[string]any, len(value))
+		for _, k := range keys {
+			out[k] = collapseLargeInputStrings(value[k], joinInputPath(path, k), fields)
+		}
+		return out
+	case []any:
+		out := make([]any, len(value))
+		for i, item := range value {
+			out[i] = collapseLargeInputStrings(item, joinInputPath(path, strconv.Itoa(i)), fields)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// joinInputPath appends key to a dotted tool input path.
+func joinInputPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// renderToolInput renders the input of a tool call as indented JSON. Large strings
+// (see largeToolInputBytes) are replaced in the JSON by a placeholder, so it stays valid
+// and short, and follow it in collapsed "content (N bytes)" sections with a copy button
+// for the full text. Inputs without large strings render as a single block, as before.
+func renderToolInput(input map[string]any) string {
+	var fields []largeToolInputField
+	summary, _ := collapseLargeInputStrings(input, "", &fields).(map[string]any)
+	if len(fields) == 0 {
+		return fmt.Sprintf(`    <pre class="tool-input">%s</pre>`, escapeHTML(formatToolInput(input))) + "\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`    <pre class="tool-input">%s</pre>`, escapeHTML(formatToolInput(summary))))
+	sb.WriteString("\n")
+	for _, field := range fields {
+		size := strconv.Itoa(len(field.Value))
+		sb.WriteString(`    <details class="tool-input-field" data-field="` + escapeHTML(field.Path) + `" data-bytes="` + size + `">`)
+		sb.WriteString(`<summary class="tool-input-field-summary">` + escapeHTML(field.Path) + ` (` + size + ` bytes)</summary>`)
+		sb.WriteString(`<div class="code-block"><div class="code-header">`)
+		sb.WriteString(`<span class="language-badge">` + escapeHTML(field.Path) + `</span>`)
+		sb.WriteString(`<button class="copy-code-btn" onclick="copyCode(this)" title="Copy ` + escapeHTML(field.Path) + `">Copy</button>`)
+		sb.WriteString(`</div><pre class="code-content"><code>` + escapeHTML(field.Value) + `</code></pre></div>`)
+		sb.WriteString("</details>\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,74 @@
+package export
+
+import (
+	"encoding/json"
+	"html"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestRenderToolInput_SmallInputUnchanged(t *testing.T) {
+	input := map[string]any{"file_path": "/a/b.go", "content": "package main\n"}
+	want := `    <pre class="tool-input">` + escapeHTML(formatToolInput(input)) + "</pre>\n"
+	if got := renderToolInput(input); got != want {
+		t.Errorf("renderToolInput() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderToolInput_CollapsesLargeStrings(t *testing.T) {
+	content := strings.Repeat("x <y> & z\n", 300) // 3000 bytes
+	input := map[string]any{
+		"file_path": "/a/b.go",
+		"content":   content,
+		"edits":     []any{map[string]any{"new_string": content, "old_string": "short"}},
+	}
+	got := renderToolInput(input)
+
+	// The summary JSON stays valid and keeps the small fields
+	m := regexp.MustCompile(`(?s)<pre class="tool-input">(.*?)</pre>`).FindStringSubmatch(got)
+	if m == nil {
+		t.Fatalf("no tool-input block in:\n%s", got)
+	}
+	summary := html.UnescapeString(m[1])
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(summary), &parsed); err != nil {
+		t.Fatalf("summary is not valid JSON: %v\n%s", err, summary)
+	}
+	if parsed["file_path"] != "/a/b.go" || parsed["content"] != "[3000 bytes, shown below]" {
+		t.Errorf("summary = %v", parsed)
+	}
+	if strings.Contains(summary, "x <y>") {
+		t.Error("summary should not contain the large string")
+	}
+
+	// Each large string gets a collapsed section with the full, escaped text
+	for _, path := range []string{"content", "edits.0.new_string"} {
+		if !strings.Contains(got, `<details class="tool-input-field" data-field="`+path+`" data-bytes="3000">`) ||
+			!strings.Contains(got, `<summary class="tool-input-field-summary">`+path+` (3000 bytes)</summary>`) {
+			t.Errorf("missing collapsed section for %s", path)
+		}
+	}
+	if strings.Count(got, escapeHTML(content)) != 2 {
+		t.Error("each collapsed section should hold the full content")
+	}
+	if strings.Count(got, `onclick="copyCode(this)"`) != 2 {
+		t.Error("each collapsed section should have a copy button")
+	}
+	if strings.Index(got, `data-field="content"`) > strings.Index(got, `data-field="edits.0.new_string"`) {
+		t.Error("collapsed sections should be in path order")
+	}
+}
+
+func TestRenderToolCall_WriteContentCollapsed(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Write", Input: map[string]any{
+		"file_path": "/a/b.go",
+		"content":   strings.Repeat("line\n", 1000),
+	}}
+	got := renderToolCall(tool, models.ToolResult{}, false)
+	if !strings.Contains(got, `<summary class="tool-input-field-summary">content (5000 bytes)</summary>`) {
+		t.Errorf("Write content should be collapsed, got:\n%s", got)
+	}
+}
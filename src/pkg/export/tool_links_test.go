@@ -0,0 +1,174 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func toolLinkEntries() []models.ConversationEntry {
+	return []models.ConversationEntry{
+		{
+			UUID:      "a1",
+			Type:      models.EntryTypeAssistant,
+			Timestamp: "2026-02-01T10:00:00Z",
+			Message:   json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"toolu_paired","name":"Bash","input":{"command":"ls"}},{"type":"tool_use","id":"toolu_lonely","name":"Bash","input":{"command":"pwd"}}]}`),
+		},
+		{
+			UUID:      "u1",
+			Type:      models.EntryTypeUser,
+			Timestamp: "2026-02-01T10:00:01Z",
+			Message:   json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_paired","content":"file.txt"}]}`),
+		},
+		{
+			UUID:      "u2",
+			Type:      models.EntryTypeUser,
+			Timestamp: "2026-02-01T10:00:02Z",
+			Message:   json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_missing","content":"stray output","is_error":true}]}`),
+		},
+	}
+}
+
+func TestBuildToolResultsMap_RecordsEntryUUID(t *testing.T) {
+	results := buildToolResultsMap(toolLinkEntries())
+
+	if got := results["toolu_paired"].EntryUUID; got != "u1" {
+		t.Errorf("EntryUUID = %q, want %q", got, "u1")
+	}
+	if got := results["toolu_missing"].EntryUUID; got != "u2" {
+		t.Errorf("EntryUUID = %q, want %q", got, "u2")
+	}
+}
+
+func TestRenderToolCallWith_LinksResult(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Bash", Input: map[string]any{"command": "ls"}}
+	result := models.ToolResult{ToolUseID: "toolu_1", Content: "ok", EntryUUID: "u1"}
+
+	html := renderToolCallWith(tool, result, true, "", entryRenderOptions{opts: ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen}})
+
+	for _, want := range []string{
+		`id="tool-toolu_1"`,
+		`href="#tool-result-toolu_1"`,
+		`href="#tool-toolu_1"`,
+		`<pre class="tool-output bash-output" id="tool-result-toolu_1" data-tool-result-for="toolu_1" data-result-entry="u1">ok</pre>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing %q in:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "tool-orphan") {
+		t.Error("paired tool call should not be marked as orphan")
+	}
+}
+
+func TestRenderToolCallWith_OrphanCall(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Bash", Input: map[string]any{"command": "ls"}}
+
+	html := renderToolCallWith(tool, models.ToolResult{}, false, "", entryRenderOptions{opts: ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen}})
+
+	if !strings.Contains(html, `<span class="tool-orphan"`) || !strings.Contains(html, "no result") {
+		t.Errorf("tool call without result should be marked, got:\n%s", html)
+	}
+	if strings.Contains(html, "tool-pair-link") || strings.Contains(html, "data-tool-result-for") {
+		t.Error("orphan tool call should not link to a result")
+	}
+}
+
+func TestRenderConversation_OrphanResult(t *testing.T) {
+	html, err := RenderConversation(toolLinkEntries(), nil)
+	if err != nil {
+		t.Fatalf("RenderConversation() error = %v", err)
+	}
+
+	if !strings.Contains(html, `<div class="orphan-tool-results" data-uuid="u2">`) {
+		t.Error("result without a call should be rendered as an orphan block")
+	}
+	if !strings.Contains(html, "Tool result without a matching call") || !strings.Contains(html, "stray output") {
+		t.Error("orphan result should be labelled and show its content")
+	}
+	if !strings.Contains(html, `class="tool-output error" id="tool-result-toolu_missing"`) {
+		t.Error("orphan error result should keep the error styling")
+	}
+	if strings.Contains(html, `orphan-tool-results" data-uuid="u1"`) {
+		t.Error("paired result should not be rendered as an orphan")
+	}
+	if strings.Count(html, `<span class="tool-orphan"`) != 1 {
+		t.Errorf("expected exactly one orphan tool call marker, got %d", strings.Count(html, `<span class="tool-orphan"`))
+	}
+}
+
+func TestRenderAgentFragment_OrphanResult(t *testing.T) {
+	html, err := RenderAgentFragment("agent1", toolLinkEntries())
+	if err != nil {
+		t.Fatalf("RenderAgentFragment() error = %v", err)
+	}
+
+	if !strings.Contains(html, `data-tool-result-for="toolu_missing"`) {
+		t.Errorf("agent fragment should render orphan results, got:\n%s", html)
+	}
+}
+
+// mixedUserEntries returns a Bash call answered by a user entry that also carries text
+// and a result whose call is missing.
+func mixedUserEntries() []models.ConversationEntry {
+	return []models.ConversationEntry{
+		{
+			UUID:      "a1",
+			Type:      models.EntryTypeAssistant,
+			Timestamp: "2026-02-01T10:00:00Z",
+			Message:   json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"toolu_paired","name":"Bash","input":{"command":"ls"}}]}`),
+		},
+		{
+			UUID:      "u1",
+			Type:      models.EntryTypeUser,
+			Timestamp: "2026-02-01T10:00:01Z",
+			Message:   json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_paired","content":"PAIRED OUTPUT"},{"type":"text","text":"MIXED USER TEXT"},{"type":"tool_result","tool_use_id":"toolu_missing","content":"STRAY OUTPUT"}]}`),
+		},
+	}
+}
+
+func TestRenderConversation_MixedUserEntry(t *testing.T) {
+	entries := mixedUserEntries()
+	if !hasContent(entries[1]) {
+		t.Fatal("a user entry with text and tool results has content")
+	}
+
+	html, err := RenderConversation(entries, nil)
+	if err != nil {
+		t.Fatalf("RenderConversation() error = %v", err)
+	}
+
+	// The text is a normal bubble, shown once
+	if !strings.Contains(html, `data-uuid="u1"`) || strings.Count(html, "MIXED USER TEXT") != 1 {
+		t.Error("mixed entry's text should render once as its own message")
+	}
+
+	// The paired result is shown with its call, not in the user bubble
+	if strings.Count(html, "PAIRED OUTPUT") != 1 {
+		t.Errorf("paired result should be shown once, with its call (got %d)", strings.Count(html, "PAIRED OUTPUT"))
+	}
+	callIdx := strings.Index(html, `data-tool-id="toolu_paired"`)
+	if callIdx < 0 || strings.Index(html, "PAIRED OUTPUT") < callIdx || strings.Index(html, "PAIRED OUTPUT") > strings.Index(html, "MIXED USER TEXT") {
+		t.Error("paired result should render inside its tool call, before the user's text")
+	}
+
+	// The result without a call still shows up, after the text
+	if !strings.Contains(html, `<div class="orphan-tool-results" data-uuid="u1">`) || !strings.Contains(html, "STRAY OUTPUT") {
+		t.Error("orphan result of a mixed entry should be rendered")
+	}
+	if strings.Contains(html, `data-tool-id="toolu_paired"`) && strings.Contains(html, `orphan-result" data-tool-id="toolu_paired"`) {
+		t.Error("paired result should not be rendered as an orphan")
+	}
+}
+
+func TestRenderAgentFragment_MixedUserEntry(t *testing.T) {
+	html, err := RenderAgentFragment("agent1", mixedUserEntries())
+	if err != nil {
+		t.Fatalf("RenderAgentFragment() error = %v", err)
+	}
+	if strings.Count(html, "MIXED USER TEXT") != 1 || strings.Count(html, "PAIRED OUTPUT") != 1 || !strings.Contains(html, "STRAY OUTPUT") {
+		t.Errorf("agent fragment should render the mixed entry's text, paired result, and orphan result once each, got:\n%s", html)
+	}
+}
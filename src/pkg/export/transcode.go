@@ -0,0 +1,40 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// transcodeToolOutput returns entries with their tool results converted to UTF-8 from
+// opts.ToolOutputEncoding (see models.ToUTF8), and the options to render them with.
+// Without an encoding, or when no result needs converting, entries and opts are
+// returned as they are. Otherwise the caller's entries are left untouched, and
+// opts.ToolIndex, which holds the unconverted results, is dropped.
+func transcodeToolOutput(entries []models.ConversationEntry, opts ExportOptions) ([]models.ConversationEntry, ExportOptions, error) {
+	if opts.ToolOutputEncoding == "" {
+		return entries, opts, nil
+	}
+	enc, err := models.LookupEncoding(opts.ToolOutputEncoding)
+	if err != nil {
+		return nil, opts, fmt.Errorf("invalid tool output encoding: %w", err)
+	}
+
+	var out []models.ConversationEntry
+	for i := range entries {
+		converted, changed := entries[i].WithToolResultsUTF8(enc)
+		if !changed {
+			continue
+		}
+		if out == nil {
+			out = make([]models.ConversationEntry, len(entries))
+			copy(out, entries)
+		}
+		out[i] = converted
+	}
+	if out == nil {
+		return entries, opts, nil
+	}
+	opts.ToolIndex = nil
+	return out, opts, nil
+}
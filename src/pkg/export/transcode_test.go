@@ -0,0 +1,90 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// latin1ToolEntries returns a Bash call and its result, whose output has Latin-1 bytes.
+func latin1ToolEntries() []models.ConversationEntry {
+	return []models.ConversationEntry{
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:00Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"cat notes.txt"}}]}`)},
+		{UUID: "r1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:01Z",
+			Message: json.RawMessage("{\"role\":\"user\",\"content\":[{\"type\":\"tool_result\",\"tool_use_id\":\"t1\",\"content\":\"caf\xe9 cr\xe8me\"}]}")},
+	}
+}
+
+func TestTranscodeToolOutput(t *testing.T) {
+	entries := latin1ToolEntries()
+	original := string(entries[1].Message)
+
+	got, opts, err := transcodeToolOutput(entries, ExportOptions{ToolOutputEncoding: "latin1", ToolIndex: NewToolIndex(entries)})
+	if err != nil {
+		t.Fatalf("transcodeToolOutput() error = %v", err)
+	}
+	if content := got[1].ExtractToolResults()[0].Content; content != "café crème" {
+		t.Errorf("content = %q, want café crème", content)
+	}
+	if opts.ToolIndex != nil {
+		t.Error("the tool index of the unconverted entries should be dropped")
+	}
+	if string(entries[1].Message) != original {
+		t.Error("the caller's entries should not be modified")
+	}
+}
+
+func TestTranscodeToolOutput_PassThrough(t *testing.T) {
+	entries := latin1ToolEntries()
+	index := NewToolIndex(entries)
+
+	got, opts, err := transcodeToolOutput(entries, ExportOptions{ToolIndex: index})
+	if err != nil || &got[0] != &entries[0] || opts.ToolIndex != index {
+		t.Error("without an encoding, entries and options should be returned as they are")
+	}
+
+	valid := entries[:1]
+	got, opts, err = transcodeToolOutput(valid, ExportOptions{ToolOutputEncoding: "latin1", ToolIndex: index})
+	if err != nil || &got[0] != &valid[0] || opts.ToolIndex != index {
+		t.Error("entries without invalid bytes should be returned as they are")
+	}
+
+	if _, _, err := transcodeToolOutput(entries, ExportOptions{ToolOutputEncoding: "bogus"}); err == nil {
+		t.Error("an unknown encoding should be an error")
+	}
+}
+
+func TestRenderConversation_ToolOutputEncoding(t *testing.T) {
+	entries := latin1ToolEntries()
+
+	plain, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if !strings.Contains(plain, "caf� cr�me") {
+		t.Error("by default invalid bytes should show as replacement characters")
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{ToolOutputEncoding: "windows-1252"})
+	if err != nil {
+		t.Fatalf("RenderConversationWithOptions() error = %v", err)
+	}
+	if !strings.Contains(html, "café crème") {
+		t.Error("ToolOutputEncoding should convert the tool output")
+	}
+
+	fragment, err := RenderAgentFragmentWithOptions("agent1", entries, ExportOptions{ToolOutputEncoding: "latin1"})
+	if err != nil {
+		t.Fatalf("RenderAgentFragmentWithOptions() error = %v", err)
+	}
+	if !strings.Contains(fragment, "café crème") {
+		t.Error("agent fragments should convert the tool output too")
+	}
+
+	if _, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{ToolOutputEncoding: "bogus"}); err == nil {
+		t.Error("an unknown encoding should be an error")
+	}
+}
@@ -0,0 +1,103 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		name          string
+		s             string
+		maxBytes      int
+		want          string
+		wantTruncated bool
+	}{
+		{"no limit", "hello", 0, "hello", false},
+		{"negative limit", "hello", -1, "hello", false},
+		{"under limit", "hello", 10, "hello", false},
+		{"exact limit", "hello", 5, "hello", false},
+		{"ascii cut", "hello world", 5, "hello", true},
+		{"cut inside two-byte rune", "aé", 2, "a", true},
+		{"cut inside four-byte rune", "ab😀cd", 4, "ab", true},
+		{"cut after rune", "ab😀cd", 6, "ab😀", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := truncateUTF8(tt.s, tt.maxBytes)
+			if got != tt.want || truncated != tt.wantTruncated {
+				t.Errorf("truncateUTF8(%q, %d) = (%q, %v), want (%q, %v)", tt.s, tt.maxBytes, got, truncated, tt.want, tt.wantTruncated)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateUTF8(%q, %d) produced invalid UTF-8", tt.s, tt.maxBytes)
+			}
+		})
+	}
+}
+
+func TestRenderToolCallWith_TruncatesOutput(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Read", Input: map[string]any{"file_path": "big.txt"}}
+	content := strings.Repeat("x", 50) + "é" + strings.Repeat("y", 50)
+	result := models.ToolResult{ToolUseID: "toolu_1", Content: content}
+
+	html := renderToolCallWith(tool, result, true, "", entryRenderOptions{opts: ExportOptions{MaxToolOutputBytes: 51, SummaryMaxLen: DefaultSummaryMaxLen}})
+
+	if !strings.Contains(html, `<pre class="tool-output" id="tool-result-toolu_1" data-tool-result-for="toolu_1">`+strings.Repeat("x", 50)+`</pre>`) {
+		t.Errorf("output should be cut before the split rune, got:\n%s", html)
+	}
+	if !strings.Contains(html, "… (truncated, 102 bytes total)") {
+		t.Error("truncation notice should report the full size")
+	}
+	if !strings.Contains(html, `data-copy-text="`+content+`"`) {
+		t.Error("copy button should copy the full output")
+	}
+}
+
+func TestRenderToolCallWith_NeverTruncatesErrors(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Bash", Input: map[string]any{"command": "make"}}
+	content := strings.Repeat("error line\n", 20)
+	result := models.ToolResult{ToolUseID: "toolu_1", Content: content, IsError: true}
+
+	html := renderToolCallWith(tool, result, true, "", entryRenderOptions{opts: ExportOptions{MaxToolOutputBytes: 10, SummaryMaxLen: DefaultSummaryMaxLen}})
+
+	if strings.Contains(html, "tool-output-truncated") {
+		t.Error("error output should never be truncated")
+	}
+	if !strings.Contains(html, escapeHTML(content)) {
+		t.Error("full error output should be rendered")
+	}
+}
+
+func TestRenderToolCallWith_ZeroMeansNoLimit(t *testing.T) {
+	tool := models.ToolUse{ID: "toolu_1", Name: "Bash", Input: map[string]any{"command": "ls"}}
+	result := models.ToolResult{ToolUseID: "toolu_1", Content: strings.Repeat("a", 10000)}
+
+	if got, want := renderToolCallWith(tool, result, true, "", entryRenderOptions{opts: ExportOptions{SummaryMaxLen: DefaultSummaryMaxLen, NoToolIcons: true}}), renderToolCall(tool, result, true); got != want {
+		t.Error("limit 0 should render the same as renderToolCall")
+	}
+	if strings.Contains(renderToolCall(tool, result, true), "tool-output-truncated") {
+		t.Error("default rendering should not truncate")
+	}
+}
+
+func TestRenderConversationWithOptions_MaxToolOutputBytes(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:00Z",
+			Message: json.RawMessage(`[{"type":"tool_use","id":"toolu_1","name":"Bash","input":{"command":"cat big"}}]`)},
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:01Z",
+			Message: json.RawMessage(`[{"type":"tool_result","tool_use_id":"toolu_1","content":"` + strings.Repeat("z", 200) + `"}]`)},
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{MaxToolOutputBytes: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, "… (truncated, 200 bytes total)") {
+		t.Error("conversation rendering should apply MaxToolOutputBytes")
+	}
+}
@@ -0,0 +1,136 @@
+package export
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// typeColorVars maps the entry classes (see getEntryClass) whose color can be set with
+// ExportOptions.TypeColors to the prefix of their CSS variables in style.css.
+var typeColorVars = map[string]string{
+	"user":            "user",
+	"assistant":       "assistant",
+	"system":          "system",
+	"queue-operation": "queue",
+	"summary":         "summary",
+}
+
+// Background tints mixed from a type color, in percent of the color; the rest is
+// transparent so the tint works on light and dark pages alike.
+const (
+	typeColorBgPercent      = 12
+	typeColorBgHoverPercent = 20
+)
+
+// hexColorRe matches #rgb, #rgba, #rrggbb, and #rrggbbaa colors.
+var hexColorRe = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// funcColorRe matches rgb(), rgba(), hsl(), and hsla() colors with numeric arguments,
+// comma- or space-separated, with an optional "/ alpha".
+var funcColorRe = regexp.MustCompile(`^(?:rgba?|hsla?)\(\s*[0-9.]+(?:deg|%)?(?:\s*,\s*|\s+)[0-9.]+%?(?:\s*,\s*|\s+)[0-9.]+%?(?:\s*(?:,|/)\s*[0-9.]+%?)?\s*\)$`)
+
+// cssNamedColors are the CSS color keywords accepted by ValidCSSColor.
+var cssNamedColors = map[string]bool{
+	"aliceblue": true, "antiquewhite": true, "aqua": true, "aquamarine": true, "azure": true,
+	"beige": true, "bisque": true, "black": true, "blanchedalmond": true, "blue": true,
+	"blueviolet": true, "brown": true, "burlywood": true, "cadetblue": true,
+	"chartreuse": true, "chocolate": true, "coral": true, "cornflowerblue": true,
+	"cornsilk": true, "crimson": true, "cyan": true, "darkblue": true, "darkcyan": true,
+	"darkgoldenrod": true, "darkgray": true, "darkgreen": true, "darkgrey": true,
+	"darkkhaki": true, "darkmagenta": true, "darkolivegreen": true, "darkorange": true,
+	"darkorchid": true, "darkred": true, "darksalmon": true, "darkseagreen": true,
+	"darkslateblue": true, "darkslategray": true, "darkslategrey": true,
+	"darkturquoise": true, "darkviolet": true, "deeppink": true, "deepskyblue": true,
+	"dimgray": true, "dimgrey": true, "dodgerblue": true, "firebrick": true,
+	"floralwhite": true, "forestgreen": true, "fuchsia": true, "gainsboro": true,
+	"ghostwhite": true, "gold": true, "goldenrod": true, "gray": true, "green": true,
+	"greenyellow": true, "grey": true, "honeydew": true, "hotpink": true, "indianred": true,
+	"indigo": true, "ivory": true, "khaki": true, "lavender": true, "lavenderblush": true,
+	"lawngreen": true, "lemonchiffon": true, "lightblue": true, "lightcoral": true,
+	"lightcyan": true, "lightgoldenrodyellow": true, "lightgray": true, "lightgreen": true,
+	"lightgrey": true, "lightpink": true, "lightsalmon": true, "lightseagreen": true,
+	"lightskyblue": true, "lightslategray": true, "lightslategrey": true,
+	"lightsteelblue": true, "lightyellow": true, "lime": true, "limegreen": true,
+	"linen": true, "magenta": true, "maroon": true, "mediumaquamarine": true,
+	"mediumblue": true, "mediumorchid": true, "mediumpurple": true, "mediumseagreen": true,
+	"mediumslateblue": true, "mediumspringgreen": true, "mediumturquoise": true,
+	"mediumvioletred": true, "midnightblue": true, "mintcream": true, "mistyrose": true,
+	"moccasin": true, "navajowhite": true, "navy": true, "oldlace": true, "olive": true,
+	"olivedrab": true, "orange": true, "orangered": true, "orchid": true,
+	"palegoldenrod": true, "palegreen": true, "paleturquoise": true, "palevioletred": true,
+	"papayawhip": true, "peachpuff": true, "peru": true, "pink": true, "plum": true,
+	"powderblue": true, "purple": true, "rebeccapurple": true, "red": true, "rosybrown": true,
+	"royalblue": true, "saddlebrown": true, "salmon": true, "sandybrown": true,
+	"seagreen": true, "seashell": true, "sienna": true, "silver": true, "skyblue": true,
+	"slateblue": true, "slategray": true, "slategrey": true, "snow": true,
+	"springgreen": true, "steelblue": true, "tan": true, "teal": true, "thistle": true,
+	"tomato": true, "turquoise": true, "violet": true, "wheat": true, "white": true,
+	"whitesmoke": true, "yellow": true, "yellowgreen": true,
+}
+
+// ValidCSSColor reports whether s is a color ExportOptions.TypeColors accepts: a hex
+// color, an rgb()/rgba()/hsl()/hsla() color with numeric arguments, or a CSS color name.
+func ValidCSSColor(s string) bool {
+	s = strings.TrimSpace(s)
+	return hexColorRe.MatchString(s) || funcColorRe.MatchString(strings.ToLower(s)) || cssNamedColors[strings.ToLower(s)]
+}
+
+// TypeColorNames returns the sorted entry types ExportOptions.TypeColors accepts.
+func TypeColorNames() []string {
+	names := make([]string, 0, len(typeColorVars))
+	for name := range typeColorVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateTypeColors reports the first entry of colors (see ExportOptions.TypeColors)
+// whose type is not one of TypeColorNames or whose color ValidCSSColor rejects.
+func ValidateTypeColors(colors map[string]string) error {
+	types := make([]string, 0, len(colors))
+	for entryType := range colors {
+		types = append(types, entryType)
+	}
+	sort.Strings(types)
+	for _, entryType := range types {
+		if _, ok := typeColorVars[entryType]; !ok {
+			return fmt.Errorf("invalid type color %q: type must be one of %s", entryType, strings.Join(TypeColorNames(), ", "))
+		}
+		if color := colors[entryType]; !ValidCSSColor(color) {
+			return fmt.Errorf("invalid color %q for %s: want a hex color such as #888, an rgb() or hsl() color, or a CSS color name", color, entryType)
+		}
+	}
+	return nil
+}
+
+// renderTypeColorStyle returns a <style> block overriding the CSS variables of the
+// entry types in colors: the accent and border take the color, and the backgrounds a
+// tint of it. Types not in colors keep the stylesheet's light and dark defaults. Colors
+// must have passed ValidateTypeColors; invalid entries are left out.
+func renderTypeColorStyle(colors map[string]string) string {
+	if len(colors) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, entryType := range TypeColorNames() {
+		color, ok := colors[entryType]
+		if !ok || !ValidCSSColor(color) {
+			continue
+		}
+		color = strings.TrimSpace(color)
+		prefix := typeColorVars[entryType]
+		fmt.Fprintf(&sb, "        --%s-accent: %s;\n", prefix, color)
+		fmt.Fprintf(&sb, "        --%s-border: %s;\n", prefix, color)
+		fmt.Fprintf(&sb, "        --%s-bg: color-mix(in srgb, %s %d%%, transparent);\n", prefix, color, typeColorBgPercent)
+		fmt.Fprintf(&sb, "        --%s-bg-hover: color-mix(in srgb, %s %d%%, transparent);\n", prefix, color, typeColorBgHoverPercent)
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	// Same specificity as the stylesheet's :root rules but later, so these win in dark mode too
+	return "    <style>\n    :root {\n" + sb.String() + "    }\n    </style>\n"
+}
@@ -0,0 +1,70 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestValidCSSColor(t *testing.T) {
+	for _, color := range []string{"#888", "#88888880", "#A1b2C3", "gray", "RebeccaPurple", "rgb(1, 2, 3)", "rgba(1,2,3,0.5)", "hsl(210deg 50% 40% / 0.8)"} {
+		if !ValidCSSColor(color) {
+			t.Errorf("ValidCSSColor(%q) = false, want true", color)
+		}
+	}
+	for _, color := range []string{"", "#12", "#ggg", "notacolor", "red;", "rgb(1, 2)", "url(x)", "var(--user-bg)", "expression(alert(1))"} {
+		if ValidCSSColor(color) {
+			t.Errorf("ValidCSSColor(%q) = true, want false", color)
+		}
+	}
+}
+
+func TestValidateTypeColors(t *testing.T) {
+	if err := ValidateTypeColors(map[string]string{"system": "gray", "queue-operation": "#f80"}); err != nil {
+		t.Errorf("ValidateTypeColors(valid) = %v", err)
+	}
+	if err := ValidateTypeColors(map[string]string{"robot": "gray"}); err == nil || !strings.Contains(err.Error(), "type must be one of") {
+		t.Errorf("ValidateTypeColors(unknown type) = %v", err)
+	}
+	if err := ValidateTypeColors(map[string]string{"user": "blu"}); err == nil || !strings.Contains(err.Error(), `invalid color "blu" for user`) {
+		t.Errorf("ValidateTypeColors(bad color) = %v", err)
+	}
+}
+
+func TestRenderTypeColorStyle(t *testing.T) {
+	if got := renderTypeColorStyle(nil); got != "" {
+		t.Errorf("renderTypeColorStyle(nil) = %q, want empty", got)
+	}
+
+	got := renderTypeColorStyle(map[string]string{"system": "gray", "queue-operation": "#f80"})
+	for _, want := range []string{"--system-accent: gray;", "--system-border: gray;", "--system-bg: color-mix(in srgb, gray 12%, transparent);", "--queue-accent: #f80;"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("style should contain %q, got:\n%s", want, got)
+		}
+	}
+	// Unspecified types keep the stylesheet's colors
+	if strings.Contains(got, "--user-") || strings.Contains(got, "--assistant-") {
+		t.Errorf("style should only override the given types, got:\n%s", got)
+	}
+}
+
+func TestRenderConversationWithOptions_TypeColors(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: []byte(`"hello"`)},
+	}
+
+	html, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{TypeColors: map[string]string{"user": "#6366f1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	linkIdx := strings.Index(html, `<link rel="stylesheet" href="static/style.css">`)
+	styleIdx := strings.Index(html, "--user-accent: #6366f1;")
+	if styleIdx < 0 || styleIdx < linkIdx || styleIdx > strings.Index(html, "</head>") {
+		t.Error("color overrides should be in a <style> block after the stylesheet, in the head")
+	}
+
+	if _, err := RenderConversationWithOptions(entries, nil, nil, ExportOptions{TypeColors: map[string]string{"user": "}body{"}}); err == nil {
+		t.Error("an invalid color should fail rendering")
+	}
+}
@@ -139,3 +139,69 @@ func TestFormatUserContentMultipleTags(t *testing.T) {
 		t.Errorf("formatUserContent() should show other-tag with content")
 	}
 }
+
+func TestFormatUserContentWith_Attachment(t *testing.T) {
+	input := "Please review this:\n<file path=\"pkg/main.go\">\npackage main\n\nfunc main() {}\n</file>\n<bash-stdout>ok</bash-stdout>"
+
+	html := formatUserContentWith(input, "/work/project")
+
+	for _, want := range []string{
+		"Please review this:",
+		`<details class="attachment" open>`,
+		`<span class="attachment-name">main.go</span>`,
+		`<a href="file:///work/project/pkg/main.go" class="file-link" title="/work/project/pkg/main.go">pkg/main.go</a>`,
+		`<span class="attachment-size">3 lines</span>`,
+		`<div class="code-block language-go">`,
+		"<code>package main\n\nfunc main() {}</code>",
+		// Other tags keep the generic handling
+		`&lt;bash-stdout&gt;`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing %q in:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "&lt;file") {
+		t.Error("attachment tags should not be shown as XML")
+	}
+}
+
+func TestFormatUserContentWith_LargeAttachmentCollapsed(t *testing.T) {
+	content := strings.Repeat("line\n", attachmentCollapseLines+1)
+	html := formatUserContentWith(`<attachment name="/tmp/big.log">`+content+`</attachment>`, "")
+
+	if !strings.Contains(html, `<details class="attachment">`) {
+		t.Errorf("large attachment should start collapsed, got:\n%s", html)
+	}
+	if !strings.Contains(html, `href="file:///tmp/big.log"`) {
+		t.Error("absolute paths should be linked as-is")
+	}
+}
+
+func TestFormatUserContentWith_AttachmentContentEscaped(t *testing.T) {
+	html := formatUserContentWith(`<file path="a &amp; b.html"><div>hi</div></file>`, "")
+
+	if !strings.Contains(html, "&lt;div&gt;hi&lt;/div&gt;") {
+		t.Errorf("attachment content should be escaped, got:\n%s", html)
+	}
+	if !strings.Contains(html, `<span class="attachment-name">a &amp; b.html</span>`) {
+		t.Errorf("attribute entities should be decoded once and re-escaped, got:\n%s", html)
+	}
+}
+
+func TestFormatUserContentWith_TagWithoutPath(t *testing.T) {
+	html := formatUserContentWith("<file>notes</file>", "/work")
+
+	if strings.Contains(html, "attachment") || !strings.Contains(html, "xml-tag-block") {
+		t.Errorf("file tag without a path should keep the generic tag rendering, got:\n%s", html)
+	}
+}
+
+func BenchmarkFormatUserContent(b *testing.B) {
+	content := "<bash-input>go test ./...</bash-input>\n<bash-stdout>ok  \tpkg/export\t0.2s</bash-stdout>\n<bash-stderr></bash-stderr>"
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			formatUserContent(content)
+		}
+	})
+}
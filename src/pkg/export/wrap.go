@@ -0,0 +1,90 @@
+package export
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// wrapPrefixRe matches the start of a line that its wrapped continuation lines keep:
+// indentation, blockquote markers, and a list marker, whose width is kept as spaces so
+// the continuation stays in the list item.
+var wrapPrefixRe = regexp.MustCompile(`^([ \t]*(?:>[ \t]?)*)((?:[-*+]|\d{1,9}[.)])[ \t]+)?`)
+
+// wrapText hard-wraps the lines of s longer than width characters at word boundaries
+// (see ExportOptions.WrapWidth). The newlines already in s are kept, and lines that fit
+// are left as they are. Words longer than width, such as URLs, are never broken: they
+// get a line of their own. Fenced and indented code, tables and headings are not
+// wrapped. A width of 0 or less returns s unchanged.
+func wrapText(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+
+	lines := strings.Split(s, "\n")
+	out := make([]string, 0, len(lines))
+	fence := ""
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+
+		// Fenced code blocks run to a closing fence of the same kind
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			out = append(out, line)
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			out = append(out, line)
+			continue
+		}
+
+		if utf8.RuneCountInString(line) <= width || !isWrappableLine(line, trimmed) {
+			out = append(out, line)
+			continue
+		}
+		out = append(out, wrapLine(line, width)...)
+	}
+	return strings.Join(out, "\n")
+}
+
+// isWrappableLine reports whether line is prose that wrapText may wrap: not indented
+// code, a table row, or a heading. trimmed is line without its leading whitespace.
+func isWrappableLine(line, trimmed string) bool {
+	if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "    ") {
+		return false
+	}
+	return !strings.HasPrefix(trimmed, "|") && !strings.HasPrefix(trimmed, "#")
+}
+
+// wrapLine splits one line into lines of at most width characters where its words
+// allow. Continuation lines repeat the line's indentation and blockquote markers, and
+// indent past its list marker.
+func wrapLine(line string, width int) []string {
+	m := wrapPrefixRe.FindStringSubmatch(line)
+	first := m[0]
+	rest := m[1] + strings.Repeat(" ", utf8.RuneCountInString(m[2]))
+
+	words := strings.Fields(line[len(first):])
+	if len(words) == 0 {
+		return []string{line}
+	}
+
+	var lines []string
+	current := first + words[0]
+	currentLen := utf8.RuneCountInString(current)
+	for _, word := range words[1:] {
+		wordLen := utf8.RuneCountInString(word)
+		if currentLen+1+wordLen > width {
+			lines = append(lines, current)
+			current = rest + word
+			currentLen = utf8.RuneCountInString(rest) + wordLen
+			continue
+		}
+		current += " " + word
+		currentLen += 1 + wordLen
+	}
+	return append(lines, current)
+}
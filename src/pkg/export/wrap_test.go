@@ -0,0 +1,114 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{"zero width", "one two three four", 0, "one two three four"},
+		{"fits", "one two", 10, "one two"},
+		{"word boundaries", "one two three four five", 10, "one two\nthree four\nfive"},
+		{"newlines kept", "short\n\nline one two three", 10, "short\n\nline one\ntwo three"},
+		{"long URL kept whole", "see https://example.com/a/very/long/path now", 12, "see\nhttps://example.com/a/very/long/path\nnow"},
+		{"list item hangs", "- alpha beta gamma", 12, "- alpha beta\n  gamma"},
+		{"numbered item hangs", "10. alpha beta gamma", 14, "10. alpha beta\n    gamma"},
+		{"blockquote", "> alpha beta gamma", 12, "> alpha beta\n> gamma"},
+		{"multibyte counted as characters", "ééé ééé ééé", 7, "ééé ééé\nééé"},
+		{"heading kept", "# a very long heading here", 10, "# a very long heading here"},
+		{"table kept", "| a long cell | another cell |", 10, "| a long cell | another cell |"},
+		{"indented code kept", "    x := someFunction(argument)", 10, "    x := someFunction(argument)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapText(tt.input, tt.width); got != tt.want {
+				t.Errorf("wrapText(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapText_FencedCode(t *testing.T) {
+	input := "Run this long command please:\n```bash\ngo test ./... -run TestSomethingLong -count 1\n```\n~~~\nanother long line inside tildes\n~~~\nand then wrap this prose too"
+	want := "Run this long\ncommand please:\n```bash\ngo test ./... -run TestSomethingLong -count 1\n```\n~~~\nanother long line inside tildes\n~~~\nand then wrap\nthis prose too"
+	if got := wrapText(input, 15); got != want {
+		t.Errorf("wrapText() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderConversationText_WrapWidth(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:00Z",
+			Message: []byte(`{"role":"assistant","content":[{"type":"text","text":"The quick brown fox jumps over the lazy dog and keeps running\nNew line"}]}`)},
+	}
+
+	plain, err := renderConversationText(entries, nil, nil, false, localizer{}, 0)
+	if err != nil {
+		t.Fatalf("renderConversationText() error = %v", err)
+	}
+	if !strings.Contains(plain, "  The quick brown fox jumps over the lazy dog and keeps running\n  New line") {
+		t.Errorf("width 0 should not wrap:\n%s", plain)
+	}
+
+	wrapped, err := renderConversationText(entries, nil, nil, false, localizer{}, 24)
+	if err != nil {
+		t.Fatalf("renderConversationText() error = %v", err)
+	}
+	if !strings.Contains(wrapped, "  The quick brown fox\n  jumps over the lazy\n") || !strings.Contains(wrapped, "\n  New line") {
+		t.Errorf("text should wrap within the indent:\n%s", wrapped)
+	}
+	for _, line := range strings.Split(wrapped, "\n") {
+		if strings.HasPrefix(line, "  ") && utf8.RuneCountInString(line) > 24 {
+			t.Errorf("line %q is longer than 24 columns", line)
+		}
+	}
+}
+
+func TestRenderConversationMarkdown_WrapWidth(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:00Z",
+			Message: []byte(`{"role":"assistant","content":[{"type":"text","text":"Wrap this paragraph of prose please\n\n` + "```" + `\nkeep this code line exactly as it is\n` + "```" + `"}]}`)},
+	}
+
+	md, err := renderConversationMarkdown(entries, nil, nil, false, localizer{}, 20)
+	if err != nil {
+		t.Fatalf("renderConversationMarkdown() error = %v", err)
+	}
+	if !strings.Contains(md, "Wrap this paragraph\nof prose please") {
+		t.Errorf("prose should wrap:\n%s", md)
+	}
+	if !strings.Contains(md, "keep this code line exactly as it is") {
+		t.Errorf("code should not wrap:\n%s", md)
+	}
+}
+
+func TestExporters_WrapWidth(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: []byte(`"alpha beta gamma delta"`)},
+	}
+
+	text, err := TextExporter{NoStats: true, WrapWidth: 14}.Render(entries, nil, nil)
+	if err != nil {
+		t.Fatalf("TextExporter.Render() error = %v", err)
+	}
+	if !strings.Contains(string(text), "  alpha beta\n  gamma delta") {
+		t.Errorf("TextExporter should wrap:\n%s", text)
+	}
+
+	md, err := MarkdownExporter{NoStats: true, WrapWidth: 12}.Render(entries, nil, nil)
+	if err != nil {
+		t.Fatalf("MarkdownExporter.Render() error = %v", err)
+	}
+	if !strings.Contains(string(md), "alpha beta\ngamma delta") {
+		t.Errorf("MarkdownExporter should wrap:\n%s", md)
+	}
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiErrorSubtype is the subtype of the system entries Claude Code records when an API
+// request fails.
+const apiErrorSubtype = "api_error"
+
+// apiErrorPrefixes are the texts Claude Code writes into the messages it synthesizes for
+// failed API requests. They are only consulted when no structured field marks the error.
+var apiErrorPrefixes = []string{
+	"API Error",
+	"Claude AI usage limit reached",
+}
+
+// apiErrorStatusRe extracts the HTTP status from an "API Error: 529 ..." text.
+var apiErrorStatusRe = regexp.MustCompile(`^API Error:?\s*(\d{3})\b`)
+
+// APIError describes a failed API request recorded in a session.
+type APIError struct {
+	Status       int           // HTTP status, 0 if unknown
+	Type         string        // Error type, e.g. "rate_limit_error" or "overloaded_error"
+	Message      string        // Error message
+	RetryIn      time.Duration // Delay before Claude Code retried the request, 0 if unknown
+	RetryAttempt int           // Retry attempt number, 0 if not retrying
+	MaxRetries   int           // Retries Claude Code allows before giving up, 0 if unknown
+}
+
+// IsRateLimit reports whether the error is a rate or usage limit.
+func (a APIError) IsRateLimit() bool {
+	return a.Status == 429 || strings.Contains(a.Type, "rate_limit") ||
+		strings.HasPrefix(a.Message, "Claude AI usage limit reached")
+}
+
+// IsAPIError returns true if this entry records a failed API request (rate limit,
+// overload, server error) rather than conversation content. It checks the structured
+// fields first: a system entry with subtype "api_error" or an error field, or a message
+// flagged isApiErrorMessage. Otherwise it falls back to the known error texts, but only
+// in system entries and in assistant messages Claude Code synthesized itself, so tool
+// errors (tool_result blocks with is_error) are never matched.
+func (e *ConversationEntry) IsAPIError() bool {
+	if e.IsAPIErrorMessage {
+		return true
+	}
+	switch e.Type {
+	case EntryTypeSystem:
+		if e.Subtype == apiErrorSubtype || hasJSONValue(e.Error) {
+			return true
+		}
+	case EntryTypeAssistant:
+		if !e.isSynthetic() {
+			return false
+		}
+	default:
+		return false
+	}
+	return isAPIErrorText(e.GetTextContent())
+}
+
+// APIErrorDetail returns the details of the failed request recorded by this entry, taken
+// from the structured error and retry fields when present and from the message text
+// otherwise. ok is false if the entry is not an API error (see IsAPIError).
+func (e *ConversationEntry) APIErrorDetail() (detail APIError, ok bool) {
+	if !e.IsAPIError() {
+		return APIError{}, false
+	}
+
+	detail.Status, detail.Type, detail.Message = parseAPIErrorValue(e.Error)
+	detail.RetryIn = time.Duration(e.RetryInMs * float64(time.Millisecond))
+	detail.RetryAttempt = e.RetryAttempt
+	detail.MaxRetries = e.MaxRetries
+
+	text := strings.TrimSpace(e.GetTextContent())
+	if detail.Message == "" {
+		detail.Message = text
+	}
+	if detail.Status == 0 {
+		if m := apiErrorStatusRe.FindStringSubmatch(text); m != nil {
+			detail.Status, _ = strconv.Atoi(m[1])
+		}
+	}
+	return detail, true
+}
+
+// isSynthetic reports whether Claude Code generated this message itself.
+func (e *ConversationEntry) isSynthetic() bool {
+	var wrapper MessageWrapper
+	return len(e.Message) > 0 && json.Unmarshal(e.Message, &wrapper) == nil && wrapper.Model == syntheticModel
+}
+
+// isAPIErrorText reports whether text starts with a known API error message.
+func isAPIErrorText(text string) bool {
+	text = strings.TrimSpace(text)
+	for _, prefix := range apiErrorPrefixes {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasJSONValue reports whether raw holds a JSON value other than null.
+func hasJSONValue(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) > 0 && !bytes.Equal(raw, []byte("null"))
+}
+
+// parseAPIErrorValue reads the status, type and message of an error field. The field is
+// either a string naming the error or an object such as
+// {"status":529,"error":{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}},
+// whose innermost type and message win.
+func parseAPIErrorValue(raw json.RawMessage) (status int, errType, message string) {
+	if !hasJSONValue(raw) {
+		return 0, "", ""
+	}
+	var name string
+	if json.Unmarshal(raw, &name) == nil {
+		return 0, name, ""
+	}
+
+	var obj map[string]any
+	if json.Unmarshal(raw, &obj) != nil {
+		return 0, "", ""
+	}
+	if s, ok := obj["status"].(float64); ok {
+		status = int(s)
+	}
+	for obj != nil {
+		if t, ok := obj["type"].(string); ok && t != "error" {
+			errType = t
+		}
+		if m, ok := obj["message"].(string); ok && m != "" {
+			message = m
+		}
+		switch inner := obj["error"].(type) {
+		case map[string]any:
+			obj = inner
+		case string:
+			if errType == "" {
+				errType = inner
+			}
+			obj = nil
+		default:
+			obj = nil
+		}
+	}
+	return status, errType, message
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func parseEntry(t *testing.T, line string) ConversationEntry {
+	t.Helper()
+	var entry ConversationEntry
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("unmarshal %s: %v", line, err)
+	}
+	return entry
+}
+
+func TestIsAPIError(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want bool
+	}{
+		{
+			name: "system api_error subtype",
+			line: `{"type":"system","subtype":"api_error","level":"error","retryInMs":5000,"retryAttempt":1,"maxRetries":10}`,
+			want: true,
+		},
+		{
+			name: "system entry with error field",
+			line: `{"type":"system","error":{"status":529}}`,
+			want: true,
+		},
+		{
+			name: "flagged assistant message",
+			line: `{"type":"assistant","isApiErrorMessage":true,"message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"API Error: 500 Internal server error"}]}}`,
+			want: true,
+		},
+		{
+			name: "synthetic assistant message with error text",
+			line: `{"type":"assistant","message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"Claude AI usage limit reached|1760000000"}]}}`,
+			want: true,
+		},
+		{
+			name: "real assistant message quoting an error",
+			line: `{"type":"assistant","message":{"role":"assistant","model":"claude-sonnet-4","content":[{"type":"text","text":"API Error: 529 means the service is overloaded."}]}}`,
+			want: false,
+		},
+		{
+			name: "tool error",
+			line: `{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"API Error: connection refused","is_error":true}]}}`,
+			want: false,
+		},
+		{
+			name: "user text",
+			line: `{"type":"user","error":"rate_limit","message":"API Error: why did this happen?"}`,
+			want: false,
+		},
+		{
+			name: "other system entry",
+			line: `{"type":"system","subtype":"compact_boundary","error":null,"message":"Conversation compacted"}`,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := parseEntry(t, tt.line)
+			if got := entry.IsAPIError(); got != tt.want {
+				t.Errorf("IsAPIError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIErrorDetail_Structured(t *testing.T) {
+	entry := parseEntry(t, `{"type":"system","subtype":"api_error","level":"error",
+		"error":{"status":529,"headers":{},"error":{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}},
+		"retryInMs":1250.5,"retryAttempt":2,"maxRetries":10}`)
+
+	detail, ok := entry.APIErrorDetail()
+	if !ok {
+		t.Fatal("APIErrorDetail() ok = false, want true")
+	}
+	want := APIError{
+		Status:       529,
+		Type:         "overloaded_error",
+		Message:      "Overloaded",
+		RetryIn:      1250500 * time.Microsecond,
+		RetryAttempt: 2,
+		MaxRetries:   10,
+	}
+	if detail != want {
+		t.Errorf("APIErrorDetail() = %+v, want %+v", detail, want)
+	}
+	if detail.IsRateLimit() {
+		t.Error("an overload is not a rate limit")
+	}
+}
+
+func TestAPIErrorDetail_StringError(t *testing.T) {
+	entry := parseEntry(t, `{"type":"assistant","isApiErrorMessage":true,"error":"rate_limit",
+		"message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"API Error: 429 {\"type\":\"error\"}"}]}}`)
+
+	detail, ok := entry.APIErrorDetail()
+	if !ok {
+		t.Fatal("APIErrorDetail() ok = false, want true")
+	}
+	if detail.Type != "rate_limit" || detail.Status != 429 {
+		t.Errorf("APIErrorDetail() = %+v, want type rate_limit and status 429 from the text", detail)
+	}
+	if detail.Message != `API Error: 429 {"type":"error"}` {
+		t.Errorf("Message = %q, want the message text", detail.Message)
+	}
+	if !detail.IsRateLimit() {
+		t.Error("IsRateLimit() = false, want true")
+	}
+}
+
+func TestAPIErrorDetail_NotAPIError(t *testing.T) {
+	entry := parseEntry(t, `{"type":"user","message":"hello"}`)
+	if _, ok := entry.APIErrorDetail(); ok {
+		t.Error("APIErrorDetail() ok = true for a user message")
+	}
+}
+
+func TestAPIError_IsRateLimit(t *testing.T) {
+	tests := []struct {
+		err  APIError
+		want bool
+	}{
+		{APIError{Status: 429}, true},
+		{APIError{Type: "rate_limit_error"}, true},
+		{APIError{Message: "Claude AI usage limit reached|1760000000"}, true},
+		{APIError{Status: 500, Type: "api_error"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.err.IsRateLimit(); got != tt.want {
+			t.Errorf("%+v.IsRateLimit() = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import "strings"
+
+// compactBoundarySubtype is the subtype of the system entry Claude Code records where it
+// compacted the conversation's context.
+const compactBoundarySubtype = "compact_boundary"
+
+// compactionSummaryPrefix starts the summary of the compacted history that Claude Code
+// sends as a user message after compacting. Older versions do not flag the message with
+// isCompactSummary, so the text identifies it.
+const compactionSummaryPrefix = "This session is being continued from a previous conversation"
+
+// IsCompactionBoundary returns true if this entry is the system marker Claude Code
+// records where it compacted the context ("Conversation compacted").
+func (e *ConversationEntry) IsCompactionBoundary() bool {
+	return e.Type == EntryTypeSystem && e.Subtype == compactBoundarySubtype
+}
+
+// IsCompactionSummary returns true if this entry is the summary of the compacted history
+// that the conversation continues from: a user message flagged isCompactSummary, or one
+// starting with the text Claude Code writes for it. Summary entries (EntryTypeSummary)
+// are not compaction summaries: Claude Code writes them to title the session.
+func (e *ConversationEntry) IsCompactionSummary() bool {
+	if e.Type != EntryTypeUser {
+		return false
+	}
+	if e.IsCompactSummary {
+		return true
+	}
+	return len(e.ExtractToolResults()) == 0 && strings.HasPrefix(strings.TrimSpace(e.GetTextContent()), compactionSummaryPrefix)
+}
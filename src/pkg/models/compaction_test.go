@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIsCompactionBoundary(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry ConversationEntry
+		want  bool
+	}{
+		{"compact boundary", ConversationEntry{Type: EntryTypeSystem, Subtype: "compact_boundary"}, true},
+		{"other system entry", ConversationEntry{Type: EntryTypeSystem, Subtype: "api_error"}, false},
+		{"summary entry", ConversationEntry{Type: EntryTypeSummary}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.IsCompactionBoundary(); got != tt.want {
+			t.Errorf("%s: IsCompactionBoundary() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsCompactionSummary(t *testing.T) {
+	continued := `"This session is being continued from a previous conversation that ran out of context. The conversation is summarized below:\n..."`
+	tests := []struct {
+		name  string
+		entry ConversationEntry
+		want  bool
+	}{
+		{"flagged", ConversationEntry{Type: EntryTypeUser, IsCompactSummary: true, Message: json.RawMessage(`"Summary"`)}, true},
+		{"continuation text", ConversationEntry{Type: EntryTypeUser, Message: json.RawMessage(continued)}, true},
+		{"ordinary prompt", ConversationEntry{Type: EntryTypeUser, Message: json.RawMessage(`"Fix the tests"`)}, false},
+		{"session title summary", ConversationEntry{Type: EntryTypeSummary}, false},
+		{"assistant quoting it", ConversationEntry{Type: EntryTypeAssistant, Message: json.RawMessage(continued)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.IsCompactionSummary(); got != tt.want {
+			t.Errorf("%s: IsCompactionSummary() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConversationEntry_IsCompactSummaryField(t *testing.T) {
+	var entry ConversationEntry
+	if err := json.Unmarshal([]byte(`{"type":"user","isCompactSummary":true,"message":{"role":"user","content":"s"}}`), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if !entry.IsCompactSummary || !entry.IsCompactionSummary() {
+		t.Error("isCompactSummary should be read from the entry")
+	}
+}
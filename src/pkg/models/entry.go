@@ -3,6 +3,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -26,6 +27,9 @@ type ToolUseResult struct {
 	Description string `json:"description"` // Human-readable description of the task
 	Prompt      string `json:"prompt"`      // The prompt given to the spawned agent
 	OutputFile  string `json:"outputFile"`  // Path to the agent's output file
+	Interrupted bool   `json:"interrupted"` // Set when the user interrupted the tool (e.g. Bash)
+	Stdout      string `json:"stdout"`      // Standard output of a Bash command
+	Stderr      string `json:"stderr"`      // Standard error of a Bash command
 }
 
 // ConversationEntry represents a single entry in a Claude Code session.
@@ -48,6 +52,49 @@ type ConversationEntry struct {
 	// Additional fields that may be present
 	CacheBreakpoint bool   `json:"cacheBreakpoint,omitempty"`
 	Usertype        string `json:"userType,omitempty"`
+
+	// Cwd and GitBranch record where Claude Code was running when the entry was written.
+	// Both are empty on entries that don't record them (e.g. summaries, older versions);
+	// GitBranch is also empty outside a git repository.
+	Cwd       string `json:"cwd,omitempty"`
+	GitBranch string `json:"gitBranch,omitempty"`
+
+	// Subtype and Level qualify system entries, e.g. subtype "api_error" at level "error"
+	Subtype string `json:"subtype,omitempty"`
+	Level   string `json:"level,omitempty"`
+
+	// Content is the text of a system entry, which has no message (e.g. hook output or
+	// environment context). It is usually a JSON string.
+	Content json.RawMessage `json:"content,omitempty"`
+
+	// IsMeta marks user entries that Claude Code added as context rather than the user
+	// typing them, such as the caveat before local command output.
+	IsMeta bool `json:"isMeta,omitempty"`
+
+	// IsCompactSummary marks the user message holding the summary of the history Claude
+	// Code compacted (see IsCompactionSummary).
+	IsCompactSummary bool `json:"isCompactSummary,omitempty"`
+
+	// API error fields (see IsAPIError). Error holds the structured error, an object or a
+	// string depending on the Claude Code version; the retry fields are set on system
+	// entries recorded while Claude Code retries a failed request.
+	IsAPIErrorMessage bool            `json:"isApiErrorMessage,omitempty"`
+	Error             json.RawMessage `json:"error,omitempty"`
+	RetryInMs         float64         `json:"retryInMs,omitempty"`
+	RetryAttempt      int             `json:"retryAttempt,omitempty"`
+	MaxRetries        int             `json:"maxRetries,omitempty"`
+
+	// Revisions holds the earlier versions of an edited message, oldest first; Message
+	// is the current version. Empty for messages that were never edited.
+	Revisions []MessageRevision `json:"revisions,omitempty"`
+
+	// SourceLine is the 1-based line of this entry in the JSONL file it was read from.
+	// It is set by session.ReadSession and is 0 when unknown.
+	SourceLine int `json:"-"`
+
+	// RawLine is the complete JSONL line this entry was parsed from, including fields
+	// the struct does not model. It is set by session.ReadSession and is nil when unknown.
+	RawLine json.RawMessage `json:"-"`
 }
 
 // GetTimestamp parses and returns the timestamp as a time.Time.
@@ -105,6 +152,81 @@ func (e *ConversationEntry) GetSpawnedAgentID() string {
 	return e.ToolUseResult.AgentID
 }
 
+// SpawnTargetAgentID returns the ID of the agent this entry spawned, recognizing both
+// spawn formats: modern toolUseResult spawns (see IsAgentSpawn) and legacy
+// queue-operation entries carrying the agent ID. Returns an empty string if this entry
+// did not spawn an agent.
+func (e *ConversationEntry) SpawnTargetAgentID() string {
+	if id := e.GetSpawnedAgentID(); id != "" {
+		return id
+	}
+	if e.Type == EntryTypeQueueOperation {
+		return e.AgentID
+	}
+	return ""
+}
+
+// IsSpawnEntry returns true if this entry spawned an agent, in either spawn format
+// (see SpawnTargetAgentID).
+func (e *ConversationEntry) IsSpawnEntry() bool {
+	return e.SpawnTargetAgentID() != ""
+}
+
+// interruptionPrefixes are the messages Claude Code records when the user interrupts a
+// request or rejects a tool call. They are only consulted when no structured field
+// marks the interruption.
+var interruptionPrefixes = []string{
+	"[Request interrupted by user", // also matches "... for tool use]"
+	"The user doesn't want to proceed with this tool use.",
+}
+
+// IsInterruption returns true if this entry records the user interrupting a request or
+// cancelling a tool call rather than a real message. It checks toolUseResult.interrupted
+// first and falls back to the known interruption texts, which must make up every text
+// and tool_result block so that messages merely quoting them are not matched.
+func (e *ConversationEntry) IsInterruption() bool {
+	if e.Type != EntryTypeUser {
+		return false
+	}
+	if e.ToolUseResult != nil && e.ToolUseResult.Interrupted {
+		return true
+	}
+
+	contents, err := e.ParseMessageContent()
+	if err != nil || len(contents) == 0 {
+		return false
+	}
+	results := e.ExtractToolResults()
+	nextResult := 0
+	for _, c := range contents {
+		var text string
+		switch c.Type {
+		case "text":
+			text = c.Text
+		case "tool_result":
+			text = results[nextResult].Content
+			nextResult++
+		default:
+			return false
+		}
+		if !isInterruptionText(text) {
+			return false
+		}
+	}
+	return true
+}
+
+// isInterruptionText reports whether text starts with a known interruption message.
+func isInterruptionText(text string) bool {
+	text = strings.TrimSpace(text)
+	for _, prefix := range interruptionPrefixes {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // MessageContent represents the content of a message.
 type MessageContent struct {
 	Type string `json:"type"`
@@ -120,8 +242,38 @@ type MessageContent struct {
 
 // MessageWrapper represents the Claude Code message envelope with role/content.
 type MessageWrapper struct {
-	Role    string          `json:"role"`
-	Content json.RawMessage `json:"content"`
+	Role       string          `json:"role"`
+	Model      string          `json:"model,omitempty"`
+	Content    json.RawMessage `json:"content"`
+	StopReason string          `json:"stop_reason,omitempty"`
+}
+
+// syntheticModel is the model name Claude Code records for messages it generates itself.
+const syntheticModel = "<synthetic>"
+
+// ModelName returns the model that produced this message, or "" if unknown.
+// Messages generated by Claude Code itself (model "<synthetic>") are reported as unknown.
+func (e *ConversationEntry) ModelName() string {
+	if len(e.Message) == 0 {
+		return ""
+	}
+	var wrapper MessageWrapper
+	if err := json.Unmarshal(e.Message, &wrapper); err != nil || wrapper.Model == syntheticModel {
+		return ""
+	}
+	return wrapper.Model
+}
+
+// ParentSessionID returns the session this entry was carried over from when it was
+// read from the file of session sessionID, or "" if it belongs to that session. When a
+// session is resumed, the new session's file starts with the earlier conversation, whose
+// entries keep the sessionId they were recorded under: that ID is the parent session.
+// Entries without a sessionId are never carried over.
+func (e *ConversationEntry) ParentSessionID(sessionID string) string {
+	if e.SessionID == "" || e.SessionID == sessionID {
+		return ""
+	}
+	return e.SessionID
 }
 
 // ParseMessageContent parses the message field into structured content.
@@ -167,7 +319,8 @@ func parseContent(data json.RawMessage) ([]MessageContent, error) {
 	return nil, nil
 }
 
-// GetTextContent extracts plain text content from the message.
+// GetTextContent extracts plain text content from the message. Only text blocks count:
+// the text inside tool_result blocks belongs to ExtractToolResults.
 func (e *ConversationEntry) GetTextContent() string {
 	contents, err := e.ParseMessageContent()
 	if err != nil {
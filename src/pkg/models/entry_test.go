@@ -3,6 +3,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
@@ -394,6 +395,11 @@ func TestParseMessageContent(t *testing.T) {
 			messageJSON: `{"role": "user", "content": "Hello from wrapper"}`,
 			expectText:  "Hello from wrapper",
 		},
+		{
+			name:        "wrapped assistant string content",
+			messageJSON: `{"role": "assistant", "content": "plain text"}`,
+			expectText:  "plain text",
+		},
 		{
 			name:        "array content with text",
 			messageJSON: `{"role": "assistant", "content": [{"type": "text", "text": "Response text"}]}`,
@@ -517,3 +523,175 @@ func TestMultipleAgentSpawnDetection(t *testing.T) {
 		}
 	}
 }
+
+func TestModelName(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"wrapped assistant message", `{"role":"assistant","model":"claude-opus-4-1","content":[{"type":"text","text":"hi"}]}`, "claude-opus-4-1"},
+		{"no model field", `{"role":"assistant","content":"hi"}`, ""},
+		{"synthetic message", `{"role":"assistant","model":"<synthetic>","content":"hi"}`, ""},
+		{"plain string message", `"hello"`, ""},
+		{"content array", `[{"type":"text","text":"hi"}]`, ""},
+		{"empty message", ``, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ConversationEntry{Type: EntryTypeAssistant, Message: json.RawMessage(tt.message)}
+			if got := entry.ModelName(); got != tt.want {
+				t.Errorf("ModelName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParentSessionID(t *testing.T) {
+	tests := []struct {
+		name      string
+		entryID   string
+		sessionID string
+		want      string
+	}{
+		{"own session", "s2", "s2", ""},
+		{"carried over", "s1", "s2", "s1"},
+		{"no session ID", "", "s2", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ConversationEntry{SessionID: tt.entryID}
+			if got := entry.ParentSessionID(tt.sessionID); got != tt.want {
+				t.Errorf("ParentSessionID(%q) = %q, want %q", tt.sessionID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsInterruption_StructuredField(t *testing.T) {
+	line := `{"uuid":"u1","type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"partial output"}]},"toolUseResult":{"stdout":"partial output","interrupted":true}}`
+	var entry ConversationEntry
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !entry.IsInterruption() {
+		t.Error("toolUseResult.interrupted should mark the entry as an interruption")
+	}
+
+	entry.ToolUseResult.Interrupted = false
+	if entry.IsInterruption() {
+		t.Error("tool result with ordinary output should not be an interruption")
+	}
+}
+
+func TestIsInterruption_TextFallback(t *testing.T) {
+	tests := []struct {
+		name      string
+		entryType EntryType
+		message   string
+		want      bool
+	}{
+		{"interrupted request string", EntryTypeUser, `{"role":"user","content":"[Request interrupted by user]"}`, true},
+		{"interrupted request block", EntryTypeUser, `{"role":"user","content":[{"type":"text","text":"[Request interrupted by user for tool use]"}]}`, true},
+		{"rejected tool", EntryTypeUser, `{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"The user doesn't want to proceed with this tool use. The tool use was rejected.","is_error":true}]}`, true},
+		{"rejected tool with interruption text", EntryTypeUser, `{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"The user doesn't want to proceed with this tool use."},{"type":"text","text":"[Request interrupted by user for tool use]"}]}`, true},
+		{"ordinary message", EntryTypeUser, `{"role":"user","content":"please continue"}`, false},
+		{"message quoting the text", EntryTypeUser, `{"role":"user","content":"why did I see [Request interrupted by user]?"}`, false},
+		{"interruption plus real text", EntryTypeUser, `{"role":"user","content":[{"type":"text","text":"[Request interrupted by user]"},{"type":"text","text":"try again"}]}`, false},
+		{"assistant message", EntryTypeAssistant, `{"role":"assistant","content":"[Request interrupted by user]"}`, false},
+		{"empty message", EntryTypeUser, ``, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ConversationEntry{Type: tt.entryType, Message: json.RawMessage(tt.message)}
+			if got := entry.IsInterruption(); got != tt.want {
+				t.Errorf("IsInterruption() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpawnTargetAgentID(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    ConversationEntry
+		expected string
+	}{
+		{
+			name: "modern toolUseResult spawn",
+			entry: ConversationEntry{
+				Type:          EntryTypeUser,
+				ToolUseResult: &ToolUseResult{Status: "async_launched", AgentID: "a6f6578"},
+			},
+			expected: "a6f6578",
+		},
+		{
+			name:     "legacy queue operation",
+			entry:    ConversationEntry{Type: EntryTypeQueueOperation, AgentID: "a12eb64"},
+			expected: "a12eb64",
+		},
+		{
+			name:     "queue operation without agent",
+			entry:    ConversationEntry{Type: EntryTypeQueueOperation},
+			expected: "",
+		},
+		{
+			name:     "agent entry is not a spawn",
+			entry:    ConversationEntry{Type: EntryTypeAssistant, AgentID: "a12eb64"},
+			expected: "",
+		},
+		{
+			name: "completed task result is not a spawn",
+			entry: ConversationEntry{
+				Type:          EntryTypeUser,
+				ToolUseResult: &ToolUseResult{Status: "completed", AgentID: "a6f6578"},
+			},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.SpawnTargetAgentID(); got != tt.expected {
+				t.Errorf("SpawnTargetAgentID() = %q, expected %q", got, tt.expected)
+			}
+			if got := tt.entry.IsSpawnEntry(); got != (tt.expected != "") {
+				t.Errorf("IsSpawnEntry() = %v, expected %v", got, tt.expected != "")
+			}
+		})
+	}
+}
+
+func TestWorkingContextParsing(t *testing.T) {
+	jsonData := `{"type":"user","uuid":"u1","cwd":"/Users/dev/app","gitBranch":"feature/login","version":"2.1.3","message":"hi"}`
+
+	var entry ConversationEntry
+	if err := json.Unmarshal([]byte(jsonData), &entry); err != nil {
+		t.Fatalf("Failed to parse entry: %v", err)
+	}
+	if entry.Cwd != "/Users/dev/app" {
+		t.Errorf("Cwd = %q, want /Users/dev/app", entry.Cwd)
+	}
+	if entry.GitBranch != "feature/login" {
+		t.Errorf("GitBranch = %q, want feature/login", entry.GitBranch)
+	}
+
+	// Entries without the fields leave them empty and don't write them back out
+	var bare ConversationEntry
+	if err := json.Unmarshal([]byte(`{"type":"summary"}`), &bare); err != nil {
+		t.Fatalf("Failed to parse entry: %v", err)
+	}
+	if bare.Cwd != "" || bare.GitBranch != "" {
+		t.Errorf("Cwd, GitBranch = %q, %q; want empty", bare.Cwd, bare.GitBranch)
+	}
+	data, err := json.Marshal(bare)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "cwd") || strings.Contains(string(data), "gitBranch") {
+		t.Errorf("marshaled bare entry should omit cwd and gitBranch: %s", data)
+	}
+}
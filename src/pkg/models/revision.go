@@ -0,0 +1,25 @@
+package models
+
+import "encoding/json"
+
+// MessageRevision is an earlier version of an edited message (see
+// ConversationEntry.Revisions).
+type MessageRevision struct {
+	// Timestamp is when this version was written, if recorded.
+	Timestamp string `json:"timestamp,omitempty"`
+
+	// Message holds this version in the same shape as ConversationEntry.Message.
+	Message json.RawMessage `json:"message,omitempty"`
+}
+
+// GetTextContent returns the text of this version, as ConversationEntry.GetTextContent
+// does for the current one.
+func (r MessageRevision) GetTextContent() string {
+	entry := ConversationEntry{Message: r.Message}
+	return entry.GetTextContent()
+}
+
+// HasRevisions returns true if this message was edited and records earlier versions.
+func (e *ConversationEntry) HasRevisions() bool {
+	return len(e.Revisions) > 0
+}
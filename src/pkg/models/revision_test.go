@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConversationEntry_Revisions(t *testing.T) {
+	line := `{"uuid":"u1","type":"user","message":{"role":"user","content":"Fix the tests"},` +
+		`"revisions":[{"timestamp":"2025-01-01T10:00:00Z","message":{"role":"user","content":"Fix the test"}},` +
+		`{"message":{"role":"user","content":[{"type":"text","text":"Fix the tests please"}]}}]}`
+	var entry ConversationEntry
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if !entry.HasRevisions() || len(entry.Revisions) != 2 {
+		t.Fatalf("Revisions = %+v, want 2 earlier versions", entry.Revisions)
+	}
+	if got := entry.Revisions[0].Timestamp; got != "2025-01-01T10:00:00Z" {
+		t.Errorf("Revisions[0].Timestamp = %q", got)
+	}
+	for i, want := range []string{"Fix the test", "Fix the tests please"} {
+		if got := entry.Revisions[i].GetTextContent(); got != want {
+			t.Errorf("Revisions[%d].GetTextContent() = %q, want %q", i, got, want)
+		}
+	}
+
+	var plain ConversationEntry
+	if err := json.Unmarshal([]byte(`{"uuid":"u2","type":"user","message":{"role":"user","content":"Hi"}}`), &plain); err != nil {
+		t.Fatal(err)
+	}
+	if plain.HasRevisions() {
+		t.Error("a message without revisions should not report any")
+	}
+	if got := (MessageRevision{}).GetTextContent(); got != "" {
+		t.Errorf("empty revision text = %q, want empty", got)
+	}
+}
@@ -15,6 +15,7 @@ type Session struct {
 	Created      time.Time `json:"created"`
 	Modified     time.Time `json:"modified"`
 	GitBranch    string    `json:"gitBranch,omitempty"`
+	Cwd          string    `json:"cwd,omitempty"`
 	IsSidechain  bool      `json:"isSidechain"`
 }
 
@@ -65,6 +66,10 @@ type Agent struct {
 	EntryCount int     `json:"entryCount"`
 	SpawnedBy  *string `json:"spawnedBy,omitempty"` // parentUuid of spawning queue-operation
 	AgentType  string  `json:"agentType,omitempty"` // e.g., "prompt_suggestion", "explore"
+
+	// ParseErrors counts the lines of the agent file that could not be parsed; EntryCount
+	// counts only the entries that could.
+	ParseErrors int `json:"parseErrors,omitempty"`
 }
 
 // Project represents a Claude Code project directory.
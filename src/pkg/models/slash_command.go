@@ -0,0 +1,86 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Claude Code records a slash command as a user message of tags such as
+// "<command-name>/compact</command-name><command-message>compact</command-message>
+// <command-args>focus on tests</command-args>".
+var (
+	commandNameRe = regexp.MustCompile(`(?s)<command-name>\s*(.*?)\s*</command-name>`)
+	commandArgsRe = regexp.MustCompile(`(?s)<command-args>\s*(.*?)\s*</command-args>`)
+)
+
+// slashCommandNameRe matches the name of a slash command: a leading slash, a word, and
+// optional ":"-separated parts for custom commands ("/project:deploy"). Further
+// slashes or dots make it a path ("/usr/bin", "/main.go") rather than a command.
+var slashCommandNameRe = regexp.MustCompile(`^/[A-Za-z][A-Za-z0-9_-]*(:[A-Za-z0-9_-]+)*$`)
+
+// builtinSlashCommands are the Claude Code built-in commands recognized by the
+// leading-slash heuristic even when followed by arguments.
+var builtinSlashCommands = map[string]bool{
+	"/add-dir": true, "/agents": true, "/bug": true, "/clear": true, "/compact": true,
+	"/config": true, "/context": true, "/cost": true, "/doctor": true, "/exit": true,
+	"/export": true, "/help": true, "/hooks": true, "/init": true, "/login": true,
+	"/logout": true, "/mcp": true, "/memory": true, "/model": true, "/permissions": true,
+	"/pr-comments": true, "/resume": true, "/review": true, "/rewind": true,
+	"/status": true, "/terminal-setup": true, "/todos": true, "/vim": true,
+}
+
+// SlashCommand returns the slash command this user entry invokes (e.g. "/compact") and
+// true, or "" and false if it is not a slash command. The name comes from the
+// <command-name> tag Claude Code records when present. Otherwise a single-line message
+// counts as a command only if its first word is command-shaped (see slashCommandNameRe)
+// and it is either a built-in command or the whole message, so a message such as
+// "/etc/hosts is missing" or "/tmp is full" stays a message.
+func (e *ConversationEntry) SlashCommand() (string, bool) {
+	name, _, ok := e.parseSlashCommand()
+	return name, ok
+}
+
+// SlashCommandArgs returns the arguments of the slash command this entry invokes
+// ("" if it has none or is not a slash command).
+func (e *ConversationEntry) SlashCommandArgs() string {
+	_, args, _ := e.parseSlashCommand()
+	return args
+}
+
+// parseSlashCommand returns the command name and arguments of a slash command entry.
+func (e *ConversationEntry) parseSlashCommand() (name, args string, ok bool) {
+	if e.Type != EntryTypeUser || len(e.ExtractToolResults()) > 0 {
+		return "", "", false
+	}
+	text := strings.TrimSpace(e.GetTextContent())
+
+	// Structured form: the message is made of <command-*> tags
+	if strings.HasPrefix(text, "<command-") {
+		m := commandNameRe.FindStringSubmatch(text)
+		if m == nil || m[1] == "" {
+			return "", "", false
+		}
+		name = m[1]
+		if !strings.HasPrefix(name, "/") {
+			name = "/" + name
+		}
+		if a := commandArgsRe.FindStringSubmatch(text); a != nil {
+			args = a[1]
+		}
+		return name, args, true
+	}
+
+	// Heuristic: a single line starting with a command name
+	if !strings.HasPrefix(text, "/") || strings.Contains(text, "\n") {
+		return "", "", false
+	}
+	name, args, _ = strings.Cut(text, " ")
+	if !slashCommandNameRe.MatchString(name) {
+		return "", "", false
+	}
+	args = strings.TrimSpace(args)
+	if args != "" && !builtinSlashCommands[name] {
+		return "", "", false
+	}
+	return name, args, true
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSlashCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		entryType EntryType
+		message   string
+		wantName  string
+		wantArgs  string
+		wantOK    bool
+	}{
+		{"structured", EntryTypeUser, `{"role":"user","content":"<command-message>compact</command-message>\n<command-name>/compact</command-name>\n<command-args>focus on tests</command-args>"}`, "/compact", "focus on tests", true},
+		{"structured without args", EntryTypeUser, `{"role":"user","content":"<command-name>/clear</command-name>\n<command-message>clear</command-message>\n<command-args></command-args>"}`, "/clear", "", true},
+		{"structured name without slash", EntryTypeUser, `{"role":"user","content":"<command-name>review</command-name>"}`, "/review", "", true},
+		{"structured custom command", EntryTypeUser, `{"role":"user","content":[{"type":"text","text":"<command-name>/project:deploy</command-name><command-args>staging</command-args>"}]}`, "/project:deploy", "staging", true},
+		{"bare built-in", EntryTypeUser, `"/compact"`, "/compact", "", true},
+		{"built-in with args", EntryTypeUser, `"/model opus"`, "/model", "opus", true},
+		{"bare custom command", EntryTypeUser, `"/project:deploy"`, "/project:deploy", "", true},
+		{"file path", EntryTypeUser, `"/etc/hosts is missing"`, "", "", false},
+		{"bare file path", EntryTypeUser, `"/usr/local/bin"`, "", "", false},
+		{"file name", EntryTypeUser, `"/main.go"`, "", "", false},
+		{"directory in a sentence", EntryTypeUser, `"/tmp is full"`, "", "", false},
+		{"multi-line message", EntryTypeUser, `"/compact\nand then keep going"`, "", "", false},
+		{"slash mid-message", EntryTypeUser, `"please run /compact"`, "", "", false},
+		{"tags quoted in prose", EntryTypeUser, `"what does <command-name>/x</command-name> mean?"`, "", "", false},
+		{"empty structured name", EntryTypeUser, `"<command-name></command-name>"`, "", "", false},
+		{"assistant message", EntryTypeAssistant, `{"role":"assistant","content":"/compact"}`, "", "", false},
+		{"tool result", EntryTypeUser, `{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"/compact"}]}`, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ConversationEntry{Type: tt.entryType, Message: json.RawMessage(tt.message)}
+			name, ok := entry.SlashCommand()
+			if name != tt.wantName || ok != tt.wantOK {
+				t.Errorf("SlashCommand() = %q, %v; want %q, %v", name, ok, tt.wantName, tt.wantOK)
+			}
+			if args := entry.SlashCommandArgs(); args != tt.wantArgs {
+				t.Errorf("SlashCommandArgs() = %q, want %q", args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// routineStopReasons are the stop reasons of responses that ended as intended: the
+// model finished its turn, called a tool, or hit a stop sequence.
+var routineStopReasons = map[string]bool{
+	"":              true,
+	"end_turn":      true,
+	"tool_use":      true,
+	"stop_sequence": true,
+}
+
+// StopReason returns why the model stopped generating this message (the message's
+// stop_reason), e.g. "end_turn", "tool_use", "max_tokens" or "refusal", or "" if none
+// was recorded.
+func (e *ConversationEntry) StopReason() string {
+	if len(e.Message) == 0 {
+		return ""
+	}
+	var wrapper MessageWrapper
+	if err := json.Unmarshal(e.Message, &wrapper); err != nil {
+		return ""
+	}
+	return wrapper.StopReason
+}
+
+// IsStopNotice returns true if this is an assistant entry with no text or tool calls
+// whose stop reason is all it has to show, such as a response cut off at max_tokens or
+// a refusal. Entries that stopped routinely (end_turn, tool_use, stop_sequence) are not
+// stop notices, however empty.
+func (e *ConversationEntry) IsStopNotice() bool {
+	if e.Type != EntryTypeAssistant || routineStopReasons[e.StopReason()] {
+		return false
+	}
+	return strings.TrimSpace(e.GetTextContent()) == "" && len(e.ExtractToolCalls()) == 0
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStopReason(t *testing.T) {
+	entry := ConversationEntry{Type: EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[],"stop_reason":"max_tokens"}`)}
+	if got := entry.StopReason(); got != "max_tokens" {
+		t.Errorf("StopReason() = %q, want max_tokens", got)
+	}
+	for _, msg := range []string{``, `"plain text"`, `{"role":"assistant","content":[]}`} {
+		entry := ConversationEntry{Type: EntryTypeAssistant, Message: json.RawMessage(msg)}
+		if got := entry.StopReason(); got != "" {
+			t.Errorf("StopReason(%s) = %q, want empty", msg, got)
+		}
+	}
+}
+
+func TestIsStopNotice(t *testing.T) {
+	assistant := func(msg string) ConversationEntry {
+		return ConversationEntry{Type: EntryTypeAssistant, Message: json.RawMessage(msg)}
+	}
+	tests := []struct {
+		name  string
+		entry ConversationEntry
+		want  bool
+	}{
+		{"truncated without content", assistant(`{"role":"assistant","content":[],"stop_reason":"max_tokens"}`), true},
+		{"refusal with blank text", assistant(`{"role":"assistant","content":[{"type":"text","text":"  "}],"stop_reason":"refusal"}`), true},
+		{"truncated with text", assistant(`{"role":"assistant","content":[{"type":"text","text":"Partial"}],"stop_reason":"max_tokens"}`), false},
+		{"truncated with tool call", assistant(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Read","input":{}}],"stop_reason":"max_tokens"}`), false},
+		{"empty end_turn", assistant(`{"role":"assistant","content":[],"stop_reason":"end_turn"}`), false},
+		{"empty without stop reason", assistant(`{"role":"assistant","content":[]}`), false},
+		{"user entry", ConversationEntry{Type: EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":[],"stop_reason":"max_tokens"}`)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.IsStopNotice(); got != tt.want {
+			t.Errorf("%s: IsStopNotice() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ package models
 import (
 	"encoding/json"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -19,9 +20,30 @@ type ToolResult struct {
 	ToolUseID string `json:"tool_use_id"`
 	Content   string `json:"content"`
 	IsError   bool   `json:"is_error"`
+	EntryUUID string `json:"-"` // UUID of the user entry carrying the result
+
+	// Stdout and Stderr are the separate output streams of a Bash command, from the
+	// entry's toolUseResult. Both are empty when the result does not record them.
+	Stdout string `json:"-"`
+	Stderr string `json:"-"`
+}
+
+// HasStreams reports whether the result records separate stdout and stderr.
+func (r ToolResult) HasStreams() bool {
+	return r.Stdout != "" || r.Stderr != ""
 }
 
-// ExtractToolCalls extracts tool_use blocks from assistant message content.
+// toolUseBlockTypes are the content block types that represent a tool call.
+// Server-side tools (e.g. web search) and MCP connector tools use their own block
+// types but carry the same id/name/input fields.
+var toolUseBlockTypes = map[string]bool{
+	"tool_use":        true,
+	"server_tool_use": true,
+	"mcp_tool_use":    true,
+}
+
+// ExtractToolCalls extracts tool call blocks (tool_use and the server-side variants in
+// toolUseBlockTypes) from assistant message content.
 // Returns an empty slice if the entry is not an assistant message or has no tool calls.
 func (e *ConversationEntry) ExtractToolCalls() []ToolUse {
 	if e.Type != EntryTypeAssistant {
@@ -35,7 +57,7 @@ func (e *ConversationEntry) ExtractToolCalls() []ToolUse {
 
 	var tools []ToolUse
 	for _, c := range contents {
-		if c.Type != "tool_use" {
+		if !toolUseBlockTypes[c.Type] {
 			continue
 		}
 
@@ -44,12 +66,14 @@ func (e *ConversationEntry) ExtractToolCalls() []ToolUse {
 			Name: c.Name,
 		}
 
-		// Parse the input field if present
+		// Parse the input field if present; some server tools nest it in content instead
 		if len(c.Input) > 0 {
 			var input map[string]any
 			if err := json.Unmarshal(c.Input, &input); err == nil {
 				tool.Input = input
 			}
+		} else if len(c.Content) > 0 {
+			tool.Input = parseNestedToolInput(c.Content)
 		}
 
 		tools = append(tools, tool)
@@ -58,8 +82,23 @@ func (e *ConversationEntry) ExtractToolCalls() []ToolUse {
 	return tools
 }
 
+// parseNestedToolInput normalizes a server tool's nested content into a tool input map.
+// Object content is used as-is; any other JSON value is wrapped as {"content": value}.
+func parseNestedToolInput(content json.RawMessage) map[string]any {
+	var input map[string]any
+	if err := json.Unmarshal(content, &input); err == nil {
+		return input
+	}
+	var value any
+	if err := json.Unmarshal(content, &value); err != nil {
+		return nil
+	}
+	return map[string]any{"content": value}
+}
+
 // ExtractToolResults extracts tool results from user message content.
-// User messages with tool results have content as an array of tool_result objects.
+// User messages with tool results have content as an array of tool_result objects,
+// possibly mixed with text blocks, which are left to GetTextContent.
 // Returns an empty slice if the entry is not a user message or has no tool results.
 func (e *ConversationEntry) ExtractToolResults() []ToolResult {
 	if e.Type != EntryTypeUser {
@@ -79,6 +118,7 @@ func (e *ConversationEntry) ExtractToolResults() []ToolResult {
 
 		result := ToolResult{
 			ToolUseID: c.ToolResultID,
+			EntryUUID: e.UUID,
 		}
 
 		// Parse content - can be string or array
@@ -112,6 +152,12 @@ func (e *ConversationEntry) ExtractToolResults() []ToolResult {
 		results = append(results, result)
 	}
 
+	// toolUseResult describes the entry's result, so it only applies to a lone one
+	if len(results) == 1 && e.ToolUseResult != nil {
+		results[0].Stdout = e.ToolUseResult.Stdout
+		results[0].Stderr = e.ToolUseResult.Stderr
+	}
+
 	return results
 }
 
@@ -192,3 +238,46 @@ func (e *ConversationEntry) MatchesToolInput(pattern string) bool {
 
 	return false
 }
+
+// LookupInputField returns the value at path in a tool input, and whether it exists.
+// Path segments are separated by dots and step into nested objects by key, or into
+// arrays by index: "command", "options.timeout", "todos.0.content".
+func (t ToolUse) LookupInputField(path string) (any, bool) {
+	var value any = t.Input
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// InputFieldString returns the value at path in a tool input as text for matching:
+// strings as-is, other values as JSON. ok is false when the field is missing or null.
+func (t ToolUse) InputFieldString(path string) (text string, ok bool) {
+	value, found := t.LookupInputField(path)
+	if !found || value == nil {
+		return "", false
+	}
+	if s, isString := value.(string); isString {
+		return s, true
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -371,6 +372,20 @@ func TestExtractToolCalls_NoToolUse(t *testing.T) {
 	}
 }
 
+func TestExtractToolCalls_StringContent(t *testing.T) {
+	entry := ConversationEntry{
+		Type:    EntryTypeAssistant,
+		Message: json.RawMessage(`{"role":"assistant","content":"plain text"}`),
+	}
+
+	if tools := entry.ExtractToolCalls(); len(tools) != 0 {
+		t.Errorf("ExtractToolCalls() on string content returned %d tools, want 0", len(tools))
+	}
+	if text := entry.GetTextContent(); text != "plain text" {
+		t.Errorf("GetTextContent() = %q, want %q", text, "plain text")
+	}
+}
+
 func TestExtractToolResults_SingleResult(t *testing.T) {
 	entry := ConversationEntry{
 		Type: EntryTypeUser,
@@ -1244,3 +1259,166 @@ func TestExtractToolResults_ContentAsNumber(t *testing.T) {
 		t.Errorf("Content = %q, want empty string for non-string content", results[0].Content)
 	}
 }
+
+func TestExtractToolCalls_ServerToolUse(t *testing.T) {
+	entry := ConversationEntry{
+		Type: EntryTypeAssistant,
+		Message: json.RawMessage(`{
+			"role": "assistant",
+			"content": [
+				{"type": "text", "text": "Searching the web."},
+				{"type": "server_tool_use", "id": "srvtoolu_01", "name": "web_search", "input": {"query": "go generics"}},
+				{"type": "web_search_tool_result", "tool_use_id": "srvtoolu_01", "content": []},
+				{"type": "mcp_tool_use", "id": "mcptoolu_01", "name": "list_issues", "input": {"repo": "x/y"}},
+				{"type": "tool_use", "id": "toolu_01", "name": "Bash", "input": {"command": "ls"}}
+			]
+		}`),
+	}
+
+	tools := entry.ExtractToolCalls()
+
+	if len(tools) != 3 {
+		t.Fatalf("ExtractToolCalls() returned %d tools, want 3: %+v", len(tools), tools)
+	}
+	if tools[0].ID != "srvtoolu_01" || tools[0].Name != "web_search" || tools[0].Input["query"] != "go generics" {
+		t.Errorf("server tool = %+v, want web_search with query", tools[0])
+	}
+	if tools[1].ID != "mcptoolu_01" || tools[1].Input["repo"] != "x/y" {
+		t.Errorf("mcp tool = %+v, want list_issues with repo", tools[1])
+	}
+	if tools[2].Name != "Bash" {
+		t.Errorf("regular tool = %+v, want Bash", tools[2])
+	}
+}
+
+func TestExtractToolCalls_NestedContentInput(t *testing.T) {
+	entry := ConversationEntry{
+		Type: EntryTypeAssistant,
+		Message: json.RawMessage(`{
+			"role": "assistant",
+			"content": [
+				{"type": "server_tool_use", "id": "srv_1", "name": "code_execution", "content": {"code": "print(1)"}},
+				{"type": "server_tool_use", "id": "srv_2", "name": "web_fetch", "content": "https://example.com"}
+			]
+		}`),
+	}
+
+	tools := entry.ExtractToolCalls()
+
+	if len(tools) != 2 {
+		t.Fatalf("ExtractToolCalls() returned %d tools, want 2", len(tools))
+	}
+	if tools[0].Input["code"] != "print(1)" {
+		t.Errorf("object content should become the input, got %+v", tools[0].Input)
+	}
+	if tools[1].Input["content"] != "https://example.com" {
+		t.Errorf("non-object content should be wrapped under \"content\", got %+v", tools[1].Input)
+	}
+}
+
+func TestExtractToolResults_BashStreams(t *testing.T) {
+	entry := ConversationEntry{
+		Type:          EntryTypeUser,
+		Message:       json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"out\nerr"}]}`),
+		ToolUseResult: &ToolUseResult{Stdout: "out", Stderr: "err"},
+	}
+
+	results := entry.ExtractToolResults()
+	if len(results) != 1 {
+		t.Fatalf("ExtractToolResults() returned %d results, want 1", len(results))
+	}
+	if results[0].Stdout != "out" || results[0].Stderr != "err" || !results[0].HasStreams() {
+		t.Errorf("result streams = (%q, %q), want (out, err)", results[0].Stdout, results[0].Stderr)
+	}
+}
+
+func TestExtractToolResults_BashStreamsNeedSingleResult(t *testing.T) {
+	entry := ConversationEntry{
+		Type: EntryTypeUser,
+		Message: json.RawMessage(`{"role":"user","content":[
+			{"type":"tool_result","tool_use_id":"t1","content":"a"},
+			{"type":"tool_result","tool_use_id":"t2","content":"b"}]}`),
+		ToolUseResult: &ToolUseResult{Stdout: "a"},
+	}
+
+	for _, r := range entry.ExtractToolResults() {
+		if r.HasStreams() {
+			t.Errorf("result %s should not take streams shared by several results", r.ToolUseID)
+		}
+	}
+}
+
+func TestToolUse_LookupInputField(t *testing.T) {
+	tool := ToolUse{Input: map[string]any{
+		"command": "git status",
+		"options": map[string]any{"timeout": float64(30), "env": map[string]any{"CI": "1"}},
+		"todos":   []any{map[string]any{"content": "first"}, map[string]any{"content": "second"}},
+		"empty":   nil,
+	}}
+
+	tests := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{"command", "git status", true},
+		{"options.timeout", "30", true},
+		{"options.env.CI", "1", true},
+		{"options.env", `{"CI":"1"}`, true},
+		{"todos.1.content", "second", true},
+		{"todos.2.content", "", false},
+		{"todos.x", "", false},
+		{"missing", "", false},
+		{"command.sub", "", false},
+		{"options.missing", "", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := tool.InputFieldString(tt.path)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("InputFieldString(%q) = %q, %v; want %q, %v", tt.path, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	if _, ok := (ToolUse{}).LookupInputField("command"); ok {
+		t.Error("LookupInputField on a tool without input should find nothing")
+	}
+}
+
+func TestMixedUserEntry_TextAndResultsStaySeparate(t *testing.T) {
+	// Result content given as text blocks must not leak into the entry's text, and the
+	// entry's text must not leak into a result
+	entry := ConversationEntry{
+		UUID: "u1",
+		Type: EntryTypeUser,
+		Message: json.RawMessage(`{
+			"role": "user",
+			"content": [
+				{"type": "tool_result", "tool_use_id": "toolu_01", "content": [{"type": "text", "text": "RESULT ONE"}]},
+				{"type": "text", "text": "Please also check the tests"},
+				{"type": "tool_result", "tool_use_id": "toolu_02", "content": "RESULT TWO"}
+			]
+		}`),
+	}
+
+	if got := entry.GetTextContent(); got != "Please also check the tests" {
+		t.Errorf("GetTextContent() = %q, want only the text block", got)
+	}
+
+	results := entry.ExtractToolResults()
+	if len(results) != 2 {
+		t.Fatalf("ExtractToolResults() returned %d results, want 2", len(results))
+	}
+	if results[0].ToolUseID != "toolu_01" || results[0].Content != "RESULT ONE" {
+		t.Errorf("Result 0 = %+v", results[0])
+	}
+	if results[1].ToolUseID != "toolu_02" || results[1].Content != "RESULT TWO" {
+		t.Errorf("Result 1 = %+v", results[1])
+	}
+	for _, r := range results {
+		if strings.Contains(r.Content, "Please also check") {
+			t.Errorf("result %s should not contain the entry's text", r.ToolUseID)
+		}
+	}
+}
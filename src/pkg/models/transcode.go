@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// LookupEncoding returns the single-byte encoding named name, such as "latin1",
+// "windows-1252" or "koi8-r" (any WHATWG label of a single-byte encoding), for
+// ToUTF8. Multi-byte encodings are not supported: their bytes cannot be told apart
+// from UTF-8 one run at a time.
+func LookupEncoding(name string) (encoding.Encoding, error) {
+	enc, err := htmlindex.Get(strings.TrimSpace(name))
+	if err != nil {
+		return nil, fmt.Errorf("unknown encoding %q", name)
+	}
+	if _, ok := enc.(*charmap.Charmap); !ok {
+		return nil, fmt.Errorf("encoding %q is not a single-byte encoding", name)
+	}
+	return enc, nil
+}
+
+// ToUTF8 returns data with each run of bytes that is not valid UTF-8 decoded from enc,
+// so output in a legacy encoding mixed into UTF-8 text reads correctly. Valid UTF-8 is
+// left as it is, so text is never transcoded twice. Bytes enc cannot decode become
+// U+FFFD. Data that is valid UTF-8 is returned unchanged, as is all data if enc is nil.
+func ToUTF8(data []byte, enc encoding.Encoding) []byte {
+	if enc == nil || utf8.Valid(data) {
+		return data
+	}
+
+	decoder := enc.NewDecoder()
+	out := make([]byte, 0, len(data)+len(data)/2)
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r != utf8.RuneError || size != 1 {
+			out = append(out, data[i:i+size]...)
+			i += size
+			continue
+		}
+
+		// Decode the whole run of invalid bytes at once
+		end := i + 1
+		for end < len(data) {
+			if r, size := utf8.DecodeRune(data[end:]); r != utf8.RuneError || size != 1 {
+				break
+			}
+			end++
+		}
+		decoded, err := decoder.Bytes(data[i:end])
+		if err != nil {
+			decoded = []byte(strings.Repeat(string(utf8.RuneError), end-i))
+		}
+		out = append(out, decoded...)
+		i = end
+	}
+	return out
+}
+
+// WithToolResultsUTF8 returns a copy of the entry with the tool results it carries
+// converted to UTF-8 by ToUTF8, and whether anything changed. Tool output is where
+// bytes in other encodings appear, from the commands a session ran; other entries, and
+// entries that are valid UTF-8, are returned as they are.
+func (e *ConversationEntry) WithToolResultsUTF8(enc encoding.Encoding) (ConversationEntry, bool) {
+	if enc == nil || e.Type != EntryTypeUser {
+		return *e, false
+	}
+	if utf8.Valid(e.Message) && utf8.Valid(e.RawLine) {
+		return *e, false
+	}
+	if len(e.ExtractToolResults()) == 0 && e.ToolUseResult == nil {
+		return *e, false
+	}
+
+	out := *e
+	out.Message = ToUTF8(e.Message, enc)
+
+	// The stdout and stderr of toolUseResult were decoded with the line, so decode them
+	// again from the transcoded line
+	if e.ToolUseResult != nil && !utf8.Valid(e.RawLine) {
+		var line struct {
+			ToolUseResult *ToolUseResult `json:"toolUseResult"`
+		}
+		if err := json.Unmarshal(ToUTF8(e.RawLine, enc), &line); err == nil && line.ToolUseResult != nil {
+			out.ToolUseResult = line.ToolUseResult
+		}
+	}
+	return out, true
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLookupEncoding(t *testing.T) {
+	for _, name := range []string{"latin1", "ISO-8859-1", "windows-1252", " koi8-r ", "iso-8859-15"} {
+		if _, err := LookupEncoding(name); err != nil {
+			t.Errorf("LookupEncoding(%q) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "no-such-encoding", "shift_jis", "utf-16le", "gbk"} {
+		if _, err := LookupEncoding(name); err == nil {
+			t.Errorf("LookupEncoding(%q) should fail", name)
+		}
+	}
+}
+
+func TestToUTF8(t *testing.T) {
+	latin1, err := LookupEncoding("latin1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{"latin-1 bytes", []byte("caf\xe9 na\xefve"), "café naïve"},
+		{"valid UTF-8 untouched", []byte("café ✓"), "café ✓"},
+		{"mixed", []byte("caf\xe9 and café"), "café and café"},
+		{"run of invalid bytes", []byte("\xc0\xe9\xe8!"), "Àéè!"},
+		{"windows-1252 punctuation", []byte("\x93quoted\x94"), "“quoted”"},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(ToUTF8(tt.in, latin1)); got != tt.want {
+				t.Errorf("ToUTF8(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToUTF8_NoDoubleTranscoding(t *testing.T) {
+	latin1, _ := LookupEncoding("latin1")
+	once := ToUTF8([]byte("caf\xe9"), latin1)
+	if twice := ToUTF8(once, latin1); !bytes.Equal(once, twice) {
+		t.Errorf("ToUTF8 of UTF-8 output = %q, want it unchanged (%q)", twice, once)
+	}
+}
+
+func TestToUTF8_NilEncoding(t *testing.T) {
+	in := []byte("caf\xe9")
+	if got := ToUTF8(in, nil); !bytes.Equal(got, in) {
+		t.Errorf("ToUTF8 without an encoding = %q, want pass-through", got)
+	}
+}
+
+func TestToUTF8_UndefinedBytes(t *testing.T) {
+	// 0xAE is not assigned in ISO-8859-7 (Greek)
+	greek, err := LookupEncoding("iso-8859-7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(ToUTF8([]byte("a\xaeb"), greek))
+	if got != "a�b" {
+		t.Errorf("ToUTF8 = %q, want the undefined byte replaced", got)
+	}
+}
+
+func TestWithToolResultsUTF8(t *testing.T) {
+	latin1, _ := LookupEncoding("latin1")
+	line := []byte(strings.ReplaceAll(`{"uuid":"r1","type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"rÉsumÉ"}]},"toolUseResult":{"stdout":"rÉsumÉ","stderr":""}}`, "É", "\xe9"))
+
+	var entry ConversationEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		t.Fatal(err)
+	}
+	entry.RawLine = line
+
+	// Without an encoding the bytes become replacement characters
+	if got := entry.ExtractToolResults()[0].Content; got != "r�sum�" {
+		t.Fatalf("default content = %q, want replacement characters", got)
+	}
+
+	converted, changed := entry.WithToolResultsUTF8(latin1)
+	if !changed {
+		t.Fatal("WithToolResultsUTF8() should report a change")
+	}
+	if got := converted.ExtractToolResults()[0].Content; got != "résumé" {
+		t.Errorf("content = %q, want résumé", got)
+	}
+	if converted.ToolUseResult == nil || converted.ToolUseResult.Stdout != "résumé" {
+		t.Errorf("toolUseResult = %+v, want stdout résumé", converted.ToolUseResult)
+	}
+	if strings.Contains(entry.ExtractToolResults()[0].Content, "é") {
+		t.Error("the original entry should be left unchanged")
+	}
+}
+
+func TestWithToolResultsUTF8_Unchanged(t *testing.T) {
+	latin1, _ := LookupEncoding("latin1")
+	tests := []struct {
+		name  string
+		entry ConversationEntry
+	}{
+		{"valid UTF-8", ConversationEntry{Type: EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"café"}]}`)}},
+		{"not a tool result", ConversationEntry{Type: EntryTypeUser, Message: json.RawMessage("\"caf\xe9\"")}},
+		{"assistant", ConversationEntry{Type: EntryTypeAssistant, Message: json.RawMessage("{\"role\":\"assistant\",\"content\":\"caf\xe9\"}")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := tt.entry.WithToolResultsUTF8(latin1)
+			if changed || !bytes.Equal(got.Message, tt.entry.Message) {
+				t.Errorf("WithToolResultsUTF8() changed = %v, message %q", changed, got.Message)
+			}
+		})
+	}
+
+	entry := tests[1].entry
+	if _, changed := entry.WithToolResultsUTF8(nil); changed {
+		t.Error("WithToolResultsUTF8(nil) should pass through")
+	}
+}
@@ -64,7 +64,7 @@ func SessionFile(claudeDir string, projectPath string, sessionID string) (string
 		return "", err
 	}
 
-	return filepath.Join(projectDir, sessionID+".jsonl"), nil
+	return JSONLFile(filepath.Join(projectDir, sessionID)), nil
 }
 
 // AgentFile returns the path to an agent's JSONL file within a session.
@@ -75,7 +75,42 @@ func AgentFile(claudeDir string, projectPath string, sessionID string, agentID s
 	}
 
 	// Agent files are in: {projectDir}/{sessionId}/subagents/agent-{agentId}.jsonl
-	return filepath.Join(projectDir, sessionID, "subagents", "agent-"+agentID+".jsonl"), nil
+	return JSONLFile(filepath.Join(projectDir, sessionID, "subagents", "agent-"+agentID)), nil
+}
+
+// JSONL file extensions: sessions and agents are stored plain, or compressed with zstd.
+const (
+	JSONLExt     = ".jsonl"
+	ZstdJSONLExt = ".jsonl.zst"
+)
+
+// JSONLFile returns the path of the JSONL file named by base (a path without extension):
+// base+".jsonl", or base+".jsonl.zst" when only the compressed file exists.
+func JSONLFile(base string) string {
+	if plain := base + JSONLExt; Exists(plain) || !Exists(base+ZstdJSONLExt) {
+		return plain
+	}
+	return base + ZstdJSONLExt
+}
+
+// TrimJSONLExt removes the ".jsonl" or ".jsonl.zst" extension from a file name, reporting
+// whether it had one.
+func TrimJSONLExt(name string) (string, bool) {
+	for _, ext := range []string{JSONLExt, ZstdJSONLExt} {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext), true
+		}
+	}
+	return name, false
+}
+
+// JSONLExtOf returns the JSONL extension of a file name: ".jsonl.zst" for a compressed
+// file, ".jsonl" otherwise. Copies named with it stay readable (see jsonl.Open).
+func JSONLExtOf(name string) string {
+	if strings.HasSuffix(name, ZstdJSONLExt) {
+		return ZstdJSONLExt
+	}
+	return JSONLExt
 }
 
 // SubagentsDir returns the path to the subagents directory for a session.
@@ -145,11 +180,13 @@ func ListSessionFiles(projectDir string) (map[string]string, error) {
 			continue
 		}
 		name := entry.Name()
-		if strings.HasSuffix(name, ".jsonl") {
-			sessionID := strings.TrimSuffix(name, ".jsonl")
+		if sessionID, ok := TrimJSONLExt(name); ok {
 			// Session IDs are UUIDs, not encoded paths
 			if !encoding.IsEncodedPath(sessionID) && looksLikeUUID(sessionID) {
-				result[sessionID] = filepath.Join(projectDir, name)
+				// The plain file wins when a session exists both plain and compressed
+				if _, seen := result[sessionID]; !seen || strings.HasSuffix(name, JSONLExt) {
+					result[sessionID] = filepath.Join(projectDir, name)
+				}
 			}
 		}
 	}
@@ -201,9 +238,11 @@ func listAgentFilesRecursive(dir string, result map[string]string) error {
 		}
 
 		// Process agent JSONL files
-		if strings.HasPrefix(name, "agent-") && strings.HasSuffix(name, ".jsonl") {
-			agentID := strings.TrimPrefix(strings.TrimSuffix(name, ".jsonl"), "agent-")
-			result[agentID] = fullPath
+		if base, ok := TrimJSONLExt(name); ok && strings.HasPrefix(base, "agent-") {
+			agentID := strings.TrimPrefix(base, "agent-")
+			if _, seen := result[agentID]; !seen || strings.HasSuffix(name, JSONLExt) {
+				result[agentID] = fullPath
+			}
 		}
 	}
 
@@ -217,6 +217,80 @@ func TestListAgentFiles(t *testing.T) {
 	}
 }
 
+func TestListAgentFiles_Zstd(t *testing.T) {
+	tmpDir := t.TempDir()
+	subagentsDir := filepath.Join(tmpDir, "subagents")
+	mustMkdirAll(t, subagentsDir)
+
+	mustWriteFile(t, filepath.Join(subagentsDir, "agent-a12eb64.jsonl.zst"), []byte("{}"))
+	mustWriteFile(t, filepath.Join(subagentsDir, "agent-b34cd56.jsonl"), []byte("{}"))
+	mustWriteFile(t, filepath.Join(subagentsDir, "agent-b34cd56.jsonl.zst"), []byte("{}"))
+
+	agents, err := ListAgentFiles(tmpDir)
+	if err != nil {
+		t.Fatalf("ListAgentFiles() error: %v", err)
+	}
+	if got := agents["a12eb64"]; got != filepath.Join(subagentsDir, "agent-a12eb64.jsonl.zst") {
+		t.Errorf("ListAgentFiles()[a12eb64] = %q, want the compressed file", got)
+	}
+	// The plain file wins over a compressed copy
+	if got := agents["b34cd56"]; got != filepath.Join(subagentsDir, "agent-b34cd56.jsonl") {
+		t.Errorf("ListAgentFiles()[b34cd56] = %q, want the plain file", got)
+	}
+	if len(agents) != 2 {
+		t.Errorf("ListAgentFiles() returned %d agents, want 2", len(agents))
+	}
+}
+
+func TestJSONLFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	mustWriteFile(t, filepath.Join(tmpDir, "compressed.jsonl.zst"), []byte("{}"))
+	mustWriteFile(t, filepath.Join(tmpDir, "both.jsonl"), []byte("{}"))
+	mustWriteFile(t, filepath.Join(tmpDir, "both.jsonl.zst"), []byte("{}"))
+
+	tests := map[string]string{
+		"compressed": "compressed.jsonl.zst",
+		"both":       "both.jsonl",
+		"missing":    "missing.jsonl",
+	}
+	for base, want := range tests {
+		if got := JSONLFile(filepath.Join(tmpDir, base)); got != filepath.Join(tmpDir, want) {
+			t.Errorf("JSONLFile(%q) = %q, want %q", base, got, want)
+		}
+	}
+}
+
+func TestTrimJSONLExt(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"agent-a12eb64.jsonl", "agent-a12eb64", true},
+		{"agent-a12eb64.jsonl.zst", "agent-a12eb64", true},
+		{"sessions-index.json", "sessions-index.json", false},
+		{"notes.zst", "notes.zst", false},
+	}
+	for _, tt := range tests {
+		got, ok := TrimJSONLExt(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("TrimJSONLExt(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestJSONLExtOf(t *testing.T) {
+	for name, want := range map[string]string{
+		"/p/session.jsonl":     ".jsonl",
+		"/p/session.jsonl.zst": ".jsonl.zst",
+		"agent-a1":             ".jsonl",
+	} {
+		if got := JSONLExtOf(name); got != want {
+			t.Errorf("JSONLExtOf(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
 func TestLooksLikeUUID(t *testing.T) {
 	tests := []struct {
 		input    string
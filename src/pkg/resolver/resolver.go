@@ -2,6 +2,7 @@
 package resolver
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -14,6 +15,23 @@ import (
 	"github.com/randlee/claude-history/pkg/session"
 )
 
+// ErrSessionNotFound and ErrAgentNotFound are matched (with errors.Is) by the errors
+// returned when no session or agent has the requested ID or prefix.
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrAgentNotFound   = errors.New("agent not found")
+)
+
+// notFoundError reports a failed lookup in its own words while unwrapping to the
+// ErrSessionNotFound or ErrAgentNotFound sentinel.
+type notFoundError struct {
+	sentinel error
+	msg      string
+}
+
+func (e *notFoundError) Error() string { return e.msg }
+func (e *notFoundError) Unwrap() error { return e.sentinel }
+
 // SessionMatch represents a session that matches a prefix.
 type SessionMatch struct {
 	ID          string    // Full session ID
@@ -45,7 +63,7 @@ func ResolveSessionID(projectDir, prefix string) (string, error) {
 	}
 
 	if len(matches) == 0 {
-		return "", fmt.Errorf("no sessions found with prefix '%s'", prefix)
+		return "", &notFoundError{ErrSessionNotFound, fmt.Sprintf("no sessions found with prefix '%s'", prefix)}
 	}
 
 	if len(matches) == 1 {
@@ -71,7 +89,7 @@ func ResolveAgentID(projectDir, sessionID, prefix string) (string, error) {
 	}
 
 	if len(matches) == 0 {
-		return "", fmt.Errorf("no agents found with prefix '%s' in session %s", prefix, sessionID)
+		return "", &notFoundError{ErrAgentNotFound, fmt.Sprintf("no agents found with prefix '%s' in session %s", prefix, sessionID)}
 	}
 
 	if len(matches) == 1 {
@@ -148,7 +166,7 @@ func findMatchingAgentIDs(projectDir, sessionID, prefix string) ([]AgentMatch, e
 	}
 
 	// Get agent spawn descriptions from session file
-	sessionFile := filepath.Join(projectDir, sessionID+".jsonl")
+	sessionFile := paths.JSONLFile(filepath.Join(projectDir, sessionID))
 	spawnDescs := extractAgentSpawnDescriptions(sessionFile)
 
 	var matches []AgentMatch
@@ -1,6 +1,7 @@
 package resolver
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -22,6 +23,9 @@ func TestResolveSessionID_NonExistentPrefix(t *testing.T) {
 
 	_, err := ResolveSessionID(projectDir, "zzz")
 	assertError(t, err, "no sessions found")
+	if !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("error %v should match ErrSessionNotFound", err)
+	}
 }
 
 // TestResolveSessionID_FullIDPassthrough tests that a full UUID is returned as-is.
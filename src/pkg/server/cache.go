@@ -0,0 +1,61 @@
+package server
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/export"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// loadedFile is a parsed JSONL file together with its tool index.
+type loadedFile struct {
+	size    int64
+	modTime time.Time
+	entries []models.ConversationEntry
+	index   *export.ToolIndex
+}
+
+// fileCache keeps the entries and tool index of recently served files so repeated
+// requests for the same session skip re-reading and re-parsing it. An entry is reused
+// only while the file's size and modification time are unchanged.
+type fileCache struct {
+	mu    sync.Mutex
+	files map[string]*loadedFile
+}
+
+// load returns the entries and tool index of path, reading it with read when the
+// cached copy is missing or stale.
+func (c *fileCache) load(path string, read func(string) ([]models.ConversationEntry, error)) ([]models.ConversationEntry, *export.ToolIndex, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.mu.Lock()
+	cached, ok := c.files[path]
+	c.mu.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.entries, cached.index, nil
+	}
+
+	entries, err := read(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	loaded := &loadedFile{
+		size:    info.Size(),
+		modTime: info.ModTime(),
+		entries: entries,
+		index:   export.NewToolIndex(entries),
+	}
+
+	c.mu.Lock()
+	if c.files == nil {
+		c.files = make(map[string]*loadedFile)
+	}
+	c.files[path] = loaded
+	c.mu.Unlock()
+	return entries, loaded.index, nil
+}
@@ -0,0 +1,233 @@
+// Package server provides an HTTP handler for browsing Claude Code sessions on demand.
+package server
+
+import (
+	"fmt"
+	"html"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/agent"
+	"github.com/randlee/claude-history/pkg/export"
+	"github.com/randlee/claude-history/pkg/models"
+	"github.com/randlee/claude-history/pkg/paths"
+	"github.com/randlee/claude-history/pkg/resolver"
+	"github.com/randlee/claude-history/pkg/session"
+)
+
+// DefaultAddr is the default listen address. It binds to localhost only so
+// session contents are not exposed to the network unless explicitly requested.
+const DefaultAddr = "127.0.0.1:8080"
+
+// Handler serves a project's sessions as HTML rendered on each request.
+//
+// Routes:
+//
+//	/                                     session list
+//	/session/<id>/                        rendered conversation
+//	/session/<id>/agent/<agentID>         subagent fragment
+//	/session/<id>/agents/<agentID>.html   subagent fragment (path used by loadAgent in script.js)
+//	/static/<asset>, /session/<id>/static/<asset>   embedded CSS/JS
+type Handler struct {
+	projectDir  string
+	projectPath string
+	opts        export.ExportOptions
+	cache       fileCache
+}
+
+// NewHandler creates a handler for the project stored in projectDir.
+// projectPath is the original filesystem path, used for file links and display.
+func NewHandler(projectDir, projectPath string, opts export.ExportOptions) *Handler {
+	return &Handler{
+		projectDir:  projectDir,
+		projectPath: projectPath,
+		opts:        opts,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	urlPath := path.Clean("/" + r.URL.Path)
+	parts := strings.Split(strings.Trim(urlPath, "/"), "/")
+
+	switch {
+	case urlPath == "/":
+		h.serveIndex(w)
+	case len(parts) == 2 && parts[0] == "static":
+		serveStatic(w, parts[1])
+	case len(parts) >= 2 && parts[0] == "session":
+		h.serveSession(w, r, parts[1], parts[2:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveSession dispatches routes under /session/<id>/.
+func (h *Handler) serveSession(w http.ResponseWriter, r *http.Request, idPrefix string, rest []string) {
+	sessionID, err := resolver.ResolveSessionID(h.projectDir, idPrefix)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case len(rest) == 0:
+		// Relative asset and fragment URLs require a trailing slash
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			http.Redirect(w, r, "/session/"+sessionID+"/", http.StatusMovedPermanently)
+			return
+		}
+		h.serveConversation(w, sessionID)
+	case len(rest) == 2 && rest[0] == "static":
+		serveStatic(w, rest[1])
+	case len(rest) == 2 && rest[0] == "agent":
+		h.serveAgent(w, sessionID, rest[1])
+	case len(rest) == 2 && rest[0] == "agents" && strings.HasSuffix(rest[1], ".html"):
+		h.serveAgent(w, sessionID, strings.TrimSuffix(rest[1], ".html"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveIndex renders the list of sessions in the project.
+func (h *Handler) serveIndex(w http.ResponseWriter) {
+	sessions, err := session.ListSessions(h.projectDir)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list sessions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Claude Sessions</title>
+    <link rel="stylesheet" href="static/style.css">
+</head>
+<body>
+`)
+	sb.WriteString(fmt.Sprintf("<header class=\"page-header\"><h1>Sessions</h1><p class=\"session-project\">%s</p></header>\n", html.EscapeString(h.projectPath)))
+	sb.WriteString(`<table class="session-list">` + "\n")
+	sb.WriteString("<thead><tr><th>Session</th><th>Modified</th><th>Messages</th><th>First prompt</th></tr></thead>\n<tbody>\n")
+	for _, s := range sessions {
+		modified := ""
+		if !s.Modified.IsZero() {
+			modified = s.Modified.Format("2006-01-02 15:04")
+		}
+		sb.WriteString(fmt.Sprintf(`<tr><td><a href="session/%s/">%s</a></td><td>%s</td><td>%d</td><td>%s</td></tr>`+"\n",
+			html.EscapeString(s.ID),
+			html.EscapeString(s.ID),
+			html.EscapeString(modified),
+			s.MessageCount,
+			html.EscapeString(s.FirstPrompt)))
+	}
+	sb.WriteString("</tbody>\n</table>\n</body>\n</html>\n")
+
+	writeHTML(w, sb.String())
+}
+
+// serveConversation renders a full session page.
+func (h *Handler) serveConversation(w http.ResponseWriter, sessionID string) {
+	sessionFile := paths.JSONLFile(filepath.Join(h.projectDir, sessionID))
+	entries, index, err := h.cache.load(sessionFile, session.ReadSession)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read session: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	var agentNodes []*agent.TreeNode
+	if tree, err := agent.BuildNestedTree(h.projectDir, sessionID); err == nil && tree != nil {
+		agentNodes = tree.Children
+	}
+
+	stats := export.ComputeSessionStats(entries, agentNodes)
+	stats.ProjectPath = h.projectPath
+	stats.SessionFolderPath = filepath.Join(h.projectDir, sessionID)
+
+	opts := h.opts
+	opts.ToolIndex = index
+	content, err := export.RenderConversationWithOptions(entries, agentNodes, stats, opts)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to render session: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeHTML(w, content)
+}
+
+// serveAgent renders a subagent fragment for lazy loading.
+func (h *Handler) serveAgent(w http.ResponseWriter, sessionID, agentIDPrefix string) {
+	agentFiles, err := paths.ListAgentFiles(filepath.Join(h.projectDir, sessionID))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list agents: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	agentID := agentIDPrefix
+	if _, ok := agentFiles[agentID]; !ok {
+		resolved, err := resolver.ResolveAgentID(h.projectDir, sessionID, agentIDPrefix)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		agentID = resolved
+	}
+
+	agentFile, ok := agentFiles[agentID]
+	if !ok {
+		http.Error(w, fmt.Sprintf("agent not found: %s", agentIDPrefix), http.StatusNotFound)
+		return
+	}
+
+	entries, index, err := h.cache.load(agentFile, readAgentFile)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read agent: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	opts := h.opts
+	opts.ToolIndex = index
+	content, err := export.RenderAgentFragmentWithOptions(agentID, entries, opts)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to render agent: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeHTML(w, content)
+}
+
+// readAgentFile reads an agent file for lazy loading, skipping lines that cannot be
+// parsed so a damaged file still renders (see agent.ReadAgentFile).
+func readAgentFile(filePath string) ([]models.ConversationEntry, error) {
+	entries, _, err := agent.ReadAgentFile(filePath)
+	return entries, err
+}
+
+// serveStatic serves an embedded CSS or JavaScript asset.
+func serveStatic(w http.ResponseWriter, name string) {
+	content, ok := export.StaticAsset(name)
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "text/plain; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write([]byte(content))
+}
+
+// writeHTML writes an HTML response body.
+func writeHTML(w http.ResponseWriter, content string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(content))
+}
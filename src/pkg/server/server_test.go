@@ -0,0 +1,188 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/export"
+)
+
+const (
+	testSessionID = "33333333-3333-3333-3333-333333333333"
+	testAgentID   = "a1b2c3d4e5f6a7b8c"
+)
+
+// setupProject creates a project directory with one session and one subagent.
+func setupProject(t *testing.T) string {
+	t.Helper()
+
+	projectDir := t.TempDir()
+	sessionContent := `{"uuid":"e1","type":"user","timestamp":"2026-02-01T10:00:00Z","sessionId":"` + testSessionID + `","message":"Build the thing"}
+{"uuid":"e2","type":"assistant","timestamp":"2026-02-01T10:00:01Z","sessionId":"` + testSessionID + `","message":[{"type":"text","text":"On it."}]}
+{"uuid":"e3","type":"queue-operation","timestamp":"2026-02-01T10:00:02Z","sessionId":"` + testSessionID + `","agentId":"` + testAgentID + `"}
+`
+	if err := os.WriteFile(filepath.Join(projectDir, testSessionID+".jsonl"), []byte(sessionContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	subagentsDir := filepath.Join(projectDir, testSessionID, "subagents")
+	if err := os.MkdirAll(subagentsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	agentContent := `{"uuid":"a1","type":"assistant","timestamp":"2026-02-01T10:00:03Z","agentId":"` + testAgentID + `","message":[{"type":"text","text":"Agent work done"}]}
+`
+	if err := os.WriteFile(filepath.Join(subagentsDir, "agent-"+testAgentID+".jsonl"), []byte(agentContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return projectDir
+}
+
+func get(t *testing.T, h http.Handler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestHandler_Index(t *testing.T) {
+	h := NewHandler(setupProject(t), "/work/project", export.ExportOptions{})
+
+	rec := get(t, h, "/")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, `href="session/`+testSessionID+`/"`) {
+		t.Errorf("index should link to the session, got:\n%s", body)
+	}
+	if !strings.Contains(body, "Build the thing") {
+		t.Error("index should show the first prompt")
+	}
+}
+
+func TestHandler_SessionRedirectsToTrailingSlash(t *testing.T) {
+	h := NewHandler(setupProject(t), "/work/project", export.ExportOptions{})
+
+	rec := get(t, h, "/session/33333333")
+	if rec.Code != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want 301", rec.Code)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/session/"+testSessionID+"/" {
+		t.Errorf("Location = %q, want resolved session with trailing slash", loc)
+	}
+}
+
+func TestHandler_Session(t *testing.T) {
+	h := NewHandler(setupProject(t), "/work/project", export.ExportOptions{})
+
+	rec := get(t, h, "/session/"+testSessionID+"/")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "On it.") {
+		t.Error("session page should render the conversation")
+	}
+	if !strings.Contains(body, `data-agent-id="`+testAgentID+`"`) {
+		t.Error("session page should include the subagent placeholder")
+	}
+}
+
+func TestHandler_AgentFragment(t *testing.T) {
+	h := NewHandler(setupProject(t), "/work/project", export.ExportOptions{})
+
+	for _, target := range []string{
+		"/session/" + testSessionID + "/agent/" + testAgentID,
+		// URL requested by loadAgent() relative to the session page
+		"/session/" + testSessionID + "/agents/" + testAgentID + ".html",
+		// Prefixes resolve like the CLI
+		"/session/" + testSessionID + "/agent/a1b2c3",
+	} {
+		rec := get(t, h, target)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", target, rec.Code)
+			continue
+		}
+		if !strings.Contains(rec.Body.String(), "Agent work done") {
+			t.Errorf("%s: fragment should contain agent content", target)
+		}
+		if strings.Contains(rec.Body.String(), "<!DOCTYPE") {
+			t.Errorf("%s: fragment should not be a full page", target)
+		}
+	}
+}
+
+func TestHandler_StaticAssets(t *testing.T) {
+	h := NewHandler(setupProject(t), "/work/project", export.ExportOptions{})
+
+	for _, target := range []string{"/static/style.css", "/session/" + testSessionID + "/static/script.js"} {
+		rec := get(t, h, target)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", target, rec.Code)
+		}
+		if rec.Body.Len() == 0 {
+			t.Errorf("%s: empty body", target)
+		}
+	}
+}
+
+func TestHandler_NotFound(t *testing.T) {
+	h := NewHandler(setupProject(t), "/work/project", export.ExportOptions{})
+
+	for _, target := range []string{
+		"/nope",
+		"/static/missing.css",
+		"/session/ffffffff/",
+		"/session/" + testSessionID + "/agent/zzzz",
+		"/session/" + testSessionID + "/../../etc/passwd",
+	} {
+		if rec := get(t, h, target); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", target, rec.Code)
+		}
+	}
+}
+
+func TestHandler_MethodNotAllowed(t *testing.T) {
+	h := NewHandler(setupProject(t), "/work/project", export.ExportOptions{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", rec.Code)
+	}
+}
+
+func TestDefaultAddr_Localhost(t *testing.T) {
+	if !strings.HasPrefix(DefaultAddr, "127.0.0.1:") {
+		t.Errorf("DefaultAddr = %q, should bind to localhost", DefaultAddr)
+	}
+}
+
+func TestHandler_SessionReloadsChangedFile(t *testing.T) {
+	projectDir := setupProject(t)
+	h := NewHandler(projectDir, "/work/project", export.ExportOptions{})
+
+	if body := get(t, h, "/session/"+testSessionID+"/").Body.String(); !strings.Contains(body, "On it.") {
+		t.Fatal("first request should render the conversation")
+	}
+
+	sessionFile := filepath.Join(projectDir, testSessionID+".jsonl")
+	f, err := os.OpenFile(sessionFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.WriteString(`{"uuid":"e4","type":"assistant","timestamp":"2026-02-01T10:00:04Z","sessionId":"` + testSessionID + `","message":[{"type":"text","text":"All done."}]}` + "\n")
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if body := get(t, h, "/session/"+testSessionID+"/").Body.String(); !strings.Contains(body, "All done.") {
+		t.Error("a changed session file should be read again")
+	}
+}
@@ -0,0 +1,23 @@
+package session
+
+import (
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// EntriesAfter returns the entries that come after the entry with the given UUID, for
+// clients polling a live session that pass the last UUID they have seen. An empty UUID,
+// or one not in entries, returns all entries, as on a client's first load. entries is
+// searched from the end, where a polling client's last-seen entry usually is, and the
+// search stops at the first match, so a UUID that appears more than once counts from
+// its last occurrence. The result shares entries' backing array.
+func EntriesAfter(entries []models.ConversationEntry, uuid string) []models.ConversationEntry {
+	if uuid == "" {
+		return entries
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].UUID == uuid {
+			return entries[i+1:]
+		}
+	}
+	return entries
+}
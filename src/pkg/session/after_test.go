@@ -0,0 +1,40 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestEntriesAfter(t *testing.T) {
+	entries := []models.ConversationEntry{{UUID: "a"}, {UUID: "b"}, {UUID: "c"}, {UUID: "b"}, {UUID: "d"}}
+
+	tests := []struct {
+		name string
+		uuid string
+		want []string
+	}{
+		{"empty UUID returns all", "", []string{"a", "b", "c", "b", "d"}},
+		{"unknown UUID returns all", "zzz", []string{"a", "b", "c", "b", "d"}},
+		{"after the first entry", "a", []string{"b", "c", "b", "d"}},
+		{"last entry leaves nothing", "d", []string{}},
+		{"repeated UUID counts from its last occurrence", "b", []string{"d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EntriesAfter(entries, tt.uuid)
+			if len(got) != len(tt.want) {
+				t.Fatalf("EntriesAfter(%q) returned %d entries, want %d", tt.uuid, len(got), len(tt.want))
+			}
+			for i, uuid := range tt.want {
+				if got[i].UUID != uuid {
+					t.Errorf("EntriesAfter(%q)[%d] = %q, want %q", tt.uuid, i, got[i].UUID, uuid)
+				}
+			}
+		})
+	}
+
+	if got := EntriesAfter(nil, "a"); len(got) != 0 {
+		t.Errorf("EntriesAfter(nil) = %v, want none", got)
+	}
+}
@@ -0,0 +1,57 @@
+package session
+
+import (
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// bashToolName is the name of Claude Code's shell tool.
+const bashToolName = "Bash"
+
+// BashCommand is one command a session ran with the Bash tool.
+type BashCommand struct {
+	EntryUUID   string // UUID of the assistant entry that made the call
+	Timestamp   string // Timestamp of that entry
+	ToolUseID   string // ID of the tool call
+	Command     string // The command line, as given to the tool
+	Description string // The call's description input, "" if none
+	HasResult   bool   // Whether the session recorded a result for the call
+	Output      string // Recorded output ("" without a result)
+	IsError     bool   // Whether the recorded result is an error
+}
+
+// ExtractBashCommands returns the commands of the Bash tool calls in entries, in order,
+// each with its recorded result if the entries include one. Other tools, including
+// other shells, are left out, as are calls without a command.
+func ExtractBashCommands(entries []models.ConversationEntry) []BashCommand {
+	results := buildToolResults(entries)
+
+	var commands []BashCommand
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Type != models.EntryTypeAssistant {
+			continue
+		}
+		for _, tool := range entry.ExtractToolCalls() {
+			if tool.Name != bashToolName {
+				continue
+			}
+			command, _ := tool.Input["command"].(string)
+			if command == "" {
+				continue
+			}
+			description, _ := tool.Input["description"].(string)
+			result, ok := results[tool.ID]
+			commands = append(commands, BashCommand{
+				EntryUUID:   entry.UUID,
+				Timestamp:   entry.Timestamp,
+				ToolUseID:   tool.ID,
+				Command:     command,
+				Description: description,
+				HasResult:   ok,
+				Output:      result.Content,
+				IsError:     result.IsError,
+			})
+		}
+	}
+	return commands
+}
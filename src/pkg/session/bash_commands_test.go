@@ -0,0 +1,44 @@
+package session
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestExtractBashCommands(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Message: json.RawMessage(`"run the tests"`)},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:00Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./...","description":"Run tests"}},{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"/a.go"}}]}`)},
+		{UUID: "r1", Type: models.EntryTypeUser,
+			Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"FAIL","is_error":true},{"type":"tool_result","tool_use_id":"t2","content":"package a"}]}`)},
+		{UUID: "a2", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:05Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t3","name":"Bash","input":{"command":"ls"}},{"type":"tool_use","id":"t4","name":"Bash","input":{}},{"type":"tool_use","id":"t5","name":"PowerShell","input":{"command":"dir"}}]}`)},
+	}
+
+	commands := ExtractBashCommands(entries)
+	want := []BashCommand{
+		{EntryUUID: "a1", Timestamp: "2026-02-01T10:00:00Z", ToolUseID: "t1", Command: "go test ./...", Description: "Run tests", HasResult: true, Output: "FAIL", IsError: true},
+		{EntryUUID: "a2", Timestamp: "2026-02-01T10:00:05Z", ToolUseID: "t3", Command: "ls"},
+	}
+	if len(commands) != len(want) {
+		t.Fatalf("ExtractBashCommands() = %+v, want %+v", commands, want)
+	}
+	for i := range want {
+		if commands[i] != want[i] {
+			t.Errorf("command %d = %+v, want %+v", i, commands[i], want[i])
+		}
+	}
+}
+
+func TestExtractBashCommands_None(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Message: json.RawMessage(`"hello"`)},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Bash"}]}`)},
+	}
+	if commands := ExtractBashCommands(entries); len(commands) != 0 {
+		t.Errorf("ExtractBashCommands() = %+v, want none", commands)
+	}
+}
@@ -0,0 +1,20 @@
+package session
+
+import "github.com/randlee/claude-history/pkg/models"
+
+// CompactionCount returns how many times the context was compacted in entries. Each
+// compaction boundary counts once (see models.ConversationEntry.IsCompactionBoundary),
+// and so does a compaction summary that no boundary precedes, as older Claude Code
+// versions record only the summary.
+func CompactionCount(entries []models.ConversationEntry) int {
+	count := 0
+	afterBoundary := false
+	for i := range entries {
+		entry := &entries[i]
+		if entry.IsCompactionBoundary() || (entry.IsCompactionSummary() && !afterBoundary) {
+			count++
+		}
+		afterBoundary = entry.IsCompactionBoundary()
+	}
+	return count
+}
@@ -0,0 +1,31 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestCompactionCount(t *testing.T) {
+	boundary := models.ConversationEntry{Type: models.EntryTypeSystem, Subtype: "compact_boundary"}
+	summary := models.ConversationEntry{Type: models.EntryTypeUser, IsCompactSummary: true}
+	prompt := models.ConversationEntry{Type: models.EntryTypeUser}
+	title := models.ConversationEntry{Type: models.EntryTypeSummary}
+
+	tests := []struct {
+		name    string
+		entries []models.ConversationEntry
+		want    int
+	}{
+		{"none", []models.ConversationEntry{title, prompt}, 0},
+		{"boundary with summary", []models.ConversationEntry{prompt, boundary, summary, prompt}, 1},
+		{"twice", []models.ConversationEntry{prompt, boundary, summary, prompt, boundary, summary}, 2},
+		{"summary without boundary", []models.ConversationEntry{summary, prompt}, 1},
+		{"boundary without summary", []models.ConversationEntry{prompt, boundary, prompt}, 1},
+	}
+	for _, tt := range tests {
+		if got := CompactionCount(tt.entries); got != tt.want {
+			t.Errorf("%s: CompactionCount() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// deferredResultTools are tools that spawn work whose result may legitimately arrive
+// after the session file ends (subagents run asynchronously).
+var deferredResultTools = map[string]bool{
+	"Task":  true,
+	"Agent": true,
+}
+
+// SessionCompleteness reports whether a session looks complete. A session is flagged
+// as incomplete (crashed or still running) when its last user or assistant entry is an
+// assistant message with a tool call that never received a result. Tool calls with
+// deferred results are not counted: subagent spawns (Task/Agent) and background
+// commands (input run_in_background: true). reason explains an incomplete result and
+// is empty otherwise.
+func SessionCompleteness(entries []models.ConversationEntry) (complete bool, reason string) {
+	var last *models.ConversationEntry
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Type == models.EntryTypeUser || entries[i].Type == models.EntryTypeAssistant {
+			last = &entries[i]
+			break
+		}
+	}
+	if last == nil || last.Type != models.EntryTypeAssistant {
+		return true, ""
+	}
+
+	toolResults := buildToolResults(entries)
+	var pending []string
+	for _, tool := range last.ExtractToolCalls() {
+		if _, ok := toolResults[tool.ID]; ok || hasDeferredResult(tool) {
+			continue
+		}
+		pending = append(pending, tool.Name)
+	}
+	if len(pending) == 0 {
+		return true, ""
+	}
+
+	noun := "call"
+	if len(pending) > 1 {
+		noun = "calls"
+	}
+	return false, fmt.Sprintf("last assistant message has %d tool %s without a result (%s)",
+		len(pending), noun, strings.Join(pending, ", "))
+}
+
+// hasDeferredResult reports whether a tool call's result may arrive after the session ends.
+func hasDeferredResult(tool models.ToolUse) bool {
+	if deferredResultTools[tool.Name] {
+		return true
+	}
+	background, _ := tool.Input["run_in_background"].(bool)
+	return background
+}
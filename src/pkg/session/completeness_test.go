@@ -0,0 +1,62 @@
+package session
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func completenessEntry(entryType models.EntryType, message string) models.ConversationEntry {
+	return models.ConversationEntry{Type: entryType, Message: json.RawMessage(message)}
+}
+
+func TestSessionCompleteness(t *testing.T) {
+	prompt := completenessEntry(models.EntryTypeUser, `{"role":"user","content":"run the tests"}`)
+	bashCall := completenessEntry(models.EntryTypeAssistant, `{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test"}}]}`)
+	bashResult := completenessEntry(models.EntryTypeUser, `{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}`)
+	reply := completenessEntry(models.EntryTypeAssistant, `{"role":"assistant","content":"All tests pass."}`)
+
+	tests := []struct {
+		name       string
+		entries    []models.ConversationEntry
+		complete   bool
+		wantReason string
+	}{
+		{"empty", nil, true, ""},
+		{"finished normally", []models.ConversationEntry{prompt, bashCall, bashResult, reply}, true, ""},
+		{"ends with answered tool call", []models.ConversationEntry{prompt, bashCall, bashResult}, true, ""},
+		{"ends with user prompt", []models.ConversationEntry{prompt}, true, ""},
+		{"ends with unanswered tool call", []models.ConversationEntry{prompt, bashCall}, false, "1 tool call without a result (Bash)"},
+		{"trailing system entry ignored", []models.ConversationEntry{prompt, bashCall, {Type: models.EntryTypeSystem}}, false, "(Bash)"},
+		{"several unanswered calls", []models.ConversationEntry{prompt,
+			completenessEntry(models.EntryTypeAssistant, `{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Read","input":{}},{"type":"tool_use","id":"t2","name":"Grep","input":{}}]}`)},
+			false, "2 tool calls without a result (Read, Grep)"},
+		{"async agent spawn", []models.ConversationEntry{prompt,
+			completenessEntry(models.EntryTypeAssistant, `{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Task","input":{"prompt":"explore"}}]}`),
+			{Type: models.EntryTypeQueueOperation, AgentID: "a1"}},
+			true, ""},
+		{"background command", []models.ConversationEntry{prompt,
+			completenessEntry(models.EntryTypeAssistant, `{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"npm run dev","run_in_background":true}}]}`)},
+			true, ""},
+		{"spawn alongside unanswered call", []models.ConversationEntry{prompt,
+			completenessEntry(models.EntryTypeAssistant, `{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Task","input":{}},{"type":"tool_use","id":"t2","name":"Bash","input":{"command":"ls"}}]}`)},
+			false, "1 tool call without a result (Bash)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			complete, reason := SessionCompleteness(tt.entries)
+			if complete != tt.complete {
+				t.Errorf("SessionCompleteness() complete = %v, want %v (reason %q)", complete, tt.complete, reason)
+			}
+			if tt.complete && reason != "" {
+				t.Errorf("complete session should have no reason, got %q", reason)
+			}
+			if !strings.Contains(reason, tt.wantReason) {
+				t.Errorf("reason = %q, want it to contain %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
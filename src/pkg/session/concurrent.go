@@ -0,0 +1,64 @@
+package session
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MaxConcurrentReads bounds the session files ReadConcurrently reads at once, so listing a
+// project with hundreds of sessions does not run out of file descriptors.
+const MaxConcurrentReads = 8
+
+// SessionReadError records a session file that could not be read.
+type SessionReadError struct {
+	SessionID string
+	Path      string
+	Err       error
+}
+
+func (e *SessionReadError) Error() string {
+	return fmt.Sprintf("session %s: %v", e.SessionID, e.Err)
+}
+
+func (e *SessionReadError) Unwrap() error {
+	return e.Err
+}
+
+// ReadConcurrently calls read(i) for each i in [0, n) from at most MaxConcurrentReads
+// goroutines at a time, returning once all calls have. errs[i] is the error of read(i),
+// so one failure does not stop the others; errs is nil when every call succeeded. read
+// must be safe to call concurrently, typically by writing only to index i of its results.
+func ReadConcurrently(n int, read func(i int) error) (errs []error) {
+	if n <= 0 {
+		return nil
+	}
+	workers := MaxConcurrentReads
+	if n < workers {
+		workers = n
+	}
+
+	errs = make([]error, n)
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = read(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return errs
+		}
+	}
+	return nil
+}
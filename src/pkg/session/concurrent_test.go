@@ -0,0 +1,108 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadConcurrently(t *testing.T) {
+	const n = 50
+	var running, peak int32
+	seen := make([]int32, n)
+	errs := ReadConcurrently(n, func(i int) error {
+		cur := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if cur <= old || atomic.CompareAndSwapInt32(&peak, old, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&seen[i], 1)
+		if i%10 == 3 {
+			return fmt.Errorf("read %d failed", i)
+		}
+		return nil
+	})
+
+	if peak > MaxConcurrentReads {
+		t.Errorf("%d reads ran at once, want at most %d", peak, MaxConcurrentReads)
+	}
+	for i, count := range seen {
+		if count != 1 {
+			t.Errorf("read(%d) called %d times, want 1", i, count)
+		}
+	}
+	if len(errs) != n {
+		t.Fatalf("len(errs) = %d, want %d", len(errs), n)
+	}
+	for i, err := range errs {
+		if (err != nil) != (i%10 == 3) {
+			t.Errorf("errs[%d] = %v", i, err)
+		}
+	}
+
+	if errs := ReadConcurrently(3, func(int) error { return nil }); errs != nil {
+		t.Errorf("ReadConcurrently() = %v, want nil when every read succeeds", errs)
+	}
+	if errs := ReadConcurrently(0, func(int) error { t.Error("read called for n = 0"); return nil }); errs != nil {
+		t.Errorf("ReadConcurrently(0) = %v, want nil", errs)
+	}
+}
+
+func TestListSessionsWithErrors(t *testing.T) {
+	dir := t.TempDir()
+	// Many sessions sharing a modification time, to check the order is deterministic
+	var want []string
+	for i := 0; i < 30; i++ {
+		id := fmt.Sprintf("%08x-0000-0000-0000-000000000000", i)
+		want = append(want, id)
+		mustWriteFile(t, filepath.Join(dir, id+".jsonl"),
+			[]byte(`{"type":"user","timestamp":"2026-02-01T10:00:00Z","message":"hi"}`+"\n"))
+	}
+	// Newest first
+	newest := "ffffffff-0000-0000-0000-000000000000"
+	mustWriteFile(t, filepath.Join(dir, newest+".jsonl"),
+		[]byte(`{"type":"user","timestamp":"2026-02-02T10:00:00Z","message":"later"}`+"\n"))
+	want = append([]string{newest}, want...)
+	// No conversation: left out without an error
+	mustWriteFile(t, filepath.Join(dir, "eeeeeeee-0000-0000-0000-000000000000.jsonl"), []byte(`{"type":"summary"}`+"\n"))
+	// Unreadable: reported without stopping the listing
+	broken := "dddddddd-0000-0000-0000-000000000000"
+	if err := os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, broken+".jsonl")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	sessions, readErrs, err := ListSessionsWithErrors(dir)
+	if err != nil {
+		t.Fatalf("ListSessionsWithErrors() error = %v", err)
+	}
+	if len(sessions) != len(want) {
+		t.Fatalf("got %d sessions, want %d", len(sessions), len(want))
+	}
+	for i, s := range sessions {
+		if s.ID != want[i] {
+			t.Errorf("sessions[%d] = %s, want %s", i, s.ID, want[i])
+		}
+	}
+
+	if len(readErrs) != 1 {
+		t.Fatalf("readErrs = %v, want one error", readErrs)
+	}
+	var readErr *SessionReadError
+	if !errors.As(readErrs[0], &readErr) || readErr.SessionID != broken || !errors.Is(readErr, os.ErrNotExist) {
+		t.Errorf("readErrs[0] = %#v, want a SessionReadError for %s", readErrs[0], broken)
+	}
+
+	// ListSessions skips the unreadable file
+	listed, err := ListSessions(dir)
+	if err != nil || len(listed) != len(want) {
+		t.Errorf("ListSessions() = %d sessions, %v; want %d", len(listed), err, len(want))
+	}
+}
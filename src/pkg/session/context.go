@@ -0,0 +1,66 @@
+package session
+
+import "github.com/randlee/claude-history/pkg/models"
+
+// SearchHit is an entry that matched a query, with the messages around it (see
+// HitsWithContext).
+type SearchHit struct {
+	Entry  models.ConversationEntry   `json:"entry"`
+	Before []models.ConversationEntry `json:"before,omitempty"` // Messages before Entry, oldest first
+	After  []models.ConversationEntry `json:"after,omitempty"`  // Messages after Entry, oldest first
+
+	// Joined is set when this hit's context runs into the previous hit's, with no
+	// message left out between them, so the two read as one passage.
+	Joined bool `json:"joined,omitempty"`
+}
+
+// HitsWithContext pairs each of hits, which must be a subsequence of entries (as
+// FilterEntries returns), with up to n user or assistant messages before and after it
+// in entries, like grep -C. Windows of nearby hits are merged rather than repeated:
+// context stops at the next hit, and a message in one hit's After is left out of the
+// next hit's Before. A hit not found in entries is returned without context.
+func HitsWithContext(entries, hits []models.ConversationEntry, n int) []SearchHit {
+	if len(hits) == 0 {
+		return nil
+	}
+
+	// The messages and hits of entries, in order; context is counted in messages
+	var seq []models.ConversationEntry
+	var hitAt []int // Index in seq of each hit found, in order
+	for _, entry := range entries {
+		if len(hitAt) < len(hits) && sameEntry(entry, hits[len(hitAt)]) {
+			hitAt = append(hitAt, len(seq))
+			seq = append(seq, entry)
+			continue
+		}
+		if entry.Type == models.EntryTypeUser || entry.Type == models.EntryTypeAssistant {
+			seq = append(seq, entry)
+		}
+	}
+
+	result := make([]SearchHit, 0, len(hits))
+	used := 0 // seq[:used] is already in the context of an earlier hit
+	for k, at := range hitAt {
+		start := max(at-n, used)
+		end := min(at+1+n, len(seq))
+		if k+1 < len(hitAt) {
+			end = min(end, hitAt[k+1])
+		}
+		result = append(result, SearchHit{
+			Entry:  seq[at],
+			Before: seq[start:at],
+			After:  seq[at+1 : end],
+			Joined: k > 0 && start == used,
+		})
+		used = end
+	}
+	for _, hit := range hits[len(hitAt):] {
+		result = append(result, SearchHit{Entry: hit})
+	}
+	return result
+}
+
+// sameEntry reports whether a and b are the same entry of a session file.
+func sameEntry(a, b models.ConversationEntry) bool {
+	return a.UUID == b.UUID && a.SourceLine == b.SourceLine && a.Type == b.Type && a.Timestamp == b.Timestamp
+}
@@ -0,0 +1,63 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestHitsWithContext(t *testing.T) {
+	var entries []models.ConversationEntry
+	for i, id := range []string{"m1", "m2", "m3", "m4", "s1", "m5", "m6", "m7", "m8", "m9"} {
+		entryType := models.EntryTypeUser
+		if id[0] == 's' {
+			entryType = models.EntryTypeSystem
+		}
+		entries = append(entries, models.ConversationEntry{UUID: id, Type: entryType, SourceLine: i + 1})
+	}
+	uuids := func(entries []models.ConversationEntry) []string {
+		var ids []string
+		for _, e := range entries {
+			ids = append(ids, e.UUID)
+		}
+		return ids
+	}
+
+	// m3 and m5 are close enough to merge; m9 starts a new passage
+	hits := HitsWithContext(entries, []models.ConversationEntry{entries[2], entries[5], entries[9]}, 2)
+	if len(hits) != 3 {
+		t.Fatalf("HitsWithContext() = %d hits, want 3", len(hits))
+	}
+	tests := []struct {
+		hit, before, after []string
+		joined             bool
+	}{
+		{[]string{"m3"}, []string{"m1", "m2"}, []string{"m4"}, false},
+		// The system entry is not a message, and m4 is already in m3's context
+		{[]string{"m5"}, nil, []string{"m6", "m7"}, true},
+		{[]string{"m9"}, []string{"m8"}, nil, true},
+	}
+	for i, tt := range tests {
+		hit := hits[i]
+		if hit.Entry.UUID != tt.hit[0] || !reflect.DeepEqual(uuids(hit.Before), tt.before) ||
+			!reflect.DeepEqual(uuids(hit.After), tt.after) || hit.Joined != tt.joined {
+			t.Errorf("hit %d = %s before %v after %v joined %v, want %s before %v after %v joined %v", i,
+				hit.Entry.UUID, uuids(hit.Before), uuids(hit.After), hit.Joined, tt.hit[0], tt.before, tt.after, tt.joined)
+		}
+	}
+
+	// With less context, a gap separates the passages
+	hits = HitsWithContext(entries, []models.ConversationEntry{entries[0], entries[9]}, 1)
+	if hits[1].Joined || !reflect.DeepEqual(uuids(hits[0].After), []string{"m2"}) || !reflect.DeepEqual(uuids(hits[1].Before), []string{"m8"}) {
+		t.Errorf("distant hits = %+v, want separate passages", hits)
+	}
+
+	if got := HitsWithContext(entries, nil, 2); got != nil {
+		t.Errorf("no hits = %+v, want nil", got)
+	}
+	missing := models.ConversationEntry{UUID: "other"}
+	if got := HitsWithContext(entries, []models.ConversationEntry{missing}, 2); len(got) != 1 || got[0].Before != nil || got[0].After != nil {
+		t.Errorf("a hit missing from entries = %+v, want it without context", got)
+	}
+}
@@ -0,0 +1,48 @@
+//go:build go1.23
+
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/randlee/claude-history/internal/jsonl"
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// errStopEntries stops the underlying scan when the caller breaks out of Entries.
+var errStopEntries = errors.New("entries iteration stopped")
+
+// Entries returns an iterator over the entries of a session JSONL file, decoding each
+// line lazily:
+//
+//	for entry, err := range session.Entries(path) {
+//		if err != nil { ... }
+//	}
+//
+// It reads lines like ScanSession, but a line that fails to decode is yielded as an
+// error instead of being skipped; iteration continues if the caller does. Failing to
+// open the file or a line longer than the scanner's limit (bufio.ErrTooLong) is
+// yielded as a final error. Breaking out of the loop stops reading and closes the file.
+func Entries(path string) iter.Seq2[models.ConversationEntry, error] {
+	return func(yield func(models.ConversationEntry, error) bool) {
+		err := jsonl.NewScanner().Scan(path, func(line json.RawMessage) error {
+			var entry models.ConversationEntry
+			if err := json.Unmarshal(line, &entry); err != nil {
+				if !yield(models.ConversationEntry{}, fmt.Errorf("failed to parse entry: %w", err)) {
+					return errStopEntries
+				}
+				return nil
+			}
+			if !yield(entry, nil) {
+				return errStopEntries
+			}
+			return nil
+		})
+		if err != nil && err != errStopEntries {
+			yield(models.ConversationEntry{}, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+//go:build go1.23
+
+package session
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func writeEntriesFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEntries(t *testing.T) {
+	path := writeEntriesFile(t, `{"uuid":"1","type":"user","timestamp":"2026-02-01T10:00:00Z"}
+{"uuid":"2","type":"assistant","timestamp":"2026-02-01T10:00:05Z"}
+not json
+{"uuid":"3","type":"user","timestamp":"2026-02-01T10:00:10Z"}
+`)
+
+	var uuids []string
+	for entry, err := range Entries(path) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		uuids = append(uuids, entry.UUID)
+	}
+
+	if strings.Join(uuids, ",") != "1,2,3" {
+		t.Errorf("got entries %v, want [1 2 3]", uuids)
+	}
+}
+
+func TestEntries_ParseError(t *testing.T) {
+	path := writeEntriesFile(t, `{"uuid":"1","type":"user"}
+{"uuid":2,"type":"user"}
+{"uuid":"3","type":"user"}
+`)
+
+	var uuids []string
+	var errs []error
+	for entry, err := range Entries(path) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		uuids = append(uuids, entry.UUID)
+	}
+
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "failed to parse entry") {
+		t.Errorf("expected one parse error, got %v", errs)
+	}
+	if strings.Join(uuids, ",") != "1,3" {
+		t.Errorf("iteration should continue past a parse error, got %v", uuids)
+	}
+}
+
+func TestEntries_EarlyBreak(t *testing.T) {
+	path := writeEntriesFile(t, `{"uuid":"1","type":"user"}
+{"uuid":"2","type":"assistant"}
+{"uuid":"3","type":"user"}
+`)
+
+	count := 0
+	for entry, err := range Entries(path) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		count++
+		if entry.UUID == "2" {
+			break
+		}
+	}
+	if count != 2 {
+		t.Errorf("read %d entries before break, want 2", count)
+	}
+
+	// Breaking on an error is also clean
+	path = writeEntriesFile(t, "{\"uuid\":1}\n{\"uuid\":\"2\"}\n")
+	for _, err := range Entries(path) {
+		if err == nil {
+			t.Fatal("expected the parse error first")
+		}
+		break
+	}
+}
+
+func TestEntries_MissingFile(t *testing.T) {
+	var errs []error
+	for _, err := range Entries(filepath.Join(t.TempDir(), "missing.jsonl")) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], os.ErrNotExist) {
+		t.Errorf("expected a single not-exist error, got %v", errs)
+	}
+}
+
+func TestEntries_OversizedLineMatchesScanSession(t *testing.T) {
+	long := `{"uuid":"big","type":"user","message":"` + strings.Repeat("x", 11*1024*1024) + `"}`
+	path := writeEntriesFile(t, `{"uuid":"1","type":"user"}`+"\n"+long+"\n"+`{"uuid":"3","type":"user"}`+"\n")
+
+	var streamed []string
+	streamErr := ScanSession(path, func(entry models.ConversationEntry) error {
+		streamed = append(streamed, entry.UUID)
+		return nil
+	})
+
+	var iterated []string
+	var iterErr error
+	for entry, err := range Entries(path) {
+		if err != nil {
+			iterErr = err
+			continue
+		}
+		iterated = append(iterated, entry.UUID)
+	}
+
+	if !errors.Is(streamErr, bufio.ErrTooLong) || !errors.Is(iterErr, bufio.ErrTooLong) {
+		t.Errorf("both readers should report bufio.ErrTooLong, got ScanSession %v, Entries %v", streamErr, iterErr)
+	}
+	if strings.Join(iterated, ",") != strings.Join(streamed, ",") {
+		t.Errorf("Entries yielded %v, ScanSession yielded %v", iterated, streamed)
+	}
+}
@@ -0,0 +1,81 @@
+package session
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// FileActivity is how often a session read and changed one file (see FilesTouched).
+type FileActivity struct {
+	Path   string `json:"path"`
+	Reads  int    `json:"reads"`  // Read calls
+	Writes int    `json:"writes"` // Calls of the editing tools (see EditedFilePath)
+}
+
+// patchFileRe matches the file headers of an ApplyPatch patch.
+var patchFileRe = regexp.MustCompile(`(?m)^\*\*\* (?:Add|Update|Delete) File: (.+)$`)
+
+// ToolFilePath returns the file a tool call reads or changes: the file_path of Read,
+// Write, Edit, MultiEdit and ApplyPatch (or, for a patch-only ApplyPatch, the first
+// file its patch names) and the notebook_path of NotebookEdit. Other tools return "".
+func ToolFilePath(tool models.ToolUse) string {
+	if tool.Input == nil {
+		return ""
+	}
+
+	switch tool.Name {
+	case "Read", "Write", "Edit", "MultiEdit":
+		path, _ := tool.Input["file_path"].(string)
+		return path
+	case "NotebookEdit":
+		path, _ := tool.Input["notebook_path"].(string)
+		return path
+	case "ApplyPatch":
+		if path, ok := tool.Input["file_path"].(string); ok && path != "" {
+			return path
+		}
+		for _, key := range []string{"patch", "input"} {
+			if patch, ok := tool.Input[key].(string); ok {
+				if m := patchFileRe.FindStringSubmatch(patch); m != nil {
+					return strings.TrimSpace(m[1])
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// FilesTouched returns the files the tool calls in entries read or changed (see
+// ToolFilePath), one FileActivity per path sorted by path, counting Read calls as reads
+// and the editing tools' calls as writes.
+func FilesTouched(entries []models.ConversationEntry) []FileActivity {
+	byPath := make(map[string]*FileActivity)
+	for i := range entries {
+		for _, tool := range entries[i].ExtractToolCalls() {
+			path := ToolFilePath(tool)
+			if path == "" {
+				continue
+			}
+			activity := byPath[path]
+			if activity == nil {
+				activity = &FileActivity{Path: path}
+				byPath[path] = activity
+			}
+			if tool.Name == "Read" {
+				activity.Reads++
+			} else {
+				activity.Writes++
+			}
+		}
+	}
+
+	files := make([]FileActivity, 0, len(byPath))
+	for _, activity := range byPath {
+		files = append(files, *activity)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
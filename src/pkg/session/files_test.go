@@ -0,0 +1,53 @@
+package session
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestToolFilePath(t *testing.T) {
+	tests := []struct {
+		tool models.ToolUse
+		want string
+	}{
+		{models.ToolUse{Name: "Read", Input: map[string]any{"file_path": "/a.go"}}, "/a.go"},
+		{models.ToolUse{Name: "Write", Input: map[string]any{"file_path": "/b.go"}}, "/b.go"},
+		{models.ToolUse{Name: "NotebookEdit", Input: map[string]any{"notebook_path": "/n.ipynb"}}, "/n.ipynb"},
+		{models.ToolUse{Name: "ApplyPatch", Input: map[string]any{"patch": "*** Begin Patch\n*** Update File: src/c.go\n"}}, "src/c.go"},
+		{models.ToolUse{Name: "Grep", Input: map[string]any{"path": "/src"}}, ""},
+		{models.ToolUse{Name: "Read"}, ""},
+	}
+	for _, tt := range tests {
+		if got := ToolFilePath(tt.tool); got != tt.want {
+			t.Errorf("ToolFilePath(%s %v) = %q, want %q", tt.tool.Name, tt.tool.Input, got, tt.want)
+		}
+	}
+}
+
+func TestFilesTouched(t *testing.T) {
+	call := func(name, key, path string) string {
+		return `{"type":"tool_use","id":"t","name":"` + name + `","input":{"` + key + `":"` + path + `"}}`
+	}
+	entries := []models.ConversationEntry{
+		{Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[` +
+			call("Read", "file_path", "/src/main.go") + `,` + call("Edit", "file_path", "/src/main.go") + `]}`)},
+		{Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[` +
+			call("MultiEdit", "file_path", "/src/main.go") + `,` + call("Read", "file_path", "/README.md") + `,` +
+			call("Write", "file_path", "/src/new.go") + `,` + call("Bash", "command", "ls") + `]}`)},
+	}
+
+	want := []FileActivity{
+		{Path: "/README.md", Reads: 1},
+		{Path: "/src/main.go", Reads: 1, Writes: 2},
+		{Path: "/src/new.go", Writes: 1},
+	}
+	if got := FilesTouched(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("FilesTouched() = %+v, want %+v", got, want)
+	}
+	if got := FilesTouched(nil); len(got) != 0 {
+		t.Errorf("FilesTouched(nil) = %+v, want none", got)
+	}
+}
@@ -0,0 +1,64 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// FingerprintOptions controls which entry fields SessionFingerprintWith hashes.
+type FingerprintOptions struct {
+	// IncludeTimestamps also hashes each entry's timestamp. Off by default, so a
+	// conversation replayed or re-recorded at another time keeps its fingerprint.
+	IncludeTimestamps bool
+}
+
+// SessionFingerprint returns a stable key for the content of a conversation, for
+// deduplication and caching. It is SessionFingerprintWith with default options.
+func SessionFingerprint(entries []models.ConversationEntry) string {
+	return SessionFingerprintWith(entries, FingerprintOptions{})
+}
+
+// SessionFingerprintWith returns a 16-digit hex hash of each entry's UUID, type,
+// whitespace-normalized text, and tool calls (name and input), in order: reordering
+// the entries changes the fingerprint. Tool inputs are hashed as JSON with sorted
+// keys, so their key order does not matter.
+//
+// The hash is 64-bit FNV-1a. It is a content key, not a cryptographic digest: it is
+// cheap to compute and stable across runs and platforms, but collisions can be
+// constructed on purpose, so never rely on it to detect tampering.
+func SessionFingerprintWith(entries []models.ConversationEntry, opts FingerprintOptions) string {
+	h := fnv.New64a()
+	for i := range entries {
+		writeEntryFingerprint(h, &entries[i], opts)
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// writeEntryFingerprint writes entry's fingerprinted fields to h. Fields end with a
+// unit separator and entries with a record separator, so moving text from one field
+// or entry to the next changes the hash.
+func writeEntryFingerprint(h hash.Hash64, entry *models.ConversationEntry, opts FingerprintOptions) {
+	field := func(s string) {
+		h.Write([]byte(s))
+		h.Write([]byte{0x1f})
+	}
+
+	field(entry.UUID)
+	field(string(entry.Type))
+	if opts.IncludeTimestamps {
+		field(entry.Timestamp)
+	}
+	field(strings.Join(strings.Fields(entry.GetTextContent()), " "))
+	for _, tool := range entry.ExtractToolCalls() {
+		field(tool.Name)
+		// encoding/json sorts map keys; inputs that fail to marshal hash as empty
+		input, _ := json.Marshal(tool.Input)
+		field(string(input))
+	}
+	h.Write([]byte{0x1e})
+}
@@ -0,0 +1,110 @@
+package session
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func fingerprintEntries() []models.ConversationEntry {
+	return []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: json.RawMessage(`"List the files"`)},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:01Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Sure."},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls","timeout":5}}]}`)},
+	}
+}
+
+func TestSessionFingerprint_Stable(t *testing.T) {
+	got := SessionFingerprint(fingerprintEntries())
+	if !regexp.MustCompile(`^[0-9a-f]{16}$`).MatchString(got) {
+		t.Fatalf("SessionFingerprint() = %q, want 16 hex digits", got)
+	}
+	if again := SessionFingerprint(fingerprintEntries()); again != got {
+		t.Errorf("fingerprint changed between calls: %s then %s", got, again)
+	}
+	if empty := SessionFingerprint(nil); empty == got {
+		t.Error("empty conversation should not share a fingerprint with a non-empty one")
+	}
+}
+
+func TestSessionFingerprint_OrderSensitive(t *testing.T) {
+	entries := fingerprintEntries()
+	swapped := []models.ConversationEntry{entries[1], entries[0]}
+	if SessionFingerprint(entries) == SessionFingerprint(swapped) {
+		t.Error("reordering entries should change the fingerprint")
+	}
+}
+
+func TestSessionFingerprint_ContentChanges(t *testing.T) {
+	base := SessionFingerprint(fingerprintEntries())
+
+	tests := map[string]func(e []models.ConversationEntry){
+		"uuid": func(e []models.ConversationEntry) { e[0].UUID = "u2" },
+		"text": func(e []models.ConversationEntry) { e[0].Message = json.RawMessage(`"List the dirs"`) },
+		"tool input": func(e []models.ConversationEntry) {
+			e[1].Message = json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Sure."},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls -a","timeout":5}}]}`)
+		},
+		"tool name": func(e []models.ConversationEntry) {
+			e[1].Message = json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Sure."},{"type":"tool_use","id":"t1","name":"Sh","input":{"command":"ls","timeout":5}}]}`)
+		},
+		"dropped": func(e []models.ConversationEntry) { e[1] = models.ConversationEntry{} },
+	}
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			entries := fingerprintEntries()
+			change(entries)
+			if SessionFingerprint(entries) == base {
+				t.Errorf("changing the %s should change the fingerprint", name)
+			}
+		})
+	}
+}
+
+func TestSessionFingerprint_IgnoresVolatileDetails(t *testing.T) {
+	base := SessionFingerprint(fingerprintEntries())
+
+	tests := map[string]func(e []models.ConversationEntry){
+		"timestamps": func(e []models.ConversationEntry) {
+			e[0].Timestamp = "2027-01-01T00:00:00Z"
+			e[1].Timestamp = "2027-01-01T00:00:01Z"
+		},
+		"whitespace": func(e []models.ConversationEntry) { e[0].Message = json.RawMessage(`"  List   the\n files "`) },
+		"input key order": func(e []models.ConversationEntry) {
+			e[1].Message = json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Sure."},{"type":"tool_use","id":"t1","name":"Bash","input":{"timeout":5,"command":"ls"}}]}`)
+		},
+		"source line": func(e []models.ConversationEntry) { e[0].SourceLine = 42 },
+	}
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			entries := fingerprintEntries()
+			change(entries)
+			if got := SessionFingerprint(entries); got != base {
+				t.Errorf("changing %s changed the fingerprint: %s, want %s", name, got, base)
+			}
+		})
+	}
+}
+
+func TestSessionFingerprintWith_IncludeTimestamps(t *testing.T) {
+	opts := FingerprintOptions{IncludeTimestamps: true}
+	entries := fingerprintEntries()
+	base := SessionFingerprintWith(entries, opts)
+	if base == SessionFingerprint(entries) {
+		t.Error("including timestamps should give a different fingerprint")
+	}
+
+	entries[1].Timestamp = "2026-02-01T10:00:02Z"
+	if SessionFingerprintWith(entries, opts) == base {
+		t.Error("with IncludeTimestamps, changing a timestamp should change the fingerprint")
+	}
+}
+
+func TestSessionFingerprint_FieldBoundaries(t *testing.T) {
+	a := []models.ConversationEntry{{UUID: "ab", Type: models.EntryTypeUser}, {UUID: "c", Type: models.EntryTypeUser}}
+	b := []models.ConversationEntry{{UUID: "a", Type: models.EntryTypeUser}, {UUID: "bc", Type: models.EntryTypeUser}}
+	if SessionFingerprint(a) == SessionFingerprint(b) {
+		t.Error("moving characters between entries should change the fingerprint")
+	}
+}
@@ -0,0 +1,203 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// DefaultFollowPollInterval is how often FollowSession checks the file for changes.
+const DefaultFollowPollInterval = 250 * time.Millisecond
+
+// FollowOptions configures FollowSessionWith.
+type FollowOptions struct {
+	// PollInterval is how often the file is checked for appended data, truncation
+	// and replacement. Zero uses DefaultFollowPollInterval.
+	PollInterval time.Duration
+}
+
+// FollowSession tails a session JSONL file, calling fn for each entry appended after
+// it is opened. It runs until the file can no longer be read; see FollowSessionWith.
+func FollowSession(path string, fn func(models.ConversationEntry)) error {
+	return FollowSessionWith(context.Background(), path, FollowOptions{}, fn)
+}
+
+// FollowSessionWith tails a session JSONL file like FollowSession until ctx is done,
+// when it returns nil.
+//
+// Following starts at the end of the file, so existing entries are not reported. A
+// line is only parsed once its terminating newline has been written; a partial line
+// is held until the rest arrives. Blank and malformed lines are skipped, as in
+// ReadSession. If the file shrinks (truncated and rewritten), following restarts at
+// its beginning. If the path is replaced by a new file (rotated), the new file is
+// read from its beginning; while the path is missing, polling continues.
+func FollowSessionWith(ctx context.Context, path string, opts FollowOptions, fn func(models.ConversationEntry)) error {
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = DefaultFollowPollInterval
+	}
+
+	f := &follower{path: path, fn: fn}
+	if err := f.openAtEnd(); err != nil {
+		return err
+	}
+	defer f.close()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := f.poll(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// follower holds the state of a followed session file between polls.
+type follower struct {
+	path    string
+	fn      func(models.ConversationEntry)
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte // bytes of an unterminated line
+	skip    bool   // discard up to the next newline (the line in progress at open)
+}
+
+// openAtEnd opens the file positioned at its end. If the file does not end with a
+// newline, the line being written is skipped: its beginning was never seen.
+func (f *follower) openAtEnd() error {
+	file, err := os.Open(f.path) //nolint:gosec // G304: file path from CLI input is expected
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file, f.info, f.offset = file, info, info.Size()
+	if f.offset > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, f.offset-1); err != nil {
+			_ = file.Close()
+			return fmt.Errorf("failed to read %s: %w", f.path, err)
+		}
+		f.skip = last[0] != '\n'
+	}
+	return nil
+}
+
+func (f *follower) close() {
+	if f.file != nil {
+		_ = f.file.Close()
+		f.file = nil
+	}
+}
+
+// poll handles a rotation or truncation of the file, then reads whatever was appended.
+func (f *follower) poll() error {
+	info, err := os.Stat(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil // mid-rotation; the new file will appear
+	}
+	if err != nil {
+		return err
+	}
+
+	if f.file == nil || !os.SameFile(f.info, info) {
+		// Drain the old file first: writes that landed before the rename still count
+		if f.file != nil {
+			if err := f.read(); err != nil {
+				return err
+			}
+			f.close()
+		}
+		file, err := os.Open(f.path) //nolint:gosec // G304: file path from CLI input is expected
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		f.file, f.info = file, info
+		f.restart()
+	} else if info.Size() < f.offset {
+		f.restart()
+	}
+
+	return f.read()
+}
+
+// restart follows the current file from its beginning.
+func (f *follower) restart() {
+	f.offset = 0
+	f.partial = nil
+	f.skip = false
+}
+
+// read consumes the bytes appended since the last read and reports complete lines.
+func (f *follower) read() error {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := f.file.ReadAt(buf, f.offset)
+		if n > 0 {
+			f.offset += int64(n)
+			f.consume(buf[:n])
+		}
+		if err == io.EOF || (err == nil && n == 0) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.path, err)
+		}
+	}
+}
+
+// consume splits data into lines, holding back a trailing partial line.
+func (f *follower) consume(data []byte) {
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			if !f.skip {
+				f.partial = append(f.partial, data...)
+			}
+			return
+		}
+		line := data[:i]
+		data = data[i+1:]
+		if f.skip {
+			f.skip = false
+			continue
+		}
+		if len(f.partial) > 0 {
+			line = append(f.partial, line...)
+			f.partial = nil
+		}
+		f.emit(line)
+	}
+}
+
+// emit parses a complete line and passes it to fn, skipping blank or malformed lines.
+func (f *follower) emit(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+	var entry models.ConversationEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return
+	}
+	f.fn(entry)
+}
@@ -0,0 +1,267 @@
+package session
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// followHarness runs FollowSessionWith on a file in the background and collects the
+// UUIDs of the entries it reports.
+type followHarness struct {
+	t      *testing.T
+	path   string
+	mu     sync.Mutex
+	uuids  []string
+	cancel context.CancelFunc
+	done   chan error
+}
+
+func startFollow(t *testing.T, initial string) *followHarness {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(initial), 0600); err != nil {
+		t.Fatal(err)
+	}
+	h := &followHarness{t: t, path: path, done: make(chan error, 1)}
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+
+	go func() {
+		h.done <- FollowSessionWith(ctx, path, FollowOptions{PollInterval: 5 * time.Millisecond}, h.record)
+	}()
+	// Give FollowSessionWith time to open the file and seek to its end
+	time.Sleep(30 * time.Millisecond)
+	t.Cleanup(h.stop)
+	return h
+}
+
+func (h *followHarness) record(entry models.ConversationEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.uuids = append(h.uuids, entry.UUID)
+}
+
+func (h *followHarness) seen() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.uuids...)
+}
+
+func (h *followHarness) stop() {
+	if h.cancel != nil {
+		h.cancel()
+		h.cancel = nil
+		if err := <-h.done; err != nil {
+			h.t.Errorf("FollowSessionWith() error = %v", err)
+		}
+	}
+}
+
+func (h *followHarness) append(data string) {
+	h.t.Helper()
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	if _, err := f.WriteString(data); err != nil {
+		h.t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+// waitFor waits until want entries have been seen, failing the test on timeout.
+func (h *followHarness) waitFor(want ...string) {
+	h.t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if equalStrings(h.seen(), want) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	h.t.Fatalf("seen = %v, want %v", h.seen(), want)
+}
+
+// settle gives the follower a few polls to pick up anything pending.
+func (h *followHarness) settle() {
+	time.Sleep(50 * time.Millisecond)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func followLine(uuid string) string {
+	return `{"uuid":"` + uuid + `","type":"user","timestamp":"2026-02-01T10:00:00Z","message":"hi"}` + "\n"
+}
+
+func TestFollowSession_ReportsOnlyAppendedEntries(t *testing.T) {
+	h := startFollow(t, followLine("old-1")+followLine("old-2"))
+
+	h.append(followLine("new-1"))
+	h.waitFor("new-1")
+	h.append(followLine("new-2") + followLine("new-3"))
+	h.waitFor("new-1", "new-2", "new-3")
+}
+
+func TestFollowSession_WaitsForNewline(t *testing.T) {
+	h := startFollow(t, followLine("old"))
+
+	line := followLine("split")
+	h.append(line[:20])
+	h.settle()
+	if got := h.seen(); len(got) != 0 {
+		t.Fatalf("partial line reported: %v", got)
+	}
+	h.append(line[20 : len(line)-1])
+	h.settle()
+	if got := h.seen(); len(got) != 0 {
+		t.Fatalf("unterminated line reported: %v", got)
+	}
+	h.append("\n")
+	h.waitFor("split")
+}
+
+func TestFollowSession_SkipsLineInProgressAtStart(t *testing.T) {
+	partial := followLine("in-progress")
+	h := startFollow(t, followLine("old")+partial[:15])
+
+	// The rest of the in-progress line cannot be parsed on its own and is dropped
+	h.append(partial[15:] + followLine("next"))
+	h.waitFor("next")
+}
+
+func TestFollowSession_SkipsBlankAndMalformedLines(t *testing.T) {
+	h := startFollow(t, "")
+
+	h.append("\n   \nnot json\n{\"uuid\":\n" + followLine("good"))
+	h.waitFor("good")
+}
+
+func TestFollowSession_Truncation(t *testing.T) {
+	h := startFollow(t, followLine("old-1")+followLine("old-2"))
+
+	h.append(followLine("new-1"))
+	h.waitFor("new-1")
+
+	if err := os.WriteFile(h.path, []byte(followLine("rewritten")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	h.waitFor("new-1", "rewritten")
+
+	h.append(followLine("after"))
+	h.waitFor("new-1", "rewritten", "after")
+}
+
+func TestFollowSession_TruncationDropsPartialLine(t *testing.T) {
+	h := startFollow(t, "")
+
+	h.append(followLine("half")[:20])
+	h.settle()
+	if err := os.Truncate(h.path, 0); err != nil {
+		t.Fatal(err)
+	}
+	h.settle()
+
+	h.append(followLine("fresh"))
+	h.waitFor("fresh")
+}
+
+func TestFollowSession_Rotation(t *testing.T) {
+	h := startFollow(t, followLine("old"))
+
+	h.append(followLine("before"))
+	h.waitFor("before")
+
+	// Replace the file the way log rotation does: write a new file and rename it over
+	replacement := h.path + ".new"
+	content := followLine("rotated-1") + followLine("rotated-2") + followLine("rotated-3")
+	if err := os.WriteFile(replacement, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(replacement, h.path); err != nil {
+		t.Fatal(err)
+	}
+	h.waitFor("before", "rotated-1", "rotated-2", "rotated-3")
+
+	h.append(followLine("after"))
+	h.waitFor("before", "rotated-1", "rotated-2", "rotated-3", "after")
+}
+
+func TestFollowSession_FileRemovedThenRecreated(t *testing.T) {
+	h := startFollow(t, followLine("old"))
+
+	if err := os.Remove(h.path); err != nil {
+		t.Fatal(err)
+	}
+	h.settle()
+	if err := os.WriteFile(h.path, []byte(followLine("recreated")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	h.waitFor("recreated")
+}
+
+func TestFollowSessionWith_StopsOnCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- FollowSessionWith(ctx, path, FollowOptions{PollInterval: time.Millisecond}, func(models.ConversationEntry) {})
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("FollowSessionWith() error = %v, want nil", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("FollowSessionWith did not return after cancel")
+	}
+}
+
+func TestFollowSession_MissingFile(t *testing.T) {
+	err := FollowSession(filepath.Join(t.TempDir(), "missing.jsonl"), func(models.ConversationEntry) {})
+	if !os.IsNotExist(err) {
+		t.Errorf("FollowSession() error = %v, want not-exist error", err)
+	}
+}
+
+func TestFollowerConsume_SplitAcrossReads(t *testing.T) {
+	var got []string
+	f := &follower{fn: func(e models.ConversationEntry) { got = append(got, e.UUID) }}
+	line := followLine("a") + followLine("b")
+
+	for i := 0; i < len(line); i += 7 {
+		end := i + 7
+		if end > len(line) {
+			end = len(line)
+		}
+		f.consume([]byte(line[i:end]))
+	}
+	if !equalStrings(got, []string{"a", "b"}) {
+		t.Errorf("got %v, want [a b]", got)
+	}
+	if len(f.partial) != 0 {
+		t.Errorf("partial = %q, want empty", f.partial)
+	}
+}
@@ -0,0 +1,41 @@
+package session
+
+import (
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// SessionLineage returns the IDs of the sessions that the session in entries was
+// resumed from, oldest first. The session the entries belong to is the one of the last
+// entry with a sessionId; earlier entries carried over from other sessions name its
+// ancestors (see models.ConversationEntry.ParentSessionID). It returns nil for a session
+// without lineage information, including one never resumed.
+func SessionLineage(entries []models.ConversationEntry) []string {
+	current := CurrentSessionID(entries)
+	if current == "" {
+		return nil
+	}
+
+	var lineage []string
+	seen := make(map[string]bool)
+	for i := range entries {
+		parent := entries[i].ParentSessionID(current)
+		if parent == "" || seen[parent] {
+			continue
+		}
+		seen[parent] = true
+		lineage = append(lineage, parent)
+	}
+	return lineage
+}
+
+// CurrentSessionID returns the session that entries belong to: the sessionId of the last
+// entry with one, since entries carried over by a resume keep the ID of their own session.
+// It returns "" if no entry has a sessionId.
+func CurrentSessionID(entries []models.ConversationEntry) string {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].SessionID != "" {
+			return entries[i].SessionID
+		}
+	}
+	return ""
+}
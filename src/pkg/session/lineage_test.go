@@ -0,0 +1,45 @@
+package session
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestSessionLineage(t *testing.T) {
+	tests := []struct {
+		name       string
+		sessionIDs []string
+		want       []string
+	}{
+		{"no entries", nil, nil},
+		{"no session IDs", []string{"", ""}, nil},
+		{"never resumed", []string{"s1", "s1"}, nil},
+		{"resumed once", []string{"s1", "s1", "s2", "s2"}, []string{"s1"}},
+		{"resumed twice", []string{"s1", "s2", "s2", "s3"}, []string{"s1", "s2"}},
+		{"entries without ID ignored", []string{"s1", "", "s2", ""}, []string{"s1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entries []models.ConversationEntry
+			for _, id := range tt.sessionIDs {
+				entries = append(entries, models.ConversationEntry{SessionID: id})
+			}
+			if got := SessionLineage(entries); !slices.Equal(got, tt.want) {
+				t.Errorf("SessionLineage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrentSessionID(t *testing.T) {
+	entries := []models.ConversationEntry{{SessionID: "s1"}, {SessionID: "s2"}, {}}
+	if got := CurrentSessionID(entries); got != "s2" {
+		t.Errorf("CurrentSessionID() = %q, want s2", got)
+	}
+	if got := CurrentSessionID(nil); got != "" {
+		t.Errorf("CurrentSessionID(nil) = %q, want empty", got)
+	}
+}
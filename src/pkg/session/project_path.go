@@ -0,0 +1,145 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/encoding"
+	"github.com/randlee/claude-history/pkg/models"
+	"github.com/randlee/claude-history/pkg/paths"
+)
+
+// InferProjectPath recovers the project a session file belongs to, for files read
+// directly rather than through a project directory. It uses, in order:
+//   - the cwd of the first entry that records one
+//   - the projectPath of sessions-index.json in the enclosing project directory
+//   - the name of the enclosing ~/.claude/projects/<encoded> directory, decoded
+//
+// Since '/', '.' and '-' all encode to '-', the name is decoded by matching it against
+// the directories that exist on disk (see DecodeProjectDirName), so project paths
+// containing dashes or dots come back intact. If no such path exists, the name is
+// decoded with encoding.DecodePath, which turns every dash into a separator. Subagent
+// files under <encoded>/<session>/subagents use the same project directory.
+func InferProjectPath(sessionFile string) (string, error) {
+	var cwd string
+	err := ScanSession(sessionFile, func(entry models.ConversationEntry) error {
+		if entry.Cwd != "" {
+			cwd = entry.Cwd
+			return StopScan
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if cwd != "" {
+		return cwd, nil
+	}
+
+	projectDir := enclosingProjectDir(sessionFile)
+	if projectDir == "" {
+		return "", fmt.Errorf("cannot infer project path of %s: no entry records a cwd and it is not in a projects directory", sessionFile)
+	}
+
+	indexPath := filepath.Join(projectDir, "sessions-index.json")
+	if paths.Exists(indexPath) {
+		if index, err := ReadSessionIndex(indexPath); err == nil {
+			if projectPath := GetProjectPathFromIndex(index); projectPath != "" {
+				return projectPath, nil
+			}
+		}
+	}
+
+	name := filepath.Base(projectDir)
+	if projectPath, ok := DecodeProjectDirName(name); ok {
+		return projectPath, nil
+	}
+	return encoding.DecodePath(name, ""), nil
+}
+
+// enclosingProjectDir returns the nearest ancestor directory of file that sits directly
+// in a "projects" directory and has an encoded path as its name, or "" if none does.
+func enclosingProjectDir(file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return ""
+	}
+	for dir := filepath.Dir(abs); ; {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		if filepath.Base(parent) == "projects" && encoding.IsEncodedPath(filepath.Base(dir)) {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// DecodeProjectDirName decodes an encoded project directory name (see encoding.EncodePath)
+// to the existing directory it was encoded from. Each dash is either a separator or a
+// character of the path ('-' or '.'), so the name is matched one path component at a
+// time against the entries of the directories on disk, preferring the longest matching
+// component. ok is false if no existing directory encodes to name.
+func DecodeProjectDirName(name string) (path string, ok bool) {
+	root, rest, ok := splitEncodedRoot(name)
+	if !ok {
+		return "", false
+	}
+	return matchEncodedPath(root, rest)
+}
+
+// splitEncodedRoot splits an encoded absolute path into the filesystem root it starts
+// from and the encoded components after it. ok is false if name does not encode an
+// absolute path.
+func splitEncodedRoot(name string) (root, rest string, ok bool) {
+	if runtime.GOOS == "windows" {
+		// C:\Users\x encodes to C--Users-x
+		if len(name) >= 3 && name[1] == '-' && name[2] == '-' {
+			return name[:1] + `:\`, name[3:], true
+		}
+		return "", "", false
+	}
+	if !strings.HasPrefix(name, "-") {
+		return "", "", false
+	}
+	return "/", name[1:], true
+}
+
+// matchEncodedPath returns the directory under dir whose path relative to dir encodes to
+// rest, trying the entries of each directory longest encoded name first.
+func matchEncodedPath(dir, rest string) (string, bool) {
+	if rest == "" {
+		return dir, true
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return len(entries[i].Name()) > len(entries[j].Name())
+	})
+
+	for _, entry := range entries {
+		encoded := encoding.EncodePath(entry.Name())
+		if rest != encoded && !strings.HasPrefix(rest, encoded+"-") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			continue // Follows symlinks, e.g. /tmp on macOS
+		}
+		if rest == encoded {
+			return path, true
+		}
+		if resolved, ok := matchEncodedPath(path, rest[len(encoded)+1:]); ok {
+			return resolved, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,158 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/encoding"
+)
+
+// writeProjectSession writes a session file with the given content into the Claude
+// project directory of projectPath under claudeDir, returning the file's path.
+func writeProjectSession(t *testing.T, claudeDir, projectPath, content string) string {
+	t.Helper()
+	projectDir := filepath.Join(claudeDir, "projects", encoding.EncodePath(projectPath))
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(projectDir, "session-1.jsonl")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestInferProjectPath_FromCwd(t *testing.T) {
+	file := writeProjectSession(t, t.TempDir(), "/some/project", `{"type":"summary","summary":"s"}
+{"type":"user","cwd":"/work/my-app","message":"hi"}
+{"type":"user","cwd":"/work/other","message":"later"}
+`)
+
+	got, err := InferProjectPath(file)
+	if err != nil {
+		t.Fatalf("InferProjectPath() error = %v", err)
+	}
+	if got != "/work/my-app" {
+		t.Errorf("InferProjectPath() = %q, want the first cwd", got)
+	}
+}
+
+func TestInferProjectPath_FromIndex(t *testing.T) {
+	claudeDir := t.TempDir()
+	file := writeProjectSession(t, claudeDir, "/work/my-app", `{"type":"user","message":"hi"}`+"\n")
+	index := `{"version":1,"entries":[{"sessionId":"session-1","projectPath":"/work/my-app"}]}`
+	if err := os.WriteFile(filepath.Join(filepath.Dir(file), "sessions-index.json"), []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := InferProjectPath(file)
+	if err != nil {
+		t.Fatalf("InferProjectPath() error = %v", err)
+	}
+	if got != "/work/my-app" {
+		t.Errorf("InferProjectPath() = %q, want the index projectPath", got)
+	}
+}
+
+func TestInferProjectPath_FromDirectoryName(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("project paths below are Unix paths")
+	}
+	root := t.TempDir()
+	projectPath := filepath.Join(root, "my-app", "v1.2", "web-ui")
+	if err := os.MkdirAll(projectPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	file := writeProjectSession(t, filepath.Join(root, ".claude"), projectPath, `{"type":"user","message":"hi"}`+"\n")
+
+	got, err := InferProjectPath(file)
+	if err != nil {
+		t.Fatalf("InferProjectPath() error = %v", err)
+	}
+	if got != projectPath {
+		t.Errorf("InferProjectPath() = %q, want %q", got, projectPath)
+	}
+
+	// Subagent files resolve to the same project
+	agentFile := filepath.Join(filepath.Dir(file), "session-1", "subagents", "agent-a1.jsonl")
+	if err := os.MkdirAll(filepath.Dir(agentFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(agentFile, []byte(`{"type":"user","message":"task"}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := InferProjectPath(agentFile); err != nil || got != projectPath {
+		t.Errorf("InferProjectPath(agent file) = %q, %v, want %q", got, err, projectPath)
+	}
+}
+
+func TestInferProjectPath_MissingDirectoryFallsBack(t *testing.T) {
+	file := writeProjectSession(t, t.TempDir(), "/no/such/dir-x", `{"type":"user","message":"hi"}`+"\n")
+
+	got, err := InferProjectPath(file)
+	if err != nil {
+		t.Fatalf("InferProjectPath() error = %v", err)
+	}
+	if want := encoding.DecodePath(encoding.EncodePath("/no/such/dir-x"), ""); got != want {
+		t.Errorf("InferProjectPath() = %q, want heuristic decoding %q", got, want)
+	}
+}
+
+func TestInferProjectPath_NotInProjectsDir(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(file, []byte(`{"type":"user","message":"hi"}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := InferProjectPath(file); err == nil {
+		t.Error("InferProjectPath() should fail without a cwd or project directory")
+	}
+}
+
+func TestDecodeProjectDirName(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("project paths below are Unix paths")
+	}
+
+	for _, rel := range []string{"a-b/c", "a/b-c", "my-long-project-name", "x/.config/y", "dotted.name/z"} {
+		t.Run(rel, func(t *testing.T) {
+			want := filepath.Join(t.TempDir(), rel)
+			if err := os.MkdirAll(want, 0755); err != nil {
+				t.Fatal(err)
+			}
+			got, ok := DecodeProjectDirName(encoding.EncodePath(want))
+			if !ok || got != want {
+				t.Errorf("DecodeProjectDirName() = %q, %v, want %q", got, ok, want)
+			}
+		})
+	}
+
+	root := t.TempDir()
+	if _, ok := DecodeProjectDirName(encoding.EncodePath(filepath.Join(root, "missing"))); ok {
+		t.Error("DecodeProjectDirName() should fail for a path that does not exist")
+	}
+	if _, ok := DecodeProjectDirName("relative-name"); ok {
+		t.Error("DecodeProjectDirName() should fail for a name that is not an encoded absolute path")
+	}
+}
+
+func TestDecodeProjectDirName_PrefersLongestComponent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("project paths below are Unix paths")
+	}
+	// a-b/c and a/b-c encode to the same name; the longer first component wins
+	root := t.TempDir()
+	for _, dir := range []string{"a-b/c", "a/b-c"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := filepath.Join(root, "a-b", "c")
+	if got, ok := DecodeProjectDirName(encoding.EncodePath(want)); !ok || got != want {
+		t.Errorf("DecodeProjectDirName() = %q, %v, want %q", got, ok, want)
+	}
+}
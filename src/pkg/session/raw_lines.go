@@ -0,0 +1,44 @@
+package session
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// RawLines returns the JSONL line of each entry: the line it was read from (see
+// ReadSession), so fields the models do not know about are kept, or for an entry built
+// in memory its parsed fields marshaled.
+func RawLines(entries []models.ConversationEntry) ([]json.RawMessage, error) {
+	lines := make([]json.RawMessage, 0, len(entries))
+	for _, entry := range entries {
+		line := entry.RawLine
+		if len(line) == 0 {
+			var err error
+			if line, err = json.Marshal(entry); err != nil {
+				return nil, err
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// WriteJSONL writes entries to w as JSONL, one RawLines line per entry, so reading the
+// output back with ReadSession yields the same entries.
+func WriteJSONL(w io.Writer, entries []models.ConversationEntry) error {
+	lines, err := RawLines(entries)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
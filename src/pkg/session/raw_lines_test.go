@@ -0,0 +1,63 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestWriteJSONL_RoundTrip(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.jsonl")
+	mustWriteFile(t, testFile, []byte(`{"uuid":"1","type":"user","unmodeled":true,"message":"Hello"}
+{"uuid":"2","type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Hi"}]}}
+{"uuid":"3","type":"user","message":"Bye"}
+`))
+
+	entries, err := ReadSession(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filtered := FilterEntries(entries, FilterOptions{Types: []models.EntryType{models.EntryTypeUser}})
+
+	var buf bytes.Buffer
+	if err := WriteJSONL(&buf, filtered); err != nil {
+		t.Fatalf("WriteJSONL() error: %v", err)
+	}
+	want := `{"uuid":"1","type":"user","unmodeled":true,"message":"Hello"}` + "\n" + `{"uuid":"3","type":"user","message":"Bye"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("WriteJSONL() =\n%s\nwant the original lines\n%s", buf.String(), want)
+	}
+
+	outFile := filepath.Join(t.TempDir(), "out.jsonl")
+	mustWriteFile(t, outFile, buf.Bytes())
+	reread, err := ReadSession(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reread) != len(filtered) {
+		t.Fatalf("re-read %d entries, want %d", len(reread), len(filtered))
+	}
+	for i := range reread {
+		// Line numbers differ between the files; everything else round-trips
+		reread[i].SourceLine, filtered[i].SourceLine = 0, 0
+		if !reflect.DeepEqual(reread[i], filtered[i]) {
+			t.Errorf("entry %d = %+v, want %+v", i, reread[i], filtered[i])
+		}
+	}
+}
+
+func TestRawLines_MarshalsEntriesWithoutRawLine(t *testing.T) {
+	entry := models.ConversationEntry{UUID: "1", Type: models.EntryTypeUser, Message: json.RawMessage(`"Hello"`)}
+	lines, err := RawLines([]models.ConversationEntry{entry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got models.ConversationEntry
+	if len(lines) != 1 || json.Unmarshal(lines[0], &got) != nil || got.UUID != "1" || string(got.Message) != `"Hello"` {
+		t.Errorf("RawLines() = %s, want the entry marshaled", lines)
+	}
+}
@@ -2,20 +2,37 @@
 package session
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/randlee/claude-history/internal/jsonl"
 	"github.com/randlee/claude-history/pkg/models"
 	"github.com/randlee/claude-history/pkg/paths"
 )
 
-// ReadSession reads all entries from a session JSONL file.
+// ReadSession reads all entries from a session JSONL file, recording each entry's
+// line in the file as its SourceLine and the line itself as its RawLine. Malformed
+// lines are skipped.
 func ReadSession(filePath string) ([]models.ConversationEntry, error) {
-	return jsonl.ReadAll[models.ConversationEntry](filePath)
+	var entries []models.ConversationEntry
+	err := jsonl.NewScanner().ScanNumbered(filePath, func(lineNum int, line json.RawMessage) error {
+		var entry models.ConversationEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil // Skip malformed entries
+		}
+		entry.SourceLine = lineNum
+		entry.RawLine = line
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
 }
 
 // ScanSession streams through a session JSONL file, calling fn for each entry.
@@ -37,11 +54,22 @@ func ScanSession(filePath string, fn func(entry models.ConversationEntry) error)
 }
 
 // GetSessionInfo extracts session metadata by scanning a session file.
+// The first prompt is truncated to 200 bytes; see GetSessionInfoWith.
 func GetSessionInfo(filePath string) (*models.Session, error) {
+	return GetSessionInfoWith(filePath, 200)
+}
+
+// GetSessionInfoWith extracts session metadata like GetSessionInfo, truncating the
+// first prompt to at most maxPromptLen bytes plus "..." (0 keeps the full prompt).
+// The first prompt is the text of the first user message that has any, whether the
+// message content is a plain string or a list of blocks; tool results are skipped.
+// Cwd and GitBranch are taken from the first entry that records each.
+func GetSessionInfoWith(filePath string, maxPromptLen int) (*models.Session, error) {
 	var session models.Session
 	var firstEntry, lastEntry *models.ConversationEntry
 	var messageCount int
 	var firstPrompt string
+	var cwd, gitBranch string
 
 	err := ScanSession(filePath, func(entry models.ConversationEntry) error {
 		messageCount++
@@ -53,12 +81,16 @@ func GetSessionInfo(filePath string) (*models.Session, error) {
 		entryCopy := entry
 		lastEntry = &entryCopy
 
+		if cwd == "" {
+			cwd = entry.Cwd
+		}
+		if gitBranch == "" {
+			gitBranch = entry.GitBranch
+		}
+
 		// Capture first user message as the prompt
-		if firstPrompt == "" && entry.IsUser() {
-			firstPrompt = entry.GetTextContent()
-			if len(firstPrompt) > 200 {
-				firstPrompt = firstPrompt[:200] + "..."
-			}
+		if firstPrompt == "" {
+			firstPrompt = truncatePrompt(promptText(entry), maxPromptLen)
 		}
 
 		return nil
@@ -86,14 +118,60 @@ func GetSessionInfo(filePath string) (*models.Session, error) {
 	session.FilePath = filePath
 	session.MessageCount = messageCount
 	session.FirstPrompt = firstPrompt
+	session.Cwd = cwd
+	session.GitBranch = gitBranch
 
 	return &session, nil
 }
 
+// FirstPrompt returns the full first prompt of a session's entries, the one
+// GetSessionInfo reports: the text of the first user message that has any. It returns
+// "" when no user message has text, as when they only carry tool results.
+func FirstPrompt(entries []models.ConversationEntry) string {
+	for i := range entries {
+		if text := promptText(entries[i]); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// promptText returns the text of a user message, a candidate first prompt; "" for
+// other entries.
+func promptText(entry models.ConversationEntry) string {
+	if !entry.IsUser() {
+		return ""
+	}
+	return entry.GetTextContent()
+}
+
+// truncatePrompt shortens prompt to at most maxLen bytes plus "...", without splitting
+// a UTF-8 character. A maxLen of 0 or less leaves it unchanged.
+func truncatePrompt(prompt string, maxLen int) string {
+	if maxLen <= 0 || len(prompt) <= maxLen {
+		return prompt
+	}
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(prompt[cut]) {
+		cut--
+	}
+	return prompt[:cut] + "..."
+}
+
 // ListSessions returns all sessions in a project directory.
 // It scans all JSONL files and enriches with index data when available.
-// Empty sessions (no user/assistant messages) are filtered out.
+// Empty sessions (no user/assistant messages) are filtered out, as are files that
+// cannot be read; ListSessionsWithErrors reports those.
 func ListSessions(projectDir string) ([]models.Session, error) {
+	sessions, _, err := ListSessionsWithErrors(projectDir)
+	return sessions, err
+}
+
+// ListSessionsWithErrors returns the sessions of a project directory like ListSessions,
+// along with a *SessionReadError for each session file that could not be read, sorted
+// by session ID. The files are read concurrently (see ReadConcurrently); err is only
+// for a directory that cannot be listed.
+func ListSessionsWithErrors(projectDir string) (sessions []models.Session, readErrs []error, err error) {
 	// Build index lookup map for enrichment
 	indexMap := make(map[string]*models.SessionIndexEntry)
 	indexPath := filepath.Join(projectDir, "sessions-index.json")
@@ -108,39 +186,57 @@ func ListSessions(projectDir string) ([]models.Session, error) {
 	// Always scan directory for all session files
 	sessionFiles, err := paths.ListSessionFiles(projectDir)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	sessionIDs := make([]string, 0, len(sessionFiles))
+	for sessionID := range sessionFiles {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	sort.Strings(sessionIDs)
 
-	var sessions []models.Session
-	for sessionID, filePath := range sessionFiles {
+	// Each file's session, or nil when it is empty
+	found := make([]*models.Session, len(sessionIDs))
+	errs := ReadConcurrently(len(sessionIDs), func(i int) error {
+		sessionID := sessionIDs[i]
 		// Check if we have index data for this session
 		if indexEntry, ok := indexMap[sessionID]; ok {
 			// Use index data (faster, has summary)
-			sessions = append(sessions, indexEntry.ToSession())
-		} else {
-			// Scan file to get session info
-			info, err := GetSessionInfo(filePath)
-			if err != nil {
-				// Skip files that can't be read
-				continue
-			}
-			info.ID = sessionID
+			s := indexEntry.ToSession()
+			found[i] = &s
+			return nil
+		}
 
-			// Filter out empty sessions (no actual conversation)
-			if !hasConversation(filePath) {
-				continue
-			}
+		// Scan file to get session info
+		filePath := sessionFiles[sessionID]
+		info, err := GetSessionInfo(filePath)
+		if err != nil {
+			return &SessionReadError{SessionID: sessionID, Path: filePath, Err: err}
+		}
+		info.ID = sessionID
 
-			sessions = append(sessions, *info)
+		// Filter out empty sessions (no actual conversation)
+		if !hasConversation(filePath) {
+			return nil
+		}
+		found[i] = info
+		return nil
+	})
+
+	for i, s := range found {
+		if s != nil {
+			sessions = append(sessions, *s)
+		}
+		if errs != nil && errs[i] != nil {
+			readErrs = append(readErrs, errs[i])
 		}
 	}
 
-	// Sort by modified time (most recent first)
-	sort.Slice(sessions, func(i, j int) bool {
+	// Sort by modified time (most recent first); ties stay in session ID order
+	sort.SliceStable(sessions, func(i, j int) bool {
 		return sessions[i].Modified.After(sessions[j].Modified)
 	})
 
-	return sessions, nil
+	return sessions, readErrs, nil
 }
 
 // hasConversation checks if a session file has at least one user or assistant message.
@@ -165,7 +261,7 @@ func (e *stopScanError) Error() string { return "stop scan" }
 
 // FindSession finds a session by ID in a project directory.
 func FindSession(projectDir string, sessionID string) (*models.Session, error) {
-	filePath := filepath.Join(projectDir, sessionID+".jsonl")
+	filePath := paths.JSONLFile(filepath.Join(projectDir, sessionID))
 	if !paths.Exists(filePath) {
 		return nil, os.ErrNotExist
 	}
@@ -173,23 +269,112 @@ func FindSession(projectDir string, sessionID string) (*models.Session, error) {
 	return GetSessionInfo(filePath)
 }
 
+// LatestSession returns the ID of the session in projectDir whose file was modified most
+// recently. Modification time tracks the session still being written, unlike the creation
+// time; sessions modified at the same instant are ordered by ID, the lowest winning.
+func LatestSession(projectDir string) (string, error) {
+	sessionFiles, err := paths.ListSessionFiles(projectDir)
+	if err != nil {
+		return "", err
+	}
+
+	latestID := ""
+	var latestMod time.Time
+	for sessionID, filePath := range sessionFiles {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			continue // Removed since listing
+		}
+		mod := info.ModTime()
+		if latestID == "" || mod.After(latestMod) || (mod.Equal(latestMod) && sessionID < latestID) {
+			latestID, latestMod = sessionID, mod
+		}
+	}
+
+	if latestID == "" {
+		return "", fmt.Errorf("no sessions found in %s", projectDir)
+	}
+	return latestID, nil
+}
+
 // FilterOptions specifies criteria for filtering session entries.
 type FilterOptions struct {
 	StartTime *time.Time
 	EndTime   *time.Time
-	Types     []models.EntryType
-	AgentID   string
+
+	// Times relative to the session start: SinceStart keeps entries at or after
+	// start+SinceStart and UntilStart entries at or before start+UntilStart, so
+	// UntilStart of 5m keeps the first five minutes. The start is SessionStart when set,
+	// otherwise the first parseable timestamp of the entries filtered (see
+	// SessionStartTime). They compose with StartTime and EndTime: an entry must pass
+	// both. When there is no start, because no entry has a parseable timestamp, they
+	// filter nothing out; otherwise entries without a timestamp are excluded, as by
+	// StartTime and EndTime.
+	SinceStart   *time.Duration
+	UntilStart   *time.Duration
+	SessionStart time.Time
+
+	Types   []models.EntryType
+	AgentID string
 
 	// Tool filtering
 	ToolTypes []string // Filter by tool names (case-insensitive)
 	ToolMatch string   // Regex pattern to match tool inputs
 
+	// ToolFieldMatch maps tool input fields to regexes their values must match, e.g.
+	// {"command": "^git "}. Nested fields use dot notation ("options.timeout"). A single
+	// tool call (one of ToolTypes, when set) must match every field; a missing field
+	// never matches. An invalid regex matches nothing.
+	ToolFieldMatch map[string]string
+
 	// Text search
 	TextSearch string // Search for text in message content (case-insensitive)
+
+	// Error filtering
+	ToolErrorsOnly bool // Keep only assistant entries with a tool call whose result is an error (respects ToolTypes)
+
+	// Delegation review
+	SpawnsOnly bool // Keep only entries that spawned an agent, in either spawn format (see models.ConversationEntry.IsSpawnEntry)
+
+	// Working context. Entries that don't record the field are excluded when it is set.
+	Cwd       string // Keep entries whose working directory is Cwd or below it
+	GitBranch string // Keep entries recorded on this git branch (exact match)
+
+	// Speaker turns (1-based). UserTurn keeps the Nth user message and AssistantTurn the
+	// Nth assistant message, where a message is an entry of that type with text (tool
+	// results and tool-call-only entries are not turns). Turns are numbered over all of
+	// the entries passed in, before any other filter, so "user turn 3" names the same
+	// entry whatever else is filtered; a turn past the end keeps nothing. When both are
+	// set, both entries are kept. 0 disables.
+	UserTurn      int
+	AssistantTurn int
+}
+
+// SessionStartTime returns the first parseable timestamp of entries, in file order: the
+// start that FilterOptions.SinceStart and UntilStart measure from. ok is false when no
+// entry has one.
+func SessionStartTime(entries []models.ConversationEntry) (start time.Time, ok bool) {
+	for i := range entries {
+		if ts, err := entries[i].GetTimestamp(); err == nil && !ts.IsZero() {
+			return ts, true
+		}
+	}
+	return time.Time{}, false
 }
 
 // FilterEntries filters session entries based on the given options.
+// Tool results for result-aware filters (ToolErrorsOnly) are taken from entries itself.
 func FilterEntries(entries []models.ConversationEntry, opts FilterOptions) []models.ConversationEntry {
+	var toolResults map[string]models.ToolResult
+	if opts.ToolErrorsOnly {
+		toolResults = buildToolResults(entries)
+	}
+	return FilterEntriesWithResults(entries, toolResults, opts)
+}
+
+// FilterEntriesWithResults filters entries like FilterEntries, using toolResults
+// (keyed by tool use ID) for result-aware filters such as ToolErrorsOnly.
+func FilterEntriesWithResults(entries []models.ConversationEntry, toolResults map[string]models.ToolResult, opts FilterOptions) []models.ConversationEntry {
 	var result []models.ConversationEntry
 
 	typeSet := make(map[models.EntryType]bool)
@@ -197,7 +382,36 @@ func FilterEntries(entries []models.ConversationEntry, opts FilterOptions) []mod
 		typeSet[t] = true
 	}
 
-	for _, entry := range entries {
+	fieldMatchers, fieldsValid := compileFieldMatchers(opts.ToolFieldMatch)
+
+	var turns map[int]bool
+	if opts.UserTurn > 0 || opts.AssistantTurn > 0 {
+		turns = selectTurns(entries, opts.UserTurn, opts.AssistantTurn)
+	}
+
+	// Relative times become absolute bounds once, from the session start
+	var sinceStart, untilStart *time.Time
+	if opts.SinceStart != nil || opts.UntilStart != nil {
+		start, ok := opts.SessionStart, !opts.SessionStart.IsZero()
+		if !ok {
+			start, ok = SessionStartTime(entries)
+		}
+		if ok && opts.SinceStart != nil {
+			t := start.Add(*opts.SinceStart)
+			sinceStart = &t
+		}
+		if ok && opts.UntilStart != nil {
+			t := start.Add(*opts.UntilStart)
+			untilStart = &t
+		}
+	}
+
+	for i, entry := range entries {
+		// Filter by speaker turn
+		if turns != nil && !turns[i] {
+			continue
+		}
+
 		// Filter by type
 		if len(typeSet) > 0 && !typeSet[entry.Type] {
 			continue
@@ -208,6 +422,19 @@ func FilterEntries(entries []models.ConversationEntry, opts FilterOptions) []mod
 			continue
 		}
 
+		// Filter by agent spawns (legacy queue operations or toolUseResult spawns)
+		if opts.SpawnsOnly && !entry.IsSpawnEntry() {
+			continue
+		}
+
+		// Filter by working directory and git branch
+		if opts.Cwd != "" && !pathWithin(entry.Cwd, opts.Cwd) {
+			continue
+		}
+		if opts.GitBranch != "" && entry.GitBranch != opts.GitBranch {
+			continue
+		}
+
 		// Filter by time range
 		if opts.StartTime != nil || opts.EndTime != nil {
 			ts, err := entry.GetTimestamp()
@@ -222,6 +449,20 @@ func FilterEntries(entries []models.ConversationEntry, opts FilterOptions) []mod
 			}
 		}
 
+		// Filter by time since the session start
+		if sinceStart != nil || untilStart != nil {
+			ts, err := entry.GetTimestamp()
+			if err != nil {
+				continue
+			}
+			if sinceStart != nil && ts.Before(*sinceStart) {
+				continue
+			}
+			if untilStart != nil && ts.After(*untilStart) {
+				continue
+			}
+		}
+
 		// Filter by tool types (only applies to entries with tool calls)
 		if len(opts.ToolTypes) > 0 {
 			hasMatchingTool := false
@@ -236,6 +477,11 @@ func FilterEntries(entries []models.ConversationEntry, opts FilterOptions) []mod
 			}
 		}
 
+		// Filter by errored tool calls (limited to ToolTypes when set)
+		if opts.ToolErrorsOnly && !hasToolError(entry, toolResults, opts.ToolTypes) {
+			continue
+		}
+
 		// Filter by tool input pattern
 		if opts.ToolMatch != "" {
 			if !entry.MatchesToolInput(opts.ToolMatch) {
@@ -243,6 +489,13 @@ func FilterEntries(entries []models.ConversationEntry, opts FilterOptions) []mod
 			}
 		}
 
+		// Filter by named tool input fields (limited to ToolTypes when set)
+		if len(opts.ToolFieldMatch) > 0 {
+			if !fieldsValid || !hasToolFieldMatch(entry, fieldMatchers, opts.ToolTypes) {
+				continue
+			}
+		}
+
 		// Filter by text search (case-insensitive)
 		if opts.TextSearch != "" {
 			textContent := entry.GetTextContent()
@@ -257,6 +510,141 @@ func FilterEntries(entries []models.ConversationEntry, opts FilterOptions) []mod
 	return result
 }
 
+// selectTurns returns the indexes in entries of the userTurn-th user message and the
+// assistantTurn-th assistant message (1-based; 0 selects none). Only entries with text
+// count as messages, so tool results and tool-call-only entries are skipped.
+func selectTurns(entries []models.ConversationEntry, userTurn, assistantTurn int) map[int]bool {
+	selected := make(map[int]bool, 2)
+	var users, assistants int
+	for i := range entries {
+		entry := &entries[i]
+		if !entry.IsUser() && !entry.IsAssistant() {
+			continue
+		}
+		if strings.TrimSpace(entry.GetTextContent()) == "" {
+			continue
+		}
+		if entry.IsUser() {
+			users++
+			if users == userTurn {
+				selected[i] = true
+			}
+		} else {
+			assistants++
+			if assistants == assistantTurn {
+				selected[i] = true
+			}
+		}
+	}
+	return selected
+}
+
+// buildToolResults maps tool use IDs to their results across all entries.
+func buildToolResults(entries []models.ConversationEntry) map[string]models.ToolResult {
+	results := make(map[string]models.ToolResult)
+	for i := range entries {
+		for _, r := range entries[i].ExtractToolResults() {
+			results[r.ToolUseID] = r
+		}
+	}
+	return results
+}
+
+// pathWithin reports whether path is dir or a path below it. An empty path (not
+// recorded) is never within dir.
+func pathWithin(path, dir string) bool {
+	if path == "" {
+		return false
+	}
+	path, dir = filepath.Clean(path), filepath.Clean(dir)
+	if path == dir {
+		return true
+	}
+	prefix := dir
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, prefix)
+}
+
+// hasToolError reports whether an assistant entry has a tool call whose result is an error.
+// If toolTypes is non-empty, only tool calls with one of those names (case-insensitive) count.
+func hasToolError(entry models.ConversationEntry, toolResults map[string]models.ToolResult, toolTypes []string) bool {
+	if entry.Type != models.EntryTypeAssistant {
+		return false
+	}
+	for _, tool := range entry.ExtractToolCalls() {
+		if len(toolTypes) > 0 && !containsFold(toolTypes, tool.Name) {
+			continue
+		}
+		if r, ok := toolResults[tool.ID]; ok && r.IsError {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldMatcher is a compiled ToolFieldMatch entry.
+type fieldMatcher struct {
+	path string
+	re   *regexp.Regexp
+}
+
+// compileFieldMatchers compiles the regexes of a ToolFieldMatch, in path order.
+// ok is false if any regex is invalid.
+func compileFieldMatchers(fields map[string]string) (matchers []fieldMatcher, ok bool) {
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		re, err := regexp.Compile(fields[path])
+		if err != nil {
+			return nil, false
+		}
+		matchers = append(matchers, fieldMatcher{path: path, re: re})
+	}
+	return matchers, true
+}
+
+// hasToolFieldMatch reports whether entry has a tool call (one of toolTypes, when set)
+// whose input fields match all of matchers.
+func hasToolFieldMatch(entry models.ConversationEntry, matchers []fieldMatcher, toolTypes []string) bool {
+	for _, tool := range entry.ExtractToolCalls() {
+		if len(toolTypes) > 0 && !containsFold(toolTypes, tool.Name) {
+			continue
+		}
+		if toolFieldsMatch(tool, matchers) {
+			return true
+		}
+	}
+	return false
+}
+
+// toolFieldsMatch reports whether every field of matchers is present in tool's input
+// and matches its regex.
+func toolFieldsMatch(tool models.ToolUse, matchers []fieldMatcher) bool {
+	for _, m := range matchers {
+		value, ok := tool.InputFieldString(m.path)
+		if !ok || !m.re.MatchString(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsFold reports whether values contains s, ignoring case.
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
 // CountEntriesByType counts entries grouped by type.
 func CountEntriesByType(entries []models.ConversationEntry) map[models.EntryType]int {
 	counts := make(map[models.EntryType]int)
@@ -265,3 +653,15 @@ func CountEntriesByType(entries []models.ConversationEntry) map[models.EntryType
 	}
 	return counts
 }
+
+// CountToolCalls counts tool calls grouped by tool name.
+// An entry with several tool calls contributes once per call.
+func CountToolCalls(entries []models.ConversationEntry) map[string]int {
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		for _, tool := range entry.ExtractToolCalls() {
+			counts[tool.Name]++
+		}
+	}
+	return counts
+}
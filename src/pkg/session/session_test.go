@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/randlee/claude-history/pkg/models"
 )
@@ -41,6 +43,83 @@ func TestReadSession(t *testing.T) {
 	}
 }
 
+func TestReadSession_SourceLine(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.jsonl")
+
+	content := `{"uuid":"1","type":"user","timestamp":"2026-02-01T18:00:00.000Z","message":"Hello"}
+{"uuid":"broken",
+{"uuid":"2","type":"progress","timestamp":"2026-02-01T18:00:01.000Z"}
+
+{"uuid":"3","type":"assistant","timestamp":"2026-02-01T18:00:02.000Z","message":"Hi there"}
+`
+	mustWriteFile(t, testFile, []byte(content))
+
+	entries, err := ReadSession(testFile)
+	if err != nil {
+		t.Fatalf("ReadSession() error: %v", err)
+	}
+
+	want := map[string]int{"1": 1, "2": 3, "3": 5}
+	if len(entries) != len(want) {
+		t.Fatalf("ReadSession() returned %d entries, want %d", len(entries), len(want))
+	}
+	for _, e := range entries {
+		if e.SourceLine != want[e.UUID] {
+			t.Errorf("entry %s SourceLine = %d, want %d", e.UUID, e.SourceLine, want[e.UUID])
+		}
+	}
+
+	// Line numbers come from the read, so filtering keeps them accurate
+	filtered := FilterEntries(entries, FilterOptions{Types: []models.EntryType{models.EntryTypeAssistant}})
+	if len(filtered) != 1 || filtered[0].SourceLine != 5 {
+		t.Errorf("filtered entries should keep their source lines, got %+v", filtered)
+	}
+}
+
+func TestReadSession_RawLine(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.jsonl")
+	line := `{"uuid":"1","type":"user","unmodeled":true,"message":"Hello"}`
+	mustWriteFile(t, testFile, []byte(line+"\n"))
+
+	entries, err := ReadSession(testFile)
+	if err != nil {
+		t.Fatalf("ReadSession() error: %v", err)
+	}
+	if len(entries) != 1 || string(entries[0].RawLine) != line {
+		t.Fatalf("RawLine = %q, want the full line %q", entries[0].RawLine, line)
+	}
+}
+
+func TestReadSession_BOMAndBlankLines(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.jsonl")
+	content := "\xEF\xBB\xBF" + `{"uuid":"1","type":"user","message":"Hello"}` + "\n   \n\t\r\n" +
+		`{"uuid":"2","type":"assistant","message":"Hi"}  ` + "\r\n\n"
+	mustWriteFile(t, testFile, []byte(content))
+
+	entries, err := ReadSession(testFile)
+	if err != nil {
+		t.Fatalf("ReadSession() error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("ReadSession() returned %d entries, want 2 (no phantom entries for blank lines)", len(entries))
+	}
+	if entries[0].Type != models.EntryTypeUser || entries[0].UUID != "1" || entries[0].SourceLine != 1 {
+		t.Errorf("first entry = type %q, uuid %q, line %d; the BOM should not affect it", entries[0].Type, entries[0].UUID, entries[0].SourceLine)
+	}
+	if entries[1].SourceLine != 4 {
+		t.Errorf("second entry SourceLine = %d, want 4", entries[1].SourceLine)
+	}
+
+	// A file of only blank lines has no entries and is not an error
+	blankFile := filepath.Join(t.TempDir(), "blank.jsonl")
+	mustWriteFile(t, blankFile, []byte("\n  \n\t\n\r\n"))
+	entries, err = ReadSession(blankFile)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("ReadSession(blank file) = %d entries, %v, want 0, nil", len(entries), err)
+	}
+}
+
 func TestGetSessionInfo(t *testing.T) {
 	tmpDir := t.TempDir()
 	testFile := filepath.Join(tmpDir, "679761ba-80c0-4cd3-a586-cc6a1fc56308.jsonl")
@@ -69,6 +148,185 @@ func TestGetSessionInfo(t *testing.T) {
 	}
 }
 
+func TestGetSessionInfo_CwdAndBranch(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "session.jsonl")
+	// The summary line records neither field; later entries change directory
+	content := `{"type":"summary","summary":"Weather app"}
+{"uuid":"1","type":"user","timestamp":"2026-02-01T18:00:00.000Z","cwd":"/work/app","message":"Hi"}
+{"uuid":"2","type":"assistant","timestamp":"2026-02-01T18:00:05.000Z","cwd":"/work/app","gitBranch":"main"}
+{"uuid":"3","type":"user","timestamp":"2026-02-01T18:01:00.000Z","cwd":"/work/app/web","gitBranch":"feature/x"}
+`
+	mustWriteFile(t, testFile, []byte(content))
+
+	session, err := GetSessionInfo(testFile)
+	if err != nil {
+		t.Fatalf("GetSessionInfo() error: %v", err)
+	}
+	if session.Cwd != "/work/app" {
+		t.Errorf("Cwd = %q, want /work/app (first recorded)", session.Cwd)
+	}
+	if session.GitBranch != "main" {
+		t.Errorf("GitBranch = %q, want main (first recorded)", session.GitBranch)
+	}
+
+	mustWriteFile(t, testFile, []byte(`{"uuid":"1","type":"user","timestamp":"2026-02-01T18:00:00.000Z","message":"Hi"}`+"\n"))
+	session, err = GetSessionInfo(testFile)
+	if err != nil {
+		t.Fatalf("GetSessionInfo() error: %v", err)
+	}
+	if session.Cwd != "" || session.GitBranch != "" {
+		t.Errorf("Cwd, GitBranch = %q, %q; want empty when not recorded", session.Cwd, session.GitBranch)
+	}
+}
+
+func TestGetSessionInfo_FirstPromptForms(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "string content",
+			content: `{"uuid":"1","type":"user","message":{"role":"user","content":"Fix the build"}}`,
+			want:    "Fix the build",
+		},
+		{
+			name:    "block content",
+			content: `{"uuid":"1","type":"user","message":{"role":"user","content":[{"type":"text","text":"Fix the build"},{"type":"text","text":"and the tests"}]}}`,
+			want:    "Fix the build\nand the tests",
+		},
+		{
+			name: "skips tool results",
+			content: `{"uuid":"1","type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}
+{"uuid":"2","type":"user","message":{"role":"user","content":[{"type":"image","source":{}},{"type":"text","text":"What is this?"}]}}`,
+			want: "What is this?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testFile := filepath.Join(t.TempDir(), "s.jsonl")
+			mustWriteFile(t, testFile, []byte(tt.content+"\n"))
+
+			session, err := GetSessionInfo(testFile)
+			if err != nil {
+				t.Fatalf("GetSessionInfo() error: %v", err)
+			}
+			if session.FirstPrompt != tt.want {
+				t.Errorf("FirstPrompt = %q, want %q", session.FirstPrompt, tt.want)
+			}
+
+			// FirstPrompt finds the same prompt in the entries
+			entries, err := ReadSession(testFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := FirstPrompt(entries); got != tt.want {
+				t.Errorf("FirstPrompt(entries) = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := FirstPrompt(nil); got != "" {
+		t.Errorf("FirstPrompt(nil) = %q, want empty", got)
+	}
+}
+
+func TestGetSessionInfoWith_PromptLength(t *testing.T) {
+	long := strings.Repeat("é", 150) // 300 bytes
+	testFile := filepath.Join(t.TempDir(), "s.jsonl")
+	mustWriteFile(t, testFile, []byte(`{"uuid":"1","type":"user","message":"`+long+`"}`+"\n"))
+
+	full, err := GetSessionInfoWith(testFile, 0)
+	if err != nil {
+		t.Fatalf("GetSessionInfoWith() error: %v", err)
+	}
+	if full.FirstPrompt != long {
+		t.Errorf("maxPromptLen 0 should keep the full prompt, got %d bytes", len(full.FirstPrompt))
+	}
+
+	short, err := GetSessionInfo(testFile)
+	if err != nil {
+		t.Fatalf("GetSessionInfo() error: %v", err)
+	}
+	if want := strings.Repeat("é", 100) + "..."; short.FirstPrompt != want {
+		t.Errorf("FirstPrompt = %q, want %q", short.FirstPrompt, want)
+	}
+
+	odd, err := GetSessionInfoWith(testFile, 5)
+	if err != nil {
+		t.Fatalf("GetSessionInfoWith() error: %v", err)
+	}
+	if !utf8.ValidString(odd.FirstPrompt) || odd.FirstPrompt != "éé..." {
+		t.Errorf("FirstPrompt = %q, want truncation on a character boundary", odd.FirstPrompt)
+	}
+}
+
+func TestLatestSession(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)
+	sessions := []struct {
+		id       string
+		modified time.Time
+	}{
+		// Created first but still being written, so it is the most recently modified
+		{"11111111-1111-1111-1111-111111111111", base.Add(2 * time.Hour)},
+		{"22222222-2222-2222-2222-222222222222", base.Add(time.Hour)},
+		{"33333333-3333-3333-3333-333333333333", base},
+	}
+	for _, s := range sessions {
+		path := filepath.Join(dir, s.id+".jsonl")
+		mustWriteFile(t, path, []byte(`{"type":"user","message":"hi"}`+"\n"))
+		if err := os.Chtimes(path, s.modified, s.modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Not a session file, however new
+	mustWriteFile(t, filepath.Join(dir, "notes.jsonl"), []byte("{}\n"))
+
+	got, err := LatestSession(dir)
+	if err != nil {
+		t.Fatalf("LatestSession() error = %v", err)
+	}
+	if got != sessions[0].id {
+		t.Errorf("LatestSession() = %s, want %s", got, sessions[0].id)
+	}
+}
+
+func TestLatestSession_TieBrokenByID(t *testing.T) {
+	dir := t.TempDir()
+	modified := time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)
+	for _, id := range []string{
+		"cccccccc-0000-0000-0000-000000000000",
+		"aaaaaaaa-0000-0000-0000-000000000000",
+		"bbbbbbbb-0000-0000-0000-000000000000",
+	} {
+		path := filepath.Join(dir, id+".jsonl")
+		mustWriteFile(t, path, []byte("{}\n"))
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 5; i++ {
+		got, err := LatestSession(dir)
+		if err != nil {
+			t.Fatalf("LatestSession() error = %v", err)
+		}
+		if got != "aaaaaaaa-0000-0000-0000-000000000000" {
+			t.Fatalf("LatestSession() = %s, want the lowest ID on a tie", got)
+		}
+	}
+}
+
+func TestLatestSession_Empty(t *testing.T) {
+	dir := t.TempDir()
+	_, err := LatestSession(dir)
+	if err == nil || !strings.Contains(err.Error(), "no sessions found") {
+		t.Errorf("LatestSession() error = %v, want no sessions error", err)
+	}
+}
+
 func TestFilterEntries(t *testing.T) {
 	entries := []models.ConversationEntry{
 		{UUID: "1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00.000Z"},
@@ -122,6 +380,26 @@ func TestCountEntriesByType(t *testing.T) {
 	}
 }
 
+func TestCountToolCalls(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{}},{"type":"tool_use","id":"t2","name":"Read","input":{}}]}`)},
+		{Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t3","name":"Bash","input":{}}]}`)},
+		{Type: models.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":"no tools"}`)},
+	}
+
+	counts := CountToolCalls(entries)
+
+	if counts["Bash"] != 2 {
+		t.Errorf("Bash count = %d, want 2", counts["Bash"])
+	}
+	if counts["Read"] != 1 {
+		t.Errorf("Read count = %d, want 1", counts["Read"])
+	}
+	if len(counts) != 2 {
+		t.Errorf("got %d tools, want 2: %v", len(counts), counts)
+	}
+}
+
 func TestReadSessionIndex(t *testing.T) {
 	tmpDir := t.TempDir()
 	indexFile := filepath.Join(tmpDir, "sessions-index.json")
@@ -802,3 +1080,376 @@ func TestFilterEntries_ToolTypeAndMatch_WithAllFilters(t *testing.T) {
 
 // Verify the json import is used
 var _ = json.Marshal
+
+// makeToolEntries builds an assistant entry with one tool call and the user entry carrying its result.
+func makeToolEntries(uuid, toolID, toolName, text string, isError bool) []models.ConversationEntry {
+	content := []map[string]any{}
+	if text != "" {
+		content = append(content, map[string]any{"type": "text", "text": text})
+	}
+	content = append(content, map[string]any{"type": "tool_use", "id": toolID, "name": toolName, "input": map[string]any{}})
+	assistantMsg, _ := json.Marshal(map[string]any{"role": "assistant", "content": content})
+	resultMsg, _ := json.Marshal(map[string]any{"role": "user", "content": []map[string]any{
+		{"type": "tool_result", "tool_use_id": toolID, "content": "output", "is_error": isError},
+	}})
+
+	return []models.ConversationEntry{
+		{UUID: uuid, Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:00.000Z", Message: assistantMsg},
+		{UUID: uuid + "-result", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:01.000Z", Message: resultMsg},
+	}
+}
+
+func TestFilterEntries_ToolErrorsOnly(t *testing.T) {
+	var entries []models.ConversationEntry
+	entries = append(entries, makeToolEntries("bash-fail", "toolu_a", "Bash", "", true)...)
+	entries = append(entries, makeToolEntries("bash-ok", "toolu_b", "Bash", "Running the build", false)...)
+	entries = append(entries, makeToolEntries("read-fail", "toolu_c", "Read", "", true)...)
+	entries = append(entries, makeToolEntries("text-ok", "toolu_d", "Grep", "Lots of explanatory text", false)...)
+	entries = append(entries, models.ConversationEntry{
+		UUID: "no-tools", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:00.000Z",
+		Message: json.RawMessage(`{"role":"assistant","content":"Just text"}`),
+	})
+
+	tests := []struct {
+		name      string
+		opts      FilterOptions
+		wantUUIDs []string
+	}{
+		{
+			name:      "errors only",
+			opts:      FilterOptions{ToolErrorsOnly: true},
+			wantUUIDs: []string{"bash-fail", "read-fail"},
+		},
+		{
+			name:      "composes with tool types",
+			opts:      FilterOptions{ToolErrorsOnly: true, ToolTypes: []string{"bash"}},
+			wantUUIDs: []string{"bash-fail"},
+		},
+		{
+			name:      "tool type without errors",
+			opts:      FilterOptions{ToolErrorsOnly: true, ToolTypes: []string{"Grep"}},
+			wantUUIDs: nil,
+		},
+		{
+			name:      "disabled keeps everything",
+			opts:      FilterOptions{},
+			wantUUIDs: []string{"bash-fail", "bash-fail-result", "bash-ok", "bash-ok-result", "read-fail", "read-fail-result", "text-ok", "text-ok-result", "no-tools"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FilterEntries(entries, tt.opts)
+			var got []string
+			for _, e := range result {
+				got = append(got, e.UUID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantUUIDs, ",") {
+				t.Errorf("FilterEntries() = %v, want %v", got, tt.wantUUIDs)
+			}
+		})
+	}
+}
+
+func TestFilterEntries_ToolErrorsOnly_MixedResults(t *testing.T) {
+	// One assistant entry with a failing Read and a succeeding Bash
+	assistantMsg := json.RawMessage(`{"role":"assistant","content":[
+		{"type":"tool_use","id":"toolu_1","name":"Bash","input":{}},
+		{"type":"tool_use","id":"toolu_2","name":"Read","input":{}}]}`)
+	resultMsg := json.RawMessage(`{"role":"user","content":[
+		{"type":"tool_result","tool_use_id":"toolu_1","content":"ok"},
+		{"type":"tool_result","tool_use_id":"toolu_2","content":"missing","is_error":true}]}`)
+	entries := []models.ConversationEntry{
+		{UUID: "a", Type: models.EntryTypeAssistant, Message: assistantMsg},
+		{UUID: "u", Type: models.EntryTypeUser, Message: resultMsg},
+	}
+
+	if got := FilterEntries(entries, FilterOptions{ToolErrorsOnly: true}); len(got) != 1 || got[0].UUID != "a" {
+		t.Errorf("expected entry with an errored tool, got %v", got)
+	}
+	if got := FilterEntries(entries, FilterOptions{ToolErrorsOnly: true, ToolTypes: []string{"Bash"}}); len(got) != 0 {
+		t.Errorf("Bash succeeded, so no entries expected, got %d", len(got))
+	}
+	if got := FilterEntries(entries, FilterOptions{ToolErrorsOnly: true, ToolTypes: []string{"Read"}}); len(got) != 1 {
+		t.Errorf("Read failed, so one entry expected, got %d", len(got))
+	}
+}
+
+func TestFilterEntriesWithResults_ExternalResults(t *testing.T) {
+	// Results may come from outside the filtered slice (e.g. a different page of entries)
+	entries := makeToolEntries("a", "toolu_x", "Bash", "", false)[:1]
+	results := map[string]models.ToolResult{"toolu_x": {ToolUseID: "toolu_x", IsError: true}}
+
+	if got := FilterEntriesWithResults(entries, results, FilterOptions{ToolErrorsOnly: true}); len(got) != 1 {
+		t.Errorf("expected entry to match using provided results, got %d", len(got))
+	}
+	if got := FilterEntries(entries, FilterOptions{ToolErrorsOnly: true}); len(got) != 0 {
+		t.Errorf("without a result the entry should be excluded, got %d", len(got))
+	}
+}
+
+func TestFilterEntries_ToolFieldMatch(t *testing.T) {
+	gitPush := makeAssistantWithTools("1", struct{ name, input string }{"Bash", `{"command":"git push","description":"Push"}`})
+	echoGit := makeAssistantWithTools("2", struct{ name, input string }{"Bash", `{"command":"echo done","description":"mentions git"}`})
+	readGo := makeAssistantWithTools("3", struct{ name, input string }{"Read", `{"file_path":"/src/main.go","options":{"limit":50}}`})
+	nested := makeAssistantWithTools("4", struct{ name, input string }{"Task", `{"config":{"model":"haiku"},"prompt":"git things"}`})
+	mixed := makeAssistantWithTools("5",
+		struct{ name, input string }{"Bash", `{"command":"git log"}`},
+		struct{ name, input string }{"Read", `{"file_path":"/src/git.go"}`})
+	for _, e := range []*models.ConversationEntry{&gitPush, &echoGit, &readGo, &nested, &mixed} {
+		e.Type = models.EntryTypeAssistant
+	}
+	entries := []models.ConversationEntry{gitPush, echoGit, readGo, nested, mixed}
+
+	tests := []struct {
+		name string
+		opts FilterOptions
+		want []string
+	}{
+		{
+			name: "single field",
+			opts: FilterOptions{ToolFieldMatch: map[string]string{"command": "^git"}},
+			want: []string{"1", "5"},
+		},
+		{
+			name: "only the named field is matched",
+			opts: FilterOptions{ToolFieldMatch: map[string]string{"command": "git"}},
+			want: []string{"1", "5"}, // entry 2 mentions git only in its description
+		},
+		{
+			name: "nested field",
+			opts: FilterOptions{ToolFieldMatch: map[string]string{"config.model": "^haiku$"}},
+			want: []string{"4"},
+		},
+		{
+			name: "non-string field",
+			opts: FilterOptions{ToolFieldMatch: map[string]string{"options.limit": "^50$"}},
+			want: []string{"3"},
+		},
+		{
+			name: "missing field never matches",
+			opts: FilterOptions{ToolFieldMatch: map[string]string{"config.missing": ".*"}},
+			want: nil,
+		},
+		{
+			name: "all fields must match in one call",
+			opts: FilterOptions{ToolFieldMatch: map[string]string{"command": "git", "file_path": "git"}},
+			want: nil, // entry 5 matches each field, but in different calls
+		},
+		{
+			name: "several fields of one call",
+			opts: FilterOptions{ToolFieldMatch: map[string]string{"command": "git", "description": "^Push$"}},
+			want: []string{"1"},
+		},
+		{
+			name: "limited to ToolTypes",
+			opts: FilterOptions{ToolTypes: []string{"read"}, ToolFieldMatch: map[string]string{"file_path": "git"}},
+			want: []string{"5"},
+		},
+		{
+			name: "ToolTypes excludes the matching call",
+			opts: FilterOptions{ToolTypes: []string{"bash"}, ToolFieldMatch: map[string]string{"file_path": `\.go$`}},
+			want: nil,
+		},
+		{
+			name: "composes with ToolMatch",
+			opts: FilterOptions{ToolMatch: "Push", ToolFieldMatch: map[string]string{"command": "git"}},
+			want: []string{"1"},
+		},
+		{
+			name: "invalid regex matches nothing",
+			opts: FilterOptions{ToolFieldMatch: map[string]string{"command": "("}},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range FilterEntries(entries, tt.opts) {
+				got = append(got, e.UUID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("FilterEntries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterEntries_SpawnsOnly(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "prompt", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00.000Z", Message: json.RawMessage(`"Explore the repo"`)},
+		{UUID: "modern", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:01.000Z",
+			ToolUseResult: &models.ToolUseResult{Status: "async_launched", AgentID: "a12eb64"}},
+		{UUID: "legacy", Type: models.EntryTypeQueueOperation, Timestamp: "2026-02-01T10:00:02.000Z", AgentID: "a12eb64"},
+		{UUID: "agent-msg", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:03.000Z", AgentID: "a12eb64",
+			Message: json.RawMessage(`{"role":"assistant","content":"Working"}`)},
+		{UUID: "done", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:04.000Z",
+			ToolUseResult: &models.ToolUseResult{Status: "completed", AgentID: "a12eb64"}},
+	}
+
+	tests := []struct {
+		name      string
+		opts      FilterOptions
+		wantUUIDs []string
+	}{
+		{"both formats", FilterOptions{SpawnsOnly: true}, []string{"modern", "legacy"}},
+		{"composes with type", FilterOptions{SpawnsOnly: true, Types: []models.EntryType{models.EntryTypeQueueOperation}}, []string{"legacy"}},
+		{"disabled", FilterOptions{}, []string{"prompt", "modern", "legacy", "agent-msg", "done"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range FilterEntries(entries, tt.opts) {
+				got = append(got, e.UUID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantUUIDs, ",") {
+				t.Errorf("FilterEntries() = %v, want %v", got, tt.wantUUIDs)
+			}
+		})
+	}
+}
+
+func TestFilterEntries_CwdAndBranch(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "root", Type: models.EntryTypeUser, Cwd: "/work/app", GitBranch: "main"},
+		{UUID: "sub", Type: models.EntryTypeUser, Cwd: "/work/app/web", GitBranch: "feature/x"},
+		{UUID: "sibling", Type: models.EntryTypeUser, Cwd: "/work/app-old", GitBranch: "main"},
+		{UUID: "no-branch", Type: models.EntryTypeUser, Cwd: "/work/app"},
+		{UUID: "bare", Type: models.EntryTypeSummary},
+	}
+
+	tests := []struct {
+		name      string
+		opts      FilterOptions
+		wantUUIDs []string
+	}{
+		{"cwd includes subdirectories", FilterOptions{Cwd: "/work/app"}, []string{"root", "sub", "no-branch"}},
+		{"cwd trailing slash", FilterOptions{Cwd: "/work/app/"}, []string{"root", "sub", "no-branch"}},
+		{"cwd subdirectory only", FilterOptions{Cwd: "/work/app/web"}, []string{"sub"}},
+		{"cwd root", FilterOptions{Cwd: "/"}, []string{"root", "sub", "sibling", "no-branch"}},
+		{"branch", FilterOptions{GitBranch: "main"}, []string{"root", "sibling"}},
+		{"branch and cwd", FilterOptions{GitBranch: "main", Cwd: "/work/app"}, []string{"root"}},
+		{"branch matches exactly", FilterOptions{GitBranch: "feature"}, nil},
+		{"disabled", FilterOptions{}, []string{"root", "sub", "sibling", "no-branch", "bare"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range FilterEntries(entries, tt.opts) {
+				got = append(got, e.UUID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantUUIDs, ",") {
+				t.Errorf("FilterEntries() = %v, want %v", got, tt.wantUUIDs)
+			}
+		})
+	}
+}
+
+func TestFilterEntries_Turns(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, GitBranch: "main", Message: json.RawMessage(`"First prompt"`)},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[{"type":"text","text":"Reading"},{"type":"tool_use","id":"t1","name":"Read","input":{}}]}`)},
+		{UUID: "r1", Type: models.EntryTypeUser, Message: json.RawMessage(`[{"type":"tool_result","tool_use_id":"t1","content":"file"}]`)},
+		{UUID: "tool-only", Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Bash","input":{}}]}`)},
+		{UUID: "sys", Type: models.EntryTypeSystem, Message: json.RawMessage(`"note"`)},
+		{UUID: "a2", Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":"Done"}`)},
+		{UUID: "u2", Type: models.EntryTypeUser, GitBranch: "feature/x", Message: json.RawMessage(`[{"type":"text","text":"Second prompt"}]`)},
+	}
+
+	tests := []struct {
+		name      string
+		opts      FilterOptions
+		wantUUIDs []string
+	}{
+		{"user turn skips tool results", FilterOptions{UserTurn: 2}, []string{"u2"}},
+		{"assistant turn skips tool-call-only entries", FilterOptions{AssistantTurn: 2}, []string{"a2"}},
+		{"both turns", FilterOptions{UserTurn: 1, AssistantTurn: 1}, []string{"u1", "a1"}},
+		{"out of range", FilterOptions{UserTurn: 3}, nil},
+		{"counted before other filters", FilterOptions{UserTurn: 2, GitBranch: "feature/x"}, []string{"u2"}},
+		{"other filters still apply", FilterOptions{UserTurn: 1, GitBranch: "feature/x"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range FilterEntries(entries, tt.opts) {
+				got = append(got, e.UUID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantUUIDs, ",") {
+				t.Errorf("FilterEntries() = %v, want %v", got, tt.wantUUIDs)
+			}
+		})
+	}
+}
+
+func TestFilterEntries_RelativeToStart(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "no-time", Type: models.EntryTypeSystem},
+		{UUID: "0m", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00.000Z"},
+		{UUID: "3m", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:03:00.000Z"},
+		{UUID: "5m", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:05:00.000Z"},
+		{UUID: "20m", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:20:00.000Z"},
+	}
+	dur := func(s string) *time.Duration {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &d
+	}
+	at := func(s string) *time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &ts
+	}
+
+	tests := []struct {
+		name      string
+		opts      FilterOptions
+		wantUUIDs []string
+	}{
+		{"until start", FilterOptions{UntilStart: dur("5m")}, []string{"0m", "3m", "5m"}},
+		{"since start", FilterOptions{SinceStart: dur("4m")}, []string{"5m", "20m"}},
+		{"window", FilterOptions{SinceStart: dur("1m"), UntilStart: dur("10m")}, []string{"3m", "5m"}},
+		{"composes with absolute times", FilterOptions{UntilStart: dur("10m"), StartTime: at("2026-02-01T10:04:00Z")}, []string{"5m"}},
+		{"explicit session start", FilterOptions{SinceStart: dur("2m"), SessionStart: *at("2026-02-01T10:04:00Z")}, []string{"20m"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range FilterEntries(entries, tt.opts) {
+				got = append(got, e.UUID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantUUIDs, ",") {
+				t.Errorf("FilterEntries() = %v, want %v", got, tt.wantUUIDs)
+			}
+		})
+	}
+
+	t.Run("no timestamps", func(t *testing.T) {
+		untimed := []models.ConversationEntry{{UUID: "a"}, {UUID: "b", Timestamp: "not a time"}}
+		if got := FilterEntries(untimed, FilterOptions{UntilStart: dur("5m")}); len(got) != 2 {
+			t.Errorf("FilterEntries() kept %d entries, want 2", len(got))
+		}
+	})
+}
+
+func TestSessionStartTime(t *testing.T) {
+	if _, ok := SessionStartTime([]models.ConversationEntry{{Timestamp: "bad"}}); ok {
+		t.Error("SessionStartTime() ok = true without a parseable timestamp")
+	}
+	start, ok := SessionStartTime([]models.ConversationEntry{
+		{Timestamp: ""},
+		{Timestamp: "2026-02-01T10:05:00.000Z"},
+		{Timestamp: "2026-02-01T10:00:00.000Z"},
+	})
+	if !ok || !start.Equal(time.Date(2026, 2, 1, 10, 5, 0, 0, time.UTC)) {
+		t.Errorf("SessionStartTime() = %v, %v, want the first timestamp in file order", start, ok)
+	}
+}
@@ -0,0 +1,97 @@
+package session
+
+import (
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// OrderByThread returns entries reordered so that a reply follows the entry it answers
+// (its parentUuid), for sessions whose file order puts a reply first. Only the parent
+// links the timestamps cannot settle are followed: a reply whose timestamp is known and
+// earlier than its parent's keeps its file position. A reply moved for its parent is
+// placed right after it, with replies to the same parent in file order. Entries without
+// a parent, or whose parent is not in entries, keep their position relative to each
+// other. Parent links that form a cycle are cut at the entry of the cycle that comes
+// first in the file, which is then treated as having no parent.
+//
+// The result has the same entries as entries, which is left unchanged.
+func OrderByThread(entries []models.ConversationEntry) []models.ConversationEntry {
+	index := make(map[string]int, len(entries))
+	for i := range entries {
+		if entries[i].UUID != "" {
+			if _, dup := index[entries[i].UUID]; !dup {
+				index[entries[i].UUID] = i
+			}
+		}
+	}
+
+	// waitsFor[i] is the parent entry i must follow, or -1
+	waitsFor := make([]int, len(entries))
+	for i := range entries {
+		waitsFor[i] = -1
+		if entries[i].ParentUUID == nil {
+			continue
+		}
+		parent, ok := index[*entries[i].ParentUUID]
+		if !ok || parent == i || !threadTimesAllow(entries[parent], entries[i]) {
+			continue
+		}
+		waitsFor[i] = parent
+	}
+
+	// Replies reached before their parent, waiting for it in file order
+	waiting := make(map[int][]int)
+
+	ordered := make([]models.ConversationEntry, 0, len(entries))
+	emitted := make([]bool, len(entries))
+	emit := func(i int) {
+		stack := []int{i}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if emitted[n] {
+				continue
+			}
+			emitted[n] = true
+			ordered = append(ordered, entries[n])
+			// Push in reverse so the replies come out in file order
+			replies := waiting[n]
+			for j := len(replies) - 1; j >= 0; j-- {
+				stack = append(stack, replies[j])
+			}
+		}
+	}
+
+	for i := range entries {
+		if emitted[i] {
+			continue
+		}
+		if parent := waitsFor[i]; parent >= 0 && !emitted[parent] {
+			waiting[parent] = append(waiting[parent], i)
+			continue
+		}
+		emit(i)
+	}
+	// Entries left are on a cycle of parent links (or wait for one); cutting the cycle at
+	// its first entry releases the rest
+	for i := range entries {
+		if !emitted[i] {
+			emit(i)
+		}
+	}
+	return ordered
+}
+
+// threadTimesAllow reports whether reply may be placed after parent: unless both
+// timestamps are known and the reply's is earlier, the timestamps do not contradict
+// the parent link.
+func threadTimesAllow(parent, reply models.ConversationEntry) bool {
+	parentTime, err := parent.GetTimestamp()
+	if err != nil || parentTime.IsZero() {
+		return true
+	}
+	replyTime, err := reply.GetTimestamp()
+	if err != nil || replyTime.IsZero() {
+		return true
+	}
+	return !replyTime.Before(parentTime)
+}
@@ -0,0 +1,101 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// threadEntry returns an entry with the given UUID, parent ("" for none), and timestamp.
+func threadEntry(uuid, parent, timestamp string) models.ConversationEntry {
+	e := models.ConversationEntry{UUID: uuid, Type: models.EntryTypeUser, Timestamp: timestamp}
+	if parent != "" {
+		e.ParentUUID = &parent
+	}
+	return e
+}
+
+func threadUUIDs(entries []models.ConversationEntry) []string {
+	uuids := make([]string, len(entries))
+	for i := range entries {
+		uuids[i] = entries[i].UUID
+	}
+	return uuids
+}
+
+func TestOrderByThread(t *testing.T) {
+	const ts = "2026-02-01T10:00:00Z"
+	tests := []struct {
+		name    string
+		entries []models.ConversationEntry
+		want    []string
+	}{
+		{
+			name: "file order already threaded",
+			entries: []models.ConversationEntry{
+				threadEntry("a", "", ts), threadEntry("x", "", ts), threadEntry("b", "a", ts),
+			},
+			want: []string{"a", "x", "b"},
+		},
+		{
+			name: "reply before its parent with tied timestamps",
+			entries: []models.ConversationEntry{
+				threadEntry("b", "a", ts), threadEntry("x", "", ts), threadEntry("a", "", ts), threadEntry("y", "", ts),
+			},
+			want: []string{"x", "a", "b", "y"},
+		},
+		{
+			name: "missing timestamps and a chain",
+			entries: []models.ConversationEntry{
+				threadEntry("c", "b", ""), threadEntry("b", "a", ""), threadEntry("a", "", ""),
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "replies to one parent keep file order",
+			entries: []models.ConversationEntry{
+				threadEntry("b2", "a", ts), threadEntry("b1", "a", ts), threadEntry("a", "", ts),
+			},
+			want: []string{"a", "b2", "b1"},
+		},
+		{
+			name: "timestamps settle the order",
+			entries: []models.ConversationEntry{
+				threadEntry("b", "a", "2026-02-01T09:00:00Z"), threadEntry("a", "", ts),
+			},
+			want: []string{"b", "a"},
+		},
+		{
+			name: "parent not in the entries",
+			entries: []models.ConversationEntry{
+				threadEntry("b", "gone", ts), threadEntry("a", "", ts),
+			},
+			want: []string{"b", "a"},
+		},
+		{
+			name: "cycle is cut at its first entry",
+			entries: []models.ConversationEntry{
+				threadEntry("x", "", ts), threadEntry("b", "a", ts), threadEntry("a", "b", ts), threadEntry("c", "a", ts),
+			},
+			want: []string{"x", "b", "a", "c"},
+		},
+		{
+			name:    "self parent",
+			entries: []models.ConversationEntry{threadEntry("a", "a", ts)},
+			want:    []string{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := threadUUIDs(tt.entries)
+			got := threadUUIDs(OrderByThread(tt.entries))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OrderByThread() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(threadUUIDs(tt.entries), original) {
+				t.Error("OrderByThread() should not modify its input")
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package session
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+// toolInputSummaryFields are the input fields that best describe a tool call, in order
+// of preference: the command a shell ran, the file a file tool touched, and so on.
+var toolInputSummaryFields = []string{"command", "file_path", "path", "notebook_path", "pattern", "url", "query", "description", "prompt"}
+
+// ToolError is a tool call whose result was an error, with the call it answered.
+type ToolError struct {
+	EntryUUID    string         // UUID of the assistant entry that made the call ("" if not found)
+	ResultUUID   string         // UUID of the user entry carrying the error result
+	Timestamp    string         // Timestamp of the call's entry, or of the result's without one
+	ToolUseID    string         // ID of the tool call
+	ToolName     string         // Name of the tool ("" if the call was not found)
+	Input        map[string]any // Input of the call (nil if the call was not found)
+	InputSummary string         // The input field that best describes the call (see ToolInputSummary)
+	Error        string         // Text of the error result
+}
+
+// CollectToolErrors returns every tool result in entries marked as an error, in order,
+// each paired with the tool call it answered by tool use ID. Errors whose call is not in
+// entries (e.g. lost to compaction) are still returned, without the call's details.
+func CollectToolErrors(entries []models.ConversationEntry) []ToolError {
+	type call struct {
+		tool  models.ToolUse
+		entry *models.ConversationEntry
+	}
+	calls := make(map[string]call)
+	for i := range entries {
+		for _, tool := range entries[i].ExtractToolCalls() {
+			calls[tool.ID] = call{tool: tool, entry: &entries[i]}
+		}
+	}
+
+	var toolErrors []ToolError
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Type != models.EntryTypeUser {
+			continue
+		}
+		for _, result := range entry.ExtractToolResults() {
+			if !result.IsError {
+				continue
+			}
+			toolError := ToolError{
+				ResultUUID: entry.UUID,
+				Timestamp:  entry.Timestamp,
+				ToolUseID:  result.ToolUseID,
+				Error:      result.Content,
+			}
+			if c, ok := calls[result.ToolUseID]; ok {
+				toolError.EntryUUID = c.entry.UUID
+				if c.entry.Timestamp != "" {
+					toolError.Timestamp = c.entry.Timestamp
+				}
+				toolError.ToolName = c.tool.Name
+				toolError.Input = c.tool.Input
+				toolError.InputSummary = ToolInputSummary(c.tool)
+			}
+			toolErrors = append(toolErrors, toolError)
+		}
+	}
+	return toolErrors
+}
+
+// ToolInputSummary returns the input of a tool call that best describes it: the first
+// of toolInputSummaryFields it has, such as a Bash command or a Read file path, or else
+// all of its input as compact JSON. Whitespace, including newlines, is collapsed.
+func ToolInputSummary(tool models.ToolUse) string {
+	for _, field := range toolInputSummaryFields {
+		if value, ok := tool.Input[field].(string); ok && strings.TrimSpace(value) != "" {
+			return strings.Join(strings.Fields(value), " ")
+		}
+	}
+	if len(tool.Input) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(tool.Input)
+	if err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(string(data)), " ")
+}
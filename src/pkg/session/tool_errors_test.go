@@ -0,0 +1,75 @@
+package session
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestCollectToolErrors(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "u1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:00Z", Message: json.RawMessage(`"fix the build"`)},
+		{UUID: "a1", Type: models.EntryTypeAssistant, Timestamp: "2026-02-01T10:00:05Z",
+			Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"make\nbuild"}},{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"/a.go"}}]}`)},
+		{UUID: "r1", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:00:06Z",
+			Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"make: *** No rule","is_error":true},{"type":"tool_result","tool_use_id":"t2","content":"package a"}]}`)},
+		{UUID: "r2", Type: models.EntryTypeUser, Timestamp: "2026-02-01T10:01:00Z",
+			Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"gone","content":"denied","is_error":true}]}`)},
+	}
+
+	got := CollectToolErrors(entries)
+	want := []ToolError{
+		{
+			EntryUUID:    "a1",
+			ResultUUID:   "r1",
+			Timestamp:    "2026-02-01T10:00:05Z",
+			ToolUseID:    "t1",
+			ToolName:     "Bash",
+			Input:        map[string]any{"command": "make\nbuild"},
+			InputSummary: "make build",
+			Error:        "make: *** No rule",
+		},
+		{
+			ResultUUID: "r2",
+			Timestamp:  "2026-02-01T10:01:00Z",
+			ToolUseID:  "gone",
+			Error:      "denied",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CollectToolErrors() = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestCollectToolErrors_None(t *testing.T) {
+	entries := []models.ConversationEntry{
+		{UUID: "a1", Type: models.EntryTypeAssistant, Message: json.RawMessage(`{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls"}}]}`)},
+		{UUID: "r1", Type: models.EntryTypeUser, Message: json.RawMessage(`{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"a.go"}]}`)},
+	}
+	if got := CollectToolErrors(entries); len(got) != 0 {
+		t.Errorf("CollectToolErrors() = %+v, want none", got)
+	}
+}
+
+func TestToolInputSummary(t *testing.T) {
+	tests := []struct {
+		name string
+		tool models.ToolUse
+		want string
+	}{
+		{"command", models.ToolUse{Name: "Bash", Input: map[string]any{"command": "go  test\n./...", "description": "Run tests"}}, "go test ./..."},
+		{"file path", models.ToolUse{Name: "Edit", Input: map[string]any{"file_path": "/a.go", "old_string": "x"}}, "/a.go"},
+		{"pattern", models.ToolUse{Name: "Grep", Input: map[string]any{"pattern": "TODO", "path": ""}}, "TODO"},
+		{"fallback json", models.ToolUse{Name: "mcp__x", Input: map[string]any{"b": 2, "a": "one"}}, `{"a":"one","b":2}`},
+		{"no input", models.ToolUse{Name: "ExitPlanMode"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToolInputSummary(tt.tool); got != tt.want {
+				t.Errorf("ToolInputSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package session
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+	"github.com/randlee/claude-history/pkg/paths"
+)
+
+// UsageReport is a project's tool usage across all of its sessions, subagents
+// included (see ProjectUsage).
+type UsageReport struct {
+	Sessions  int         `json:"sessions"`  // Sessions read
+	ToolCalls int         `json:"toolCalls"` // Tool calls of every tool
+	Tools     []ToolUsage `json:"tools"`     // Calls per tool, most called first
+	Files     []FileUsage `json:"files"`     // Edited files, most edited first
+	Days      []DayUsage  `json:"days"`      // Days with entries, busiest first
+
+	// ReadErrors holds a *SessionReadError for each session, or subagent file of one,
+	// that could not be read; the report covers the rest.
+	ReadErrors []error `json:"-"`
+}
+
+// ToolUsage is the number of calls of one tool.
+type ToolUsage struct {
+	Name  string `json:"name"`
+	Calls int    `json:"calls"`
+}
+
+// FileUsage is the number of tool calls that changed one file (see EditedFilePath).
+type FileUsage struct {
+	Path  string `json:"path"`
+	Edits int    `json:"edits"`
+}
+
+// DayUsage is the activity of one local calendar day.
+type DayUsage struct {
+	Date      string `json:"date"` // YYYY-MM-DD
+	Entries   int    `json:"entries"`
+	ToolCalls int    `json:"toolCalls"`
+}
+
+// EditedFilePath returns the file a tool call changes: the path ToolFilePath finds for
+// every tool but Read, which does not change its file.
+func EditedFilePath(tool models.ToolUse) string {
+	if tool.Name == "Read" {
+		return ""
+	}
+	return ToolFilePath(tool)
+}
+
+// usageCounts accumulates the counts of a UsageReport.
+type usageCounts struct {
+	tools map[string]int
+	files map[string]int
+	days  map[string]*DayUsage
+}
+
+func newUsageCounts() *usageCounts {
+	return &usageCounts{tools: make(map[string]int), files: make(map[string]int), days: make(map[string]*DayUsage)}
+}
+
+// add counts the tool calls, edited files and days of entries, dating them in loc.
+func (u *usageCounts) add(entries []models.ConversationEntry, loc *time.Location) {
+	for name, n := range CountToolCalls(entries) {
+		u.tools[name] += n
+	}
+	for i := range entries {
+		tools := entries[i].ExtractToolCalls()
+		for _, tool := range tools {
+			if path := EditedFilePath(tool); path != "" {
+				u.files[path]++
+			}
+		}
+		ts, err := entries[i].GetTimestamp()
+		if err != nil {
+			continue
+		}
+		date := ts.In(loc).Format("2006-01-02")
+		day := u.days[date]
+		if day == nil {
+			day = &DayUsage{Date: date}
+			u.days[date] = day
+		}
+		day.Entries++
+		day.ToolCalls += len(tools)
+	}
+}
+
+// merge adds the counts of other to u.
+func (u *usageCounts) merge(other *usageCounts) {
+	for name, n := range other.tools {
+		u.tools[name] += n
+	}
+	for path, n := range other.files {
+		u.files[path] += n
+	}
+	for date, day := range other.days {
+		if mine := u.days[date]; mine != nil {
+			mine.Entries += day.Entries
+			mine.ToolCalls += day.ToolCalls
+		} else {
+			copied := *day
+			u.days[date] = &copied
+		}
+	}
+}
+
+// ProjectUsage reports the tool usage of every session in a project directory: calls
+// per tool, the most edited files and the busiest days (by entries, in local time),
+// counting the entries of each session's subagents too. Sessions are read concurrently
+// (see ReadConcurrently); files that cannot be read are listed in ReadErrors. err is
+// only for a directory that cannot be listed.
+func ProjectUsage(projectDir string) (*UsageReport, error) {
+	sessionFiles, err := paths.ListSessionFiles(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	sessionIDs := make([]string, 0, len(sessionFiles))
+	for sessionID := range sessionFiles {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	sort.Strings(sessionIDs)
+
+	counts := make([]*usageCounts, len(sessionIDs))
+	agentErrs := make([][]error, len(sessionIDs))
+	errs := ReadConcurrently(len(sessionIDs), func(i int) error {
+		sessionID := sessionIDs[i]
+		entries, err := ReadSession(sessionFiles[sessionID])
+		if err != nil {
+			return &SessionReadError{SessionID: sessionID, Path: sessionFiles[sessionID], Err: err}
+		}
+		u := newUsageCounts()
+		u.add(entries, time.Local)
+
+		agentFiles, err := paths.ListAgentFiles(filepath.Join(projectDir, sessionID))
+		if err != nil {
+			return &SessionReadError{SessionID: sessionID, Path: filepath.Join(projectDir, sessionID), Err: err}
+		}
+		for _, agentFile := range agentFiles {
+			agentEntries, err := ReadSession(agentFile)
+			if err != nil {
+				agentErrs[i] = append(agentErrs[i], &SessionReadError{SessionID: sessionID, Path: agentFile, Err: err})
+				continue
+			}
+			u.add(agentEntries, time.Local)
+		}
+		counts[i] = u
+		return nil
+	})
+
+	report := &UsageReport{}
+	total := newUsageCounts()
+	for i := range sessionIDs {
+		if errs != nil && errs[i] != nil {
+			report.ReadErrors = append(report.ReadErrors, errs[i])
+			continue
+		}
+		report.ReadErrors = append(report.ReadErrors, agentErrs[i]...)
+		report.Sessions++
+		total.merge(counts[i])
+	}
+
+	for name, n := range total.tools {
+		report.Tools = append(report.Tools, ToolUsage{Name: name, Calls: n})
+		report.ToolCalls += n
+	}
+	sort.Slice(report.Tools, func(i, j int) bool {
+		a, b := report.Tools[i], report.Tools[j]
+		if a.Calls != b.Calls {
+			return a.Calls > b.Calls
+		}
+		return a.Name < b.Name
+	})
+
+	for path, n := range total.files {
+		report.Files = append(report.Files, FileUsage{Path: path, Edits: n})
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		a, b := report.Files[i], report.Files[j]
+		if a.Edits != b.Edits {
+			return a.Edits > b.Edits
+		}
+		return a.Path < b.Path
+	})
+
+	for _, day := range total.days {
+		report.Days = append(report.Days, *day)
+	}
+	sort.Slice(report.Days, func(i, j int) bool {
+		a, b := report.Days[i], report.Days[j]
+		if a.Entries != b.Entries {
+			return a.Entries > b.Entries
+		}
+		return a.Date < b.Date
+	})
+	return report, nil
+}
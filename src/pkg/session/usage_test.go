@@ -0,0 +1,113 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/models"
+)
+
+func TestEditedFilePath(t *testing.T) {
+	tests := []struct {
+		name string
+		tool models.ToolUse
+		want string
+	}{
+		{"Edit", models.ToolUse{Name: "Edit", Input: map[string]any{"file_path": "/src/a.go"}}, "/src/a.go"},
+		{"Write", models.ToolUse{Name: "Write", Input: map[string]any{"file_path": "/src/b.go"}}, "/src/b.go"},
+		{"MultiEdit", models.ToolUse{Name: "MultiEdit", Input: map[string]any{"file_path": "/src/c.go"}}, "/src/c.go"},
+		{"NotebookEdit", models.ToolUse{Name: "NotebookEdit", Input: map[string]any{"notebook_path": "/nb.ipynb"}}, "/nb.ipynb"},
+		{"ApplyPatch", models.ToolUse{Name: "ApplyPatch", Input: map[string]any{"input": "*** Begin Patch\n*** Update File: docs/x.md\n*** End Patch"}}, "docs/x.md"},
+		{"Read is not an edit", models.ToolUse{Name: "Read", Input: map[string]any{"file_path": "/src/a.go"}}, ""},
+		{"no input", models.ToolUse{Name: "Edit"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EditedFilePath(tt.tool); got != tt.want {
+				t.Errorf("EditedFilePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectUsage(t *testing.T) {
+	projectDir := t.TempDir()
+	call := func(ts, name, input string) string {
+		return `{"type":"assistant","timestamp":"` + ts + `","message":{"role":"assistant","content":[{"type":"tool_use","id":"t","name":"` + name + `","input":` + input + `}]}}` + "\n"
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Session one edits main.go twice on day one; its subagent edits it again on day two
+	write(filepath.Join(projectDir, "11111111-1111-1111-1111-111111111111.jsonl"),
+		`{"type":"user","timestamp":"2026-01-10T12:00:00Z","message":"edit it"}`+"\n"+
+			call("2026-01-10T12:00:01Z", "Edit", `{"file_path":"/src/main.go"}`)+
+			call("2026-01-10T12:00:02Z", "Edit", `{"file_path":"/src/main.go"}`)+
+			call("2026-01-10T12:00:03Z", "Bash", `{"command":"go test"}`))
+	write(filepath.Join(projectDir, "11111111-1111-1111-1111-111111111111", "subagents", "agent-a1.jsonl"),
+		call("2026-01-11T12:00:00Z", "Edit", `{"file_path":"/src/main.go"}`))
+	// Session two writes util.go and reads main.go, which is not an edit
+	write(filepath.Join(projectDir, "22222222-2222-2222-2222-222222222222.jsonl"),
+		call("2026-01-11T12:00:00Z", "Write", `{"file_path":"/src/util.go"}`)+
+			call("2026-01-11T12:00:01Z", "Read", `{"file_path":"/src/main.go"}`)+
+			call("2026-01-11T12:00:02Z", "Bash", `{"command":"ls"}`))
+
+	report, err := ProjectUsage(projectDir)
+	if err != nil {
+		t.Fatalf("ProjectUsage() error: %v", err)
+	}
+	if len(report.ReadErrors) != 0 {
+		t.Errorf("ReadErrors = %v", report.ReadErrors)
+	}
+	if report.Sessions != 2 || report.ToolCalls != 7 {
+		t.Errorf("Sessions, ToolCalls = %d, %d, want 2, 7", report.Sessions, report.ToolCalls)
+	}
+
+	wantTools := []ToolUsage{{"Edit", 3}, {"Bash", 2}, {"Read", 1}, {"Write", 1}}
+	if len(report.Tools) != len(wantTools) {
+		t.Fatalf("Tools = %v, want %v", report.Tools, wantTools)
+	}
+	for i, want := range wantTools {
+		if report.Tools[i] != want {
+			t.Errorf("Tools[%d] = %v, want %v", i, report.Tools[i], want)
+		}
+	}
+
+	wantFiles := []FileUsage{{"/src/main.go", 3}, {"/src/util.go", 1}}
+	if len(report.Files) != len(wantFiles) || report.Files[0] != wantFiles[0] || report.Files[1] != wantFiles[1] {
+		t.Errorf("Files = %v, want %v", report.Files, wantFiles)
+	}
+
+	day := func(ts string) string {
+		parsed, _ := time.Parse(time.RFC3339, ts)
+		return parsed.Local().Format("2006-01-02")
+	}
+	wantDays := []DayUsage{{day("2026-01-10T12:00:00Z"), 4, 3}, {day("2026-01-11T12:00:00Z"), 4, 4}}
+	if len(report.Days) != 2 {
+		t.Fatalf("Days = %v, want %v", report.Days, wantDays)
+	}
+	for i, want := range wantDays {
+		if report.Days[i] != want {
+			t.Errorf("Days[%d] = %v, want %v", i, report.Days[i], want)
+		}
+	}
+}
+
+func TestProjectUsage_EmptyProject(t *testing.T) {
+	report, err := ProjectUsage(t.TempDir())
+	if err != nil {
+		t.Fatalf("ProjectUsage() error: %v", err)
+	}
+	if report.Sessions != 0 || report.ToolCalls != 0 || len(report.Tools) != 0 {
+		t.Errorf("empty project report = %+v", report)
+	}
+}
@@ -0,0 +1,133 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// LatestReleaseURL is the GitHub API endpoint describing the latest published release.
+const LatestReleaseURL = "https://api.github.com/repos/randlee/claude-history/releases/latest"
+
+// Release is the latest published release, as reported by the releases API.
+type Release struct {
+	Tag string `json:"tag_name"` // e.g. "v0.4.0"
+	URL string `json:"html_url"` // Release page
+}
+
+// UpdateStatus is the result of comparing a version against the latest release.
+type UpdateStatus struct {
+	Current         string
+	Latest          Release
+	UpdateAvailable bool // Latest is newer than Current
+}
+
+// LatestRelease fetches the latest release from url (normally LatestReleaseURL).
+// The request is bounded by ctx; use a context with a timeout.
+func LatestRelease(ctx context.Context, client *http.Client, url string) (Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Release{}, fmt.Errorf("failed to build release request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "claude-history/"+Version)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Release{}, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("failed to fetch latest release: %s", resp.Status)
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return Release{}, fmt.Errorf("failed to parse latest release: %w", err)
+	}
+	if release.Tag == "" {
+		return Release{}, fmt.Errorf("latest release has no tag")
+	}
+	return release, nil
+}
+
+// CheckForUpdate fetches the latest release from url and compares it with current.
+func CheckForUpdate(ctx context.Context, client *http.Client, url, current string) (*UpdateStatus, error) {
+	release, err := LatestRelease(ctx, client, url)
+	if err != nil {
+		return nil, err
+	}
+	cmp, err := Compare(release.Tag, current)
+	if err != nil {
+		return nil, err
+	}
+	return &UpdateStatus{Current: current, Latest: release, UpdateAvailable: cmp > 0}, nil
+}
+
+// Compare compares two semantic versions ("1.2.3", optionally prefixed with "v" and
+// suffixed with "-prerelease" or "+build"), returning -1, 0 or 1 as a is older than,
+// equal to, or newer than b. A prerelease is older than its release; prerelease
+// identifiers are compared as plain strings.
+func Compare(a, b string) (int, error) {
+	va, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range va.core {
+		if va.core[i] != vb.core[i] {
+			if va.core[i] < vb.core[i] {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+
+	switch {
+	case va.pre == vb.pre:
+		return 0, nil
+	case va.pre == "":
+		return 1, nil
+	case vb.pre == "":
+		return -1, nil
+	case va.pre < vb.pre:
+		return -1, nil
+	default:
+		return 1, nil
+	}
+}
+
+// semver is a parsed version: major, minor and patch, and the prerelease suffix.
+type semver struct {
+	core [3]int
+	pre  string
+}
+
+// parseVersion parses a version for Compare. Missing minor or patch numbers are 0.
+func parseVersion(s string) (semver, error) {
+	var v semver
+	rest := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	rest, _, _ = strings.Cut(rest, "+")
+	rest, v.pre, _ = strings.Cut(rest, "-")
+
+	parts := strings.Split(rest, ".")
+	if len(parts) > 3 {
+		return v, fmt.Errorf("invalid version %q", s)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version %q", s)
+		}
+		v.core[i] = n
+	}
+	return v, nil
+}
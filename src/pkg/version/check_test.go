@@ -0,0 +1,134 @@
+package version_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/randlee/claude-history/pkg/version"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"0.3.0", "0.3.0", 0},
+		{"v0.3.0", "0.3.0", 0},
+		{"0.4.0", "0.3.9", 1},
+		{"0.3.10", "0.3.9", 1},
+		{"1.0.0", "0.99.99", 1},
+		{"0.3.0", "1.0.0", -1},
+		{"1.2", "1.2.0", 0},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0", "1.0.0-rc.1", 1},
+		{"1.0.0-rc.1", "1.0.0-rc.2", -1},
+		{"1.0.0+build.5", "1.0.0", 0},
+	}
+	for _, tt := range tests {
+		got, err := version.Compare(tt.a, tt.b)
+		if err != nil {
+			t.Errorf("Compare(%q, %q) error = %v", tt.a, tt.b, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "latest", "1.2.3.4", "1.x.0", "v-1.0.0"} {
+		if _, err := version.Compare(bad, "1.0.0"); err == nil {
+			t.Errorf("Compare(%q) should fail", bad)
+		}
+	}
+}
+
+// releaseServer serves body with status from a test releases API.
+func releaseServer(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("User-Agent"), "claude-history/") {
+			t.Errorf("User-Agent = %q, want claude-history/...", r.Header.Get("User-Agent"))
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCheckForUpdate(t *testing.T) {
+	srv := releaseServer(t, http.StatusOK, `{"tag_name":"v0.9.0","html_url":"https://example.com/releases/v0.9.0"}`)
+
+	status, err := version.CheckForUpdate(context.Background(), srv.Client(), srv.URL, "0.3.0")
+	if err != nil {
+		t.Fatalf("CheckForUpdate() error = %v", err)
+	}
+	if !status.UpdateAvailable || status.Latest.Tag != "v0.9.0" || status.Latest.URL != "https://example.com/releases/v0.9.0" {
+		t.Errorf("CheckForUpdate() = %+v, want update to v0.9.0", status)
+	}
+
+	status, err = version.CheckForUpdate(context.Background(), srv.Client(), srv.URL, "0.9.0")
+	if err != nil {
+		t.Fatalf("CheckForUpdate() error = %v", err)
+	}
+	if status.UpdateAvailable {
+		t.Error("same version should not report an update")
+	}
+
+	status, err = version.CheckForUpdate(context.Background(), srv.Client(), srv.URL, "1.0.0-dev")
+	if err != nil {
+		t.Fatalf("CheckForUpdate() error = %v", err)
+	}
+	if status.UpdateAvailable {
+		t.Error("a build newer than the latest release should not report an update")
+	}
+}
+
+func TestLatestRelease_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"rate limited", http.StatusForbidden, `{"message":"rate limit"}`, "403"},
+		{"bad json", http.StatusOK, `not json`, "failed to parse"},
+		{"no tag", http.StatusOK, `{}`, "no tag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := releaseServer(t, tt.status, tt.body)
+			_, err := version.LatestRelease(context.Background(), srv.Client(), srv.URL)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LatestRelease() error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestRelease_Timeout(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := version.LatestRelease(ctx, srv.Client(), srv.URL); err == nil {
+		t.Fatal("LatestRelease() should fail when the context times out")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("LatestRelease() took %v, should stop at the context deadline", elapsed)
+	}
+}